@@ -9,12 +9,17 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ConradKurth/forecasting/backend/internal/auth"
 	"github.com/ConradKurth/forecasting/backend/internal/config"
 	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/events"
+	"github.com/ConradKurth/forecasting/backend/internal/http/accounts"
 	"github.com/ConradKurth/forecasting/backend/internal/http/dashboard"
+	"github.com/ConradKurth/forecasting/backend/internal/http/integrations"
 	"github.com/ConradKurth/forecasting/backend/internal/http/oauth"
 	"github.com/ConradKurth/forecasting/backend/internal/http/sync"
 	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	shopifyapi "github.com/ConradKurth/forecasting/backend/internal/shopify"
 	"github.com/ConradKurth/forecasting/backend/internal/worker"
 	"github.com/ConradKurth/forecasting/backend/pkg/logger"
 	"github.com/go-chi/chi/v5"
@@ -26,6 +31,9 @@ func main() {
 	// Initialize logger
 	logger.Init(logger.Level(config.Values.Logging.Level))
 
+	// Warn early if the Shopify API version we request is no longer supported
+	shopifyapi.CheckAPIVersion(config.Values.Shopify.APIVersion, time.Now())
+
 	// Run database migrations
 	logger.Info("Running database migrations...")
 	if err := db.RunMigrations(config.Values.Database.URL); err != nil {
@@ -49,9 +57,18 @@ func main() {
 		}
 	}()
 
+	// Initialize the sync event bus
+	eventBus := events.NewBus()
+	defer func() {
+		if err := eventBus.Close(); err != nil {
+			logger.Error("Failed to close event bus", "error", err)
+		}
+	}()
+
 	// Initialize managers
 	shopifyManager := manager.NewShopifyManager(database, workerQueue)
-	syncManager := manager.NewInventorySyncManager(database, workerQueue)
+	syncManager := manager.NewInventorySyncManager(database, workerQueue, eventBus)
+	accountManager := manager.NewAccountManager(database)
 
 	r := chi.NewRouter()
 
@@ -71,14 +88,17 @@ func main() {
 		MaxAge:           300,
 	}))
 
-	// Add other middleware
+	// Add other middleware. Stream tokens are redacted before the request is logged.
+	r.Use(auth.RedactQueryToken)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
 	// Initialize routes
 	oauth.InitRoutes(r, shopifyManager, syncManager)
 	dashboard.InitRoutes(r, shopifyManager)
-	sync.InitRoutes(r, syncManager, database)
+	sync.InitRoutes(r, syncManager, eventBus)
+	integrations.InitRoutes(r, syncManager)
+	accounts.InitRoutes(r, accountManager)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+)
+
+// exchangerates loads the exchange rates the account views convert revenue and stock value with,
+// either from a CSV file with date, base, quote and rate columns or from a rates provider:
+//
+//	go run ./cmd/exchangerates --file rates.csv
+//	go run ./cmd/exchangerates --provider ecb
+func main() {
+	logger.Init(logger.Level(config.Values.Logging.Level))
+
+	path := flag.String("file", "", "CSV file of rates to import")
+	providerName := flag.String("provider", "", "provider to fetch the latest rates from: ecb")
+	flag.Parse()
+
+	if (*path == "") == (*providerName == "") {
+		logger.Error("Loading rates needs either --file or --provider")
+		os.Exit(1)
+	}
+
+	var provider currency.Provider
+	switch *providerName {
+	case "":
+	case "ecb":
+		provider = currency.NewECBProvider("")
+	default:
+		logger.Error("Unknown rates provider", "provider", *providerName)
+		os.Exit(1)
+	}
+
+	var rates []currency.Rate
+	if *path != "" {
+		file, err := os.Open(*path)
+		if err != nil {
+			logger.Error("Failed to open file", "error", err)
+			os.Exit(1)
+		}
+		rates, err = currency.ReadRates(file)
+		file.Close()
+		if err != nil {
+			logger.Error("Failed to read rates", "file", *path, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	database, err := db.New()
+	if err != nil {
+		logger.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	rateManager := manager.NewExchangeRateManager(database)
+
+	var count int
+	if provider != nil {
+		count, err = rateManager.RefreshRates(context.Background(), provider)
+	} else {
+		count, err = rateManager.ImportRates(context.Background(), "file", rates)
+	}
+	if err != nil {
+		logger.Error("Failed to load exchange rates", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("Loaded exchange rates", "rates", count)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/shopify"
+	"github.com/ConradKurth/forecasting/backend/internal/shopify/shopifytest"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+)
+
+// fakeshopify serves recorded Shopify fixtures for local development, or records them from a real shop.
+//
+// Serve (point the backend at it with SHOPIFY_API_BASE_URL=http://localhost:8090):
+//
+//	go run ./cmd/fakeshopify --fixtures shop.json
+//
+// Record:
+//
+//	go run ./cmd/fakeshopify --record --shop my-shop.myshopify.com --token shpat_... --fixtures shop.json
+func main() {
+	logger.Init(logger.Level(config.Values.Logging.Level))
+
+	addr := flag.String("addr", ":8090", "address to serve the fake Shopify API on")
+	fixturesPath := flag.String("fixtures", "", "fixtures JSON file to serve or record into (serves the demo shop when empty)")
+	record := flag.Bool("record", false, "record fixtures from a real shop instead of serving them")
+	shopDomain := flag.String("shop", "", "shop domain to record from")
+	accessToken := flag.String("token", "", "access token of the shop to record from")
+	orderDays := flag.Int("order-days", 90, "days of orders to record")
+	flag.Parse()
+
+	if *record {
+		if *shopDomain == "" || *accessToken == "" || *fixturesPath == "" {
+			logger.Error("Recording needs --shop, --token and --fixtures")
+			os.Exit(1)
+		}
+
+		client := shopify.NewClient(*shopDomain, *accessToken)
+		fixtures, err := shopifytest.Record(context.Background(), client, time.Now().AddDate(0, 0, -*orderDays))
+		if err != nil {
+			logger.Error("Failed to record fixtures", "shop_domain", *shopDomain, "error", err)
+			os.Exit(1)
+		}
+		if err := fixtures.Save(*fixturesPath); err != nil {
+			logger.Error("Failed to save fixtures", "error", err)
+			os.Exit(1)
+		}
+
+		logger.Info("Recorded fixtures",
+			"path", *fixturesPath,
+			"locations", len(fixtures.Locations),
+			"products", len(fixtures.Products),
+			"inventory_items", len(fixtures.InventoryItems),
+			"orders", len(fixtures.Orders))
+		return
+	}
+
+	fixtures := shopifytest.DemoFixtures()
+	if *fixturesPath != "" {
+		loaded, err := shopifytest.LoadFixtures(*fixturesPath)
+		if err != nil {
+			logger.Error("Failed to load fixtures", "error", err)
+			os.Exit(1)
+		}
+		fixtures = loaded
+	}
+
+	logger.Info("Fake Shopify API listening", "addr", *addr, "products", len(fixtures.Products), "orders", len(fixtures.Orders))
+	if err := http.ListenAndServe(*addr, shopifytest.NewHandler(fixtures)); err != nil {
+		logger.Error("Fake Shopify API stopped", "error", err)
+		os.Exit(1)
+	}
+}
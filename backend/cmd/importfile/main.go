@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/fileimport"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// importfile imports a CSV or XLSX file into a shop on a client's behalf, the way the upload
+// endpoint does. Products must be imported before the inventory and sales that refer to them:
+//
+//	go run ./cmd/importfile --shop my-shop.myshopify.com --type products --file products.csv
+//	go run ./cmd/importfile --shop my-shop.myshopify.com --type inventory --file stock.xlsx
+//	go run ./cmd/importfile --shop my-shop.myshopify.com --type sales --file sales.csv
+//
+// Amounts are taken to be in the shop's currency unless --currency names another (--currency EUR).
+func main() {
+	logger.Init(logger.Level(config.Values.Logging.Level))
+
+	shopDomain := flag.String("shop", "", "domain of the shop to import into")
+	importType := flag.String("type", "", "kind of data the file holds: products, inventory or sales")
+	path := flag.String("file", "", "CSV or XLSX file to import")
+	fileCurrency := flag.String("currency", "", "ISO 4217 currency of the file's prices, costs and totals (the shop's currency when empty)")
+	flag.Parse()
+
+	if *shopDomain == "" || *importType == "" || *path == "" {
+		logger.Error("Importing needs --shop, --type and --file")
+		os.Exit(1)
+	}
+
+	kind, err := fileimport.ParseKind(*importType)
+	if err != nil {
+		logger.Error("Invalid import type", "error", err)
+		os.Exit(1)
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		logger.Error("Failed to open file", "error", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	database, err := db.New()
+	if err != nil {
+		logger.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	// Imports are written synchronously, so neither the worker queue nor the event bus is needed
+	syncManager := manager.NewInventorySyncManager(database, nil, nil)
+
+	result, err := syncManager.ImportShopFile(context.Background(), manager.ImportFileRequest{
+		ShopDomain: *shopDomain,
+		Kind:       kind,
+		FileName:   filepath.Base(*path),
+		Content:    file,
+		Currency:   *fileCurrency,
+	})
+	if err != nil {
+		var validationErrors fileimport.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, validationError := range validationErrors {
+				fmt.Fprintln(os.Stderr, validationError.Error())
+			}
+			logger.Error("File has invalid rows, nothing was imported", "file", *path, "errors", len(validationErrors))
+		} else {
+			logger.Error("Failed to import file", "file", *path, "error", err)
+		}
+		os.Exit(1)
+	}
+
+	logger.Info("Imported file",
+		"file", *path,
+		"integration_id", result.IntegrationID,
+		"rows", result.Rows,
+		"locations", result.Stats.LocationsCount,
+		"products", result.Stats.ProductsCount,
+		"variants", result.Stats.ProductVariantsCount,
+		"inventory_levels", result.Stats.InventoryLevelsCount,
+		"orders", result.Stats.OrdersCount,
+		"order_line_items", result.OrderLineItemsCount)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/events"
+	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	"github.com/ConradKurth/forecasting/backend/internal/worker"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+)
+
+func main() {
+	// Initialize logger
+	logger.Init(logger.Level(config.Values.Logging.Level))
+
+	// Initialize database (config is loaded automatically)
+	database, err := db.New()
+	if err != nil {
+		logger.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	logger.Info("Database connected successfully")
+
+	// Initialize worker queue client
+	workerQueue := worker.NewClient()
+	defer func() {
+		if err := workerQueue.Close(); err != nil {
+			logger.Error("Failed to close worker queue", "error", err)
+		}
+	}()
+
+	// Initialize the sync event bus
+	eventBus := events.NewBus()
+	defer func() {
+		if err := eventBus.Close(); err != nil {
+			logger.Error("Failed to close event bus", "error", err)
+		}
+	}()
+
+	// The sync manager provides the per-integration schedules
+	syncManager := manager.NewInventorySyncManager(database, workerQueue, eventBus)
+
+	scheduler, err := worker.NewScheduler(syncManager)
+	if err != nil {
+		logger.Error("Failed to create scheduler", "error", err)
+		os.Exit(1)
+	}
+
+	// Run a single scheduler process; each replica would enqueue its own copy of every sync
+	if err := scheduler.Start(); err != nil {
+		logger.Error("Failed to start scheduler", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("Scheduler started. Press Ctrl+C to stop.")
+
+	// Wait for shutdown signal
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	logger.Info("Shutting down scheduler...")
+	scheduler.Shutdown()
+	logger.Info("Scheduler stopped.")
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/ConradKurth/forecasting/backend/internal/config"
 	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/events"
 	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	shopifyapi "github.com/ConradKurth/forecasting/backend/internal/shopify"
 	"github.com/ConradKurth/forecasting/backend/internal/worker"
 	"github.com/ConradKurth/forecasting/backend/pkg/logger"
 )
@@ -16,6 +20,9 @@ func main() {
 	// Initialize logger
 	logger.Init(logger.Level(config.Values.Logging.Level))
 
+	// Warn early if the Shopify API version we request is no longer supported
+	shopifyapi.CheckAPIVersion(config.Values.Shopify.APIVersion, time.Now())
+
 	// Initialize database (config is loaded automatically)
 	database, err := db.New()
 	if err != nil {
@@ -34,9 +41,22 @@ func main() {
 		}
 	}()
 
+	// Initialize the sync event bus
+	eventBus := events.NewBus()
+	defer func() {
+		if err := eventBus.Close(); err != nil {
+			logger.Error("Failed to close event bus", "error", err)
+		}
+	}()
+
 	// Initialize managers
 	shopifyManager := manager.NewShopifyManager(database, workerQueue)
-	syncManager := manager.NewInventorySyncManager(database, workerQueue)
+	syncManager := manager.NewInventorySyncManager(database, workerQueue, eventBus)
+
+	// Fail syncs left in_progress by a crashed worker or a lost task before taking new work
+	if err := syncManager.ReconcileSyncStates(context.Background()); err != nil {
+		logger.Error("Failed to reconcile sync states", "error", err)
+	}
 
 	// Create worker server with middleware and proper configuration
 	server := worker.NewServer(shopifyManager, syncManager)
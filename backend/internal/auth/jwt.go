@@ -10,17 +10,31 @@ import (
 
 var jwtSecret = []byte("your-secret-key") // In production, use environment variable
 
+// StreamScope is the scope of tokens that only open event streams. Browsers pass those in the
+// URL, where proxies and access logs can see them, so they are short-lived and accepted nowhere else.
+const StreamScope = "stream"
+
+// StreamTokenTTL is how long a stream token can be used to open a stream
+const StreamTokenTTL = 5 * time.Minute
+
 type Claims struct {
 	Shop   string `json:"shop"`
 	UserID string `json:"user_id"`
+
+	// AccountID is the merchant account of the shop. Tokens issued before accounts existed have none.
+	AccountID string `json:"account_id,omitempty"`
+
+	// Scope limits what the token can be used for, empty for session tokens
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(shop string, userID id.ID[id.User]) (string, error) {
+func GenerateJWT(shop string, userID id.ID[id.User], accountID id.ID[id.MerchantAccount]) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 	claims := &Claims{
-		Shop:   shop,
-		UserID: userID.String(),
+		Shop:      shop,
+		UserID:    userID.String(),
+		AccountID: accountID.String(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -31,6 +45,24 @@ func GenerateJWT(shop string, userID id.ID[id.User]) (string, error) {
 	return token.SignedString(jwtSecret)
 }
 
+// GenerateStreamJWT issues a short-lived token of the user's session that only opens event streams
+func GenerateStreamJWT(user *User) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		Shop:      user.Shop,
+		UserID:    user.UserID,
+		AccountID: user.AccountID,
+		Scope:     StreamScope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(StreamTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
 func ValidateJWT(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
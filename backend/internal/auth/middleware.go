@@ -13,8 +13,9 @@ type contextKey string
 const UserContextKey contextKey = "user"
 
 type User struct {
-	Shop   string
-	UserID string
+	Shop      string
+	UserID    string
+	AccountID string
 }
 
 func AuthMiddleware(next http.Handler) http.Handler {
@@ -31,22 +32,58 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		claims, err := ValidateJWT(bearerToken[1])
-		if err != nil {
-			sendError(w, response.InvalidToken())
+		authenticate(w, r, next, bearerToken[1], "")
+	})
+}
+
+// StreamAuthMiddleware authenticates streaming endpoints. Browsers cannot set headers on an
+// EventSource, so a stream token (see GenerateStreamJWT) may be passed in the "token" query
+// parameter instead. Session tokens are not accepted there, since URLs end up in logs.
+func StreamAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" {
+			authenticate(w, r, next, token, StreamScope)
 			return
 		}
 
-		user := &User{
-			Shop:   claims.Shop,
-			UserID: claims.UserID,
-		}
+		AuthMiddleware(next).ServeHTTP(w, r)
+	})
+}
 
-		ctx := context.WithValue(r.Context(), UserContextKey, user)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// RedactQueryToken hides the "token" query parameter from the request URI that request loggers
+// print. The parsed URL is left as is for the handlers.
+func RedactQueryToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("token") {
+			query := r.URL.Query()
+			query.Set("token", "REDACTED")
+			redacted := *r
+			redacted.RequestURI = r.URL.Path + "?" + query.Encode()
+			r = &redacted
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
+// authenticate validates the token, which must have the given scope, and passes the request on
+// with its user in the context
+func authenticate(w http.ResponseWriter, r *http.Request, next http.Handler, token, scope string) {
+	claims, err := ValidateJWT(token)
+	if err != nil || claims.Scope != scope {
+		sendError(w, response.InvalidToken())
+		return
+	}
+
+	user := &User{
+		Shop:      claims.Shop,
+		UserID:    claims.UserID,
+		AccountID: claims.AccountID,
+	}
+
+	ctx := context.WithValue(r.Context(), UserContextKey, user)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // sendError sends an HTTP error using the response package utilities
 func sendError(w http.ResponseWriter, httpErr *response.HTTPError) {
 	errorResp := response.ErrorResponse{
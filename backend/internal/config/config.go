@@ -29,6 +29,22 @@ func loadConfigFile(path string) {
 	}
 }
 
+// moduleRoot returns the closest directory at or above dir containing a go.mod,
+// or dir itself when there is none (e.g. a deployed binary)
+func moduleRoot(dir string) string {
+	for curr := dir; ; {
+		if _, err := os.Stat(filepath.Join(curr, "go.mod")); err == nil {
+			return curr
+		}
+
+		parent := filepath.Dir(curr)
+		if parent == curr {
+			return dir
+		}
+		curr = parent
+	}
+}
+
 func init() {
 	env := os.Getenv(envKey)
 	if env == "" {
@@ -41,10 +57,13 @@ func init() {
 		panic(errors.Wrapf(err, "error getting pwd"))
 	}
 
-	loadConfigFile(filepath.Join(currDir, ".env."+env+".local"))
-	loadConfigFile(filepath.Join(currDir, ".env.local"))
-	loadConfigFile(filepath.Join(currDir, ".env."+env))
-	loadConfigFile(filepath.Join(currDir, ".env"))
+	// go test runs inside the package directory, so look for the env files in the module root
+	configDir := moduleRoot(currDir)
+
+	loadConfigFile(filepath.Join(configDir, ".env."+env+".local"))
+	loadConfigFile(filepath.Join(configDir, ".env.local"))
+	loadConfigFile(filepath.Join(configDir, ".env."+env))
+	loadConfigFile(filepath.Join(configDir, ".env"))
 
 	cfg, err := parseConfig()
 	if err != nil {
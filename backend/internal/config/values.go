@@ -1,15 +1,17 @@
 package config
 
 type serviceConfig struct {
-	Env        string `env:"GO_ENV"`
-	Service    service
-	Database   database
-	Redis      redis
-	Shopify    shopify
-	Frontend   frontend
-	CORS       cors
-	Encryption encryption
-	Logging    logging
+	Env         string `env:"GO_ENV"`
+	Service     service
+	Database    database
+	Redis       redis
+	Shopify     shopify
+	BigCommerce bigcommerce
+	Square      square
+	Frontend    frontend
+	CORS        cors
+	Encryption  encryption
+	Logging     logging
 }
 
 type service struct {
@@ -25,6 +27,20 @@ type shopify struct {
 	ClientSecret string   `long:"client-secret" default:"" env:"SHOPIFY_CLIENT_SECRET" description:"Shopify Client Secret"`
 	RedirectURL  string   `long:"redirect-url" default:"" env:"SHOPIFY_REDIRECT_URL" description:"Shopify Redirect URL"`
 	Scopes       []string `long:"scopes" default:"read_products,read_locations,read_inventory,read_orders" env:"SHOPIFY_SCOPES" description:"Shopify Scopes"`
+	APIVersion   string   `long:"api-version" default:"2023-10" env:"SHOPIFY_API_VERSION" description:"Shopify Admin API version (YYYY-MM)"`
+	APIBaseURL   string   `long:"api-base-url" default:"" env:"SHOPIFY_API_BASE_URL" description:"Send Shopify Admin API requests to this URL instead of the shop (e.g. a fake Shopify server); ignored in production and staging"`
+}
+
+type bigcommerce struct {
+	ClientID     string `long:"bigcommerce-client-id" default:"" env:"BIGCOMMERCE_CLIENT_ID" description:"BigCommerce app Client ID"`
+	ClientSecret string `long:"bigcommerce-client-secret" default:"" env:"BIGCOMMERCE_CLIENT_SECRET" description:"BigCommerce app Client Secret"`
+	RedirectURL  string `long:"bigcommerce-redirect-url" default:"" env:"BIGCOMMERCE_REDIRECT_URL" description:"BigCommerce auth callback URL"`
+	LoginURL     string `long:"bigcommerce-login-url" default:"https://login.bigcommerce.com" env:"BIGCOMMERCE_LOGIN_URL" description:"BigCommerce OAuth server URL"`
+	APIBaseURL   string `long:"bigcommerce-api-base-url" default:"" env:"BIGCOMMERCE_API_BASE_URL" description:"Send BigCommerce API requests to this URL instead of https://api.bigcommerce.com (e.g. a fake server)"`
+}
+
+type square struct {
+	APIBaseURL string `long:"square-api-base-url" default:"https://connect.squareup.com" env:"SQUARE_API_BASE_URL" description:"Square API URL (https://connect.squareupsandbox.com for the sandbox, or a fake server)"`
 }
 
 type cors struct {
@@ -0,0 +1,185 @@
+package bigcommerce
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/pkg/errors"
+)
+
+// Page size requested from the BigCommerce API
+const pageSize = maxLimit
+
+// Connector reads a BigCommerce store through the API
+type Connector struct {
+	client *Client
+}
+
+var (
+	_ connector.PlatformConnector = (*Connector)(nil)
+	_ connector.Counter           = (*Connector)(nil)
+	_ connector.CurrencyReader    = (*Connector)(nil)
+)
+
+// New creates a connector that reads through the given client
+func New(client *Client) *Connector {
+	return &Connector{client: client}
+}
+
+// Factory creates the connectors of BigCommerce integrations from the access token saved when
+// the app was installed on the store
+func Factory(database db.Database) connector.Factory {
+	return func(ctx context.Context, integration core.PlatformIntegration) (connector.PlatformConnector, error) {
+		installation, err := database.GetCore().GetBigCommerceInstallationByStoreHash(ctx, integration.PlatformShopID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get bigcommerce installation")
+		}
+
+		return New(NewClient(installation.StoreHash, installation.AccessToken.String())), nil
+	}
+}
+
+// Platform implements connector.PlatformConnector
+func (c *Connector) Platform() core.PlatformType {
+	return core.PlatformTypeBigcommerce
+}
+
+// ListLocations implements connector.PlatformConnector
+func (c *Connector) ListLocations(ctx context.Context, cursor string) (*connector.Page[connector.Location], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, info, err := c.client.GetLocations(ctx, pageNumber, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Location]{NextCursor: nextCursor(pageNumber, info)}
+	for _, location := range locations {
+		page.Items = append(page.Items, toLocation(location))
+	}
+	return page, nil
+}
+
+// ListProducts implements connector.PlatformConnector. Variants come with their product.
+func (c *Connector) ListProducts(ctx context.Context, cursor string) (*connector.Page[connector.Product], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	products, info, err := c.client.GetProducts(ctx, pageNumber, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Product]{NextCursor: nextCursor(pageNumber, info)}
+	for _, product := range products {
+		page.Items = append(page.Items, toProduct(product))
+	}
+	return page, nil
+}
+
+// ListInventoryLevels implements connector.PlatformConnector through the multi-location inventory API
+func (c *Connector) ListInventoryLevels(ctx context.Context, cursor string) (*connector.Page[connector.InventoryLevel], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	items, info, err := c.client.GetInventoryItems(ctx, pageNumber, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.InventoryLevel]{NextCursor: nextCursor(pageNumber, info)}
+	for _, item := range items {
+		page.Items = append(page.Items, toInventoryLevels(item)...)
+	}
+	return page, nil
+}
+
+// ListOrders implements connector.PlatformConnector. The line items of each order are fetched with it.
+func (c *Connector) ListOrders(ctx context.Context, since time.Time, cursor string) (*connector.Page[connector.Order], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := c.client.GetOrders(ctx, since, pageNumber, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Order]{}
+	if len(orders) == pageSize {
+		page.NextCursor = strconv.Itoa(pageNumber + 1)
+	}
+	for _, order := range orders {
+		products, err := c.client.GetOrderProducts(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, toOrder(order, products))
+	}
+	return page, nil
+}
+
+// CountLocations implements connector.Counter
+func (c *Connector) CountLocations(ctx context.Context) (int, error) {
+	_, info, err := c.client.GetLocations(ctx, 1, 1)
+	if err != nil {
+		return 0, err
+	}
+	return info.Total, nil
+}
+
+// CountProducts implements connector.Counter
+func (c *Connector) CountProducts(ctx context.Context) (int, error) {
+	_, info, err := c.client.GetProducts(ctx, 1, 1)
+	if err != nil {
+		return 0, err
+	}
+	return info.Total, nil
+}
+
+// CountOrders implements connector.Counter
+func (c *Connector) CountOrders(ctx context.Context, since time.Time) (int, error) {
+	return c.client.CountOrders(ctx, since)
+}
+
+// Currency implements connector.CurrencyReader with the default currency of the store
+func (c *Connector) Currency(ctx context.Context) (string, error) {
+	store, err := c.client.GetStore(ctx)
+	if err != nil {
+		return "", err
+	}
+	return store.Currency, nil
+}
+
+// parseCursor decodes a page number cursor, an empty cursor being the first page
+func parseCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 1, nil
+	}
+
+	page, err := strconv.Atoi(cursor)
+	if err != nil || page < 1 {
+		return 0, errors.Errorf("invalid bigcommerce cursor %q", cursor)
+	}
+	return page, nil
+}
+
+// nextCursor returns the cursor of the page after pageNumber, or "" when it was the last one
+func nextCursor(pageNumber int, info *pagination) string {
+	if pageNumber >= info.TotalPages {
+		return ""
+	}
+	return strconv.Itoa(pageNumber + 1)
+}
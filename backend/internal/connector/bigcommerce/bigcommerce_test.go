@@ -0,0 +1,158 @@
+package bigcommerce_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce/bigcommercetest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newConnector(t *testing.T) (*bigcommerce.Connector, *bigcommercetest.Server) {
+	t.Helper()
+
+	server := bigcommercetest.NewServer(bigcommercetest.DemoFixtures())
+	t.Cleanup(server.Close)
+	return bigcommerce.New(server.Client(bigcommercetest.AccessToken)), server
+}
+
+func TestListProductsMapsVariants(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	products := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Product], error) {
+		return conn.ListProducts(ctx, cursor)
+	})
+
+	count, err := conn.CountProducts(ctx)
+	if err != nil {
+		t.Fatalf("CountProducts: %v", err)
+	}
+	if len(products) != 3 || count != 3 {
+		t.Fatalf("listed %d products and counted %d, want 3", len(products), count)
+	}
+
+	mug, beanie, ebook := products[0], products[1], products[2]
+	if len(mug.Variants) != 1 || mug.Variants[0].InventoryItem.Cost != "6" || !mug.Variants[0].InventoryItem.Tracked {
+		t.Errorf("product without options = %+v, want its base variant as a tracked item", mug.Variants)
+	}
+	if len(beanie.Variants) != 2 || beanie.Variants[1].Price != "32" || beanie.Variants[1].InventoryItemID != "203" {
+		t.Errorf("variants = %+v, want two variants with their own prices and items", beanie.Variants)
+	}
+	// The base variant has no price of its own
+	if ebook.Variants[0].Price != "9" || ebook.Status != core.ProductStatusDraft || ebook.Variants[0].InventoryItem.Tracked {
+		t.Errorf("hidden digital product = %+v, want a draft at the product price without tracking", ebook)
+	}
+}
+
+func TestListInventoryLevelsAtEveryLocation(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	locations := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Location], error) {
+		return conn.ListLocations(ctx, cursor)
+	})
+	if len(locations) != 2 {
+		t.Fatalf("listed %d locations, want 2", len(locations))
+	}
+
+	levels := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.InventoryLevel], error) {
+		return conn.ListInventoryLevels(ctx, cursor)
+	})
+	if len(levels) != 6 {
+		t.Fatalf("listed %d inventory levels, want 6", len(levels))
+	}
+
+	want := connector.InventoryLevel{InventoryItemID: "203", LocationID: "2", Available: 2}
+	var found bool
+	for _, level := range levels {
+		found = found || level == want
+	}
+	if !found {
+		t.Errorf("levels %+v do not include %+v", levels, want)
+	}
+}
+
+func TestListOrdersMapsStatuses(t *testing.T) {
+	conn, server := newConnector(t)
+	ctx := context.Background()
+
+	orders := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Order], error) {
+		return conn.ListOrders(ctx, time.Now().AddDate(0, 0, -20), cursor)
+	})
+	if len(orders) != 2 {
+		t.Fatalf("listed %d orders, want 2", len(orders))
+	}
+
+	completed, cancelled := orders[0], orders[1]
+	if completed.FinancialStatus != core.FinancialStatusPaid || completed.FulfillmentStatus != core.FulfillmentStatusFulfilled {
+		t.Errorf("completed order statuses = %s/%s, want paid/fulfilled", completed.FinancialStatus, completed.FulfillmentStatus)
+	}
+	if len(completed.LineItems) != 2 || completed.LineItems[1].VariantID != "202" {
+		t.Errorf("line items = %+v, want the two order products", completed.LineItems)
+	}
+	if cancelled.FinancialStatus != core.FinancialStatusVoided || cancelled.CancelledAt == nil {
+		t.Errorf("cancelled order = %+v, want voided with a cancel time", cancelled)
+	}
+
+	// No orders in the window is an empty 204 response
+	empty, err := conn.ListOrders(ctx, time.Now().Add(time.Hour), "")
+	if err != nil || len(empty.Items) != 0 || empty.NextCursor != "" {
+		t.Errorf("ListOrders in the future = %+v, %v, want an empty last page", empty, err)
+	}
+	if server.Requests("/v2/orders") == 0 {
+		t.Error("orders endpoint was not requested")
+	}
+}
+
+func TestExchangeCode(t *testing.T) {
+	server := bigcommercetest.NewServer(bigcommercetest.DemoFixtures())
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	installation, err := bigcommerce.ExchangeCode(ctx, server.OAuthConfig(), bigcommercetest.AuthCode, "store_v2_products_read_only", "stores/"+bigcommercetest.StoreHash)
+	if err != nil {
+		t.Fatalf("ExchangeCode: %v", err)
+	}
+	if installation.StoreHash != bigcommercetest.StoreHash || installation.AccessToken != bigcommercetest.AccessToken {
+		t.Errorf("installation = %+v, want the store's access token", installation)
+	}
+
+	if _, err := bigcommerce.ExchangeCode(ctx, server.OAuthConfig(), "wrong", "", "stores/"+bigcommercetest.StoreHash); err == nil {
+		t.Error("expected an error for an invalid code")
+	}
+}
+
+func TestVerifySignedPayload(t *testing.T) {
+	cfg := bigcommerce.OAuthConfig{ClientID: "bc_client", ClientSecret: "bc_secret"}
+	sign := func(secret, audience string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+			Issuer:    "bc",
+			Subject:   "stores/" + bigcommercetest.StoreHash,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		signed, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return signed
+	}
+
+	storeHash, err := bigcommerce.VerifySignedPayload(cfg, sign(cfg.ClientSecret, cfg.ClientID))
+	if err != nil || storeHash != bigcommercetest.StoreHash {
+		t.Errorf("VerifySignedPayload = %q, %v, want %q", storeHash, err, bigcommercetest.StoreHash)
+	}
+
+	if _, err := bigcommerce.VerifySignedPayload(cfg, sign("other_secret", cfg.ClientID)); err == nil {
+		t.Error("expected an error for a payload signed with another secret")
+	}
+	if _, err := bigcommerce.VerifySignedPayload(cfg, sign(cfg.ClientSecret, "other_app")); err == nil {
+		t.Error("expected an error for a payload for another app")
+	}
+}
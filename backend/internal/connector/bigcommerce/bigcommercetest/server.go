@@ -0,0 +1,278 @@
+// Package bigcommercetest provides a fake BigCommerce API for tests. It serves the V3 catalog
+// and inventory endpoints and the V2 store and orders endpoints of one store from fixtures with
+// page/limit pagination, checks the access token, can inject errors, and answers the OAuth token
+// exchange.
+package bigcommercetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+)
+
+const (
+	// Store and credentials served by a server created with NewServer
+	StoreHash   = "abc123"
+	AccessToken = "bc_token"
+	AuthCode    = "bc_code"
+
+	defaultLimit = 50
+	maxLimit     = 250
+)
+
+// Fixtures is the data a fake store serves, in the shape of the BigCommerce API
+type Fixtures struct {
+	Store          bigcommerce.Store
+	Locations      []bigcommerce.Location
+	Products       []bigcommerce.Product
+	InventoryItems []bigcommerce.InventoryItem
+	Orders         []bigcommerce.Order
+	OrderProducts  map[int64][]bigcommerce.OrderProduct
+}
+
+// DemoFixtures returns a small store with two locations, a product without options, a product
+// with two variants and a few orders
+func DemoFixtures() *Fixtures {
+	now := time.Now().UTC().Truncate(time.Second)
+	item := func(productID, variantID int64, sku string, available ...int) bigcommerce.InventoryItem {
+		var result bigcommerce.InventoryItem
+		result.Identity.ProductID, result.Identity.VariantID, result.Identity.SKU = productID, variantID, sku
+		for i, quantity := range available {
+			result.Locations = append(result.Locations, bigcommerce.InventoryLocation{LocationID: int64(i + 1), AvailableToSell: quantity})
+		}
+		return result
+	}
+
+	return &Fixtures{
+		Store: bigcommerce.Store{ID: StoreHash, Name: "Demo Store", Currency: "USD"},
+		Locations: []bigcommerce.Location{
+			{ID: 1, Code: "WH", Label: "Warehouse", Enabled: true},
+			{ID: 2, Code: "SHOP", Label: "Shop Floor", Enabled: true},
+		},
+		Products: []bigcommerce.Product{
+			{
+				ID: 111, Name: "Ceramic Mug", Type: "physical", SKU: "MUG", IsVisible: true, InventoryTracking: "product", Price: "18",
+				Variants: []bigcommerce.Variant{{ID: 201, ProductID: 111, SKU: "MUG", CalculatedPrice: "18", CostPrice: "6"}},
+			},
+			{
+				ID: 112, Name: "Wool Beanie", Type: "physical", SKU: "BEANIE", IsVisible: true, InventoryTracking: "variant", Price: "30",
+				Variants: []bigcommerce.Variant{
+					{ID: 202, ProductID: 112, SKU: "BEANIE-GRY", CalculatedPrice: "30"},
+					{ID: 203, ProductID: 112, SKU: "BEANIE-RED", Price: "32", CalculatedPrice: "32"},
+				},
+			},
+			{
+				ID: 113, Name: "E-Book", Type: "digital", SKU: "EBOOK", IsVisible: false, InventoryTracking: "none", Price: "9",
+				Variants: []bigcommerce.Variant{{ID: 204, ProductID: 113, SKU: "EBOOK"}},
+			},
+		},
+		InventoryItems: []bigcommerce.InventoryItem{
+			item(111, 201, "MUG", 10, 4),
+			item(112, 202, "BEANIE-GRY", 3, 0),
+			item(112, 203, "BEANIE-RED", 6, 2),
+		},
+		Orders: []bigcommerce.Order{
+			{ID: 100, StatusID: 10, Status: "Completed", TotalIncTax: "48.0000", DateCreated: bigcommerce.RFC1123Time{Time: now.AddDate(0, 0, -12)}, DateModified: bigcommerce.RFC1123Time{Time: now.AddDate(0, 0, -11)}},
+			{ID: 101, StatusID: 5, Status: "Cancelled", TotalIncTax: "18.0000", DateCreated: bigcommerce.RFC1123Time{Time: now.AddDate(0, 0, -5)}, DateModified: bigcommerce.RFC1123Time{Time: now.AddDate(0, 0, -4)}},
+			{ID: 102, StatusID: 11, Status: "Awaiting Fulfillment", TotalIncTax: "32.0000", DateCreated: bigcommerce.RFC1123Time{Time: now.AddDate(0, 0, -45)}, DateModified: bigcommerce.RFC1123Time{Time: now.AddDate(0, 0, -45)}},
+		},
+		OrderProducts: map[int64][]bigcommerce.OrderProduct{
+			100: {
+				{ID: 1, ProductID: 111, VariantID: 201, SKU: "MUG", Quantity: 1, PriceIncTax: "18.0000"},
+				{ID: 2, ProductID: 112, VariantID: 202, SKU: "BEANIE-GRY", Quantity: 1, PriceIncTax: "30.0000"},
+			},
+			101: {{ID: 3, ProductID: 111, VariantID: 201, SKU: "MUG", Quantity: 1, PriceIncTax: "18.0000"}},
+			102: {{ID: 4, ProductID: 112, VariantID: 203, SKU: "BEANIE-RED", Quantity: 1, PriceIncTax: "32.0000"}},
+		},
+	}
+}
+
+// Server is a running fake BigCommerce API and OAuth server
+type Server struct {
+	*httptest.Server
+	*connectortest.Fake[Fixtures]
+}
+
+// NewServer starts a fake server serving the given fixtures as store StoreHash
+func NewServer(fixtures *Fixtures) *Server {
+	s := &Server{Fake: connectortest.NewFake(fixtures)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Client returns a client for the store that talks to this server
+func (s *Server) Client(accessToken string) *bigcommerce.Client {
+	return bigcommerce.NewClient(StoreHash, accessToken, bigcommerce.WithBaseURL(s.URL))
+}
+
+// OAuthConfig returns an app configuration whose login server is this server
+func (s *Server) OAuthConfig() bigcommerce.OAuthConfig {
+	return bigcommerce.OAuthConfig{ClientID: "bc_client", ClientSecret: "bc_secret", RedirectURL: "http://localhost/callback", LoginURL: s.URL}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/oauth2/token" {
+		s.serveToken(w, r)
+		return
+	}
+
+	endpoint, ok := strings.CutPrefix(r.URL.Path, "/stores/"+StoreHash)
+	if !ok {
+		writeError(w, http.StatusNotFound, "The route is not found")
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.Record(endpoint)
+
+	if r.Header.Get("X-Auth-Token") != AccessToken {
+		writeError(w, http.StatusUnauthorized, "Missing or invalid access token")
+		return
+	}
+
+	if status, failed := s.NextFailure(endpoint); failed {
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("X-Rate-Limit-Time-Reset-Ms", "10")
+		}
+		writeError(w, status, http.StatusText(status))
+		return
+	}
+
+	switch {
+	case endpoint == "/v2/store":
+		writeJSON(w, http.StatusOK, s.Fixtures.Store)
+	case endpoint == "/v3/inventory/locations":
+		serveV3Page(w, r, s.Fixtures.Locations)
+	case endpoint == "/v3/catalog/products":
+		serveV3Page(w, r, s.Fixtures.Products)
+	case endpoint == "/v3/inventory/items":
+		serveV3Page(w, r, s.Fixtures.InventoryItems)
+	case endpoint == "/v2/orders":
+		orders, ok := s.ordersSince(w, r)
+		if ok {
+			serveV2Page(w, r, orders)
+		}
+	case endpoint == "/v2/orders/count":
+		if orders, ok := s.ordersSince(w, r); ok {
+			writeJSON(w, http.StatusOK, map[string]int{"count": len(orders)})
+		}
+	case strings.HasPrefix(endpoint, "/v2/orders/") && strings.HasSuffix(endpoint, "/products"):
+		orderID, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(endpoint, "/v2/orders/"), "/products"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "The requested resource was not found")
+			return
+		}
+		serveV2Page(w, r, s.Fixtures.OrderProducts[orderID])
+	default:
+		writeError(w, http.StatusNotFound, "The route is not found")
+	}
+}
+
+// serveToken answers the OAuth token exchange of an app install
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	cfg := s.OAuthConfig()
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != AuthCode ||
+		r.PostForm.Get("client_id") != cfg.ClientID || r.PostForm.Get("client_secret") != cfg.ClientSecret {
+		writeError(w, http.StatusUnauthorized, "Invalid client id or client secret")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": AccessToken,
+		"scope":        r.PostForm.Get("scope"),
+		"context":      r.PostForm.Get("context"),
+		"user":         map[string]any{"id": 1, "email": "merchant@example.com"},
+	})
+}
+
+// ordersSince returns the orders created at or after the min_date_created parameter
+func (s *Server) ordersSince(w http.ResponseWriter, r *http.Request) ([]bigcommerce.Order, bool) {
+	orders := s.Fixtures.Orders
+	if value := r.URL.Query().Get("min_date_created"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "The field 'min_date_created' is invalid")
+			return nil, false
+		}
+		orders = nil
+		for _, order := range s.Fixtures.Orders {
+			if !order.DateCreated.Before(since) {
+				orders = append(orders, order)
+			}
+		}
+	}
+	return orders, true
+}
+
+// pageBounds returns the page, limit and item range requested by the page and limit parameters
+func pageBounds(r *http.Request, count int) (page, limit, start, end int, err error) {
+	page, limit = 1, defaultLimit
+	if value := r.URL.Query().Get("page"); value != "" {
+		page, _ = strconv.Atoi(value)
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, _ = strconv.Atoi(value)
+	}
+	if page < 1 || limit < 1 || limit > maxLimit {
+		return 0, 0, 0, 0, fmt.Errorf("invalid page %d or limit %d", page, limit)
+	}
+
+	start = min((page-1)*limit, count)
+	end = min(start+limit, count)
+	return page, limit, start, end, nil
+}
+
+// serveV3Page writes one page of items in the V3 {data, meta.pagination} envelope
+func serveV3Page[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	page, limit, start, end, err := pageBounds(r, len(items))
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": append([]T{}, items[start:end]...),
+		"meta": map[string]any{"pagination": map[string]int{
+			"total":        len(items),
+			"count":        end - start,
+			"per_page":     limit,
+			"current_page": page,
+			"total_pages":  (len(items) + limit - 1) / limit,
+		}},
+	})
+}
+
+// serveV2Page writes one page of items as a bare array, or 204 No Content past the last item
+func serveV2Page[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	_, _, start, end, err := pageBounds(r, len(items))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if start == end {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the V3 {status, title} format
+func writeError(w http.ResponseWriter, status int, title string) {
+	writeJSON(w, status, map[string]any{"status": status, "title": title})
+}
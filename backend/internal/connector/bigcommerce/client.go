@@ -0,0 +1,260 @@
+// Package bigcommerce reads BigCommerce stores through the V3 catalog and inventory APIs and the
+// V2 orders API, and adapts them to connector.PlatformConnector
+package bigcommerce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Default API host, requests go to <base>/stores/<store hash>/v3/...
+	defaultBaseURL = "https://api.bigcommerce.com"
+
+	// Largest page size the V2 and V3 APIs accept
+	maxLimit = 250
+)
+
+// Client is a BigCommerce API client for one store, authenticated with an app access token
+type Client struct {
+	baseURL     string
+	storeHash   string
+	accessToken string
+	httpClient  *http.Client
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithBaseURL sends requests to baseURL ("http://127.0.0.1:8091") instead of https://api.bigcommerce.com,
+// e.g. to a fake BigCommerce server
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// NewClient creates a client for the store with the given hash
+func NewClient(storeHash, accessToken string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:     defaultBaseURL,
+		storeHash:   storeHash,
+		accessToken: accessToken,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+
+	if config.Values.BigCommerce.APIBaseURL != "" {
+		WithBaseURL(config.Values.BigCommerce.APIBaseURL)(c)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError represents a non-successful response from the BigCommerce API
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bigcommerce API error: status %d: %s", e.StatusCode, e.Message)
+}
+
+// HTTPStatus implements connector.APIError
+func (e *APIError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// RetryDelay implements connector.APIError
+func (e *APIError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// pagination is the meta.pagination object of V3 list responses
+type pagination struct {
+	Total       int `json:"total"`
+	CurrentPage int `json:"current_page"`
+	TotalPages  int `json:"total_pages"`
+}
+
+// listResponse is the envelope of V3 list responses
+type listResponse[T any] struct {
+	Data []T `json:"data"`
+	Meta struct {
+		Pagination pagination `json:"pagination"`
+	} `json:"meta"`
+}
+
+// get requests path ("/v3/catalog/products") of the store and decodes the JSON response into target.
+// A 204 No Content response, which the V2 API sends for an empty list, leaves target untouched.
+// Rate limited and server error responses are retried with jittered exponential backoff.
+func (c *Client) get(ctx context.Context, path string, params url.Values, target any) error {
+	requestURL := fmt.Sprintf("%s/stores/%s%s", c.baseURL, c.storeHash, path)
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	return connector.Retry(ctx, "BigCommerce", path, func() error {
+		return c.doRequest(ctx, requestURL, target)
+	})
+}
+
+// doRequest performs a single request and converts error statuses into *APIError
+func (c *Client) doRequest(ctx context.Context, requestURL string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("X-Auth-Token", c.accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+		var errorBody struct {
+			Title string `json:"title"`
+		}
+		if json.Unmarshal(body, &errorBody) == nil && errorBody.Title != "" {
+			apiErr.Message = errorBody.Title
+		}
+		// The rate limit window resets after this many milliseconds
+		if ms, err := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Time-Reset-Ms")); err == nil {
+			apiErr.RetryAfter = time.Duration(ms) * time.Millisecond
+		}
+		return apiErr
+	}
+
+	if resp.StatusCode == http.StatusNoContent || len(body) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return errors.Wrap(err, "failed to unmarshal response")
+	}
+	return nil
+}
+
+// pageParams returns the parameters of one page of a listing
+func pageParams(page, limit int) url.Values {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+	return params
+}
+
+// GetStore retrieves the store information, including its default currency
+func (c *Client) GetStore(ctx context.Context) (*Store, error) {
+	var store Store
+	if err := c.get(ctx, "/v2/store", nil, &store); err != nil {
+		return nil, errors.Wrap(err, "failed to get store")
+	}
+	return &store, nil
+}
+
+// GetLocations retrieves one page of inventory locations
+func (c *Client) GetLocations(ctx context.Context, page, limit int) ([]Location, *pagination, error) {
+	var resp listResponse[Location]
+	if err := c.get(ctx, "/v3/inventory/locations", pageParams(page, limit), &resp); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get locations")
+	}
+	return resp.Data, &resp.Meta.Pagination, nil
+}
+
+// GetProducts retrieves one page of products with their variants, in ascending ID order
+func (c *Client) GetProducts(ctx context.Context, page, limit int) ([]Product, *pagination, error) {
+	params := pageParams(page, limit)
+	params.Set("include", "variants")
+	params.Set("sort", "id")
+	params.Set("direction", "asc")
+
+	var resp listResponse[Product]
+	if err := c.get(ctx, "/v3/catalog/products", params, &resp); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get products")
+	}
+	return resp.Data, &resp.Meta.Pagination, nil
+}
+
+// GetInventoryItems retrieves one page of variants with their inventory at every location
+func (c *Client) GetInventoryItems(ctx context.Context, page, limit int) ([]InventoryItem, *pagination, error) {
+	var resp listResponse[InventoryItem]
+	if err := c.get(ctx, "/v3/inventory/items", pageParams(page, limit), &resp); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get inventory items")
+	}
+	return resp.Data, &resp.Meta.Pagination, nil
+}
+
+// ordersParams returns the parameters of the orders created at or after the given time
+func ordersParams(since time.Time) url.Values {
+	params := url.Values{}
+	params.Set("min_date_created", since.UTC().Format(time.RFC3339))
+	return params
+}
+
+// GetOrders retrieves one page of the orders created since the given time, in ascending ID order.
+// The V2 API reports no totals; a page shorter than limit is the last one.
+func (c *Client) GetOrders(ctx context.Context, since time.Time, page, limit int) ([]Order, error) {
+	params := ordersParams(since)
+	for key, values := range pageParams(page, limit) {
+		params[key] = values
+	}
+	params.Set("sort", "id:asc")
+
+	var orders []Order
+	if err := c.get(ctx, "/v2/orders", params, &orders); err != nil {
+		return nil, errors.Wrap(err, "failed to get orders")
+	}
+	return orders, nil
+}
+
+// GetOrderProducts retrieves the line items of an order
+func (c *Client) GetOrderProducts(ctx context.Context, orderID int64) ([]OrderProduct, error) {
+	var products []OrderProduct
+	for page := 1; ; page++ {
+		var batch []OrderProduct
+		if err := c.get(ctx, fmt.Sprintf("/v2/orders/%d/products", orderID), pageParams(page, maxLimit), &batch); err != nil {
+			return nil, errors.Wrapf(err, "failed to get products of order %d", orderID)
+		}
+		products = append(products, batch...)
+		if len(batch) < maxLimit {
+			return products, nil
+		}
+	}
+}
+
+// CountOrders retrieves the number of orders created since the given time
+func (c *Client) CountOrders(ctx context.Context, since time.Time) (int, error) {
+	var resp struct {
+		Count int `json:"count"`
+	}
+	if err := c.get(ctx, "/v2/orders/count", ordersParams(since), &resp); err != nil {
+		return 0, errors.Wrap(err, "failed to count orders")
+	}
+	return resp.Count, nil
+}
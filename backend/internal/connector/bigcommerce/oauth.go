@@ -0,0 +1,115 @@
+package bigcommerce
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// contextPrefix prefixes the store hash in the context of OAuth responses and signed payloads
+const contextPrefix = "stores/"
+
+// OAuthConfig holds the credentials of the BigCommerce app
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	// LoginURL is the OAuth server, https://login.bigcommerce.com
+	LoginURL string
+}
+
+// Installation is the result of exchanging the code of an app install for an access token
+type Installation struct {
+	StoreHash   string
+	AccessToken string
+	Scope       string
+}
+
+// StoreHash extracts the store hash from a context ("stores/abc123")
+func StoreHash(context string) (string, error) {
+	hash, ok := strings.CutPrefix(context, contextPrefix)
+	if !ok || hash == "" || strings.Contains(hash, "/") {
+		return "", errors.Errorf("invalid bigcommerce context %q", context)
+	}
+	return hash, nil
+}
+
+// ExchangeCode exchanges the code BigCommerce sends to the auth callback when the app is
+// installed for the store's permanent access token
+func ExchangeCode(ctx context.Context, cfg OAuthConfig, code, scope, storeContext string) (*Installation, error) {
+	form := url.Values{}
+	form.Set("client_id", cfg.ClientID)
+	form.Set("client_secret", cfg.ClientSecret)
+	form.Set("code", code)
+	form.Set("scope", scope)
+	form.Set("grant_type", "authorization_code")
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("context", storeContext)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(cfg.LoginURL, "/")+"/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute token request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read token response")
+	}
+	if resp.StatusCode >= 400 {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		Scope       string `json:"scope"`
+		Context     string `json:"context"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal token response")
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, errors.New("token response has no access token")
+	}
+
+	storeHash, err := StoreHash(tokenResp.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Installation{StoreHash: storeHash, AccessToken: tokenResp.AccessToken, Scope: tokenResp.Scope}, nil
+}
+
+// VerifySignedPayload verifies the signed_payload_jwt BigCommerce sends to the load and uninstall
+// callbacks, which is signed with the app's client secret, and returns the store hash
+func VerifySignedPayload(cfg OAuthConfig, signedPayload string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(signedPayload, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(cfg.ClientSecret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid signed payload")
+	}
+
+	return StoreHash(claims.Subject)
+}
@@ -0,0 +1,143 @@
+package bigcommerce
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+// V2 order status IDs
+const (
+	orderStatusIncomplete         = 0
+	orderStatusPending            = 1
+	orderStatusShipped            = 2
+	orderStatusPartiallyShipped   = 3
+	orderStatusRefunded           = 4
+	orderStatusCancelled          = 5
+	orderStatusDeclined           = 6
+	orderStatusAwaitingPayment    = 7
+	orderStatusCompleted          = 10
+	orderStatusManualVerification = 12
+	orderStatusPartiallyRefunded  = 14
+)
+
+// toLocation converts an inventory location
+func toLocation(location Location) connector.Location {
+	result := connector.Location{
+		ExternalID: connector.FormatID(location.ID),
+		Name:       location.Label,
+		Active:     location.Enabled,
+	}
+	if location.Address != nil {
+		result.Address = location.Address.Address1
+		result.Country = location.Address.CountryCode
+		result.Province = location.Address.State
+	}
+	return result
+}
+
+// toProduct converts a catalog product. Each variant, including the base variant of a product
+// without options, is its own inventory item.
+func toProduct(product Product) connector.Product {
+	status := core.ProductStatusDraft
+	if product.IsVisible {
+		status = core.ProductStatusActive
+	}
+
+	result := connector.Product{
+		ExternalID:  connector.FormatID(product.ID),
+		Title:       product.Name,
+		Handle:      strings.Trim(product.CustomURL.URL, "/"),
+		ProductType: product.Type,
+		Status:      status,
+	}
+
+	for _, variant := range product.Variants {
+		// A variant without a price of its own sells at the product's price
+		price := variant.CalculatedPrice
+		if price == "" {
+			price = variant.Price
+		}
+		if price == "" {
+			price = product.Price
+		}
+
+		item := &connector.InventoryItem{
+			ExternalID: connector.FormatID(variant.ID),
+			SKU:        variant.SKU,
+			Tracked:    product.InventoryTracking != "none",
+			Cost:       variant.CostPrice.String(),
+		}
+		result.Variants = append(result.Variants, connector.Variant{
+			ExternalID:      connector.FormatID(variant.ID),
+			SKU:             variant.SKU,
+			Price:           price.String(),
+			InventoryItemID: item.ExternalID,
+			InventoryItem:   item,
+		})
+	}
+
+	return result
+}
+
+// toInventoryLevels converts the inventory of a variant at each of its locations
+func toInventoryLevels(item InventoryItem) []connector.InventoryLevel {
+	levels := make([]connector.InventoryLevel, 0, len(item.Locations))
+	for _, location := range item.Locations {
+		levels = append(levels, connector.InventoryLevel{
+			InventoryItemID: connector.FormatID(item.Identity.VariantID),
+			LocationID:      connector.FormatID(location.LocationID),
+			Available:       location.AvailableToSell,
+		})
+	}
+	return levels
+}
+
+// toOrder converts a V2 order and its line items. BigCommerce has a single order status, which
+// is mapped onto the financial and fulfillment statuses of the core schema.
+func toOrder(order Order, products []OrderProduct) connector.Order {
+	financialStatus := core.FinancialStatusPaid
+	fulfillmentStatus := core.FulfillmentStatusNull
+	var cancelledAt *time.Time
+
+	switch order.StatusID {
+	case orderStatusIncomplete, orderStatusPending, orderStatusAwaitingPayment, orderStatusManualVerification:
+		financialStatus = core.FinancialStatusPending
+	case orderStatusShipped, orderStatusCompleted:
+		fulfillmentStatus = core.FulfillmentStatusFulfilled
+	case orderStatusPartiallyShipped:
+		fulfillmentStatus = core.FulfillmentStatusPartial
+	case orderStatusRefunded:
+		financialStatus = core.FinancialStatusRefunded
+	case orderStatusPartiallyRefunded:
+		financialStatus = core.FinancialStatusPartiallyRefunded
+	case orderStatusCancelled, orderStatusDeclined:
+		financialStatus = core.FinancialStatusVoided
+		modified := order.DateModified.Time
+		cancelledAt = &modified
+	}
+
+	result := connector.Order{
+		ExternalID:        connector.FormatID(order.ID),
+		CreatedAt:         order.DateCreated.Time,
+		FinancialStatus:   financialStatus,
+		FulfillmentStatus: fulfillmentStatus,
+		TotalPrice:        order.TotalIncTax,
+		CancelledAt:       cancelledAt,
+	}
+
+	for _, product := range products {
+		result.LineItems = append(result.LineItems, connector.OrderLineItem{
+			ExternalID: connector.FormatID(product.ID),
+			ProductID:  connector.FormatID(product.ProductID),
+			VariantID:  connector.FormatID(product.VariantID),
+			SKU:        product.SKU,
+			Quantity:   product.Quantity,
+			Price:      product.PriceIncTax,
+		})
+	}
+
+	return result
+}
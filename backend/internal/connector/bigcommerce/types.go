@@ -0,0 +1,125 @@
+package bigcommerce
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Store represents the store information from the V2 store API
+type Store struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+// Location represents an inventory location from the V3 inventory API
+type Location struct {
+	ID          int64  `json:"id"`
+	Code        string `json:"code"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Address     *struct {
+		Address1    string `json:"address1"`
+		City        string `json:"city"`
+		State       string `json:"state"`
+		Zip         string `json:"zip"`
+		CountryCode string `json:"country_code"`
+	} `json:"address,omitempty"`
+}
+
+// Product represents a product from the V3 catalog API, requested with include=variants
+type Product struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	SKU       string `json:"sku"`
+	IsVisible bool   `json:"is_visible"`
+	// none, product (the base variant holds the stock) or variant
+	InventoryTracking string      `json:"inventory_tracking"`
+	Price             json.Number `json:"price,omitempty"`
+	CustomURL         struct {
+		URL string `json:"url"`
+	} `json:"custom_url"`
+	Variants []Variant `json:"variants"`
+}
+
+// Variant represents a variant of a product from the V3 catalog API. Every product has at
+// least its base variant.
+type Variant struct {
+	ID              int64       `json:"id"`
+	ProductID       int64       `json:"product_id"`
+	SKU             string      `json:"sku"`
+	Price           json.Number `json:"price,omitempty"`
+	CalculatedPrice json.Number `json:"calculated_price,omitempty"`
+	CostPrice       json.Number `json:"cost_price,omitempty"`
+	InventoryLevel  int         `json:"inventory_level"`
+}
+
+// InventoryItem represents a variant with its inventory at every location from the V3 inventory API
+type InventoryItem struct {
+	Identity struct {
+		SKU       string `json:"sku"`
+		VariantID int64  `json:"variant_id"`
+		ProductID int64  `json:"product_id"`
+	} `json:"identity"`
+	Locations []InventoryLocation `json:"locations"`
+}
+
+// InventoryLocation represents the inventory of a variant at one location
+type InventoryLocation struct {
+	LocationID           int64 `json:"location_id"`
+	AvailableToSell      int   `json:"available_to_sell"`
+	TotalInventoryOnhand int   `json:"total_inventory_onhand"`
+}
+
+// Order represents an order from the V2 orders API
+type Order struct {
+	ID           int64       `json:"id"`
+	StatusID     int         `json:"status_id"`
+	Status       string      `json:"status"`
+	TotalIncTax  string      `json:"total_inc_tax"`
+	DateCreated  RFC1123Time `json:"date_created"`
+	DateModified RFC1123Time `json:"date_modified"`
+}
+
+// OrderProduct represents a line item of an order from the V2 orders API
+type OrderProduct struct {
+	ID          int64  `json:"id"`
+	ProductID   int64  `json:"product_id"`
+	VariantID   int64  `json:"variant_id"`
+	SKU         string `json:"sku"`
+	Quantity    int    `json:"quantity"`
+	PriceIncTax string `json:"price_inc_tax"`
+}
+
+// RFC1123Time is a V2 API timestamp ("Tue, 20 Nov 2012 00:00:00 +0000")
+type RFC1123Time struct {
+	time.Time
+}
+
+// MarshalJSON writes the timestamp in the V2 format, a zero timestamp as an empty string
+func (t RFC1123Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return json.Marshal("")
+	}
+	return json.Marshal(t.UTC().Format(time.RFC1123Z))
+}
+
+// UnmarshalJSON parses the timestamp, an empty or null timestamp is left zero
+func (t *RFC1123Time) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC1123Z, value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timestamp %s", data)
+	}
+	t.Time = parsed.UTC()
+	return nil
+}
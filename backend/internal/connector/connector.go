@@ -0,0 +1,102 @@
+// Package connector decouples the sync pipeline from the commerce platforms it reads from.
+//
+// Each platform is implemented as an adapter that satisfies PlatformConnector and emits
+// platform-neutral records keyed by the platform's own IDs. The sync manager picks the
+// adapter of an integration by its platform_type through a Registry.
+package connector
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/pkg/errors"
+)
+
+// ErrUnsupportedPlatform is returned when no connector is registered for an integration's platform type
+var ErrUnsupportedPlatform = errors.New("connector: unsupported platform")
+
+// Page is one page of records. NextCursor is opaque to callers and empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// PlatformConnector reads the catalog, stock and orders of one store on a platform.
+//
+// Every method returns a single page starting at cursor, an empty cursor being the first page.
+// Cursors are persisted in sync checkpoints, so an adapter must accept a cursor it returned in
+// an earlier process for as long as the platform honours it.
+type PlatformConnector interface {
+	// Platform returns the platform type the connector reads from
+	Platform() core.PlatformType
+
+	// ListLocations lists the stock locations (warehouses, stores, sources) of the store
+	ListLocations(ctx context.Context, cursor string) (*Page[Location], error)
+
+	// ListProducts lists products together with their variants and inventory items
+	ListProducts(ctx context.Context, cursor string) (*Page[Product], error)
+
+	// ListInventoryLevels lists the available quantity of every inventory item at every location
+	ListInventoryLevels(ctx context.Context, cursor string) (*Page[InventoryLevel], error)
+
+	// ListOrders lists the orders created at or after since
+	ListOrders(ctx context.Context, since time.Time, cursor string) (*Page[Order], error)
+}
+
+// Counter is implemented by connectors that can count records up front, which lets sync
+// progress events carry an ETA. Connectors without it simply report no ETA.
+type Counter interface {
+	CountLocations(ctx context.Context) (int, error)
+	CountProducts(ctx context.Context) (int, error)
+	CountOrders(ctx context.Context, since time.Time) (int, error)
+}
+
+// CurrencyReader is implemented by connectors that can read the store currency, the currency of
+// the prices, costs and order totals they list. The sync records it on the integration.
+type CurrencyReader interface {
+	Currency(ctx context.Context) (string, error)
+}
+
+// Factory creates the connector of an integration, loading whatever credentials its platform needs
+type Factory func(ctx context.Context, integration core.PlatformIntegration) (PlatformConnector, error)
+
+// Registry maps platform types to the factories of their connectors
+type Registry struct {
+	factories map[core.PlatformType]Factory
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[core.PlatformType]Factory)}
+}
+
+// Register makes the factory the connector of the platform type, replacing any earlier registration
+func (r *Registry) Register(platform core.PlatformType, factory Factory) {
+	r.factories[platform] = factory
+}
+
+// Platforms returns the registered platform types in alphabetical order
+func (r *Registry) Platforms() []core.PlatformType {
+	platforms := make([]core.PlatformType, 0, len(r.factories))
+	for platform := range r.factories {
+		platforms = append(platforms, platform)
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i] < platforms[j] })
+	return platforms
+}
+
+// Connect creates the connector of the integration's platform
+func (r *Registry) Connect(ctx context.Context, integration core.PlatformIntegration) (PlatformConnector, error) {
+	factory, ok := r.factories[integration.PlatformType]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnsupportedPlatform, "platform %s", integration.PlatformType)
+	}
+
+	conn, err := factory(ctx, integration)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to %s", integration.PlatformType)
+	}
+	return conn, nil
+}
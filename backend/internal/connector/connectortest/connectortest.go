@@ -0,0 +1,90 @@
+// Package connectortest holds what the tests of the platform connectors share: the state of
+// their fake platform APIs and ListAll, which reads every page of a list method.
+package connectortest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+)
+
+// Fake is the state of a fake platform API: the fixtures it serves, the requests each endpoint
+// received and the failures injected into them. Endpoints are request paths relative to the
+// API root. The server holds the embedded mutex while it serves a request, Record, NextFailure
+// and Fixtures need it held.
+type Fake[F any] struct {
+	sync.Mutex
+	Fixtures *F
+
+	failures map[string][]int
+	requests map[string]int
+}
+
+// NewFake returns the state of a fake API serving the given fixtures
+func NewFake[F any](fixtures *F) *Fake[F] {
+	return &Fake[F]{
+		Fixtures: fixtures,
+		failures: map[string][]int{},
+		requests: map[string]int{},
+	}
+}
+
+// FailNext makes the next times requests to endpoint fail with status
+func (f *Fake[F]) FailNext(endpoint string, status, times int) {
+	f.Lock()
+	defer f.Unlock()
+
+	for range times {
+		f.failures[endpoint] = append(f.failures[endpoint], status)
+	}
+}
+
+// Requests returns how many requests endpoint has received, including failed ones
+func (f *Fake[F]) Requests(endpoint string) int {
+	f.Lock()
+	defer f.Unlock()
+
+	return f.requests[endpoint]
+}
+
+// UpdateFixtures changes the served data
+func (f *Fake[F]) UpdateFixtures(update func(*F)) {
+	f.Lock()
+	defer f.Unlock()
+
+	update(f.Fixtures)
+}
+
+// Record counts a request to endpoint
+func (f *Fake[F]) Record(endpoint string) {
+	f.requests[endpoint]++
+}
+
+// NextFailure takes the status of the next failure injected into endpoint, false when there
+// is none
+func (f *Fake[F]) NextFailure(endpoint string) (int, bool) {
+	pending := f.failures[endpoint]
+	if len(pending) == 0 {
+		return 0, false
+	}
+	f.failures[endpoint] = pending[1:]
+	return pending[0], true
+}
+
+// ListAll reads every page of a connector list method, failing the test on an error
+func ListAll[T any](t *testing.T, list func(cursor string) (*connector.Page[T], error)) []T {
+	t.Helper()
+
+	var items []T
+	for cursor := ""; ; {
+		page, err := list(cursor)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		items = append(items, page.Items...)
+		if cursor = page.NextCursor; cursor == "" {
+			return items
+		}
+	}
+}
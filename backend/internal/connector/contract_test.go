@@ -0,0 +1,226 @@
+package connector_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce/bigcommercetest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento/magentotest"
+	shopifyconnector "github.com/ConradKurth/forecasting/backend/internal/connector/shopify"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square/squaretest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce/woocommercetest"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/internal/shopify/shopifytest"
+	"github.com/pkg/errors"
+)
+
+const shopifyToken = "shpat_test"
+
+// fakeAPI is the part of a fake platform API the contract drives
+type fakeAPI interface {
+	FailNext(endpoint string, status, times int)
+	Requests(endpoint string) int
+}
+
+// contractCase is a platform connector reading a fake store that serves the platform's demo fixtures
+type contractCase struct {
+	platform core.PlatformType
+	// connect starts the fake store and returns a connector reading it with valid or wrong credentials
+	connect func(t *testing.T, authorized bool) (connector.PlatformConnector, fakeAPI)
+	// productsEndpoint is the endpoint the first page of ListProducts requests
+	productsEndpoint string
+	currency         string
+	// recentOrders is the number of demo orders created in the last 20 days
+	recentOrders int
+}
+
+var contractCases = []contractCase{
+	{
+		platform: core.PlatformTypeShopify,
+		connect: func(t *testing.T, authorized bool) (connector.PlatformConnector, fakeAPI) {
+			server := shopifytest.NewServer(shopifytest.DemoFixtures(), shopifytest.WithAccessToken(shopifyToken))
+			t.Cleanup(server.Close)
+			token := shopifyToken
+			if !authorized {
+				token = "shpat_revoked"
+			}
+			return shopifyconnector.New(server.ShopifyClient(token)), server
+		},
+		productsEndpoint: "/products.json",
+		currency:         "USD",
+		recentOrders:     4,
+	},
+	{
+		platform: core.PlatformTypeWoocommerce,
+		connect: func(t *testing.T, authorized bool) (connector.PlatformConnector, fakeAPI) {
+			server := woocommercetest.NewServer(woocommercetest.DemoFixtures())
+			t.Cleanup(server.Close)
+			secret := woocommercetest.ConsumerSecret
+			if !authorized {
+				secret = "cs_wrong"
+			}
+			return woocommerce.New(server.Client(woocommercetest.ConsumerKey, secret)), server
+		},
+		productsEndpoint: "/products",
+		currency:         "EUR",
+		recentOrders:     2,
+	},
+	{
+		platform: core.PlatformTypeBigcommerce,
+		connect: func(t *testing.T, authorized bool) (connector.PlatformConnector, fakeAPI) {
+			server := bigcommercetest.NewServer(bigcommercetest.DemoFixtures())
+			t.Cleanup(server.Close)
+			token := bigcommercetest.AccessToken
+			if !authorized {
+				token = "revoked"
+			}
+			return bigcommerce.New(server.Client(token)), server
+		},
+		productsEndpoint: "/v3/catalog/products",
+		currency:         "USD",
+		recentOrders:     2,
+	},
+	{
+		platform: core.PlatformTypeSquare,
+		connect: func(t *testing.T, authorized bool) (connector.PlatformConnector, fakeAPI) {
+			server := squaretest.NewServer(squaretest.DemoFixtures())
+			t.Cleanup(server.Close)
+			token := squaretest.AccessToken
+			if !authorized {
+				token = "revoked"
+			}
+			return square.New(server.Client(token)), server
+		},
+		productsEndpoint: "/v2/catalog/list",
+		currency:         "USD",
+		recentOrders:     3,
+	},
+	{
+		platform: core.PlatformTypeMagento,
+		connect: func(t *testing.T, authorized bool) (connector.PlatformConnector, fakeAPI) {
+			server := magentotest.NewServer(magentotest.DemoFixtures())
+			t.Cleanup(server.Close)
+			token := magentotest.AccessToken
+			if !authorized {
+				token = "revoked"
+			}
+			return magento.New(server.Client(token)), server
+		},
+		productsEndpoint: "/products",
+		currency:         "USD",
+		recentOrders:     3,
+	},
+}
+
+func TestConnectorContract(t *testing.T) {
+	for _, tc := range contractCases {
+		t.Run(string(tc.platform), func(t *testing.T) {
+			t.Run("lists every page", func(t *testing.T) {
+				conn, _ := tc.connect(t, true)
+				ctx := context.Background()
+
+				if conn.Platform() != tc.platform {
+					t.Errorf("Platform() = %s, want %s", conn.Platform(), tc.platform)
+				}
+				locations := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Location], error) {
+					return conn.ListLocations(ctx, cursor)
+				})
+				products := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Product], error) {
+					return conn.ListProducts(ctx, cursor)
+				})
+				levels := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.InventoryLevel], error) {
+					return conn.ListInventoryLevels(ctx, cursor)
+				})
+				if len(locations) == 0 || len(products) == 0 || len(levels) == 0 {
+					t.Errorf("listed %d locations, %d products and %d inventory levels, want some of each", len(locations), len(products), len(levels))
+				}
+			})
+
+			t.Run("lists orders since", func(t *testing.T) {
+				conn, _ := tc.connect(t, true)
+				ctx := context.Background()
+
+				since := time.Now().AddDate(0, 0, -20)
+				orders := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Order], error) {
+					return conn.ListOrders(ctx, since, cursor)
+				})
+				if len(orders) != tc.recentOrders {
+					t.Errorf("listed %d orders, want %d", len(orders), tc.recentOrders)
+				}
+				for _, order := range orders {
+					if order.CreatedAt.Before(since) {
+						t.Errorf("order %s created %s, before %s", order.ExternalID, order.CreatedAt, since)
+					}
+				}
+
+				if counter, ok := conn.(connector.Counter); ok {
+					count, err := counter.CountOrders(ctx, since)
+					if err != nil || count != len(orders) {
+						t.Errorf("CountOrders = %d, %v, want %d", count, err, len(orders))
+					}
+				}
+			})
+
+			t.Run("reads the currency", func(t *testing.T) {
+				conn, _ := tc.connect(t, true)
+
+				reader, ok := conn.(connector.CurrencyReader)
+				if !ok {
+					t.Fatal("connector does not read the store currency")
+				}
+				currency, err := reader.Currency(context.Background())
+				if err != nil || currency != tc.currency {
+					t.Errorf("Currency() = %q %v, want %s", currency, err, tc.currency)
+				}
+			})
+
+			t.Run("wrong credentials", func(t *testing.T) {
+				conn, _ := tc.connect(t, false)
+
+				_, err := conn.ListProducts(context.Background(), "")
+				if !errors.Is(err, connector.ErrUnauthorized) {
+					t.Errorf("got %v, want an error matching %v", err, connector.ErrUnauthorized)
+				}
+			})
+
+			errorClasses := []struct {
+				status int
+				want   error
+			}{
+				{status: http.StatusForbidden, want: connector.ErrUnauthorized},
+				{status: http.StatusNotFound, want: connector.ErrNotFound},
+			}
+			for _, class := range errorClasses {
+				t.Run(http.StatusText(class.status), func(t *testing.T) {
+					conn, server := tc.connect(t, true)
+					server.FailNext(tc.productsEndpoint, class.status, 1)
+
+					_, err := conn.ListProducts(context.Background(), "")
+					if !errors.Is(err, class.want) {
+						t.Errorf("got %v, want an error matching %v", err, class.want)
+					}
+				})
+			}
+
+			t.Run("rate limited is retried", func(t *testing.T) {
+				conn, server := tc.connect(t, true)
+				server.FailNext(tc.productsEndpoint, http.StatusTooManyRequests, 1)
+
+				if _, err := conn.ListProducts(context.Background(), ""); err != nil {
+					t.Fatalf("ListProducts: %v", err)
+				}
+				if got := server.Requests(tc.productsEndpoint); got != 2 {
+					t.Errorf("products requested %d times, want 2", got)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package connector
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors for the classes of platform API failures callers can branch on, whatever the platform.
+// Use errors.Is against these; adapters tag their API errors with WithStatus.
+var (
+	ErrRateLimited  = errors.New("connector: rate limited")
+	ErrUnauthorized = errors.New("connector: unauthorized")
+	ErrNotFound     = errors.New("connector: not found")
+	ErrServer       = errors.New("connector: server error")
+)
+
+// statusError tags an API client error with the HTTP status code that caused it
+type statusError struct {
+	err        error
+	statusCode int
+}
+
+// WithStatus tags err with the HTTP status code of the failed request, so that it matches the
+// error class of that status. The message and the wrapped chain of err are kept as they are.
+func WithStatus(err error, statusCode int) error {
+	if err == nil {
+		return nil
+	}
+	return &statusError{err: err, statusCode: statusCode}
+}
+
+// Error implements the error interface
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the tagged error
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the status code belongs to the given error class
+func (e *statusError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.statusCode == http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return e.statusCode == http.StatusUnauthorized || e.statusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.statusCode == http.StatusNotFound
+	case ErrServer:
+		return e.statusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
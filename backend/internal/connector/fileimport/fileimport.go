@@ -0,0 +1,144 @@
+// Package fileimport reads the catalog, stock and sales of shops without an API integration
+// from CSV and Excel (XLSX) files.
+//
+// Unlike the other connectors it does not crawl a platform: files are pushed to it, parsed and
+// validated as a whole, and converted into the same platform-neutral records the sync pipeline
+// writes. Each file holds one kind of data:
+//
+//   - products: one row per variant; rows sharing a handle are the variants of one product
+//   - inventory: the available quantity of a SKU at a location
+//   - sales: one row per order line; rows sharing an order ID are one order
+//
+// Records are keyed by the shop's own identifiers (SKUs, location names, order IDs) within a
+// namespace, so importing the same file again updates the rows it created instead of adding new ones.
+package fileimport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/pkg/errors"
+)
+
+// Kind is the kind of data a file holds
+type Kind string
+
+const (
+	KindProducts  Kind = "products"
+	KindInventory Kind = "inventory"
+	KindSales     Kind = "sales"
+)
+
+// Most validation errors reported for a file, the rest of a badly broken file is not checked
+const maxErrors = 100
+
+var (
+	// ErrUnsupportedFormat is returned for files that are neither CSV nor XLSX
+	ErrUnsupportedFormat = errors.New("fileimport: unsupported file format, upload a .csv or .xlsx file")
+
+	// ErrInvalidFile is returned for files that cannot be read as a table at all
+	ErrInvalidFile = errors.New("fileimport: invalid file")
+)
+
+// ParseKind parses the kind of an import
+func ParseKind(value string) (Kind, error) {
+	switch kind := Kind(strings.ToLower(strings.TrimSpace(value))); kind {
+	case KindProducts, KindInventory, KindSales:
+		return kind, nil
+	}
+	return "", errors.Errorf("unknown import type %q, expected products, inventory or sales", value)
+}
+
+// ValidationError is a problem with one row, or with the header when Line is the header's line
+type ValidationError struct {
+	Line    int    `json:"line"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// ValidationErrors are the problems found in a file. A file with any of them is not imported.
+type ValidationErrors []ValidationError
+
+// Error implements the error interface
+func (e ValidationErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no validation errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0].Error(), len(e)-1)
+}
+
+// Records are the records parsed from a file
+type Records struct {
+	Kind            Kind
+	Rows            int
+	Locations       []connector.Location
+	Products        []connector.Product
+	InventoryLevels []connector.InventoryLevel
+	Orders          []connector.Order
+
+	// References are the SKUs inventory and sales rows refer to, which must have been imported
+	// with a products file. Each SKU is listed once, with the first line referring to it.
+	References []Reference
+}
+
+// Reference is a SKU referred to by a row
+type Reference struct {
+	Line int
+	SKU  string
+
+	// ExternalID of the variant and inventory item of the SKU
+	ExternalID string
+}
+
+// Parse validates the rows of a table and converts them into records, keyed within namespace.
+// It returns ValidationErrors listing every invalid row, up to a limit.
+func Parse(kind Kind, table *Table, namespace string) (*Records, error) {
+	p := &parser{table: table, namespace: namespace, records: &Records{Kind: kind}}
+
+	var columns []column
+	switch kind {
+	case KindProducts:
+		columns = productColumns
+	case KindInventory:
+		columns = inventoryColumns
+	case KindSales:
+		columns = salesColumns
+	default:
+		return nil, errors.Errorf("unknown import type %q", kind)
+	}
+	if !p.mapColumns(columns) {
+		return nil, p.errors
+	}
+
+	switch kind {
+	case KindProducts:
+		p.parseProducts()
+	case KindInventory:
+		p.parseInventory()
+	case KindSales:
+		p.parseSales()
+	}
+
+	if len(p.errors) > 0 {
+		return nil, p.errors
+	}
+	return p.records, nil
+}
+
+// externalID returns the external ID of a record keyed by the shop's own identifier. External
+// IDs are unique across integrations, so keys are scoped to the namespace of the import.
+func externalID(namespace, key string) string {
+	return namespace + ":" + key
+}
@@ -0,0 +1,253 @@
+package fileimport_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/fileimport"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/pkg/errors"
+)
+
+const namespace = "shop"
+
+// parse reads and parses a file
+func parse(t *testing.T, kind fileimport.Kind, filename, content string) (*fileimport.Records, error) {
+	t.Helper()
+
+	table, err := fileimport.ReadTable(filename, strings.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return fileimport.Parse(kind, table, namespace)
+}
+
+// validationErrors returns the validation errors of err, failing when it has none
+func validationErrors(t *testing.T, err error) fileimport.ValidationErrors {
+	t.Helper()
+
+	var validationErrors fileimport.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("got %v, want validation errors", err)
+	}
+	return validationErrors
+}
+
+func TestParseProductsGroupsVariantsByHandle(t *testing.T) {
+	records, err := parse(t, fileimport.KindProducts, "products.csv", "\xef\xbb\xbf"+`SKU,Title,Handle,Price,Cost per item,Type,Status,Tracked,Barcode
+TEE-S,Logo Tee,logo-tee,19.5,7,Apparel,,,5012345678900
+TEE-L,Logo Tee,logo-tee,21,,Apparel,,no,
+
+MUG-01,Enamel Mug (Blue),,12.00,4.25,,draft,yes,
+`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if records.Rows != 3 || len(records.Products) != 2 {
+		t.Fatalf("records = %+v, want 3 rows of 2 products", records)
+	}
+	tee, mug := records.Products[0], records.Products[1]
+	if tee.ExternalID != "shop:logo-tee" || tee.ProductType != "Apparel" || tee.Status != core.ProductStatusActive || len(tee.Variants) != 2 {
+		t.Errorf("tee = %+v, want an active product with two variants", tee)
+	}
+	small, large := tee.Variants[0], tee.Variants[1]
+	if small.ExternalID != "shop:TEE-S" || small.InventoryItemID != "shop:TEE-S" || small.Price != "19.50" || small.InventoryItem.Cost != "7.00" || !small.InventoryItem.Tracked || small.Barcode != "5012345678900" {
+		t.Errorf("small variant = %+v, item %+v", small, small.InventoryItem)
+	}
+	if large.Barcode != "" || large.InventoryItem.Cost != "" || large.InventoryItem.Tracked {
+		t.Errorf("large variant = %+v, item %+v, want no barcode or cost and untracked", large, large.InventoryItem)
+	}
+	if mug.Handle != "enamel-mug-blue" || mug.Status != core.ProductStatusDraft || mug.Variants[0].InventoryItem.Cost != "4.25" {
+		t.Errorf("mug = %+v, want a handle derived from the title", mug)
+	}
+}
+
+func TestParseReportsEveryInvalidRowWithItsLine(t *testing.T) {
+	_, err := parse(t, fileimport.KindProducts, "products.csv", `sku,title,price,status
+A-1,Widget,5,
+A-1,Widget,6,
+C-1,Widget,abc,
+,Gadget,1/3,retired
+B-1,!!!,2,
+`)
+
+	got := validationErrors(t, err)
+	want := fileimport.ValidationErrors{
+		{Line: 3, Column: "sku", Message: `"A-1" is already listed on line 2`},
+		{Line: 4, Column: "price", Message: `"abc" is not a valid amount, use a plain number such as 12.50`},
+		{Line: 5, Column: "sku", Message: "is required"},
+		{Line: 5, Column: "price", Message: `"1/3" is not a valid amount, use a plain number such as 12.50`},
+		{Line: 5, Column: "status", Message: `"retired" is not a product status, use active, draft or archived`},
+		{Line: 6, Column: "handle", Message: "is required when the title has no letters or digits"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("errors =\n%+v\nwant\n%+v", []fileimport.ValidationError(got), []fileimport.ValidationError(want))
+	}
+}
+
+func TestParseReportsMissingColumnsOnTheHeaderLine(t *testing.T) {
+	_, err := parse(t, fileimport.KindSales, "sales.csv", "\n\norder,sku,qty\n1001,A-1,1\n")
+
+	got := validationErrors(t, err)
+	if len(got) != 2 || got[0].Line != 3 || got[0].Column != "date" || got[1].Column != "price" {
+		t.Errorf("errors = %v, want the missing date and price columns on line 3", got)
+	}
+}
+
+func TestParseInventoryReadsSemicolonDelimitedFiles(t *testing.T) {
+	records, err := parse(t, fileimport.KindInventory, "stock.csv", `SKU;Warehouse;Qty
+TEE-S;Main;4
+TEE-L;Main;3,0
+TEE-S;Back room;-2
+TEE-S;Main;1
+`)
+
+	got := validationErrors(t, err)
+	if len(got) != 2 || got[0].Line != 3 || got[0].Column != "available" || got[1].Line != 5 || got[1].Column != "sku" {
+		t.Errorf("errors = %v, want the decimal comma and the repeated level", got)
+	}
+
+	records, err = parse(t, fileimport.KindInventory, "stock.csv", "SKU;Warehouse;Qty\nTEE-S;Main;4\nTEE-L;Main;3.0\nTEE-S;Back room;-2\n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(records.Locations) != 2 || records.Locations[1].ExternalID != "shop:Back room" || records.Locations[1].Name != "Back room" {
+		t.Errorf("locations = %+v, want Main and Back room", records.Locations)
+	}
+	if len(records.InventoryLevels) != 3 || records.InventoryLevels[1].Available != 3 || records.InventoryLevels[2].Available != -2 {
+		t.Errorf("levels = %+v", records.InventoryLevels)
+	}
+	if len(records.References) != 2 || records.References[0].SKU != "TEE-S" || records.References[1].Line != 3 {
+		t.Errorf("references = %+v, want each SKU once with its first line", records.References)
+	}
+}
+
+func TestParseSalesGroupsLinesIntoOrders(t *testing.T) {
+	records, err := parse(t, fileimport.KindSales, "sales.csv", `order_id,date,sku,quantity,price,status
+1002,2025-03-02 10:30,MUG-01,1,12,cancelled
+1001,2025-03-01,TEE-S,2,19.50,
+1001,2025-03-01,MUG-01,3,12.00,
+`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(records.Orders) != 2 {
+		t.Fatalf("orders = %+v, want 2", records.Orders)
+	}
+	paid, cancelled := records.Orders[0], records.Orders[1]
+	if paid.ExternalID != "shop:1001" || paid.TotalPrice != "75.00" || paid.FinancialStatus != core.FinancialStatusPaid ||
+		paid.FulfillmentStatus != core.FulfillmentStatusFulfilled || len(paid.LineItems) != 2 {
+		t.Errorf("paid order = %+v, want the earliest order first with its lines summed", paid)
+	}
+	if line := paid.LineItems[1]; line.ExternalID != "MUG-01" || line.VariantID != "shop:MUG-01" || line.Quantity != 3 || line.Price != "12.00" {
+		t.Errorf("line = %+v", line)
+	}
+	if cancelled.FinancialStatus != core.FinancialStatusVoided || cancelled.CancelledAt == nil ||
+		!cancelled.CancelledAt.Equal(time.Date(2025, 3, 2, 10, 30, 0, 0, time.UTC)) || cancelled.FulfillmentStatus != core.FulfillmentStatusNull {
+		t.Errorf("cancelled order = %+v, want voided and unfulfilled", cancelled)
+	}
+	if len(records.References) != 2 {
+		t.Errorf("references = %+v, want MUG-01 and TEE-S", records.References)
+	}
+}
+
+func TestParseSalesRejectsInconsistentOrders(t *testing.T) {
+	_, err := parse(t, fileimport.KindSales, "sales.csv", `order_id,date,sku,quantity,price
+1001,2025-03-01,TEE-S,2,19.50
+1001,2025-03-02,MUG-01,1,12
+1001,2025-03-01,TEE-S,1,19.50
+1002,yesterday,TEE-S,0,
+`)
+
+	got := validationErrors(t, err)
+	want := fileimport.ValidationErrors{
+		{Line: 3, Column: "date", Message: `differs from the date of order "1001" on line 2`},
+		{Line: 4, Column: "sku", Message: `"TEE-S" is already listed in order "1001" on line 2`},
+		{Line: 5, Column: "date", Message: `"yesterday" is not a date, use YYYY-MM-DD`},
+		{Line: 5, Column: "quantity", Message: "must be greater than zero"},
+		{Line: 5, Column: "price", Message: "is required"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("errors =\n%+v\nwant\n%+v", []fileimport.ValidationError(got), []fileimport.ValidationError(want))
+	}
+}
+
+func TestReadTableRejectsUnknownFormats(t *testing.T) {
+	if _, err := fileimport.ReadTable("products.xls", strings.NewReader("")); !errors.Is(err, fileimport.ErrUnsupportedFormat) {
+		t.Errorf("got %v, want %v", err, fileimport.ErrUnsupportedFormat)
+	}
+	if _, err := fileimport.ReadTable("products.xlsx", strings.NewReader("sku,title")); !errors.Is(err, fileimport.ErrInvalidFile) {
+		t.Errorf("got %v, want %v", err, fileimport.ErrInvalidFile)
+	}
+	if _, err := fileimport.ReadTable("products.csv", strings.NewReader(" , \n")); !errors.Is(err, fileimport.ErrInvalidFile) {
+		t.Errorf("got %v, want %v", err, fileimport.ErrInvalidFile)
+	}
+}
+
+// workbook builds an XLSX workbook from its parts
+func workbook(t *testing.T, parts map[string]string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("close workbook: %v", err)
+	}
+	return buf.String()
+}
+
+func TestParseSalesReadsTheFirstSheetOfAWorkbook(t *testing.T) {
+	content := workbook(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sales" sheetId="1" r:id="rId2"/><sheet name="Notes" sheetId="2" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Order</t></si><si><t>Date</t></si><si><t>SKU</t></si><si><t>Qty</t></si><si><t>Unit price</t></si><si><r><t>TEE</t></r><r><t>-S</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>notes</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="2"><c r="A2" t="s"><v>0</v></c><c r="B2" t="s"><v>1</v></c><c r="C2" t="s"><v>2</v></c><c r="D2" t="s"><v>3</v></c><c r="E2" t="s"><v>4</v></c></row>
+<row r="4"><c r="A4"><v>1001</v></c><c r="B4"><v>45717.5</v></c><c r="C4" t="s"><v>5</v></c><c r="D4"><v>2</v></c><c r="E4"><v>19.5</v></c></row>
+<row r="5"><c r="A5" t="inlineStr"><is><t>1002</t></is></c><c r="C5" t="s"><v>5</v></c><c r="D5"><v>1</v></c><c r="E5"><v>19.5</v></c></row>
+</sheetData></worksheet>`,
+	})
+
+	_, err := parse(t, fileimport.KindSales, "Sales.XLSX", content)
+	if got := validationErrors(t, err); len(got) != 1 || got[0].Line != 5 || got[0].Column != "date" {
+		t.Fatalf("errors = %v, want the missing date on row 5", got)
+	}
+
+	table, err := fileimport.ReadTable("sales.xlsx", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ReadTable: %v", err)
+	}
+	// Without the dateless row the workbook imports
+	table.Rows = table.Rows[:1]
+	records, err := fileimport.Parse(fileimport.KindSales, table, namespace)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	order := records.Orders[0]
+	if !order.CreatedAt.Equal(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("created at = %s, want the date serial 45717.5", order.CreatedAt)
+	}
+	if order.ExternalID != "shop:1001" || order.TotalPrice != "39.00" || order.LineItems[0].SKU != "TEE-S" {
+		t.Errorf("order = %+v, want the rich text SKU", order)
+	}
+}
@@ -0,0 +1,432 @@
+package fileimport
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+// column is a column a kind of file can have. Headers are matched after normalizeHeader.
+type column struct {
+	name     string
+	aliases  []string
+	required bool
+}
+
+var (
+	productColumns = []column{
+		{name: "sku", aliases: []string{"variant_sku"}, required: true},
+		{name: "title", aliases: []string{"name", "product", "product_title"}, required: true},
+		{name: "handle", aliases: []string{"product_handle"}},
+		{name: "price", aliases: []string{"variant_price"}},
+		{name: "cost", aliases: []string{"cost_per_item", "unit_cost"}},
+		{name: "product_type", aliases: []string{"type", "category"}},
+		{name: "status"},
+		{name: "tracked", aliases: []string{"track_inventory"}},
+		{name: "barcode", aliases: []string{"variant_barcode", "upc", "ean", "gtin"}},
+	}
+
+	inventoryColumns = []column{
+		{name: "sku", aliases: []string{"variant_sku"}, required: true},
+		{name: "location", aliases: []string{"location_name", "warehouse"}, required: true},
+		{name: "available", aliases: []string{"quantity", "qty", "on_hand"}, required: true},
+	}
+
+	salesColumns = []column{
+		{name: "order_id", aliases: []string{"order", "order_number", "order_name"}, required: true},
+		{name: "date", aliases: []string{"created_at", "order_date"}, required: true},
+		{name: "sku", aliases: []string{"variant_sku"}, required: true},
+		{name: "quantity", aliases: []string{"qty"}, required: true},
+		{name: "price", aliases: []string{"unit_price"}, required: true},
+		{name: "status", aliases: []string{"financial_status"}},
+	}
+)
+
+// Amounts are plain decimal numbers, without currency symbols or thousands separators
+var amountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// Date layouts accepted in sales files, besides spreadsheet date serial numbers
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// Spreadsheet date serial numbers count days from this date
+var spreadsheetEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// parser converts the rows of a table into records, collecting validation errors
+type parser struct {
+	table     *Table
+	namespace string
+	records   *Records
+	errors    ValidationErrors
+
+	// Index of each column in the header, -1 for optional columns the file does not have
+	columns map[string]int
+}
+
+// mapColumns finds the columns in the header. It reports false when a required column is missing.
+func (p *parser) mapColumns(columns []column) bool {
+	headers := make(map[string]int, len(p.table.Header))
+	for i, header := range p.table.Header {
+		name := normalizeHeader(header)
+		if _, ok := headers[name]; !ok {
+			headers[name] = i
+		}
+	}
+
+	p.columns = make(map[string]int, len(columns))
+	for _, c := range columns {
+		p.columns[c.name] = -1
+		for _, name := range append([]string{c.name}, c.aliases...) {
+			if i, ok := headers[name]; ok {
+				p.columns[c.name] = i
+				break
+			}
+		}
+		if p.columns[c.name] == -1 && c.required {
+			p.addError(p.table.HeaderLine, c.name, "required column is missing")
+		}
+	}
+	return len(p.errors) == 0
+}
+
+// value returns the value of a column of the row
+func (p *parser) value(row Row, name string) string {
+	return row.value(p.columns[name])
+}
+
+// addError records a validation error, up to maxErrors
+func (p *parser) addError(line int, column, format string, args ...any) {
+	if len(p.errors) < maxErrors {
+		p.errors = append(p.errors, ValidationError{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// required returns the value of a required column, recording an error when it is empty
+func (p *parser) required(row Row, name string) (string, bool) {
+	value := p.value(row, name)
+	if value == "" {
+		p.addError(row.Line, name, "is required")
+	}
+	return value, value != ""
+}
+
+// amount validates an optional decimal amount, returning it in canonical form
+func (p *parser) amount(row Row, name string) (*big.Rat, bool) {
+	value := p.value(row, name)
+	if value == "" {
+		return nil, true
+	}
+
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok || !amountPattern.MatchString(value) {
+		p.addError(row.Line, name, "%q is not a valid amount, use a plain number such as 12.50", value)
+		return nil, false
+	}
+	return amount, true
+}
+
+// integer validates an integer column. Spreadsheets store whole numbers as decimals ("3.0").
+func (p *parser) integer(row Row, name string) (int, bool) {
+	value, ok := p.required(row, name)
+	if !ok {
+		return 0, false
+	}
+
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && f == math.Trunc(f) && math.Abs(f) <= math.MaxInt32 {
+		return int(f), true
+	}
+	p.addError(row.Line, name, "%q is not a whole number", value)
+	return 0, false
+}
+
+// reference records a SKU a row refers to, once per SKU
+func (p *parser) reference(row Row, sku string, seen map[string]bool) {
+	if seen[sku] {
+		return
+	}
+	seen[sku] = true
+	p.records.References = append(p.records.References, Reference{Line: row.Line, SKU: sku, ExternalID: externalID(p.namespace, sku)})
+}
+
+// parseProducts converts a products file: one row per variant, grouped into products by handle,
+// or by title when the file has no handles
+func (p *parser) parseProducts() {
+	products := make(map[string]*connector.Product)
+	productLines := make(map[string]int)
+	skuLines := make(map[string]int)
+	var handles []string
+
+	for _, row := range p.table.Rows {
+		p.records.Rows++
+
+		sku, skuOK := p.required(row, "sku")
+		title, titleOK := p.required(row, "title")
+		price, priceOK := p.amount(row, "price")
+		cost, costOK := p.amount(row, "cost")
+		status, statusOK := p.productStatus(row)
+		tracked, trackedOK := p.tracked(row)
+		if !skuOK || !titleOK || !priceOK || !costOK || !statusOK || !trackedOK {
+			continue
+		}
+
+		if line, ok := skuLines[sku]; ok {
+			p.addError(row.Line, "sku", "%q is already listed on line %d", sku, line)
+			continue
+		}
+		skuLines[sku] = row.Line
+
+		handle := p.value(row, "handle")
+		if handle == "" {
+			if handle = handleize(title); handle == "" {
+				p.addError(row.Line, "handle", "is required when the title has no letters or digits")
+				continue
+			}
+		}
+
+		product, ok := products[handle]
+		if !ok {
+			product = &connector.Product{
+				ExternalID:  externalID(p.namespace, handle),
+				Title:       title,
+				Handle:      handle,
+				ProductType: p.value(row, "product_type"),
+				Status:      status,
+			}
+			products[handle], productLines[handle] = product, row.Line
+			handles = append(handles, handle)
+		} else if product.Title != title {
+			p.addError(row.Line, "title", "%q differs from the title %q of the same product on line %d", title, product.Title, productLines[handle])
+			continue
+		}
+
+		item := &connector.InventoryItem{
+			ExternalID: externalID(p.namespace, sku),
+			SKU:        sku,
+			Tracked:    tracked,
+			Cost:       formatAmount(cost),
+		}
+		product.Variants = append(product.Variants, connector.Variant{
+			ExternalID:      item.ExternalID,
+			SKU:             sku,
+			Price:           formatAmount(price),
+			Barcode:         p.value(row, "barcode"),
+			InventoryItemID: item.ExternalID,
+			InventoryItem:   item,
+		})
+	}
+
+	for _, handle := range handles {
+		p.records.Products = append(p.records.Products, *products[handle])
+	}
+}
+
+// productStatus validates the optional status of a product, active by default
+func (p *parser) productStatus(row Row) (core.ProductStatus, bool) {
+	switch status := core.ProductStatus(strings.ToLower(p.value(row, "status"))); status {
+	case "":
+		return core.ProductStatusActive, true
+	case core.ProductStatusActive, core.ProductStatusDraft, core.ProductStatusArchived:
+		return status, true
+	}
+	p.addError(row.Line, "status", "%q is not a product status, use active, draft or archived", p.value(row, "status"))
+	return "", false
+}
+
+// tracked validates whether the stock of a variant is tracked, true by default
+func (p *parser) tracked(row Row) (bool, bool) {
+	switch strings.ToLower(p.value(row, "tracked")) {
+	case "", "true", "yes", "y", "1":
+		return true, true
+	case "false", "no", "n", "0":
+		return false, true
+	}
+	p.addError(row.Line, "tracked", "%q is not a yes or no value", p.value(row, "tracked"))
+	return false, false
+}
+
+// parseInventory converts an inventory file: the available quantity of each SKU at each
+// location. Locations are created from the names the file uses.
+func (p *parser) parseInventory() {
+	levelLines := make(map[[2]string]int)
+	locations := make(map[string]bool)
+	referenced := make(map[string]bool)
+
+	for _, row := range p.table.Rows {
+		p.records.Rows++
+
+		sku, skuOK := p.required(row, "sku")
+		location, locationOK := p.required(row, "location")
+		available, availableOK := p.integer(row, "available")
+		if !skuOK || !locationOK || !availableOK {
+			continue
+		}
+
+		key := [2]string{sku, location}
+		if line, ok := levelLines[key]; ok {
+			p.addError(row.Line, "sku", "%q at %q is already listed on line %d", sku, location, line)
+			continue
+		}
+		levelLines[key] = row.Line
+
+		if !locations[location] {
+			locations[location] = true
+			p.records.Locations = append(p.records.Locations, connector.Location{
+				ExternalID: externalID(p.namespace, location),
+				Name:       location,
+				Active:     true,
+			})
+		}
+
+		p.reference(row, sku, referenced)
+		p.records.InventoryLevels = append(p.records.InventoryLevels, connector.InventoryLevel{
+			InventoryItemID: externalID(p.namespace, sku),
+			LocationID:      externalID(p.namespace, location),
+			Available:       available,
+		})
+	}
+}
+
+// parseSales converts a sales file: one row per order line, grouped into orders by order ID.
+// The order total is the sum of its lines.
+func (p *parser) parseSales() {
+	orders := make(map[string]*connector.Order)
+	orderLines := make(map[string]int)
+	totals := make(map[string]*big.Rat)
+	lineItemLines := make(map[[2]string]int)
+	referenced := make(map[string]bool)
+	var orderIDs []string
+
+	for _, row := range p.table.Rows {
+		p.records.Rows++
+
+		orderID, orderOK := p.required(row, "order_id")
+		createdAt, dateOK := p.date(row)
+		sku, skuOK := p.required(row, "sku")
+		quantity, quantityOK := p.integer(row, "quantity")
+		price, priceOK := p.amount(row, "price")
+		status, statusOK := p.financialStatus(row)
+		if quantityOK && quantity <= 0 {
+			p.addError(row.Line, "quantity", "must be greater than zero")
+			quantityOK = false
+		}
+		if priceOK && price == nil {
+			p.addError(row.Line, "price", "is required")
+			priceOK = false
+		}
+		if !orderOK || !dateOK || !skuOK || !quantityOK || !priceOK || !statusOK {
+			continue
+		}
+
+		key := [2]string{orderID, sku}
+		if line, ok := lineItemLines[key]; ok {
+			p.addError(row.Line, "sku", "%q is already listed in order %q on line %d", sku, orderID, line)
+			continue
+		}
+
+		order, ok := orders[orderID]
+		if !ok {
+			order = &connector.Order{
+				ExternalID:        externalID(p.namespace, orderID),
+				CreatedAt:         createdAt,
+				FinancialStatus:   status,
+				FulfillmentStatus: core.FulfillmentStatusFulfilled,
+			}
+			if status == core.FinancialStatusVoided {
+				order.CancelledAt = &createdAt
+				order.FulfillmentStatus = core.FulfillmentStatusNull
+			}
+			orders[orderID], orderLines[orderID], totals[orderID] = order, row.Line, new(big.Rat)
+			orderIDs = append(orderIDs, orderID)
+		} else if !order.CreatedAt.Equal(createdAt) {
+			p.addError(row.Line, "date", "differs from the date of order %q on line %d", orderID, orderLines[orderID])
+			continue
+		} else if order.FinancialStatus != status {
+			p.addError(row.Line, "status", "differs from the status of order %q on line %d", orderID, orderLines[orderID])
+			continue
+		}
+		lineItemLines[key] = row.Line
+
+		p.reference(row, sku, referenced)
+		order.LineItems = append(order.LineItems, connector.OrderLineItem{
+			ExternalID: sku,
+			VariantID:  externalID(p.namespace, sku),
+			SKU:        sku,
+			Quantity:   quantity,
+			Price:      formatAmount(price),
+		})
+		totals[orderID].Add(totals[orderID], new(big.Rat).Mul(price, big.NewRat(int64(quantity), 1)))
+	}
+
+	for _, orderID := range orderIDs {
+		order := orders[orderID]
+		order.TotalPrice = formatAmount(totals[orderID])
+		p.records.Orders = append(p.records.Orders, *order)
+	}
+	slices.SortStableFunc(p.records.Orders, func(a, b connector.Order) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
+
+// date validates the date of a sale: an ISO 8601 date or time, or a spreadsheet date serial
+// number. Times without a zone are UTC.
+func (p *parser) date(row Row) (time.Time, bool) {
+	value, ok := p.required(row, "date")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial >= 1 && serial < 2958466 {
+		days := math.Floor(serial)
+		seconds := math.Round((serial - days) * 24 * 60 * 60)
+		return spreadsheetEpoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second), true
+	}
+
+	p.addError(row.Line, "date", "%q is not a date, use YYYY-MM-DD", value)
+	return time.Time{}, false
+}
+
+// financialStatus validates the optional payment status of a sale, paid by default. Cancelled
+// sales are voided.
+func (p *parser) financialStatus(row Row) (core.FinancialStatus, bool) {
+	switch status := core.FinancialStatus(strings.ToLower(p.value(row, "status"))); status {
+	case "":
+		return core.FinancialStatusPaid, true
+	case "cancelled", "canceled":
+		return core.FinancialStatusVoided, true
+	case core.FinancialStatusPending, core.FinancialStatusAuthorized, core.FinancialStatusPartiallyPaid, core.FinancialStatusPaid,
+		core.FinancialStatusPartiallyRefunded, core.FinancialStatusRefunded, core.FinancialStatusVoided:
+		return status, true
+	}
+	p.addError(row.Line, "status", "%q is not a payment status, use paid, pending, refunded, partially_refunded or cancelled", p.value(row, "status"))
+	return "", false
+}
+
+// formatAmount formats an amount with two decimals, "" for a missing amount
+func formatAmount(amount *big.Rat) string {
+	if amount == nil {
+		return ""
+	}
+	return amount.FloatString(2)
+}
+
+// handleize derives a product handle from its title: "Logo Tee (Blue)" becomes "logo-tee-blue"
+func handleize(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r > 127)
+	}), "-")
+}
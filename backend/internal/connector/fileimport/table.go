@@ -0,0 +1,119 @@
+package fileimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Table is the content of a file: a header row and the data rows below it
+type Table struct {
+	HeaderLine int
+	Header     []string
+	Rows       []Row
+}
+
+// Row is a data row and the line it is on in the file, counting from 1
+type Row struct {
+	Line   int
+	Values []string
+}
+
+// value returns the trimmed value of column index, "" when the row is too short
+func (r Row) value(index int) string {
+	if index < 0 || index >= len(r.Values) {
+		return ""
+	}
+	return strings.TrimSpace(r.Values[index])
+}
+
+// blank reports whether every value of the row is empty
+func (r Row) blank() bool {
+	for i := range r.Values {
+		if r.value(i) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadTable reads a CSV or XLSX file, chosen by the extension of filename. The first non-blank
+// row is the header; blank rows are skipped. Of a workbook only the first sheet is read.
+func ReadTable(filename string, r io.Reader) (*Table, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read file")
+	}
+
+	var rows []Row
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		rows, err = readCSV(content)
+	case ".xlsx":
+		rows, err = readXLSX(content)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	table := &Table{}
+	for _, row := range rows {
+		switch {
+		case row.blank():
+			continue
+		case table.Header == nil:
+			table.HeaderLine, table.Header = row.Line, row.Values
+		default:
+			table.Rows = append(table.Rows, row)
+		}
+	}
+	if table.Header == nil {
+		return nil, errors.Wrap(ErrInvalidFile, "the file is empty")
+	}
+	return table, nil
+}
+
+// readCSV reads the records of a CSV file. Spreadsheets saved with a comma decimal separator
+// delimit fields with semicolons, which is detected from the first line.
+func readCSV(content []byte) ([]Row, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	var rows []Row
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, ValidationErrors{{Line: parseErr.StartLine, Message: parseErr.Err.Error()}}
+			}
+			return nil, errors.Wrap(ErrInvalidFile, err.Error())
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, Row{Line: line, Values: values})
+	}
+}
+
+// normalizeHeader turns a column header into a column name: "Order ID" becomes "order_id"
+func normalizeHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(header))
+	return strings.Join(strings.FieldsFunc(header, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "_")
+}
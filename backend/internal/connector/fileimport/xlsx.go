@@ -0,0 +1,214 @@
+package fileimport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Largest uncompressed workbook part read, which keeps a small upload from expanding into
+// gigabytes of XML
+const maxPartSize = 64 << 20
+
+// The parts of an XLSX workbook read to get the cells of its first sheet
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+
+	xlsxRelationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+
+	// xlsxText is a string item: plain text, or rich text made of runs
+	xlsxText struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	}
+
+	xlsxSharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+
+	xlsxSheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R      string    `xml:"r,attr"`
+				T      string    `xml:"t,attr"`
+				V      string    `xml:"v"`
+				Inline *xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+// String returns the text of a string item
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var text strings.Builder
+	for _, run := range t.Runs {
+		text.WriteString(run.T)
+	}
+	return text.String()
+}
+
+// readXLSX reads the rows of the first sheet of an XLSX workbook. Cells are read as they are
+// stored: numbers, dates included, come as their raw value and booleans as TRUE or FALSE.
+func readXLSX(content []byte) ([]Row, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidFile, "not an xlsx workbook")
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(file, &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxSheet
+	file, ok := files[sheetPath]
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidFile, "workbook sheet %s is missing", sheetPath)
+	}
+	if err := decodeXML(file, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(sheet.Rows))
+	for i, sheetRow := range sheet.Rows {
+		row := Row{Line: sheetRow.R}
+		if row.Line == 0 {
+			row.Line = i + 1
+		}
+
+		for j, cell := range sheetRow.Cells {
+			index := j
+			if cell.R != "" {
+				if index, err = columnIndex(cell.R); err != nil {
+					return nil, err
+				}
+			}
+
+			var value string
+			switch cell.T {
+			case "s":
+				n, err := strconv.Atoi(cell.V)
+				if err != nil || n < 0 || n >= len(sharedStrings.Items) {
+					return nil, errors.Wrapf(ErrInvalidFile, "cell %s refers to a missing shared string", cell.R)
+				}
+				value = sharedStrings.Items[n].String()
+			case "inlineStr":
+				if cell.Inline != nil {
+					value = cell.Inline.String()
+				}
+			case "b":
+				value = map[string]string{"1": "TRUE", "0": "FALSE"}[cell.V]
+			default:
+				value = cell.V
+			}
+
+			for len(row.Values) <= index {
+				row.Values = append(row.Values, "")
+			}
+			row.Values[index] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// firstSheetPath returns the path of the first sheet of the workbook within the archive
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	file, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.Wrap(ErrInvalidFile, "not an xlsx workbook")
+	}
+	if err := decodeXML(file, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.Wrap(ErrInvalidFile, "the workbook has no sheets")
+	}
+
+	var relationships xlsxRelationships
+	if file, ok := files["xl/_rels/workbook.xml.rels"]; ok {
+		if err := decodeXML(file, &relationships); err != nil {
+			return "", err
+		}
+	}
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		// Targets are relative to the workbook part unless they are absolute
+		if target, ok := strings.CutPrefix(relationship.Target, "/"); ok {
+			return target, nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+// decodeXML decodes a part of the workbook
+func decodeXML(file *zip.File, v any) error {
+	if file.UncompressedSize64 > maxPartSize {
+		return errors.Wrapf(ErrInvalidFile, "%s is too large", file.Name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return errors.Wrapf(ErrInvalidFile, "failed to open %s", file.Name)
+	}
+	defer reader.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(reader, maxPartSize)).Decode(v); err != nil {
+		return errors.Wrapf(ErrInvalidFile, "failed to read %s", file.Name)
+	}
+	return nil
+}
+
+// columnIndex returns the zero-based column of a cell reference: 0 for "A1", 27 for "AB3"
+func columnIndex(reference string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range reference {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, errors.Wrapf(ErrInvalidFile, "invalid cell reference %q", reference)
+	}
+	return index - 1, nil
+}
@@ -0,0 +1,243 @@
+// Package magento reads Magento 2 / Adobe Commerce stores through the REST API (V1), including the
+// Multi-Source Inventory (MSI) endpoints, and adapts them to connector.PlatformConnector
+package magento
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Path of the REST API below the store URL
+	apiPath = "/rest/V1"
+
+	// Page size requested in search criteria. Magento has no hard limit, large pages are slow.
+	maxPageSize = 100
+
+	// Layout of the timestamps Magento stores and filters by, always in UTC
+	timeLayout = "2006-01-02 15:04:05"
+)
+
+// Client is a Magento REST API client authenticated with an integration access token
+type Client struct {
+	storeURL    string
+	accessToken string
+	httpClient  *http.Client
+}
+
+// NewClient creates a client for the Magento store at storeURL ("https://shop.example.com")
+func NewClient(storeURL, accessToken string) *Client {
+	return &Client{
+		storeURL:    NormalizeStoreURL(storeURL),
+		accessToken: accessToken,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// NormalizeStoreURL trims whitespace and trailing slashes and defaults the scheme to https
+func NormalizeStoreURL(storeURL string) string {
+	storeURL = strings.TrimRight(strings.TrimSpace(storeURL), "/")
+	if storeURL != "" && !strings.Contains(storeURL, "://") {
+		storeURL = "https://" + storeURL
+	}
+	return storeURL
+}
+
+// APIError represents a non-successful response from the Magento API
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("magento API error: status %d: %s", e.StatusCode, e.Message)
+}
+
+// HTTPStatus implements connector.APIError
+func (e *APIError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// RetryDelay implements connector.APIError
+func (e *APIError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// Filter is one condition of a search ("created_at", "2024-01-01 00:00:00", "gteq")
+type Filter struct {
+	Field     string
+	Value     string
+	Condition string
+}
+
+// SearchCriteria selects one page of a search. Every filter is its own filter group, so a record
+// must match all of them. Results are sorted by SortField ascending.
+type SearchCriteria struct {
+	Filters     []Filter
+	SortField   string
+	PageSize    int
+	CurrentPage int
+}
+
+// params encodes the criteria as the searchCriteria query parameters
+func (s SearchCriteria) params() url.Values {
+	params := url.Values{}
+	for i, filter := range s.Filters {
+		prefix := fmt.Sprintf("searchCriteria[filter_groups][%d][filters][0]", i)
+		params.Set(prefix+"[field]", filter.Field)
+		params.Set(prefix+"[value]", filter.Value)
+		params.Set(prefix+"[condition_type]", filter.Condition)
+	}
+	if s.SortField != "" {
+		params.Set("searchCriteria[sortOrders][0][field]", s.SortField)
+		params.Set("searchCriteria[sortOrders][0][direction]", "ASC")
+	}
+	params.Set("searchCriteria[pageSize]", strconv.Itoa(s.PageSize))
+	params.Set("searchCriteria[currentPage]", strconv.Itoa(s.CurrentPage))
+	return params
+}
+
+// searchResult is the envelope of search responses
+type searchResult[T any] struct {
+	Items      []T `json:"items"`
+	TotalCount int `json:"total_count"`
+}
+
+// search requests one page of a search endpoint ("/products")
+func search[T any](ctx context.Context, c *Client, path string, criteria SearchCriteria) (*searchResult[T], error) {
+	var result searchResult[T]
+	if err := c.get(ctx, path, criteria.params(), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// get requests path below the API root and decodes the JSON response into target.
+// Rate limited and server error responses are retried with jittered exponential backoff.
+func (c *Client) get(ctx context.Context, path string, params url.Values, target any) error {
+	requestURL := c.storeURL + apiPath + path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	return connector.Retry(ctx, "Magento", path, func() error {
+		return c.doRequest(ctx, requestURL, target)
+	})
+}
+
+// doRequest performs a single request and converts error statuses into *APIError
+func (c *Client) doRequest(ctx context.Context, requestURL string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+		var errorBody struct {
+			Message    string            `json:"message"`
+			Parameters map[string]string `json:"parameters"`
+		}
+		if json.Unmarshal(body, &errorBody) == nil && errorBody.Message != "" {
+			apiErr.Message = expandMessage(errorBody.Message, errorBody.Parameters)
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return apiErr
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return errors.Wrap(err, "failed to unmarshal response")
+	}
+	return nil
+}
+
+// expandMessage fills the %name placeholders of a Magento error message with its parameters
+func expandMessage(message string, parameters map[string]string) string {
+	for name, value := range parameters {
+		message = strings.ReplaceAll(message, "%"+name, value)
+	}
+	return message
+}
+
+// SearchProducts retrieves one page of products
+func (c *Client) SearchProducts(ctx context.Context, criteria SearchCriteria) ([]Product, int, error) {
+	result, err := search[Product](ctx, c, "/products", criteria)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to search products")
+	}
+	return result.Items, result.TotalCount, nil
+}
+
+// GetConfigurableChildren retrieves the simple products a configurable product is sold as
+func (c *Client) GetConfigurableChildren(ctx context.Context, sku string) ([]Product, error) {
+	var children []Product
+	if err := c.get(ctx, "/configurable-products/"+url.PathEscape(sku)+"/children", nil, &children); err != nil {
+		return nil, errors.Wrapf(err, "failed to get children of product %s", sku)
+	}
+	return children, nil
+}
+
+// SearchSources retrieves one page of inventory sources
+func (c *Client) SearchSources(ctx context.Context, criteria SearchCriteria) ([]Source, int, error) {
+	result, err := search[Source](ctx, c, "/inventory/sources", criteria)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to search sources")
+	}
+	return result.Items, result.TotalCount, nil
+}
+
+// SearchSourceItems retrieves one page of the quantities of products at sources
+func (c *Client) SearchSourceItems(ctx context.Context, criteria SearchCriteria) ([]SourceItem, int, error) {
+	result, err := search[SourceItem](ctx, c, "/inventory/source-items", criteria)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to search source items")
+	}
+	return result.Items, result.TotalCount, nil
+}
+
+// SearchOrders retrieves one page of sales orders
+func (c *Client) SearchOrders(ctx context.Context, criteria SearchCriteria) ([]Order, int, error) {
+	result, err := search[Order](ctx, c, "/orders", criteria)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to search orders")
+	}
+	return result.Items, result.TotalCount, nil
+}
+
+// GetCurrency retrieves the base and display currencies of the store
+func (c *Client) GetCurrency(ctx context.Context) (*StoreCurrency, error) {
+	var currency StoreCurrency
+	if err := c.get(ctx, "/directory/currency", nil, &currency); err != nil {
+		return nil, errors.Wrap(err, "failed to get currency")
+	}
+	return &currency, nil
+}
@@ -0,0 +1,232 @@
+package magento
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/pkg/errors"
+)
+
+// Page size requested from the Magento API
+const pageSize = maxPageSize
+
+// Connector reads a Magento store through the REST API
+type Connector struct {
+	client *Client
+}
+
+var (
+	_ connector.PlatformConnector = (*Connector)(nil)
+	_ connector.Counter           = (*Connector)(nil)
+	_ connector.CurrencyReader    = (*Connector)(nil)
+)
+
+// New creates a connector that reads through the given client
+func New(client *Client) *Connector {
+	return &Connector{client: client}
+}
+
+// Factory creates the connectors of Magento integrations from their stored credentials
+func Factory(database db.Database) connector.Factory {
+	return func(ctx context.Context, integration core.PlatformIntegration) (connector.PlatformConnector, error) {
+		credentials, err := database.GetCore().GetMagentoCredentialsByIntegrationID(ctx, integration.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get magento credentials")
+		}
+
+		return New(NewClient(credentials.StoreUrl, credentials.AccessToken.String())), nil
+	}
+}
+
+// Platform implements connector.PlatformConnector
+func (c *Connector) Platform() core.PlatformType {
+	return core.PlatformTypeMagento
+}
+
+// Verify checks that the access token can read the store's catalog, sources, source items and orders
+func (c *Connector) Verify(ctx context.Context) error {
+	criteria := SearchCriteria{PageSize: 1, CurrentPage: 1}
+	if _, _, err := c.client.SearchProducts(ctx, criteria); err != nil {
+		return err
+	}
+	if _, _, err := c.client.SearchSources(ctx, criteria); err != nil {
+		return err
+	}
+	if _, _, err := c.client.SearchSourceItems(ctx, criteria); err != nil {
+		return err
+	}
+	if _, _, err := c.client.SearchOrders(ctx, criteria); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListLocations implements connector.PlatformConnector. MSI sources are the locations.
+func (c *Connector) ListLocations(ctx context.Context, cursor string) (*connector.Page[connector.Location], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, total, err := c.client.SearchSources(ctx, pageCriteria("source_code", pageNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Location]{NextCursor: nextCursor(pageNumber, total)}
+	for _, source := range sources {
+		page.Items = append(page.Items, toLocation(source))
+	}
+	return page, nil
+}
+
+// ListProducts implements connector.PlatformConnector.
+//
+// Only products visible on their own are listed; the children of the configurable products on
+// the page are fetched with it and become their variants. Bundles and grouped products hold no
+// stock of their own and are left out, their components are synced as products.
+func (c *Connector) ListProducts(ctx context.Context, cursor string) (*connector.Page[connector.Product], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	products, total, err := c.client.SearchProducts(ctx, productsCriteria(pageNumber, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Product]{NextCursor: nextCursor(pageNumber, total)}
+	for _, product := range products {
+		var children []Product
+		switch product.TypeID {
+		case ProductTypeBundle, ProductTypeGrouped:
+			continue
+		case ProductTypeConfigurable:
+			if children, err = c.client.GetConfigurableChildren(ctx, product.SKU); err != nil {
+				return nil, err
+			}
+		}
+		page.Items = append(page.Items, toProduct(product, children))
+	}
+	return page, nil
+}
+
+// ListInventoryLevels implements connector.PlatformConnector. MSI source items are the levels.
+func (c *Connector) ListInventoryLevels(ctx context.Context, cursor string) (*connector.Page[connector.InventoryLevel], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	items, total, err := c.client.SearchSourceItems(ctx, pageCriteria("source_item_id", pageNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.InventoryLevel]{NextCursor: nextCursor(pageNumber, total)}
+	for _, item := range items {
+		page.Items = append(page.Items, toInventoryLevel(item))
+	}
+	return page, nil
+}
+
+// ListOrders implements connector.PlatformConnector
+func (c *Connector) ListOrders(ctx context.Context, since time.Time, cursor string) (*connector.Page[connector.Order], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, total, err := c.client.SearchOrders(ctx, ordersCriteria(since, pageNumber, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Order]{NextCursor: nextCursor(pageNumber, total)}
+	for _, order := range orders {
+		page.Items = append(page.Items, toOrder(order))
+	}
+	return page, nil
+}
+
+// CountLocations implements connector.Counter
+func (c *Connector) CountLocations(ctx context.Context) (int, error) {
+	_, total, err := c.client.SearchSources(ctx, SearchCriteria{PageSize: 1, CurrentPage: 1})
+	return total, err
+}
+
+// CountProducts implements connector.Counter. Bundles and grouped products are counted, which
+// leaves the estimate slightly high.
+func (c *Connector) CountProducts(ctx context.Context) (int, error) {
+	_, total, err := c.client.SearchProducts(ctx, productsCriteria(1, 1))
+	return total, err
+}
+
+// CountOrders implements connector.Counter
+func (c *Connector) CountOrders(ctx context.Context, since time.Time) (int, error) {
+	_, total, err := c.client.SearchOrders(ctx, ordersCriteria(since, 1, 1))
+	return total, err
+}
+
+// pageCriteria returns the criteria of one page of a listing in ascending sortField order, which
+// keeps page boundaries stable while records are added during a sync
+func pageCriteria(sortField string, pageNumber int) SearchCriteria {
+	return SearchCriteria{SortField: sortField, PageSize: pageSize, CurrentPage: pageNumber}
+}
+
+// productsCriteria returns the criteria of one page of the products visible on their own
+func productsCriteria(pageNumber, size int) SearchCriteria {
+	return SearchCriteria{
+		Filters:     []Filter{{Field: "visibility", Value: strconv.Itoa(VisibilityNotVisibleIndividually), Condition: "neq"}},
+		SortField:   "entity_id",
+		PageSize:    size,
+		CurrentPage: pageNumber,
+	}
+}
+
+// ordersCriteria returns the criteria of one page of the orders created at or after since
+func ordersCriteria(since time.Time, pageNumber, size int) SearchCriteria {
+	return SearchCriteria{
+		Filters:     []Filter{{Field: "created_at", Value: formatTime(since), Condition: "gteq"}},
+		SortField:   "entity_id",
+		PageSize:    size,
+		CurrentPage: pageNumber,
+	}
+}
+
+// Currency implements connector.CurrencyReader with the base currency of the store, which
+// prices, costs and order base totals are in
+func (c *Connector) Currency(ctx context.Context) (string, error) {
+	currency, err := c.client.GetCurrency(ctx)
+	if err != nil {
+		return "", err
+	}
+	return currency.BaseCurrencyCode, nil
+}
+
+// parseCursor decodes a page number cursor, an empty cursor being the first page
+func parseCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 1, nil
+	}
+
+	page, err := strconv.Atoi(cursor)
+	if err != nil || page < 1 {
+		return 0, errors.Errorf("invalid magento cursor %q", cursor)
+	}
+	return page, nil
+}
+
+// nextCursor returns the cursor of the page after pageNumber, or "" when it was the last one.
+// Magento serves the last page again for pages past the end, so the total decides.
+func nextCursor(pageNumber, total int) string {
+	if pageNumber*pageSize >= total {
+		return ""
+	}
+	return strconv.Itoa(pageNumber + 1)
+}
@@ -0,0 +1,143 @@
+package magento_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento/magentotest"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+func newConnector(t *testing.T) (*magento.Connector, *magentotest.Server) {
+	t.Helper()
+
+	server := magentotest.NewServer(magentotest.DemoFixtures())
+	t.Cleanup(server.Close)
+	return magento.New(server.Client(magentotest.AccessToken)), server
+}
+
+func TestListProductsMapsConfigurableChildrenToVariants(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	products := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Product], error) {
+		return conn.ListProducts(ctx, cursor)
+	})
+
+	// The children of the configurable product and the bundle are not products of their own
+	if len(products) != 3 {
+		t.Fatalf("listed %d products, want 3: %+v", len(products), products)
+	}
+
+	mug, tee, warranty := products[0], products[1], products[2]
+	if mug.Handle != "enamel-mug" || len(mug.Variants) != 1 || mug.Variants[0].InventoryItem.Cost != "6" {
+		t.Errorf("simple product = %+v, want itself as its single variant", mug)
+	}
+	if len(tee.Variants) != 2 || tee.Variants[0].SKU != "TEE-S" || tee.Variants[1].ExternalID != "4" {
+		t.Fatalf("configurable variants = %+v, want the two children", tee.Variants)
+	}
+	// A child without a price of its own sells at the parent's price
+	if large := tee.Variants[1]; large.Price != "25" || large.InventoryItemID != "TEE-L" || large.InventoryItem.Cost != "9" {
+		t.Errorf("child variant = %+v, want the parent price and an item keyed by SKU", large)
+	}
+	if warranty.Status != core.ProductStatusDraft || warranty.Variants[0].InventoryItem.Tracked {
+		t.Errorf("disabled product = %+v, want a draft without stock management", warranty)
+	}
+
+	count, err := conn.CountProducts(ctx)
+	if err != nil || count != 4 {
+		t.Errorf("CountProducts = %d, %v, want the 4 individually visible products", count, err)
+	}
+}
+
+func TestListSourcesAndSourceItems(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	locations := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Location], error) {
+		return conn.ListLocations(ctx, cursor)
+	})
+	if len(locations) != 3 {
+		t.Fatalf("listed %d locations, want 3", len(locations))
+	}
+	if east := locations[2]; east.ExternalID != "warehouse_east" || east.Province != "New York" || !east.Active {
+		t.Errorf("location = %+v, want the east warehouse", east)
+	}
+	if locations[1].Active {
+		t.Error("disabled source is active")
+	}
+
+	levels := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.InventoryLevel], error) {
+		return conn.ListInventoryLevels(ctx, cursor)
+	})
+	want := connector.InventoryLevel{InventoryItemID: "TEE-L", LocationID: "warehouse_east", Available: 7}
+	if len(levels) != 4 || levels[3] != want {
+		t.Errorf("levels = %+v, want 4 ending with %+v", levels, want)
+	}
+}
+
+func TestListOrdersMapsStates(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	orders := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Order], error) {
+		return conn.ListOrders(ctx, time.Now().AddDate(0, 0, -20), cursor)
+	})
+	if len(orders) != 3 {
+		t.Fatalf("listed %d orders, want 3", len(orders))
+	}
+
+	complete, canceled, partial := orders[0], orders[1], orders[2]
+	if complete.FinancialStatus != core.FinancialStatusPaid || complete.FulfillmentStatus != core.FulfillmentStatusFulfilled {
+		t.Errorf("complete order statuses = %s/%s, want paid/fulfilled", complete.FinancialStatus, complete.FulfillmentStatus)
+	}
+	// The configurable item is one line sold as the child product
+	if len(complete.LineItems) != 2 || complete.LineItems[1].ProductID != "2" || complete.LineItems[1].VariantID != "4" || complete.LineItems[1].Price != "25" {
+		t.Errorf("line items = %+v, want the mug and the tee sold as its large child", complete.LineItems)
+	}
+	if canceled.FinancialStatus != core.FinancialStatusVoided || canceled.CancelledAt == nil {
+		t.Errorf("canceled order = %+v, want voided with a cancel time", canceled)
+	}
+	if partial.FulfillmentStatus != core.FulfillmentStatusPartial {
+		t.Errorf("partially shipped order = %s, want partial", partial.FulfillmentStatus)
+	}
+	// The order was paid in euros, its amounts are kept in the base currency
+	if partial.TotalPrice != "36" || partial.LineItems[0].Price != "18" || partial.PresentmentCurrency != "EUR" || partial.PresentmentTotalPrice != "33.5" {
+		t.Errorf("order paid in euros = %+v, want 36 in the base currency and 33.5 EUR presented", partial)
+	}
+}
+
+func TestListOrdersPagesBySearchCriteria(t *testing.T) {
+	conn, server := newConnector(t)
+	ctx := context.Background()
+
+	server.UpdateFixtures(func(f *magentotest.Fixtures) {
+		for i := range 150 {
+			f.Orders = append(f.Orders, magento.Order{
+				EntityID:    int64(2000 + i),
+				IncrementID: fmt.Sprintf("%09d", 2000+i),
+				State:       magento.OrderStateNew,
+				CreatedAt:   magento.Timestamp{Time: time.Now().UTC().Add(-time.Hour)},
+				GrandTotal:  "10",
+			})
+		}
+	})
+
+	orders := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Order], error) {
+		return conn.ListOrders(ctx, time.Now().AddDate(0, 0, -20), cursor)
+	})
+	if len(orders) != 153 {
+		t.Errorf("listed %d orders, want 153 without the last page repeated", len(orders))
+	}
+	if got := server.Requests("/orders"); got != 2 {
+		t.Errorf("orders requested %d times, want 2 pages", got)
+	}
+	if last := orders[len(orders)-1]; last.FinancialStatus != core.FinancialStatusPending {
+		t.Errorf("new unpaid order = %s, want pending", last.FinancialStatus)
+	}
+}
@@ -0,0 +1,340 @@
+// Package magentotest provides a fake Magento 2 REST API for tests. It serves the store
+// currency, products, configurable children, MSI sources and source items and sales orders
+// from fixtures, supports the searchCriteria filters and pagination the connector uses, checks
+// the access token, and can inject errors.
+package magentotest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento"
+)
+
+const (
+	// Access token accepted by a server created with NewServer
+	AccessToken = "mg_token"
+
+	apiPrefix       = "/rest/V1"
+	defaultPageSize = 20
+	timeLayout      = "2006-01-02 15:04:05"
+)
+
+// Fixtures is the data a fake store serves, in the shape of the Magento REST API
+type Fixtures struct {
+	Currency    magento.StoreCurrency
+	Products    []magento.Product
+	Sources     []magento.Source
+	SourceItems []magento.SourceItem
+	Orders      []magento.Order
+}
+
+// DemoFixtures returns a small store: a simple product, a configurable product with two
+// children, a bundle, a disabled virtual product, three sources and orders in several states
+func DemoFixtures() *Fixtures {
+	now := time.Now().UTC().Truncate(time.Second)
+	at := func(days int) magento.Timestamp { return magento.Timestamp{Time: now.AddDate(0, 0, days)} }
+	attributes := func(pairs ...string) []magento.CustomAttribute {
+		var result []magento.CustomAttribute
+		for i := 0; i+1 < len(pairs); i += 2 {
+			value, _ := json.Marshal(pairs[i+1])
+			result = append(result, magento.CustomAttribute{AttributeCode: pairs[i], Value: value})
+		}
+		return result
+	}
+	stock := func(managed bool) *magento.ProductExtensionAttributes {
+		return &magento.ProductExtensionAttributes{StockItem: &magento.StockItem{ManageStock: managed, IsInStock: true}}
+	}
+
+	return &Fixtures{
+		Currency: magento.StoreCurrency{BaseCurrencyCode: "USD", DefaultDisplayCurrencyCode: "USD"},
+		Products: []magento.Product{
+			{ID: 1, SKU: "MUG-01", Name: "Enamel Mug", TypeID: magento.ProductTypeSimple, Status: magento.ProductStatusEnabled, Visibility: magento.VisibilityCatalogSearch, Price: "18", ExtensionAttributes: stock(true), CustomAttributes: attributes("url_key", "enamel-mug", "cost", "6")},
+			{
+				ID: 2, SKU: "TEE", Name: "Logo Tee", TypeID: magento.ProductTypeConfigurable, Status: magento.ProductStatusEnabled, Visibility: magento.VisibilityCatalogSearch, Price: "25",
+				ExtensionAttributes: &magento.ProductExtensionAttributes{ConfigurableProductLinks: []int64{3, 4}},
+				CustomAttributes:    attributes("url_key", "logo-tee"),
+			},
+			{ID: 3, SKU: "TEE-S", Name: "Logo Tee-S", TypeID: magento.ProductTypeSimple, Status: magento.ProductStatusEnabled, Visibility: magento.VisibilityNotVisibleIndividually, Price: "25", ExtensionAttributes: stock(true)},
+			{ID: 4, SKU: "TEE-L", Name: "Logo Tee-L", TypeID: magento.ProductTypeSimple, Status: magento.ProductStatusEnabled, Visibility: magento.VisibilityNotVisibleIndividually, ExtensionAttributes: stock(true), CustomAttributes: attributes("cost", "9")},
+			{ID: 5, SKU: "GIFT-SET", Name: "Gift Set", TypeID: magento.ProductTypeBundle, Status: magento.ProductStatusEnabled, Visibility: magento.VisibilityCatalogSearch, Price: "40"},
+			{ID: 6, SKU: "WARRANTY", Name: "Extended Warranty", TypeID: magento.ProductTypeVirtual, Status: magento.ProductStatusDisabled, Visibility: magento.VisibilityCatalogSearch, Price: "5", ExtensionAttributes: stock(false)},
+		},
+		Sources: []magento.Source{
+			{SourceCode: "default", Name: "Default Source", Enabled: true, CountryID: "US"},
+			{SourceCode: "popup", Name: "Popup Store", Enabled: false, CountryID: "US"},
+			{SourceCode: "warehouse_east", Name: "East Warehouse", Enabled: true, CountryID: "US", Region: "New York", City: "Albany", Street: "1 Dock St", Postcode: "12207"},
+		},
+		SourceItems: []magento.SourceItem{
+			{SKU: "MUG-01", SourceCode: "default", Quantity: "10", Status: 1},
+			{SKU: "MUG-01", SourceCode: "warehouse_east", Quantity: "25", Status: 1},
+			{SKU: "TEE-S", SourceCode: "default", Quantity: "4", Status: 1},
+			{SKU: "TEE-L", SourceCode: "warehouse_east", Quantity: "7.5", Status: 1},
+		},
+		Orders: []magento.Order{
+			{
+				EntityID: 1001, IncrementID: "000001001", State: magento.OrderStateComplete, Status: "complete",
+				CreatedAt: at(-10), UpdatedAt: at(-8), GrandTotal: "43", TotalPaid: "43", OrderCurrencyCode: "USD",
+				Items: []magento.OrderItem{
+					{ItemID: 1, ProductID: 1, ProductType: magento.ProductTypeSimple, SKU: "MUG-01", QtyOrdered: "1", QtyShipped: "1", Price: "18"},
+					{ItemID: 2, ProductID: 2, ProductType: magento.ProductTypeConfigurable, SKU: "TEE-L", QtyOrdered: "1", QtyShipped: "1", Price: "25"},
+					{ItemID: 3, ParentItemID: 2, ProductID: 4, ProductType: magento.ProductTypeSimple, SKU: "TEE-L", QtyOrdered: "1", QtyShipped: "1", Price: "0"},
+				},
+			},
+			{
+				EntityID: 1002, IncrementID: "000001002", State: magento.OrderStateCanceled, Status: "canceled",
+				CreatedAt: at(-3), UpdatedAt: at(-2), GrandTotal: "18", OrderCurrencyCode: "USD",
+				Items: []magento.OrderItem{{ItemID: 4, ProductID: 1, ProductType: magento.ProductTypeSimple, SKU: "MUG-01", QtyOrdered: "1", Price: "18"}},
+			},
+			{
+				EntityID: 1003, IncrementID: "000001003", State: magento.OrderStateProcessing, Status: "processing",
+				CreatedAt: at(-40), UpdatedAt: at(-40), GrandTotal: "18", TotalPaid: "18", OrderCurrencyCode: "USD",
+				Items: []magento.OrderItem{{ItemID: 5, ProductID: 1, ProductType: magento.ProductTypeSimple, SKU: "MUG-01", QtyOrdered: "1", Price: "18"}},
+			},
+			{
+				EntityID: 1004, IncrementID: "000001004", State: magento.OrderStateProcessing, Status: "processing",
+				CreatedAt: at(-1), UpdatedAt: at(-1), GrandTotal: "33.5", TotalPaid: "33.5", OrderCurrencyCode: "EUR",
+				BaseGrandTotal: "36", BaseCurrencyCode: "USD",
+				Items: []magento.OrderItem{{ItemID: 6, ProductID: 1, ProductType: magento.ProductTypeSimple, SKU: "MUG-01", QtyOrdered: "2", QtyShipped: "1", Price: "16.75", BasePrice: "18"}},
+			},
+		},
+	}
+}
+
+// Server is a running fake Magento store
+type Server struct {
+	*httptest.Server
+	*connectortest.Fake[Fixtures]
+}
+
+// NewServer starts a fake store serving the given fixtures
+func NewServer(fixtures *Fixtures) *Server {
+	s := &Server{Fake: connectortest.NewFake(fixtures)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Client returns a client that talks to this server with the given access token
+func (s *Server) Client(accessToken string) *magento.Client {
+	return magento.NewClient(s.URL, accessToken)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := strings.CutPrefix(r.URL.Path, apiPrefix)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Request does not match any route.", nil)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.Record(endpoint)
+
+	if r.Header.Get("Authorization") != "Bearer "+AccessToken {
+		writeError(w, http.StatusUnauthorized, "The consumer isn't authorized to access %resources.", map[string]string{"resources": "Magento_Catalog::products"})
+		return
+	}
+
+	if status, failed := s.NextFailure(endpoint); failed {
+		writeError(w, status, http.StatusText(status), nil)
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case endpoint == "/directory/currency":
+		writeJSON(w, http.StatusOK, s.Fixtures.Currency)
+	case endpoint == "/products":
+		serveSearch(w, query, s.Fixtures.Products, productField)
+	case endpoint == "/inventory/sources":
+		serveSearch(w, query, s.Fixtures.Sources, func(source magento.Source, field string) (string, bool) {
+			return source.SourceCode, field == "source_code"
+		})
+	case endpoint == "/inventory/source-items":
+		serveSearch(w, query, s.Fixtures.SourceItems, func(item magento.SourceItem, field string) (string, bool) {
+			return item.SKU, field == "sku"
+		})
+	case endpoint == "/orders":
+		serveSearch(w, query, s.Fixtures.Orders, orderField)
+	case strings.HasPrefix(endpoint, "/configurable-products/") && strings.HasSuffix(endpoint, "/children"):
+		sku, _ := url.PathUnescape(strings.TrimSuffix(strings.TrimPrefix(endpoint, "/configurable-products/"), "/children"))
+		s.serveChildren(w, sku)
+	default:
+		writeError(w, http.StatusNotFound, "Request does not match any route.", nil)
+	}
+}
+
+// serveChildren serves the products linked to a configurable product
+func (s *Server) serveChildren(w http.ResponseWriter, sku string) {
+	for _, product := range s.Fixtures.Products {
+		if product.SKU != sku || product.ExtensionAttributes == nil {
+			continue
+		}
+
+		children := []magento.Product{}
+		for _, childID := range product.ExtensionAttributes.ConfigurableProductLinks {
+			for _, child := range s.Fixtures.Products {
+				if child.ID == childID {
+					children = append(children, child)
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, children)
+		return
+	}
+	writeError(w, http.StatusNotFound, "The product that was requested doesn't exist. Verify the product and try again.", nil)
+}
+
+// productField returns the value of a product field filters can refer to
+func productField(product magento.Product, field string) (string, bool) {
+	switch field {
+	case "visibility":
+		return strconv.Itoa(product.Visibility), true
+	case "type_id":
+		return product.TypeID, true
+	case "sku":
+		return product.SKU, true
+	}
+	return "", false
+}
+
+// orderField returns the value of an order field filters can refer to
+func orderField(order magento.Order, field string) (string, bool) {
+	switch field {
+	case "created_at":
+		return order.CreatedAt.UTC().Format(timeLayout), true
+	case "state":
+		return order.State, true
+	}
+	return "", false
+}
+
+// filterPattern matches the filter parameters of searchCriteria
+var filterPattern = regexp.MustCompile(`^searchCriteria\[filter_groups\]\[(\d+)\]\[filters\]\[(\d+)\]\[(field|value|condition_type)\]$`)
+
+// filter is one parsed searchCriteria filter
+type filter struct {
+	field, value, condition string
+}
+
+// parseFilters groups the searchCriteria filter parameters, filters within a group are ORed
+func parseFilters(query url.Values) map[string]map[string]*filter {
+	groups := map[string]map[string]*filter{}
+	for key, values := range query {
+		match := filterPattern.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		if groups[match[1]] == nil {
+			groups[match[1]] = map[string]*filter{}
+		}
+		f := groups[match[1]][match[2]]
+		if f == nil {
+			f = &filter{condition: "eq"}
+			groups[match[1]][match[2]] = f
+		}
+		switch match[3] {
+		case "field":
+			f.field = values[0]
+		case "value":
+			f.value = values[0]
+		case "condition_type":
+			f.condition = values[0]
+		}
+	}
+	return groups
+}
+
+// matches reports whether a field value meets the filter. Values are compared as strings,
+// which orders the timestamp layout chronologically.
+func (f *filter) matches(value string) bool {
+	switch f.condition {
+	case "eq":
+		return value == f.value
+	case "neq":
+		return value != f.value
+	case "gteq":
+		return value >= f.value
+	case "lteq":
+		return value <= f.value
+	case "in":
+		for _, candidate := range strings.Split(f.value, ",") {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serveSearch serves one page of the items matching the searchCriteria filters. Fixtures are
+// kept in ascending ID order, the order the client sorts by. As Magento does, a page past the
+// end serves the last page again.
+func serveSearch[T any](w http.ResponseWriter, query url.Values, items []T, field func(T, string) (string, bool)) {
+	groups := parseFilters(query)
+
+	matched := []T{}
+	for _, item := range items {
+		keep := true
+		for _, group := range groups {
+			any := false
+			for _, f := range group {
+				value, ok := field(item, f.field)
+				if !ok {
+					writeError(w, http.StatusBadRequest, "\"%fieldName\" is not a filterable field.", map[string]string{"fieldName": f.field})
+					return
+				}
+				any = any || f.matches(value)
+			}
+			keep = keep && any
+		}
+		if keep {
+			matched = append(matched, item)
+		}
+	}
+
+	pageSize, currentPage := defaultPageSize, 1
+	if value := query.Get("searchCriteria[pageSize]"); value != "" {
+		pageSize, _ = strconv.Atoi(value)
+	}
+	if value := query.Get("searchCriteria[currentPage]"); value != "" {
+		currentPage, _ = strconv.Atoi(value)
+	}
+	if pageSize < 1 || currentPage < 1 {
+		writeError(w, http.StatusBadRequest, "Invalid search criteria.", nil)
+		return
+	}
+
+	lastPage := max(1, (len(matched)+pageSize-1)/pageSize)
+	start := (min(currentPage, lastPage) - 1) * pageSize
+	end := min(start+pageSize, len(matched))
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items":       append([]T{}, matched[start:end]...),
+		"total_count": len(matched),
+	})
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the Magento REST API format
+func writeError(w http.ResponseWriter, status int, message string, parameters map[string]string) {
+	body := map[string]any{"message": message}
+	if parameters != nil {
+		body["parameters"] = parameters
+	}
+	writeJSON(w, status, body)
+}
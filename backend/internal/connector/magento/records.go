@@ -0,0 +1,212 @@
+package magento
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+// parseQuantity parses a decimal quantity, rounding fractions down. A missing or invalid
+// quantity is zero.
+func parseQuantity(quantity json.Number) int {
+	value, err := quantity.Float64()
+	if err != nil {
+		return 0
+	}
+	return int(math.Floor(value))
+}
+
+// parseAmount parses a decimal amount for comparisons, a missing or invalid amount is zero
+func parseAmount(amount json.Number) float64 {
+	value, _ := amount.Float64()
+	return value
+}
+
+// toLocation converts an MSI source
+func toLocation(source Source) connector.Location {
+	return connector.Location{
+		ExternalID: source.SourceCode,
+		Name:       source.Name,
+		Address:    strings.TrimSpace(strings.Join([]string{source.Street, source.City, source.Postcode}, " ")),
+		Country:    source.CountryID,
+		Province:   source.Region,
+		Active:     source.Enabled,
+	}
+}
+
+// toProduct converts a product. A configurable product's variants are its children, any other
+// product is its own single variant. MSI counts stock by SKU, so the SKU identifies the
+// inventory item of each variant.
+func toProduct(product Product, children []Product) connector.Product {
+	status := core.ProductStatusDraft
+	if product.Status == ProductStatusEnabled {
+		status = core.ProductStatusActive
+	}
+
+	result := connector.Product{
+		ExternalID:  connector.FormatID(product.ID),
+		Title:       product.Name,
+		Handle:      product.Attribute("url_key"),
+		ProductType: product.TypeID,
+		Status:      status,
+	}
+
+	if product.TypeID != ProductTypeConfigurable {
+		result.Variants = []connector.Variant{toVariant(product, product.Price)}
+		return result
+	}
+	for _, child := range children {
+		result.Variants = append(result.Variants, toVariant(child, product.Price))
+	}
+	return result
+}
+
+// toVariant converts a simple product into a variant, priced at defaultPrice when it has no price
+// of its own
+func toVariant(product Product, defaultPrice json.Number) connector.Variant {
+	price := product.Price
+	if price == "" {
+		price = defaultPrice
+	}
+
+	// Magento manages stock unless the product opts out
+	tracked := true
+	if attributes := product.ExtensionAttributes; attributes != nil && attributes.StockItem != nil {
+		tracked = attributes.StockItem.ManageStock
+	}
+
+	item := &connector.InventoryItem{
+		ExternalID: product.SKU,
+		SKU:        product.SKU,
+		Tracked:    tracked,
+		Cost:       product.Attribute("cost"),
+	}
+	return connector.Variant{
+		ExternalID:      connector.FormatID(product.ID),
+		SKU:             product.SKU,
+		Price:           price.String(),
+		InventoryItemID: item.ExternalID,
+		InventoryItem:   item,
+	}
+}
+
+// toInventoryLevel converts the quantity of a product at a source
+func toInventoryLevel(item SourceItem) connector.InventoryLevel {
+	return connector.InventoryLevel{
+		InventoryItemID: item.SKU,
+		LocationID:      item.SourceCode,
+		Available:       parseQuantity(item.Quantity),
+	}
+}
+
+// toOrder converts a sales order. Child items only name the simple product a configurable
+// parent item was sold as, so they become the variant of their parent's line.
+func toOrder(order Order) connector.Order {
+	result := connector.Order{
+		ExternalID:        connector.FormatID(order.EntityID),
+		CreatedAt:         order.CreatedAt.Time,
+		FinancialStatus:   financialStatus(order),
+		FulfillmentStatus: fulfillmentStatus(order),
+		TotalPrice:        order.GrandTotal.String(),
+	}
+	// Orders paid in another currency than the base currency carry their amounts in both
+	if order.BaseGrandTotal != "" {
+		result.TotalPrice = order.BaseGrandTotal.String()
+		result.PresentmentCurrency = order.OrderCurrencyCode
+		result.PresentmentTotalPrice = order.GrandTotal.String()
+	}
+
+	if order.State == OrderStateCanceled {
+		cancelledAt := order.UpdatedAt.Time
+		result.CancelledAt = &cancelledAt
+	}
+
+	children := make(map[int64]OrderItem)
+	for _, item := range order.Items {
+		if item.ParentItemID != 0 {
+			children[item.ParentItemID] = item
+		}
+	}
+
+	for _, item := range order.Items {
+		if item.ParentItemID != 0 {
+			continue
+		}
+
+		price := item.Price
+		if item.BasePrice != "" {
+			price = item.BasePrice
+		}
+		variantID := item.ProductID
+		if child, ok := children[item.ItemID]; ok && item.ProductType == ProductTypeConfigurable {
+			variantID = child.ProductID
+		}
+		result.LineItems = append(result.LineItems, connector.OrderLineItem{
+			ExternalID: connector.FormatID(item.ItemID),
+			ProductID:  connector.FormatID(item.ProductID),
+			VariantID:  connector.FormatID(variantID),
+			SKU:        item.SKU,
+			Quantity:   parseQuantity(item.QtyOrdered),
+			Price:      price.String(),
+		})
+	}
+
+	return result
+}
+
+// financialStatus derives the financial status of an order from its state and paid and refunded totals
+func financialStatus(order Order) core.FinancialStatus {
+	grandTotal, paid, refunded := parseAmount(order.GrandTotal), parseAmount(order.TotalPaid), parseAmount(order.TotalRefunded)
+
+	switch {
+	case order.State == OrderStateCanceled:
+		return core.FinancialStatusVoided
+	case order.State == OrderStateClosed || (refunded > 0 && refunded >= grandTotal):
+		return core.FinancialStatusRefunded
+	case refunded > 0:
+		return core.FinancialStatusPartiallyRefunded
+	// Processing and complete orders have been invoiced
+	case order.State == OrderStateProcessing || order.State == OrderStateComplete:
+		return core.FinancialStatusPaid
+	case paid > 0 && paid >= grandTotal:
+		return core.FinancialStatusPaid
+	case paid > 0:
+		return core.FinancialStatusPartiallyPaid
+	default:
+		return core.FinancialStatusPending
+	}
+}
+
+// fulfillmentStatus derives the fulfillment status of an order from the shipped quantities of its items
+func fulfillmentStatus(order Order) core.FulfillmentStatus {
+	if order.State == OrderStateComplete {
+		return core.FulfillmentStatusFulfilled
+	}
+
+	var ordered, shipped float64
+	for _, item := range order.Items {
+		if item.ParentItemID != 0 {
+			continue
+		}
+		ordered += parseAmount(item.QtyOrdered)
+		shipped += parseAmount(item.QtyShipped)
+	}
+
+	switch {
+	case shipped > 0 && shipped >= ordered:
+		return core.FulfillmentStatusFulfilled
+	case shipped > 0:
+		return core.FulfillmentStatusPartial
+	default:
+		return core.FulfillmentStatusNull
+	}
+}
+
+// formatTime formats a time for a search filter
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
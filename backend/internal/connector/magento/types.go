@@ -0,0 +1,176 @@
+package magento
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Product type IDs
+const (
+	ProductTypeSimple       = "simple"
+	ProductTypeVirtual      = "virtual"
+	ProductTypeConfigurable = "configurable"
+	ProductTypeBundle       = "bundle"
+	ProductTypeGrouped      = "grouped"
+)
+
+// Product statuses and visibilities
+const (
+	ProductStatusEnabled  = 1
+	ProductStatusDisabled = 2
+
+	// Children of configurable products are usually only visible through their parent
+	VisibilityNotVisibleIndividually = 1
+	VisibilityCatalogSearch          = 4
+)
+
+// Order states, a store's order statuses are custom labels within these
+const (
+	OrderStateNew            = "new"
+	OrderStatePendingPayment = "pending_payment"
+	OrderStateProcessing     = "processing"
+	OrderStateComplete       = "complete"
+	OrderStateClosed         = "closed"
+	OrderStateCanceled       = "canceled"
+	OrderStateHolded         = "holded"
+	OrderStatePaymentReview  = "payment_review"
+)
+
+// Product represents a product from the catalog API
+type Product struct {
+	ID                  int64                       `json:"id"`
+	SKU                 string                      `json:"sku"`
+	Name                string                      `json:"name"`
+	TypeID              string                      `json:"type_id"`
+	Status              int                         `json:"status"`
+	Visibility          int                         `json:"visibility"`
+	Price               json.Number                 `json:"price,omitempty"`
+	ExtensionAttributes *ProductExtensionAttributes `json:"extension_attributes,omitempty"`
+	CustomAttributes    []CustomAttribute           `json:"custom_attributes,omitempty"`
+}
+
+// ProductExtensionAttributes holds the attributes modules add to products
+type ProductExtensionAttributes struct {
+	ConfigurableProductLinks []int64    `json:"configurable_product_links,omitempty"`
+	StockItem                *StockItem `json:"stock_item,omitempty"`
+}
+
+// StockItem holds the legacy (default source) stock settings of a product
+type StockItem struct {
+	Qty         json.Number `json:"qty,omitempty"`
+	IsInStock   bool        `json:"is_in_stock"`
+	ManageStock bool        `json:"manage_stock"`
+}
+
+// CustomAttribute is an EAV attribute of a product ("url_key", "cost"). Values are strings,
+// or arrays for multiselect attributes.
+type CustomAttribute struct {
+	AttributeCode string          `json:"attribute_code"`
+	Value         json.RawMessage `json:"value"`
+}
+
+// Attribute returns the string value of a custom attribute, or "" when the product has no such
+// attribute or its value is not a string
+func (p Product) Attribute(code string) string {
+	for _, attribute := range p.CustomAttributes {
+		if attribute.AttributeCode != code {
+			continue
+		}
+		var value string
+		if json.Unmarshal(attribute.Value, &value) == nil {
+			return value
+		}
+		return ""
+	}
+	return ""
+}
+
+// Source represents an MSI source: a warehouse, store or drop shipper stock is held at
+type Source struct {
+	SourceCode string `json:"source_code"`
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+	CountryID  string `json:"country_id"`
+	Region     string `json:"region,omitempty"`
+	City       string `json:"city,omitempty"`
+	Street     string `json:"street,omitempty"`
+	Postcode   string `json:"postcode"`
+}
+
+// SourceItem represents the quantity of a product at a source
+type SourceItem struct {
+	SKU        string      `json:"sku"`
+	SourceCode string      `json:"source_code"`
+	Quantity   json.Number `json:"quantity"`
+	Status     int         `json:"status"` // 1 in stock, 0 out of stock
+}
+
+// Order represents a sales order
+type Order struct {
+	EntityID          int64       `json:"entity_id"`
+	IncrementID       string      `json:"increment_id"`
+	State             string      `json:"state"`
+	Status            string      `json:"status"`
+	CreatedAt         Timestamp   `json:"created_at"`
+	UpdatedAt         Timestamp   `json:"updated_at"`
+	GrandTotal        json.Number `json:"grand_total"`
+	TotalPaid         json.Number `json:"total_paid,omitempty"`
+	TotalRefunded     json.Number `json:"total_refunded,omitempty"`
+	OrderCurrencyCode string      `json:"order_currency_code"`
+	Items             []OrderItem `json:"items"`
+	// The grand total in the base currency of the store, which the other amounts are in when
+	// the customer paid in the base currency
+	BaseGrandTotal   json.Number `json:"base_grand_total,omitempty"`
+	BaseCurrencyCode string      `json:"base_currency_code,omitempty"`
+}
+
+// OrderItem represents an item of an order. A configurable product is ordered as a parent item
+// carrying the price and a child item, with ParentItemID set, naming the simple product.
+type OrderItem struct {
+	ItemID       int64       `json:"item_id"`
+	ParentItemID int64       `json:"parent_item_id,omitempty"`
+	ProductID    int64       `json:"product_id"`
+	ProductType  string      `json:"product_type"`
+	SKU          string      `json:"sku"`
+	QtyOrdered   json.Number `json:"qty_ordered"`
+	QtyShipped   json.Number `json:"qty_shipped,omitempty"`
+	Price        json.Number `json:"price"`
+	BasePrice    json.Number `json:"base_price,omitempty"`
+}
+
+// StoreCurrency represents the currency information of the store
+type StoreCurrency struct {
+	BaseCurrencyCode           string `json:"base_currency_code"`
+	DefaultDisplayCurrencyCode string `json:"default_display_currency_code"`
+}
+
+// Timestamp is a Magento timestamp ("2024-01-15 10:30:00"), always in UTC
+type Timestamp struct {
+	time.Time
+}
+
+// MarshalJSON writes the timestamp in the Magento format, a zero timestamp as null
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(timeLayout))
+}
+
+// UnmarshalJSON parses the timestamp, an empty or null timestamp is left zero
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		return nil
+	}
+
+	parsed, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timestamp %s", data)
+	}
+	t.Time = parsed
+	return nil
+}
@@ -0,0 +1,115 @@
+package connector
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+// Records are keyed by the IDs the platform assigns (external IDs) and carry values already
+// normalized to the core schema: statuses use the core enums and amounts are decimal strings.
+// Assigning internal IDs and parsing amounts is left to the sync pipeline.
+
+// FormatID formats a numeric platform ID as an external ID
+func FormatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// Location is a place stock is held at
+type Location struct {
+	ExternalID string
+	Name       string
+	Address    string
+	Country    string
+	Province   string
+	Active     bool
+}
+
+// Product is a sellable product and its variants
+type Product struct {
+	ExternalID  string
+	Title       string
+	Handle      string
+	ProductType string
+	Status      core.ProductStatus
+	Variants    []Variant
+
+	// Vendor is the brand or supplier of the product and Tags its labels, both empty when the
+	// platform has none
+	Vendor string
+	Tags   []string
+
+	// Options are the names of the product's options (Size, Color) in position order
+	Options []string
+
+	// PublishedAt is when the product was published to the storefront, nil while unpublished
+	PublishedAt *time.Time
+}
+
+// Variant is a purchasable version of a product
+type Variant struct {
+	ExternalID string
+	SKU        string
+	Price      string
+
+	// Barcode is the variant's GTIN, UPC or EAN, empty when the platform has none
+	Barcode string
+
+	// Options are the variant's value of each of the product's options, in the same order
+	Options []string
+
+	// CompareAtPrice is the price before a discount, empty when the variant is not discounted
+	CompareAtPrice string
+
+	// Weight is a decimal string in WeightUnit (g, kg, lb or oz), empty when not known
+	Weight     string
+	WeightUnit string
+
+	// InventoryItemID is the external ID of the inventory item tracking the variant's stock
+	InventoryItemID string
+
+	// InventoryItem holds the details of that item, nil when the platform returned none
+	InventoryItem *InventoryItem
+}
+
+// InventoryItem is the stock-keeping unit behind a variant
+type InventoryItem struct {
+	ExternalID string
+	SKU        string
+	Tracked    bool
+	Cost       string
+}
+
+// InventoryLevel is the available quantity of an inventory item at a location
+type InventoryLevel struct {
+	InventoryItemID string
+	LocationID      string
+	Available       int
+}
+
+// Order is a customer order. TotalPrice and the line item prices are in the store currency.
+type Order struct {
+	ExternalID        string
+	CreatedAt         time.Time
+	FinancialStatus   core.FinancialStatus
+	FulfillmentStatus core.FulfillmentStatus
+	TotalPrice        string
+	CancelledAt       *time.Time
+	LineItems         []OrderLineItem
+
+	// PresentmentCurrency is the currency the customer paid in and PresentmentTotalPrice the
+	// total in it, both empty when the platform does not report them
+	PresentmentCurrency   string
+	PresentmentTotalPrice string
+}
+
+// OrderLineItem is one product line of an order
+type OrderLineItem struct {
+	ExternalID string
+	ProductID  string
+	VariantID  string
+	SKU        string
+	Quantity   int
+	Price      string
+}
@@ -0,0 +1,77 @@
+package connector
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Rate limited and server error responses are retried this many times
+	maxRetries = 3
+
+	// Base delay of the exponential backoff between retries
+	retryBaseDelay = 500 * time.Millisecond
+
+	// Longest wait between two attempts, whether backing off or waiting out a Retry-After
+	maxRetryDelay = 30 * time.Second
+)
+
+// APIError is implemented by the API errors of the platform clients, so that Retry can decide
+// whether and when to send a failed request again
+type APIError interface {
+	error
+
+	// HTTPStatus returns the status code of the failed response
+	HTTPStatus() int
+
+	// RetryDelay returns the wait the platform asked for (Retry-After), or zero when it gave none
+	RetryDelay() time.Duration
+}
+
+// Retry calls do until it succeeds. Rate limited and server error APIErrors are retried up to
+// maxRetries times, waiting the platform's RetryDelay or else a jittered exponential backoff,
+// at most maxRetryDelay either way.
+// The last APIError is returned tagged WithStatus, any other error is returned as it is.
+// platform and path only label the retry log lines.
+func Retry(ctx context.Context, platform, path string, do func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := do()
+		if err == nil {
+			return nil
+		}
+
+		var apiErr APIError
+		if !errors.As(err, &apiErr) {
+			return err
+		}
+		status := apiErr.HTTPStatus()
+		retryable := status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+		if !retryable || attempt >= maxRetries {
+			return WithStatus(err, status)
+		}
+
+		delay := retryDelay(attempt, apiErr.RetryDelay())
+		logger.Warn("Retrying "+platform+" request", "path", path, "status", status, "attempt", attempt+1, "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "context cancelled while waiting to retry")
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryDelay returns the wait before retrying after the given attempt (0-based): the delay the
+// platform asked for, or else a jittered exponential backoff, capped at maxRetryDelay
+func retryDelay(attempt int, requested time.Duration) time.Duration {
+	if requested == 0 {
+		backoff := min(retryBaseDelay<<attempt, maxRetryDelay)
+		requested = backoff/2 + rand.N(backoff/2)
+	}
+	return min(requested, maxRetryDelay)
+}
@@ -0,0 +1,84 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type testAPIError struct {
+	status     int
+	retryAfter time.Duration
+}
+
+func (e *testAPIError) Error() string             { return fmt.Sprintf("status %d", e.status) }
+func (e *testAPIError) HTTPStatus() int           { return e.status }
+func (e *testAPIError) RetryDelay() time.Duration { return e.retryAfter }
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	err := Retry(ctx, "Test", "/items", func() error {
+		calls++
+		if calls < 3 {
+			return &testAPIError{status: http.StatusTooManyRequests, retryAfter: time.Millisecond}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("rate limited request got %v after %d calls, want success on the third", err, calls)
+	}
+
+	calls = 0
+	err = Retry(ctx, "Test", "/items", func() error {
+		calls++
+		return &testAPIError{status: http.StatusNotFound}
+	})
+	if !errors.Is(err, ErrNotFound) || calls != 1 {
+		t.Errorf("not found request got %v after %d calls, want ErrNotFound without a retry", err, calls)
+	}
+
+	calls = 0
+	err = Retry(ctx, "Test", "/items", func() error {
+		calls++
+		return &testAPIError{status: http.StatusBadGateway, retryAfter: time.Millisecond}
+	})
+	if !errors.Is(err, ErrServer) || calls != maxRetries+1 {
+		t.Errorf("failing request got %v after %d calls, want ErrServer after %d", err, calls, maxRetries+1)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = Retry(cancelled, "Test", "/items", func() error {
+		return &testAPIError{status: http.StatusServiceUnavailable, retryAfter: time.Hour}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled retry got %v, want context.Canceled", err)
+	}
+
+	plain := errors.New("connection reset")
+	if err := Retry(ctx, "Test", "/items", func() error { return plain }); err != plain {
+		t.Errorf("non-API error got %v, want it returned as it is", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	// The platform's Retry-After is honoured up to the cap
+	if got := retryDelay(0, 2*time.Second); got != 2*time.Second {
+		t.Errorf("delay for a 2s Retry-After = %s, want 2s", got)
+	}
+	if got := retryDelay(0, time.Hour); got != maxRetryDelay {
+		t.Errorf("delay for a 1h Retry-After = %s, want %s", got, maxRetryDelay)
+	}
+
+	for attempt := range 10 {
+		if got := retryDelay(attempt, 0); got <= 0 || got > maxRetryDelay {
+			t.Errorf("backoff after attempt %d = %s, want between 0 and %s", attempt, got, maxRetryDelay)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package shopify
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	shopifyapi "github.com/ConradKurth/forecasting/backend/internal/shopify"
+)
+
+// stringValue returns the string a nullable Shopify field points to, empty when it is null
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// splitTags splits Shopify's comma-separated tags, dropping empty ones
+func splitTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// toLocation converts a Shopify location. The REST API only lists active locations.
+func toLocation(location shopifyapi.ShopifyLocation) connector.Location {
+	var addressParts []string
+	for _, part := range []string{location.Address1, location.Address2, location.City} {
+		if part != "" {
+			addressParts = append(addressParts, part)
+		}
+	}
+
+	return connector.Location{
+		ExternalID: connector.FormatID(location.ID),
+		Name:       location.Name,
+		Address:    strings.Join(addressParts, ", "),
+		Country:    location.Country,
+		Province:   location.Province,
+		Active:     true,
+	}
+}
+
+// toProduct converts a Shopify product, attaching the inventory item of each variant when it was fetched
+func toProduct(product shopifyapi.ShopifyProduct, inventoryItems map[int64]shopifyapi.ShopifyInventoryItem) connector.Product {
+	var status core.ProductStatus
+	switch product.Status {
+	case "active":
+		status = core.ProductStatusActive
+	case "archived":
+		status = core.ProductStatusArchived
+	default:
+		status = core.ProductStatusDraft
+	}
+
+	result := connector.Product{
+		ExternalID:  connector.FormatID(product.ID),
+		Title:       product.Title,
+		Handle:      product.Handle,
+		ProductType: product.ProductType,
+		Status:      status,
+		Vendor:      product.Vendor,
+		Tags:        splitTags(product.Tags),
+		PublishedAt: product.PublishedAt,
+	}
+
+	options := slices.Clone(product.Options)
+	slices.SortFunc(options, func(a, b shopifyapi.ShopifyProductOption) int {
+		return a.Position - b.Position
+	})
+	// Products without options carry Shopify's placeholder option, which is not kept
+	if len(options) == 1 && options[0].Name == "Title" && slices.Equal(options[0].Values, []string{"Default Title"}) {
+		options = nil
+	}
+	for _, option := range options {
+		result.Options = append(result.Options, option.Name)
+	}
+
+	for _, variant := range product.Variants {
+		converted := connector.Variant{
+			ExternalID:     connector.FormatID(variant.ID),
+			SKU:            variant.SKU,
+			Price:          variant.Price,
+			Barcode:        stringValue(variant.Barcode),
+			CompareAtPrice: stringValue(variant.CompareAtPrice),
+		}
+		for _, value := range []*string{variant.Option1, variant.Option2, variant.Option3}[:min(len(options), 3)] {
+			converted.Options = append(converted.Options, stringValue(value))
+		}
+		if variant.WeightUnit != "" {
+			converted.Weight = strconv.FormatFloat(variant.Weight, 'f', -1, 64)
+			converted.WeightUnit = variant.WeightUnit
+		}
+		if variant.InventoryItemID != 0 {
+			converted.InventoryItemID = connector.FormatID(variant.InventoryItemID)
+		}
+		if item, ok := inventoryItems[variant.InventoryItemID]; ok {
+			converted.InventoryItem = &connector.InventoryItem{
+				ExternalID: connector.FormatID(item.ID),
+				SKU:        item.SKU,
+				Tracked:    item.Tracked,
+				Cost:       item.Cost,
+			}
+		}
+		result.Variants = append(result.Variants, converted)
+	}
+
+	return result
+}
+
+// toOrder converts a Shopify order, mapping its statuses to the core enums
+func toOrder(order shopifyapi.ShopifyOrder) connector.Order {
+	var financialStatus core.FinancialStatus
+	switch order.FinancialStatus {
+	case "authorized":
+		financialStatus = core.FinancialStatusAuthorized
+	case "partially_paid":
+		financialStatus = core.FinancialStatusPartiallyPaid
+	case "paid":
+		financialStatus = core.FinancialStatusPaid
+	case "partially_refunded":
+		financialStatus = core.FinancialStatusPartiallyRefunded
+	case "refunded":
+		financialStatus = core.FinancialStatusRefunded
+	case "voided":
+		financialStatus = core.FinancialStatusVoided
+	default:
+		financialStatus = core.FinancialStatusPending
+	}
+
+	fulfillmentStatus := core.FulfillmentStatusNull
+	if order.FulfillmentStatus != nil {
+		switch *order.FulfillmentStatus {
+		case "fulfilled":
+			fulfillmentStatus = core.FulfillmentStatusFulfilled
+		case "partial":
+			fulfillmentStatus = core.FulfillmentStatusPartial
+		case "restocked":
+			fulfillmentStatus = core.FulfillmentStatusRestocked
+		}
+	}
+
+	result := connector.Order{
+		ExternalID:        connector.FormatID(order.ID),
+		CreatedAt:         order.CreatedAt,
+		FinancialStatus:   financialStatus,
+		FulfillmentStatus: fulfillmentStatus,
+		TotalPrice:        order.TotalPrice,
+		CancelledAt:       order.CancelledAt,
+	}
+	if order.PresentmentCurrency != "" && order.TotalPriceSet != nil {
+		result.PresentmentCurrency = order.PresentmentCurrency
+		result.PresentmentTotalPrice = order.TotalPriceSet.PresentmentMoney.Amount
+	}
+
+	for _, item := range order.LineItems {
+		lineItem := connector.OrderLineItem{
+			ExternalID: connector.FormatID(item.ID),
+			Quantity:   item.Quantity,
+			Price:      item.Price,
+		}
+		if item.ProductID != nil {
+			lineItem.ProductID = connector.FormatID(*item.ProductID)
+		}
+		if item.VariantID != nil {
+			lineItem.VariantID = connector.FormatID(*item.VariantID)
+		}
+		result.LineItems = append(result.LineItems, lineItem)
+	}
+
+	return result
+}
@@ -0,0 +1,255 @@
+// Package shopify adapts the Shopify Admin API client to connector.PlatformConnector
+package shopify
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	shopifyapi "github.com/ConradKurth/forecasting/backend/internal/shopify"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Page size requested from the Shopify API
+	pageSize = 250
+
+	// Inventory items are looked up by ID in batches of this size
+	inventoryItemBatchSize = 100
+
+	// Inventory levels are listed for at most this many locations per request
+	locationBatchSize = 50
+)
+
+// Connector reads a Shopify store through the Admin API
+type Connector struct {
+	client *shopifyapi.Client
+
+	// Location IDs of the store in ascending order, loaded on the first inventory levels page
+	locationIDs []int64
+}
+
+var (
+	_ connector.PlatformConnector = (*Connector)(nil)
+	_ connector.Counter           = (*Connector)(nil)
+	_ connector.CurrencyReader    = (*Connector)(nil)
+)
+
+// New creates a connector that reads through the given client
+func New(client *shopifyapi.Client) *Connector {
+	return &Connector{client: client}
+}
+
+// Factory creates the connectors of Shopify integrations, authenticated with the access token of one of the store's users
+func Factory(database db.Database) connector.Factory {
+	return func(ctx context.Context, integration core.PlatformIntegration) (connector.PlatformConnector, error) {
+		users, err := database.GetShopify().GetShopifyUsersByStore(ctx, integration.ShopID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get shopify users")
+		}
+
+		// Use the first user with a valid access token
+		for _, user := range users {
+			if token := user.AccessToken.String(); token != "" {
+				return New(shopifyapi.NewClient(integration.PlatformShopID, token)), nil
+			}
+		}
+
+		return nil, errors.New("no shopify user with valid access token found")
+	}
+}
+
+// Platform implements connector.PlatformConnector
+func (c *Connector) Platform() core.PlatformType {
+	return core.PlatformTypeShopify
+}
+
+// ListLocations implements connector.PlatformConnector
+func (c *Connector) ListLocations(ctx context.Context, cursor string) (*connector.Page[connector.Location], error) {
+	response, err := c.client.GetLocations(ctx, pageSize, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Location]{NextCursor: response.Pagination.NextPageInfo}
+	for _, location := range response.Locations {
+		page.Items = append(page.Items, toLocation(location))
+	}
+	return page, nil
+}
+
+// ListProducts implements connector.PlatformConnector. Inventory items are fetched for the variants of each page.
+func (c *Connector) ListProducts(ctx context.Context, cursor string) (*connector.Page[connector.Product], error) {
+	response, err := c.client.GetProducts(ctx, pageSize, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	inventoryItems, err := c.fetchInventoryItems(ctx, response.Products)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Product]{NextCursor: response.Pagination.NextPageInfo}
+	for _, product := range response.Products {
+		page.Items = append(page.Items, toProduct(product, inventoryItems))
+	}
+	return page, nil
+}
+
+// ListInventoryLevels implements connector.PlatformConnector.
+//
+// Shopify lists levels by location, a bounded number of locations at a time, so the cursor is
+// "<batch>:<page_info>": the index of the location batch and the Shopify cursor within it.
+func (c *Connector) ListInventoryLevels(ctx context.Context, cursor string) (*connector.Page[connector.InventoryLevel], error) {
+	batch, pageInfo, err := parseLevelsCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.locationIDs == nil {
+		if c.locationIDs, err = c.listLocationIDs(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	start := batch * locationBatchSize
+	if start >= len(c.locationIDs) {
+		return &connector.Page[connector.InventoryLevel]{}, nil
+	}
+	locationIDs := c.locationIDs[start:min(start+locationBatchSize, len(c.locationIDs))]
+
+	response, err := c.client.GetInventoryLevelsByLocations(ctx, locationIDs, pageSize, pageInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.InventoryLevel]{}
+	for _, level := range response.InventoryLevels {
+		page.Items = append(page.Items, connector.InventoryLevel{
+			InventoryItemID: connector.FormatID(level.InventoryItemID),
+			LocationID:      connector.FormatID(level.LocationID),
+			Available:       level.Available,
+		})
+	}
+
+	switch {
+	case response.Pagination.NextPageInfo != "":
+		page.NextCursor = formatLevelsCursor(batch, response.Pagination.NextPageInfo)
+	case start+locationBatchSize < len(c.locationIDs):
+		page.NextCursor = formatLevelsCursor(batch+1, "")
+	}
+	return page, nil
+}
+
+// ListOrders implements connector.PlatformConnector
+func (c *Connector) ListOrders(ctx context.Context, since time.Time, cursor string) (*connector.Page[connector.Order], error) {
+	response, err := c.client.GetOrders(ctx, since, pageSize, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Order]{NextCursor: response.Pagination.NextPageInfo}
+	for _, order := range response.Orders {
+		page.Items = append(page.Items, toOrder(order))
+	}
+	return page, nil
+}
+
+// CountLocations implements connector.Counter
+func (c *Connector) CountLocations(ctx context.Context) (int, error) {
+	return c.client.GetLocationsCount(ctx)
+}
+
+// CountProducts implements connector.Counter
+func (c *Connector) CountProducts(ctx context.Context) (int, error) {
+	return c.client.GetProductsCount(ctx)
+}
+
+// CountOrders implements connector.Counter
+func (c *Connector) CountOrders(ctx context.Context, since time.Time) (int, error) {
+	return c.client.GetOrdersCount(ctx, since)
+}
+
+// Currency implements connector.CurrencyReader with the currency of the shop
+func (c *Connector) Currency(ctx context.Context) (string, error) {
+	shop, err := c.client.GetShop(ctx)
+	if err != nil {
+		return "", err
+	}
+	return shop.Currency, nil
+}
+
+// fetchInventoryItems fetches the inventory items referenced by the variants of the given products, by ID
+func (c *Connector) fetchInventoryItems(ctx context.Context, products []shopifyapi.ShopifyProduct) (map[int64]shopifyapi.ShopifyInventoryItem, error) {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, product := range products {
+		for _, variant := range product.Variants {
+			if variant.InventoryItemID != 0 && !seen[variant.InventoryItemID] {
+				seen[variant.InventoryItemID] = true
+				ids = append(ids, variant.InventoryItemID)
+			}
+		}
+	}
+
+	items := make(map[int64]shopifyapi.ShopifyInventoryItem, len(ids))
+	for start := 0; start < len(ids); start += inventoryItemBatchSize {
+		end := min(start+inventoryItemBatchSize, len(ids))
+
+		response, err := c.client.GetInventoryItems(ctx, ids[start:end], inventoryItemBatchSize, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get inventory items batch %d-%d", start, end)
+		}
+		for _, item := range response.InventoryItems {
+			items[item.ID] = item
+		}
+	}
+
+	return items, nil
+}
+
+// listLocationIDs lists the IDs of all locations of the store in ascending order, so that location
+// batches stay the same across processes resuming from a checkpointed cursor
+func (c *Connector) listLocationIDs(ctx context.Context) ([]int64, error) {
+	ids := []int64{}
+	for pageInfo := ""; ; {
+		response, err := c.client.GetLocations(ctx, pageSize, pageInfo)
+		if err != nil {
+			return nil, err
+		}
+		for _, location := range response.Locations {
+			ids = append(ids, location.ID)
+		}
+		if pageInfo = response.Pagination.NextPageInfo; pageInfo == "" {
+			break
+		}
+	}
+
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// formatLevelsCursor encodes an inventory levels cursor
+func formatLevelsCursor(batch int, pageInfo string) string {
+	return strconv.Itoa(batch) + ":" + pageInfo
+}
+
+// parseLevelsCursor decodes an inventory levels cursor, an empty cursor being the first page of the first batch
+func parseLevelsCursor(cursor string) (int, string, error) {
+	if cursor == "" {
+		return 0, "", nil
+	}
+
+	batchPart, pageInfo, ok := strings.Cut(cursor, ":")
+	batch, err := strconv.Atoi(batchPart)
+	if !ok || err != nil || batch < 0 {
+		return 0, "", errors.Errorf("invalid inventory levels cursor %q", cursor)
+	}
+	return batch, pageInfo, nil
+}
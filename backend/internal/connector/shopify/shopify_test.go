@@ -0,0 +1,198 @@
+package shopify_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	shopifyconnector "github.com/ConradKurth/forecasting/backend/internal/connector/shopify"
+	shopifyapi "github.com/ConradKurth/forecasting/backend/internal/shopify"
+	"github.com/ConradKurth/forecasting/backend/internal/shopify/shopifytest"
+	"github.com/pkg/errors"
+)
+
+const testToken = "shpat_test"
+
+func newConnector(t *testing.T) (*shopifyconnector.Connector, *shopifytest.Server) {
+	t.Helper()
+
+	server := shopifytest.NewServer(shopifytest.DemoFixtures(), shopifytest.WithAccessToken(testToken))
+	t.Cleanup(server.Close)
+	return shopifyconnector.New(server.ShopifyClient(testToken)), server
+}
+
+// addLocations adds n locations to the shop, each stocking one item, and returns the number of inventory levels
+func addLocations(server *shopifytest.Server, n int) int {
+	var levels int
+	server.UpdateFixtures(func(f *shopifytest.Fixtures) {
+		itemID := f.InventoryItems[0].ID
+		for i := int64(1); i <= int64(n); i++ {
+			locationID := 900000 + i
+			f.Locations = append(f.Locations, shopifyapi.ShopifyLocation{ID: locationID, Name: "Extra"})
+			f.InventoryLevels = append(f.InventoryLevels, shopifyapi.ShopifyInventoryLevel{InventoryItemID: itemID, LocationID: locationID, Available: 1})
+		}
+		levels = len(f.InventoryLevels)
+	})
+	return levels
+}
+
+func TestListProductsAttachesInventoryItems(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	products := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Product], error) {
+		return conn.ListProducts(ctx, cursor)
+	})
+
+	count, err := conn.CountProducts(ctx)
+	if err != nil {
+		t.Fatalf("CountProducts: %v", err)
+	}
+	if len(products) != count {
+		t.Errorf("listed %d products, count reports %d", len(products), count)
+	}
+
+	for _, product := range products {
+		if product.Status == "" {
+			t.Errorf("product %s has no status", product.ExternalID)
+		}
+		for _, variant := range product.Variants {
+			if variant.InventoryItemID == "" {
+				continue
+			}
+			if variant.InventoryItem == nil {
+				t.Errorf("variant %s has no inventory item attached", variant.ExternalID)
+				continue
+			}
+			if variant.InventoryItem.ExternalID != variant.InventoryItemID {
+				t.Errorf("variant %s references item %s but has item %s attached", variant.ExternalID, variant.InventoryItemID, variant.InventoryItem.ExternalID)
+			}
+		}
+	}
+}
+
+func TestListInventoryLevelsCoversEveryLocationBatch(t *testing.T) {
+	conn, server := newConnector(t)
+	ctx := context.Background()
+
+	// Push the store past one batch of locations
+	want := addLocations(server, 60)
+
+	levels := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.InventoryLevel], error) {
+		return conn.ListInventoryLevels(ctx, cursor)
+	})
+
+	if len(levels) != want {
+		t.Errorf("listed %d inventory levels, want %d", len(levels), want)
+	}
+
+	seen := make(map[connector.InventoryLevel]bool)
+	for _, level := range levels {
+		if seen[level] {
+			t.Errorf("inventory level %+v listed twice", level)
+		}
+		seen[level] = true
+	}
+}
+
+func TestListInventoryLevelsResumesFromCursor(t *testing.T) {
+	conn, server := newConnector(t)
+	ctx := context.Background()
+	addLocations(server, 60)
+
+	first, err := conn.ListInventoryLevels(ctx, "")
+	if err != nil {
+		t.Fatalf("ListInventoryLevels: %v", err)
+	}
+	if first.NextCursor == "" {
+		t.Fatal("expected a second page of inventory levels")
+	}
+
+	// A new connector, as in a process resuming from a checkpoint, accepts the cursor
+	resumed := shopifyconnector.New(server.ShopifyClient(testToken))
+	if _, err := resumed.ListInventoryLevels(ctx, first.NextCursor); err != nil {
+		t.Fatalf("ListInventoryLevels from cursor %q: %v", first.NextCursor, err)
+	}
+
+	if _, err := resumed.ListInventoryLevels(ctx, "not-a-cursor"); err == nil {
+		t.Error("expected an error for a malformed cursor")
+	}
+}
+
+func TestErrorsKeepShopifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   error
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, want: shopifyapi.ErrUnauthorized},
+		{name: "not found", status: http.StatusNotFound, want: shopifyapi.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, server := newConnector(t)
+			server.FailNext("/locations.json", tt.status, 1)
+
+			_, err := conn.ListLocations(context.Background(), "")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want an error matching %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListOrdersKeepsPresentmentTotals(t *testing.T) {
+	conn, _ := newConnector(t)
+
+	orders := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Order], error) {
+		return conn.ListOrders(context.Background(), time.Now().AddDate(0, 0, -90), cursor)
+	})
+	presented := map[string]connector.Order{}
+	for _, order := range orders {
+		if order.PresentmentCurrency != "" {
+			presented[order.ExternalID] = order
+		}
+	}
+	order, ok := presented["4400"]
+	if len(presented) != 1 || !ok || order.TotalPrice != "24.00" || order.PresentmentCurrency != "CAD" || order.PresentmentTotalPrice != "32.50" {
+		t.Errorf("orders paid in another currency = %+v, want order 4400 paid 32.50 CAD", presented)
+	}
+}
+
+func TestListProductsKeepsAttributes(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	products := map[string]connector.Product{}
+	for _, product := range connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Product], error) {
+		return conn.ListProducts(ctx, cursor)
+	}) {
+		products[product.ExternalID] = product
+	}
+
+	tee := products["7001"]
+	if tee.Vendor != "Fake Shop Apparel" || !slices.Equal(tee.Tags, []string{"summer", "tees", "bestseller"}) || !slices.Equal(tee.Options, []string{"Color", "Size"}) {
+		t.Errorf("tee = %+v, want its vendor, tags and Color and Size options", tee)
+	}
+	if tee.PublishedAt == nil || !tee.PublishedAt.Equal(time.Date(2025, 6, 3, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("tee published at %v, want 2025-06-03 13:00 UTC", tee.PublishedAt)
+	}
+	variant := tee.Variants[0]
+	if variant.Barcode != "0012345600011" || variant.CompareAtPrice != "30.00" || !slices.Equal(variant.Options, []string{"Black", "M"}) || variant.Weight != "0.2" || variant.WeightUnit != "kg" {
+		t.Errorf("tee variant = %+v, want its barcode, compare at price, options and weight", variant)
+	}
+
+	// The placeholder option of products without options is dropped, and unpublished products have no published time
+	pin := products["7004"]
+	if len(pin.Options) != 0 || len(pin.Variants[0].Options) != 0 || len(pin.Tags) != 0 || pin.PublishedAt != nil {
+		t.Errorf("pin = %+v, want no options, tags or published time", pin)
+	}
+	if pin.Variants[0].Barcode != "" || pin.Variants[0].CompareAtPrice != "" {
+		t.Errorf("pin variant = %+v, want no barcode or compare at price", pin.Variants[0])
+	}
+}
@@ -0,0 +1,265 @@
+// Package square reads Square sellers through the Catalog, Inventory, Locations and Orders APIs
+// and adapts them to connector.PlatformConnector
+package square
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Default API host, used when the config does not set one
+	defaultBaseURL = "https://connect.squareup.com"
+
+	// API version requested with the Square-Version header
+	apiVersion = "2025-01-23"
+
+	// Largest page sizes the inventory counts and order search endpoints accept
+	maxCountsLimit = 1000
+	maxOrdersLimit = 1000
+
+	// Largest number of locations an order search can cover
+	maxSearchLocations = 10
+)
+
+// Client is a Square API client for one seller, authenticated with an access token
+type Client struct {
+	baseURL     string
+	accessToken string
+	httpClient  *http.Client
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithBaseURL sends requests to baseURL ("http://127.0.0.1:8092") instead of the configured Square
+// API host, e.g. to a fake Square server
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// NewClient creates a client authenticated with the seller's access token
+func NewClient(accessToken string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:     defaultBaseURL,
+		accessToken: accessToken,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+
+	if config.Values.Square.APIBaseURL != "" {
+		WithBaseURL(config.Values.Square.APIBaseURL)(c)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError represents a non-successful response from the Square API
+type APIError struct {
+	StatusCode int
+	Category   string
+	Code       string
+	Detail     string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("square API error: status %d, %s %s: %s", e.StatusCode, e.Category, e.Code, e.Detail)
+}
+
+// HTTPStatus implements connector.APIError
+func (e *APIError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// RetryDelay implements connector.APIError, Square sends no Retry-After header
+func (e *APIError) RetryDelay() time.Duration {
+	return 0
+}
+
+// do sends a request to path ("/v2/locations") and decodes the JSON response into target.
+// A non-nil body is sent as JSON. Rate limited and server error responses are retried with
+// jittered exponential backoff, Square sends no Retry-After header.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, body, target any) error {
+	requestURL := c.baseURL + path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return errors.Wrap(err, "failed to marshal request body")
+		}
+	}
+
+	return connector.Retry(ctx, "Square", path, func() error {
+		return c.doRequest(ctx, method, requestURL, payload, target)
+	})
+}
+
+// doRequest performs a single request and converts error statuses into *APIError
+func (c *Client) doRequest(ctx context.Context, method, requestURL string, payload []byte, target any) error {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Square-Version", apiVersion)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Detail: string(body)}
+		var errorBody struct {
+			Errors []struct {
+				Category string `json:"category"`
+				Code     string `json:"code"`
+				Detail   string `json:"detail"`
+			} `json:"errors"`
+		}
+		if json.Unmarshal(body, &errorBody) == nil && len(errorBody.Errors) > 0 {
+			first := errorBody.Errors[0]
+			apiErr.Category, apiErr.Code, apiErr.Detail = first.Category, first.Code, first.Detail
+		}
+		return apiErr
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return errors.Wrap(err, "failed to unmarshal response")
+	}
+	return nil
+}
+
+// GetMerchant retrieves the seller the access token belongs to
+func (c *Client) GetMerchant(ctx context.Context) (*Merchant, error) {
+	var resp struct {
+		Merchant Merchant `json:"merchant"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v2/merchants/me", nil, nil, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant")
+	}
+	return &resp.Merchant, nil
+}
+
+// GetLocations retrieves every location of the seller, including inactive ones. The endpoint is not paginated.
+func (c *Client) GetLocations(ctx context.Context) ([]Location, error) {
+	var resp struct {
+		Locations []Location `json:"locations"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v2/locations", nil, nil, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed to get locations")
+	}
+	return resp.Locations, nil
+}
+
+// ListCatalogItems retrieves one page of catalog items with their variations, starting at cursor
+func (c *Client) ListCatalogItems(ctx context.Context, cursor string) ([]CatalogObject, string, error) {
+	params := url.Values{}
+	params.Set("types", "ITEM")
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+
+	var resp struct {
+		Objects []CatalogObject `json:"objects"`
+		Cursor  string          `json:"cursor"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v2/catalog/list", params, nil, &resp); err != nil {
+		return nil, "", errors.Wrap(err, "failed to list catalog items")
+	}
+	return resp.Objects, resp.Cursor, nil
+}
+
+// BatchRetrieveInventoryCounts retrieves one page of the in stock counts of every catalog object at
+// every location, starting at cursor
+func (c *Client) BatchRetrieveInventoryCounts(ctx context.Context, cursor string, limit int) ([]InventoryCount, string, error) {
+	body := map[string]any{
+		"states": []string{InventoryStateInStock},
+		"limit":  limit,
+	}
+	if cursor != "" {
+		body["cursor"] = cursor
+	}
+
+	var resp struct {
+		Counts []InventoryCount `json:"counts"`
+		Cursor string           `json:"cursor"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v2/inventory/counts/batch-retrieve", nil, body, &resp); err != nil {
+		return nil, "", errors.Wrap(err, "failed to retrieve inventory counts")
+	}
+	return resp.Counts, resp.Cursor, nil
+}
+
+// SearchOrders retrieves one page of the orders of up to ten locations created at or after since,
+// oldest first. Draft orders, which were never placed, are left out.
+func (c *Client) SearchOrders(ctx context.Context, locationIDs []string, since time.Time, cursor string, limit int) ([]Order, string, error) {
+	if len(locationIDs) == 0 || len(locationIDs) > maxSearchLocations {
+		return nil, "", errors.Errorf("order search needs 1 to %d locations, got %d", maxSearchLocations, len(locationIDs))
+	}
+
+	body := map[string]any{
+		"location_ids": locationIDs,
+		"limit":        limit,
+		"query": map[string]any{
+			"filter": map[string]any{
+				"date_time_filter": map[string]any{
+					"created_at": map[string]string{"start_at": since.UTC().Format(time.RFC3339)},
+				},
+				"state_filter": map[string]any{
+					"states": []string{OrderStateOpen, OrderStateCompleted, OrderStateCanceled},
+				},
+			},
+			// The sort field must be the field of the date filter
+			"sort": map[string]string{"sort_field": "CREATED_AT", "sort_order": "ASC"},
+		},
+	}
+	if cursor != "" {
+		body["cursor"] = cursor
+	}
+
+	var resp struct {
+		Orders []Order `json:"orders"`
+		Cursor string  `json:"cursor"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v2/orders/search", nil, body, &resp); err != nil {
+		return nil, "", errors.Wrap(err, "failed to search orders")
+	}
+	return resp.Orders, resp.Cursor, nil
+}
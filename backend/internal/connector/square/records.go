@@ -0,0 +1,241 @@
+package square
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+// Refund status of money that has been returned to the buyer
+const refundStatusApproved = "APPROVED"
+
+// Decimal places of the currencies whose minor unit is not a hundredth
+var currencyDecimals = map[string]int{
+	"JPY": 0, "KRW": 0, "CLP": 0, "ISK": 0, "VND": 0, "XAF": 0, "XOF": 0,
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+}
+
+// formatMoney formats an amount in minor units as a decimal string in major units ("24.50")
+func formatMoney(money Money) string {
+	decimals, ok := currencyDecimals[strings.ToUpper(money.Currency)]
+	if !ok {
+		decimals = 2
+	}
+	if decimals == 0 {
+		return strconv.FormatInt(money.Amount, 10)
+	}
+
+	sign, amount := "", money.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	unit := int64(math.Pow10(decimals))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, decimals, amount%unit)
+}
+
+// parseQuantity parses a decimal quantity, rounding fractions of items sold by measure down.
+// A quantity that cannot be parsed is zero.
+func parseQuantity(quantity string) int {
+	value, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return 0
+	}
+	return int(math.Floor(value))
+}
+
+// handleize derives a URL handle from an item name ("Cold Brew, 12oz" becomes "cold-brew-12oz"),
+// Square items have none of their own
+func handleize(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// toLocation converts a location
+func toLocation(location Location) connector.Location {
+	result := connector.Location{
+		ExternalID: location.ID,
+		Name:       location.Name,
+		Country:    location.Country,
+		Active:     location.Status == "ACTIVE",
+	}
+	if location.Address != nil {
+		result.Address = location.Address.AddressLine1
+		result.Province = location.Address.AdministrativeDistrictLevel1
+		if location.Address.Country != "" {
+			result.Country = location.Address.Country
+		}
+	}
+	return result
+}
+
+// toProduct converts a catalog item. Inventory is counted per variation, so each variation is
+// its own inventory item.
+func toProduct(item CatalogObject) connector.Product {
+	data := item.ItemData
+	if data == nil {
+		data = &ItemData{}
+	}
+
+	status := core.ProductStatusActive
+	if data.IsArchived {
+		status = core.ProductStatusArchived
+	}
+
+	result := connector.Product{
+		ExternalID:  item.ID,
+		Title:       data.Name,
+		Handle:      handleize(data.Name),
+		ProductType: strings.ToLower(data.ProductType),
+		Status:      status,
+	}
+
+	for _, variation := range data.Variations {
+		variationData := variation.ItemVariationData
+		if variation.IsDeleted || variationData == nil {
+			continue
+		}
+
+		// Variable priced variations are priced at the register
+		var price string
+		if variationData.PriceMoney != nil {
+			price = formatMoney(*variationData.PriceMoney)
+		}
+
+		// Tracking at any location makes the stock worth forecasting
+		tracked := variationData.TrackInventory
+		for _, override := range variationData.LocationOverrides {
+			tracked = tracked || override.TrackInventory
+		}
+
+		inventoryItem := &connector.InventoryItem{
+			ExternalID: variation.ID,
+			SKU:        variationData.SKU,
+			Tracked:    tracked,
+		}
+		result.Variants = append(result.Variants, connector.Variant{
+			ExternalID:      variation.ID,
+			SKU:             variationData.SKU,
+			Price:           price,
+			Barcode:         variationData.UPC,
+			InventoryItemID: inventoryItem.ExternalID,
+			InventoryItem:   inventoryItem,
+		})
+	}
+
+	return result
+}
+
+// toInventoryLevel converts the in stock count of a variation at a location
+func toInventoryLevel(count InventoryCount) connector.InventoryLevel {
+	return connector.InventoryLevel{
+		InventoryItemID: count.CatalogObjectID,
+		LocationID:      count.LocationID,
+		Available:       parseQuantity(count.Quantity),
+	}
+}
+
+// toOrder converts an order. Square tracks the order state, its payments (tenders) and refunds
+// separately, which are combined into the financial status of the core schema.
+func toOrder(order Order) connector.Order {
+	result := connector.Order{
+		ExternalID:        order.ID,
+		CreatedAt:         order.CreatedAt,
+		FinancialStatus:   financialStatus(order),
+		FulfillmentStatus: fulfillmentStatus(order),
+		TotalPrice:        formatMoney(order.TotalMoney),
+	}
+
+	if order.State == OrderStateCanceled {
+		cancelledAt := order.UpdatedAt
+		if order.ClosedAt != nil {
+			cancelledAt = *order.ClosedAt
+		}
+		result.CancelledAt = &cancelledAt
+	}
+
+	for _, lineItem := range order.LineItems {
+		var price string
+		if lineItem.BasePriceMoney != nil {
+			price = formatMoney(*lineItem.BasePriceMoney)
+		}
+		result.LineItems = append(result.LineItems, connector.OrderLineItem{
+			ExternalID: lineItem.UID,
+			VariantID:  lineItem.CatalogObjectID,
+			Quantity:   parseQuantity(lineItem.Quantity),
+			Price:      price,
+		})
+	}
+
+	return result
+}
+
+// financialStatus derives the financial status of an order from its state, tenders and refunds
+func financialStatus(order Order) core.FinancialStatus {
+	if order.State == OrderStateCanceled {
+		return core.FinancialStatusVoided
+	}
+
+	var refunded int64
+	for _, refund := range order.Refunds {
+		if refund.Status == refundStatusApproved {
+			refunded += refund.AmountMoney.Amount
+		}
+	}
+	switch {
+	case refunded > 0 && refunded >= order.TotalMoney.Amount:
+		return core.FinancialStatusRefunded
+	case refunded > 0:
+		return core.FinancialStatusPartiallyRefunded
+	case order.State == OrderStateCompleted:
+		return core.FinancialStatusPaid
+	case len(order.Tenders) == 0:
+		return core.FinancialStatusPending
+	case order.NetAmountDueMoney != nil && order.NetAmountDueMoney.Amount > 0:
+		return core.FinancialStatusPartiallyPaid
+	default:
+		return core.FinancialStatusPaid
+	}
+}
+
+// fulfillmentStatus derives the fulfillment status of an order. A completed order without
+// fulfillments was handed over at the counter.
+func fulfillmentStatus(order Order) core.FulfillmentStatus {
+	var total, completed int
+	for _, fulfillment := range order.Fulfillments {
+		switch fulfillment.State {
+		case FulfillmentStateCanceled, FulfillmentStateFailed:
+			continue
+		case FulfillmentStateCompleted:
+			completed++
+		}
+		total++
+	}
+
+	switch {
+	case total == 0 && order.State == OrderStateCompleted:
+		return core.FulfillmentStatusFulfilled
+	case total > 0 && completed == total:
+		return core.FulfillmentStatusFulfilled
+	case completed > 0:
+		return core.FulfillmentStatusPartial
+	default:
+		return core.FulfillmentStatusNull
+	}
+}
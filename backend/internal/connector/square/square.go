@@ -0,0 +1,213 @@
+package square
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Page sizes requested from the Square API. Catalog listings are paged by Square itself.
+	countsPageSize = maxCountsLimit
+	ordersPageSize = 500
+)
+
+// Connector reads a Square seller through the Square API
+type Connector struct {
+	client *Client
+
+	// Location IDs orders are searched by, loaded once per connector
+	locationsMu sync.Mutex
+	locationIDs []string
+}
+
+var (
+	_ connector.PlatformConnector = (*Connector)(nil)
+	_ connector.CurrencyReader    = (*Connector)(nil)
+)
+
+// New creates a connector that reads through the given client
+func New(client *Client) *Connector {
+	return &Connector{client: client}
+}
+
+// Factory creates the connectors of Square integrations from their stored access tokens
+func Factory(database db.Database) connector.Factory {
+	return func(ctx context.Context, integration core.PlatformIntegration) (connector.PlatformConnector, error) {
+		credentials, err := database.GetCore().GetSquareCredentialsByIntegrationID(ctx, integration.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get square credentials")
+		}
+
+		return New(NewClient(credentials.AccessToken.String())), nil
+	}
+}
+
+// Platform implements connector.PlatformConnector
+func (c *Connector) Platform() core.PlatformType {
+	return core.PlatformTypeSquare
+}
+
+// Verify checks that the access token can read the seller's catalog, inventory and orders
+func (c *Connector) Verify(ctx context.Context) error {
+	if _, _, err := c.client.ListCatalogItems(ctx, ""); err != nil {
+		return err
+	}
+	if _, _, err := c.client.BatchRetrieveInventoryCounts(ctx, "", 1); err != nil {
+		return err
+	}
+
+	locationIDs, err := c.getLocationIDs(ctx)
+	if err != nil || len(locationIDs) == 0 {
+		return err
+	}
+	_, _, err = c.client.SearchOrders(ctx, locationIDs[:min(len(locationIDs), maxSearchLocations)], time.Now(), "", 1)
+	return err
+}
+
+// ListLocations implements connector.PlatformConnector. Square returns every location at once.
+func (c *Connector) ListLocations(ctx context.Context, cursor string) (*connector.Page[connector.Location], error) {
+	locations, err := c.client.GetLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Location]{}
+	for _, location := range locations {
+		page.Items = append(page.Items, toLocation(location))
+	}
+	return page, nil
+}
+
+// ListProducts implements connector.PlatformConnector. The cursor is Square's catalog cursor.
+func (c *Connector) ListProducts(ctx context.Context, cursor string) (*connector.Page[connector.Product], error) {
+	objects, next, err := c.client.ListCatalogItems(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Product]{NextCursor: next}
+	for _, object := range objects {
+		if object.Type != CatalogObjectTypeItem || object.IsDeleted {
+			continue
+		}
+		page.Items = append(page.Items, toProduct(object))
+	}
+	return page, nil
+}
+
+// ListInventoryLevels implements connector.PlatformConnector. The cursor is Square's inventory cursor.
+func (c *Connector) ListInventoryLevels(ctx context.Context, cursor string) (*connector.Page[connector.InventoryLevel], error) {
+	counts, next, err := c.client.BatchRetrieveInventoryCounts(ctx, cursor, countsPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.InventoryLevel]{NextCursor: next}
+	for _, count := range counts {
+		if count.CatalogObjectType != CatalogObjectTypeItemVariation || count.State != InventoryStateInStock {
+			continue
+		}
+		page.Items = append(page.Items, toInventoryLevel(count))
+	}
+	return page, nil
+}
+
+// ListOrders implements connector.PlatformConnector.
+//
+// An order search covers at most ten locations, so the seller's locations are searched in groups
+// of ten. The cursor is "<group>:<Square cursor>", moving on to the next group once Square has
+// no more pages for the current one.
+func (c *Connector) ListOrders(ctx context.Context, since time.Time, cursor string) (*connector.Page[connector.Order], error) {
+	group, searchCursor, err := parseOrdersCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	locationIDs, err := c.getLocationIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups := slices.Collect(slices.Chunk(locationIDs, maxSearchLocations))
+	if group >= len(groups) {
+		return &connector.Page[connector.Order]{}, nil
+	}
+
+	orders, next, err := c.client.SearchOrders(ctx, groups[group], since, searchCursor, ordersPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Order]{}
+	switch {
+	case next != "":
+		page.NextCursor = formatOrdersCursor(group, next)
+	case group+1 < len(groups):
+		page.NextCursor = formatOrdersCursor(group+1, "")
+	}
+	for _, order := range orders {
+		page.Items = append(page.Items, toOrder(order))
+	}
+	return page, nil
+}
+
+// Currency implements connector.CurrencyReader with the currency of the seller's account
+func (c *Connector) Currency(ctx context.Context) (string, error) {
+	merchant, err := c.client.GetMerchant(ctx)
+	if err != nil {
+		return "", err
+	}
+	return merchant.Currency, nil
+}
+
+// getLocationIDs returns the IDs of every location of the seller in a stable order, so that
+// location groups in persisted order cursors keep meaning the same locations
+func (c *Connector) getLocationIDs(ctx context.Context) ([]string, error) {
+	c.locationsMu.Lock()
+	defer c.locationsMu.Unlock()
+
+	if c.locationIDs != nil {
+		return c.locationIDs, nil
+	}
+
+	locations, err := c.client.GetLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	locationIDs := make([]string, 0, len(locations))
+	for _, location := range locations {
+		locationIDs = append(locationIDs, location.ID)
+	}
+	slices.Sort(locationIDs)
+
+	c.locationIDs = locationIDs
+	return locationIDs, nil
+}
+
+// parseOrdersCursor decodes an orders cursor, an empty cursor being the first page of the first group
+func parseOrdersCursor(cursor string) (int, string, error) {
+	if cursor == "" {
+		return 0, "", nil
+	}
+
+	groupValue, searchCursor, ok := strings.Cut(cursor, ":")
+	group, err := strconv.Atoi(groupValue)
+	if !ok || err != nil || group < 0 {
+		return 0, "", errors.Errorf("invalid square orders cursor %q", cursor)
+	}
+	return group, searchCursor, nil
+}
+
+// formatOrdersCursor encodes an orders cursor
+func formatOrdersCursor(group int, searchCursor string) string {
+	return strconv.Itoa(group) + ":" + searchCursor
+}
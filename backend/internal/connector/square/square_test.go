@@ -0,0 +1,137 @@
+package square_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square/squaretest"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+func newConnector(t *testing.T) (*square.Connector, *squaretest.Server) {
+	t.Helper()
+
+	server := squaretest.NewServer(squaretest.DemoFixtures())
+	t.Cleanup(server.Close)
+	return square.New(server.Client(squaretest.AccessToken)), server
+}
+
+func TestListProductsMapsVariations(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	products := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Product], error) {
+		return conn.ListProducts(ctx, cursor)
+	})
+	if len(products) != 3 {
+		t.Fatalf("listed %d products, want 3", len(products))
+	}
+
+	beans, mug, holiday := products[0], products[1], products[2]
+	if beans.Handle != "house-beans" || beans.ProductType != "regular" || len(beans.Variants) != 2 {
+		t.Errorf("product = %+v, want house-beans with two variants", beans)
+	}
+	// Tracking at one location is enough
+	if kilo := beans.Variants[1]; kilo.Price != "48.00" || kilo.InventoryItemID != "V_BEANS_1KG" || !kilo.InventoryItem.Tracked {
+		t.Errorf("variant = %+v, want a tracked item priced in dollars", kilo)
+	}
+	if mug.Variants[0].SKU != "MUG-1" || mug.Variants[0].Barcode != "012345678905" || mug.Status != core.ProductStatusActive {
+		t.Errorf("product = %+v, want an active mug with its UPC", mug)
+	}
+	if holiday.Status != core.ProductStatusArchived || holiday.Variants[0].Price != "" || holiday.Variants[0].InventoryItem.Tracked {
+		t.Errorf("archived variable priced item = %+v, want archived without a price or tracking", holiday)
+	}
+}
+
+func TestListInventoryLevelsAtEveryLocation(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	locations := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Location], error) {
+		return conn.ListLocations(ctx, cursor)
+	})
+	if len(locations) != 2 {
+		t.Fatalf("listed %d locations, want 2", len(locations))
+	}
+
+	levels := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.InventoryLevel], error) {
+		return conn.ListInventoryLevels(ctx, cursor)
+	})
+	if len(levels) != 4 {
+		t.Fatalf("listed %d in stock levels, want 4: %+v", len(levels), levels)
+	}
+
+	// Fractions of items sold by measure are rounded down
+	want := connector.InventoryLevel{InventoryItemID: "V_BEANS_1KG", LocationID: "L02", Available: 4}
+	var found bool
+	for _, level := range levels {
+		found = found || level == want
+	}
+	if !found {
+		t.Errorf("levels %+v do not include %+v", levels, want)
+	}
+}
+
+func TestListOrdersMapsStates(t *testing.T) {
+	conn, _ := newConnector(t)
+	ctx := context.Background()
+
+	orders := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Order], error) {
+		return conn.ListOrders(ctx, time.Now().AddDate(0, 0, -20), cursor)
+	})
+	if len(orders) != 3 {
+		t.Fatalf("listed %d orders, want 3 placed orders in the window: %+v", len(orders), orders)
+	}
+
+	counter, canceled, pickup := orders[0], orders[1], orders[2]
+	if counter.FinancialStatus != core.FinancialStatusPartiallyRefunded || counter.FulfillmentStatus != core.FulfillmentStatusFulfilled || counter.TotalPrice != "32.00" {
+		t.Errorf("counter sale = %+v, want a partially refunded, fulfilled $32.00 order", counter)
+	}
+	if len(counter.LineItems) != 2 || counter.LineItems[1].VariantID != "V_MUG" || counter.LineItems[1].Price != "18.00" {
+		t.Errorf("line items = %+v, want the mug at $18.00", counter.LineItems)
+	}
+	if canceled.FinancialStatus != core.FinancialStatusVoided || canceled.CancelledAt == nil {
+		t.Errorf("canceled order = %+v, want voided with a cancel time", canceled)
+	}
+	if pickup.FinancialStatus != core.FinancialStatusPaid || pickup.FulfillmentStatus != core.FulfillmentStatusNull {
+		t.Errorf("pickup order = %+v, want paid and not yet fulfilled", pickup)
+	}
+}
+
+func TestListOrdersSearchesLocationsInGroups(t *testing.T) {
+	conn, server := newConnector(t)
+	ctx := context.Background()
+
+	// Ten more locations push the last one into a second search
+	server.UpdateFixtures(func(f *squaretest.Fixtures) {
+		for i := 3; i <= 12; i++ {
+			f.Locations = append(f.Locations, square.Location{ID: fmt.Sprintf("L%02d", i), Name: fmt.Sprintf("Store %d", i), Status: "ACTIVE"})
+		}
+		f.Orders = append(f.Orders, square.Order{
+			ID: "ORDER_FAR", LocationID: "L12", State: square.OrderStateCompleted,
+			CreatedAt: time.Now().AddDate(0, 0, -5), TotalMoney: square.Money{Amount: 500, Currency: "USD"},
+		})
+	})
+
+	orders := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Order], error) {
+		return conn.ListOrders(ctx, time.Now().AddDate(0, 0, -20), cursor)
+	})
+	if len(orders) != 4 || orders[3].ExternalID != "ORDER_FAR" {
+		t.Errorf("orders = %+v, want the three demo orders and the one at the twelfth location", orders)
+	}
+	if got := server.Requests("/v2/locations"); got != 1 {
+		t.Errorf("locations requested %d times, want 1", got)
+	}
+}
+
+func TestListOrdersRejectsInvalidCursor(t *testing.T) {
+	conn, _ := newConnector(t)
+	if _, err := conn.ListOrders(context.Background(), time.Now(), "not-a-cursor"); err == nil {
+		t.Error("expected an error for an invalid cursor")
+	}
+}
@@ -0,0 +1,316 @@
+// Package squaretest provides a fake Square API for tests. It serves the merchant, locations,
+// catalog items, inventory counts and an order search from fixtures with opaque cursor
+// pagination, checks the access token, and can inject errors.
+package squaretest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square"
+)
+
+const (
+	// Access token accepted by a server created with NewServer, and the merchant it belongs to
+	AccessToken = "sq_token"
+	MerchantID  = "MLTEST123"
+
+	// The fake serves small pages so that tests page through the data
+	pageSize = 2
+
+	// Largest number of locations an order search can cover
+	maxSearchLocations = 10
+)
+
+// Fixtures is the data a fake seller serves, in the shape of the Square API
+type Fixtures struct {
+	Merchant  square.Merchant
+	Locations []square.Location
+	Items     []square.CatalogObject
+	Counts    []square.InventoryCount
+	Orders    []square.Order
+}
+
+// DemoFixtures returns a small coffee shop: a store and a warehouse, an item with two
+// variations, a single variation item, an archived variable priced item, their stock and
+// a few orders in every state
+func DemoFixtures() *Fixtures {
+	now := time.Now().UTC().Truncate(time.Second)
+	usd := func(amount int64) *square.Money { return &square.Money{Amount: amount, Currency: "USD"} }
+	variation := func(id, itemID, sku string, price *square.Money, tracked bool) square.CatalogObject {
+		pricingType := "FIXED_PRICING"
+		if price == nil {
+			pricingType = "VARIABLE_PRICING"
+		}
+		return square.CatalogObject{
+			Type: square.CatalogObjectTypeItemVariation,
+			ID:   id,
+			ItemVariationData: &square.ItemVariationData{
+				ItemID: itemID, SKU: sku, PricingType: pricingType, PriceMoney: price, TrackInventory: tracked,
+			},
+		}
+	}
+	count := func(variationID, locationID, quantity string) square.InventoryCount {
+		return square.InventoryCount{
+			CatalogObjectID: variationID, CatalogObjectType: square.CatalogObjectTypeItemVariation,
+			State: square.InventoryStateInStock, LocationID: locationID, Quantity: quantity, CalculatedAt: now,
+		}
+	}
+	closedAt := now.AddDate(0, 0, -2)
+
+	beansKilo := variation("V_BEANS_1KG", "ITEM_BEANS", "BEANS-1KG", usd(4800), false)
+	beansKilo.ItemVariationData.LocationOverrides = []square.LocationOverride{{LocationID: "L02", TrackInventory: true}}
+	mug := variation("V_MUG", "ITEM_MUG", "MUG-1", usd(1800), true)
+	mug.ItemVariationData.UPC = "012345678905"
+
+	return &Fixtures{
+		Merchant: square.Merchant{ID: MerchantID, BusinessName: "Corner Coffee", Country: "US", Currency: "USD", Status: "ACTIVE"},
+		Locations: []square.Location{
+			{ID: "L01", Name: "Main Street", Status: "ACTIVE", Country: "US"},
+			{ID: "L02", Name: "Warehouse", Status: "ACTIVE", Country: "US"},
+		},
+		Items: []square.CatalogObject{
+			{
+				Type: square.CatalogObjectTypeItem, ID: "ITEM_BEANS",
+				ItemData: &square.ItemData{Name: "House Beans", ProductType: "REGULAR", Variations: []square.CatalogObject{
+					variation("V_BEANS_250", "ITEM_BEANS", "BEANS-250", usd(1400), true),
+					beansKilo,
+				}},
+			},
+			{
+				Type: square.CatalogObjectTypeItem, ID: "ITEM_MUG",
+				ItemData: &square.ItemData{Name: "Ceramic Mug", ProductType: "REGULAR", Variations: []square.CatalogObject{
+					mug,
+				}},
+			},
+			{
+				Type: square.CatalogObjectTypeItem, ID: "ITEM_HOLIDAY",
+				ItemData: &square.ItemData{Name: "Holiday Blend", ProductType: "REGULAR", IsArchived: true, Variations: []square.CatalogObject{
+					variation("V_HOLIDAY", "ITEM_HOLIDAY", "HOLIDAY", nil, false),
+				}},
+			},
+		},
+		Counts: []square.InventoryCount{
+			count("V_BEANS_250", "L01", "12"),
+			count("V_BEANS_250", "L02", "30"),
+			count("V_BEANS_1KG", "L02", "4.5"),
+			count("V_MUG", "L01", "6"),
+			{CatalogObjectID: "V_MUG", CatalogObjectType: square.CatalogObjectTypeItemVariation, State: "WASTE", LocationID: "L01", Quantity: "2", CalculatedAt: now},
+		},
+		Orders: []square.Order{
+			{
+				ID: "ORDER_COUNTER", LocationID: "L01", State: square.OrderStateCompleted,
+				CreatedAt: now.AddDate(0, 0, -10), UpdatedAt: now.AddDate(0, 0, -10), TotalMoney: *usd(3200),
+				LineItems: []square.OrderLineItem{
+					{UID: "li_1", CatalogObjectID: "V_BEANS_250", Name: "House Beans", Quantity: "1", BasePriceMoney: usd(1400)},
+					{UID: "li_2", CatalogObjectID: "V_MUG", Name: "Ceramic Mug", Quantity: "1", BasePriceMoney: usd(1800)},
+				},
+				Tenders: []square.OrderTender{{ID: "t_1", Type: "CARD", AmountMoney: *usd(3200)}},
+				Refunds: []square.OrderRefund{{ID: "r_1", Status: "APPROVED", AmountMoney: *usd(1400)}},
+			},
+			{
+				ID: "ORDER_CANCELED", LocationID: "L02", State: square.OrderStateCanceled,
+				CreatedAt: now.AddDate(0, 0, -3), UpdatedAt: closedAt, ClosedAt: &closedAt, TotalMoney: *usd(4800),
+				LineItems: []square.OrderLineItem{{UID: "li_3", CatalogObjectID: "V_BEANS_1KG", Name: "House Beans", Quantity: "1", BasePriceMoney: usd(4800)}},
+			},
+			{
+				ID: "ORDER_OLD", LocationID: "L01", State: square.OrderStateCompleted,
+				CreatedAt: now.AddDate(0, 0, -40), UpdatedAt: now.AddDate(0, 0, -40), TotalMoney: *usd(1800),
+				LineItems: []square.OrderLineItem{{UID: "li_4", CatalogObjectID: "V_MUG", Name: "Ceramic Mug", Quantity: "1", BasePriceMoney: usd(1800)}},
+			},
+			{
+				ID: "ORDER_DRAFT", LocationID: "L01", State: square.OrderStateDraft,
+				CreatedAt: now.AddDate(0, 0, -1), UpdatedAt: now.AddDate(0, 0, -1), TotalMoney: *usd(1400),
+			},
+			{
+				ID: "ORDER_PICKUP", LocationID: "L02", State: square.OrderStateOpen,
+				CreatedAt: now.AddDate(0, 0, -1), UpdatedAt: now.AddDate(0, 0, -1), TotalMoney: *usd(2800), NetAmountDueMoney: usd(0),
+				LineItems:    []square.OrderLineItem{{UID: "li_5", CatalogObjectID: "V_BEANS_250", Name: "House Beans", Quantity: "2", BasePriceMoney: usd(1400)}},
+				Tenders:      []square.OrderTender{{ID: "t_2", Type: "CARD", AmountMoney: *usd(2800)}},
+				Fulfillments: []square.OrderFulfillment{{UID: "f_1", Type: "PICKUP", State: "PROPOSED"}},
+			},
+		},
+	}
+}
+
+// Server is a running fake Square API
+type Server struct {
+	*httptest.Server
+	*connectortest.Fake[Fixtures]
+}
+
+// NewServer starts a fake Square API serving the given fixtures
+func NewServer(fixtures *Fixtures) *Server {
+	s := &Server{Fake: connectortest.NewFake(fixtures)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Client returns a client that talks to this server with the given access token
+func (s *Server) Client(accessToken string) *square.Client {
+	return square.NewClient(accessToken, square.WithBaseURL(s.URL))
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	s.Record(r.URL.Path)
+
+	if r.Header.Get("Authorization") != "Bearer "+AccessToken {
+		writeError(w, http.StatusUnauthorized, "AUTHENTICATION_ERROR", "UNAUTHORIZED", "This request could not be authorized.")
+		return
+	}
+
+	if status, failed := s.NextFailure(r.URL.Path); failed {
+		writeError(w, status, "API_ERROR", "INJECTED_ERROR", http.StatusText(status))
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/merchants/me":
+		writeJSON(w, http.StatusOK, map[string]any{"merchant": s.Fixtures.Merchant})
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/locations":
+		writeJSON(w, http.StatusOK, map[string]any{"locations": s.Fixtures.Locations})
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/catalog/list":
+		s.serveCatalog(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/inventory/counts/batch-retrieve":
+		s.serveCounts(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/orders/search":
+		s.serveOrders(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "INVALID_REQUEST_ERROR", "NOT_FOUND", "Resource not found.")
+	}
+}
+
+// serveCatalog serves the catalog objects of the requested types
+func (s *Server) serveCatalog(w http.ResponseWriter, r *http.Request) {
+	types := strings.Split(r.URL.Query().Get("types"), ",")
+
+	var objects []square.CatalogObject
+	for _, object := range s.Fixtures.Items {
+		if slices.Contains(types, object.Type) {
+			objects = append(objects, object)
+		}
+	}
+	servePage(w, "objects", objects, r.URL.Query().Get("cursor"), pageSize)
+}
+
+// serveCounts serves the counts in the requested states
+func (s *Server) serveCounts(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		States []string `json:"states"`
+		Cursor string   `json:"cursor"`
+		Limit  int      `json:"limit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", "INVALID_BODY", err.Error())
+		return
+	}
+
+	var counts []square.InventoryCount
+	for _, count := range s.Fixtures.Counts {
+		if len(req.States) == 0 || slices.Contains(req.States, count.State) {
+			counts = append(counts, count)
+		}
+	}
+	servePage(w, "counts", counts, req.Cursor, limit(req.Limit))
+}
+
+// serveOrders serves the orders of the requested locations and states created at or after the
+// start of the created_at filter, oldest first
+func (s *Server) serveOrders(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		LocationIDs []string `json:"location_ids"`
+		Cursor      string   `json:"cursor"`
+		Limit       int      `json:"limit"`
+		Query       struct {
+			Filter struct {
+				DateTimeFilter struct {
+					CreatedAt struct {
+						StartAt time.Time `json:"start_at"`
+					} `json:"created_at"`
+				} `json:"date_time_filter"`
+				StateFilter struct {
+					States []string `json:"states"`
+				} `json:"state_filter"`
+			} `json:"filter"`
+		} `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", "INVALID_BODY", err.Error())
+		return
+	}
+	if len(req.LocationIDs) == 0 || len(req.LocationIDs) > maxSearchLocations {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", "INVALID_ARRAY_LENGTH", "location_ids must have between 1 and 10 elements.")
+		return
+	}
+
+	filter := req.Query.Filter
+	var orders []square.Order
+	for _, order := range s.Fixtures.Orders {
+		if !slices.Contains(req.LocationIDs, order.LocationID) || order.CreatedAt.Before(filter.DateTimeFilter.CreatedAt.StartAt) {
+			continue
+		}
+		if states := filter.StateFilter.States; len(states) > 0 && !slices.Contains(states, order.State) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	slices.SortStableFunc(orders, func(a, b square.Order) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	servePage(w, "orders", orders, req.Cursor, limit(req.Limit))
+}
+
+// limit caps a requested page size at the fake's page size
+func limit(requested int) int {
+	if requested <= 0 {
+		return pageSize
+	}
+	return min(requested, pageSize)
+}
+
+// servePage writes one page of items under key with the cursor of the next page, if any.
+// Cursors are the base64 encoded offset of the page.
+func servePage[T any](w http.ResponseWriter, key string, items []T, cursor string, size int) {
+	offset := 0
+	if cursor != "" {
+		decoded, err := base64.StdEncoding.DecodeString(cursor)
+		if err == nil {
+			offset, err = strconv.Atoi(string(decoded))
+		}
+		if err != nil || offset < 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", "INVALID_CURSOR", "The pagination cursor is not valid.")
+			return
+		}
+	}
+
+	start := min(offset, len(items))
+	end := min(start+size, len(items))
+	resp := map[string]any{key: append([]T{}, items[start:end]...)}
+	if end < len(items) {
+		resp["cursor"] = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the Square API format
+func writeError(w http.ResponseWriter, status int, category, code, detail string) {
+	writeJSON(w, status, map[string]any{
+		"errors": []map[string]string{{"category": category, "code": code, "detail": detail}},
+	})
+}
@@ -0,0 +1,155 @@
+package square
+
+import "time"
+
+// Catalog object types
+const (
+	CatalogObjectTypeItem          = "ITEM"
+	CatalogObjectTypeItemVariation = "ITEM_VARIATION"
+)
+
+// Inventory states, only IN_STOCK counts are sellable
+const (
+	InventoryStateInStock = "IN_STOCK"
+)
+
+// Order states
+const (
+	OrderStateOpen      = "OPEN"
+	OrderStateCompleted = "COMPLETED"
+	OrderStateCanceled  = "CANCELED"
+	OrderStateDraft     = "DRAFT"
+)
+
+// Fulfillment states
+const (
+	FulfillmentStateCompleted = "COMPLETED"
+	FulfillmentStateCanceled  = "CANCELED"
+	FulfillmentStateFailed    = "FAILED"
+)
+
+// Money is an amount in the smallest unit of its currency, e.g. cents
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// Merchant represents the seller an access token belongs to
+type Merchant struct {
+	ID           string `json:"id"`
+	BusinessName string `json:"business_name"`
+	Country      string `json:"country"`
+	Currency     string `json:"currency"`
+	Status       string `json:"status"`
+}
+
+// Location represents a store, warehouse or other place the seller does business from
+type Location struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Status  string `json:"status"` // ACTIVE or INACTIVE
+	Country string `json:"country"`
+	Address *struct {
+		AddressLine1                 string `json:"address_line_1"`
+		Locality                     string `json:"locality"`
+		AdministrativeDistrictLevel1 string `json:"administrative_district_level_1"`
+		PostalCode                   string `json:"postal_code"`
+		Country                      string `json:"country"`
+	} `json:"address,omitempty"`
+}
+
+// CatalogObject represents an object of the Catalog API. Items carry ItemData, and the
+// variations nested in an item carry ItemVariationData.
+type CatalogObject struct {
+	Type              string             `json:"type"`
+	ID                string             `json:"id"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+	IsDeleted         bool               `json:"is_deleted"`
+	ItemData          *ItemData          `json:"item_data,omitempty"`
+	ItemVariationData *ItemVariationData `json:"item_variation_data,omitempty"`
+}
+
+// ItemData holds the attributes of a catalog item
+type ItemData struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	ProductType string          `json:"product_type"` // REGULAR, APPOINTMENTS_SERVICE, ...
+	IsArchived  bool            `json:"is_archived"`
+	Variations  []CatalogObject `json:"variations"`
+}
+
+// ItemVariationData holds the attributes of a variation of a catalog item
+type ItemVariationData struct {
+	ItemID      string `json:"item_id"`
+	Name        string `json:"name"`
+	SKU         string `json:"sku,omitempty"`
+	UPC         string `json:"upc,omitempty"`
+	PricingType string `json:"pricing_type"` // FIXED_PRICING or VARIABLE_PRICING
+	PriceMoney  *Money `json:"price_money,omitempty"`
+
+	// TrackInventory is the seller-wide setting, which locations can override
+	TrackInventory    bool               `json:"track_inventory"`
+	LocationOverrides []LocationOverride `json:"location_overrides,omitempty"`
+}
+
+// LocationOverride holds the settings of a variation at one location
+type LocationOverride struct {
+	LocationID     string `json:"location_id"`
+	TrackInventory bool   `json:"track_inventory"`
+}
+
+// InventoryCount represents the quantity of a catalog object in one state at one location
+type InventoryCount struct {
+	CatalogObjectID   string    `json:"catalog_object_id"`
+	CatalogObjectType string    `json:"catalog_object_type"`
+	State             string    `json:"state"`
+	LocationID        string    `json:"location_id"`
+	Quantity          string    `json:"quantity"` // a decimal, items sold by measure have fractions
+	CalculatedAt      time.Time `json:"calculated_at"`
+}
+
+// Order represents an order from the Orders API, placed in person or online
+type Order struct {
+	ID                string             `json:"id"`
+	LocationID        string             `json:"location_id"`
+	State             string             `json:"state"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+	ClosedAt          *time.Time         `json:"closed_at,omitempty"`
+	TotalMoney        Money              `json:"total_money"`
+	NetAmountDueMoney *Money             `json:"net_amount_due_money,omitempty"`
+	LineItems         []OrderLineItem    `json:"line_items,omitempty"`
+	Tenders           []OrderTender      `json:"tenders,omitempty"`
+	Refunds           []OrderRefund      `json:"refunds,omitempty"`
+	Fulfillments      []OrderFulfillment `json:"fulfillments,omitempty"`
+}
+
+// OrderLineItem represents one line of an order
+type OrderLineItem struct {
+	UID             string `json:"uid"`
+	CatalogObjectID string `json:"catalog_object_id,omitempty"` // the variation, empty for custom amounts
+	Name            string `json:"name"`
+	Quantity        string `json:"quantity"`
+	BasePriceMoney  *Money `json:"base_price_money,omitempty"`
+}
+
+// OrderTender represents a payment towards an order
+type OrderTender struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	AmountMoney Money  `json:"amount_money"`
+}
+
+// OrderRefund represents money returned for an order
+type OrderRefund struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"` // PENDING, APPROVED, REJECTED or FAILED
+	AmountMoney Money  `json:"amount_money"`
+}
+
+// OrderFulfillment represents the pickup, shipment or delivery of an order
+type OrderFulfillment struct {
+	UID   string `json:"uid"`
+	Type  string `json:"type"`
+	State string `json:"state"`
+}
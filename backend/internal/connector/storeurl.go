@@ -0,0 +1,45 @@
+package connector
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not routable on the internet either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckStoreURL checks that a self-hosted store's URL is safe to send credentials to: it must use
+// https and its host must only resolve to public addresses. Private, loopback and link-local hosts
+// are rejected, since requests to them would reach our own network rather than a store.
+//
+// The URL is returned parsed when it passes.
+func CheckStoreURL(ctx context.Context, storeURL string) (*url.URL, error) {
+	parsed, err := url.Parse(storeURL)
+	if err != nil || parsed.Hostname() == "" || parsed.User != nil {
+		return nil, errors.Errorf("%q is not a store url", storeURL)
+	}
+	if parsed.Scheme != "https" {
+		return nil, errors.Errorf("store url %q must use https", storeURL)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", parsed.Hostname())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve store host %s", parsed.Hostname())
+	}
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return nil, errors.Errorf("store host %s resolves to the non-public address %s", parsed.Hostname(), addr)
+		}
+	}
+	return parsed, nil
+}
+
+// publicAddr reports whether addr is a unicast address reachable on the internet
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
@@ -0,0 +1,225 @@
+// Package woocommerce reads WooCommerce stores through the REST API (wc/v3) and adapts them to connector.PlatformConnector
+package woocommerce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Path of the REST API below the WordPress site URL
+	apiPath = "/wp-json/wc/v3"
+
+	// Largest page size WooCommerce accepts
+	maxPerPage = 100
+)
+
+// Client is a WooCommerce REST API client authenticated with a consumer key and secret
+type Client struct {
+	storeURL       string
+	consumerKey    string
+	consumerSecret string
+	httpClient     *http.Client
+}
+
+// NewClient creates a client for the WordPress site at storeURL ("https://shop.example.com")
+func NewClient(storeURL, consumerKey, consumerSecret string) *Client {
+	return &Client{
+		storeURL:       NormalizeStoreURL(storeURL),
+		consumerKey:    consumerKey,
+		consumerSecret: consumerSecret,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// NormalizeStoreURL trims whitespace and trailing slashes and defaults the scheme to https
+func NormalizeStoreURL(storeURL string) string {
+	storeURL = strings.TrimRight(strings.TrimSpace(storeURL), "/")
+	if storeURL != "" && !strings.Contains(storeURL, "://") {
+		storeURL = "https://" + storeURL
+	}
+	return storeURL
+}
+
+// APIError represents a non-successful response from the WooCommerce API
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("woocommerce API error: status %d, code %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// HTTPStatus implements connector.APIError
+func (e *APIError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// RetryDelay implements connector.APIError
+func (e *APIError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// listInfo carries the totals WooCommerce reports in the X-WP-Total and X-WP-TotalPages headers
+type listInfo struct {
+	total      int
+	totalPages int
+}
+
+// get requests path below the API root and decodes the JSON response into target.
+// Rate limited and server error responses are retried with jittered exponential backoff.
+func (c *Client) get(ctx context.Context, path string, params url.Values, target any) (*listInfo, error) {
+	requestURL := c.storeURL + apiPath + path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	var info *listInfo
+	err := connector.Retry(ctx, "WooCommerce", path, func() error {
+		var err error
+		info, err = c.doRequest(ctx, requestURL, target)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// doRequest performs a single request and converts error statuses into *APIError
+func (c *Client) doRequest(ctx context.Context, requestURL string, target any) (*listInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	req.SetBasicAuth(c.consumerKey, c.consumerSecret)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+		var errorBody struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &errorBody) == nil && errorBody.Code != "" {
+			apiErr.Code, apiErr.Message = errorBody.Code, errorBody.Message
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, apiErr
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	info := &listInfo{}
+	info.total, _ = strconv.Atoi(resp.Header.Get("X-WP-Total"))
+	info.totalPages, _ = strconv.Atoi(resp.Header.Get("X-WP-TotalPages"))
+	return info, nil
+}
+
+// pageParams returns the parameters of one page of a listing in ascending ID order, which keeps
+// page boundaries stable while records are added during a sync
+func pageParams(page, perPage int) url.Values {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("per_page", strconv.Itoa(perPage))
+	params.Set("orderby", "id")
+	params.Set("order", "asc")
+	return params
+}
+
+// GetProducts retrieves one page of products
+func (c *Client) GetProducts(ctx context.Context, page, perPage int) ([]Product, *listInfo, error) {
+	var products []Product
+	info, err := c.get(ctx, "/products", pageParams(page, perPage), &products)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get products")
+	}
+	return products, info, nil
+}
+
+// GetAllVariations retrieves every variation of a variable product
+func (c *Client) GetAllVariations(ctx context.Context, productID int64) ([]Variation, error) {
+	var variations []Variation
+	for page := 1; ; page++ {
+		var batch []Variation
+		info, err := c.get(ctx, fmt.Sprintf("/products/%d/variations", productID), pageParams(page, maxPerPage), &batch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get variations of product %d", productID)
+		}
+		variations = append(variations, batch...)
+		if page >= info.totalPages {
+			return variations, nil
+		}
+	}
+}
+
+// GetOrders retrieves one page of the orders created after the given time
+func (c *Client) GetOrders(ctx context.Context, after time.Time, page, perPage int) ([]Order, *listInfo, error) {
+	params := pageParams(page, perPage)
+	params.Set("after", after.UTC().Format(time.RFC3339))
+
+	var orders []Order
+	info, err := c.get(ctx, "/orders", params, &orders)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get orders")
+	}
+	return orders, info, nil
+}
+
+// GetCurrency retrieves the store currency from the general settings
+func (c *Client) GetCurrency(ctx context.Context) (string, error) {
+	var setting Setting
+	if _, err := c.get(ctx, "/settings/general/woocommerce_currency", nil, &setting); err != nil {
+		return "", errors.Wrap(err, "failed to get currency setting")
+	}
+	return setting.Value, nil
+}
+
+// CountProducts retrieves the number of products from the X-WP-Total header
+func (c *Client) CountProducts(ctx context.Context) (int, error) {
+	_, info, err := c.GetProducts(ctx, 1, 1)
+	if err != nil {
+		return 0, err
+	}
+	return info.total, nil
+}
+
+// CountOrders retrieves the number of orders created after the given time
+func (c *Client) CountOrders(ctx context.Context, after time.Time) (int, error) {
+	_, info, err := c.GetOrders(ctx, after, 1, 1)
+	if err != nil {
+		return 0, err
+	}
+	return info.total, nil
+}
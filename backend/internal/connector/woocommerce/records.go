@@ -0,0 +1,137 @@
+package woocommerce
+
+import (
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+// toProduct converts a WooCommerce product. A simple product is its own single variant and
+// inventory item; a variable product has one variant per variation.
+func toProduct(product Product, variations []Variation) connector.Product {
+	var status core.ProductStatus
+	switch product.Status {
+	case "publish":
+		status = core.ProductStatusActive
+	default:
+		// draft, pending and private products are not for sale
+		status = core.ProductStatusDraft
+	}
+
+	var productType string
+	if len(product.Categories) > 0 {
+		productType = product.Categories[0].Name
+	}
+
+	result := connector.Product{
+		ExternalID:  connector.FormatID(product.ID),
+		Title:       product.Name,
+		Handle:      product.Slug,
+		ProductType: productType,
+		Status:      status,
+	}
+
+	productItem := &connector.InventoryItem{
+		ExternalID: connector.FormatID(product.ID),
+		SKU:        product.SKU,
+		Tracked:    product.ManageStock == ManageStockYes,
+	}
+
+	if product.Type != "variable" {
+		result.Variants = []connector.Variant{{
+			ExternalID:      connector.FormatID(product.ID),
+			SKU:             product.SKU,
+			Price:           product.Price,
+			InventoryItemID: productItem.ExternalID,
+			InventoryItem:   productItem,
+		}}
+		return result
+	}
+
+	for _, variation := range variations {
+		variant := connector.Variant{
+			ExternalID: connector.FormatID(variation.ID),
+			SKU:        variation.SKU,
+			Price:      variation.Price,
+		}
+
+		// Variations without stock of their own draw from the parent product's stock
+		item := &connector.InventoryItem{
+			ExternalID: connector.FormatID(variation.ID),
+			SKU:        variation.SKU,
+			Tracked:    variation.ManageStock == ManageStockYes,
+		}
+		if variation.ManageStock == ManageStockParent {
+			item = productItem
+		}
+		variant.InventoryItemID = item.ExternalID
+		variant.InventoryItem = item
+
+		result.Variants = append(result.Variants, variant)
+	}
+
+	return result
+}
+
+// stockLevel returns the level of an item at the default location if WooCommerce manages its stock
+func stockLevel(itemID int64, managed ManageStock, quantity *int) (connector.InventoryLevel, bool) {
+	if managed != ManageStockYes || quantity == nil {
+		return connector.InventoryLevel{}, false
+	}
+
+	return connector.InventoryLevel{
+		InventoryItemID: connector.FormatID(itemID),
+		LocationID:      defaultLocationID,
+		Available:       *quantity,
+	}, true
+}
+
+// toOrder converts a WooCommerce order. WooCommerce has a single order status, which is mapped
+// onto the financial and fulfillment statuses of the core schema.
+func toOrder(order Order) connector.Order {
+	financialStatus := core.FinancialStatusPending
+	fulfillmentStatus := core.FulfillmentStatusNull
+	var cancelledAt *time.Time
+
+	switch order.Status {
+	case "processing":
+		financialStatus = core.FinancialStatusPaid
+	case "completed":
+		financialStatus = core.FinancialStatusPaid
+		fulfillmentStatus = core.FulfillmentStatusFulfilled
+	case "refunded":
+		financialStatus = core.FinancialStatusRefunded
+	case "cancelled":
+		financialStatus = core.FinancialStatusVoided
+		modified := order.DateModifiedGMT.Time
+		cancelledAt = &modified
+	}
+
+	result := connector.Order{
+		ExternalID:        connector.FormatID(order.ID),
+		CreatedAt:         order.DateCreatedGMT.Time,
+		FinancialStatus:   financialStatus,
+		FulfillmentStatus: fulfillmentStatus,
+		TotalPrice:        order.Total,
+		CancelledAt:       cancelledAt,
+	}
+
+	for _, item := range order.LineItems {
+		lineItem := connector.OrderLineItem{
+			ExternalID: connector.FormatID(item.ID),
+			ProductID:  connector.FormatID(item.ProductID),
+			SKU:        item.SKU,
+			Quantity:   item.Quantity,
+			Price:      item.Price.String(),
+		}
+		if item.VariationID != 0 {
+			lineItem.VariantID = connector.FormatID(item.VariationID)
+		} else {
+			lineItem.VariantID = lineItem.ProductID
+		}
+		result.LineItems = append(result.LineItems, lineItem)
+	}
+
+	return result
+}
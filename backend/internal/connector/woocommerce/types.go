@@ -0,0 +1,129 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Setting represents a store setting from the WooCommerce settings API
+type Setting struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+// Product represents a product from the WooCommerce API
+type Product struct {
+	ID            int64       `json:"id"`
+	Name          string      `json:"name"`
+	Slug          string      `json:"slug"`
+	Type          string      `json:"type"`
+	Status        string      `json:"status"`
+	SKU           string      `json:"sku"`
+	Price         string      `json:"price"`
+	ManageStock   ManageStock `json:"manage_stock"`
+	StockQuantity *int        `json:"stock_quantity"`
+	Categories    []struct {
+		Name string `json:"name"`
+	} `json:"categories"`
+	Variations []int64 `json:"variations"`
+}
+
+// Variation represents a variation of a variable product from the WooCommerce API
+type Variation struct {
+	ID            int64       `json:"id"`
+	SKU           string      `json:"sku"`
+	Price         string      `json:"price"`
+	ManageStock   ManageStock `json:"manage_stock"`
+	StockQuantity *int        `json:"stock_quantity"`
+}
+
+// Order represents an order from the WooCommerce API
+type Order struct {
+	ID              int64           `json:"id"`
+	Status          string          `json:"status"`
+	Total           string          `json:"total"`
+	DateCreatedGMT  GMTTime         `json:"date_created_gmt"`
+	DateModifiedGMT GMTTime         `json:"date_modified_gmt"`
+	LineItems       []OrderLineItem `json:"line_items"`
+}
+
+// OrderLineItem represents a line item of an order from the WooCommerce API
+type OrderLineItem struct {
+	ID          int64       `json:"id"`
+	ProductID   int64       `json:"product_id"`
+	VariationID int64       `json:"variation_id"`
+	SKU         string      `json:"sku"`
+	Quantity    int         `json:"quantity"`
+	Price       json.Number `json:"price"`
+}
+
+// ManageStock is the manage_stock field: a boolean on products, and on variations either a
+// boolean or "parent" when the parent product holds the stock
+type ManageStock string
+
+const (
+	ManageStockYes    ManageStock = "true"
+	ManageStockNo     ManageStock = "false"
+	ManageStockParent ManageStock = "parent"
+)
+
+// MarshalJSON writes "parent" as a string and the other values as booleans
+func (m ManageStock) MarshalJSON() ([]byte, error) {
+	if m == ManageStockParent {
+		return json.Marshal(string(m))
+	}
+	return json.Marshal(m == ManageStockYes)
+}
+
+// UnmarshalJSON accepts both the boolean and the string form
+func (m *ManageStock) UnmarshalJSON(data []byte) error {
+	var managed bool
+	if err := json.Unmarshal(data, &managed); err == nil {
+		*m = ManageStockNo
+		if managed {
+			*m = ManageStockYes
+		}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.Wrapf(err, "invalid manage_stock %s", data)
+	}
+	*m = ManageStock(value)
+	return nil
+}
+
+// GMTTime is a WooCommerce *_gmt timestamp, which is UTC but carries no zone designator
+type GMTTime struct {
+	time.Time
+}
+
+// gmtLayout is the layout of *_gmt timestamps
+const gmtLayout = "2006-01-02T15:04:05"
+
+// MarshalJSON writes the timestamp in UTC without a zone designator, a zero timestamp as null
+func (t GMTTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(gmtLayout))
+}
+
+// UnmarshalJSON parses the timestamp as UTC, a null timestamp is left zero
+func (t *GMTTime) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		return nil
+	}
+
+	parsed, err := time.Parse(gmtLayout, value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timestamp %s", data)
+	}
+	t.Time = parsed.UTC()
+	return nil
+}
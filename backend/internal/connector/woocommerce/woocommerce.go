@@ -0,0 +1,200 @@
+package woocommerce
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Page size requested from the WooCommerce API
+	pageSize = maxPerPage
+
+	// WooCommerce core has a single stock pool per product, synced as this one location
+	defaultLocationID = "default"
+)
+
+// Connector reads a WooCommerce store through the REST API
+type Connector struct {
+	client   *Client
+	storeURL string
+}
+
+var (
+	_ connector.PlatformConnector = (*Connector)(nil)
+	_ connector.Counter           = (*Connector)(nil)
+	_ connector.CurrencyReader    = (*Connector)(nil)
+)
+
+// New creates a connector that reads through the given client
+func New(client *Client) *Connector {
+	return &Connector{client: client, storeURL: client.storeURL}
+}
+
+// Factory creates the connectors of WooCommerce integrations from their stored credentials
+func Factory(database db.Database) connector.Factory {
+	return func(ctx context.Context, integration core.PlatformIntegration) (connector.PlatformConnector, error) {
+		credentials, err := database.GetCore().GetWooCommerceCredentialsByIntegrationID(ctx, integration.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get woocommerce credentials")
+		}
+
+		client := NewClient(credentials.StoreUrl, credentials.ConsumerKey.String(), credentials.ConsumerSecret.String())
+		return New(client), nil
+	}
+}
+
+// Platform implements connector.PlatformConnector
+func (c *Connector) Platform() core.PlatformType {
+	return core.PlatformTypeWoocommerce
+}
+
+// Verify checks that the credentials can read the store's catalog and orders
+func (c *Connector) Verify(ctx context.Context) error {
+	if _, _, err := c.client.GetProducts(ctx, 1, 1); err != nil {
+		return err
+	}
+	if _, _, err := c.client.GetOrders(ctx, time.Now(), 1, 1); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListLocations implements connector.PlatformConnector. The store's single stock pool is one location.
+func (c *Connector) ListLocations(ctx context.Context, cursor string) (*connector.Page[connector.Location], error) {
+	name := c.storeURL
+	if parsed, err := url.Parse(c.storeURL); err == nil && parsed.Host != "" {
+		name = parsed.Host
+	}
+
+	return &connector.Page[connector.Location]{
+		Items: []connector.Location{{ExternalID: defaultLocationID, Name: name, Active: true}},
+	}, nil
+}
+
+// ListProducts implements connector.PlatformConnector. The variations of variable products on the page are fetched with it.
+func (c *Connector) ListProducts(ctx context.Context, cursor string) (*connector.Page[connector.Product], error) {
+	products, variations, next, err := c.productsPage(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Product]{NextCursor: next}
+	for _, product := range products {
+		page.Items = append(page.Items, toProduct(product, variations[product.ID]))
+	}
+	return page, nil
+}
+
+// ListInventoryLevels implements connector.PlatformConnector.
+// Stock quantities live on products and variations, so this pages through the catalog again.
+func (c *Connector) ListInventoryLevels(ctx context.Context, cursor string) (*connector.Page[connector.InventoryLevel], error) {
+	products, variations, next, err := c.productsPage(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.InventoryLevel]{NextCursor: next}
+	for _, product := range products {
+		if level, ok := stockLevel(product.ID, product.ManageStock, product.StockQuantity); ok {
+			page.Items = append(page.Items, level)
+		}
+		for _, variation := range variations[product.ID] {
+			if level, ok := stockLevel(variation.ID, variation.ManageStock, variation.StockQuantity); ok {
+				page.Items = append(page.Items, level)
+			}
+		}
+	}
+	return page, nil
+}
+
+// ListOrders implements connector.PlatformConnector
+func (c *Connector) ListOrders(ctx context.Context, since time.Time, cursor string) (*connector.Page[connector.Order], error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, info, err := c.client.GetOrders(ctx, since, pageNumber, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &connector.Page[connector.Order]{NextCursor: nextCursor(pageNumber, info)}
+	for _, order := range orders {
+		page.Items = append(page.Items, toOrder(order))
+	}
+	return page, nil
+}
+
+// CountLocations implements connector.Counter
+func (c *Connector) CountLocations(ctx context.Context) (int, error) {
+	return 1, nil
+}
+
+// CountProducts implements connector.Counter
+func (c *Connector) CountProducts(ctx context.Context) (int, error) {
+	return c.client.CountProducts(ctx)
+}
+
+// CountOrders implements connector.Counter
+func (c *Connector) CountOrders(ctx context.Context, since time.Time) (int, error) {
+	return c.client.CountOrders(ctx, since)
+}
+
+// Currency implements connector.CurrencyReader with the currency of the store's settings
+func (c *Connector) Currency(ctx context.Context) (string, error) {
+	return c.client.GetCurrency(ctx)
+}
+
+// productsPage fetches one page of products and the variations of the variable ones, by product ID
+func (c *Connector) productsPage(ctx context.Context, cursor string) ([]Product, map[int64][]Variation, string, error) {
+	pageNumber, err := parseCursor(cursor)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	products, info, err := c.client.GetProducts(ctx, pageNumber, pageSize)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	variations := make(map[int64][]Variation)
+	for _, product := range products {
+		if product.Type != "variable" || len(product.Variations) == 0 {
+			continue
+		}
+		if variations[product.ID], err = c.client.GetAllVariations(ctx, product.ID); err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	return products, variations, nextCursor(pageNumber, info), nil
+}
+
+// parseCursor decodes a page number cursor, an empty cursor being the first page
+func parseCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 1, nil
+	}
+
+	page, err := strconv.Atoi(cursor)
+	if err != nil || page < 1 {
+		return 0, errors.Errorf("invalid woocommerce cursor %q", cursor)
+	}
+	return page, nil
+}
+
+// nextCursor returns the cursor of the page after pageNumber, or "" when it was the last one
+func nextCursor(pageNumber int, info *listInfo) string {
+	if pageNumber >= info.totalPages {
+		return ""
+	}
+	return strconv.Itoa(pageNumber + 1)
+}
@@ -0,0 +1,140 @@
+package woocommerce_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce/woocommercetest"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/pkg/errors"
+)
+
+func newConnector(t *testing.T) (*woocommerce.Connector, *woocommercetest.Server) {
+	t.Helper()
+
+	server := woocommercetest.NewServer(woocommercetest.DemoFixtures())
+	t.Cleanup(server.Close)
+	return woocommerce.New(server.Client(woocommercetest.ConsumerKey, woocommercetest.ConsumerSecret)), server
+}
+
+// addProducts adds n simple products with managed stock to the store
+func addProducts(server *woocommercetest.Server, n int) {
+	server.UpdateFixtures(func(f *woocommercetest.Fixtures) {
+		for i := int64(1); i <= int64(n); i++ {
+			quantity := int(i)
+			f.Products = append(f.Products, woocommerce.Product{
+				ID: 1000 + i, Name: "Extra", Type: "simple", Status: "publish",
+				ManageStock: woocommerce.ManageStockYes, StockQuantity: &quantity,
+			})
+		}
+	})
+}
+
+func TestListProductsMapsVariations(t *testing.T) {
+	conn, _ := newConnector(t)
+
+	products := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Product], error) {
+		return conn.ListProducts(context.Background(), cursor)
+	})
+	if len(products) != 3 {
+		t.Fatalf("listed %d products, want 3", len(products))
+	}
+
+	tote, shirt, giftCard := products[0], products[1], products[2]
+
+	// A simple product is its own variant and inventory item
+	if len(tote.Variants) != 1 || tote.Variants[0].ExternalID != "11" || tote.Variants[0].InventoryItemID != "11" {
+		t.Errorf("simple product variants = %+v, want the product itself", tote.Variants)
+	}
+	if tote.Status != core.ProductStatusActive || !tote.Variants[0].InventoryItem.Tracked {
+		t.Errorf("simple product = %+v, want an active product with a tracked item", tote)
+	}
+
+	if len(shirt.Variants) != 3 {
+		t.Fatalf("variable product has %d variants, want 3", len(shirt.Variants))
+	}
+	if item := shirt.Variants[0].InventoryItemID; item != "121" {
+		t.Errorf("variation with its own stock uses item %s, want 121", item)
+	}
+	// The parent product holds the stock of this variation
+	if item := shirt.Variants[2].InventoryItemID; item != "12" {
+		t.Errorf("parent managed variation uses item %s, want 12", item)
+	}
+
+	if giftCard.Status != core.ProductStatusDraft || giftCard.Variants[0].InventoryItem.Tracked {
+		t.Errorf("draft product = %+v, want a draft with an untracked item", giftCard)
+	}
+}
+
+func TestListInventoryLevelsPagesThroughCatalog(t *testing.T) {
+	conn, server := newConnector(t)
+	ctx := context.Background()
+
+	// Push the catalog past one page
+	addProducts(server, 150)
+
+	levels := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.InventoryLevel], error) {
+		return conn.ListInventoryLevels(ctx, cursor)
+	})
+
+	// Tote, shirt, two shirt variations with their own stock and the added products;
+	// the gift card does not manage stock and the parent managed variation has no level of its own
+	if want := 4 + 150; len(levels) != want {
+		t.Errorf("listed %d inventory levels, want %d", len(levels), want)
+	}
+	for _, level := range levels {
+		if level.LocationID == "" {
+			t.Errorf("level %+v has no location", level)
+		}
+	}
+
+	locations, err := conn.ListLocations(ctx, "")
+	if err != nil {
+		t.Fatalf("ListLocations: %v", err)
+	}
+	if len(locations.Items) != 1 || locations.Items[0].ExternalID != levels[0].LocationID {
+		t.Errorf("locations = %+v, want the single location of the levels", locations.Items)
+	}
+
+	if _, err := conn.ListProducts(ctx, "not-a-cursor"); err == nil {
+		t.Error("expected an error for a malformed cursor")
+	}
+}
+
+func TestListOrdersMapsStatuses(t *testing.T) {
+	conn, _ := newConnector(t)
+
+	orders := connectortest.ListAll(t, func(cursor string) (*connector.Page[connector.Order], error) {
+		return conn.ListOrders(context.Background(), time.Now().AddDate(0, 0, -20), cursor)
+	})
+	if len(orders) != 2 {
+		t.Fatalf("listed %d orders, want 2", len(orders))
+	}
+
+	completed, cancelled := orders[0], orders[1]
+	if completed.FinancialStatus != core.FinancialStatusPaid || completed.FulfillmentStatus != core.FulfillmentStatusFulfilled {
+		t.Errorf("completed order statuses = %s/%s, want paid/fulfilled", completed.FinancialStatus, completed.FulfillmentStatus)
+	}
+	if completed.LineItems[0].VariantID != "11" || completed.LineItems[1].VariantID != "121" {
+		t.Errorf("line item variants = %s, %s, want 11, 121", completed.LineItems[0].VariantID, completed.LineItems[1].VariantID)
+	}
+	if cancelled.FinancialStatus != core.FinancialStatusVoided || cancelled.CancelledAt == nil {
+		t.Errorf("cancelled order = %+v, want voided with a cancel time", cancelled)
+	}
+}
+
+func TestErrorsKeepAPIError(t *testing.T) {
+	conn, server := newConnector(t)
+	server.FailNext("/products", http.StatusNotFound, 1)
+
+	_, err := conn.ListProducts(context.Background(), "")
+	var apiErr *woocommerce.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "injected_error" {
+		t.Errorf("got %v, want the WooCommerce API error kept", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package woocommercetest provides a fake WooCommerce REST API (wc/v3) for tests. It serves
+// the currency setting, products, variations and orders from fixtures with page/per_page
+// pagination and the X-WP-Total headers, checks the consumer key and secret, and can inject
+// errors.
+package woocommercetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/connectortest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce"
+)
+
+const (
+	// Credentials accepted by a server created with NewServer
+	ConsumerKey    = "ck_test"
+	ConsumerSecret = "cs_test"
+
+	apiPrefix       = "/wp-json/wc/v3"
+	defaultPerPage  = 10
+	maxPerPage      = 100
+	afterTimeLayout = time.RFC3339
+)
+
+// Fixtures is the data a fake store serves, in the shape of the WooCommerce REST API
+type Fixtures struct {
+	Currency   string
+	Products   []woocommerce.Product
+	Variations map[int64][]woocommerce.Variation
+	Orders     []woocommerce.Order
+}
+
+// DemoFixtures returns a small store: a simple product, a variable product whose variations
+// hold their own stock or share the parent's, an untracked product and a few orders
+func DemoFixtures() *Fixtures {
+	stock := func(n int) *int { return &n }
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return &Fixtures{
+		Currency: "EUR",
+		Products: []woocommerce.Product{
+			{ID: 11, Name: "Canvas Tote", Slug: "canvas-tote", Type: "simple", Status: "publish", SKU: "TOTE-1", Price: "24.00", ManageStock: woocommerce.ManageStockYes, StockQuantity: stock(12)},
+			{ID: 12, Name: "Linen Shirt", Slug: "linen-shirt", Type: "variable", Status: "publish", SKU: "SHIRT", Price: "58.00", ManageStock: woocommerce.ManageStockYes, StockQuantity: stock(7), Variations: []int64{121, 122, 123}},
+			{ID: 13, Name: "Gift Card", Slug: "gift-card", Type: "simple", Status: "draft", SKU: "GIFT", Price: "50.00", ManageStock: woocommerce.ManageStockNo},
+		},
+		Variations: map[int64][]woocommerce.Variation{
+			12: {
+				{ID: 121, SKU: "SHIRT-S", Price: "58.00", ManageStock: woocommerce.ManageStockYes, StockQuantity: stock(3)},
+				{ID: 122, SKU: "SHIRT-M", Price: "58.00", ManageStock: woocommerce.ManageStockYes, StockQuantity: stock(5)},
+				{ID: 123, SKU: "SHIRT-L", Price: "60.00", ManageStock: woocommerce.ManageStockParent, StockQuantity: stock(7)},
+			},
+		},
+		Orders: []woocommerce.Order{
+			{
+				ID: 501, Status: "completed", Total: "82.00",
+				DateCreatedGMT:  woocommerce.GMTTime{Time: now.AddDate(0, 0, -10)},
+				DateModifiedGMT: woocommerce.GMTTime{Time: now.AddDate(0, 0, -9)},
+				LineItems: []woocommerce.OrderLineItem{
+					{ID: 1, ProductID: 11, SKU: "TOTE-1", Quantity: 1, Price: "24"},
+					{ID: 2, ProductID: 12, VariationID: 121, SKU: "SHIRT-S", Quantity: 1, Price: "58"},
+				},
+			},
+			{
+				ID: 502, Status: "cancelled", Total: "24.00",
+				DateCreatedGMT:  woocommerce.GMTTime{Time: now.AddDate(0, 0, -3)},
+				DateModifiedGMT: woocommerce.GMTTime{Time: now.AddDate(0, 0, -2)},
+				LineItems:       []woocommerce.OrderLineItem{{ID: 3, ProductID: 11, SKU: "TOTE-1", Quantity: 1, Price: "24"}},
+			},
+			{
+				ID: 503, Status: "processing", Total: "120.00",
+				DateCreatedGMT:  woocommerce.GMTTime{Time: now.AddDate(0, 0, -40)},
+				DateModifiedGMT: woocommerce.GMTTime{Time: now.AddDate(0, 0, -40)},
+				LineItems:       []woocommerce.OrderLineItem{{ID: 4, ProductID: 12, VariationID: 123, SKU: "SHIRT-L", Quantity: 2, Price: "60"}},
+			},
+		},
+	}
+}
+
+// Server is a running fake WooCommerce store
+type Server struct {
+	*httptest.Server
+	*connectortest.Fake[Fixtures]
+}
+
+// NewServer starts a fake store serving the given fixtures
+func NewServer(fixtures *Fixtures) *Server {
+	s := &Server{Fake: connectortest.NewFake(fixtures)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Client returns a client that talks to this server with the given credentials
+func (s *Server) Client(consumerKey, consumerSecret string) *woocommerce.Client {
+	return woocommerce.NewClient(s.URL, consumerKey, consumerSecret)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := strings.CutPrefix(r.URL.Path, apiPrefix)
+	if !ok {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.Record(endpoint)
+
+	key, secret, _ := r.BasicAuth()
+	if key != ConsumerKey || secret != ConsumerSecret {
+		writeError(w, http.StatusUnauthorized, "woocommerce_rest_cannot_view", "Sorry, you cannot list resources.")
+		return
+	}
+
+	if status, failed := s.NextFailure(endpoint); failed {
+		writeError(w, status, "injected_error", http.StatusText(status))
+		return
+	}
+
+	switch {
+	case endpoint == "/settings/general/woocommerce_currency":
+		writeJSON(w, http.StatusOK, woocommerce.Setting{ID: "woocommerce_currency", Value: s.Fixtures.Currency})
+	case endpoint == "/products":
+		servePage(w, r, s.Fixtures.Products)
+	case endpoint == "/orders":
+		s.serveOrders(w, r)
+	case strings.HasPrefix(endpoint, "/products/") && strings.HasSuffix(endpoint, "/variations"):
+		productID, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(endpoint, "/products/"), "/variations"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "woocommerce_rest_product_invalid_id", "Invalid ID.")
+			return
+		}
+		servePage(w, r, s.Fixtures.Variations[productID])
+	default:
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+	}
+}
+
+// serveOrders serves the orders created after the after parameter
+func (s *Server) serveOrders(w http.ResponseWriter, r *http.Request) {
+	orders := s.Fixtures.Orders
+	if after := r.URL.Query().Get("after"); after != "" {
+		since, err := time.Parse(afterTimeLayout, after)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): after")
+			return
+		}
+		orders = nil
+		for _, order := range s.Fixtures.Orders {
+			if order.DateCreatedGMT.After(since) {
+				orders = append(orders, order)
+			}
+		}
+	}
+	servePage(w, r, orders)
+}
+
+// servePage writes one page of items with the X-WP-Total and X-WP-TotalPages headers.
+// Fixtures are kept in ascending ID order, the order the client requests.
+func servePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	page, perPage := 1, defaultPerPage
+	if value := r.URL.Query().Get("page"); value != "" {
+		page, _ = strconv.Atoi(value)
+	}
+	if value := r.URL.Query().Get("per_page"); value != "" {
+		perPage, _ = strconv.Atoi(value)
+	}
+	if page < 1 || perPage < 1 || perPage > maxPerPage {
+		writeError(w, http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): page, per_page")
+		return
+	}
+
+	totalPages := (len(items) + perPage - 1) / perPage
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	w.Header().Set("X-WP-Total", strconv.Itoa(len(items)))
+	w.Header().Set("X-WP-TotalPages", strconv.Itoa(totalPages))
+	writeJSON(w, http.StatusOK, append([]T{}, items[start:end]...))
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the WordPress REST API format
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{"code": code, "message": message, "data": map[string]int{"status": status}})
+}
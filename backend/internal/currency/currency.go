@@ -0,0 +1,110 @@
+// Package currency converts amounts between currencies with exchange rates loaded from files or
+// rate providers
+package currency
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidCode is returned for currency codes that are not three letters
+var ErrInvalidCode = errors.New("currency: invalid ISO 4217 code")
+
+var codePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Normalize trims and upper-cases an ISO 4217 code ("usd " becomes "USD")
+func Normalize(code string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	if !codePattern.MatchString(normalized) {
+		return "", errors.Wrapf(ErrInvalidCode, "%q", code)
+	}
+	return normalized, nil
+}
+
+// Rate is the value of one unit of the base currency in the quote currency on a day
+type Rate struct {
+	Base  string
+	Quote string
+	Date  time.Time
+	Rate  float64
+}
+
+// Provider fetches the current exchange rates from a source of rates
+type Provider interface {
+	// Name identifies the provider as the source of the rates it fetched
+	Name() string
+
+	// Rates fetches the latest rates the provider publishes
+	Rates(ctx context.Context) ([]Rate, error)
+}
+
+// Converter converts amounts with a set of rates. Pairs without a rate are converted with the
+// inverse rate, or across one currency both have a rate with, like the euro of ECB rates.
+type Converter struct {
+	rates      map[[2]string]float64
+	currencies []string
+}
+
+// NewConverter creates a converter of the rates, the later of two rates of a pair winning
+func NewConverter(rates []Rate) *Converter {
+	c := &Converter{rates: make(map[[2]string]float64, len(rates))}
+	dates := make(map[[2]string]time.Time, len(rates))
+	for _, rate := range rates {
+		pair := [2]string{rate.Base, rate.Quote}
+		if date, ok := dates[pair]; rate.Rate <= 0 || (ok && date.After(rate.Date)) {
+			continue
+		}
+		c.rates[pair], dates[pair] = rate.Rate, rate.Date
+	}
+	for pair := range c.rates {
+		c.currencies = append(c.currencies, pair[0], pair[1])
+	}
+	slices.Sort(c.currencies)
+	c.currencies = slices.Compact(c.currencies)
+	return c
+}
+
+// Rate returns the value of one unit of from in to, false when the rates cannot convert them
+func (c *Converter) Rate(from, to string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	if rate, ok := c.pairRate(from, to); ok {
+		return rate, true
+	}
+	for _, via := range c.currencies {
+		first, ok := c.pairRate(from, via)
+		if !ok {
+			continue
+		}
+		if second, ok := c.pairRate(via, to); ok {
+			return first * second, true
+		}
+	}
+	return 0, false
+}
+
+// Convert converts an amount in from into to, false when the rates cannot convert them
+func (c *Converter) Convert(amount float64, from, to string) (float64, bool) {
+	rate, ok := c.Rate(from, to)
+	if !ok {
+		return 0, false
+	}
+	return amount * rate, true
+}
+
+// pairRate returns the direct or inverse rate of a pair
+func (c *Converter) pairRate(from, to string) (float64, bool) {
+	if rate, ok := c.rates[[2]string{from, to}]; ok {
+		return rate, true
+	}
+	if rate, ok := c.rates[[2]string{to, from}]; ok {
+		return 1 / rate, true
+	}
+	return 0, false
+}
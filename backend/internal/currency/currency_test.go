@@ -0,0 +1,123 @@
+package currency_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
+	"github.com/pkg/errors"
+)
+
+// day parses a rate date
+func day(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return date
+}
+
+func TestNormalize(t *testing.T) {
+	if code, err := currency.Normalize(" usd"); err != nil || code != "USD" {
+		t.Errorf("Normalize(usd) = %q %v, want USD", code, err)
+	}
+	for _, code := range []string{"", "US", "USDT", "U$D"} {
+		if _, err := currency.Normalize(code); !errors.Is(err, currency.ErrInvalidCode) {
+			t.Errorf("Normalize(%q) got %v, want %v", code, err, currency.ErrInvalidCode)
+		}
+	}
+}
+
+func TestConverter(t *testing.T) {
+	converter := currency.NewConverter([]currency.Rate{
+		{Base: "EUR", Quote: "USD", Date: day(t, "2024-01-01"), Rate: 1.05},
+		{Base: "EUR", Quote: "USD", Date: day(t, "2024-01-02"), Rate: 1.1},
+		{Base: "EUR", Quote: "GBP", Date: day(t, "2024-01-02"), Rate: 0.88},
+		{Base: "JPY", Quote: "CHF", Date: day(t, "2024-01-02"), Rate: 0.006},
+	})
+
+	tests := []struct {
+		amount   float64
+		from, to string
+		want     float64
+		wantOK   bool
+	}{
+		{amount: 10, from: "USD", to: "USD", want: 10, wantOK: true},
+		// The later of two rates of a pair wins
+		{amount: 10, from: "EUR", to: "USD", want: 11, wantOK: true},
+		{amount: 11, from: "USD", to: "EUR", want: 10, wantOK: true},
+		// Across the euro
+		{amount: 11, from: "USD", to: "GBP", want: 8.8, wantOK: true},
+		{amount: 8.8, from: "GBP", to: "USD", want: 11, wantOK: true},
+		{amount: 10, from: "USD", to: "CHF"},
+		{amount: 10, from: "USD", to: "CAD"},
+	}
+	for _, tt := range tests {
+		got, ok := converter.Convert(tt.amount, tt.from, tt.to)
+		if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %s, %s) = %v %v, want %v %v", tt.amount, tt.from, tt.to, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestReadRates(t *testing.T) {
+	rates, err := currency.ReadRates(strings.NewReader("Base,Quote,Rate,Date\neur, usd, 1.0956, 2024-01-02\nGBP,USD,1.27,2024-01-02\n"))
+	if err != nil {
+		t.Fatalf("ReadRates: %v", err)
+	}
+	if len(rates) != 2 || rates[0] != (currency.Rate{Base: "EUR", Quote: "USD", Date: day(t, "2024-01-02"), Rate: 1.0956}) {
+		t.Errorf("rates = %+v, want EUR/USD and GBP/USD", rates)
+	}
+
+	invalid := map[string]string{
+		"date,base,quote\n":                               "no rate column",
+		"date,base,quote,rate\n2024-01-02,EUR,USD,0\n":    "line 2: invalid rate",
+		"date,base,quote,rate\n02/01/2024,EUR,USD,1.1\n":  "line 2: invalid date",
+		"date,base,quote,rate\n2024-01-02,EURO,USD,1.1\n": "line 2",
+	}
+	for content, want := range invalid {
+		if _, err := currency.ReadRates(strings.NewReader(content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ReadRates(%q) got %v, want %q", content, err, want)
+		}
+	}
+}
+
+func TestECBProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2024-01-02">
+			<Cube currency="USD" rate="1.0956"/>
+			<Cube currency="JPY" rate="155.52"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`))
+	}))
+	defer server.Close()
+
+	provider := currency.NewECBProvider(server.URL)
+	rates, err := provider.Rates(context.Background())
+	if err != nil {
+		t.Fatalf("Rates: %v", err)
+	}
+	want := []currency.Rate{
+		{Base: "EUR", Quote: "USD", Date: day(t, "2024-01-02"), Rate: 1.0956},
+		{Base: "EUR", Quote: "JPY", Date: day(t, "2024-01-02"), Rate: 155.52},
+	}
+	if len(rates) != len(want) || rates[0] != want[0] || rates[1] != want[1] {
+		t.Errorf("rates = %+v, want %+v", rates, want)
+	}
+	if provider.Name() != "ecb" {
+		t.Errorf("Name() = %q, want ecb", provider.Name())
+	}
+}
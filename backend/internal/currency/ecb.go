@@ -0,0 +1,95 @@
+package currency
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ECBDailyURL is the European Central Bank's feed of the euro reference rates of the last
+// working day
+const ECBDailyURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// ECBProvider fetches the euro reference rates of the European Central Bank. Every rate has the
+// euro as base; other pairs convert across it.
+type ECBProvider struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewECBProvider creates a provider reading the ECB feed at url, ECBDailyURL when empty
+func NewECBProvider(url string) *ECBProvider {
+	if url == "" {
+		url = ECBDailyURL
+	}
+	return &ECBProvider{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// ecbEnvelope is the XML document of the ECB feed, a cube of rates per day
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Name implements Provider
+func (p *ECBProvider) Name() string {
+	return "ecb"
+}
+
+// Rates implements Provider
+func (p *ECBProvider) Rates(ctx context.Context) ([]Rate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create ECB request")
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch ECB rates")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("ECB rates returned status %d", resp.StatusCode)
+	}
+
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, errors.Wrap(err, "failed to decode ECB rates")
+	}
+
+	var rates []Rate
+	for _, day := range envelope.Days {
+		date, err := time.Parse(dateLayout, day.Time)
+		if err != nil {
+			return nil, errors.Errorf("invalid ECB rate date %q", day.Time)
+		}
+		for _, cube := range day.Rates {
+			quote, err := Normalize(cube.Currency)
+			if err != nil {
+				return nil, err
+			}
+			value, err := strconv.ParseFloat(cube.Rate, 64)
+			if err != nil || value <= 0 {
+				return nil, errors.Errorf("invalid ECB rate %q of %s", cube.Rate, quote)
+			}
+			rates = append(rates, Rate{Base: "EUR", Quote: quote, Date: date, Rate: value})
+		}
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("ECB feed has no rates")
+	}
+	return rates, nil
+}
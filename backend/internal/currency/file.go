@@ -0,0 +1,83 @@
+package currency
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Layout of the dates of rate files
+const dateLayout = "2006-01-02"
+
+// rateColumns are the columns of a rate file, in any order
+var rateColumns = []string{"date", "base", "quote", "rate"}
+
+// ReadRates reads a CSV file of rates with a header naming the columns date, base, quote and
+// rate ("2024-01-02,EUR,USD,1.0956"). Lines are numbered from the header in errors.
+func ReadRates(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("rate file is empty")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rate file header")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range rateColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Errorf("rate file has no %s column", name)
+		}
+	}
+
+	var rates []Rate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read line %d", line)
+		}
+
+		rate, err := parseRate(record, columns)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		rates = append(rates, rate)
+	}
+}
+
+// parseRate parses a record of a rate file
+func parseRate(record []string, columns map[string]int) (Rate, error) {
+	field := func(name string) string {
+		return strings.TrimSpace(record[columns[name]])
+	}
+
+	date, err := time.Parse(dateLayout, field("date"))
+	if err != nil {
+		return Rate{}, errors.Errorf("invalid date %q", field("date"))
+	}
+	base, err := Normalize(field("base"))
+	if err != nil {
+		return Rate{}, err
+	}
+	quote, err := Normalize(field("quote"))
+	if err != nil {
+		return Rate{}, err
+	}
+	value, err := strconv.ParseFloat(field("rate"), 64)
+	if err != nil || value <= 0 {
+		return Rate{}, errors.Errorf("invalid rate %q", field("rate"))
+	}
+	return Rate{Base: base, Quote: quote, Date: date, Rate: value}, nil
+}
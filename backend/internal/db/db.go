@@ -22,6 +22,7 @@ type Database interface {
 	GetShopify() shopify.Querier
 	GetCore() core.Querier
 	WithTx(ctx context.Context, fn func(*TxDB) error) error
+	TryLock(ctx context.Context, key string) (release func(), acquired bool, err error)
 	Close()
 }
 
@@ -168,6 +169,39 @@ func (db *DB) WithTx(ctx context.Context, fn func(*TxDB) error) error {
 	return nil
 }
 
+// TryLock takes the Postgres advisory lock for the key without waiting for it.
+// Advisory locks belong to a session, so the lock holds on to a dedicated pool connection until
+// release is called. If the process dies the session ends and Postgres frees the lock.
+func (db *DB) TryLock(ctx context.Context, key string) (func(), bool, error) {
+	conn, err := db.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to acquire connection for lock")
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtextextended($1, 0))", key).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, errors.Wrap(err, "failed to take advisory lock")
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	release := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// Should the unlock fail, close the session instead of returning a locked connection to the pool
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_unlock(hashtextextended($1, 0))", key); err != nil {
+			_ = conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}
+
+	return release, true, nil
+}
+
 // TxDB provides repository access within a transaction
 type TxDB struct {
 	Users   users.Querier
@@ -0,0 +1,293 @@
+package dbtest
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// The merchant account queries, and the views aggregating the catalog of an account's integrations
+
+// accountUserKey identifies a user of an account
+type accountUserKey struct {
+	accountID id.ID[id.MerchantAccount]
+	userID    id.ID[id.User]
+}
+
+// skuKey identifies the rows of a SKU of an integration in the account views
+type skuKey struct {
+	sku           string
+	integrationID id.ID[id.PlatformIntegration]
+}
+
+// compareSKUKeys orders view rows by SKU, then integration
+func compareSKUKeys(a, b skuKey) int {
+	return cmp.Or(strings.Compare(a.sku, b.sku), strings.Compare(a.integrationID.String(), b.integrationID.String()))
+}
+
+func (m *Memory) AddMerchantAccountUser(ctx context.Context, arg core.AddMerchantAccountUserParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tables.merchantAccounts[arg.AccountID]; !ok {
+		return errors.Errorf("insert on table \"merchant_account_users\" violates foreign key constraint on account_id: %s", arg.AccountID)
+	}
+
+	key := accountUserKey{arg.AccountID, arg.UserID}
+	if _, ok := m.tables.merchantAccountUsers[key]; !ok {
+		m.tables.merchantAccountUsers[key] = core.MerchantAccountUser{AccountID: arg.AccountID, UserID: arg.UserID, CreatedAt: now()}
+	}
+	return nil
+}
+
+func (m *Memory) CreateMerchantAccount(ctx context.Context, arg core.CreateMerchantAccountParams) (core.MerchantAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tables.merchantAccounts[arg.ID]; ok {
+		return core.MerchantAccount{}, errors.New("duplicate key value violates unique constraint \"merchant_accounts_pkey\"")
+	}
+
+	account := core.MerchantAccount{ID: arg.ID, Name: arg.Name, CreatedAt: now(), UpdatedAt: now(), ReportingCurrency: arg.ReportingCurrency}
+	m.tables.merchantAccounts[account.ID] = account
+	return account, nil
+}
+
+func (m *Memory) GetMerchantAccountByID(ctx context.Context, argID id.ID[id.MerchantAccount]) (core.MerchantAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, ok := m.tables.merchantAccounts[argID]
+	if !ok {
+		return core.MerchantAccount{}, pgx.ErrNoRows
+	}
+	return account, nil
+}
+
+func (m *Memory) GetMerchantAccountByShopID(ctx context.Context, shopID id.ID[id.ShopifyStore]) (core.MerchantAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shop, ok := m.tables.merchantAccountShops[shopID]
+	if !ok {
+		return core.MerchantAccount{}, pgx.ErrNoRows
+	}
+	return m.tables.merchantAccounts[shop.AccountID], nil
+}
+
+func (m *Memory) GetMerchantAccountInventoryBySKU(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]core.GetMerchantAccountInventoryBySKURow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := map[skuKey]*core.GetMerchantAccountInventoryBySKURow{}
+	for _, variant := range m.tables.productVariants {
+		product, ok := m.tables.products[variant.ProductID]
+		if !ok || product.DeletedAt.Valid || variant.DeletedAt.Valid || variant.Sku.String == "" {
+			continue
+		}
+		integration, ok := m.accountIntegration(accountID, product.IntegrationID)
+		if !ok || !integration.IsActive.Bool {
+			continue
+		}
+
+		key := skuKey{variant.Sku.String, integration.ID}
+		row, ok := rows[key]
+		if !ok {
+			row = &core.GetMerchantAccountInventoryBySKURow{Sku: key.sku, IntegrationID: integration.ID, PlatformType: integration.PlatformType, Currency: integration.Currency, Title: product.Title}
+			rows[key] = row
+		}
+		row.Title = min(row.Title, product.Title)
+		row.VariantsCount++
+		available, value := m.variantStock(integration.ID, variant)
+		row.Available += available
+		row.StockValue += value
+	}
+
+	return sortedSKURows(rows, func(row *core.GetMerchantAccountInventoryBySKURow) skuKey {
+		return skuKey{row.Sku, row.IntegrationID}
+	}), nil
+}
+
+func (m *Memory) GetMerchantAccountSalesBySKU(ctx context.Context, arg core.GetMerchantAccountSalesBySKUParams) ([]core.GetMerchantAccountSalesBySKURow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := map[skuKey]*core.GetMerchantAccountSalesBySKURow{}
+	orders := map[skuKey]map[id.ID[id.Order]]bool{}
+	for key, line := range m.tables.orderLineItems {
+		order := m.tables.orders[key.orderID]
+		if order.CreatedAt.Time.Before(arg.CreatedAt.Time) || order.CancelledAt.Valid || order.FinancialStatus == core.FinancialStatusVoided {
+			continue
+		}
+		integration, ok := m.accountIntegration(arg.AccountID, order.IntegrationID)
+		if !ok {
+			continue
+		}
+		variant, ok := m.tables.productVariants[line.VariantID]
+		if !ok || variant.Sku.String == "" {
+			continue
+		}
+
+		key := skuKey{variant.Sku.String, integration.ID}
+		row, ok := rows[key]
+		if !ok {
+			row = &core.GetMerchantAccountSalesBySKURow{Sku: key.sku, IntegrationID: integration.ID, PlatformType: integration.PlatformType, Currency: integration.Currency}
+			rows[key], orders[key] = row, map[id.ID[id.Order]]bool{}
+		}
+		row.UnitsSold += int64(line.Quantity)
+		row.Revenue += float64(line.Quantity) * numericFloat(line.Price)
+		orders[key][order.ID] = true
+		row.OrdersCount = int64(len(orders[key]))
+	}
+
+	return sortedSKURows(rows, func(row *core.GetMerchantAccountSalesBySKURow) skuKey {
+		return skuKey{row.Sku, row.IntegrationID}
+	}), nil
+}
+
+func (m *Memory) GetMerchantAccountShops(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]core.GetMerchantAccountShopsRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.GetMerchantAccountShopsRow{}
+	for _, shop := range m.tables.merchantAccountShops {
+		if shop.AccountID != accountID {
+			continue
+		}
+		store := m.tables.shopifyStores[shop.ShopID]
+		items = append(items, core.GetMerchantAccountShopsRow{ID: store.ID, ShopDomain: store.ShopDomain, ShopName: store.ShopName, LinkedAt: shop.CreatedAt})
+	}
+	slices.SortFunc(items, func(a, b core.GetMerchantAccountShopsRow) int {
+		return cmp.Or(compareTimestamps(a.LinkedAt, b.LinkedAt), strings.Compare(a.ShopDomain, b.ShopDomain))
+	})
+	return items, nil
+}
+
+func (m *Memory) GetMerchantAccountsByUserID(ctx context.Context, userID id.ID[id.User]) ([]core.MerchantAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.MerchantAccount{}
+	for key := range m.tables.merchantAccountUsers {
+		if key.userID == userID {
+			items = append(items, m.tables.merchantAccounts[key.accountID])
+		}
+	}
+	slices.SortFunc(items, func(a, b core.MerchantAccount) int {
+		return compareTimestamps(a.CreatedAt, b.CreatedAt)
+	})
+	return items, nil
+}
+
+func (m *Memory) GetPlatformIntegrationsByAccountID(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.PlatformIntegration{}
+	for _, integration := range m.tables.integrations {
+		if _, ok := m.accountIntegration(accountID, integration.ID); ok && integration.IsActive.Bool {
+			items = append(items, integration)
+		}
+	}
+	slices.SortFunc(items, func(a, b core.PlatformIntegration) int {
+		return compareTimestamps(a.CreatedAt, b.CreatedAt)
+	})
+	return items, nil
+}
+
+func (m *Memory) IsMerchantAccountUser(ctx context.Context, arg core.IsMerchantAccountUserParams) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.tables.merchantAccountUsers[accountUserKey{arg.AccountID, arg.UserID}]
+	return ok, nil
+}
+
+func (m *Memory) SetMerchantAccountReportingCurrency(ctx context.Context, arg core.SetMerchantAccountReportingCurrencyParams) (core.MerchantAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, ok := m.tables.merchantAccounts[arg.ID]
+	if !ok {
+		return core.MerchantAccount{}, pgx.ErrNoRows
+	}
+	account.ReportingCurrency = arg.ReportingCurrency
+	account.UpdatedAt = now()
+	m.tables.merchantAccounts[arg.ID] = account
+	return account, nil
+}
+
+func (m *Memory) SetMerchantAccountShop(ctx context.Context, arg core.SetMerchantAccountShopParams) (core.MerchantAccountShop, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tables.merchantAccounts[arg.AccountID]; !ok {
+		return core.MerchantAccountShop{}, errors.Errorf("insert or update on table \"merchant_account_shops\" violates foreign key constraint on account_id: %s", arg.AccountID)
+	}
+	if _, ok := m.tables.shopifyStores[arg.ShopID]; !ok {
+		return core.MerchantAccountShop{}, errors.Errorf("insert or update on table \"merchant_account_shops\" violates foreign key constraint on shop_id: %s", arg.ShopID)
+	}
+
+	shop, ok := m.tables.merchantAccountShops[arg.ShopID]
+	if !ok {
+		shop = core.MerchantAccountShop{ShopID: arg.ShopID, CreatedAt: now()}
+	}
+	shop.AccountID = arg.AccountID
+	m.tables.merchantAccountShops[arg.ShopID] = shop
+	return shop, nil
+}
+
+// accountIntegration gets an integration of the account, m.mu must be held
+func (m *Memory) accountIntegration(accountID id.ID[id.MerchantAccount], integrationID id.ID[id.PlatformIntegration]) (core.PlatformIntegration, bool) {
+	integration, ok := m.tables.integrations[integrationID]
+	return integration, ok && integration.AccountID == accountID
+}
+
+// variantStock sums the stock of a variant's inventory item at locations that are not deleted
+// and the value at cost of the units in stock, m.mu must be held
+func (m *Memory) variantStock(integrationID id.ID[id.PlatformIntegration], variant core.ProductVariant) (int64, float64) {
+	var available int64
+	var value float64
+	for _, item := range m.tables.inventoryItems {
+		if item.IntegrationID != integrationID || item.ExternalID != variant.InventoryItemID || item.DeletedAt.Valid {
+			continue
+		}
+		for key, level := range m.tables.inventoryLevels {
+			if location, ok := m.tables.locations[key.locationID]; key.inventoryItemID == item.ID && ok && !location.DeletedAt.Valid {
+				available += int64(level.Available.Int32)
+				if level.Available.Int32 > 0 {
+					value += float64(level.Available.Int32) * numericFloat(item.Cost)
+				}
+			}
+		}
+	}
+	return available, value
+}
+
+// numericFloat returns a numeric column as a float8 cast does, zero for NULL
+func numericFloat(n pgtype.Numeric) float64 {
+	f, err := n.Float64Value()
+	if err != nil {
+		return 0
+	}
+	return f.Float64
+}
+
+// sortedSKURows returns the rows of an account view in the order of the query
+func sortedSKURows[T any](rows map[skuKey]*T, key func(*T) skuKey) []T {
+	items := make([]T, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, *row)
+	}
+	slices.SortFunc(items, func(a, b T) int {
+		return compareSKUKeys(key(&a), key(&b))
+	})
+	return items
+}
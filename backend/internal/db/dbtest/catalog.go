@@ -0,0 +1,472 @@
+package dbtest
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// The catalog and order queries: upserts and lookups by external ID. External IDs of locations,
+// variants, inventory items and orders are unique across integrations, like the table constraints.
+
+// levelKey identifies the inventory level of an item at a location
+type levelKey struct {
+	inventoryItemID id.ID[id.InventoryItem]
+	locationID      id.ID[id.Location]
+}
+
+// lineItemKey identifies a line item of an order
+type lineItemKey struct {
+	orderID    id.ID[id.Order]
+	externalID string
+}
+
+func (m *Memory) GetInventoryItemByExternalID(ctx context.Context, arg core.GetInventoryItemByExternalIDParams) (core.InventoryItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, item := range m.tables.inventoryItems {
+		if item.IntegrationID == arg.IntegrationID && item.ExternalID == arg.ExternalID && !item.DeletedAt.Valid {
+			return item, nil
+		}
+	}
+	return core.InventoryItem{}, pgx.ErrNoRows
+}
+
+func (m *Memory) GetInventoryItemsByIntegrationID(ctx context.Context, arg core.GetInventoryItemsByIntegrationIDParams) ([]core.InventoryItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var items []core.InventoryItem
+	for _, item := range m.tables.inventoryItems {
+		if item.IntegrationID == arg.IntegrationID && !item.DeletedAt.Valid {
+			items = append(items, item)
+		}
+	}
+	return newestPage(items, func(item core.InventoryItem) pgtype.Timestamp { return item.CreatedAt }, arg.Limit, arg.Offset), nil
+}
+
+func (m *Memory) GetInventoryLevelsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]core.GetInventoryLevelsByIntegrationIDRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.GetInventoryLevelsByIntegrationIDRow{}
+	for key, level := range m.tables.inventoryLevels {
+		item, ok := m.tables.inventoryItems[key.inventoryItemID]
+		if !ok || item.IntegrationID != integrationID || item.DeletedAt.Valid {
+			continue
+		}
+		location, ok := m.tables.locations[key.locationID]
+		if !ok || location.DeletedAt.Valid {
+			continue
+		}
+		items = append(items, core.GetInventoryLevelsByIntegrationIDRow{
+			InventoryItemExternalID: item.ExternalID,
+			LocationExternalID:      location.ExternalID,
+			Available:               level.Available,
+		})
+	}
+	return items, nil
+}
+
+func (m *Memory) GetInventoryLevelsByInventoryItemID(ctx context.Context, inventoryItemID id.ID[id.InventoryItem]) ([]core.InventoryLevel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.InventoryLevel{}
+	for key, level := range m.tables.inventoryLevels {
+		if key.inventoryItemID == inventoryItemID {
+			items = append(items, level)
+		}
+	}
+	slices.SortFunc(items, func(a, b core.InventoryLevel) int {
+		return strings.Compare(a.LocationID.String(), b.LocationID.String())
+	})
+	return items, nil
+}
+
+func (m *Memory) GetLocationByExternalID(ctx context.Context, arg core.GetLocationByExternalIDParams) (core.Location, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, location := range m.tables.locations {
+		if location.IntegrationID == arg.IntegrationID && location.ExternalID == arg.ExternalID && !location.DeletedAt.Valid {
+			return location, nil
+		}
+	}
+	return core.Location{}, pgx.ErrNoRows
+}
+
+func (m *Memory) GetAllLocationsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]core.Location, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var locations []core.Location
+	for _, location := range m.tables.locations {
+		if location.IntegrationID == integrationID && !location.DeletedAt.Valid {
+			locations = append(locations, location)
+		}
+	}
+	return newestPage(locations, func(location core.Location) pgtype.Timestamp { return location.CreatedAt }, int32(len(locations)), 0), nil
+}
+
+func (m *Memory) GetLocationsByIntegrationID(ctx context.Context, arg core.GetLocationsByIntegrationIDParams) ([]core.Location, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var locations []core.Location
+	for _, location := range m.tables.locations {
+		if location.IntegrationID == arg.IntegrationID && location.IsActive.Bool && !location.DeletedAt.Valid {
+			locations = append(locations, location)
+		}
+	}
+	return newestPage(locations, func(location core.Location) pgtype.Timestamp { return location.CreatedAt }, arg.Limit, arg.Offset), nil
+}
+
+func (m *Memory) GetOrderByExternalID(ctx context.Context, arg core.GetOrderByExternalIDParams) (core.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, order := range m.tables.orders {
+		if order.IntegrationID == arg.IntegrationID && order.ExternalID == arg.ExternalID {
+			return order, nil
+		}
+	}
+	return core.Order{}, pgx.ErrNoRows
+}
+
+func (m *Memory) GetOrderLineItemsByOrderID(ctx context.Context, orderID id.ID[id.Order]) ([]core.OrderLineItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.OrderLineItem{}
+	for key, item := range m.tables.orderLineItems {
+		if key.orderID == orderID {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a, b core.OrderLineItem) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return items, nil
+}
+
+func (m *Memory) GetProductByExternalID(ctx context.Context, arg core.GetProductByExternalIDParams) (core.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, product := range m.tables.products {
+		if product.IntegrationID == arg.IntegrationID && product.ExternalID == arg.ExternalID && !product.DeletedAt.Valid {
+			return product, nil
+		}
+	}
+	return core.Product{}, pgx.ErrNoRows
+}
+
+func (m *Memory) GetProductVariantByExternalID(ctx context.Context, arg core.GetProductVariantByExternalIDParams) (core.ProductVariant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, variant := range m.tables.productVariants {
+		product, ok := m.tables.products[variant.ProductID]
+		if ok && product.IntegrationID == arg.IntegrationID && variant.ExternalID == arg.ExternalID && !variant.DeletedAt.Valid {
+			return variant, nil
+		}
+	}
+	return core.ProductVariant{}, pgx.ErrNoRows
+}
+
+func (m *Memory) GetProductVariantsByIntegrationID(ctx context.Context, arg core.GetProductVariantsByIntegrationIDParams) ([]core.ProductVariant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var variants []core.ProductVariant
+	for _, variant := range m.tables.productVariants {
+		if m.tables.products[variant.ProductID].IntegrationID == arg.IntegrationID && !variant.DeletedAt.Valid {
+			variants = append(variants, variant)
+		}
+	}
+	return newestPage(variants, func(variant core.ProductVariant) pgtype.Timestamp { return variant.CreatedAt }, arg.Limit, arg.Offset), nil
+}
+
+func (m *Memory) GetProductsByIntegrationID(ctx context.Context, arg core.GetProductsByIntegrationIDParams) ([]core.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var products []core.Product
+	for _, product := range m.tables.products {
+		if product.IntegrationID == arg.IntegrationID && !product.DeletedAt.Valid {
+			products = append(products, product)
+		}
+	}
+	return newestPage(products, func(product core.Product) pgtype.Timestamp { return product.CreatedAt }, arg.Limit, arg.Offset), nil
+}
+
+func (m *Memory) GetProductsByTags(ctx context.Context, arg core.GetProductsByTagsParams) ([]core.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var products []core.Product
+	for _, product := range m.tables.products {
+		if product.IntegrationID != arg.IntegrationID || product.DeletedAt.Valid {
+			continue
+		}
+		hasTags := true
+		for _, tag := range arg.Tags {
+			hasTags = hasTags && slices.Contains(product.Tags, tag)
+		}
+		if hasTags {
+			products = append(products, product)
+		}
+	}
+	slices.SortFunc(products, func(a, b core.Product) int {
+		if c := strings.Compare(a.Title, b.Title); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return products, nil
+}
+
+func (m *Memory) SoftDeleteInventoryItemsNotSyncedSince(ctx context.Context, arg core.SoftDeleteInventoryItemsNotSyncedSinceParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for itemID, item := range m.tables.inventoryItems {
+		if item.IntegrationID != arg.IntegrationID || item.DeletedAt.Valid || !item.UpdatedAt.Time.Before(arg.UpdatedAt.Time) {
+			continue
+		}
+		item.DeletedAt = now()
+		m.tables.inventoryItems[itemID] = item
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (m *Memory) SoftDeleteLocationsNotSyncedSince(ctx context.Context, arg core.SoftDeleteLocationsNotSyncedSinceParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for locationID, location := range m.tables.locations {
+		if location.IntegrationID != arg.IntegrationID || location.DeletedAt.Valid || !location.UpdatedAt.Time.Before(arg.UpdatedAt.Time) {
+			continue
+		}
+		location.DeletedAt = now()
+		m.tables.locations[locationID] = location
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (m *Memory) SoftDeleteProductsNotSyncedSince(ctx context.Context, arg core.SoftDeleteProductsNotSyncedSinceParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for productID, product := range m.tables.products {
+		if product.IntegrationID != arg.IntegrationID || product.DeletedAt.Valid || !product.UpdatedAt.Time.Before(arg.UpdatedAt.Time) {
+			continue
+		}
+		product.DeletedAt = now()
+		m.tables.products[productID] = product
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (m *Memory) SoftDeleteProductVariantsNotSyncedSince(ctx context.Context, arg core.SoftDeleteProductVariantsNotSyncedSinceParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for variantID, variant := range m.tables.productVariants {
+		if variant.IntegrationID != arg.IntegrationID || variant.DeletedAt.Valid || !variant.UpdatedAt.Time.Before(arg.UpdatedAt.Time) {
+			continue
+		}
+		variant.DeletedAt = now()
+		m.tables.productVariants[variantID] = variant
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (m *Memory) UpsertInventoryItem(ctx context.Context, arg core.UpsertInventoryItemParams) (core.InventoryItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := findByExternalID(m.tables.inventoryItems, arg.IntegrationID, arg.ExternalID, func(item core.InventoryItem) (id.ID[id.PlatformIntegration], pgtype.Text) {
+		return item.IntegrationID, item.ExternalID
+	})
+	if !ok {
+		item = core.InventoryItem{ID: arg.ID, IntegrationID: arg.IntegrationID, ExternalID: arg.ExternalID, CreatedAt: now()}
+	}
+	item.Sku = arg.Sku
+	item.Tracked = arg.Tracked
+	item.Cost = arg.Cost
+	item.DeletedAt = pgtype.Timestamp{}
+	item.UpdatedAt = now()
+	m.tables.inventoryItems[item.ID] = item
+	return item, nil
+}
+
+func (m *Memory) UpsertInventoryLevel(ctx context.Context, arg core.UpsertInventoryLevelParams) (core.InventoryLevel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := levelKey{arg.InventoryItemID, arg.LocationID}
+	level, ok := m.tables.inventoryLevels[key]
+	if !ok {
+		level = core.InventoryLevel{ID: arg.ID, InventoryItemID: arg.InventoryItemID, LocationID: arg.LocationID}
+	}
+	level.Available = arg.Available
+	level.UpdatedAt = now()
+	m.tables.inventoryLevels[key] = level
+	return level, nil
+}
+
+func (m *Memory) UpsertLocation(ctx context.Context, arg core.UpsertLocationParams) (core.Location, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	location, ok := findByExternalID(m.tables.locations, arg.IntegrationID, arg.ExternalID, func(location core.Location) (id.ID[id.PlatformIntegration], pgtype.Text) {
+		return location.IntegrationID, location.ExternalID
+	})
+	if !ok {
+		location = core.Location{ID: arg.ID, IntegrationID: arg.IntegrationID, ExternalID: arg.ExternalID, CreatedAt: now()}
+	}
+	location.Name = arg.Name
+	location.Address = arg.Address
+	location.Country = arg.Country
+	location.Province = arg.Province
+	location.IsActive = arg.IsActive
+	location.DeletedAt = pgtype.Timestamp{}
+	location.UpdatedAt = now()
+	m.tables.locations[location.ID] = location
+	return location, nil
+}
+
+func (m *Memory) UpsertOrder(ctx context.Context, arg core.UpsertOrderParams) (core.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := findByExternalID(m.tables.orders, arg.IntegrationID, arg.ExternalID, func(order core.Order) (id.ID[id.PlatformIntegration], pgtype.Text) {
+		return order.IntegrationID, order.ExternalID
+	})
+	if !ok {
+		order = core.Order{ID: arg.ID, IntegrationID: arg.IntegrationID, ExternalID: arg.ExternalID, CreatedAt: arg.CreatedAt}
+	}
+	order.FinancialStatus = arg.FinancialStatus
+	order.FulfillmentStatus = arg.FulfillmentStatus
+	order.TotalPrice = arg.TotalPrice
+	order.CancelledAt = arg.CancelledAt
+	order.PresentmentCurrency = arg.PresentmentCurrency
+	order.PresentmentTotalPrice = arg.PresentmentTotalPrice
+	m.tables.orders[order.ID] = order
+	return order, nil
+}
+
+func (m *Memory) UpsertOrderLineItem(ctx context.Context, arg core.UpsertOrderLineItemParams) (core.OrderLineItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tables.orders[arg.OrderID]; !ok {
+		return core.OrderLineItem{}, errors.Errorf("insert or update on table \"order_line_items\" violates foreign key constraint on order_id: %s", arg.OrderID)
+	}
+
+	key := lineItemKey{arg.OrderID, arg.ExternalID.String}
+	item, ok := m.tables.orderLineItems[key]
+	if !ok {
+		item = core.OrderLineItem{ID: arg.ID, OrderID: arg.OrderID, ExternalID: arg.ExternalID}
+	}
+	item.ProductID = arg.ProductID
+	item.VariantID = arg.VariantID
+	item.InventoryItemID = arg.InventoryItemID
+	item.Quantity = arg.Quantity
+	item.Price = arg.Price
+	m.tables.orderLineItems[key] = item
+	return item, nil
+}
+
+func (m *Memory) UpsertProduct(ctx context.Context, arg core.UpsertProductParams) (core.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var product core.Product
+	found := false
+	for _, existing := range m.tables.products {
+		if existing.IntegrationID == arg.IntegrationID && existing.Handle == arg.Handle {
+			product, found = existing, true
+			break
+		}
+	}
+	if !found {
+		product = core.Product{ID: arg.ID, IntegrationID: arg.IntegrationID, Handle: arg.Handle, CreatedAt: now()}
+	}
+	product.ExternalID = arg.ExternalID
+	product.Title = arg.Title
+	product.ProductType = arg.ProductType
+	product.Status = arg.Status
+	product.Vendor = arg.Vendor
+	product.Tags = arg.Tags
+	product.OptionNames = arg.OptionNames
+	product.PublishedAt = arg.PublishedAt
+	product.DeletedAt = pgtype.Timestamp{}
+	product.UpdatedAt = now()
+	m.tables.products[product.ID] = product
+	return product, nil
+}
+
+func (m *Memory) UpsertProductVariant(ctx context.Context, arg core.UpsertProductVariantParams) (core.ProductVariant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	variant, ok := findByExternalID(m.tables.productVariants, arg.IntegrationID, arg.ExternalID, func(variant core.ProductVariant) (id.ID[id.PlatformIntegration], pgtype.Text) {
+		return variant.IntegrationID, variant.ExternalID
+	})
+	if !ok {
+		if _, ok := m.tables.products[arg.ProductID]; !ok {
+			return core.ProductVariant{}, errors.Errorf("insert or update on table \"product_variants\" violates foreign key constraint on product_id: %s", arg.ProductID)
+		}
+		variant = core.ProductVariant{ID: arg.ID, ProductID: arg.ProductID, IntegrationID: arg.IntegrationID, ExternalID: arg.ExternalID, CreatedAt: now()}
+	}
+	variant.Sku = arg.Sku
+	variant.Price = arg.Price
+	variant.InventoryItemID = arg.InventoryItemID
+	variant.Barcode = arg.Barcode
+	variant.OptionValues = arg.OptionValues
+	variant.CompareAtPrice = arg.CompareAtPrice
+	variant.Weight = arg.Weight
+	variant.WeightUnit = arg.WeightUnit
+	variant.DeletedAt = pgtype.Timestamp{}
+	variant.UpdatedAt = now()
+	m.tables.productVariants[variant.ID] = variant
+	return variant, nil
+}
+
+// newestPage orders rows by created_at DESC and applies LIMIT and OFFSET
+func newestPage[V any](rows []V, createdAt func(V) pgtype.Timestamp, limit, offset int32) []V {
+	slices.SortStableFunc(rows, func(a, b V) int {
+		return compareTimestamps(createdAt(b), createdAt(a))
+	})
+	rows = rows[min(int(offset), len(rows)):]
+	return append([]V{}, rows[:min(int(limit), len(rows))]...)
+}
+
+// findByExternalID finds the row of the integration with the external ID, which is unique within an integration
+func findByExternalID[K comparable, V any](rows map[K]V, integrationID id.ID[id.PlatformIntegration], externalID pgtype.Text, get func(V) (id.ID[id.PlatformIntegration], pgtype.Text)) (V, bool) {
+	for _, row := range rows {
+		if rowIntegrationID, rowExternalID := get(row); rowIntegrationID == integrationID && rowExternalID == externalID {
+			return row, true
+		}
+	}
+	var zero V
+	return zero, false
+}
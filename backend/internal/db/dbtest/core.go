@@ -0,0 +1,726 @@
+package dbtest
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+var _ core.Querier = (*Memory)(nil)
+
+// Column default of platform_integrations.sync_interval_minutes, scheduled syncs are opt-in
+const defaultSyncIntervalMinutes = 0
+
+// compareTimestamps orders timestamps ascending with NULLs last, as Postgres does
+func compareTimestamps(a, b pgtype.Timestamp) int {
+	switch {
+	case !a.Valid && !b.Valid:
+		return 0
+	case !a.Valid:
+		return 1
+	case !b.Valid:
+		return -1
+	default:
+		return a.Time.Compare(b.Time)
+	}
+}
+
+// integrationByPlatformShop finds an integration by its unique (platform shop, type) pair, m.mu must be held
+func (m *Memory) integrationByPlatformShop(platformShopID string, platformType core.PlatformType) (core.PlatformIntegration, bool) {
+	for _, integration := range m.tables.integrations {
+		if integration.PlatformShopID == platformShopID && integration.PlatformType == platformType {
+			return integration, true
+		}
+	}
+	return core.PlatformIntegration{}, false
+}
+
+func (m *Memory) CancelInProgressSyncStates(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]core.SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.SyncState{}
+	for key, state := range m.tables.syncStates {
+		if state.IntegrationID != integrationID || state.SyncStatus != core.SyncStatusInProgress {
+			continue
+		}
+		state.SyncStatus = core.SyncStatusCancelled
+		state.ErrorMessage = pgtype.Text{}
+		m.tables.syncStates[key] = state
+		items = append(items, state)
+	}
+	return items, nil
+}
+
+func (m *Memory) ClearBigCommerceClaimToken(ctx context.Context, argID id.ID[id.BigCommerceInstallation]) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for storeHash, installation := range m.tables.bigCommerceInstalls {
+		if installation.ID == argID {
+			installation.ClaimTokenHash = pgtype.Text{}
+			installation.ClaimExpiresAt = pgtype.Timestamp{}
+			installation.UpdatedAt = now()
+			m.tables.bigCommerceInstalls[storeHash] = installation
+		}
+	}
+	return nil
+}
+
+func (m *Memory) CreatePlatformIntegration(ctx context.Context, arg core.CreatePlatformIntegrationParams) (core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.integrationByPlatformShop(arg.PlatformShopID, arg.PlatformType); ok {
+		return core.PlatformIntegration{}, errors.Errorf("duplicate key value violates unique constraint on (platform_shop_id, platform_type): %s, %s", arg.PlatformShopID, arg.PlatformType)
+	}
+
+	integration := core.PlatformIntegration{
+		ID:                  arg.ID,
+		ShopID:              arg.ShopID,
+		AccountID:           arg.AccountID,
+		PlatformType:        arg.PlatformType,
+		PlatformShopID:      arg.PlatformShopID,
+		IsActive:            arg.IsActive,
+		CreatedAt:           now(),
+		UpdatedAt:           now(),
+		SyncIntervalMinutes: defaultSyncIntervalMinutes,
+	}
+	m.tables.integrations[integration.ID] = integration
+	return integration, nil
+}
+
+func (m *Memory) CreateSyncRun(ctx context.Context, arg core.CreateSyncRunParams) (core.SyncRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run := core.SyncRun{
+		ID:            arg.ID,
+		IntegrationID: arg.IntegrationID,
+		EntityType:    arg.EntityType,
+		Trigger:       arg.Trigger,
+		SyncStatus:    arg.SyncStatus,
+		StartedAt:     now(),
+		PhaseTimings:  []byte("{}"),
+		Stats:         []byte("{}"),
+		TaskID:        arg.TaskID,
+		DryRun:        arg.DryRun,
+	}
+	m.tables.syncRuns[run.ID] = run
+	return run, nil
+}
+
+func (m *Memory) CreateSyncState(ctx context.Context, arg core.CreateSyncStateParams) (core.SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := syncKey{arg.IntegrationID, arg.EntityType}
+	if _, ok := m.tables.syncStates[key]; ok {
+		return core.SyncState{}, errors.Errorf("duplicate key value violates unique constraint on (integration_id, entity_type): %s, %s", arg.IntegrationID, arg.EntityType)
+	}
+
+	state := core.SyncState{
+		ID:            arg.ID,
+		IntegrationID: arg.IntegrationID,
+		EntityType:    arg.EntityType,
+		LastSyncedAt:  arg.LastSyncedAt,
+		SyncStatus:    arg.SyncStatus,
+		ErrorMessage:  arg.ErrorMessage,
+		HeartbeatAt:   now(),
+	}
+	m.tables.syncStates[key] = state
+	return state, nil
+}
+
+func (m *Memory) DeleteBigCommerceInstallation(ctx context.Context, storeHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.tables.bigCommerceInstalls, storeHash)
+	return nil
+}
+
+func (m *Memory) DeactivatePlatformIntegration(ctx context.Context, argID id.ID[id.PlatformIntegration]) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if integration, ok := m.tables.integrations[argID]; ok {
+		integration.IsActive = pgtype.Bool{Bool: false, Valid: true}
+		integration.UpdatedAt = now()
+		m.tables.integrations[argID] = integration
+	}
+	return nil
+}
+
+func (m *Memory) DeleteSyncCheckpointsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.tables.checkpoints {
+		if key.integrationID == integrationID {
+			delete(m.tables.checkpoints, key)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) DeleteSyncState(ctx context.Context, arg core.DeleteSyncStateParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.tables.syncStates, syncKey{arg.IntegrationID, arg.EntityType})
+	return nil
+}
+
+func (m *Memory) FailInProgressSyncState(ctx context.Context, arg core.FailInProgressSyncStateParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := syncKey{arg.IntegrationID, arg.EntityType}
+	state, ok := m.tables.syncStates[key]
+	if !ok || state.SyncStatus != core.SyncStatusInProgress {
+		return 0, nil
+	}
+	state.SyncStatus = core.SyncStatusFailed
+	state.ErrorMessage = arg.ErrorMessage
+	m.tables.syncStates[key] = state
+	return 1, nil
+}
+
+func (m *Memory) FailStaleSyncStates(ctx context.Context, arg core.FailStaleSyncStatesParams) ([]core.SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.SyncState{}
+	for key, state := range m.tables.syncStates {
+		if state.SyncStatus != core.SyncStatusInProgress || !state.HeartbeatAt.Time.Before(arg.HeartbeatAt.Time) {
+			continue
+		}
+		state.SyncStatus = core.SyncStatusFailed
+		state.ErrorMessage = arg.ErrorMessage
+		m.tables.syncStates[key] = state
+		items = append(items, state)
+	}
+	return items, nil
+}
+
+func (m *Memory) FailUnfinishedSyncRuns(ctx context.Context, arg core.FailUnfinishedSyncRunsParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var failed int64
+	for runID, run := range m.tables.syncRuns {
+		if run.IntegrationID != arg.IntegrationID || run.EntityType != arg.EntityType || run.FinishedAt.Valid || run.DryRun {
+			continue
+		}
+		run.SyncStatus = core.SyncStatusFailed
+		run.FinishedAt = now()
+		run.ErrorMessage = arg.ErrorMessage
+		m.tables.syncRuns[runID] = run
+		failed++
+	}
+	return failed, nil
+}
+
+func (m *Memory) FinishSyncDryRun(ctx context.Context, arg core.FinishSyncDryRunParams) (core.SyncRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.tables.syncRuns[arg.ID]
+	if !ok || !run.DryRun {
+		return core.SyncRun{}, pgx.ErrNoRows
+	}
+	run.SyncStatus = arg.SyncStatus
+	run.FinishedAt = now()
+	run.Diff = arg.Diff
+	run.ErrorMessage = arg.ErrorMessage
+	m.tables.syncRuns[arg.ID] = run
+	return run, nil
+}
+
+func (m *Memory) FinishSyncRun(ctx context.Context, arg core.FinishSyncRunParams) (core.SyncRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.tables.syncRuns[arg.ID]
+	if !ok {
+		return core.SyncRun{}, pgx.ErrNoRows
+	}
+	run.SyncStatus = arg.SyncStatus
+	run.FinishedAt = now()
+	run.PhaseTimings = arg.PhaseTimings
+	run.Stats = arg.Stats
+	run.ErrorMessage = arg.ErrorMessage
+	m.tables.syncRuns[arg.ID] = run
+	return run, nil
+}
+
+func (m *Memory) GetBigCommerceInstallationByClaimToken(ctx context.Context, claimTokenHash pgtype.Text) (core.BigcommerceInstallation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, installation := range m.tables.bigCommerceInstalls {
+		if installation.ClaimTokenHash.Valid && installation.ClaimTokenHash == claimTokenHash && installation.ClaimExpiresAt.Time.After(now().Time) {
+			return installation, nil
+		}
+	}
+	return core.BigcommerceInstallation{}, pgx.ErrNoRows
+}
+
+func (m *Memory) GetBigCommerceInstallationByStoreHash(ctx context.Context, storeHash string) (core.BigcommerceInstallation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	installation, ok := m.tables.bigCommerceInstalls[storeHash]
+	if !ok {
+		return core.BigcommerceInstallation{}, pgx.ErrNoRows
+	}
+	return installation, nil
+}
+
+func (m *Memory) GetInProgressSyncStates(ctx context.Context) ([]core.SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.SyncState{}
+	for _, state := range m.tables.syncStates {
+		if state.SyncStatus == core.SyncStatusInProgress {
+			items = append(items, state)
+		}
+	}
+	return items, nil
+}
+
+func (m *Memory) GetMagentoCredentialsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (core.MagentoCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	credentials, ok := m.tables.magentoCredentials[integrationID]
+	if !ok {
+		return core.MagentoCredential{}, pgx.ErrNoRows
+	}
+	return credentials, nil
+}
+
+func (m *Memory) GetPlatformIntegrationByID(ctx context.Context, argID id.ID[id.PlatformIntegration]) (core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	integration, ok := m.tables.integrations[argID]
+	if !ok {
+		return core.PlatformIntegration{}, pgx.ErrNoRows
+	}
+	return integration, nil
+}
+
+func (m *Memory) GetPlatformIntegrationByPlatformShop(ctx context.Context, arg core.GetPlatformIntegrationByPlatformShopParams) (core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	integration, ok := m.integrationByPlatformShop(arg.PlatformShopID, arg.PlatformType)
+	if !ok {
+		return core.PlatformIntegration{}, pgx.ErrNoRows
+	}
+	return integration, nil
+}
+
+func (m *Memory) GetPlatformIntegrationByShopAndType(ctx context.Context, arg core.GetPlatformIntegrationByShopAndTypeParams) (core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, integration := range m.tables.integrations {
+		if integration.ShopID == arg.ShopID && integration.PlatformType == arg.PlatformType && integration.IsActive.Bool {
+			return integration, nil
+		}
+	}
+	return core.PlatformIntegration{}, pgx.ErrNoRows
+}
+
+func (m *Memory) GetPlatformIntegrationsByShopID(ctx context.Context, shopID id.ID[id.ShopifyStore]) ([]core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.PlatformIntegration{}
+	for _, integration := range m.tables.integrations {
+		if integration.ShopID == shopID && integration.IsActive.Bool {
+			items = append(items, integration)
+		}
+	}
+	slices.SortFunc(items, func(a, b core.PlatformIntegration) int {
+		return compareTimestamps(b.CreatedAt, a.CreatedAt)
+	})
+	return items, nil
+}
+
+func (m *Memory) GetScheduledPlatformIntegrations(ctx context.Context) ([]core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.PlatformIntegration{}
+	for _, integration := range m.tables.integrations {
+		if integration.IsActive.Bool && integration.SyncIntervalMinutes > 0 && integration.PlatformType != core.PlatformTypeFileImport {
+			items = append(items, integration)
+		}
+	}
+	slices.SortFunc(items, func(a, b core.PlatformIntegration) int {
+		return compareTimestamps(a.CreatedAt, b.CreatedAt)
+	})
+	return items, nil
+}
+
+func (m *Memory) GetSquareCredentialsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (core.SquareCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	credentials, ok := m.tables.squareCredentials[integrationID]
+	if !ok {
+		return core.SquareCredential{}, pgx.ErrNoRows
+	}
+	return credentials, nil
+}
+
+func (m *Memory) GetSyncCheckpointsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]core.SyncCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.SyncCheckpoint{}
+	for key, checkpoint := range m.tables.checkpoints {
+		if key.integrationID == integrationID {
+			items = append(items, checkpoint)
+		}
+	}
+	slices.SortFunc(items, func(a, b core.SyncCheckpoint) int {
+		return compareTimestamps(a.CreatedAt, b.CreatedAt)
+	})
+	return items, nil
+}
+
+func (m *Memory) GetSyncRunsByIntegrationID(ctx context.Context, arg core.GetSyncRunsByIntegrationIDParams) ([]core.SyncRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.SyncRun{}
+	for _, run := range m.tables.syncRuns {
+		if run.IntegrationID == arg.IntegrationID {
+			items = append(items, run)
+		}
+	}
+	slices.SortFunc(items, func(a, b core.SyncRun) int {
+		return compareTimestamps(b.StartedAt, a.StartedAt)
+	})
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+func (m *Memory) GetSyncState(ctx context.Context, arg core.GetSyncStateParams) (core.SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.tables.syncStates[syncKey{arg.IntegrationID, arg.EntityType}]
+	if !ok {
+		return core.SyncState{}, pgx.ErrNoRows
+	}
+	return state, nil
+}
+
+func (m *Memory) GetSyncStatesByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]core.SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.SyncState{}
+	for key, state := range m.tables.syncStates {
+		if key.integrationID == integrationID {
+			items = append(items, state)
+		}
+	}
+	// ORDER BY last_synced_at DESC puts NULLs first
+	slices.SortFunc(items, func(a, b core.SyncState) int {
+		return compareTimestamps(b.LastSyncedAt, a.LastSyncedAt)
+	})
+	return items, nil
+}
+
+func (m *Memory) GetWooCommerceCredentialsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (core.WoocommerceCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	credentials, ok := m.tables.wooCommerceCredentials[integrationID]
+	if !ok {
+		return core.WoocommerceCredential{}, pgx.ErrNoRows
+	}
+	return credentials, nil
+}
+
+func (m *Memory) IsSyncRunCancelRequested(ctx context.Context, runID id.ID[id.SyncRun]) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.tables.syncRuns[runID]
+	if !ok {
+		return false, pgx.ErrNoRows
+	}
+	return run.CancelRequestedAt.Valid, nil
+}
+
+func (m *Memory) IsSyncTaskCancelled(ctx context.Context, taskID pgtype.Text) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !taskID.Valid {
+		return false, nil
+	}
+
+	var latest *core.SyncRun
+	for _, run := range m.tables.syncRuns {
+		if run.TaskID.Valid && run.TaskID.String == taskID.String {
+			if latest == nil || compareTimestamps(run.StartedAt, latest.StartedAt) > 0 {
+				latest = &run
+			}
+		}
+	}
+	return latest != nil && latest.SyncStatus == core.SyncStatusCancelled, nil
+}
+
+func (m *Memory) RequestSyncRunsCancellation(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]core.SyncRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.SyncRun{}
+	for runID, run := range m.tables.syncRuns {
+		if run.IntegrationID != integrationID || run.FinishedAt.Valid || run.CancelRequestedAt.Valid {
+			continue
+		}
+		run.CancelRequestedAt = now()
+		m.tables.syncRuns[runID] = run
+		items = append(items, run)
+	}
+	return items, nil
+}
+
+func (m *Memory) SetPlatformIntegrationCurrency(ctx context.Context, arg core.SetPlatformIntegrationCurrencyParams) (core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	integration, ok := m.tables.integrations[arg.ID]
+	if !ok {
+		return core.PlatformIntegration{}, pgx.ErrNoRows
+	}
+	integration.Currency = arg.Currency
+	integration.UpdatedAt = now()
+	m.tables.integrations[arg.ID] = integration
+	return integration, nil
+}
+
+func (m *Memory) SetPlatformIntegrationSyncInterval(ctx context.Context, arg core.SetPlatformIntegrationSyncIntervalParams) (core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	integration, ok := m.tables.integrations[arg.ID]
+	if !ok {
+		return core.PlatformIntegration{}, pgx.ErrNoRows
+	}
+	integration.SyncIntervalMinutes = arg.SyncIntervalMinutes
+	integration.UpdatedAt = now()
+	m.tables.integrations[arg.ID] = integration
+	return integration, nil
+}
+
+func (m *Memory) SetPlatformIntegrationsAccountByShopID(ctx context.Context, arg core.SetPlatformIntegrationsAccountByShopIDParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for integrationID, integration := range m.tables.integrations {
+		if integration.ShopID == arg.ShopID {
+			integration.AccountID = arg.AccountID
+			integration.UpdatedAt = now()
+			m.tables.integrations[integrationID] = integration
+		}
+	}
+	return nil
+}
+
+func (m *Memory) TouchSyncStateHeartbeat(ctx context.Context, arg core.TouchSyncStateHeartbeatParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := syncKey{arg.IntegrationID, arg.EntityType}
+	if state, ok := m.tables.syncStates[key]; ok && state.SyncStatus == core.SyncStatusInProgress {
+		state.HeartbeatAt = now()
+		m.tables.syncStates[key] = state
+	}
+	return nil
+}
+
+func (m *Memory) UpdatePlatformIntegration(ctx context.Context, arg core.UpdatePlatformIntegrationParams) (core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	integration, ok := m.tables.integrations[arg.ID]
+	if !ok || integration.ShopID != arg.ShopID {
+		return core.PlatformIntegration{}, pgx.ErrNoRows
+	}
+	integration.IsActive = arg.IsActive
+	integration.UpdatedAt = now()
+	m.tables.integrations[arg.ID] = integration
+	return integration, nil
+}
+
+func (m *Memory) UpdateSyncState(ctx context.Context, arg core.UpdateSyncStateParams) (core.SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := syncKey{arg.IntegrationID, arg.EntityType}
+	state, ok := m.tables.syncStates[key]
+	if !ok {
+		return core.SyncState{}, pgx.ErrNoRows
+	}
+	state.LastSyncedAt = arg.LastSyncedAt
+	state.SyncStatus = arg.SyncStatus
+	state.ErrorMessage = arg.ErrorMessage
+	state.HeartbeatAt = now()
+	m.tables.syncStates[key] = state
+	return state, nil
+}
+
+func (m *Memory) UpsertBigCommerceInstallation(ctx context.Context, arg core.UpsertBigCommerceInstallationParams) (core.BigcommerceInstallation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	installation, ok := m.tables.bigCommerceInstalls[arg.StoreHash]
+	if !ok {
+		installation = core.BigcommerceInstallation{ID: arg.ID, StoreHash: arg.StoreHash, CreatedAt: now()}
+	}
+	installation.AccessToken = arg.AccessToken
+	installation.Scope = arg.Scope
+	installation.ClaimTokenHash = arg.ClaimTokenHash
+	installation.ClaimExpiresAt = arg.ClaimExpiresAt
+	installation.UpdatedAt = now()
+	m.tables.bigCommerceInstalls[arg.StoreHash] = installation
+	return installation, nil
+}
+
+func (m *Memory) UpsertMagentoCredentials(ctx context.Context, arg core.UpsertMagentoCredentialsParams) (core.MagentoCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	credentials, ok := m.tables.magentoCredentials[arg.IntegrationID]
+	if !ok {
+		credentials = core.MagentoCredential{ID: arg.ID, IntegrationID: arg.IntegrationID, CreatedAt: now()}
+	}
+	credentials.StoreUrl = arg.StoreUrl
+	credentials.AccessToken = arg.AccessToken
+	credentials.UpdatedAt = now()
+	m.tables.magentoCredentials[arg.IntegrationID] = credentials
+	return credentials, nil
+}
+
+func (m *Memory) UpsertPlatformIntegration(ctx context.Context, arg core.UpsertPlatformIntegrationParams) (core.PlatformIntegration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	integration, ok := m.integrationByPlatformShop(arg.PlatformShopID, arg.PlatformType)
+	if !ok {
+		if arg.ShopID == "" && arg.AccountID == "" {
+			return core.PlatformIntegration{}, errors.New("new row for relation \"platform_integrations\" violates check constraint \"platform_integrations_owner_check\"")
+		}
+		integration = core.PlatformIntegration{
+			ID:                  arg.ID,
+			ShopID:              arg.ShopID,
+			AccountID:           arg.AccountID,
+			PlatformType:        arg.PlatformType,
+			PlatformShopID:      arg.PlatformShopID,
+			CreatedAt:           now(),
+			SyncIntervalMinutes: defaultSyncIntervalMinutes,
+		}
+	}
+	integration.IsActive = arg.IsActive
+	integration.UpdatedAt = now()
+	m.tables.integrations[integration.ID] = integration
+	return integration, nil
+}
+
+func (m *Memory) UpsertSquareCredentials(ctx context.Context, arg core.UpsertSquareCredentialsParams) (core.SquareCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	credentials, ok := m.tables.squareCredentials[arg.IntegrationID]
+	if !ok {
+		credentials = core.SquareCredential{ID: arg.ID, IntegrationID: arg.IntegrationID, CreatedAt: now()}
+	}
+	credentials.AccessToken = arg.AccessToken
+	credentials.UpdatedAt = now()
+	m.tables.squareCredentials[arg.IntegrationID] = credentials
+	return credentials, nil
+}
+
+func (m *Memory) UpsertSyncCheckpoint(ctx context.Context, arg core.UpsertSyncCheckpointParams) (core.SyncCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := syncKey{arg.IntegrationID, arg.EntityType}
+	checkpoint, ok := m.tables.checkpoints[key]
+	if !ok {
+		checkpoint = core.SyncCheckpoint{
+			ID:            arg.ID,
+			IntegrationID: arg.IntegrationID,
+			EntityType:    arg.EntityType,
+			CreatedAt:     now(),
+		}
+	}
+	checkpoint.PageInfo = arg.PageInfo
+	checkpoint.PagesProcessed = arg.PagesProcessed
+	checkpoint.Stats = arg.Stats
+	checkpoint.Completed = arg.Completed
+	checkpoint.UpdatedAt = now()
+	m.tables.checkpoints[key] = checkpoint
+	return checkpoint, nil
+}
+
+func (m *Memory) UpsertSyncState(ctx context.Context, arg core.UpsertSyncStateParams) (core.SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := syncKey{arg.IntegrationID, arg.EntityType}
+	state, ok := m.tables.syncStates[key]
+	if !ok {
+		state = core.SyncState{ID: arg.ID, IntegrationID: arg.IntegrationID, EntityType: arg.EntityType}
+	}
+	state.LastSyncedAt = arg.LastSyncedAt
+	state.SyncStatus = arg.SyncStatus
+	state.ErrorMessage = arg.ErrorMessage
+	state.HeartbeatAt = now()
+	m.tables.syncStates[key] = state
+	return state, nil
+}
+
+func (m *Memory) UpsertWooCommerceCredentials(ctx context.Context, arg core.UpsertWooCommerceCredentialsParams) (core.WoocommerceCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	credentials, ok := m.tables.wooCommerceCredentials[arg.IntegrationID]
+	if !ok {
+		credentials = core.WoocommerceCredential{ID: arg.ID, IntegrationID: arg.IntegrationID, CreatedAt: now()}
+	}
+	credentials.StoreUrl = arg.StoreUrl
+	credentials.ConsumerKey = arg.ConsumerKey
+	credentials.ConsumerSecret = arg.ConsumerSecret
+	credentials.UpdatedAt = now()
+	m.tables.wooCommerceCredentials[arg.IntegrationID] = credentials
+	return credentials, nil
+}
+
+// SetSyncState overwrites a sync state as is, so tests can set up states such as an
+// in_progress sync whose heartbeat stopped long ago
+func (m *Memory) SetSyncState(state core.SyncState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tables.syncStates[syncKey{state.IntegrationID, state.EntityType}] = state
+}
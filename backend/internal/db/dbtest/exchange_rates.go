@@ -0,0 +1,66 @@
+package dbtest
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The exchange rates between currencies by day
+
+// exchangeRateKey identifies the rate of a currency pair on a day
+type exchangeRateKey struct {
+	base, quote string
+	date        int64
+}
+
+func (m *Memory) GetLatestExchangeRates(ctx context.Context, rateDate pgtype.Date) ([]core.GetLatestExchangeRatesRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	latest := map[[2]string]core.ExchangeRate{}
+	for _, rate := range m.tables.exchangeRates {
+		if rate.RateDate.Time.After(rateDate.Time) {
+			continue
+		}
+		pair := [2]string{rate.BaseCurrency, rate.QuoteCurrency}
+		if existing, ok := latest[pair]; !ok || rate.RateDate.Time.After(existing.RateDate.Time) {
+			latest[pair] = rate
+		}
+	}
+
+	items := make([]core.GetLatestExchangeRatesRow, 0, len(latest))
+	for _, rate := range latest {
+		items = append(items, core.GetLatestExchangeRatesRow{
+			BaseCurrency:  rate.BaseCurrency,
+			QuoteCurrency: rate.QuoteCurrency,
+			RateDate:      rate.RateDate,
+			Rate:          rate.Rate,
+			Source:        rate.Source,
+		})
+	}
+	slices.SortFunc(items, func(a, b core.GetLatestExchangeRatesRow) int {
+		return cmp.Or(strings.Compare(a.BaseCurrency, b.BaseCurrency), strings.Compare(a.QuoteCurrency, b.QuoteCurrency))
+	})
+	return items, nil
+}
+
+func (m *Memory) UpsertExchangeRate(ctx context.Context, arg core.UpsertExchangeRateParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := exchangeRateKey{arg.BaseCurrency, arg.QuoteCurrency, arg.RateDate.Time.Unix()}
+	rate, ok := m.tables.exchangeRates[key]
+	if !ok {
+		rate = core.ExchangeRate{BaseCurrency: arg.BaseCurrency, QuoteCurrency: arg.QuoteCurrency, RateDate: arg.RateDate, CreatedAt: now()}
+	}
+	rate.Rate = arg.Rate
+	rate.Source = arg.Source
+	rate.UpdatedAt = now()
+	m.tables.exchangeRates[key] = rate
+	return nil
+}
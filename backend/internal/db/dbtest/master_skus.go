@@ -0,0 +1,365 @@
+package dbtest
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// The master SKUs of merchant accounts, the links of variants to them and their rollups
+
+// masterSKUKey identifies the rows of a master SKU of an integration in the rollups
+type masterSKUKey struct {
+	masterSKUID   id.ID[id.MasterSKU]
+	integrationID id.ID[id.PlatformIntegration]
+}
+
+// compareMasterSKUKeys orders rollup rows by master SKU, then integration
+func compareMasterSKUKeys(a, b masterSKUKey) int {
+	return cmp.Or(strings.Compare(a.masterSKUID.String(), b.masterSKUID.String()), strings.Compare(a.integrationID.String(), b.integrationID.String()))
+}
+
+func (m *Memory) CreateMasterSKU(ctx context.Context, arg core.CreateMasterSKUParams) (core.MasterSku, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tables.merchantAccounts[arg.AccountID]; !ok {
+		return core.MasterSku{}, errors.Errorf("insert on table \"master_skus\" violates foreign key constraint on account_id: %s", arg.AccountID)
+	}
+	for _, master := range m.tables.masterSKUs {
+		if master.AccountID == arg.AccountID && master.Sku == arg.Sku {
+			return core.MasterSku{}, errors.New("duplicate key value violates unique constraint \"master_skus_account_id_sku_key\"")
+		}
+	}
+
+	master := core.MasterSku{
+		ID:        arg.ID,
+		AccountID: arg.AccountID,
+		Sku:       arg.Sku,
+		Title:     arg.Title,
+		Barcode:   arg.Barcode,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}
+	m.tables.masterSKUs[master.ID] = master
+	return master, nil
+}
+
+func (m *Memory) GetAccountVariantByID(ctx context.Context, arg core.GetAccountVariantByIDParams) (core.GetAccountVariantByIDRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	variant, ok := m.tables.productVariants[arg.ID]
+	if !ok || variant.DeletedAt.Valid {
+		return core.GetAccountVariantByIDRow{}, pgx.ErrNoRows
+	}
+	product, ok := m.tables.products[variant.ProductID]
+	if !ok || product.DeletedAt.Valid {
+		return core.GetAccountVariantByIDRow{}, pgx.ErrNoRows
+	}
+	integration, ok := m.accountIntegration(arg.AccountID, product.IntegrationID)
+	if !ok {
+		return core.GetAccountVariantByIDRow{}, pgx.ErrNoRows
+	}
+	return core.GetAccountVariantByIDRow{
+		ID:            variant.ID,
+		Sku:           variant.Sku,
+		Barcode:       variant.Barcode,
+		Title:         product.Title,
+		IntegrationID: integration.ID,
+		PlatformType:  integration.PlatformType,
+	}, nil
+}
+
+func (m *Memory) GetMasterSKUByID(ctx context.Context, arg core.GetMasterSKUByIDParams) (core.MasterSku, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	master, ok := m.tables.masterSKUs[arg.ID]
+	if !ok || master.AccountID != arg.AccountID {
+		return core.MasterSku{}, pgx.ErrNoRows
+	}
+	return master, nil
+}
+
+func (m *Memory) GetMasterSKUBySKU(ctx context.Context, arg core.GetMasterSKUBySKUParams) (core.MasterSku, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, master := range m.tables.masterSKUs {
+		if master.AccountID == arg.AccountID && master.Sku == arg.Sku {
+			return master, nil
+		}
+	}
+	return core.MasterSku{}, pgx.ErrNoRows
+}
+
+func (m *Memory) GetMasterSKUInventory(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]core.GetMasterSKUInventoryRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := map[masterSKUKey]*core.GetMasterSKUInventoryRow{}
+	for _, link := range m.tables.masterSKULinks {
+		master, variant, integration, ok := m.confirmedLink(accountID, link)
+		if !ok || variant.DeletedAt.Valid || !integration.IsActive.Bool {
+			continue
+		}
+		if product := m.tables.products[variant.ProductID]; product.DeletedAt.Valid {
+			continue
+		}
+
+		key := masterSKUKey{master.ID, integration.ID}
+		row, ok := rows[key]
+		if !ok {
+			row = &core.GetMasterSKUInventoryRow{MasterSkuID: master.ID, IntegrationID: integration.ID, PlatformType: integration.PlatformType, Currency: integration.Currency}
+			rows[key] = row
+		}
+		row.VariantsCount++
+		available, value := m.variantStock(integration.ID, variant)
+		row.Available += available
+		row.StockValue += value
+	}
+
+	return sortedMasterSKURows(rows, func(row *core.GetMasterSKUInventoryRow) masterSKUKey {
+		return masterSKUKey{row.MasterSkuID, row.IntegrationID}
+	}), nil
+}
+
+func (m *Memory) GetMasterSKULinkByVariantID(ctx context.Context, arg core.GetMasterSKULinkByVariantIDParams) (core.MasterSkuLink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	link, ok := m.tables.masterSKULinks[arg.VariantID]
+	if !ok || m.tables.masterSKUs[link.MasterSkuID].AccountID != arg.AccountID {
+		return core.MasterSkuLink{}, pgx.ErrNoRows
+	}
+	return link, nil
+}
+
+func (m *Memory) GetMasterSKULinks(ctx context.Context, arg core.GetMasterSKULinksParams) ([]core.GetMasterSKULinksRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type linkRow struct {
+		row         core.GetMasterSKULinksRow
+		integration core.PlatformIntegration
+	}
+	var links []linkRow
+	for _, link := range m.tables.masterSKULinks {
+		master := m.tables.masterSKUs[link.MasterSkuID]
+		variant, ok := m.tables.productVariants[link.VariantID]
+		if master.AccountID != arg.AccountID || link.Status != arg.Status || !ok || variant.DeletedAt.Valid {
+			continue
+		}
+		product := m.tables.products[variant.ProductID]
+		if product.DeletedAt.Valid {
+			continue
+		}
+		integration := m.tables.integrations[product.IntegrationID]
+		links = append(links, linkRow{
+			row: core.GetMasterSKULinksRow{
+				VariantID:      link.VariantID,
+				MasterSkuID:    link.MasterSkuID,
+				MatchType:      link.MatchType,
+				Status:         link.Status,
+				UpdatedAt:      link.UpdatedAt,
+				MasterSku:      master.Sku,
+				MasterTitle:    master.Title,
+				VariantSku:     variant.Sku,
+				VariantBarcode: variant.Barcode,
+				ProductTitle:   product.Title,
+				IntegrationID:  integration.ID,
+				PlatformType:   integration.PlatformType,
+			},
+			integration: integration,
+		})
+	}
+	slices.SortFunc(links, func(a, b linkRow) int {
+		return cmp.Or(
+			strings.Compare(a.row.MasterSku, b.row.MasterSku),
+			compareTimestamps(a.integration.CreatedAt, b.integration.CreatedAt),
+			strings.Compare(a.row.VariantID.String(), b.row.VariantID.String()),
+		)
+	})
+
+	items := make([]core.GetMasterSKULinksRow, 0, len(links))
+	for _, link := range links {
+		items = append(items, link.row)
+	}
+	return items, nil
+}
+
+func (m *Memory) GetMasterSKUSales(ctx context.Context, arg core.GetMasterSKUSalesParams) ([]core.GetMasterSKUSalesRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := map[masterSKUKey]*core.GetMasterSKUSalesRow{}
+	orders := map[masterSKUKey]map[id.ID[id.Order]]bool{}
+	for key, line := range m.tables.orderLineItems {
+		order := m.tables.orders[key.orderID]
+		if order.CreatedAt.Time.Before(arg.CreatedAt.Time) || order.CancelledAt.Valid || order.FinancialStatus == core.FinancialStatusVoided {
+			continue
+		}
+		link, ok := m.tables.masterSKULinks[line.VariantID]
+		if !ok {
+			continue
+		}
+		master, _, _, ok := m.confirmedLink(arg.AccountID, link)
+		if !ok {
+			continue
+		}
+		integration, ok := m.accountIntegration(arg.AccountID, order.IntegrationID)
+		if !ok {
+			continue
+		}
+
+		rowKey := masterSKUKey{master.ID, integration.ID}
+		row, ok := rows[rowKey]
+		if !ok {
+			row = &core.GetMasterSKUSalesRow{MasterSkuID: master.ID, IntegrationID: integration.ID, PlatformType: integration.PlatformType, Currency: integration.Currency}
+			rows[rowKey], orders[rowKey] = row, map[id.ID[id.Order]]bool{}
+		}
+		row.UnitsSold += int64(line.Quantity)
+		row.Revenue += float64(line.Quantity) * numericFloat(line.Price)
+		orders[rowKey][order.ID] = true
+		row.OrdersCount = int64(len(orders[rowKey]))
+	}
+
+	return sortedMasterSKURows(rows, func(row *core.GetMasterSKUSalesRow) masterSKUKey {
+		return masterSKUKey{row.MasterSkuID, row.IntegrationID}
+	}), nil
+}
+
+func (m *Memory) GetMasterSKUsByAccountID(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]core.MasterSku, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []core.MasterSku{}
+	for _, master := range m.tables.masterSKUs {
+		if master.AccountID == accountID {
+			items = append(items, master)
+		}
+	}
+	slices.SortFunc(items, func(a, b core.MasterSku) int {
+		return strings.Compare(a.Sku, b.Sku)
+	})
+	return items, nil
+}
+
+func (m *Memory) GetUnlinkedAccountVariants(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]core.GetUnlinkedAccountVariantsRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type variantRow struct {
+		row         core.GetUnlinkedAccountVariantsRow
+		integration core.PlatformIntegration
+	}
+	var variants []variantRow
+	for _, variant := range m.tables.productVariants {
+		if _, linked := m.tables.masterSKULinks[variant.ID]; linked || variant.DeletedAt.Valid {
+			continue
+		}
+		product, ok := m.tables.products[variant.ProductID]
+		if !ok || product.DeletedAt.Valid {
+			continue
+		}
+		integration, ok := m.accountIntegration(accountID, product.IntegrationID)
+		if !ok || !integration.IsActive.Bool {
+			continue
+		}
+		variants = append(variants, variantRow{
+			row: core.GetUnlinkedAccountVariantsRow{
+				ID:            variant.ID,
+				Sku:           variant.Sku,
+				Barcode:       variant.Barcode,
+				Title:         product.Title,
+				IntegrationID: integration.ID,
+				PlatformType:  integration.PlatformType,
+			},
+			integration: integration,
+		})
+	}
+	slices.SortFunc(variants, func(a, b variantRow) int {
+		return cmp.Or(
+			compareTimestamps(a.integration.CreatedAt, b.integration.CreatedAt),
+			strings.Compare(a.row.Sku.String, b.row.Sku.String),
+			strings.Compare(a.row.ID.String(), b.row.ID.String()),
+		)
+	})
+
+	items := make([]core.GetUnlinkedAccountVariantsRow, 0, len(variants))
+	for _, variant := range variants {
+		items = append(items, variant.row)
+	}
+	return items, nil
+}
+
+func (m *Memory) SetMasterSKULinkStatus(ctx context.Context, arg core.SetMasterSKULinkStatusParams) (core.MasterSkuLink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	link, ok := m.tables.masterSKULinks[arg.VariantID]
+	if !ok {
+		return core.MasterSkuLink{}, pgx.ErrNoRows
+	}
+	link.Status = arg.Status
+	link.UpdatedAt = now()
+	m.tables.masterSKULinks[link.VariantID] = link
+	return link, nil
+}
+
+func (m *Memory) UpsertMasterSKULink(ctx context.Context, arg core.UpsertMasterSKULinkParams) (core.MasterSkuLink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tables.productVariants[arg.VariantID]; !ok {
+		return core.MasterSkuLink{}, errors.Errorf("insert on table \"master_sku_links\" violates foreign key constraint on variant_id: %s", arg.VariantID)
+	}
+	if _, ok := m.tables.masterSKUs[arg.MasterSkuID]; !ok {
+		return core.MasterSkuLink{}, errors.Errorf("insert on table \"master_sku_links\" violates foreign key constraint on master_sku_id: %s", arg.MasterSkuID)
+	}
+
+	link, ok := m.tables.masterSKULinks[arg.VariantID]
+	if !ok {
+		link = core.MasterSkuLink{VariantID: arg.VariantID, CreatedAt: now()}
+	}
+	link.MasterSkuID = arg.MasterSkuID
+	link.MatchType = arg.MatchType
+	link.Status = arg.Status
+	link.UpdatedAt = now()
+	m.tables.masterSKULinks[link.VariantID] = link
+	return link, nil
+}
+
+// confirmedLink gets the master SKU, variant and integration of a confirmed link of the account,
+// the integration being of a shop still in the account, m.mu must be held
+func (m *Memory) confirmedLink(accountID id.ID[id.MerchantAccount], link core.MasterSkuLink) (core.MasterSku, core.ProductVariant, core.PlatformIntegration, bool) {
+	master := m.tables.masterSKUs[link.MasterSkuID]
+	if link.Status != core.MasterSkuLinkStatusConfirmed || master.AccountID != accountID {
+		return core.MasterSku{}, core.ProductVariant{}, core.PlatformIntegration{}, false
+	}
+	variant, ok := m.tables.productVariants[link.VariantID]
+	if !ok {
+		return core.MasterSku{}, core.ProductVariant{}, core.PlatformIntegration{}, false
+	}
+	integration, ok := m.accountIntegration(accountID, m.tables.products[variant.ProductID].IntegrationID)
+	return master, variant, integration, ok
+}
+
+// sortedMasterSKURows returns the rows of a master SKU rollup in the order of the query
+func sortedMasterSKURows[T any](rows map[masterSKUKey]*T, key func(*T) masterSKUKey) []T {
+	items := make([]T, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, *row)
+	}
+	slices.SortFunc(items, func(a, b T) int {
+		return compareMasterSKUKeys(key(&a), key(&b))
+	})
+	return items
+}
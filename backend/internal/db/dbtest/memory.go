@@ -0,0 +1,220 @@
+// Package dbtest provides an in-memory db.Database for unit tests that should not need Postgres.
+package dbtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/users"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Memory is an in-memory db.Database. It implements the users, shopify and core Queriers
+// itself, following the semantics of the SQL queries (unique keys, upserts, pgx.ErrNoRows).
+//
+// Of the core catalog and order queries (locations, products, variants, inventory and orders)
+// only the upserts, lookups by external ID, merchant account views, master SKUs and exchange
+// rates are implemented; calling any other panics through the nil embedded core.Querier. Add
+// them here as tests come to need them.
+type Memory struct {
+	core.Querier
+
+	mu     sync.Mutex
+	tables *tables
+	locks  map[string]bool
+
+	// Transactions are serialized, like a test suite running one request at a time
+	txMu sync.Mutex
+}
+
+// syncKey identifies the per-entity rows of an integration (sync states and checkpoints)
+type syncKey struct {
+	integrationID id.ID[id.PlatformIntegration]
+	entityType    core.EntityType
+}
+
+// tables holds every row of the fake database
+type tables struct {
+	users         map[id.ID[id.User]]users.User
+	shopifyStores map[id.ID[id.ShopifyStore]]shopify.ShopifyStore
+	shopifyUsers  map[id.ID[id.ShopifyUser]]shopify.ShopifyUser
+	integrations  map[id.ID[id.PlatformIntegration]]core.PlatformIntegration
+	syncStates    map[syncKey]core.SyncState
+	syncRuns      map[id.ID[id.SyncRun]]core.SyncRun
+	checkpoints   map[syncKey]core.SyncCheckpoint
+
+	wooCommerceCredentials map[id.ID[id.PlatformIntegration]]core.WoocommerceCredential
+	bigCommerceInstalls    map[string]core.BigcommerceInstallation
+	squareCredentials      map[id.ID[id.PlatformIntegration]]core.SquareCredential
+	magentoCredentials     map[id.ID[id.PlatformIntegration]]core.MagentoCredential
+
+	merchantAccounts     map[id.ID[id.MerchantAccount]]core.MerchantAccount
+	merchantAccountUsers map[accountUserKey]core.MerchantAccountUser
+	merchantAccountShops map[id.ID[id.ShopifyStore]]core.MerchantAccountShop
+	masterSKUs           map[id.ID[id.MasterSKU]]core.MasterSku
+	masterSKULinks       map[id.ID[id.ProductVariant]]core.MasterSkuLink
+	exchangeRates        map[exchangeRateKey]core.ExchangeRate
+
+	locations       map[id.ID[id.Location]]core.Location
+	products        map[id.ID[id.Product]]core.Product
+	productVariants map[id.ID[id.ProductVariant]]core.ProductVariant
+	inventoryItems  map[id.ID[id.InventoryItem]]core.InventoryItem
+	inventoryLevels map[levelKey]core.InventoryLevel
+	orders          map[id.ID[id.Order]]core.Order
+	orderLineItems  map[lineItemKey]core.OrderLineItem
+}
+
+// clone copies the tables so a failed transaction can be rolled back
+func (t *tables) clone() *tables {
+	return &tables{
+		users:         cloneMap(t.users),
+		shopifyStores: cloneMap(t.shopifyStores),
+		shopifyUsers:  cloneMap(t.shopifyUsers),
+		integrations:  cloneMap(t.integrations),
+		syncStates:    cloneMap(t.syncStates),
+		syncRuns:      cloneMap(t.syncRuns),
+		checkpoints:   cloneMap(t.checkpoints),
+
+		wooCommerceCredentials: cloneMap(t.wooCommerceCredentials),
+		bigCommerceInstalls:    cloneMap(t.bigCommerceInstalls),
+		squareCredentials:      cloneMap(t.squareCredentials),
+		magentoCredentials:     cloneMap(t.magentoCredentials),
+
+		merchantAccounts:     cloneMap(t.merchantAccounts),
+		merchantAccountUsers: cloneMap(t.merchantAccountUsers),
+		merchantAccountShops: cloneMap(t.merchantAccountShops),
+		masterSKUs:           cloneMap(t.masterSKUs),
+		masterSKULinks:       cloneMap(t.masterSKULinks),
+		exchangeRates:        cloneMap(t.exchangeRates),
+
+		locations:       cloneMap(t.locations),
+		products:        cloneMap(t.products),
+		productVariants: cloneMap(t.productVariants),
+		inventoryItems:  cloneMap(t.inventoryItems),
+		inventoryLevels: cloneMap(t.inventoryLevels),
+		orders:          cloneMap(t.orders),
+		orderLineItems:  cloneMap(t.orderLineItems),
+	}
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	cloned := make(map[K]V, len(m))
+	for k, v := range m {
+		cloned[k] = v
+	}
+	return cloned
+}
+
+var _ db.Database = (*Memory)(nil)
+
+// New creates an empty in-memory database
+func New() *Memory {
+	return &Memory{
+		tables: &tables{
+			users:         map[id.ID[id.User]]users.User{},
+			shopifyStores: map[id.ID[id.ShopifyStore]]shopify.ShopifyStore{},
+			shopifyUsers:  map[id.ID[id.ShopifyUser]]shopify.ShopifyUser{},
+			integrations:  map[id.ID[id.PlatformIntegration]]core.PlatformIntegration{},
+			syncStates:    map[syncKey]core.SyncState{},
+			syncRuns:      map[id.ID[id.SyncRun]]core.SyncRun{},
+			checkpoints:   map[syncKey]core.SyncCheckpoint{},
+
+			wooCommerceCredentials: map[id.ID[id.PlatformIntegration]]core.WoocommerceCredential{},
+			bigCommerceInstalls:    map[string]core.BigcommerceInstallation{},
+			squareCredentials:      map[id.ID[id.PlatformIntegration]]core.SquareCredential{},
+			magentoCredentials:     map[id.ID[id.PlatformIntegration]]core.MagentoCredential{},
+
+			merchantAccounts:     map[id.ID[id.MerchantAccount]]core.MerchantAccount{},
+			merchantAccountUsers: map[accountUserKey]core.MerchantAccountUser{},
+			merchantAccountShops: map[id.ID[id.ShopifyStore]]core.MerchantAccountShop{},
+			masterSKUs:           map[id.ID[id.MasterSKU]]core.MasterSku{},
+			masterSKULinks:       map[id.ID[id.ProductVariant]]core.MasterSkuLink{},
+			exchangeRates:        map[exchangeRateKey]core.ExchangeRate{},
+
+			locations:       map[id.ID[id.Location]]core.Location{},
+			products:        map[id.ID[id.Product]]core.Product{},
+			productVariants: map[id.ID[id.ProductVariant]]core.ProductVariant{},
+			inventoryItems:  map[id.ID[id.InventoryItem]]core.InventoryItem{},
+			inventoryLevels: map[levelKey]core.InventoryLevel{},
+			orders:          map[id.ID[id.Order]]core.Order{},
+			orderLineItems:  map[lineItemKey]core.OrderLineItem{},
+		},
+		locks: map[string]bool{},
+	}
+}
+
+// GetUsers returns the users repository
+func (m *Memory) GetUsers() users.Querier {
+	return m
+}
+
+// GetShopify returns the shopify repository
+func (m *Memory) GetShopify() shopify.Querier {
+	return m
+}
+
+// GetCore returns the core repository
+func (m *Memory) GetCore() core.Querier {
+	return m
+}
+
+// WithTx runs fn against the database and restores the state from before the transaction if fn fails
+func (m *Memory) WithTx(ctx context.Context, fn func(*db.TxDB) error) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+
+	m.mu.Lock()
+	snapshot := m.tables.clone()
+	m.mu.Unlock()
+
+	rollback := func() {
+		m.mu.Lock()
+		m.tables = snapshot
+		m.mu.Unlock()
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&db.TxDB{Users: m, Shopify: m, Core: m}); err != nil {
+		rollback()
+		return err
+	}
+
+	return nil
+}
+
+// TryLock takes the named lock if nobody holds it
+func (m *Memory) TryLock(ctx context.Context, key string) (func(), bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.locks[key] {
+		return nil, false, nil
+	}
+	m.locks[key] = true
+
+	release := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.locks, key)
+	}
+	return release, true, nil
+}
+
+// Close implements db.Database, there is nothing to close
+func (m *Memory) Close() {}
+
+// now returns the current time the way NOW() is stored in a TIMESTAMP column
+func now() pgtype.Timestamp {
+	return pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}
+}
@@ -0,0 +1,217 @@
+package dbtest
+
+import (
+	"context"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var _ shopify.Querier = (*Memory)(nil)
+
+// storeByDomain finds a store by its unique shop domain, m.mu must be held
+func (m *Memory) storeByDomain(shopDomain string) (shopify.ShopifyStore, bool) {
+	for _, store := range m.tables.shopifyStores {
+		if store.ShopDomain == shopDomain {
+			return store, true
+		}
+	}
+	return shopify.ShopifyStore{}, false
+}
+
+// shopifyUserByUserAndStore finds a shopify user by its unique (user, store) pair, m.mu must be held
+func (m *Memory) shopifyUserByUserAndStore(userID id.ID[id.User], storeID id.ID[id.ShopifyStore]) (shopify.ShopifyUser, bool) {
+	for _, user := range m.tables.shopifyUsers {
+		if user.UserID == userID && user.ShopifyStoreID == storeID {
+			return user, true
+		}
+	}
+	return shopify.ShopifyUser{}, false
+}
+
+func (m *Memory) CreateOrUpdateShopifyStore(ctx context.Context, arg shopify.CreateOrUpdateShopifyStoreParams) (shopify.ShopifyStore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	store, ok := m.storeByDomain(arg.ShopDomain)
+	if !ok {
+		store = shopify.ShopifyStore{ID: arg.ID, ShopDomain: arg.ShopDomain, CreatedAt: now()}
+	}
+	store.ShopName = arg.ShopName
+	store.Timezone = arg.Timezone
+	store.Currency = arg.Currency
+	store.UpdatedAt = now()
+
+	m.tables.shopifyStores[store.ID] = store
+	return store, nil
+}
+
+func (m *Memory) CreateOrUpdateShopifyUser(ctx context.Context, arg shopify.CreateOrUpdateShopifyUserParams) (shopify.ShopifyUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.shopifyUserByUserAndStore(arg.UserID, arg.ShopifyStoreID)
+	if !ok {
+		user = shopify.ShopifyUser{ID: arg.ID, UserID: arg.UserID, ShopifyStoreID: arg.ShopifyStoreID, CreatedAt: now()}
+	}
+	user.AccessToken = arg.AccessToken
+	user.Scope = arg.Scope
+	user.ExpiresAt = arg.ExpiresAt
+	user.UpdatedAt = now()
+
+	m.tables.shopifyUsers[user.ID] = user
+	return user, nil
+}
+
+func (m *Memory) CreateShopifyStore(ctx context.Context, arg shopify.CreateShopifyStoreParams) (shopify.ShopifyStore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.storeByDomain(arg.ShopDomain); ok {
+		return shopify.ShopifyStore{}, errors.Errorf("duplicate key value violates unique constraint on shop_domain: %s", arg.ShopDomain)
+	}
+
+	store := shopify.ShopifyStore{
+		ID:         arg.ID,
+		ShopDomain: arg.ShopDomain,
+		ShopName:   arg.ShopName,
+		Timezone:   arg.Timezone,
+		Currency:   arg.Currency,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	}
+	m.tables.shopifyStores[store.ID] = store
+	return store, nil
+}
+
+func (m *Memory) CreateShopifyUser(ctx context.Context, arg shopify.CreateShopifyUserParams) (shopify.ShopifyUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.shopifyUserByUserAndStore(arg.UserID, arg.ShopifyStoreID); ok {
+		return shopify.ShopifyUser{}, errors.Errorf("duplicate key value violates unique constraint on (user_id, shopify_store_id): %s, %s", arg.UserID, arg.ShopifyStoreID)
+	}
+
+	user := shopify.ShopifyUser{
+		ID:             arg.ID,
+		UserID:         arg.UserID,
+		ShopifyStoreID: arg.ShopifyStoreID,
+		AccessToken:    arg.AccessToken,
+		Scope:          arg.Scope,
+		ExpiresAt:      arg.ExpiresAt,
+		CreatedAt:      now(),
+		UpdatedAt:      now(),
+	}
+	m.tables.shopifyUsers[user.ID] = user
+	return user, nil
+}
+
+func (m *Memory) GetShopifyStoreByDomain(ctx context.Context, shopDomain string) (shopify.ShopifyStore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	store, ok := m.storeByDomain(shopDomain)
+	if !ok {
+		return shopify.ShopifyStore{}, pgx.ErrNoRows
+	}
+	return store, nil
+}
+
+func (m *Memory) GetShopifyStoreByID(ctx context.Context, argID id.ID[id.ShopifyStore]) (shopify.ShopifyStore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	store, ok := m.tables.shopifyStores[argID]
+	if !ok {
+		return shopify.ShopifyStore{}, pgx.ErrNoRows
+	}
+	return store, nil
+}
+
+func (m *Memory) GetShopifyUserByUserAndDomain(ctx context.Context, arg shopify.GetShopifyUserByUserAndDomainParams) (shopify.ShopifyUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	store, ok := m.storeByDomain(arg.ShopDomain)
+	if !ok {
+		return shopify.ShopifyUser{}, pgx.ErrNoRows
+	}
+	user, ok := m.shopifyUserByUserAndStore(arg.UserID, store.ID)
+	if !ok {
+		return shopify.ShopifyUser{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+func (m *Memory) GetShopifyUserByUserAndStore(ctx context.Context, arg shopify.GetShopifyUserByUserAndStoreParams) (shopify.ShopifyUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.shopifyUserByUserAndStore(arg.UserID, arg.ShopifyStoreID)
+	if !ok {
+		return shopify.ShopifyUser{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+func (m *Memory) GetShopifyUsersByStore(ctx context.Context, shopifyStoreID id.ID[id.ShopifyStore]) ([]shopify.ShopifyUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []shopify.ShopifyUser{}
+	for _, user := range m.tables.shopifyUsers {
+		if user.ShopifyStoreID == shopifyStoreID {
+			items = append(items, user)
+		}
+	}
+	return items, nil
+}
+
+func (m *Memory) GetShopifyUsersByUser(ctx context.Context, userID id.ID[id.User]) ([]shopify.ShopifyUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []shopify.ShopifyUser{}
+	for _, user := range m.tables.shopifyUsers {
+		if user.UserID == userID {
+			items = append(items, user)
+		}
+	}
+	return items, nil
+}
+
+func (m *Memory) UpdateShopifyStore(ctx context.Context, arg shopify.UpdateShopifyStoreParams) (shopify.ShopifyStore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	store, ok := m.storeByDomain(arg.ShopDomain)
+	if !ok {
+		return shopify.ShopifyStore{}, pgx.ErrNoRows
+	}
+	store.ShopName = arg.ShopName
+	store.Timezone = arg.Timezone
+	store.Currency = arg.Currency
+	store.UpdatedAt = now()
+
+	m.tables.shopifyStores[store.ID] = store
+	return store, nil
+}
+
+func (m *Memory) UpdateShopifyUserToken(ctx context.Context, arg shopify.UpdateShopifyUserTokenParams) (shopify.ShopifyUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.shopifyUserByUserAndStore(arg.UserID, arg.ShopifyStoreID)
+	if !ok {
+		return shopify.ShopifyUser{}, pgx.ErrNoRows
+	}
+	user.AccessToken = arg.AccessToken
+	user.Scope = arg.Scope
+	user.ExpiresAt = arg.ExpiresAt
+	user.UpdatedAt = now()
+
+	m.tables.shopifyUsers[user.ID] = user
+	return user, nil
+}
@@ -0,0 +1,49 @@
+package dbtest
+
+import (
+	"context"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/users"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var _ users.Querier = (*Memory)(nil)
+
+func (m *Memory) CreateUser(ctx context.Context, argID id.ID[id.User]) (users.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tables.users[argID]; ok {
+		return users.User{}, errors.Errorf("duplicate key value violates unique constraint \"users_pkey\": %s", argID)
+	}
+
+	user := users.User{ID: argID, CreatedAt: now(), UpdatedAt: now()}
+	m.tables.users[argID] = user
+	return user, nil
+}
+
+func (m *Memory) GetUserByID(ctx context.Context, argID id.ID[id.User]) (users.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.tables.users[argID]
+	if !ok {
+		return users.User{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+func (m *Memory) UpdateUser(ctx context.Context, argID id.ID[id.User]) (users.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.tables.users[argID]
+	if !ok {
+		return users.User{}, pgx.ErrNoRows
+	}
+	user.UpdatedAt = now()
+	m.tables.users[argID] = user
+	return user, nil
+}
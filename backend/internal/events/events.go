@@ -0,0 +1,36 @@
+package events
+
+import (
+	"time"
+)
+
+// Sync event types
+const (
+	SyncEventStarted   = "started"
+	SyncEventProgress  = "progress"
+	SyncEventCompleted = "completed"
+	SyncEventCancelled = "cancelled"
+	SyncEventFailed    = "failed"
+)
+
+// Sync phases reported in events
+const (
+	SyncPhaseSetup    = "setup"
+	SyncPhaseSync     = "sync"
+	SyncPhaseFinalize = "finalize"
+)
+
+// SyncEvent is a progress update of a running sync, published to everyone watching the integration
+type SyncEvent struct {
+	Type          string    `json:"type"`
+	RunID         string    `json:"run_id"`
+	IntegrationID string    `json:"integration_id"`
+	Phase         string    `json:"phase"`
+	Entity        string    `json:"entity,omitempty"`
+	PagesFetched  int       `json:"pages_fetched"`
+	RowsUpserted  int       `json:"rows_upserted"`
+	TotalRows     int       `json:"total_rows,omitempty"`
+	ETASeconds    *int64    `json:"eta_seconds,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Bus publishes and subscribes to sync events over Redis pub/sub, so events published
+// by the worker reach API servers streaming them to browsers
+type Bus struct {
+	client *redis.Client
+}
+
+// NewBus creates a new Redis backed event bus
+func NewBus() *Bus {
+	return &Bus{
+		client: redis.NewClient(&redis.Options{
+			Addr: config.Values.Redis.URL,
+		}),
+	}
+}
+
+// syncChannel returns the pub/sub channel of an integration's sync events
+func syncChannel(integrationID id.ID[id.PlatformIntegration]) string {
+	return fmt.Sprintf("sync:events:%s", integrationID)
+}
+
+// PublishSyncEvent publishes a sync event to the integration's subscribers.
+// Events are fire and forget: nobody listening is not an error.
+func (b *Bus) PublishSyncEvent(ctx context.Context, integrationID id.ID[id.PlatformIntegration], event SyncEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode sync event")
+	}
+
+	if err := b.client.Publish(ctx, syncChannel(integrationID), payload).Err(); err != nil {
+		return errors.Wrap(err, "failed to publish sync event")
+	}
+
+	return nil
+}
+
+// SubscribeSyncEvents streams the integration's sync events until ctx is done,
+// at which point the returned channel is closed
+func (b *Bus) SubscribeSyncEvents(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (<-chan SyncEvent, error) {
+	pubsub := b.client.Subscribe(ctx, syncChannel(integrationID))
+
+	// Wait for the subscription to be confirmed so no event published after this call is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, errors.Wrap(err, "failed to subscribe to sync events")
+	}
+
+	events := make(chan SyncEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event SyncEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					logger.Warn("Dropping malformed sync event", "channel", message.Channel, "error", err)
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// Close closes the Redis connection
+func (b *Bus) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,224 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ConradKurth/forecasting/backend/internal/auth"
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
+	"github.com/ConradKurth/forecasting/backend/internal/http/response"
+	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	shopifyutil "github.com/ConradKurth/forecasting/backend/pkg/shopify"
+	"github.com/go-chi/chi/v5"
+)
+
+// InitRoutes initializes the routes of merchant accounts and their cross-channel views
+func InitRoutes(r *chi.Mux, accountManager *manager.AccountManager) {
+	r.Route("/v1/accounts", func(r chi.Router) {
+		r.Use(auth.AuthMiddleware)
+		r.Get("/", response.Wrap(ListAccounts(accountManager)))
+		r.Get("/current", response.Wrap(GetAccount(accountManager)))
+		r.Put("/current", response.Wrap(UpdateAccount(accountManager)))
+		r.Post("/current/shops", response.Wrap(LinkShop(accountManager)))
+		r.Get("/current/skus", response.Wrap(GetAccountSKUs(accountManager)))
+		r.Get("/current/master-skus", response.Wrap(GetMasterSKUs(accountManager)))
+		r.Post("/current/master-skus", response.Wrap(CreateMasterSKU(accountManager)))
+		r.Post("/current/master-skus/match", response.Wrap(MatchMasterSKUs(accountManager)))
+		r.Get("/current/master-skus/links", response.Wrap(GetMasterSKULinks(accountManager)))
+		r.Put("/current/master-skus/links/{variant_id}", response.Wrap(ReviewMasterSKULink(accountManager)))
+	})
+}
+
+// LinkShopRequest represents a request to move another shop into the current account
+type LinkShopRequest struct {
+	ShopDomain string `json:"shop_domain"`
+}
+
+// UpdateAccountRequest represents a request to change the settings of the current account
+type UpdateAccountRequest struct {
+	ReportingCurrency string `json:"reporting_currency"`
+}
+
+// AccountsResponse represents the response for the accounts of a user
+type AccountsResponse struct {
+	Accounts []manager.AccountResult `json:"accounts"`
+}
+
+// ListAccounts lists the merchant accounts of the user
+// GET /v1/accounts
+func ListAccounts(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := accountManager.ListAccounts(r.Context(), req.UserID)
+		if err != nil {
+			logger.Error("Failed to list accounts", "error", err, "user_id", req.UserID)
+			return response.InternalServerError("Failed to list accounts", err)
+		}
+
+		return response.JSON(w, http.StatusOK, AccountsResponse{Accounts: accounts})
+	}
+}
+
+// GetAccount gets the merchant account of the session with its shops and integrations
+// GET /v1/accounts/current
+func GetAccount(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		account, err := accountManager.GetAccount(r.Context(), req)
+		if err != nil {
+			return accountError(err, req, "Failed to get account")
+		}
+
+		return response.JSON(w, http.StatusOK, account)
+	}
+}
+
+// UpdateAccount changes the reporting currency the session's account views are converted into
+// PUT /v1/accounts/current
+func UpdateAccount(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		var body UpdateAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			logger.Error("Failed to decode update account request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+		if body.ReportingCurrency == "" {
+			return response.MissingParameter("reporting_currency")
+		}
+
+		account, err := accountManager.SetReportingCurrency(r.Context(), manager.SetReportingCurrencyRequest{
+			AccountRequest:    req,
+			ReportingCurrency: body.ReportingCurrency,
+		})
+		if errors.Is(err, currency.ErrInvalidCode) {
+			return response.BadRequest("reporting_currency must be a three letter ISO 4217 code", nil)
+		}
+		if err != nil {
+			return accountError(err, req, "Failed to update account")
+		}
+
+		return response.JSON(w, http.StatusOK, account)
+	}
+}
+
+// LinkShop moves another shop the user has connected, and its integrations, into the session's account
+// POST /v1/accounts/current/shops
+func LinkShop(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		var body LinkShopRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			logger.Error("Failed to decode link shop request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+		if body.ShopDomain == "" {
+			return response.MissingParameter("shop_domain")
+		}
+
+		account, err := accountManager.LinkShop(r.Context(), manager.LinkShopRequest{
+			AccountRequest: req,
+			LinkShopDomain: shopifyutil.NormalizeDomain(body.ShopDomain),
+		})
+		if errors.Is(err, manager.ErrShopAccessDenied) {
+			return response.NotFound("Shop not found, connect it from this session first", nil)
+		}
+		if err != nil {
+			return accountError(err, req, "Failed to link shop")
+		}
+
+		return response.JSON(w, http.StatusOK, account)
+	}
+}
+
+// GetAccountSKUs gets the stock and recent sales of every SKU across the channels of the session's account
+// GET /v1/accounts/current/skus?days=30
+func GetAccountSKUs(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		days, err := salesDays(r)
+		if err != nil {
+			return err
+		}
+
+		result, err := accountManager.GetAccountSKUs(r.Context(), manager.AccountSKUsRequest{
+			AccountRequest: req,
+			Days:           days,
+		})
+		if err != nil {
+			return accountError(err, req, "Failed to get account SKUs")
+		}
+
+		return response.JSON(w, http.StatusOK, result)
+	}
+}
+
+// salesDays reads the sales window of the days query parameter, the default window when absent
+func salesDays(r *http.Request) (int, error) {
+	rawDays := r.URL.Query().Get("days")
+	if rawDays == "" {
+		return manager.DefaultAccountSalesDays, nil
+	}
+	days, err := strconv.Atoi(rawDays)
+	if err != nil || days <= 0 || days > manager.MaxAccountSalesDays {
+		return 0, response.BadRequest(fmt.Sprintf("days must be between 1 and %d", manager.MaxAccountSalesDays), nil)
+	}
+	return days, nil
+}
+
+// accountRequest identifies the account of the session from its token
+func accountRequest(r *http.Request) (manager.AccountRequest, error) {
+	user, ok := auth.GetUserFromContext(r.Context())
+	if !ok {
+		return manager.AccountRequest{}, response.InternalServerError("User not found in context", nil)
+	}
+
+	userID, err := id.New[id.User](user.UserID)
+	if err != nil {
+		logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
+		return manager.AccountRequest{}, response.BadRequest("Invalid user ID", nil)
+	}
+
+	req := manager.AccountRequest{UserID: userID, ShopDomain: user.Shop}
+	if user.AccountID != "" {
+		if req.AccountID, err = id.New[id.MerchantAccount](user.AccountID); err != nil {
+			logger.Error("Invalid account ID", "account_id", user.AccountID, "error", err)
+			return manager.AccountRequest{}, response.BadRequest("Invalid account ID", nil)
+		}
+	}
+	return req, nil
+}
+
+// accountError converts an account manager error into a response
+func accountError(err error, req manager.AccountRequest, message string) error {
+	if errors.Is(err, manager.ErrAccountNotFound) {
+		return response.NotFound("Account not found", nil)
+	}
+	logger.Error(message, "error", err, "user_id", req.UserID, "account_id", req.AccountID)
+	return response.InternalServerError(message, err)
+}
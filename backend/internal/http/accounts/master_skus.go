@@ -0,0 +1,191 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ConradKurth/forecasting/backend/internal/http/response"
+	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateMasterSKURequest represents a request to create a master SKU
+type CreateMasterSKURequest struct {
+	SKU     string `json:"sku"`
+	Title   string `json:"title"`
+	Barcode string `json:"barcode,omitempty"`
+}
+
+// ReviewMasterSKULinkRequest represents the review of a variant's match. Either the status
+// confirms or rejects the match, or the master SKU ID links the variant to that master SKU.
+type ReviewMasterSKULinkRequest struct {
+	Status      core.MasterSkuLinkStatus `json:"status,omitempty"`
+	MasterSKUID string                   `json:"master_sku_id,omitempty"`
+}
+
+// MasterSKULinksResponse represents the response for the matches of an account
+type MasterSKULinksResponse struct {
+	Links []manager.MasterSKULink `json:"links"`
+}
+
+// GetMasterSKUs gets the master SKUs of the session's account with their stock and recent sales
+// across every channel
+// GET /v1/accounts/current/master-skus?days=30
+func GetMasterSKUs(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+		days, err := salesDays(r)
+		if err != nil {
+			return err
+		}
+
+		result, err := accountManager.GetMasterSKUs(r.Context(), manager.AccountSKUsRequest{
+			AccountRequest: req,
+			Days:           days,
+		})
+		if err != nil {
+			return accountError(err, req, "Failed to get master SKUs")
+		}
+
+		return response.JSON(w, http.StatusOK, result)
+	}
+}
+
+// CreateMasterSKU creates a master SKU in the session's account
+// POST /v1/accounts/current/master-skus
+func CreateMasterSKU(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		var body CreateMasterSKURequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			logger.Error("Failed to decode create master SKU request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+		if body.SKU == "" {
+			return response.MissingParameter("sku")
+		}
+		if body.Title == "" {
+			return response.MissingParameter("title")
+		}
+
+		master, err := accountManager.CreateMasterSKU(r.Context(), manager.CreateMasterSKURequest{
+			AccountRequest: req,
+			SKU:            body.SKU,
+			Title:          body.Title,
+			Barcode:        body.Barcode,
+		})
+		if errors.Is(err, manager.ErrMasterSKUExists) {
+			return response.Conflict("A master SKU with this SKU already exists", nil)
+		}
+		if err != nil {
+			return accountError(err, req, "Failed to create master SKU")
+		}
+
+		return response.JSON(w, http.StatusCreated, master)
+	}
+}
+
+// MatchMasterSKUs links the variants of the session's account without a master SKU to one
+// POST /v1/accounts/current/master-skus/match
+func MatchMasterSKUs(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		result, err := accountManager.MatchMasterSKUs(r.Context(), req)
+		if err != nil {
+			return accountError(err, req, "Failed to match master SKUs")
+		}
+
+		return response.JSON(w, http.StatusOK, result)
+	}
+}
+
+// GetMasterSKULinks lists the matches of the session's account in a review state
+// GET /v1/accounts/current/master-skus/links?status=suggested
+func GetMasterSKULinks(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		status := core.MasterSkuLinkStatus(r.URL.Query().Get("status"))
+		switch status {
+		case "", core.MasterSkuLinkStatusSuggested, core.MasterSkuLinkStatusConfirmed, core.MasterSkuLinkStatusRejected:
+		default:
+			return response.BadRequest("status must be suggested, confirmed or rejected", nil)
+		}
+
+		links, err := accountManager.GetMasterSKULinks(r.Context(), manager.MasterSKULinksRequest{
+			AccountRequest: req,
+			Status:         status,
+		})
+		if err != nil {
+			return accountError(err, req, "Failed to get master SKU links")
+		}
+
+		return response.JSON(w, http.StatusOK, MasterSKULinksResponse{Links: links})
+	}
+}
+
+// ReviewMasterSKULink confirms or rejects the match of a variant, or links it to a master SKU
+// PUT /v1/accounts/current/master-skus/links/{variant_id}
+func ReviewMasterSKULink(accountManager *manager.AccountManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		req, err := accountRequest(r)
+		if err != nil {
+			return err
+		}
+
+		variantID, err := id.New[id.ProductVariant](chi.URLParam(r, "variant_id"))
+		if err != nil {
+			return response.BadRequest("Invalid variant ID", nil)
+		}
+
+		var body ReviewMasterSKULinkRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			logger.Error("Failed to decode review master SKU link request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+		review := manager.ReviewMasterSKULinkRequest{
+			AccountRequest: req,
+			VariantID:      variantID,
+			Status:         body.Status,
+		}
+		if body.MasterSKUID != "" {
+			if review.MasterSKUID, err = id.New[id.MasterSKU](body.MasterSKUID); err != nil {
+				return response.BadRequest("Invalid master SKU ID", nil)
+			}
+		}
+
+		link, err := accountManager.ReviewMasterSKULink(r.Context(), review)
+		switch {
+		case errors.Is(err, manager.ErrInvalidMasterSKUReview):
+			return response.BadRequest(err.Error(), nil)
+		case errors.Is(err, manager.ErrVariantNotFound):
+			return response.NotFound("Variant not found", nil)
+		case errors.Is(err, manager.ErrMasterSKUNotFound):
+			return response.NotFound("Master SKU not found", nil)
+		case errors.Is(err, manager.ErrMasterSKULinkNotFound):
+			return response.NotFound("Variant has no master SKU match", nil)
+		case err != nil:
+			return accountError(err, req, "Failed to review master SKU link")
+		}
+
+		return response.JSON(w, http.StatusOK, link)
+	}
+}
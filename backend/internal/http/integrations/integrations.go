@@ -0,0 +1,509 @@
+package integrations
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ConradKurth/forecasting/backend/internal/auth"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/fileimport"
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
+	"github.com/ConradKurth/forecasting/backend/internal/http/response"
+	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	shopifyutil "github.com/ConradKurth/forecasting/backend/pkg/shopify"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// Largest file accepted by an import, and how much of it is held in memory while parsing the upload
+	maxImportFileSize = 20 << 20
+	maxImportMemory   = 8 << 20
+
+	// ErrorCodeInvalidRows is the code of the response for a file that failed validation
+	ErrorCodeInvalidRows = "INVALID_ROWS"
+)
+
+// InitRoutes initializes the routes that connect other platforms to a merchant account
+func InitRoutes(r *chi.Mux, syncManager *manager.InventorySyncManager) {
+	r.Route("/v1/integrations", func(r chi.Router) {
+		r.Use(auth.AuthMiddleware)
+		r.Get("/", response.Wrap(ListIntegrations(syncManager)))
+		r.Post("/woocommerce", response.Wrap(ConnectWooCommerce(syncManager)))
+		r.Post("/bigcommerce/claim", response.Wrap(ClaimBigCommerce(syncManager)))
+		r.Post("/square", response.Wrap(ConnectSquare(syncManager)))
+		r.Post("/magento", response.Wrap(ConnectMagento(syncManager)))
+		r.Post("/imports", response.Wrap(ImportFile(syncManager)))
+		r.Put("/{integration_id}/sync-interval", response.Wrap(SetSyncInterval(syncManager)))
+		r.Get("/{integration_id}/products", response.Wrap(GetProductsByTags(syncManager)))
+	})
+}
+
+// ConnectWooCommerceRequest represents a request to connect a WooCommerce store. The connect
+// requests take an optional shop_domain: without one the store is connected to the session's
+// account directly.
+type ConnectWooCommerceRequest struct {
+	ShopDomain     string `json:"shop_domain"`
+	StoreURL       string `json:"store_url"`
+	ConsumerKey    string `json:"consumer_key"`
+	ConsumerSecret string `json:"consumer_secret"`
+}
+
+// ClaimBigCommerceRequest represents a request to link an installed BigCommerce store
+type ClaimBigCommerceRequest struct {
+	ShopDomain string `json:"shop_domain"`
+	ClaimToken string `json:"claim_token"`
+}
+
+// ConnectSquareRequest represents a request to connect a Square seller
+type ConnectSquareRequest struct {
+	ShopDomain  string `json:"shop_domain"`
+	AccessToken string `json:"access_token"`
+}
+
+// ConnectMagentoRequest represents a request to connect a Magento store
+type ConnectMagentoRequest struct {
+	ShopDomain  string `json:"shop_domain"`
+	StoreURL    string `json:"store_url"`
+	AccessToken string `json:"access_token"`
+}
+
+// SetSyncIntervalRequest represents a request to change how often an integration is synced
+type SetSyncIntervalRequest struct {
+	IntervalMinutes *int `json:"sync_interval_minutes"`
+}
+
+// ImportErrorsResponse represents the response for a file that failed validation, listing the
+// line of every problem found
+type ImportErrorsResponse struct {
+	Error   string                      `json:"error"`
+	Message string                      `json:"message"`
+	Code    string                      `json:"code"`
+	Errors  fileimport.ValidationErrors `json:"errors"`
+}
+
+// IntegrationsResponse represents the response for the integrations of a shop
+type IntegrationsResponse struct {
+	Integrations []manager.IntegrationResult `json:"integrations"`
+}
+
+// ProductsResponse represents the response for the products of an integration
+type ProductsResponse struct {
+	Products []manager.ProductResult `json:"products"`
+}
+
+// ConnectWooCommerce connects a WooCommerce store to the session's account with a REST API consumer key and secret
+// POST /v1/integrations/woocommerce
+func ConnectWooCommerce(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req ConnectWooCommerceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Error("Failed to decode connect request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+
+		owner, err := connectOwner(r, req.ShopDomain)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case req.StoreURL == "":
+			return response.MissingParameter("store_url")
+		case req.ConsumerKey == "":
+			return response.MissingParameter("consumer_key")
+		case req.ConsumerSecret == "":
+			return response.MissingParameter("consumer_secret")
+		}
+
+		// Delegate to manager
+		result, err := syncManager.ConnectWooCommerce(r.Context(), manager.ConnectWooCommerceRequest{
+			ConnectOwner:   owner,
+			StoreURL:       req.StoreURL,
+			ConsumerKey:    req.ConsumerKey,
+			ConsumerSecret: req.ConsumerSecret,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, manager.ErrInvalidStoreURL):
+				return response.BadRequest("Store URL must be a public https URL", nil)
+			case errors.Is(err, manager.ErrInvalidCredentials):
+				return response.BadRequest("WooCommerce rejected the consumer key and secret", nil)
+			case errors.Is(err, manager.ErrStoreConnectedElsewhere):
+				return response.Conflict("Store is already connected to another account", nil)
+			case errors.Is(err, manager.ErrAccountNotFound):
+				return response.NotFound("Account not found", nil)
+			}
+			logger.Error("WooCommerce connect failed", "error", err, "user_id", owner.UserID, "account_id", owner.AccountID, "shop_domain", owner.ShopDomain)
+			return response.InternalServerError("Failed to connect WooCommerce store", err)
+		}
+
+		return response.JSON(w, http.StatusCreated, result)
+	}
+}
+
+// ClaimBigCommerce links a BigCommerce store that installed the app to the session's account, using the claim
+// token the install callback handed to the frontend
+// POST /v1/integrations/bigcommerce/claim
+func ClaimBigCommerce(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req ClaimBigCommerceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Error("Failed to decode claim request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+
+		owner, err := connectOwner(r, req.ShopDomain)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case req.ClaimToken == "":
+			return response.MissingParameter("claim_token")
+		}
+
+		// Delegate to manager
+		result, err := syncManager.ClaimBigCommerceInstallation(r.Context(), manager.ClaimBigCommerceRequest{
+			ConnectOwner: owner,
+			ClaimToken:   req.ClaimToken,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, manager.ErrInstallationNotFound):
+				return response.NotFound("Installation not found or expired, install the app again", nil)
+			case errors.Is(err, manager.ErrStoreConnectedElsewhere):
+				return response.Conflict("Store is already connected to another account", nil)
+			case errors.Is(err, manager.ErrAccountNotFound):
+				return response.NotFound("Account not found", nil)
+			}
+			logger.Error("BigCommerce claim failed", "error", err, "user_id", owner.UserID, "account_id", owner.AccountID, "shop_domain", owner.ShopDomain)
+			return response.InternalServerError("Failed to connect BigCommerce store", err)
+		}
+
+		return response.JSON(w, http.StatusCreated, result)
+	}
+}
+
+// ConnectSquare connects a Square seller to the session's account with an access token
+// POST /v1/integrations/square
+func ConnectSquare(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req ConnectSquareRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Error("Failed to decode connect request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+
+		owner, err := connectOwner(r, req.ShopDomain)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case req.AccessToken == "":
+			return response.MissingParameter("access_token")
+		}
+
+		// Delegate to manager
+		result, err := syncManager.ConnectSquare(r.Context(), manager.ConnectSquareRequest{
+			ConnectOwner: owner,
+			AccessToken:  req.AccessToken,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, manager.ErrInvalidCredentials):
+				return response.BadRequest("Square rejected the access token", nil)
+			case errors.Is(err, manager.ErrStoreConnectedElsewhere):
+				return response.Conflict("Seller is already connected to another account", nil)
+			case errors.Is(err, manager.ErrAccountNotFound):
+				return response.NotFound("Account not found", nil)
+			}
+			logger.Error("Square connect failed", "error", err, "user_id", owner.UserID, "account_id", owner.AccountID, "shop_domain", owner.ShopDomain)
+			return response.InternalServerError("Failed to connect Square seller", err)
+		}
+
+		return response.JSON(w, http.StatusCreated, result)
+	}
+}
+
+// ConnectMagento connects a Magento store to the session's account with the access token of a store integration
+// POST /v1/integrations/magento
+func ConnectMagento(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req ConnectMagentoRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Error("Failed to decode connect request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+
+		owner, err := connectOwner(r, req.ShopDomain)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case req.StoreURL == "":
+			return response.MissingParameter("store_url")
+		case req.AccessToken == "":
+			return response.MissingParameter("access_token")
+		}
+
+		// Delegate to manager
+		result, err := syncManager.ConnectMagento(r.Context(), manager.ConnectMagentoRequest{
+			ConnectOwner: owner,
+			StoreURL:     req.StoreURL,
+			AccessToken:  req.AccessToken,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, manager.ErrInvalidStoreURL):
+				return response.BadRequest("Store URL must be a public https URL", nil)
+			case errors.Is(err, manager.ErrInvalidCredentials):
+				return response.BadRequest("Magento rejected the access token", nil)
+			case errors.Is(err, manager.ErrStoreConnectedElsewhere):
+				return response.Conflict("Store is already connected to another account", nil)
+			case errors.Is(err, manager.ErrAccountNotFound):
+				return response.NotFound("Account not found", nil)
+			}
+			logger.Error("Magento connect failed", "error", err, "user_id", owner.UserID, "account_id", owner.AccountID, "shop_domain", owner.ShopDomain)
+			return response.InternalServerError("Failed to connect Magento store", err)
+		}
+
+		return response.JSON(w, http.StatusCreated, result)
+	}
+}
+
+// ImportFile imports products, inventory levels or sales of a shop from an uploaded CSV or XLSX
+// file, sent as multipart/form-data with the fields shop_domain, type and file, and optionally
+// the currency of the file's amounts
+// POST /v1/integrations/imports
+func ImportFile(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		user, ok := auth.GetUserFromContext(r.Context())
+		if !ok {
+			return response.InternalServerError("User not found in context", nil)
+		}
+
+		userID, err := id.New[id.User](user.UserID)
+		if err != nil {
+			logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
+			return response.BadRequest("Invalid user ID", nil)
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+		if err := r.ParseMultipartForm(maxImportMemory); err != nil {
+			logger.Error("Failed to parse import upload", "error", err)
+			return response.BadRequest("Invalid upload, send the file as multipart/form-data of at most 20 MB", nil)
+		}
+
+		shopDomain := r.FormValue("shop_domain")
+		switch {
+		case shopDomain == "":
+			return response.MissingParameter("shop_domain")
+		case r.FormValue("type") == "":
+			return response.MissingParameter("type")
+		}
+
+		kind, err := fileimport.ParseKind(r.FormValue("type"))
+		if err != nil {
+			return response.BadRequest(err.Error(), nil)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return response.MissingParameter("file")
+		}
+		defer file.Close()
+
+		// Delegate to manager
+		result, err := syncManager.ImportFile(r.Context(), manager.ImportFileRequest{
+			UserID:     userID,
+			ShopDomain: shopifyutil.NormalizeDomain(shopDomain),
+			Kind:       kind,
+			FileName:   header.Filename,
+			Content:    file,
+			Currency:   r.FormValue("currency"),
+		})
+		if err != nil {
+			var validationErrors fileimport.ValidationErrors
+			switch {
+			case errors.As(err, &validationErrors):
+				return response.JSON(w, http.StatusUnprocessableEntity, ImportErrorsResponse{
+					Error:   http.StatusText(http.StatusUnprocessableEntity),
+					Message: "The file has invalid rows, nothing was imported",
+					Code:    ErrorCodeInvalidRows,
+					Errors:  validationErrors,
+				})
+			case errors.Is(err, fileimport.ErrUnsupportedFormat):
+				return response.BadRequest("Unsupported file format, upload a .csv or .xlsx file", nil)
+			case errors.Is(err, fileimport.ErrInvalidFile):
+				return response.BadRequest("The file could not be read, upload a .csv file or an .xlsx workbook", err)
+			case errors.Is(err, currency.ErrInvalidCode):
+				return response.BadRequest("currency must be a three letter ISO 4217 code", nil)
+			}
+			logger.Error("File import failed", "error", err, "user_id", userID, "shop_domain", shopDomain)
+			return response.InternalServerError("Failed to import file", err)
+		}
+
+		return response.JSON(w, http.StatusOK, result)
+	}
+}
+
+// ListIntegrations lists the platform integrations of a shop
+// GET /v1/integrations?shop_domain={shop_domain}
+func ListIntegrations(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		user, ok := auth.GetUserFromContext(r.Context())
+		if !ok {
+			return response.InternalServerError("User not found in context", nil)
+		}
+
+		userID, err := id.New[id.User](user.UserID)
+		if err != nil {
+			logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
+			return response.BadRequest("Invalid user ID", nil)
+		}
+
+		shopDomain := r.URL.Query().Get("shop_domain")
+		if shopDomain == "" {
+			return response.MissingParameter("shop_domain")
+		}
+
+		integrations, err := syncManager.ListIntegrations(r.Context(), userID, shopifyutil.NormalizeDomain(shopDomain))
+		if err != nil {
+			logger.Error("Failed to list integrations", "error", err, "user_id", userID, "shop_domain", shopDomain)
+			return response.InternalServerError("Failed to list integrations", err)
+		}
+
+		return response.JSON(w, http.StatusOK, IntegrationsResponse{Integrations: integrations})
+	}
+}
+
+// SetSyncInterval sets the minutes between scheduled full syncs of an integration, 0 to turn them off
+// PUT /v1/integrations/{integration_id}/sync-interval
+func SetSyncInterval(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		user, ok := auth.GetUserFromContext(r.Context())
+		if !ok {
+			return response.InternalServerError("User not found in context", nil)
+		}
+
+		userID, err := id.New[id.User](user.UserID)
+		if err != nil {
+			logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
+			return response.BadRequest("Invalid user ID", nil)
+		}
+
+		integrationID, err := id.New[id.PlatformIntegration](chi.URLParam(r, "integration_id"))
+		if err != nil {
+			return response.BadRequest("Invalid integration_id", nil)
+		}
+
+		var req SetSyncIntervalRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Error("Failed to decode sync interval request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
+		}
+
+		if req.IntervalMinutes == nil {
+			return response.MissingParameter("sync_interval_minutes")
+		}
+
+		// Delegate to manager
+		result, err := syncManager.SetSyncInterval(r.Context(), manager.SetSyncIntervalRequest{
+			UserID:          userID,
+			IntegrationID:   integrationID,
+			IntervalMinutes: *req.IntervalMinutes,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, manager.ErrInvalidSyncInterval):
+				return response.BadRequest(err.Error(), nil)
+			case errors.Is(err, manager.ErrIntegrationNotFound):
+				return response.NotFound("Integration not found", nil)
+			case errors.Is(err, manager.ErrNotSyncable):
+				return response.BadRequest("Integration is updated by file imports and cannot be synced", nil)
+			}
+			logger.Error("Failed to set sync interval", "error", err, "user_id", userID, "integration_id", integrationID)
+			return response.InternalServerError("Failed to set sync interval", err)
+		}
+
+		return response.JSON(w, http.StatusOK, result)
+	}
+}
+
+// GetProductsByTags gets the products of an integration carrying every one of the comma-separated tags,
+// whatever their case
+// GET /v1/integrations/{integration_id}/products?tags=summer,tees
+func GetProductsByTags(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		user, ok := auth.GetUserFromContext(r.Context())
+		if !ok {
+			return response.InternalServerError("User not found in context", nil)
+		}
+
+		userID, err := id.New[id.User](user.UserID)
+		if err != nil {
+			logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
+			return response.BadRequest("Invalid user ID", nil)
+		}
+
+		integrationID, err := id.New[id.PlatformIntegration](chi.URLParam(r, "integration_id"))
+		if err != nil {
+			return response.BadRequest("Invalid integration_id", nil)
+		}
+
+		query := r.URL.Query()
+		if query.Get("tags") == "" {
+			return response.MissingParameter("tags")
+		}
+
+		products, err := syncManager.GetProductsByTags(r.Context(), manager.ProductsByTagsRequest{
+			UserID:        userID,
+			IntegrationID: integrationID,
+			Tags:          strings.Split(query.Get("tags"), ","),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, manager.ErrNoTags):
+				return response.BadRequest(err.Error(), nil)
+			case errors.Is(err, manager.ErrIntegrationNotFound):
+				return response.NotFound("Integration not found", nil)
+			}
+			logger.Error("Failed to get products by tags", "error", err, "user_id", userID, "integration_id", integrationID)
+			return response.InternalServerError("Failed to get products", err)
+		}
+
+		return response.JSON(w, http.StatusOK, ProductsResponse{Products: products})
+	}
+}
+
+// connectOwner reads who a store is connected for: the session's account, through the shop of
+// shop_domain when one is given. Sessions started before accounts existed must give a shop.
+func connectOwner(r *http.Request, shopDomain string) (manager.ConnectOwner, error) {
+	user, ok := auth.GetUserFromContext(r.Context())
+	if !ok {
+		return manager.ConnectOwner{}, response.InternalServerError("User not found in context", nil)
+	}
+
+	userID, err := id.New[id.User](user.UserID)
+	if err != nil {
+		logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
+		return manager.ConnectOwner{}, response.BadRequest("Invalid user ID", nil)
+	}
+
+	owner := manager.ConnectOwner{UserID: userID, ShopDomain: shopifyutil.NormalizeDomain(shopDomain)}
+	if user.AccountID != "" {
+		if owner.AccountID, err = id.New[id.MerchantAccount](user.AccountID); err != nil {
+			logger.Error("Invalid account ID", "account_id", user.AccountID, "error", err)
+			return manager.ConnectOwner{}, response.BadRequest("Invalid account ID", nil)
+		}
+	}
+	if owner.ShopDomain == "" && owner.AccountID == "" {
+		return manager.ConnectOwner{}, response.MissingParameter("shop_domain")
+	}
+	return owner, nil
+}
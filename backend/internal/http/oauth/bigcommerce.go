@@ -0,0 +1,113 @@
+package oauth
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/http/response"
+	"github.com/ConradKurth/forecasting/backend/internal/manager"
+	"github.com/go-chi/chi/v5"
+)
+
+// initBigCommerceRoutes initializes the callbacks of the BigCommerce app
+func initBigCommerceRoutes(r *chi.Mux, syncManager *manager.InventorySyncManager) {
+	r.Get("/v1/bigcommerce/install", response.Wrap(RequestBigCommerceInstall))
+	r.Get("/v1/bigcommerce/callback", response.Wrap(BigCommerceCallback(syncManager)))
+	r.Get("/v1/bigcommerce/load", response.Wrap(BigCommerceLoad))
+	r.Get("/v1/bigcommerce/uninstall", response.Wrap(BigCommerceUninstall(syncManager)))
+}
+
+// bigCommerceOAuthConfig returns the credentials of the BigCommerce app
+func bigCommerceOAuthConfig() bigcommerce.OAuthConfig {
+	return bigcommerce.OAuthConfig{
+		ClientID:     config.Values.BigCommerce.ClientID,
+		ClientSecret: config.Values.BigCommerce.ClientSecret,
+		RedirectURL:  config.Values.BigCommerce.RedirectURL,
+		LoginURL:     config.Values.BigCommerce.LoginURL,
+	}
+}
+
+// RequestBigCommerceInstall sends the merchant to the BigCommerce app install page
+func RequestBigCommerceInstall(w http.ResponseWriter, r *http.Request) error {
+	redirectURL := fmt.Sprintf("%s/app/%s/install", config.Values.BigCommerce.LoginURL, url.PathEscape(config.Values.BigCommerce.ClientID))
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+	return nil
+}
+
+// BigCommerceCallback is the auth callback BigCommerce calls when the app is installed on a store.
+// It saves the store's access token and sends the merchant to the frontend to link the store to a shop.
+func BigCommerceCallback(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		code := r.URL.Query().Get("code")
+		scope := r.URL.Query().Get("scope")
+		storeContext := r.URL.Query().Get("context")
+
+		if code == "" {
+			return response.MissingParameter("code")
+		}
+		if storeContext == "" {
+			return response.MissingParameter("context")
+		}
+
+		installation, err := bigcommerce.ExchangeCode(r.Context(), bigCommerceOAuthConfig(), code, scope, storeContext)
+		if err != nil {
+			return response.InternalServerError("Failed to get access token", err)
+		}
+
+		claimToken, err := syncManager.SaveBigCommerceInstallation(r.Context(), installation)
+		if err != nil {
+			log.Printf("Failed to save bigcommerce installation for store %s: %v", installation.StoreHash, err)
+			return response.DatabaseError(err)
+		}
+
+		// Redirect to frontend claim page, which links the store to the signed in user's shop
+		redirectTo := fmt.Sprintf("%v/integrations/bigcommerce/claim?store_hash=%s&claim_token=%s",
+			config.Values.Frontend.URL, url.QueryEscape(installation.StoreHash), url.QueryEscape(claimToken))
+		http.Redirect(w, r, redirectTo, http.StatusFound)
+		return nil
+	}
+}
+
+// BigCommerceLoad is the load callback BigCommerce calls when the merchant opens the app from the
+// store's control panel
+func BigCommerceLoad(w http.ResponseWriter, r *http.Request) error {
+	signedPayload := r.URL.Query().Get("signed_payload_jwt")
+	if signedPayload == "" {
+		return response.MissingParameter("signed_payload_jwt")
+	}
+
+	storeHash, err := bigcommerce.VerifySignedPayload(bigCommerceOAuthConfig(), signedPayload)
+	if err != nil {
+		return response.Unauthorized("Invalid signed payload", err)
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("%v/?bigcommerce_store=%s", config.Values.Frontend.URL, url.QueryEscape(storeHash)), http.StatusFound)
+	return nil
+}
+
+// BigCommerceUninstall is the uninstall callback BigCommerce calls when the app is removed from a store
+func BigCommerceUninstall(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		signedPayload := r.URL.Query().Get("signed_payload_jwt")
+		if signedPayload == "" {
+			return response.MissingParameter("signed_payload_jwt")
+		}
+
+		storeHash, err := bigcommerce.VerifySignedPayload(bigCommerceOAuthConfig(), signedPayload)
+		if err != nil {
+			return response.Unauthorized("Invalid signed payload", err)
+		}
+
+		if err := syncManager.UninstallBigCommerce(r.Context(), storeHash); err != nil {
+			log.Printf("Failed to uninstall bigcommerce store %s: %v", storeHash, err)
+			return response.DatabaseError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
@@ -1,12 +1,19 @@
 package oauth
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ConradKurth/forecasting/backend/internal/auth"
 	"github.com/ConradKurth/forecasting/backend/internal/config"
@@ -17,9 +24,19 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+const (
+	// stateCookie holds the state nonce of the install this browser started, and the user of the
+	// session that started it
+	stateCookie = "shopify_oauth_state"
+
+	// How long the browser has to complete an install
+	stateTTL = 10 * time.Minute
+)
+
 func InitRoutes(r *chi.Mux, shopifyManager *manager.ShopifyManager, syncManager *manager.InventorySyncManager) {
 	r.Get("/v1/shopify/install", response.Wrap(RequestInstall))
 	r.Get("/v1/shopify/callback", response.Wrap(RequestCallback(shopifyManager, syncManager)))
+	initBigCommerceRoutes(r, syncManager)
 }
 
 func RequestInstall(w http.ResponseWriter, r *http.Request) error {
@@ -28,8 +45,28 @@ func RequestInstall(w http.ResponseWriter, r *http.Request) error {
 		return response.MissingParameter("shop")
 	}
 	normalizedShop := shopify.NormalizeDomain(shop)
-	redirectURL := fmt.Sprintf("https://%s/admin/oauth/authorize?client_id=%s&scope=%s&redirect_uri=%s",
-		normalizedShop, config.Values.Shopify.ClientID, url.QueryEscape(strings.Join(config.Values.Shopify.Scopes, ",")), url.QueryEscape(config.Values.Shopify.RedirectURL))
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return response.InternalServerError("Failed to generate OAuth state", err)
+	}
+	state := hex.EncodeToString(nonce)
+
+	// The callback only accepts the state of this browser's install, and only joins the shop to
+	// the session that started it
+	userID, _, _ := sessionUser(r)
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    state + "." + userID.String(),
+		HttpOnly: true,
+		Secure:   !config.IsTestOrDevelopment(),
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/v1/shopify/callback",
+		MaxAge:   int(stateTTL.Seconds()),
+	})
+
+	redirectURL := fmt.Sprintf("https://%s/admin/oauth/authorize?client_id=%s&scope=%s&redirect_uri=%s&state=%s",
+		normalizedShop, config.Values.Shopify.ClientID, url.QueryEscape(strings.Join(config.Values.Shopify.Scopes, ",")), url.QueryEscape(config.Values.Shopify.RedirectURL), state)
 
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 	return nil
@@ -47,6 +84,16 @@ func RequestCallback(shopifyManager *manager.ShopifyManager, syncManager *manage
 			return response.MissingParameter("code")
 		}
 
+		// The callback must come from Shopify, for the install this browser started
+		if !validHMAC(r.URL.Query(), config.Values.Shopify.ClientSecret) {
+			return response.BadRequest("Invalid OAuth callback signature", nil)
+		}
+		installUserID, ok := verifyState(r)
+		if !ok {
+			return response.BadRequest("Invalid OAuth state", nil)
+		}
+		http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/v1/shopify/callback", MaxAge: -1})
+
 		normalizedShop := shopify.NormalizeDomain(shop)
 		accessTokenURL := fmt.Sprintf("https://%s/admin/oauth/access_token", normalizedShop)
 
@@ -68,8 +115,12 @@ func RequestCallback(shopifyManager *manager.ShopifyManager, syncManager *manage
 			return response.InternalServerError("Failed to decode access token", err)
 		}
 
-		// Generate a user ID for this shop (you might want to get this from somewhere else)
-		userID := id.NewGeneration[id.User]()
+		// A shop connected from a signed in session joins the session's user and merchant account,
+		// as long as that session started the install, otherwise generate a user ID for this shop
+		userID, accountID, ok := sessionUser(r)
+		if !ok || userID != installUserID {
+			userID, accountID = id.NewGeneration[id.User](), ""
+		}
 
 		// Create or update the complete shopify integration
 		integration, err := shopifyManager.CreateOrUpdateShopifyIntegration(r.Context(), manager.CreateShopifyIntegrationParams{
@@ -77,6 +128,7 @@ func RequestCallback(shopifyManager *manager.ShopifyManager, syncManager *manage
 			ShopDomain:  normalizedShop,
 			AccessToken: tokenResp.AccessToken,
 			Scope:       strings.Join(config.Values.Shopify.Scopes, ","),
+			AccountID:   accountID,
 		})
 		if err != nil {
 			log.Printf("Failed to create or update shopify integration for shop %s: %v", shop, err)
@@ -84,7 +136,7 @@ func RequestCallback(shopifyManager *manager.ShopifyManager, syncManager *manage
 		}
 
 		// Generate JWT token for the authenticated user
-		jwtToken, err := auth.GenerateJWT(normalizedShop, integration.User.ID)
+		jwtToken, err := auth.GenerateJWT(normalizedShop, integration.User.ID, integration.Account.ID)
 		if err != nil {
 			return response.InternalServerError("Failed to generate JWT token", err)
 		}
@@ -95,6 +147,7 @@ func RequestCallback(shopifyManager *manager.ShopifyManager, syncManager *manage
 				UserID:     integration.User.ID,
 				ShopDomain: shop,
 				Force:      false, // Respect rate limiting
+				Trigger:    manager.SyncTriggerOAuth,
 			})
 			if syncErr != nil {
 				log.Printf("Failed to trigger initial sync for shop %s: %v", shop, syncErr)
@@ -118,3 +171,63 @@ func RequestCallback(shopifyManager *manager.ShopifyManager, syncManager *manage
 		return nil
 	}
 }
+
+// sessionUser returns the user and merchant account of the auth cookie of a signed in session
+func sessionUser(r *http.Request) (id.ID[id.User], id.ID[id.MerchantAccount], bool) {
+	cookie, err := r.Cookie("auth_token")
+	if err != nil {
+		return "", "", false
+	}
+	claims, err := auth.ValidateJWT(cookie.Value)
+	if err != nil {
+		return "", "", false
+	}
+
+	userID, err := id.New[id.User](claims.UserID)
+	if err != nil {
+		return "", "", false
+	}
+	// Tokens issued before accounts existed have no account
+	accountID, err := id.New[id.MerchantAccount](claims.AccountID)
+	if err != nil {
+		accountID = ""
+	}
+	return userID, accountID, true
+}
+
+// verifyState checks that the state of the callback is the nonce of the install this browser
+// started, and returns the user of the session that started it, empty when there was none
+func verifyState(r *http.Request) (id.ID[id.User], bool) {
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil {
+		return "", false
+	}
+	nonce, userID, _ := strings.Cut(cookie.Value, ".")
+	state := r.URL.Query().Get("state")
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(nonce), []byte(state)) != 1 {
+		return "", false
+	}
+	return id.ID[id.User](userID), true
+}
+
+// validHMAC checks the signature Shopify adds to the callback: the hex HMAC-SHA256, keyed with
+// the app's client secret, of the other query parameters sorted by name
+func validHMAC(query url.Values, secret string) bool {
+	signature, err := hex.DecodeString(query.Get("hmac"))
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+
+	params := make([]string, 0, len(query))
+	for key, values := range query {
+		if key == "hmac" || key == "signature" {
+			continue
+		}
+		params = append(params, key+"="+strings.Join(values, ","))
+	}
+	sort.Strings(params)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(params, "&")))
+	return hmac.Equal(mac.Sum(nil), signature)
+}
@@ -0,0 +1,69 @@
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestValidHMAC(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("code=abc&shop=test.myshopify.com&state=nonce&timestamp=1700000000"))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	query := url.Values{
+		"code":      {"abc"},
+		"shop":      {"test.myshopify.com"},
+		"state":     {"nonce"},
+		"timestamp": {"1700000000"},
+		"hmac":      {signature},
+	}
+	if !validHMAC(query, "secret") {
+		t.Error("callback signed by Shopify was rejected")
+	}
+	if validHMAC(query, "other") {
+		t.Error("callback signed with another secret was accepted")
+	}
+
+	query.Set("shop", "attacker.myshopify.com")
+	if validHMAC(query, "secret") {
+		t.Error("callback with a changed shop was accepted")
+	}
+
+	query.Del("hmac")
+	if validHMAC(query, "secret") {
+		t.Error("unsigned callback was accepted")
+	}
+}
+
+func TestVerifyState(t *testing.T) {
+	callback := func(state, cookie string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/v1/shopify/callback?state="+state, nil)
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: stateCookie, Value: cookie})
+		}
+		return r
+	}
+
+	if userID, ok := verifyState(callback("nonce", "nonce.user-1")); !ok || userID != "user-1" {
+		t.Errorf("matching state got %q, %v, want user-1", userID, ok)
+	}
+	if userID, ok := verifyState(callback("nonce", "nonce.")); !ok || userID != "" {
+		t.Errorf("state of a signed out install got %q, %v, want no user", userID, ok)
+	}
+
+	for name, r := range map[string]*http.Request{
+		"no cookie":   callback("nonce", ""),
+		"other nonce": callback("other", "nonce.user-1"),
+		"no state":    callback("", "nonce.user-1"),
+		"empty nonce": callback("", ".user-1"),
+	} {
+		if _, ok := verifyState(r); ok {
+			t.Errorf("%s was accepted, want it rejected", name)
+		}
+	}
+}
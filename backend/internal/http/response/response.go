@@ -11,6 +11,7 @@ const (
 	ErrorCodeBadRequest    = "BAD_REQUEST"
 	ErrorCodeUnauthorized  = "UNAUTHORIZED"
 	ErrorCodeNotFound      = "NOT_FOUND"
+	ErrorCodeConflict      = "CONFLICT"
 	ErrorCodeInternalError = "INTERNAL_ERROR"
 	ErrorCodeMissingParam  = "MISSING_PARAM"
 	ErrorCodeInvalidToken  = "INVALID_TOKEN"
@@ -64,6 +65,10 @@ func NotFound(message string, err error) *HTTPError {
 	return NewHTTPError(http.StatusNotFound, ErrorCodeNotFound, message, err)
 }
 
+func Conflict(message string, err error) *HTTPError {
+	return NewHTTPError(http.StatusConflict, ErrorCodeConflict, message, err)
+}
+
 func InternalServerError(message string, err error) *HTTPError {
 	return NewHTTPError(http.StatusInternalServerError, ErrorCodeInternalError, message, err)
 }
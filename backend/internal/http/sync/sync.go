@@ -2,33 +2,69 @@ package sync
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ConradKurth/forecasting/backend/internal/auth"
-	"github.com/ConradKurth/forecasting/backend/internal/db"
 	"github.com/ConradKurth/forecasting/backend/internal/http/response"
+	"github.com/ConradKurth/forecasting/backend/internal/interfaces"
 	"github.com/ConradKurth/forecasting/backend/internal/manager"
 	"github.com/ConradKurth/forecasting/backend/pkg/id"
 	"github.com/ConradKurth/forecasting/backend/pkg/logger"
 	shopifyutil "github.com/ConradKurth/forecasting/backend/pkg/shopify"
-	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
 	"github.com/go-chi/chi/v5"
 )
 
 // InitRoutes initializes sync-related routes
-func InitRoutes(r *chi.Mux, syncManager *manager.InventorySyncManager, database db.Database) {
+func InitRoutes(r *chi.Mux, syncManager *manager.InventorySyncManager, subscriber interfaces.SyncEventSubscriber) {
 	r.Route("/v1/sync", func(r chi.Router) {
-		r.Use(auth.AuthMiddleware)
-		r.Post("/trigger", response.Wrap(TriggerSync(syncManager)))
-		r.Get("/status/{shop_domain}", response.Wrap(GetSyncStatus(syncManager, database)))
+		r.Group(func(r chi.Router) {
+			r.Use(auth.AuthMiddleware)
+			r.Post("/trigger", response.Wrap(TriggerSync(syncManager)))
+			r.Post("/cancel", response.Wrap(CancelSync(syncManager)))
+			r.Get("/status", response.Wrap(GetSyncStatus(syncManager)))
+			r.Get("/status/{shop_domain}", response.Wrap(GetSyncStatus(syncManager)))
+			r.Get("/runs", response.Wrap(GetSyncRuns(syncManager)))
+			r.Post("/events/token", response.Wrap(CreateStreamToken()))
+		})
+
+		// EventSource cannot send an Authorization header, so the stream also accepts a stream
+		// token from /events/token in ?token=
+		r.With(auth.StreamAuthMiddleware).Get("/events", response.Wrap(StreamSyncEvents(syncManager, subscriber)))
+		r.With(auth.StreamAuthMiddleware).Get("/events/{shop_domain}", response.Wrap(StreamSyncEvents(syncManager, subscriber)))
 	})
 }
 
-// TriggerSyncRequest represents a sync trigger request
+const (
+	// Number of sync runs returned when no limit is given, and the most that can be requested
+	defaultSyncRunsLimit = 20
+	maxSyncRunsLimit     = 100
+
+	// Interval of the comments sent on an idle event stream so proxies keep the connection open
+	streamKeepAliveInterval = 15 * time.Second
+)
+
+// TriggerSyncRequest represents a sync trigger request. The shop domain can be left out when an
+// integration ID is given.
 type TriggerSyncRequest struct {
-	ShopDomain string `json:"shop_domain"`
+	ShopDomain string `json:"shop_domain,omitempty"`
 	Force      bool   `json:"force,omitempty"`
+	DryRun     bool   `json:"dry_run,omitempty"`
+
+	// IntegrationID selects a connected store other than the Shopify shop itself
+	IntegrationID string `json:"integration_id,omitempty"`
+}
+
+// CancelSyncRequest represents a sync cancel request. The shop domain can be left out when an
+// integration ID is given.
+type CancelSyncRequest struct {
+	ShopDomain string `json:"shop_domain,omitempty"`
+
+	// IntegrationID selects a connected store other than the Shopify shop itself
+	IntegrationID string `json:"integration_id,omitempty"`
 }
 
 // SyncStatusResponse represents the response for sync status
@@ -37,44 +73,45 @@ type SyncStatusResponse struct {
 	Status        string     `json:"status"`
 	LastSynced    *time.Time `json:"last_synced,omitempty"`
 	Error         string     `json:"error,omitempty"`
+
+	// RunID is the run of a started dry run, listed by /v1/sync/runs with its diff once it completed
+	RunID string `json:"run_id,omitempty"`
+}
+
+// SyncRunsResponse represents the response for sync run history
+type SyncRunsResponse struct {
+	Runs []manager.SyncRunResult `json:"runs"`
 }
 
 // TriggerSync triggers a sync for a shop
 // POST /v1/sync/trigger
 func TriggerSync(syncManager *manager.InventorySyncManager) response.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) error {
-		user, ok := auth.GetUserFromContext(r.Context())
-		if !ok {
-			return response.InternalServerError("User not found in context", nil)
-		}
-
-		userID, err := id.New[id.User](user.UserID)
-		if err != nil {
-			logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
-			return response.BadRequest("Invalid user ID", nil)
-		}
-
 		var req TriggerSyncRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			logger.Error("Failed to decode sync request", "error", err)
 			return response.BadRequest("Invalid request body", nil)
 		}
 
-		if req.ShopDomain == "" {
-			return response.BadRequest("shop_domain is required", nil)
+		target, err := readSyncTarget(r, req.ShopDomain, req.IntegrationID)
+		if err != nil {
+			return err
 		}
 
-		normalizedShopDomain := shopifyutil.NormalizeDomain(req.ShopDomain)
-
 		// Delegate to manager
 		result, err := syncManager.TriggerShopifySync(r.Context(), manager.SyncRequest{
-			UserID:     userID,
-			ShopDomain: normalizedShopDomain,
-			Force:      req.Force,
+			UserID:        target.userID,
+			ShopDomain:    target.shopDomain,
+			Force:         req.Force,
+			Trigger:       manager.SyncTriggerManual,
+			DryRun:        req.DryRun,
+			IntegrationID: target.integrationID,
 		})
 		if err != nil {
-			logger.Error("Sync trigger failed", "error", err, "user_id", userID, "shop_domain", req.ShopDomain)
-			return response.InternalServerError("Failed to trigger sync", err)
+			if errors.Is(err, manager.ErrNotSyncable) {
+				return response.BadRequest("Integration is updated by file imports, upload a new file instead", nil)
+			}
+			return syncError(err, "Failed to trigger sync", target)
 		}
 
 		return response.JSON(w, http.StatusOK, SyncStatusResponse{
@@ -82,53 +119,56 @@ func TriggerSync(syncManager *manager.InventorySyncManager) response.HandlerFunc
 			Status:        string(result.Status),
 			LastSynced:    result.LastSynced,
 			Error:         result.Error,
+			RunID:         result.RunID,
 		})
 	}
 }
 
-// GetSyncStatus gets the sync status for a shop
-// GET /v1/sync/status/{shop_domain}
-func GetSyncStatus(syncManager *manager.InventorySyncManager, database db.Database) response.HandlerFunc {
+// CancelSync cancels the in-progress sync of a shop, or of the integration given by integration_id
+// POST /v1/sync/cancel
+func CancelSync(syncManager *manager.InventorySyncManager) response.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) error {
-		user, ok := auth.GetUserFromContext(r.Context())
-		if !ok {
-			return response.InternalServerError("User not found in context", nil)
+		var req CancelSyncRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Error("Failed to decode cancel request", "error", err)
+			return response.BadRequest("Invalid request body", nil)
 		}
 
-		userID, err := id.New[id.User](user.UserID)
+		target, err := readSyncTarget(r, req.ShopDomain, req.IntegrationID)
 		if err != nil {
-			logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
-			return response.BadRequest("Invalid user ID", nil)
-		}
-
-		shopDomain := chi.URLParam(r, "shop_domain")
-		if shopDomain == "" {
-			return response.BadRequest("shop_domain is required", nil)
+			return err
 		}
 
-		normalizedShopDomain := shopifyutil.NormalizeDomain(shopDomain)
-
-		// Verify user has access to this shop
-		shop, err := database.GetShopify().GetShopifyStoreByDomain(r.Context(), normalizedShopDomain)
+		// Delegate to manager
+		result, err := syncManager.CancelSync(r.Context(), target.userID, target.shopDomain, target.integrationID)
 		if err != nil {
-			logger.Error("Shop not found", "error", err, "shop_domain", shopDomain)
-			return response.NotFound("Shop not found", nil)
+			if errors.Is(err, manager.ErrNoSyncInProgress) {
+				return response.Conflict("No sync in progress", nil)
+			}
+			return syncError(err, "Failed to cancel sync", target)
 		}
 
-		_, err = database.GetShopify().GetShopifyUserByUserAndStore(r.Context(), shopify.GetShopifyUserByUserAndStoreParams{
-			UserID:         userID,
-			ShopifyStoreID: shop.ID,
+		return response.JSON(w, http.StatusOK, SyncStatusResponse{
+			IntegrationID: result.IntegrationID,
+			Status:        string(result.Status),
 		})
+	}
+}
+
+// GetSyncStatus gets the sync status for a shop, or for the integration given by integration_id
+// GET /v1/sync/status/{shop_domain}?integration_id={integration_id}
+// GET /v1/sync/status?integration_id={integration_id}
+func GetSyncStatus(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		target, err := readSyncTarget(r, chi.URLParam(r, "shop_domain"), r.URL.Query().Get("integration_id"))
 		if err != nil {
-			logger.Error("User does not have access to shop", "error", err, "user_id", userID, "shop_domain", shopDomain)
-			return response.Unauthorized("Access denied to this shop", nil)
+			return err
 		}
 
 		// Delegate to manager
-		result, err := syncManager.GetSyncStatus(r.Context(), userID, normalizedShopDomain)
+		result, err := syncManager.GetSyncStatus(r.Context(), target.userID, target.shopDomain, target.integrationID)
 		if err != nil {
-			logger.Error("Failed to get sync status", "error", err, "user_id", userID, "shop_domain", shopDomain)
-			return response.InternalServerError("Failed to get sync status", err)
+			return syncError(err, "Failed to get sync status", target)
 		}
 
 		return response.JSON(w, http.StatusOK, SyncStatusResponse{
@@ -139,3 +179,208 @@ func GetSyncStatus(syncManager *manager.InventorySyncManager, database db.Databa
 		})
 	}
 }
+
+// GetSyncRuns lists the most recent sync runs for a shop, or for the integration given by
+// integration_id, newest first
+// GET /v1/sync/runs?shop_domain={shop_domain}&integration_id={integration_id}&limit={limit}
+func GetSyncRuns(syncManager *manager.InventorySyncManager) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		target, err := readSyncTarget(r, r.URL.Query().Get("shop_domain"), r.URL.Query().Get("integration_id"))
+		if err != nil {
+			return err
+		}
+
+		limit := defaultSyncRunsLimit
+		if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+			limit, err = strconv.Atoi(rawLimit)
+			if err != nil || limit <= 0 || limit > maxSyncRunsLimit {
+				return response.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxSyncRunsLimit), nil)
+			}
+		}
+
+		// Delegate to manager
+		runs, err := syncManager.GetSyncRuns(r.Context(), target.userID, target.shopDomain, target.integrationID, int32(limit))
+		if err != nil {
+			return syncError(err, "Failed to get sync runs", target)
+		}
+
+		return response.JSON(w, http.StatusOK, SyncRunsResponse{Runs: runs})
+	}
+}
+
+// StreamTokenResponse represents a token that opens event streams of the session's user
+type StreamTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"` // Seconds the token can be used to open a stream
+}
+
+// CreateStreamToken issues a short-lived token for the event stream, which browsers must pass in
+// the URL. The session token itself is not accepted there.
+// POST /v1/sync/events/token
+func CreateStreamToken() response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		user, ok := auth.GetUserFromContext(r.Context())
+		if !ok {
+			return response.InternalServerError("User not found in context", nil)
+		}
+
+		token, err := auth.GenerateStreamJWT(user)
+		if err != nil {
+			logger.Error("Failed to generate stream token", "error", err, "user_id", user.UserID)
+			return response.InternalServerError("Failed to generate stream token", err)
+		}
+
+		return response.JSON(w, http.StatusOK, StreamTokenResponse{
+			Token:     token,
+			ExpiresIn: int(auth.StreamTokenTTL.Seconds()),
+		})
+	}
+}
+
+// StreamSyncEvents streams a shop's live sync progress as Server-Sent Events, or that of the
+// integration given by integration_id.
+// The stream starts with a "status" event holding the current sync status, followed by
+// the sync events published while the connection is open.
+// GET /v1/sync/events/{shop_domain}?integration_id={integration_id}&token=<stream token>
+// GET /v1/sync/events?integration_id={integration_id}&token=<stream token>
+func StreamSyncEvents(syncManager *manager.InventorySyncManager, subscriber interfaces.SyncEventSubscriber) response.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		target, err := readSyncTarget(r, chi.URLParam(r, "shop_domain"), r.URL.Query().Get("integration_id"))
+		if err != nil {
+			return err
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return response.InternalServerError("Streaming is not supported", nil)
+		}
+
+		// The manager checks the user's access to the shop or integration
+		status, err := syncManager.GetSyncStatus(r.Context(), target.userID, target.shopDomain, target.integrationID)
+		if err != nil {
+			return syncError(err, "Failed to get sync status", target)
+		}
+		if status.IntegrationID == "" {
+			return response.NotFound("Shop has no integration to sync", nil)
+		}
+
+		integrationID, err := id.New[id.PlatformIntegration](status.IntegrationID)
+		if err != nil {
+			return response.InternalServerError("Invalid integration ID", err)
+		}
+
+		// Subscribe before sending the status so no event between the two is lost
+		events, err := subscriber.SubscribeSyncEvents(r.Context(), integrationID)
+		if err != nil {
+			logger.Error("Failed to subscribe to sync events", "error", err, "integration_id", integrationID)
+			return response.InternalServerError("Failed to subscribe to sync events", err)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if err := writeServerSentEvent(w, "status", SyncStatusResponse{
+			IntegrationID: status.IntegrationID,
+			Status:        string(status.Status),
+			LastSynced:    status.LastSynced,
+			Error:         status.Error,
+		}); err != nil {
+			return nil
+		}
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(streamKeepAliveInterval)
+		defer keepAlive.Stop()
+
+		// The response has started, so from here on errors just end the stream
+		for {
+			select {
+			case <-r.Context().Done():
+				return nil
+			case event, ok := <-events:
+				if !ok {
+					return nil
+				}
+				if err := writeServerSentEvent(w, event.Type, event); err != nil {
+					logger.Debug("Sync event stream closed", "error", err, "integration_id", integrationID)
+					return nil
+				}
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return nil
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// syncTarget is the user of a sync request and the shop or integration it is for
+type syncTarget struct {
+	userID        id.ID[id.User]
+	shopDomain    string
+	integrationID id.ID[id.PlatformIntegration]
+}
+
+// readSyncTarget reads the user from the request context and the shop domain and integration ID
+// given with the request, at least one of which is required. Whether the user has access to the
+// shop or integration is checked by the manager, see syncError.
+func readSyncTarget(r *http.Request, shopDomain, rawIntegrationID string) (syncTarget, error) {
+	user, ok := auth.GetUserFromContext(r.Context())
+	if !ok {
+		return syncTarget{}, response.InternalServerError("User not found in context", nil)
+	}
+
+	userID, err := id.New[id.User](user.UserID)
+	if err != nil {
+		logger.Error("Invalid user ID", "user_id", user.UserID, "error", err)
+		return syncTarget{}, response.BadRequest("Invalid user ID", nil)
+	}
+
+	target := syncTarget{userID: userID}
+	if rawIntegrationID != "" {
+		target.integrationID, err = id.New[id.PlatformIntegration](rawIntegrationID)
+		if err != nil {
+			return syncTarget{}, response.BadRequest("Invalid integration_id", nil)
+		}
+	}
+
+	if shopDomain == "" && target.integrationID == "" {
+		return syncTarget{}, response.BadRequest("shop_domain or integration_id is required", nil)
+	}
+	if shopDomain != "" {
+		target.shopDomain = shopifyutil.NormalizeDomain(shopDomain)
+	}
+
+	return target, nil
+}
+
+// syncError maps the access errors of the sync manager to responses, and any other error to an
+// internal server error with the given message
+func syncError(err error, message string, target syncTarget) error {
+	switch {
+	case errors.Is(err, manager.ErrShopNotFound):
+		return response.NotFound("Shop not found", nil)
+	case errors.Is(err, manager.ErrShopAccessDenied):
+		return response.Unauthorized("Access denied to this shop", nil)
+	case errors.Is(err, manager.ErrIntegrationNotFound):
+		return response.NotFound("Integration not found", nil)
+	}
+
+	logger.Error(message, "error", err, "user_id", target.userID, "shop_domain", target.shopDomain, "integration_id", target.integrationID)
+	return response.InternalServerError(message, err)
+}
+
+// writeServerSentEvent writes a single named event with a JSON payload
+func writeServerSentEvent(w http.ResponseWriter, name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+	return err
+}
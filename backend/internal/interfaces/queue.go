@@ -2,22 +2,45 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
+	"github.com/ConradKurth/forecasting/backend/internal/events"
 	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/pkg/errors"
 )
 
+// ErrTaskAlreadyQueued is returned when enqueueing a sync that is already waiting to run or running
+var ErrTaskAlreadyQueued = errors.New("task already queued")
+
 // Queue represents a job queue interface for enqueueing background tasks
 type Queue interface {
 	// EnqueueShopifyStoreSync enqueues a Shopify store sync task
 	EnqueueShopifyStoreSync(ctx context.Context, userID, shopID, token string) error
 
-	// EnqueueShopifyInventorySync enqueues a Shopify inventory sync task
-	EnqueueShopifyInventorySync(ctx context.Context, integrationID, shopDomain, accessToken string) error
+	// EnqueueShopifyInventorySync enqueues a Shopify inventory sync task, trigger records what started it
+	EnqueueShopifyInventorySync(ctx context.Context, integrationID, shopDomain, accessToken, trigger string) error
+
+	// EnqueueSyncDryRun enqueues the dry run recorded as the given sync run of an integration
+	EnqueueSyncDryRun(ctx context.Context, integrationID id.ID[id.PlatformIntegration], runID id.ID[id.SyncRun]) error
+
+	// CancelInventorySyncs signals the given running sync tasks to stop and deletes the integration's queued sync tasks
+	CancelInventorySyncs(ctx context.Context, integrationID string, activeTaskIDs []string) error
+
+	// ListInventorySyncs lists the sync tasks that are running or waiting to run
+	ListInventorySyncs(ctx context.Context) ([]SyncTask, error)
 
 	// Close closes the queue client connection
 	Close() error
 }
 
+// SyncTask identifies a sync task in the queue
+type SyncTask struct {
+	IntegrationID id.ID[id.PlatformIntegration]
+
+	// Entity is the sync state the task reports to: "full_sync", "location", "product" or "order"
+	Entity string
+}
+
 // ShopifyManager interface defines what the worker needs from a Shopify manager
 type ShopifyManager interface {
 	// SyncStoreInfo updates store information by fetching from Shopify API
@@ -27,5 +50,44 @@ type ShopifyManager interface {
 // InventorySyncManager interface defines what the worker needs from an inventory sync manager
 type InventorySyncManager interface {
 	// SyncInventory performs inventory synchronization
-	SyncInventory(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) error
+	SyncInventory(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error
+
+	// SyncLocations synchronizes only locations
+	SyncLocations(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error
+
+	// SyncProducts synchronizes only products, variants and inventory items
+	SyncProducts(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error
+
+	// SyncOrders synchronizes only recent orders
+	SyncOrders(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error
+
+	// RunSyncDryRun compares the platform's data with the integration's rows and records the diff on the run
+	RunSyncDryRun(ctx context.Context, integrationID id.ID[id.PlatformIntegration], runID id.ID[id.SyncRun]) error
+
+	// ReapStaleSyncs fails sync states stuck in_progress after their sync stopped sending heartbeats
+	ReapStaleSyncs(ctx context.Context) error
+}
+
+// SyncEventPublisher interface defines how sync progress is broadcast to listeners
+type SyncEventPublisher interface {
+	// PublishSyncEvent publishes a progress event of an integration's sync
+	PublishSyncEvent(ctx context.Context, integrationID id.ID[id.PlatformIntegration], event events.SyncEvent) error
+}
+
+// SyncEventSubscriber interface defines how sync progress is received by listeners
+type SyncEventSubscriber interface {
+	// SubscribeSyncEvents streams an integration's sync events until ctx is done
+	SubscribeSyncEvents(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (<-chan events.SyncEvent, error)
+}
+
+// SyncSchedule is how often one integration is synced by the scheduler
+type SyncSchedule struct {
+	IntegrationID id.ID[id.PlatformIntegration]
+	Interval      time.Duration
+}
+
+// SyncScheduleProvider interface defines what the scheduler needs to know about periodic syncs
+type SyncScheduleProvider interface {
+	// GetSyncSchedules returns the schedule of every active integration with periodic syncs enabled
+	GetSyncSchedules(ctx context.Context) ([]SyncSchedule, error)
 }
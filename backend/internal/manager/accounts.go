@@ -0,0 +1,518 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// Sales window of the account SKU view when none is given, and the longest one allowed
+const (
+	DefaultAccountSalesDays = 30
+	MaxAccountSalesDays     = 365
+)
+
+var (
+	// ErrAccountNotFound is returned when an account does not exist or the user is not one of its users
+	ErrAccountNotFound = errors.New("merchant account not found")
+
+	// ErrShopAccessDenied is returned when using or linking a shop the user has not connected
+	ErrShopAccessDenied = errors.New("user does not have access to shop")
+)
+
+// AccountManager manages merchant accounts, which group the shops of one merchant, and the views
+// that aggregate the catalog, stock and sales of every integration of an account.
+//
+// A shop joins an account when it is first connected: the account of the session it was
+// connected from, or a new account of its own. Variants of different integrations are matched
+// by SKU, so the same SKU sold on two Shopify stores and a Square point of sale is one row of
+// the account views.
+type AccountManager struct {
+	database db.Database
+}
+
+// NewAccountManager creates a new AccountManager instance
+func NewAccountManager(database db.Database) *AccountManager {
+	return &AccountManager{
+		database: database,
+	}
+}
+
+// AccountRequest identifies the account of a session. Sessions started before accounts existed
+// have no AccountID, and use the account of their shop.
+type AccountRequest struct {
+	UserID     id.ID[id.User]            `json:"user_id"`
+	AccountID  id.ID[id.MerchantAccount] `json:"account_id,omitempty"`
+	ShopDomain string                    `json:"shop_domain"`
+}
+
+// AccountResult represents a merchant account with its shops and their integrations
+type AccountResult struct {
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	ReportingCurrency string              `json:"reporting_currency"`
+	Shops             []AccountShop       `json:"shops"`
+	Integrations      []IntegrationResult `json:"integrations"`
+}
+
+// AccountShop represents a shop of an account
+type AccountShop struct {
+	ID         string    `json:"id"`
+	ShopDomain string    `json:"shop_domain"`
+	ShopName   string    `json:"shop_name,omitempty"`
+	LinkedAt   time.Time `json:"linked_at"`
+}
+
+// LinkShopRequest represents a request to move another shop of the user into the session's account
+type LinkShopRequest struct {
+	AccountRequest
+	LinkShopDomain string `json:"link_shop_domain"`
+}
+
+// SetReportingCurrencyRequest represents a request to change the currency an account's views
+// convert revenue and stock value into
+type SetReportingCurrencyRequest struct {
+	AccountRequest
+	ReportingCurrency string `json:"reporting_currency"`
+}
+
+// AccountSKUsRequest represents a request for the SKU view of an account
+type AccountSKUsRequest struct {
+	AccountRequest
+	Days int `json:"days"`
+}
+
+// AccountSKU is the stock and recent sales of a SKU across every channel of an account. Revenue
+// and stock value are in the account's reporting currency.
+type AccountSKU struct {
+	SKU        string  `json:"sku"`
+	Title      string  `json:"title"`
+	Available  int64   `json:"available"`
+	UnitsSold  int64   `json:"units_sold"`
+	DailyUnits float64 `json:"daily_units"`
+	Revenue    float64 `json:"revenue"`
+	StockValue float64 `json:"stock_value"`
+
+	// DaysOfCover is how long the stock lasts at the current daily units, nil when nothing sold
+	DaysOfCover *float64 `json:"days_of_cover,omitempty"`
+
+	Channels []AccountSKUChannel `json:"channels"`
+}
+
+// AccountSKUChannel is the stock and recent sales of a SKU on one integration. Revenue (sold
+// units at their line price) and stock value (available units at cost) are in the currency of
+// the integration, empty while it is not known.
+type AccountSKUChannel struct {
+	IntegrationID string            `json:"integration_id"`
+	PlatformType  core.PlatformType `json:"platform_type"`
+	Title         string            `json:"title,omitempty"`
+	Available     int64             `json:"available"`
+	UnitsSold     int64             `json:"units_sold"`
+	OrdersCount   int64             `json:"orders_count"`
+	Currency      string            `json:"currency"`
+	Revenue       float64           `json:"revenue"`
+	StockValue    float64           `json:"stock_value"`
+}
+
+// AccountSKUsResult represents the SKU view of an account. Currency is the reporting currency of
+// the totals; MissingRates lists the currencies of channels left out of them for lack of a rate,
+// or "unknown" for channels whose currency is not known. Every amount, revenue of past orders
+// included, is converted at the latest rates on RatesDate.
+type AccountSKUsResult struct {
+	AccountID    string       `json:"account_id"`
+	Days         int          `json:"days"`
+	Since        time.Time    `json:"since"`
+	Currency     string       `json:"currency"`
+	RatesDate    time.Time    `json:"rates_date"`
+	MissingRates []string     `json:"missing_rates"`
+	SKUs         []AccountSKU `json:"skus"`
+}
+
+// GetAccount gets the session's account with its shops and integrations
+func (m *AccountManager) GetAccount(ctx context.Context, req AccountRequest) (*AccountResult, error) {
+	account, err := m.resolveAccount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return m.accountResult(ctx, account)
+}
+
+// ListAccounts lists the accounts the user has access to
+func (m *AccountManager) ListAccounts(ctx context.Context, userID id.ID[id.User]) ([]AccountResult, error) {
+	accounts, err := m.database.GetCore().GetMerchantAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant accounts")
+	}
+
+	results := make([]AccountResult, 0, len(accounts))
+	for _, account := range accounts {
+		result, err := m.accountResult(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// LinkShop moves another shop the user has connected into the session's account, with all of
+// its integrations. A shop belongs to one account, so it leaves the account it was in.
+func (m *AccountManager) LinkShop(ctx context.Context, req LinkShopRequest) (*AccountResult, error) {
+	account, err := m.resolveAccount(ctx, req.AccountRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	shop, err := m.database.GetShopify().GetShopifyStoreByDomain(ctx, req.LinkShopDomain)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrShopAccessDenied
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get shop")
+	}
+	_, err = m.database.GetShopify().GetShopifyUserByUserAndStore(ctx, shopify.GetShopifyUserByUserAndStoreParams{
+		UserID:         req.UserID,
+		ShopifyStoreID: shop.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrShopAccessDenied
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get shopify user")
+	}
+
+	err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		return setShopAccount(ctx, tx.GetCore(), shop.ID, account.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Shop linked to merchant account", "user_id", req.UserID, "account_id", account.ID, "shop_domain", shop.ShopDomain)
+	return m.accountResult(ctx, account)
+}
+
+// SetReportingCurrency changes the currency the account's views convert revenue and stock value
+// into. A code that is not ISO 4217 returns currency.ErrInvalidCode.
+func (m *AccountManager) SetReportingCurrency(ctx context.Context, req SetReportingCurrencyRequest) (*AccountResult, error) {
+	code, err := currency.Normalize(req.ReportingCurrency)
+	if err != nil {
+		return nil, err
+	}
+	account, err := m.resolveAccount(ctx, req.AccountRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err = m.database.GetCore().SetMerchantAccountReportingCurrency(ctx, core.SetMerchantAccountReportingCurrencyParams{
+		ID:                account.ID,
+		ReportingCurrency: code,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set reporting currency")
+	}
+
+	logger.Info("Merchant account reporting currency set", "user_id", req.UserID, "account_id", account.ID, "currency", code)
+	return m.accountResult(ctx, account)
+}
+
+// GetAccountSKUs gets the stock and the sales of the last days of every SKU of the account,
+// summed across its integrations. Variants are matched by their exact SKU; variants without a
+// SKU cannot be matched and are left out. Revenue and stock value are converted into the
+// account's reporting currency with the latest exchange rates, revenue of past orders included;
+// channels whose currency is not known are left out of the converted totals.
+func (m *AccountManager) GetAccountSKUs(ctx context.Context, req AccountSKUsRequest) (*AccountSKUsResult, error) {
+	account, err := m.resolveAccount(ctx, req.AccountRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	days, since := salesWindow(req.Days)
+	inventory, err := m.database.GetCore().GetMerchantAccountInventoryBySKU(ctx, account.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account inventory")
+	}
+	sales, err := m.database.GetCore().GetMerchantAccountSalesBySKU(ctx, core.GetMerchantAccountSalesBySKUParams{
+		AccountID: account.ID,
+		CreatedAt: pgtype.Timestamp{Time: since, Valid: true},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account sales")
+	}
+	conversion, err := newReportingConversion(ctx, m.database.GetCore(), account)
+	if err != nil {
+		return nil, err
+	}
+
+	skus := mergeAccountSKUs(inventory, sales, days, conversion)
+	return &AccountSKUsResult{
+		AccountID:    account.ID.String(),
+		Days:         days,
+		Since:        since,
+		Currency:     account.ReportingCurrency,
+		RatesDate:    conversion.asOf,
+		MissingRates: conversion.missingRates(),
+		SKUs:         skus,
+	}, nil
+}
+
+// mergeAccountSKUs merges the per-integration stock and sales rows, both ordered by SKU and
+// integration, into one row per SKU
+func mergeAccountSKUs(inventory []core.GetMerchantAccountInventoryBySKURow, sales []core.GetMerchantAccountSalesBySKURow, days int, conversion *reportingConversion) []AccountSKU {
+	skus := []AccountSKU{}
+	index := make(map[string]int)
+	channel := func(sku string, integrationID id.ID[id.PlatformIntegration], platformType core.PlatformType, integrationCurrency pgtype.Text) *AccountSKUChannel {
+		i, ok := index[sku]
+		if !ok {
+			i = len(skus)
+			index[sku] = i
+			skus = append(skus, AccountSKU{SKU: sku})
+		}
+		for j := range skus[i].Channels {
+			if skus[i].Channels[j].IntegrationID == integrationID.String() {
+				return &skus[i].Channels[j]
+			}
+		}
+		skus[i].Channels = append(skus[i].Channels, AccountSKUChannel{
+			IntegrationID: integrationID.String(),
+			PlatformType:  platformType,
+			Currency:      conversion.currencyOf(integrationCurrency),
+		})
+		return &skus[i].Channels[len(skus[i].Channels)-1]
+	}
+
+	for _, row := range inventory {
+		c := channel(row.Sku, row.IntegrationID, row.PlatformType, row.Currency)
+		c.Title, c.Available, c.StockValue = row.Title, row.Available, roundAmount(row.StockValue)
+	}
+	for _, row := range sales {
+		c := channel(row.Sku, row.IntegrationID, row.PlatformType, row.Currency)
+		c.UnitsSold, c.OrdersCount, c.Revenue = row.UnitsSold, row.OrdersCount, roundAmount(row.Revenue)
+	}
+
+	for i := range skus {
+		sku := &skus[i]
+		for _, c := range sku.Channels {
+			if sku.Title == "" {
+				sku.Title = c.Title
+			}
+			sku.Available += c.Available
+			sku.UnitsSold += c.UnitsSold
+			sku.Revenue += conversion.convert(c.Revenue, c.Currency)
+			sku.StockValue += conversion.convert(c.StockValue, c.Currency)
+		}
+		sku.Revenue, sku.StockValue = roundAmount(sku.Revenue), roundAmount(sku.StockValue)
+		sku.DailyUnits, sku.DaysOfCover = salesRate(sku.Available, sku.UnitsSold, days)
+	}
+	return skus
+}
+
+// salesWindow returns the days of sales of an account view, the default when none are given,
+// and when they start
+func salesWindow(days int) (int, time.Time) {
+	if days <= 0 {
+		days = DefaultAccountSalesDays
+	}
+	return days, time.Now().UTC().AddDate(0, 0, -days)
+}
+
+// salesRate returns the daily units sold over a number of days, and how many days the stock
+// lasts at that rate, nil when nothing sold
+func salesRate(available, unitsSold int64, days int) (float64, *float64) {
+	dailyUnits := float64(unitsSold) / float64(days)
+	if dailyUnits <= 0 {
+		return dailyUnits, nil
+	}
+	cover := float64(max(available, 0)) / dailyUnits
+	return dailyUnits, &cover
+}
+
+// resolveAccount gets the account of a session, checking the user is one of its users
+func (m *AccountManager) resolveAccount(ctx context.Context, req AccountRequest) (core.MerchantAccount, error) {
+	if req.AccountID == "" {
+		return m.shopAccount(ctx, req.UserID, req.ShopDomain)
+	}
+
+	isUser, err := m.database.GetCore().IsMerchantAccountUser(ctx, core.IsMerchantAccountUserParams{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+	})
+	if err != nil {
+		return core.MerchantAccount{}, errors.Wrap(err, "failed to check account user")
+	}
+	if !isUser {
+		return core.MerchantAccount{}, ErrAccountNotFound
+	}
+
+	account, err := m.database.GetCore().GetMerchantAccountByID(ctx, req.AccountID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return core.MerchantAccount{}, ErrAccountNotFound
+	}
+	return account, errors.Wrap(err, "failed to get merchant account")
+}
+
+// shopAccount gets the account of a shop the user has access to, creating it for shops
+// connected before accounts existed
+func (m *AccountManager) shopAccount(ctx context.Context, userID id.ID[id.User], shopDomain string) (core.MerchantAccount, error) {
+	shop, err := m.database.GetShopify().GetShopifyStoreByDomain(ctx, shopDomain)
+	if err != nil {
+		return core.MerchantAccount{}, ErrAccountNotFound
+	}
+	_, err = m.database.GetShopify().GetShopifyUserByUserAndStore(ctx, shopify.GetShopifyUserByUserAndStoreParams{
+		UserID:         userID,
+		ShopifyStoreID: shop.ID,
+	})
+	if err != nil {
+		return core.MerchantAccount{}, ErrAccountNotFound
+	}
+
+	var account core.MerchantAccount
+	err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		account, err = ensureShopAccount(ctx, tx.GetCore(), shop, userID, "")
+		return err
+	})
+	return account, err
+}
+
+// accountResult converts an account with its shops and their integrations
+func (m *AccountManager) accountResult(ctx context.Context, account core.MerchantAccount) (*AccountResult, error) {
+	shops, err := m.database.GetCore().GetMerchantAccountShops(ctx, account.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account shops")
+	}
+	integrations, err := m.database.GetCore().GetPlatformIntegrationsByAccountID(ctx, account.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account integrations")
+	}
+
+	result := &AccountResult{
+		ID:                account.ID.String(),
+		Name:              account.Name,
+		ReportingCurrency: account.ReportingCurrency,
+		Shops:             make([]AccountShop, 0, len(shops)),
+		Integrations:      make([]IntegrationResult, 0, len(integrations)),
+	}
+	for _, shop := range shops {
+		result.Shops = append(result.Shops, AccountShop{
+			ID:         shop.ID.String(),
+			ShopDomain: shop.ShopDomain,
+			ShopName:   shop.ShopName.String,
+			LinkedAt:   shop.LinkedAt.Time,
+		})
+	}
+	for _, integration := range integrations {
+		result.Integrations = append(result.Integrations, toIntegrationResult(integration))
+	}
+	return result, nil
+}
+
+// ensureShopAccount gets the account of a shop, adding the user to it. A shop without an account
+// joins the preferred account when the user is one of its users, or gets a new account of its own.
+func ensureShopAccount(ctx context.Context, queries core.Querier, shop shopify.ShopifyStore, userID id.ID[id.User], preferred id.ID[id.MerchantAccount]) (core.MerchantAccount, error) {
+	account, err := queries.GetMerchantAccountByShopID(ctx, shop.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		account, err = newShopAccount(ctx, queries, shop, userID, preferred)
+	}
+	if err != nil {
+		return core.MerchantAccount{}, errors.Wrap(err, "failed to get shop account")
+	}
+
+	err = queries.AddMerchantAccountUser(ctx, core.AddMerchantAccountUserParams{
+		AccountID: account.ID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return core.MerchantAccount{}, errors.Wrap(err, "failed to add account user")
+	}
+	return account, nil
+}
+
+// newShopAccount links a shop without an account to the preferred account, or to a new one
+// reporting in the shop's currency
+func newShopAccount(ctx context.Context, queries core.Querier, shop shopify.ShopifyStore, userID id.ID[id.User], preferred id.ID[id.MerchantAccount]) (core.MerchantAccount, error) {
+	account, err := preferredAccount(ctx, queries, userID, preferred)
+	if err != nil {
+		return core.MerchantAccount{}, err
+	}
+
+	if account.ID == "" {
+		name := shop.ShopName.String
+		if name == "" {
+			name = shop.ShopDomain
+		}
+		reportingCurrency, err := currency.Normalize(shop.Currency.String)
+		if err != nil {
+			reportingCurrency = DefaultReportingCurrency
+		}
+		account, err = queries.CreateMerchantAccount(ctx, core.CreateMerchantAccountParams{
+			ID:                id.NewGeneration[id.MerchantAccount](),
+			Name:              name,
+			ReportingCurrency: reportingCurrency,
+		})
+		if err != nil {
+			return core.MerchantAccount{}, errors.Wrap(err, "failed to create merchant account")
+		}
+	}
+
+	if err := setShopAccount(ctx, queries, shop.ID, account.ID); err != nil {
+		return core.MerchantAccount{}, err
+	}
+	return account, nil
+}
+
+// setShopAccount links a shop to an account and moves the shop's integrations into it
+func setShopAccount(ctx context.Context, queries core.Querier, shopID id.ID[id.ShopifyStore], accountID id.ID[id.MerchantAccount]) error {
+	_, err := queries.SetMerchantAccountShop(ctx, core.SetMerchantAccountShopParams{
+		ShopID:    shopID,
+		AccountID: accountID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to link shop")
+	}
+
+	err = queries.SetPlatformIntegrationsAccountByShopID(ctx, core.SetPlatformIntegrationsAccountByShopIDParams{
+		ShopID:    shopID,
+		AccountID: accountID,
+	})
+	return errors.Wrap(err, "failed to move shop integrations")
+}
+
+// shopAccountID gets the ID of the account of a shop, empty for shops connected before accounts
+// existed that have not joined one yet
+func shopAccountID(ctx context.Context, queries core.Querier, shopID id.ID[id.ShopifyStore]) (id.ID[id.MerchantAccount], error) {
+	account, err := queries.GetMerchantAccountByShopID(ctx, shopID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get shop account")
+	}
+	return account.ID, nil
+}
+
+// preferredAccount gets the preferred account when the user is one of its users, or an empty account
+func preferredAccount(ctx context.Context, queries core.Querier, userID id.ID[id.User], preferred id.ID[id.MerchantAccount]) (core.MerchantAccount, error) {
+	if preferred == "" {
+		return core.MerchantAccount{}, nil
+	}
+
+	isUser, err := queries.IsMerchantAccountUser(ctx, core.IsMerchantAccountUserParams{AccountID: preferred, UserID: userID})
+	if err != nil {
+		return core.MerchantAccount{}, errors.Wrap(err, "failed to check account user")
+	}
+	if !isUser {
+		return core.MerchantAccount{}, nil
+	}
+
+	account, err := queries.GetMerchantAccountByID(ctx, preferred)
+	return account, errors.Wrap(err, "failed to get merchant account")
+}
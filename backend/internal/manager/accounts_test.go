@@ -0,0 +1,409 @@
+package manager
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/fileimport"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce/woocommercetest"
+	"github.com/ConradKurth/forecasting/backend/internal/crypto"
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/pkg/errors"
+)
+
+const testOtherShopDomain = "other-shop.myshopify.com"
+
+// addShop creates another shop the test user has connected
+func (st *syncTest) addShop(t *testing.T, shopDomain string) id.ID[id.ShopifyStore] {
+	t.Helper()
+
+	shopID := id.NewGeneration[id.ShopifyStore]()
+	if _, err := st.db.CreateShopifyStore(st.ctx, shopify.CreateShopifyStoreParams{ID: shopID, ShopDomain: shopDomain}); err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	if _, err := st.db.CreateShopifyUser(st.ctx, shopify.CreateShopifyUserParams{
+		ID:             id.NewGeneration[id.ShopifyUser](),
+		UserID:         st.userID,
+		ShopifyStoreID: shopID,
+		AccessToken:    crypto.EncryptedSecret(testAccessToken),
+	}); err != nil {
+		t.Fatalf("create shopify user: %v", err)
+	}
+	return shopID
+}
+
+// account gets the account of a shop of the test user
+func (st *syncTest) account(t *testing.T, accounts *AccountManager, shopDomain string) *AccountResult {
+	t.Helper()
+
+	account, err := accounts.GetAccount(st.ctx, AccountRequest{UserID: st.userID, ShopDomain: shopDomain})
+	if err != nil {
+		t.Fatalf("GetAccount %s: %v", shopDomain, err)
+	}
+	return account
+}
+
+// login connects a store through the Shopify OAuth flow from a session of the account
+func (st *syncTest) login(t *testing.T, shopDomain string, accountID id.ID[id.MerchantAccount]) *ShopifyIntegration {
+	t.Helper()
+
+	integration, err := NewShopifyManager(st.db, st.queue).CreateOrUpdateShopifyIntegration(st.ctx, CreateShopifyIntegrationParams{
+		UserID:      st.userID,
+		ShopDomain:  shopDomain,
+		AccessToken: testAccessToken,
+		Scope:       "read_products",
+		AccountID:   accountID,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateShopifyIntegration %s: %v", shopDomain, err)
+	}
+	return integration
+}
+
+// importFiles imports the products, inventory and sales files of a shop, in that order
+func (st *syncTest) importFiles(t *testing.T, shopDomain string, files [3]string) {
+	t.Helper()
+
+	for i, kind := range []fileimport.Kind{fileimport.KindProducts, fileimport.KindInventory, fileimport.KindSales} {
+		_, err := st.manager.ImportFile(st.ctx, ImportFileRequest{
+			UserID:     st.userID,
+			ShopDomain: shopDomain,
+			Kind:       kind,
+			FileName:   string(kind) + ".csv",
+			Content:    strings.NewReader(files[i]),
+		})
+		if err != nil {
+			t.Fatalf("ImportFile %s %s: %v", shopDomain, kind, err)
+		}
+	}
+}
+
+func TestShopifyLoginJoinsTheSessionAccount(t *testing.T) {
+	st := newSyncTest(t)
+
+	first := st.login(t, "first.myshopify.com", "")
+	if first.Account.Name != "first.myshopify.com" {
+		t.Errorf("account = %+v, want a new account named after the shop", first.Account)
+	}
+	second := st.login(t, "second.myshopify.com", first.Account.ID)
+	if second.Account.ID != first.Account.ID {
+		t.Errorf("second shop account = %s, want the session account %s", second.Account.ID, first.Account.ID)
+	}
+
+	// An account the user is not a user of is ignored
+	stranger, err := st.db.CreateMerchantAccount(st.ctx, core.CreateMerchantAccountParams{ID: id.NewGeneration[id.MerchantAccount](), Name: "Stranger"})
+	if err != nil {
+		t.Fatalf("CreateMerchantAccount: %v", err)
+	}
+	third := st.login(t, "third.myshopify.com", stranger.ID)
+	if third.Account.ID == stranger.ID || third.Account.ID == first.Account.ID {
+		t.Errorf("third shop account = %s, want a new account", third.Account.ID)
+	}
+
+	// A store already in an account stays there
+	again := st.login(t, "second.myshopify.com", third.Account.ID)
+	if again.Account.ID != first.Account.ID {
+		t.Errorf("second shop account after login = %s, want %s", again.Account.ID, first.Account.ID)
+	}
+
+	accounts, err := NewAccountManager(st.db).ListAccounts(st.ctx, st.userID)
+	if err != nil {
+		t.Fatalf("ListAccounts: %v", err)
+	}
+	if len(accounts) != 2 || len(accounts[0].Shops) != 2 || len(accounts[1].Shops) != 1 {
+		t.Errorf("accounts = %+v, want 2 shops and 1 shop", accounts)
+	}
+}
+
+func TestLegacySessionsGetTheShopAccount(t *testing.T) {
+	st := newSyncTest(t)
+	accounts := NewAccountManager(st.db)
+
+	account := st.account(t, accounts, testShopDomain)
+	if again := st.account(t, accounts, testShopDomain); again.ID != account.ID {
+		t.Errorf("account = %s, want the account created before %s", again.ID, account.ID)
+	}
+	if len(account.Shops) != 1 || account.Shops[0].ShopDomain != testShopDomain {
+		t.Errorf("shops = %+v, want the session's shop", account.Shops)
+	}
+
+	_, err := accounts.GetAccount(st.ctx, AccountRequest{UserID: id.NewGeneration[id.User](), ShopDomain: testShopDomain})
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("another user got %v, want %v", err, ErrAccountNotFound)
+	}
+	_, err = accounts.GetAccount(st.ctx, AccountRequest{UserID: st.userID, AccountID: id.NewGeneration[id.MerchantAccount]()})
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("unknown account got %v, want %v", err, ErrAccountNotFound)
+	}
+}
+
+func TestLinkShopRequiresAccessToTheShop(t *testing.T) {
+	st := newSyncTest(t)
+	accounts := NewAccountManager(st.db)
+	account := st.account(t, accounts, testShopDomain)
+	request := AccountRequest{UserID: st.userID, AccountID: id.ID[id.MerchantAccount](account.ID)}
+
+	// A shop of another user
+	if _, err := st.db.CreateShopifyStore(st.ctx, shopify.CreateShopifyStoreParams{ID: id.NewGeneration[id.ShopifyStore](), ShopDomain: "stranger.myshopify.com"}); err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	for _, shopDomain := range []string{"stranger.myshopify.com", "missing.myshopify.com"} {
+		_, err := accounts.LinkShop(st.ctx, LinkShopRequest{AccountRequest: request, LinkShopDomain: shopDomain})
+		if !errors.Is(err, ErrShopAccessDenied) {
+			t.Errorf("linking %s got %v, want %v", shopDomain, err, ErrShopAccessDenied)
+		}
+	}
+
+	st.addShop(t, testOtherShopDomain)
+	other := st.account(t, accounts, testOtherShopDomain)
+	linked, err := accounts.LinkShop(st.ctx, LinkShopRequest{AccountRequest: request, LinkShopDomain: testOtherShopDomain})
+	if err != nil {
+		t.Fatalf("LinkShop: %v", err)
+	}
+	if len(linked.Shops) != 2 {
+		t.Errorf("shops = %+v, want both shops", linked.Shops)
+	}
+	if left := st.account(t, accounts, testOtherShopDomain); left.ID != account.ID || left.ID == other.ID {
+		t.Errorf("other shop account = %s, want %s", left.ID, account.ID)
+	}
+}
+
+func TestGetAccountSKUsSumsEveryChannel(t *testing.T) {
+	st := newSyncTest(t)
+	accounts := NewAccountManager(st.db)
+	st.addShop(t, testOtherShopDomain)
+
+	recent := time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02")
+	old := time.Now().UTC().AddDate(0, 0, -90).Format("2006-01-02")
+	imports := map[string][3]string{
+		testShopDomain: {
+			testProductsFile,
+			testInventoryFile,
+			fmt.Sprintf("order_id,date,sku,quantity,price\n1001,%s,TEE-S,2,19.50\n1001,%s,MUG-01,1,12\n1002,%s,MUG-01,5,12\n", recent, recent, old),
+		},
+		testOtherShopDomain: {
+			"sku,title\nMUG-01,Mug\nCAP-01,Cap\n",
+			"sku,location,available\nMUG-01,Till,3\nCAP-01,Till,0\n",
+			fmt.Sprintf("order_id,date,sku,quantity,price\nA-1,%s,MUG-01,2,12\nA-2,%s,MUG-01,1,12\n", recent, recent),
+		},
+	}
+	for _, shopDomain := range []string{testShopDomain, testOtherShopDomain} {
+		st.importFiles(t, shopDomain, imports[shopDomain])
+	}
+
+	account := st.account(t, accounts, testShopDomain)
+	request := AccountRequest{UserID: st.userID, AccountID: id.ID[id.MerchantAccount](account.ID)}
+	if _, err := accounts.LinkShop(st.ctx, LinkShopRequest{AccountRequest: request, LinkShopDomain: testOtherShopDomain}); err != nil {
+		t.Fatalf("LinkShop: %v", err)
+	}
+
+	result, err := accounts.GetAccountSKUs(st.ctx, AccountSKUsRequest{AccountRequest: request, Days: 10})
+	if err != nil {
+		t.Fatalf("GetAccountSKUs: %v", err)
+	}
+	skus := map[string]AccountSKU{}
+	for _, sku := range result.SKUs {
+		skus[sku.SKU] = sku
+	}
+	if len(skus) != 4 {
+		t.Fatalf("skus = %+v, want CAP-01, MUG-01, TEE-L and TEE-S", result.SKUs)
+	}
+
+	mug := skus["MUG-01"]
+	if mug.Available != 15 || mug.UnitsSold != 4 || len(mug.Channels) != 2 {
+		t.Errorf("mug = %+v, want 15 available and 4 sold in the window on 2 channels", mug)
+	}
+	if mug.DaysOfCover == nil || *mug.DaysOfCover != 37.5 {
+		t.Errorf("mug days of cover = %v, want 37.5", mug.DaysOfCover)
+	}
+	for _, channel := range mug.Channels {
+		if channel.PlatformType != core.PlatformTypeFileImport || (channel.UnitsSold == 3 && channel.OrdersCount != 2) {
+			t.Errorf("mug channel = %+v", channel)
+		}
+	}
+	if tee := skus["TEE-S"]; tee.Title != "Logo Tee" || tee.Available != 4 || tee.UnitsSold != 2 {
+		t.Errorf("tee = %+v, want 4 available and 2 sold", tee)
+	}
+
+	// The shops' currency is not known, so their revenue cannot be converted into the totals
+	if tee := skus["TEE-S"]; tee.Revenue != 0 || tee.Channels[0].Revenue != 39 || tee.Channels[0].Currency != "" {
+		t.Errorf("tee = %+v, want its channel revenue left out of the total", tee)
+	}
+	if !slices.Equal(result.MissingRates, []string{UnknownCurrency}) {
+		t.Errorf("missing rates = %v, want the unknown currency", result.MissingRates)
+	}
+	if hat := skus["CAP-01"]; hat.UnitsSold != 0 || hat.DaysOfCover != nil {
+		t.Errorf("cap = %+v, want no sales and no days of cover", hat)
+	}
+
+	// Only users of an account see its SKUs
+	_, err = accounts.GetAccountSKUs(st.ctx, AccountSKUsRequest{AccountRequest: AccountRequest{UserID: st.userID, AccountID: id.NewGeneration[id.MerchantAccount]()}})
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("unknown account got %v, want %v", err, ErrAccountNotFound)
+	}
+}
+
+func TestGetAccountSKUsCountsSalesOfSyncedOrders(t *testing.T) {
+	st := newSyncTest(t)
+	accounts := NewAccountManager(st.db)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	if err := st.manager.SyncProducts(st.ctx, integrationID, string(SyncTriggerManual)); err != nil {
+		t.Fatalf("SyncProducts: %v", err)
+	}
+
+	// Orders synced twice keep one line per line item
+	for range 2 {
+		if err := st.manager.SyncOrders(st.ctx, integrationID, string(SyncTriggerManual)); err != nil {
+			t.Fatalf("SyncOrders: %v", err)
+		}
+	}
+
+	account := st.account(t, accounts, testShopDomain)
+	result, err := accounts.GetAccountSKUs(st.ctx, AccountSKUsRequest{
+		AccountRequest: AccountRequest{UserID: st.userID, AccountID: id.ID[id.MerchantAccount](account.ID)},
+		Days:           30,
+	})
+	if err != nil {
+		t.Fatalf("GetAccountSKUs: %v", err)
+	}
+	sold := map[string]int64{}
+	for _, sku := range result.SKUs {
+		if len(sku.Channels) != 1 || sku.Channels[0].PlatformType != core.PlatformTypeWoocommerce {
+			t.Errorf("sku %s channels = %+v, want the woocommerce store", sku.SKU, sku.Channels)
+		}
+		sold[sku.SKU] = sku.UnitsSold
+	}
+
+	// The cancelled order and the order before the window are not counted
+	want := map[string]int64{"TOTE-1": 1, "SHIRT-S": 1, "SHIRT-M": 0, "SHIRT-L": 0}
+	for sku, units := range want {
+		if sold[sku] != units {
+			t.Errorf("%s sold %d, want %d", sku, sold[sku], units)
+		}
+	}
+}
+
+func TestGetAccountSKUsConvertsIntoTheReportingCurrency(t *testing.T) {
+	st := newSyncTest(t)
+	accounts := NewAccountManager(st.db)
+	st.addShop(t, testOtherShopDomain)
+
+	recent := time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02")
+	imports := map[string][3]string{
+		testShopDomain: {
+			"sku,title,cost\nMUG-01,Mug,4\n",
+			"sku,location,available\nMUG-01,Main,10\n",
+			fmt.Sprintf("order_id,date,sku,quantity,price\n1001,%s,MUG-01,1,12\n", recent),
+		},
+		testOtherShopDomain: {
+			"sku,title,cost\nMUG-01,Mug,5\n",
+			"sku,location,available\nMUG-01,Till,2\n",
+			fmt.Sprintf("order_id,date,sku,quantity,price\nA-1,%s,MUG-01,2,10\n", recent),
+		},
+	}
+	currencies := map[string]string{testShopDomain: "usd", testOtherShopDomain: "EUR"}
+	for _, shopDomain := range []string{testShopDomain, testOtherShopDomain} {
+		for i, kind := range []fileimport.Kind{fileimport.KindProducts, fileimport.KindInventory, fileimport.KindSales} {
+			_, err := st.manager.ImportFile(st.ctx, ImportFileRequest{
+				UserID:     st.userID,
+				ShopDomain: shopDomain,
+				Kind:       kind,
+				FileName:   string(kind) + ".csv",
+				Content:    strings.NewReader(imports[shopDomain][i]),
+				Currency:   currencies[shopDomain],
+			})
+			if err != nil {
+				t.Fatalf("ImportFile %s %s: %v", shopDomain, kind, err)
+			}
+		}
+	}
+	_, err := st.manager.ImportFile(st.ctx, ImportFileRequest{
+		UserID:     st.userID,
+		ShopDomain: testShopDomain,
+		Kind:       fileimport.KindProducts,
+		Content:    strings.NewReader(imports[testShopDomain][0]),
+		Currency:   "dollars",
+	})
+	if !errors.Is(err, currency.ErrInvalidCode) {
+		t.Errorf("importing in dollars got %v, want %v", err, currency.ErrInvalidCode)
+	}
+
+	account := st.account(t, accounts, testShopDomain)
+	if account.ReportingCurrency != DefaultReportingCurrency {
+		t.Errorf("reporting currency = %q, want %q", account.ReportingCurrency, DefaultReportingCurrency)
+	}
+	request := AccountRequest{UserID: st.userID, AccountID: id.ID[id.MerchantAccount](account.ID)}
+	if _, err := accounts.LinkShop(st.ctx, LinkShopRequest{AccountRequest: request, LinkShopDomain: testOtherShopDomain}); err != nil {
+		t.Fatalf("LinkShop: %v", err)
+	}
+
+	mug := func() (AccountSKU, *AccountSKUsResult) {
+		t.Helper()
+		result, err := accounts.GetAccountSKUs(st.ctx, AccountSKUsRequest{AccountRequest: request, Days: 10})
+		if err != nil {
+			t.Fatalf("GetAccountSKUs: %v", err)
+		}
+		if len(result.SKUs) != 1 {
+			t.Fatalf("skus = %+v, want MUG-01", result.SKUs)
+		}
+		return result.SKUs[0], result
+	}
+
+	// Without a EUR rate the euro channel is left out of the totals
+	sku, result := mug()
+	if !result.RatesDate.Equal(time.Now().UTC().Truncate(24 * time.Hour)) {
+		t.Errorf("rates date = %v, want today", result.RatesDate)
+	}
+	if sku.Revenue != 12 || sku.StockValue != 40 || !slices.Equal(result.MissingRates, []string{"EUR"}) {
+		t.Errorf("mug = %+v, missing rates %v, want the dollar channel only and EUR missing", sku, result.MissingRates)
+	}
+	for _, channel := range sku.Channels {
+		if channel.Currency == "EUR" && (channel.Revenue != 20 || channel.StockValue != 10) {
+			t.Errorf("euro channel = %+v, want its amounts in euros", channel)
+		}
+	}
+
+	rates := NewExchangeRateManager(st.db)
+	if _, err := rates.ImportRates(st.ctx, "file", []currency.Rate{
+		{Base: "EUR", Quote: "USD", Date: time.Now().UTC().AddDate(0, 0, -3), Rate: 1.2},
+		{Base: "EUR", Quote: "USD", Date: time.Now().UTC().AddDate(0, 0, -1), Rate: 1.1},
+		{Base: "EUR", Quote: "USD", Date: time.Now().UTC().AddDate(0, 0, 2), Rate: 1.5},
+	}); err != nil {
+		t.Fatalf("ImportRates: %v", err)
+	}
+
+	// The latest rate up to today converts the euro channel
+	sku, result = mug()
+	if sku.Revenue != 34 || sku.StockValue != 51 || len(result.MissingRates) != 0 || result.Currency != "USD" {
+		t.Errorf("mug = %+v in %s, missing rates %v, want 34 revenue and 51 stock value", sku, result.Currency, result.MissingRates)
+	}
+
+	// Dollars convert into euros with the inverse rate
+	updated, err := accounts.SetReportingCurrency(st.ctx, SetReportingCurrencyRequest{AccountRequest: request, ReportingCurrency: "eur"})
+	if err != nil {
+		t.Fatalf("SetReportingCurrency: %v", err)
+	}
+	if updated.ReportingCurrency != "EUR" {
+		t.Errorf("reporting currency = %q, want EUR", updated.ReportingCurrency)
+	}
+	sku, result = mug()
+	if sku.Revenue != 30.91 || sku.StockValue != 46.36 || result.Currency != "EUR" {
+		t.Errorf("mug = %+v in %s, want 30.91 revenue and 46.36 stock value", sku, result.Currency)
+	}
+
+	if _, err := accounts.SetReportingCurrency(st.ctx, SetReportingCurrencyRequest{AccountRequest: request, ReportingCurrency: "euro"}); !errors.Is(err, currency.ErrInvalidCode) {
+		t.Errorf("setting euro got %v, want %v", err, currency.ErrInvalidCode)
+	}
+}
@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/crypto"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// How long the merchant has to claim a BigCommerce installation from the app
+const bigCommerceClaimTTL = time.Hour
+
+// ErrInstallationNotFound is returned when a claim token is unknown, expired or already used
+var ErrInstallationNotFound = errors.New("installation not found")
+
+// ClaimBigCommerceRequest represents a request to link an installed BigCommerce store to an account
+type ClaimBigCommerceRequest struct {
+	ConnectOwner
+	ClaimToken string `json:"claim_token"`
+}
+
+// SaveBigCommerceInstallation saves the access token of a store that installed the app and
+// returns a one-time token that links the store to an account through ClaimBigCommerceInstallation.
+//
+// BigCommerce starts the install from its control panel, so the auth callback does not know
+// which of our users or accounts it belongs to; the claim token is only handed to the browser that
+// completed the install. Reinstalling the app replaces the access token and issues a new claim token.
+func (m *InventorySyncManager) SaveBigCommerceInstallation(ctx context.Context, installation *bigcommerce.Installation) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "failed to generate claim token")
+	}
+	claimToken := hex.EncodeToString(secret)
+
+	_, err := m.database.GetCore().UpsertBigCommerceInstallation(ctx, core.UpsertBigCommerceInstallationParams{
+		ID:             id.NewGeneration[id.BigCommerceInstallation](),
+		StoreHash:      installation.StoreHash,
+		AccessToken:    crypto.EncryptedSecret(installation.AccessToken),
+		Scope:          installation.Scope,
+		ClaimTokenHash: pgtype.Text{String: hashClaimToken(claimToken), Valid: true},
+		ClaimExpiresAt: pgtype.Timestamp{Time: time.Now().UTC().Add(bigCommerceClaimTTL), Valid: true},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to save bigcommerce installation")
+	}
+
+	logger.Info("BigCommerce app installed", "store_hash", installation.StoreHash, "scope", installation.Scope)
+	return claimToken, nil
+}
+
+// ClaimBigCommerceInstallation links an installed BigCommerce store to an account and starts its initial sync
+func (m *InventorySyncManager) ClaimBigCommerceInstallation(ctx context.Context, req ClaimBigCommerceRequest) (*IntegrationResult, error) {
+	owner, err := m.getIntegrationOwner(ctx, req.ConnectOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	installation, err := m.database.GetCore().GetBigCommerceInstallationByClaimToken(ctx, pgtype.Text{String: hashClaimToken(req.ClaimToken), Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInstallationNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get bigcommerce installation")
+	}
+
+	// The access token was saved on install, claiming the installation uses up its claim token
+	return m.connectIntegration(ctx, owner, core.PlatformTypeBigcommerce, installation.StoreHash, func(tx *db.TxDB, integrationID id.ID[id.PlatformIntegration]) error {
+		return errors.Wrap(tx.GetCore().ClearBigCommerceClaimToken(ctx, installation.ID), "failed to clear claim token")
+	})
+}
+
+// UninstallBigCommerce deactivates the integration of a store that uninstalled the app and
+// deletes its access token, which BigCommerce has revoked
+func (m *InventorySyncManager) UninstallBigCommerce(ctx context.Context, storeHash string) error {
+	return m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		integration, err := tx.GetCore().GetPlatformIntegrationByPlatformShop(ctx, core.GetPlatformIntegrationByPlatformShopParams{
+			PlatformShopID: storeHash,
+			PlatformType:   core.PlatformTypeBigcommerce,
+		})
+		switch {
+		case err == nil:
+			if err := tx.GetCore().DeactivatePlatformIntegration(ctx, integration.ID); err != nil {
+				return errors.Wrap(err, "failed to deactivate platform integration")
+			}
+		case !errors.Is(err, pgx.ErrNoRows):
+			return errors.Wrap(err, "failed to get platform integration")
+		}
+
+		logger.Info("BigCommerce app uninstalled", "store_hash", storeHash)
+		return errors.Wrap(tx.GetCore().DeleteBigCommerceInstallation(ctx, storeHash), "failed to delete bigcommerce installation")
+	})
+}
+
+// hashClaimToken returns the SHA-256 of a claim token, which is what is stored
+func hashClaimToken(claimToken string) string {
+	sum := sha256.Sum256([]byte(claimToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,106 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce/bigcommercetest"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/pkg/errors"
+)
+
+// newBigCommerceStore starts a fake BigCommerce API and points the BigCommerce client at it
+func newBigCommerceStore(t *testing.T) *bigcommercetest.Server {
+	t.Helper()
+
+	server := bigcommercetest.NewServer(bigcommercetest.DemoFixtures())
+	t.Cleanup(server.Close)
+	previousBaseURL := config.Values.BigCommerce.APIBaseURL
+	config.Values.BigCommerce.APIBaseURL = server.URL
+	t.Cleanup(func() { config.Values.BigCommerce.APIBaseURL = previousBaseURL })
+	return server
+}
+
+// installBigCommerce saves an installation of the fake store and returns its claim token
+func (st *syncTest) installBigCommerce(t *testing.T) string {
+	t.Helper()
+
+	claimToken, err := st.manager.SaveBigCommerceInstallation(st.ctx, &bigcommerce.Installation{
+		StoreHash:   bigcommercetest.StoreHash,
+		AccessToken: bigcommercetest.AccessToken,
+		Scope:       "store_v2_products_read_only",
+	})
+	if err != nil {
+		t.Fatalf("SaveBigCommerceInstallation: %v", err)
+	}
+	return claimToken
+}
+
+func (st *syncTest) claimBigCommerce(claimToken string) (*IntegrationResult, error) {
+	return st.manager.ClaimBigCommerceInstallation(st.ctx, ClaimBigCommerceRequest{
+		ConnectOwner: ConnectOwner{UserID: st.userID, ShopDomain: testShopDomain},
+		ClaimToken:   claimToken,
+	})
+}
+
+func TestClaimBigCommerceInstallation(t *testing.T) {
+	st := newSyncTest(t)
+	claimToken := st.installBigCommerce(t)
+
+	result, err := st.claimBigCommerce(claimToken)
+	if err != nil {
+		t.Fatalf("ClaimBigCommerceInstallation: %v", err)
+	}
+	if result.PlatformShopID != bigcommercetest.StoreHash {
+		t.Errorf("result = %+v, want the bigcommerce store", result)
+	}
+
+	// The claim token only works once
+	if _, err := st.claimBigCommerce(claimToken); !errors.Is(err, ErrInstallationNotFound) {
+		t.Errorf("second claim: got %v, want %v", err, ErrInstallationNotFound)
+	}
+}
+
+func TestClaimBigCommerceRejectsUnknownToken(t *testing.T) {
+	st := newSyncTest(t)
+	st.installBigCommerce(t)
+
+	if _, err := st.claimBigCommerce("not-the-token"); !errors.Is(err, ErrInstallationNotFound) {
+		t.Errorf("got %v, want %v", err, ErrInstallationNotFound)
+	}
+}
+
+func TestUninstallBigCommerceDeactivatesIntegration(t *testing.T) {
+	st := newSyncTest(t)
+
+	result, err := st.claimBigCommerce(st.installBigCommerce(t))
+	if err != nil {
+		t.Fatalf("ClaimBigCommerceInstallation: %v", err)
+	}
+
+	if err := st.manager.UninstallBigCommerce(st.ctx, bigcommercetest.StoreHash); err != nil {
+		t.Fatalf("UninstallBigCommerce: %v", err)
+	}
+
+	integration, err := st.db.GetPlatformIntegrationByID(st.ctx, id.ID[id.PlatformIntegration](result.ID))
+	if err != nil {
+		t.Fatalf("GetPlatformIntegrationByID: %v", err)
+	}
+	if integration.IsActive.Bool {
+		t.Error("integration is still active after the app was uninstalled")
+	}
+	if _, err := st.db.GetBigCommerceInstallationByStoreHash(st.ctx, bigcommercetest.StoreHash); err == nil {
+		t.Error("installation was not deleted")
+	}
+
+	// Reinstalling and claiming reactivates the same integration
+	st.queue.Finish(result.ID)
+	reclaimed, err := st.claimBigCommerce(st.installBigCommerce(t))
+	if err != nil {
+		t.Fatalf("ClaimBigCommerceInstallation after reinstall: %v", err)
+	}
+	if reclaimed.ID != result.ID || !reclaimed.IsActive {
+		t.Errorf("reclaimed = %+v, want integration %s active again", reclaimed, result.ID)
+	}
+}
@@ -0,0 +1,148 @@
+package manager
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// DefaultReportingCurrency is the reporting currency of accounts whose shop currency is not known
+const DefaultReportingCurrency = "USD"
+
+// UnknownCurrency stands in missing rates for the currency of integrations that is not known yet
+const UnknownCurrency = "unknown"
+
+// ExchangeRateManager loads the exchange rates the account views convert amounts with
+type ExchangeRateManager struct {
+	database db.Database
+}
+
+// NewExchangeRateManager creates a new ExchangeRateManager instance
+func NewExchangeRateManager(database db.Database) *ExchangeRateManager {
+	return &ExchangeRateManager{
+		database: database,
+	}
+}
+
+// ImportRates saves rates read from a file or fetched from a provider, recording the source they
+// came from. A rate of a pair on a day that was saved before is replaced.
+func (m *ExchangeRateManager) ImportRates(ctx context.Context, source string, rates []currency.Rate) (int, error) {
+	err := m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		for _, rate := range rates {
+			var value pgtype.Numeric
+			if err := value.Scan(strconv.FormatFloat(rate.Rate, 'f', -1, 64)); err != nil {
+				return errors.Wrapf(err, "invalid rate of %s/%s", rate.Base, rate.Quote)
+			}
+			err := tx.GetCore().UpsertExchangeRate(ctx, core.UpsertExchangeRateParams{
+				BaseCurrency:  rate.Base,
+				QuoteCurrency: rate.Quote,
+				RateDate:      pgtype.Date{Time: rate.Date, Valid: true},
+				Rate:          value,
+				Source:        source,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to upsert rate of %s/%s", rate.Base, rate.Quote)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info("Exchange rates imported", "source", source, "rates", len(rates))
+	return len(rates), nil
+}
+
+// RefreshRates fetches the latest rates of a provider and saves them
+func (m *ExchangeRateManager) RefreshRates(ctx context.Context, provider currency.Provider) (int, error) {
+	rates, err := provider.Rates(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to fetch rates from %s", provider.Name())
+	}
+	return m.ImportRates(ctx, provider.Name(), rates)
+}
+
+// reportingConversion converts the amounts of an account's integrations into the account's
+// reporting currency with the latest rates as of today. Revenue of past orders is converted at
+// these rates too, not at the rates of the day the orders were placed. Amounts of integrations
+// whose currency is not known yet cannot be converted.
+type reportingConversion struct {
+	converter *currency.Converter
+	reporting string
+	asOf      time.Time
+	missing   map[string]bool
+}
+
+// newReportingConversion loads the latest rates to convert amounts into the account's reporting currency
+func newReportingConversion(ctx context.Context, queries core.Querier, account core.MerchantAccount) (*reportingConversion, error) {
+	asOf := time.Now().UTC().Truncate(24 * time.Hour)
+	rows, err := queries.GetLatestExchangeRates(ctx, pgtype.Date{Time: asOf, Valid: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get exchange rates")
+	}
+
+	rates := make([]currency.Rate, 0, len(rows))
+	for _, row := range rows {
+		value, err := row.Rate.Float64Value()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid rate of %s/%s", row.BaseCurrency, row.QuoteCurrency)
+		}
+		rates = append(rates, currency.Rate{Base: row.BaseCurrency, Quote: row.QuoteCurrency, Date: row.RateDate.Time, Rate: value.Float64})
+	}
+
+	return &reportingConversion{
+		converter: currency.NewConverter(rates),
+		reporting: account.ReportingCurrency,
+		asOf:      asOf,
+		missing:   make(map[string]bool),
+	}, nil
+}
+
+// currencyOf returns the currency of the amounts of an integration, empty when it is not known
+func (c *reportingConversion) currencyOf(integrationCurrency pgtype.Text) string {
+	return integrationCurrency.String
+}
+
+// convert converts an amount in from into the reporting currency. Amounts of a currency without
+// a rate, or of an unknown currency, are left out, and the currency is reported by missingRates.
+func (c *reportingConversion) convert(amount float64, from string) float64 {
+	if amount == 0 {
+		return 0
+	}
+	if from == "" {
+		c.missing[UnknownCurrency] = true
+		return 0
+	}
+	converted, ok := c.converter.Convert(amount, from, c.reporting)
+	if !ok {
+		c.missing[from] = true
+		return 0
+	}
+	return converted
+}
+
+// missingRates lists the currencies that amounts were left out of the totals of, for lack of a
+// rate, with UnknownCurrency for amounts of integrations whose currency is not known
+func (c *reportingConversion) missingRates() []string {
+	missing := make([]string, 0, len(c.missing))
+	for code := range c.missing {
+		missing = append(missing, code)
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// roundAmount rounds an amount to cents
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,173 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/fileimport"
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// ImportFileRequest represents a request to import a CSV or XLSX file into a shop
+type ImportFileRequest struct {
+	UserID     id.ID[id.User]  `json:"user_id"`
+	ShopDomain string          `json:"shop_domain"`
+	Kind       fileimport.Kind `json:"kind"`
+	FileName   string          `json:"file_name"`
+	Content    io.Reader       `json:"-"`
+
+	// Currency of the file's prices, costs and totals. The shop's currency is assumed when
+	// empty and no earlier import set one.
+	Currency string `json:"currency,omitempty"`
+}
+
+// ImportFileResult represents the outcome of a file import
+type ImportFileResult struct {
+	IntegrationID       string          `json:"integration_id"`
+	Kind                fileimport.Kind `json:"kind"`
+	Rows                int             `json:"rows"`
+	Stats               SyncStats       `json:"stats"`
+	OrderLineItemsCount int             `json:"order_line_items_count"`
+}
+
+// ImportFile imports the products, inventory levels or sales of a shop from a CSV or XLSX file,
+// under the shop's file import integration which is created by its first import.
+//
+// The whole file is validated before anything is written. An invalid file returns
+// fileimport.ValidationErrors with the line of every problem found, and nothing is imported.
+// Inventory and sales files may only refer to SKUs imported by an earlier products file.
+//
+// A currency that is not an ISO 4217 code returns currency.ErrInvalidCode.
+//
+// Rows are keyed by the shop's own SKUs, locations and order IDs, so importing the same file
+// again leaves the data unchanged and a corrected file updates the rows it covers.
+func (m *InventorySyncManager) ImportFile(ctx context.Context, req ImportFileRequest) (*ImportFileResult, error) {
+	shop, err := m.getUserShop(ctx, req.UserID, req.ShopDomain)
+	if err != nil {
+		return nil, err
+	}
+	return m.importFile(ctx, shop, req)
+}
+
+// ImportShopFile imports a file into a shop like ImportFile, without checking a user's access
+// to the shop. It is meant for operators importing files on a client's behalf.
+func (m *InventorySyncManager) ImportShopFile(ctx context.Context, req ImportFileRequest) (*ImportFileResult, error) {
+	shop, err := m.database.GetShopify().GetShopifyStoreByDomain(ctx, req.ShopDomain)
+	if err != nil {
+		return nil, errors.Wrap(err, "shop not found")
+	}
+	return m.importFile(ctx, shop, req)
+}
+
+// importFile parses a file and writes its records to the core tables in one transaction
+func (m *InventorySyncManager) importFile(ctx context.Context, shop shopify.ShopifyStore, req ImportFileRequest) (*ImportFileResult, error) {
+	var fileCurrency pgtype.Text
+	if req.Currency != "" {
+		code, err := currency.Normalize(req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		fileCurrency = pgtype.Text{String: code, Valid: true}
+	}
+
+	table, err := fileimport.ReadTable(req.FileName, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// External IDs are unique across integrations, so the shop's keys are scoped to the shop
+	records, err := fileimport.Parse(req.Kind, table, shop.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportFileResult{Kind: req.Kind, Rows: records.Rows}
+	err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		accountID, err := shopAccountID(ctx, tx.GetCore(), shop.ID)
+		if err != nil {
+			return err
+		}
+
+		integration, err := tx.GetCore().UpsertPlatformIntegration(ctx, core.UpsertPlatformIntegrationParams{
+			ID:             id.NewGeneration[id.PlatformIntegration](),
+			ShopID:         shop.ID,
+			AccountID:      accountID,
+			PlatformType:   core.PlatformTypeFileImport,
+			PlatformShopID: shop.ID.String(),
+			IsActive:       pgtype.Bool{Bool: true, Valid: true},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to upsert platform integration")
+		}
+		result.IntegrationID = integration.ID.String()
+
+		if !fileCurrency.Valid && !integration.Currency.Valid {
+			fileCurrency = parseCurrency(shop.Currency.String, "shop currency", shop.ShopDomain)
+		}
+		if fileCurrency.Valid && fileCurrency != integration.Currency {
+			if _, err := tx.GetCore().SetPlatformIntegrationCurrency(ctx, core.SetPlatformIntegrationCurrencyParams{
+				ID:       integration.ID,
+				Currency: fileCurrency,
+			}); err != nil {
+				return errors.Wrap(err, "failed to set platform integration currency")
+			}
+		}
+
+		if err := m.checkImportReferences(ctx, tx, integration.ID, records.References); err != nil {
+			return err
+		}
+
+		data := m.normalizePage(integration.ID, fetchedPage{
+			locations:       records.Locations,
+			products:        records.Products,
+			inventoryLevels: records.InventoryLevels,
+			orders:          records.Orders,
+		})
+		result.Stats.add(data)
+		result.OrderLineItemsCount = len(data.OrderLineItems)
+		return m.batchSyncAllData(ctx, tx, integration.ID, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("File imported", "shop_domain", shop.ShopDomain, "integration_id", result.IntegrationID, "kind", req.Kind, "file", req.FileName, "rows", result.Rows)
+	return result, nil
+}
+
+// checkImportReferences checks that the SKUs an inventory or sales file refers to were imported,
+// returning a validation error for each unknown one
+func (m *InventorySyncManager) checkImportReferences(ctx context.Context, tx *db.TxDB, integrationID id.ID[id.PlatformIntegration], references []fileimport.Reference) error {
+	var validationErrors fileimport.ValidationErrors
+	for _, reference := range references {
+		_, err := tx.GetCore().GetInventoryItemByExternalID(ctx, core.GetInventoryItemByExternalIDParams{
+			IntegrationID: integrationID,
+			ExternalID:    pgtype.Text{String: reference.ExternalID, Valid: true},
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			validationErrors = append(validationErrors, fileimport.ValidationError{
+				Line:    reference.Line,
+				Column:  "sku",
+				Message: fmt.Sprintf("%q is not a known SKU, import it with a products file first", reference.SKU),
+			})
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get inventory item %s", reference.SKU)
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
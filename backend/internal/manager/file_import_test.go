@@ -0,0 +1,222 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/fileimport"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	testProductsFile = `sku,title,handle,price,cost
+TEE-S,Logo Tee,logo-tee,19.50,7
+TEE-L,Logo Tee,logo-tee,21,7
+MUG-01,Enamel Mug,,12,4
+`
+	testInventoryFile = `sku,location,available
+TEE-S,Main,4
+MUG-01,Main,10
+MUG-01,Back room,2
+`
+	testSalesFile = `order_id,date,sku,quantity,price
+1001,2025-03-01,TEE-S,2,19.50
+1001,2025-03-01,MUG-01,1,12
+1002,2025-03-02,TEE-L,1,21
+`
+)
+
+func (st *syncTest) importFile(kind fileimport.Kind, content string) (*ImportFileResult, error) {
+	return st.manager.ImportFile(st.ctx, ImportFileRequest{
+		UserID:     st.userID,
+		ShopDomain: testShopDomain,
+		Kind:       kind,
+		FileName:   string(kind) + ".csv",
+		Content:    strings.NewReader(content),
+	})
+}
+
+// mustImport imports a file, failing the test when it is not imported
+func (st *syncTest) mustImport(t *testing.T, kind fileimport.Kind, content string) *ImportFileResult {
+	t.Helper()
+
+	result, err := st.importFile(kind, content)
+	if err != nil {
+		t.Fatalf("ImportFile %s: %v", kind, err)
+	}
+	return result
+}
+
+// externalID returns the external ID an import gives to a key of the test shop
+func (st *syncTest) externalID(key string) pgtype.Text {
+	return pgtype.Text{String: st.shopID.String() + ":" + key, Valid: true}
+}
+
+func TestImportFileWritesProductsInventoryAndSales(t *testing.T) {
+	st := newSyncTest(t)
+
+	products := st.mustImport(t, fileimport.KindProducts, testProductsFile)
+	if products.Rows != 3 || products.Stats.ProductsCount != 2 || products.Stats.ProductVariantsCount != 3 || products.Stats.InventoryItemsCount != 3 {
+		t.Errorf("products result = %+v, want 2 products with 3 variants", products)
+	}
+	inventory := st.mustImport(t, fileimport.KindInventory, testInventoryFile)
+	if inventory.IntegrationID != products.IntegrationID || inventory.Stats.LocationsCount != 2 || inventory.Stats.InventoryLevelsCount != 3 {
+		t.Errorf("inventory result = %+v, want 2 locations and 3 levels of the same integration", inventory)
+	}
+	sales := st.mustImport(t, fileimport.KindSales, testSalesFile)
+	if sales.Stats.OrdersCount != 2 || sales.OrderLineItemsCount != 3 {
+		t.Errorf("sales result = %+v, want 2 orders with 3 lines", sales)
+	}
+
+	integrationID := id.ID[id.PlatformIntegration](products.IntegrationID)
+	integration, err := st.db.GetPlatformIntegrationByID(st.ctx, integrationID)
+	if err != nil {
+		t.Fatalf("GetPlatformIntegrationByID: %v", err)
+	}
+	if integration.PlatformType != core.PlatformTypeFileImport || integration.ShopID != st.shopID {
+		t.Errorf("integration = %+v, want the shop's file import integration", integration)
+	}
+
+	mug, err := st.db.GetInventoryItemByExternalID(st.ctx, core.GetInventoryItemByExternalIDParams{IntegrationID: integrationID, ExternalID: st.externalID("MUG-01")})
+	if err != nil {
+		t.Fatalf("GetInventoryItemByExternalID: %v", err)
+	}
+	levels, err := st.db.GetInventoryLevelsByInventoryItemID(st.ctx, mug.ID)
+	if err != nil {
+		t.Fatalf("GetInventoryLevelsByInventoryItemID: %v", err)
+	}
+	if len(levels) != 2 || levels[0].Available.Int32+levels[1].Available.Int32 != 12 {
+		t.Errorf("mug levels = %+v, want 10 and 2", levels)
+	}
+
+	order, err := st.db.GetOrderByExternalID(st.ctx, core.GetOrderByExternalIDParams{IntegrationID: integrationID, ExternalID: st.externalID("1001")})
+	if err != nil {
+		t.Fatalf("GetOrderByExternalID: %v", err)
+	}
+	lines, err := st.db.GetOrderLineItemsByOrderID(st.ctx, order.ID)
+	if err != nil {
+		t.Fatalf("GetOrderLineItemsByOrderID: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("order lines = %+v, want 2", lines)
+	}
+	for _, line := range lines {
+		if line.ExternalID.String == "MUG-01" && (line.InventoryItemID != mug.ID || line.Quantity != 1) {
+			t.Errorf("mug line = %+v, want the mug's inventory item", line)
+		}
+	}
+}
+
+func TestImportFileAgainUpdatesTheSameRows(t *testing.T) {
+	st := newSyncTest(t)
+
+	first := st.mustImport(t, fileimport.KindProducts, testProductsFile)
+	integrationID := id.ID[id.PlatformIntegration](first.IntegrationID)
+	before, err := st.db.GetProductVariantByExternalID(st.ctx, core.GetProductVariantByExternalIDParams{IntegrationID: integrationID, ExternalID: st.externalID("TEE-S")})
+	if err != nil {
+		t.Fatalf("GetProductVariantByExternalID: %v", err)
+	}
+
+	// A corrected file updates the price, and a new size joins the existing product
+	second := st.mustImport(t, fileimport.KindProducts, strings.Replace(testProductsFile, "19.50", "18", 1)+"TEE-XL,Logo Tee,logo-tee,23,8\n")
+	if second.IntegrationID != first.IntegrationID {
+		t.Errorf("integration = %s, want %s", second.IntegrationID, first.IntegrationID)
+	}
+	after, err := st.db.GetProductVariantByExternalID(st.ctx, core.GetProductVariantByExternalIDParams{IntegrationID: integrationID, ExternalID: st.externalID("TEE-S")})
+	if err != nil {
+		t.Fatalf("GetProductVariantByExternalID: %v", err)
+	}
+	if after.ID != before.ID || after.ProductID != before.ProductID {
+		t.Errorf("variant = %+v, want the row of %+v", after, before)
+	}
+	xl, err := st.db.GetProductVariantByExternalID(st.ctx, core.GetProductVariantByExternalIDParams{IntegrationID: integrationID, ExternalID: st.externalID("TEE-XL")})
+	if err != nil {
+		t.Fatalf("GetProductVariantByExternalID: %v", err)
+	}
+	if xl.ProductID != before.ProductID {
+		t.Errorf("new variant product = %s, want the existing product %s", xl.ProductID, before.ProductID)
+	}
+
+	st.mustImport(t, fileimport.KindInventory, testInventoryFile)
+	st.mustImport(t, fileimport.KindSales, testSalesFile)
+	sales := st.mustImport(t, fileimport.KindSales, testSalesFile)
+	order, err := st.db.GetOrderByExternalID(st.ctx, core.GetOrderByExternalIDParams{IntegrationID: integrationID, ExternalID: st.externalID("1001")})
+	if err != nil {
+		t.Fatalf("GetOrderByExternalID: %v", err)
+	}
+	lines, err := st.db.GetOrderLineItemsByOrderID(st.ctx, order.ID)
+	if err != nil {
+		t.Fatalf("GetOrderLineItemsByOrderID: %v", err)
+	}
+	if sales.OrderLineItemsCount != 3 || len(lines) != 2 {
+		t.Errorf("reimported order has %d lines and %d in total, want 2 and 3", len(lines), sales.OrderLineItemsCount)
+	}
+}
+
+func TestImportFileRejectsUnknownSKUs(t *testing.T) {
+	st := newSyncTest(t)
+
+	_, err := st.importFile(fileimport.KindInventory, testInventoryFile)
+	var validationErrors fileimport.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("got %v, want validation errors", err)
+	}
+	if len(validationErrors) != 2 || validationErrors[0].Line != 2 || validationErrors[1].Line != 3 || validationErrors[1].Column != "sku" {
+		t.Errorf("errors = %v, want TEE-S and MUG-01 on lines 2 and 3", validationErrors)
+	}
+
+	// Nothing is written, not even the integration
+	integrations, err := st.manager.ListIntegrations(st.ctx, st.userID, testShopDomain)
+	if err != nil {
+		t.Fatalf("ListIntegrations: %v", err)
+	}
+	if len(integrations) != 0 {
+		t.Errorf("integrations = %+v, want none", integrations)
+	}
+}
+
+func TestImportFileRejectsInvalidFiles(t *testing.T) {
+	st := newSyncTest(t)
+
+	if _, err := st.importFile(fileimport.KindProducts, "sku,price\nA-1,2\n"); err == nil {
+		t.Error("imported a file without a title column")
+	}
+	_, err := st.manager.ImportFile(st.ctx, ImportFileRequest{
+		UserID:     st.userID,
+		ShopDomain: testShopDomain,
+		Kind:       fileimport.KindProducts,
+		FileName:   "products.pdf",
+		Content:    strings.NewReader(testProductsFile),
+	})
+	if !errors.Is(err, fileimport.ErrUnsupportedFormat) {
+		t.Errorf("got %v, want %v", err, fileimport.ErrUnsupportedFormat)
+	}
+}
+
+func TestFileImportIntegrationsAreNotSynced(t *testing.T) {
+	st := newSyncTest(t)
+
+	result := st.mustImport(t, fileimport.KindProducts, testProductsFile)
+	integrationID := id.ID[id.PlatformIntegration](result.IntegrationID)
+
+	scheduled, err := st.db.GetScheduledPlatformIntegrations(st.ctx)
+	if err != nil {
+		t.Fatalf("GetScheduledPlatformIntegrations: %v", err)
+	}
+	if len(scheduled) != 0 {
+		t.Errorf("scheduled = %+v, want no file import integration", scheduled)
+	}
+
+	_, err = st.manager.TriggerShopifySync(st.ctx, SyncRequest{
+		UserID:        st.userID,
+		ShopDomain:    testShopDomain,
+		IntegrationID: integrationID,
+		Trigger:       SyncTriggerManual,
+	})
+	if !errors.Is(err, ErrNotSyncable) {
+		t.Errorf("got %v, want %v", err, ErrNotSyncable)
+	}
+}
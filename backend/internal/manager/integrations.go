@@ -0,0 +1,321 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/crypto"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrIntegrationNotFound is returned when an integration does not exist or the user has no access to it
+	ErrIntegrationNotFound = errors.New("integration not found")
+
+	// ErrShopNotFound is returned when a shop domain is not one of a connected Shopify shop
+	ErrShopNotFound = errors.New("shop not found")
+
+	// ErrStoreConnectedElsewhere is returned when connecting a store that is already connected to another account
+	ErrStoreConnectedElsewhere = errors.New("store is connected to another account")
+
+	// ErrInvalidStoreURL is returned when connecting a self-hosted store whose URL is not a public https URL
+	ErrInvalidStoreURL = errors.New("invalid store url")
+
+	// ErrInvalidCredentials is returned when a platform rejects the credentials of a new integration
+	ErrInvalidCredentials = errors.New("invalid platform credentials")
+
+	// ErrNotSyncable is returned when syncing an integration that is only updated by file imports
+	ErrNotSyncable = errors.New("integration is updated by file imports and cannot be synced")
+)
+
+// ConnectWooCommerceRequest represents a request to connect a WooCommerce store to an account
+type ConnectWooCommerceRequest struct {
+	ConnectOwner
+	StoreURL       string `json:"store_url"`
+	ConsumerKey    string `json:"consumer_key"`
+	ConsumerSecret string `json:"consumer_secret"`
+}
+
+// ConnectOwner identifies who a store is connected for. A store connected from a shop belongs to
+// the shop's account; without a shop domain it belongs to the session's account, so a merchant
+// selling only on Square or WooCommerce needs no Shopify shop.
+type ConnectOwner struct {
+	UserID     id.ID[id.User]            `json:"user_id"`
+	AccountID  id.ID[id.MerchantAccount] `json:"account_id,omitempty"`
+	ShopDomain string                    `json:"shop_domain,omitempty"`
+}
+
+// integrationOwner is the account an integration belongs to and the shop it was connected from, if any
+type integrationOwner struct {
+	accountID id.ID[id.MerchantAccount]
+	shopID    id.ID[id.ShopifyStore]
+}
+
+// IntegrationResult represents a platform integration of a shop
+type IntegrationResult struct {
+	ID             string            `json:"id"`
+	PlatformType   core.PlatformType `json:"platform_type"`
+	PlatformShopID string            `json:"platform_shop_id"`
+	IsActive       bool              `json:"is_active"`
+	CreatedAt      time.Time         `json:"created_at"`
+
+	// Currency is the store currency of the integration's amounts, empty until it is known
+	Currency string `json:"currency,omitempty"`
+
+	// SyncIntervalMinutes is the time between scheduled full syncs, 0 when they are off
+	SyncIntervalMinutes int `json:"sync_interval_minutes"`
+
+	// Sync is the initial sync started when the integration was connected
+	Sync *SyncResult `json:"sync,omitempty"`
+}
+
+// toIntegrationResult converts a platform integration row
+func toIntegrationResult(integration core.PlatformIntegration) IntegrationResult {
+	return IntegrationResult{
+		ID:             integration.ID.String(),
+		PlatformType:   integration.PlatformType,
+		PlatformShopID: integration.PlatformShopID,
+		IsActive:       integration.IsActive.Bool,
+		CreatedAt:      integration.CreatedAt.Time,
+		Currency:       integration.Currency.String,
+
+		SyncIntervalMinutes: int(integration.SyncIntervalMinutes),
+	}
+}
+
+// ConnectWooCommerce connects a WooCommerce store to an account and starts its initial sync.
+//
+// The store URL must be a public https URL. The credentials are checked against the store before
+// anything is saved. Reconnecting a store replaces its stored credentials, which are encrypted at rest.
+func (m *InventorySyncManager) ConnectWooCommerce(ctx context.Context, req ConnectWooCommerceRequest) (*IntegrationResult, error) {
+	owner, err := m.getIntegrationOwner(ctx, req.ConnectOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	storeURL := woocommerce.NormalizeStoreURL(req.StoreURL)
+	parsed, err := m.checkStoreURL(ctx, storeURL)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidStoreURL, err.Error())
+	}
+
+	client := woocommerce.NewClient(storeURL, req.ConsumerKey, req.ConsumerSecret)
+	if err := woocommerce.New(client).Verify(ctx); err != nil {
+		return nil, verifyError(err, "woocommerce credentials")
+	}
+
+	return m.connectIntegration(ctx, owner, core.PlatformTypeWoocommerce, parsed.Host, func(tx *db.TxDB, integrationID id.ID[id.PlatformIntegration]) error {
+		_, err := tx.GetCore().UpsertWooCommerceCredentials(ctx, core.UpsertWooCommerceCredentialsParams{
+			ID:             id.NewGeneration[id.WooCommerceCredential](),
+			IntegrationID:  integrationID,
+			StoreUrl:       storeURL,
+			ConsumerKey:    crypto.EncryptedSecret(req.ConsumerKey),
+			ConsumerSecret: crypto.EncryptedSecret(req.ConsumerSecret),
+		})
+		return errors.Wrap(err, "failed to save woocommerce credentials")
+	})
+}
+
+// connectIntegration upserts the integration of a store connected for the owner, saves the store's
+// credentials in the same transaction and starts its initial sync. A store that is connected to
+// another account is rejected with ErrStoreConnectedElsewhere.
+func (m *InventorySyncManager) connectIntegration(ctx context.Context, owner integrationOwner, platformType core.PlatformType, platformShopID string, saveCredentials func(tx *db.TxDB, integrationID id.ID[id.PlatformIntegration]) error) (*IntegrationResult, error) {
+	var integration core.PlatformIntegration
+	err := m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		var err error
+		integration, err = tx.GetCore().UpsertPlatformIntegration(ctx, core.UpsertPlatformIntegrationParams{
+			ID:             id.NewGeneration[id.PlatformIntegration](),
+			ShopID:         owner.shopID,
+			AccountID:      owner.accountID,
+			PlatformType:   platformType,
+			PlatformShopID: platformShopID,
+			IsActive:       pgtype.Bool{Bool: true, Valid: true},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to upsert platform integration")
+		}
+		if integration.AccountID != owner.accountID {
+			return ErrStoreConnectedElsewhere
+		}
+
+		return saveCredentials(tx, integration.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Store connected", "account_id", owner.accountID, "shop_id", owner.shopID, "platform", platformType, "integration_id", integration.ID, "platform_shop_id", platformShopID)
+
+	sync, err := m.startFullSync(ctx, integration, platformShopID, "", SyncTriggerManual, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start initial sync")
+	}
+
+	result := toIntegrationResult(integration)
+	result.Sync = sync
+	return &result, nil
+}
+
+// verifyError reports a failed check of new credentials, as ErrInvalidCredentials when the
+// platform rejected them
+func verifyError(err error, credentials string) error {
+	if errors.Is(err, connector.ErrUnauthorized) {
+		return errors.Wrap(ErrInvalidCredentials, err.Error())
+	}
+	return errors.Wrapf(err, "failed to verify %s", credentials)
+}
+
+// ListIntegrations lists the platform integrations of a shop
+func (m *InventorySyncManager) ListIntegrations(ctx context.Context, userID id.ID[id.User], shopDomain string) ([]IntegrationResult, error) {
+	shop, err := m.getUserShop(ctx, userID, shopDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	integrations, err := m.database.GetCore().GetPlatformIntegrationsByShopID(ctx, shop.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get platform integrations")
+	}
+
+	results := make([]IntegrationResult, 0, len(integrations))
+	for _, integration := range integrations {
+		results = append(results, toIntegrationResult(integration))
+	}
+	return results, nil
+}
+
+// getUserShop gets a shop the user has access to
+func (m *InventorySyncManager) getUserShop(ctx context.Context, userID id.ID[id.User], shopDomain string) (shopify.ShopifyStore, error) {
+	shop, err := m.database.GetShopify().GetShopifyStoreByDomain(ctx, shopDomain)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return shopify.ShopifyStore{}, ErrShopNotFound
+	}
+	if err != nil {
+		return shopify.ShopifyStore{}, errors.Wrap(err, "failed to get shop")
+	}
+
+	_, err = m.database.GetShopify().GetShopifyUserByUserAndStore(ctx, shopify.GetShopifyUserByUserAndStoreParams{
+		UserID:         userID,
+		ShopifyStoreID: shop.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return shopify.ShopifyStore{}, ErrShopAccessDenied
+	}
+	if err != nil {
+		return shopify.ShopifyStore{}, errors.Wrap(err, "failed to get shopify user")
+	}
+
+	return shop, nil
+}
+
+// getIntegrationOwner gets the owner of a store the user connects: the account of the shop when
+// a shop domain is given, the session's account joining a shop that has none yet, or otherwise
+// the session's account itself, which the user must be a user of
+func (m *InventorySyncManager) getIntegrationOwner(ctx context.Context, req ConnectOwner) (integrationOwner, error) {
+	if req.ShopDomain != "" {
+		shop, err := m.getUserShop(ctx, req.UserID, req.ShopDomain)
+		if err != nil {
+			return integrationOwner{}, err
+		}
+
+		var account core.MerchantAccount
+		err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
+			account, err = ensureShopAccount(ctx, tx.GetCore(), shop, req.UserID, req.AccountID)
+			return err
+		})
+		if err != nil {
+			return integrationOwner{}, err
+		}
+		return integrationOwner{accountID: account.ID, shopID: shop.ID}, nil
+	}
+
+	if req.AccountID == "" {
+		return integrationOwner{}, ErrAccountNotFound
+	}
+	isUser, err := m.database.GetCore().IsMerchantAccountUser(ctx, core.IsMerchantAccountUserParams{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+	})
+	if err != nil {
+		return integrationOwner{}, errors.Wrap(err, "failed to check account user")
+	}
+	if !isUser {
+		return integrationOwner{}, ErrAccountNotFound
+	}
+	return integrationOwner{accountID: req.AccountID}, nil
+}
+
+// getUserIntegration gets an integration the user has access to: an integration of an account the
+// user is a user of, or one connected from a shop the user has access to, for shops connected
+// before accounts existed that have not joined one yet
+func (m *InventorySyncManager) getUserIntegration(ctx context.Context, userID id.ID[id.User], integrationID id.ID[id.PlatformIntegration]) (core.PlatformIntegration, error) {
+	integration, err := m.database.GetCore().GetPlatformIntegrationByID(ctx, integrationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return core.PlatformIntegration{}, ErrIntegrationNotFound
+	}
+	if err != nil {
+		return core.PlatformIntegration{}, errors.Wrap(err, "failed to get integration")
+	}
+
+	if integration.AccountID != "" {
+		isUser, err := m.database.GetCore().IsMerchantAccountUser(ctx, core.IsMerchantAccountUserParams{
+			AccountID: integration.AccountID,
+			UserID:    userID,
+		})
+		if err != nil {
+			return core.PlatformIntegration{}, errors.Wrap(err, "failed to check account user")
+		}
+		if isUser {
+			return integration, nil
+		}
+	}
+
+	if integration.ShopID != "" {
+		_, err = m.database.GetShopify().GetShopifyUserByUserAndStore(ctx, shopify.GetShopifyUserByUserAndStoreParams{
+			UserID:         userID,
+			ShopifyStoreID: integration.ShopID,
+		})
+		if err == nil {
+			return integration, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return core.PlatformIntegration{}, errors.Wrap(err, "failed to get shopify user")
+		}
+	}
+	return core.PlatformIntegration{}, ErrIntegrationNotFound
+}
+
+// getSyncIntegration gets the integration whose syncs a request is about: the given one, checked
+// with getUserIntegration, or the Shopify integration of the shop when integrationID is empty.
+// found is false when the shop has no Shopify integration yet.
+func (m *InventorySyncManager) getSyncIntegration(ctx context.Context, userID id.ID[id.User], shopDomain string, integrationID id.ID[id.PlatformIntegration]) (integration core.PlatformIntegration, found bool, err error) {
+	if integrationID != "" {
+		integration, err = m.getUserIntegration(ctx, userID, integrationID)
+		return integration, err == nil, err
+	}
+
+	shop, err := m.getUserShop(ctx, userID, shopDomain)
+	if err != nil {
+		return core.PlatformIntegration{}, false, err
+	}
+	integration, err = m.database.GetCore().GetPlatformIntegrationByShopAndType(ctx, core.GetPlatformIntegrationByShopAndTypeParams{
+		ShopID:       shop.ID,
+		PlatformType: core.PlatformTypeShopify,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return core.PlatformIntegration{}, false, nil
+	}
+	if err != nil {
+		return core.PlatformIntegration{}, false, errors.Wrap(err, "failed to get integration")
+	}
+	return integration, true, nil
+}
@@ -0,0 +1,480 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento/magentotest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square/squaretest"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce/woocommercetest"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/pkg/errors"
+)
+
+func newWooCommerceStore(t *testing.T) *woocommercetest.Server {
+	t.Helper()
+
+	server := woocommercetest.NewServer(woocommercetest.DemoFixtures())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (st *syncTest) connectWooCommerce(storeURL, consumerSecret string) (*IntegrationResult, error) {
+	return st.manager.ConnectWooCommerce(st.ctx, ConnectWooCommerceRequest{
+		ConnectOwner:   ConnectOwner{UserID: st.userID, ShopDomain: testShopDomain},
+		StoreURL:       storeURL,
+		ConsumerKey:    woocommercetest.ConsumerKey,
+		ConsumerSecret: consumerSecret,
+	})
+}
+
+// connectCase connects a fake store of one platform to the test shop
+type connectCase struct {
+	platform core.PlatformType
+	// connect starts the fake store and connects it with valid credentials
+	connect func(t *testing.T, st *syncTest) (*IntegrationResult, error)
+	// connectInvalid connects it with wrong credentials, nil when the platform does not check them on connect
+	connectInvalid func(t *testing.T, st *syncTest) error
+}
+
+var connectCases = []connectCase{
+	{
+		platform: core.PlatformTypeWoocommerce,
+		connect: func(t *testing.T, st *syncTest) (*IntegrationResult, error) {
+			return st.connectWooCommerce(newWooCommerceStore(t).URL, woocommercetest.ConsumerSecret)
+		},
+		connectInvalid: func(t *testing.T, st *syncTest) error {
+			_, err := st.connectWooCommerce(newWooCommerceStore(t).URL, "cs_wrong")
+			return err
+		},
+	},
+	{
+		platform: core.PlatformTypeBigcommerce,
+		connect: func(t *testing.T, st *syncTest) (*IntegrationResult, error) {
+			newBigCommerceStore(t)
+			return st.claimBigCommerce(st.installBigCommerce(t))
+		},
+	},
+	{
+		platform: core.PlatformTypeSquare,
+		connect: func(t *testing.T, st *syncTest) (*IntegrationResult, error) {
+			newSquareSeller(t)
+			return st.connectSquare(squaretest.AccessToken)
+		},
+		connectInvalid: func(t *testing.T, st *syncTest) error {
+			newSquareSeller(t)
+			_, err := st.connectSquare("revoked")
+			return err
+		},
+	},
+	{
+		platform: core.PlatformTypeMagento,
+		connect: func(t *testing.T, st *syncTest) (*IntegrationResult, error) {
+			return st.connectMagento(newMagentoStore(t).URL, magentotest.AccessToken)
+		},
+		connectInvalid: func(t *testing.T, st *syncTest) error {
+			_, err := st.connectMagento(newMagentoStore(t).URL, "revoked")
+			return err
+		},
+	},
+}
+
+func TestConnectStoreStartsSync(t *testing.T) {
+	for _, tc := range connectCases {
+		t.Run(string(tc.platform), func(t *testing.T) {
+			st := newSyncTest(t)
+
+			result, err := tc.connect(t, st)
+			if err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			if result.PlatformType != tc.platform || result.Sync == nil || result.Sync.Status != SyncStatusInProgress {
+				t.Errorf("result = %+v, want a %s integration with its sync in progress", result, tc.platform)
+			}
+			if tasks := st.queue.InventorySyncs(); len(tasks) != 1 || tasks[0].IntegrationID != result.ID {
+				t.Errorf("enqueued syncs = %+v, want the initial sync of %s", tasks, result.ID)
+			}
+
+			// The registered factory connects with the stored credentials
+			integration, err := st.db.GetPlatformIntegrationByID(st.ctx, id.ID[id.PlatformIntegration](result.ID))
+			if err != nil {
+				t.Fatalf("GetPlatformIntegrationByID: %v", err)
+			}
+			conn, err := st.manager.connectors.Connect(st.ctx, integration)
+			if err != nil {
+				t.Fatalf("Connect: %v", err)
+			}
+			if _, err := conn.ListLocations(st.ctx, ""); err != nil {
+				t.Errorf("ListLocations: %v", err)
+			}
+
+			integrations, err := st.manager.ListIntegrations(st.ctx, st.userID, testShopDomain)
+			if err != nil {
+				t.Fatalf("ListIntegrations: %v", err)
+			}
+			if len(integrations) != 1 || integrations[0].ID != result.ID {
+				t.Errorf("integrations = %+v, want the %s integration", integrations, tc.platform)
+			}
+		})
+	}
+}
+
+func TestConnectStoreRejectsInvalidCredentials(t *testing.T) {
+	for _, tc := range connectCases {
+		if tc.connectInvalid == nil {
+			continue
+		}
+		t.Run(string(tc.platform), func(t *testing.T) {
+			st := newSyncTest(t)
+
+			if err := tc.connectInvalid(t, st); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("got %v, want %v", err, ErrInvalidCredentials)
+			}
+
+			integrations, err := st.manager.ListIntegrations(st.ctx, st.userID, testShopDomain)
+			if err != nil {
+				t.Fatalf("ListIntegrations: %v", err)
+			}
+			if len(integrations) != 0 {
+				t.Errorf("got %d integrations, want none saved", len(integrations))
+			}
+		})
+	}
+}
+
+func TestConnectWooCommerceStoresCredentials(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	result, err := st.connectWooCommerce(store.URL+"/", woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+
+	credentials, err := st.db.GetWooCommerceCredentialsByIntegrationID(st.ctx, id.ID[id.PlatformIntegration](result.ID))
+	if err != nil {
+		t.Fatalf("GetWooCommerceCredentialsByIntegrationID: %v", err)
+	}
+	if credentials.StoreUrl != store.URL || credentials.ConsumerSecret.String() != woocommercetest.ConsumerSecret {
+		t.Errorf("credentials = %s, %s, want the normalized url and the secret", credentials.StoreUrl, credentials.ConsumerSecret)
+	}
+}
+func TestConnectWooCommerceRejectsStoreOfAnotherShop(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	if _, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret); err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+
+	// The same user connects the store again from a second shop
+	otherShop := "other-shop.myshopify.com"
+	otherShopID := id.NewGeneration[id.ShopifyStore]()
+	if _, err := st.db.CreateShopifyStore(st.ctx, shopify.CreateShopifyStoreParams{ID: otherShopID, ShopDomain: otherShop}); err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	if _, err := st.db.CreateShopifyUser(st.ctx, shopify.CreateShopifyUserParams{
+		ID:             id.NewGeneration[id.ShopifyUser](),
+		UserID:         st.userID,
+		ShopifyStoreID: otherShopID,
+	}); err != nil {
+		t.Fatalf("create shopify user: %v", err)
+	}
+
+	_, err := st.manager.ConnectWooCommerce(st.ctx, ConnectWooCommerceRequest{
+		ConnectOwner:   ConnectOwner{UserID: st.userID, ShopDomain: otherShop},
+		StoreURL:       store.URL,
+		ConsumerKey:    woocommercetest.ConsumerKey,
+		ConsumerSecret: woocommercetest.ConsumerSecret,
+	})
+	if !errors.Is(err, ErrStoreConnectedElsewhere) {
+		t.Fatalf("got %v, want %v", err, ErrStoreConnectedElsewhere)
+	}
+}
+
+func TestConnectStoreToAccountWithoutShop(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	// A merchant selling only on WooCommerce has an account but no Shopify shop
+	account, err := st.db.CreateMerchantAccount(st.ctx, core.CreateMerchantAccountParams{ID: id.NewGeneration[id.MerchantAccount](), Name: "Woo only", ReportingCurrency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateMerchantAccount: %v", err)
+	}
+	if err := st.db.AddMerchantAccountUser(st.ctx, core.AddMerchantAccountUserParams{AccountID: account.ID, UserID: st.userID}); err != nil {
+		t.Fatalf("AddMerchantAccountUser: %v", err)
+	}
+	owner := ConnectOwner{UserID: st.userID, AccountID: account.ID}
+
+	connected, err := st.manager.ConnectWooCommerce(st.ctx, ConnectWooCommerceRequest{
+		ConnectOwner:   owner,
+		StoreURL:       store.URL,
+		ConsumerKey:    woocommercetest.ConsumerKey,
+		ConsumerSecret: woocommercetest.ConsumerSecret,
+	})
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	integration, err := st.db.GetPlatformIntegrationByID(st.ctx, integrationID)
+	if err != nil {
+		t.Fatalf("GetPlatformIntegrationByID: %v", err)
+	}
+	if integration.ShopID != "" || integration.AccountID != account.ID {
+		t.Errorf("integration owned by shop %q and account %q, want only account %s", integration.ShopID, integration.AccountID, account.ID)
+	}
+
+	result, err := NewAccountManager(st.db).GetAccount(st.ctx, AccountRequest{UserID: st.userID, AccountID: account.ID})
+	if err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+	if len(result.Integrations) != 1 || result.Integrations[0].ID != connected.ID {
+		t.Errorf("account integrations = %+v, want the woocommerce store", result.Integrations)
+	}
+
+	// Syncs of the store need no shop, access is checked on the account
+	st.queue.Finish(connected.ID)
+	synced, err := st.manager.TriggerShopifySync(st.ctx, SyncRequest{UserID: st.userID, Force: true, Trigger: SyncTriggerManual, IntegrationID: integrationID})
+	if err != nil || synced.IntegrationID != connected.ID {
+		t.Fatalf("TriggerShopifySync = %+v, %v, want a sync of the store", synced, err)
+	}
+	if _, err := st.manager.GetSyncStatus(st.ctx, st.userID, "", integrationID); err != nil {
+		t.Errorf("GetSyncStatus: %v", err)
+	}
+
+	// Users of other accounts cannot see the store nor connect to the account
+	stranger := id.NewGeneration[id.User]()
+	if _, err := st.manager.GetSyncStatus(st.ctx, stranger, "", integrationID); !errors.Is(err, ErrIntegrationNotFound) {
+		t.Errorf("GetSyncStatus of another user got %v, want %v", err, ErrIntegrationNotFound)
+	}
+	_, err = st.manager.ConnectWooCommerce(st.ctx, ConnectWooCommerceRequest{
+		ConnectOwner:   ConnectOwner{UserID: stranger, AccountID: account.ID},
+		StoreURL:       store.URL,
+		ConsumerKey:    woocommercetest.ConsumerKey,
+		ConsumerSecret: woocommercetest.ConsumerSecret,
+	})
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("ConnectWooCommerce of another user got %v, want %v", err, ErrAccountNotFound)
+	}
+}
+
+func TestTriggerSyncOfConnectedStore(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	st.queue.Finish(connected.ID)
+
+	result, err := st.manager.TriggerShopifySync(st.ctx, SyncRequest{
+		UserID:        st.userID,
+		ShopDomain:    testShopDomain,
+		Force:         true,
+		Trigger:       SyncTriggerManual,
+		IntegrationID: id.ID[id.PlatformIntegration](connected.ID),
+	})
+	if err != nil {
+		t.Fatalf("TriggerShopifySync: %v", err)
+	}
+	if result.IntegrationID != connected.ID {
+		t.Errorf("synced integration %s, want %s", result.IntegrationID, connected.ID)
+	}
+
+	// Only the connected store is synced, no Shopify integration is created
+	integrations, err := st.manager.ListIntegrations(st.ctx, st.userID, testShopDomain)
+	if err != nil {
+		t.Fatalf("ListIntegrations: %v", err)
+	}
+	if len(integrations) != 1 {
+		t.Errorf("got %d integrations, want 1", len(integrations))
+	}
+
+	_, err = st.manager.TriggerShopifySync(st.ctx, SyncRequest{
+		UserID:        st.userID,
+		ShopDomain:    testShopDomain,
+		IntegrationID: id.NewGeneration[id.PlatformIntegration](),
+	})
+	if !errors.Is(err, ErrIntegrationNotFound) {
+		t.Errorf("got %v, want %v", err, ErrIntegrationNotFound)
+	}
+}
+
+func TestSyncOfConnectedStoreIsTrackedByIntegration(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	if err := st.manager.SyncLocations(st.ctx, integrationID, string(SyncTriggerManual)); err != nil {
+		t.Fatalf("SyncLocations: %v", err)
+	}
+
+	status, err := st.manager.GetSyncStatus(st.ctx, st.userID, testShopDomain, integrationID)
+	if err != nil {
+		t.Fatalf("GetSyncStatus: %v", err)
+	}
+	if status.IntegrationID != connected.ID || status.Status != SyncStatusInProgress {
+		t.Errorf("status = %+v, want the initial sync of the store in progress", status)
+	}
+
+	runs, err := st.manager.GetSyncRuns(st.ctx, st.userID, testShopDomain, integrationID, 10)
+	if err != nil {
+		t.Fatalf("GetSyncRuns: %v", err)
+	}
+	if len(runs) != 1 || runs[0].EntityType != EntityTypeLocation {
+		t.Errorf("runs = %+v, want the locations sync of the store", runs)
+	}
+
+	cancelled, err := st.manager.CancelSync(st.ctx, st.userID, testShopDomain, integrationID)
+	if err != nil {
+		t.Fatalf("CancelSync: %v", err)
+	}
+	if cancelled.IntegrationID != connected.ID {
+		t.Errorf("cancelled integration %s, want %s", cancelled.IntegrationID, connected.ID)
+	}
+
+	// The shop's own Shopify integration was never synced
+	status, err = st.manager.GetSyncStatus(st.ctx, st.userID, testShopDomain, "")
+	if err != nil {
+		t.Fatalf("GetSyncStatus: %v", err)
+	}
+	if status.Status != SyncStatusNeverSynced {
+		t.Errorf("shopify status = %+v, want never synced", status)
+	}
+
+	// Integrations of other shops are not found
+	other := id.NewGeneration[id.PlatformIntegration]()
+	if _, err := st.manager.GetSyncStatus(st.ctx, st.userID, testShopDomain, other); !errors.Is(err, ErrIntegrationNotFound) {
+		t.Errorf("GetSyncStatus got %v, want %v", err, ErrIntegrationNotFound)
+	}
+	if _, err := st.manager.GetSyncRuns(st.ctx, st.userID, testShopDomain, other, 10); !errors.Is(err, ErrIntegrationNotFound) {
+		t.Errorf("GetSyncRuns got %v, want %v", err, ErrIntegrationNotFound)
+	}
+	if _, err := st.manager.CancelSync(st.ctx, st.userID, testShopDomain, other); !errors.Is(err, ErrIntegrationNotFound) {
+		t.Errorf("CancelSync got %v, want %v", err, ErrIntegrationNotFound)
+	}
+
+	// Shops are only synced for the users that connected them
+	if _, err := st.manager.GetSyncStatus(st.ctx, st.userID, "unknown.myshopify.com", ""); !errors.Is(err, ErrShopNotFound) {
+		t.Errorf("GetSyncStatus of an unknown shop got %v, want %v", err, ErrShopNotFound)
+	}
+	stranger := id.NewGeneration[id.User]()
+	if _, err := st.manager.GetSyncRuns(st.ctx, stranger, testShopDomain, "", 10); !errors.Is(err, ErrShopAccessDenied) {
+		t.Errorf("GetSyncRuns of another user got %v, want %v", err, ErrShopAccessDenied)
+	}
+}
+
+func TestScheduledSyncsAreOptIn(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	if connected.SyncIntervalMinutes != 0 {
+		t.Errorf("sync interval = %d, want scheduled syncs off", connected.SyncIntervalMinutes)
+	}
+	schedules, err := st.manager.GetSyncSchedules(st.ctx)
+	if err != nil {
+		t.Fatalf("GetSyncSchedules: %v", err)
+	}
+	if len(schedules) != 0 {
+		t.Errorf("schedules = %+v, want none", schedules)
+	}
+
+	setInterval := func(integrationID id.ID[id.PlatformIntegration], minutes int) (*IntegrationResult, error) {
+		return st.manager.SetSyncInterval(st.ctx, SetSyncIntervalRequest{
+			UserID:          st.userID,
+			IntegrationID:   integrationID,
+			IntervalMinutes: minutes,
+		})
+	}
+
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	result, err := setInterval(integrationID, 120)
+	if err != nil {
+		t.Fatalf("SetSyncInterval: %v", err)
+	}
+	if result.SyncIntervalMinutes != 120 {
+		t.Errorf("sync interval = %d, want 120", result.SyncIntervalMinutes)
+	}
+	schedules, err = st.manager.GetSyncSchedules(st.ctx)
+	if err != nil {
+		t.Fatalf("GetSyncSchedules: %v", err)
+	}
+	if len(schedules) != 1 || schedules[0].IntegrationID != integrationID || schedules[0].Interval != 2*time.Hour {
+		t.Errorf("schedules = %+v, want the store every 2 hours", schedules)
+	}
+
+	for _, minutes := range []int{-1, 5, MaxSyncIntervalMinutes + 1} {
+		if _, err := setInterval(integrationID, minutes); !errors.Is(err, ErrInvalidSyncInterval) {
+			t.Errorf("%d minutes got %v, want %v", minutes, err, ErrInvalidSyncInterval)
+		}
+	}
+	if _, err := setInterval(id.NewGeneration[id.PlatformIntegration](), 120); !errors.Is(err, ErrIntegrationNotFound) {
+		t.Errorf("unknown integration got %v, want %v", err, ErrIntegrationNotFound)
+	}
+
+	if result, err := setInterval(integrationID, 0); err != nil || result.SyncIntervalMinutes != 0 {
+		t.Errorf("turning scheduled syncs off got %+v, %v", result, err)
+	}
+}
+
+func TestConnectStoreRejectsInvalidURL(t *testing.T) {
+	tests := []struct {
+		platform core.PlatformType
+		// newStore starts the fake store and returns its URL and how many product requests it got
+		newStore func(t *testing.T) (storeURL string, requests func() int)
+		connect  func(st *syncTest, storeURL string) error
+	}{
+		{
+			platform: core.PlatformTypeWoocommerce,
+			newStore: func(t *testing.T) (string, func() int) {
+				store := newWooCommerceStore(t)
+				return store.URL, func() int { return store.Requests("/products") }
+			},
+			connect: func(st *syncTest, storeURL string) error {
+				_, err := st.connectWooCommerce(storeURL, woocommercetest.ConsumerSecret)
+				return err
+			},
+		},
+		{
+			platform: core.PlatformTypeMagento,
+			newStore: func(t *testing.T) (string, func() int) {
+				store := newMagentoStore(t)
+				return store.URL, func() int { return store.Requests("/products") }
+			},
+			connect: func(st *syncTest, storeURL string) error {
+				_, err := st.connectMagento(storeURL, magentotest.AccessToken)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.platform), func(t *testing.T) {
+			st := newSyncTest(t)
+			st.manager.checkStoreURL = connector.CheckStoreURL
+			storeURL, requests := tt.newStore(t)
+
+			// The fake store itself is served over plain http from a loopback address
+			for _, invalid := range []string{"https://", storeURL, "http://shop.example.com", "https://10.0.0.8", "https://169.254.169.254"} {
+				if err := tt.connect(st, invalid); !errors.Is(err, ErrInvalidStoreURL) {
+					t.Errorf("%q got %v, want %v", invalid, err, ErrInvalidStoreURL)
+				}
+			}
+			if got := requests(); got != 0 {
+				t.Errorf("store got %d requests, want the credentials not sent", got)
+			}
+		})
+	}
+}
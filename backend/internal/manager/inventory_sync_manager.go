@@ -2,15 +2,21 @@ package manager
 
 import (
 	"context"
-	"strconv"
-	"strings"
+	"net/url"
 	"time"
 
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/bigcommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square"
+	shopifyconnector "github.com/ConradKurth/forecasting/backend/internal/connector/shopify"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce"
+	"github.com/ConradKurth/forecasting/backend/internal/currency"
 	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/events"
 	"github.com/ConradKurth/forecasting/backend/internal/interfaces"
 	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
 	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
-	shopifyapi "github.com/ConradKurth/forecasting/backend/internal/shopify"
 	"github.com/ConradKurth/forecasting/backend/pkg/id"
 	"github.com/ConradKurth/forecasting/backend/pkg/logger"
 	"github.com/jackc/pgx/v5"
@@ -27,11 +33,13 @@ const (
 	SyncStatusInProgress SyncStatus = "in_progress"
 	SyncStatusCompleted  SyncStatus = "completed"
 	SyncStatusFailed     SyncStatus = "failed"
+	SyncStatusCancelled  SyncStatus = "cancelled"
 
 	// Special result statuses
 	SyncStatusSyncStarted     SyncStatus = "sync_started"
 	SyncStatusNeverSynced     SyncStatus = "never_synced"
 	SyncStatusPartialSyncOnly SyncStatus = "partial_sync_only"
+	SyncStatusDryRun          SyncStatus = "dry_run"
 )
 
 // EntityType represents the type of entity being synchronized
@@ -60,6 +68,8 @@ func FromCoreStatus(coreStatus core.SyncStatus) SyncStatus {
 		return SyncStatusCompleted
 	case core.SyncStatusFailed:
 		return SyncStatusFailed
+	case core.SyncStatusCancelled:
+		return SyncStatusCancelled
 	default:
 		return SyncStatusPending
 	}
@@ -75,6 +85,8 @@ func ToCoreStatus(managerStatus SyncStatus) core.SyncStatus {
 		return core.SyncStatusCompleted
 	case SyncStatusFailed:
 		return core.SyncStatusFailed
+	case SyncStatusCancelled:
+		return core.SyncStatusCancelled
 	default:
 		return core.SyncStatusPending
 	}
@@ -90,6 +102,8 @@ func FromCoreEntity(coreEntity core.EntityType) EntityType {
 		return EntityTypeProduct
 	case core.EntityTypeInventory:
 		return EntityTypeInventoryItem
+	case core.EntityTypeInventoryLevels:
+		return EntityTypeInventoryLevel
 	case core.EntityTypeOrders:
 		return EntityTypeOrder
 	case core.EntityTypeLocations:
@@ -107,6 +121,8 @@ func ToCoreEntity(managerEntity EntityType) core.EntityType {
 		return core.EntityTypeProducts
 	case EntityTypeInventoryItem:
 		return core.EntityTypeInventory
+	case EntityTypeInventoryLevel:
+		return core.EntityTypeInventoryLevels
 	case EntityTypeOrder:
 		return core.EntityTypeOrders
 	case EntityTypeLocation:
@@ -120,23 +136,57 @@ func ToCoreEntity(managerEntity EntityType) core.EntityType {
 // It ensures data consistency by wrapping operations in database transactions
 // and coordinates between multiple repositories for complex sync workflows
 type InventorySyncManager struct {
-	database db.Database
-	queue    interfaces.Queue
+	database   db.Database
+	queue      interfaces.Queue
+	events     interfaces.SyncEventPublisher
+	connectors *connector.Registry
+
+	// checkStoreURL checks the URL of a self-hosted store before credentials are sent to it
+	checkStoreURL func(ctx context.Context, storeURL string) (*url.URL, error)
 }
 
-// NewInventorySyncManager creates a new InventorySyncManager instance
-func NewInventorySyncManager(database db.Database, queue interfaces.Queue) *InventorySyncManager {
+// NewInventorySyncManager creates a new InventorySyncManager instance with the connectors of every supported platform
+func NewInventorySyncManager(database db.Database, queue interfaces.Queue, events interfaces.SyncEventPublisher) *InventorySyncManager {
+	connectors := connector.NewRegistry()
+	connectors.Register(core.PlatformTypeShopify, shopifyconnector.Factory(database))
+	connectors.Register(core.PlatformTypeWoocommerce, woocommerce.Factory(database))
+	connectors.Register(core.PlatformTypeBigcommerce, bigcommerce.Factory(database))
+	connectors.Register(core.PlatformTypeSquare, square.Factory(database))
+	connectors.Register(core.PlatformTypeMagento, magento.Factory(database))
+
 	return &InventorySyncManager{
-		database: database,
-		queue:    queue,
+		database:   database,
+		queue:      queue,
+		events:     events,
+		connectors: connectors,
+
+		checkStoreURL: connector.CheckStoreURL,
 	}
 }
 
+// ErrSyncRunning is returned when another sync of the integration holds the sync lock
+var ErrSyncRunning = errors.New("another sync of the integration is running")
+
+// syncLockKey returns the advisory lock key that serializes the syncs of an integration
+func syncLockKey(integrationID id.ID[id.PlatformIntegration]) string {
+	return "sync:" + integrationID.String()
+}
+
 // SyncRequest represents a synchronization request
 type SyncRequest struct {
 	UserID     id.ID[id.User] `json:"user_id"`
 	ShopDomain string         `json:"shop_domain"`
 	Force      bool           `json:"force,omitempty"`
+	Trigger    SyncTrigger    `json:"trigger,omitempty"`
+
+	// DryRun compares the shop's data with the current rows without writing anything. It runs in
+	// the background and its diff is read back from the sync runs.
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// IntegrationID selects another integration of the user's account, such as a connected
+	// WooCommerce store, and ShopDomain is then not needed. When empty the shop's Shopify
+	// integration is synced, and created if needed.
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id,omitempty"`
 }
 
 // SyncResult represents the result of a synchronization operation
@@ -145,15 +195,30 @@ type SyncResult struct {
 	Status        SyncStatus `json:"status"`
 	LastSynced    *time.Time `json:"last_synced,omitempty"`
 	Error         string     `json:"error,omitempty"`
+
+	// RunID is the sync run recording a dry run that was started
+	RunID string `json:"run_id,omitempty"`
 }
 
-// ShopifySyncData holds all normalized data fetched from Shopify API
-type ShopifySyncData struct {
+// SyncData holds normalized data for one page fetched from a platform
+type SyncData struct {
 	Locations       []core.InsertLocationsBatchParams       `json:"locations"`
 	Products        []core.InsertProductsBatchParams        `json:"products"`
 	ProductVariants []core.InsertProductVariantsBatchParams `json:"product_variants"`
 	InventoryItems  []core.InsertInventoryItemsBatchParams  `json:"inventory_items"`
 	Orders          []core.InsertOrdersBatchParams          `json:"orders"`
+
+	// Levels reference their item and location by external ID, resolved to internal IDs on upsert
+	InventoryLevels []connector.InventoryLevel `json:"inventory_levels"`
+
+	// Line items reference their order and variant by external ID, resolved to internal IDs on upsert
+	OrderLineItems []SyncLineItem `json:"order_line_items"`
+}
+
+// SyncLineItem is a line item of a normalized order
+type SyncLineItem struct {
+	OrderExternalID string `json:"order_external_id"`
+	connector.OrderLineItem
 }
 
 // Stats for tracking sync progress
@@ -162,6 +227,7 @@ type SyncStats struct {
 	ProductsCount        int `json:"products_count"`
 	ProductVariantsCount int `json:"product_variants_count"`
 	InventoryItemsCount  int `json:"inventory_items_count"`
+	InventoryLevelsCount int `json:"inventory_levels_count"`
 	OrdersCount          int `json:"orders_count"`
 }
 
@@ -174,10 +240,25 @@ func (m *InventorySyncManager) TriggerShopifySync(ctx context.Context, req SyncR
 		return nil, errors.Wrap(err, "failed to get user")
 	}
 
+	// Another integration is synced with its own stored credentials, so only access to it is checked
+	if req.IntegrationID != "" {
+		integration, err := m.getUserIntegration(ctx, req.UserID, req.IntegrationID)
+		if err != nil {
+			return nil, err
+		}
+		if integration.PlatformType == core.PlatformTypeFileImport {
+			return nil, ErrNotSyncable
+		}
+		return m.startSync(ctx, integration, integration.PlatformShopID, "", req)
+	}
+
 	// Get shop and validate it exists
 	shop, err := m.database.GetShopify().GetShopifyStoreByDomain(ctx, req.ShopDomain)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrShopNotFound
+	}
 	if err != nil {
-		return nil, errors.Wrap(err, "shop not found")
+		return nil, errors.Wrap(err, "failed to get shop")
 	}
 
 	// Get shopify user to get access token
@@ -185,6 +266,9 @@ func (m *InventorySyncManager) TriggerShopifySync(ctx context.Context, req SyncR
 		UserID:         req.UserID,
 		ShopifyStoreID: shop.ID,
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrShopAccessDenied
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get access token")
 	}
@@ -200,8 +284,24 @@ func (m *InventorySyncManager) TriggerShopifySync(ctx context.Context, req SyncR
 		return nil, errors.Wrap(err, "failed to get/create integration")
 	}
 
+	return m.startSync(ctx, integration, req.ShopDomain, accessToken, req)
+}
+
+// startSync starts the dry run or the full sync of the integration the request asks for
+func (m *InventorySyncManager) startSync(ctx context.Context, integration core.PlatformIntegration, shopDomain, accessToken string, req SyncRequest) (*SyncResult, error) {
+	// A dry run leaves the sync state alone, it is only tracked by its run
+	if req.DryRun {
+		return m.startDryRun(ctx, integration, req.Trigger)
+	}
+
+	return m.startFullSync(ctx, integration, shopDomain, accessToken, req.Trigger, req.Force)
+}
+
+// startFullSync enqueues the integration's full sync and marks it in progress, unless it is
+// already running or, without force, was completed recently
+func (m *InventorySyncManager) startFullSync(ctx context.Context, integration core.PlatformIntegration, shopDomain, accessToken string, trigger SyncTrigger, force bool) (*SyncResult, error) {
 	// Check if sync should proceed
-	if !req.Force {
+	if !force {
 		shouldSkip, skipReason, err := m.shouldSkipSync(ctx, integration.ID)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to check sync status")
@@ -214,52 +314,52 @@ func (m *InventorySyncManager) TriggerShopifySync(ctx context.Context, req SyncR
 		}
 	}
 
-	// Set sync state to in_progress before enqueuing the task
-	err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
-		return m.updateSyncState(ctx, tx, integration.ID, EntityTypeFullSync, SyncStatusInProgress, "")
+	// Remember the state's heartbeat to tell whether the task already wrote the state once queued
+	before, err := m.database.GetCore().GetSyncState(ctx, core.GetSyncStateParams{
+		IntegrationID: integration.ID,
+		EntityType:    core.EntityTypeFullSync,
 	})
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to set sync state to in_progress")
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "failed to get sync state")
 	}
 
 	// Enqueue async sync task
-	err = m.queue.EnqueueShopifyInventorySync(ctx, integration.ID.String(), req.ShopDomain, accessToken)
+	err = m.queue.EnqueueShopifyInventorySync(ctx, integration.ID.String(), shopDomain, accessToken, string(trigger))
+	if errors.Is(err, interfaces.ErrTaskAlreadyQueued) {
+		// The queued or running sync covers this request
+		return &SyncResult{
+			IntegrationID: integration.ID.String(),
+			Status:        SyncStatusInProgress,
+		}, nil
+	}
 	if err != nil {
-		// If enqueueing fails, set status back to failed
-		_ = m.database.WithTx(ctx, func(tx *db.TxDB) error {
-			return m.updateSyncState(ctx, tx, integration.ID, EntityTypeFullSync, SyncStatusFailed, "failed to enqueue sync task")
-		})
+		// Nothing was queued, so the sync state is left as it was
 		return nil, errors.Wrap(err, "failed to enqueue sync task")
 	}
 
+	if err := m.markSyncQueued(ctx, integration.ID, before.HeartbeatAt); err != nil {
+		return nil, err
+	}
+
 	return &SyncResult{
 		IntegrationID: integration.ID.String(),
 		Status:        SyncStatusInProgress,
 	}, nil
 }
 
-// GetSyncStatus retrieves the current synchronization status for a shop
-func (m *InventorySyncManager) GetSyncStatus(ctx context.Context, userID id.ID[id.User], shopDomain string) (*SyncResult, error) {
-	// Get shop
-	shop, err := m.database.GetShopify().GetShopifyStoreByDomain(ctx, shopDomain)
-	if err != nil {
-		return nil, errors.Wrap(err, "shop not found")
-	}
-
+// GetSyncStatus retrieves the current synchronization status of an integration the user has
+// access to, or of the shop's Shopify integration when integrationID is empty
+func (m *InventorySyncManager) GetSyncStatus(ctx context.Context, userID id.ID[id.User], shopDomain string, integrationID id.ID[id.PlatformIntegration]) (*SyncResult, error) {
 	// Get integration - if it doesn't exist, return never synced status
-	integration, err := m.database.GetCore().GetPlatformIntegrationByShopAndType(ctx, core.GetPlatformIntegrationByShopAndTypeParams{
-		ShopID:       shop.ID,
-		PlatformType: core.PlatformTypeShopify,
-	})
+	integration, found, err := m.getSyncIntegration(ctx, userID, shopDomain, integrationID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			// No integration found - return never synced status
-			return &SyncResult{
-				IntegrationID: "",
-				Status:        SyncStatusNeverSynced,
-			}, nil
-		}
-		return nil, errors.Wrap(err, "failed to get integration")
+		return nil, err
+	}
+	if !found {
+		return &SyncResult{
+			IntegrationID: "",
+			Status:        SyncStatusNeverSynced,
+		}, nil
 	}
 
 	// Get sync states
@@ -307,10 +407,16 @@ func (m *InventorySyncManager) getOrCreateShopifyIntegration(ctx context.Context
 		return integration, nil
 	}
 
+	accountID, err := shopAccountID(ctx, m.database.GetCore(), shopID)
+	if err != nil {
+		return core.PlatformIntegration{}, err
+	}
+
 	// Create new integration
 	integration, err = m.database.GetCore().CreatePlatformIntegration(ctx, core.CreatePlatformIntegrationParams{
 		ID:             id.NewGeneration[id.PlatformIntegration](),
 		ShopID:         shopID,
+		AccountID:      accountID,
 		PlatformType:   core.PlatformTypeShopify,
 		PlatformShopID: shopDomain,
 		IsActive:       pgtype.Bool{Bool: true, Valid: true},
@@ -322,6 +428,36 @@ func (m *InventorySyncManager) getOrCreateShopifyIntegration(ctx context.Context
 	return integration, nil
 }
 
+// markSyncQueued sets the full sync state of a queued sync to in_progress so the sync shows as
+// running right away. The task sets the state itself once it starts, so the state is left alone
+// while the task holds the sync lock, or when the task already wrote it and bumped the heartbeat.
+func (m *InventorySyncManager) markSyncQueued(ctx context.Context, integrationID id.ID[id.PlatformIntegration], heartbeatBefore pgtype.Timestamp) error {
+	release, acquired, err := m.database.TryLock(ctx, syncLockKey(integrationID))
+	if err != nil {
+		return errors.Wrap(err, "failed to take sync lock")
+	}
+	if !acquired {
+		return nil
+	}
+	defer release()
+
+	err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		state, err := tx.GetCore().GetSyncState(ctx, core.GetSyncStateParams{
+			IntegrationID: integrationID,
+			EntityType:    core.EntityTypeFullSync,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return errors.Wrap(err, "failed to get sync state")
+		}
+		if state.HeartbeatAt.Valid != heartbeatBefore.Valid || !state.HeartbeatAt.Time.Equal(heartbeatBefore.Time) {
+			return nil
+		}
+
+		return m.updateSyncState(ctx, tx, integrationID, EntityTypeFullSync, SyncStatusInProgress, "")
+	})
+	return errors.Wrap(err, "failed to set sync state to in_progress")
+}
+
 // shouldSkipSync determines if a sync should be skipped based on current state
 func (m *InventorySyncManager) shouldSkipSync(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (bool, SyncStatus, error) {
 	syncState, err := m.database.GetCore().GetSyncState(ctx, core.GetSyncStateParams{
@@ -334,6 +470,11 @@ func (m *InventorySyncManager) shouldSkipSync(ctx context.Context, integrationID
 	}
 
 	if syncState.SyncStatus == core.SyncStatusInProgress {
+		// A sync that stopped sending heartbeats is stuck and must not block new ones
+		if isStale(syncState) {
+			logger.Warn("Ignoring stale in progress sync", "integration_id", integrationID, "heartbeat_at", syncState.HeartbeatAt.Time)
+			return false, "", nil
+		}
 		return true, SyncStatusInProgress, nil
 	}
 
@@ -347,58 +488,134 @@ func (m *InventorySyncManager) shouldSkipSync(ctx context.Context, integrationID
 	return false, "", nil
 }
 
-// SyncInventory performs comprehensive inventory synchronization for a platform integration
-// with optimized batch processing - fetch all data first, normalize, then batch insert
-func (m *InventorySyncManager) SyncInventory(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) error {
-	logger.Info("Starting optimized inventory sync", "integration_id", integrationID)
+// SyncInventory performs comprehensive inventory synchronization for a platform integration.
+// Data is streamed page by page through the sync pipeline and each page is committed on its own
+// along with a checkpoint, so a retried sync continues from the last committed page.
+// The trigger is recorded on the sync run and defaults to manual.
+func (m *InventorySyncManager) SyncInventory(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error {
+	return m.runSync(ctx, integrationID, syncScope{stateEntity: EntityTypeFullSync, entities: fullSyncEntities}, SyncTrigger(trigger))
+}
 
-	// Start a database transaction for consistency
-	return m.database.WithTx(ctx, func(tx *db.TxDB) error {
-		// Status should already be in_progress from TriggerShopifySync
+// SyncLocations refreshes only the locations of an integration, tracked by the locations sync state
+func (m *InventorySyncManager) SyncLocations(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error {
+	return m.runSync(ctx, integrationID, entityScope(EntityTypeLocation), SyncTrigger(trigger))
+}
 
-		// Get platform integration details
-		integration, err := tx.GetCore().GetPlatformIntegrationByID(ctx, integrationID)
-		if err != nil {
-			return errors.Wrap(err, "failed to get platform integration")
-		}
+// SyncProducts refreshes only the products, variants and inventory items of an integration,
+// tracked by the products sync state
+func (m *InventorySyncManager) SyncProducts(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error {
+	return m.runSync(ctx, integrationID, entityScope(EntityTypeProduct), SyncTrigger(trigger))
+}
 
-		// Get shopify store and access token
-		shopifyUser, err := m.getShopifyUser(ctx, tx, integration.ShopID)
-		if err != nil {
-			return errors.Wrap(err, "failed to get shopify user")
-		}
+// SyncOrders refreshes only the recent orders of an integration, tracked by the orders sync state
+func (m *InventorySyncManager) SyncOrders(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error {
+	return m.runSync(ctx, integrationID, entityScope(EntityTypeOrder), SyncTrigger(trigger))
+}
 
-		accessToken := shopifyUser.AccessToken.String()
-		if accessToken == "" {
-			return errors.New("no access token found")
-		}
+// runSync streams the entities of the scope from the integration's platform and records the outcome on the scope's
+// sync state and in a new sync run
+func (m *InventorySyncManager) runSync(ctx context.Context, integrationID id.ID[id.PlatformIntegration], scope syncScope, trigger SyncTrigger) error {
+	logger.Info("Starting streaming inventory sync", "integration_id", integrationID, "scope", scope.stateEntity, "trigger", trigger)
+
+	// A retry of a cancelled task finishes without syncing
+	cancelled, err := m.taskCancelled(ctx)
+	if err != nil {
+		return err
+	}
+	if cancelled {
+		logger.Info("Skipping retry of cancelled sync task", "integration_id", integrationID, "scope", scope.stateEntity)
+		return nil
+	}
 
-		// Create shopify client directly (without ShopifyManager dependency)
-		client := shopifyapi.NewClient(integration.PlatformShopID, accessToken)
+	// Concurrent syncs of one integration would race on the upserts. The task is retried later.
+	release, acquired, err := m.database.TryLock(ctx, syncLockKey(integrationID))
+	if err != nil {
+		return errors.Wrap(err, "failed to take sync lock")
+	}
+	if !acquired {
+		return errors.Wrapf(ErrSyncRunning, "integration %s", integrationID)
+	}
+	defer release()
+
+	// Full syncs are already in_progress from TriggerShopifySync, entity syncs are enqueued directly
+	run, err := m.startSyncRun(ctx, integrationID, scope, trigger)
+	if err != nil {
+		return err
+	}
+	m.publishEvent(ctx, run, events.SyncEvent{Type: events.SyncEventStarted, Phase: events.SyncPhaseSetup})
 
-		// Phase 1: Fetch all data from API
-		logger.Info("Phase 1: Fetching all data from Shopify API", "integration_id", integrationID)
+	stopHeartbeat := m.startHeartbeat(ctx, run)
+	defer stopHeartbeat()
 
-		syncData, err := m.fetchAllShopifyData(ctx, client, integrationID)
+	setupStarted := time.Now()
+
+	// Get platform integration details
+	integration, err := m.database.GetCore().GetPlatformIntegrationByID(ctx, integrationID)
+	if err != nil {
+		return m.handleSyncError(ctx, run, "failed to get platform integration", err)
+	}
+
+	// Pick the connector of the integration's platform, it loads the platform credentials
+	conn, err := m.connectors.Connect(ctx, integration)
+	if err != nil {
+		return m.handleSyncError(ctx, run, "failed to create platform connector", err)
+	}
+	m.recordCurrency(ctx, conn, integration)
+
+	// Pick up where an interrupted attempt left off
+	progress := make(map[EntityType]*entityProgress)
+	if scope.resumable() {
+		progress, err = m.loadCheckpoints(ctx, integrationID)
 		if err != nil {
-			return m.handleSyncError(ctx, tx, integrationID, "failed to fetch data from API", err)
+			return m.handleSyncError(ctx, run, "failed to load sync checkpoints", err)
 		}
+	}
+	run.totals = m.countEntities(ctx, conn, scope.entities)
+	run.setup = time.Since(setupStarted)
 
-		// Phase 2: Normalize and batch insert
-		logger.Info("Phase 2: Normalizing and batch inserting data", "integration_id", integrationID)
+	stats, err := m.runSyncPipeline(ctx, conn, run, progress)
+	run.stats = stats
+	if err != nil {
+		return m.handleSyncError(ctx, run, "failed to sync data from API", err)
+	}
 
-		if err := m.batchSyncAllData(ctx, tx, syncData); err != nil {
-			return m.handleSyncError(ctx, tx, integrationID, "failed to batch sync data", err)
+	// Retire records that disappeared on the platform and mark sync as completed; the checkpoints are no longer needed
+	finalizeStarted := time.Now()
+	err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		if scope.resumable() {
+			if err := m.softDeleteMissing(ctx, tx, integrationID, scope.entities); err != nil {
+				return err
+			}
+			if err := tx.GetCore().DeleteSyncCheckpointsByIntegrationID(ctx, integrationID); err != nil {
+				return errors.Wrap(err, "failed to delete sync checkpoints")
+			}
 		}
-
-		// Mark sync as completed
-		if err := m.updateSyncState(ctx, tx, integrationID, EntityTypeFullSync, SyncStatusCompleted, ""); err != nil {
-			return errors.Wrap(err, "failed to update sync state to completed")
+		if err := m.updateSyncState(ctx, tx, integrationID, scope.stateEntity, SyncStatusCompleted, ""); err != nil {
+			return err
 		}
 
-		logger.Info("Optimized inventory sync completed successfully", "integration_id", integrationID)
-		return nil
+		run.finalize = time.Since(finalizeStarted)
+		return m.finishSyncRun(ctx, tx, run, SyncStatusCompleted, "")
 	})
+	if err != nil {
+		return m.handleSyncError(ctx, run, "failed to update sync state to completed", err)
+	}
+
+	m.publishEvent(ctx, run, events.SyncEvent{
+		Type:         events.SyncEventCompleted,
+		Phase:        events.SyncPhaseFinalize,
+		RowsUpserted: stats.LocationsCount + stats.ProductsCount + stats.OrdersCount,
+	})
+
+	logger.Info("Streaming inventory sync completed successfully",
+		"integration_id", integrationID,
+		"scope", scope.stateEntity,
+		"locations", stats.LocationsCount,
+		"products", stats.ProductsCount,
+		"variants", stats.ProductVariantsCount,
+		"inventory_items", stats.InventoryItemsCount,
+		"orders", stats.OrdersCount)
+	return nil
 }
 
 // updateSyncState updates the sync state for a given integration and entity type
@@ -424,322 +641,241 @@ func (m *InventorySyncManager) updateSyncState(ctx context.Context, tx *db.TxDB,
 	return err
 }
 
-// getShopifyUser retrieves the shopify user for a given shop ID
-func (m *InventorySyncManager) getShopifyUser(ctx context.Context, tx *db.TxDB, shopID id.ID[id.ShopifyStore]) (shopify.ShopifyUser, error) {
-	// Get any shopify user for this store (there might be multiple, we just need one with valid access token)
-	shopifyUsers, err := tx.GetShopify().GetShopifyUsersByStore(ctx, shopID)
-	if err != nil {
-		return shopify.ShopifyUser{}, errors.Wrap(err, "failed to get shopify users")
+// handleSyncError handles sync errors by updating the sync state, finishing the run and logging.
+// The state is written in its own transaction since page transactions have already finished.
+func (m *InventorySyncManager) handleSyncError(ctx context.Context, run *syncRun, message string, err error) error {
+	// Cancelling the task context makes the sync fail wherever it is; record that as a cancellation
+	if errors.Is(err, errSyncCancelled) || m.cancelRequested(ctx, run) {
+		return m.handleSyncCancelled(ctx, run)
 	}
 
-	if len(shopifyUsers) == 0 {
-		return shopify.ShopifyUser{}, errors.New("no shopify users found for store")
+	fullError := errors.Wrap(err, message)
+	logger.Error("Sync error", "integration_id", run.integrationID, "error", fullError)
+
+	// Give the user something actionable for the failures we can classify
+	switch {
+	case errors.Is(err, connector.ErrUnauthorized):
+		fullError = errors.Wrap(fullError, "the platform rejected the credentials, the store may need to be reconnected")
+	case errors.Is(err, connector.ErrNotFound):
+		fullError = errors.Wrap(fullError, "the store or resource no longer exists on the platform")
+	case errors.Is(err, connector.ErrRateLimited):
+		fullError = errors.Wrap(fullError, "platform rate limit exceeded after retries")
+	case errors.Is(err, connector.ErrServer):
+		fullError = errors.Wrap(fullError, "platform API is unavailable")
+	case errors.Is(err, connector.ErrUnsupportedPlatform):
+		fullError = errors.Wrap(fullError, "syncing is not supported for this platform")
 	}
 
-	// Return the first user with a valid access token
-	for _, user := range shopifyUsers {
-		if user.AccessToken.String() != "" {
-			return user, nil
+	// Record the failure even when the sync context was cancelled
+	ctx = context.WithoutCancel(ctx)
+	updateErr := m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		if err := m.updateSyncState(ctx, tx, run.integrationID, run.scope.stateEntity, SyncStatusFailed, fullError.Error()); err != nil {
+			return err
 		}
+		return m.finishSyncRun(ctx, tx, run, SyncStatusFailed, fullError.Error())
+	})
+	if updateErr != nil {
+		logger.Error("Failed to update sync state to failed", "integration_id", run.integrationID, "error", updateErr)
 	}
 
-	return shopify.ShopifyUser{}, errors.New("no shopify user with valid access token found")
-}
-
-// handleSyncError handles sync errors by updating the sync state and logging
-func (m *InventorySyncManager) handleSyncError(ctx context.Context, tx *db.TxDB, integrationID id.ID[id.PlatformIntegration], message string, err error) error {
-	fullError := errors.Wrap(err, message)
-	logger.Error("Sync error", "integration_id", integrationID, "error", fullError)
-
-	if updateErr := m.updateSyncState(ctx, tx, integrationID, EntityTypeFullSync, SyncStatusFailed, fullError.Error()); updateErr != nil {
-		logger.Error("Failed to update sync state to failed", "integration_id", integrationID, "error", updateErr)
-	}
+	m.publishEvent(ctx, run, events.SyncEvent{Type: events.SyncEventFailed, Error: fullError.Error()})
 
 	return fullError
 }
 
-// fetchAllShopifyData fetches all data from Shopify API and normalizes it for batch insertion
-func (m *InventorySyncManager) fetchAllShopifyData(ctx context.Context, client *shopifyapi.Client, integrationID id.ID[id.PlatformIntegration]) (*ShopifySyncData, error) {
-	logger.Info("Fetching all Shopify data")
-
-	var locations []shopifyapi.ShopifyLocation
-	var products []shopifyapi.ShopifyProduct
-	var orders []shopifyapi.ShopifyOrder
-
-	// Fetch locations
-	logger.Info("Fetching locations from API")
-	pageInfo := ""
-	for {
-		response, err := client.GetLocations(ctx, 250, pageInfo)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to fetch locations")
-		}
-		locations = append(locations, response.Locations...)
-		if response.Pagination.NextPageInfo == "" {
-			break
-		}
-		pageInfo = response.Pagination.NextPageInfo
-	}
-	logger.Info("Locations fetched", "count", len(locations))
-
-	// Fetch products
-	logger.Info("Fetching products from API")
-	pageInfo = ""
-	for {
-		response, err := client.GetProducts(ctx, 250, pageInfo)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to fetch products")
-		}
-		products = append(products, response.Products...)
-		if response.Pagination.NextPageInfo == "" {
-			break
-		}
-		pageInfo = response.Pagination.NextPageInfo
-	}
-	logger.Info("Products fetched", "count", len(products))
-
-	// TODO: Fetch orders from last 30 days - disabled due to Shopify protected customer data restrictions
-	// Requires Shopify app approval for protected customer data access
-	// logger.Info("Fetching orders from API")
-	// createdAtMin := time.Now().AddDate(0, 0, -30)
-	// pageInfo = ""
-	// for {
-	// 	response, err := client.GetOrders(ctx, createdAtMin, 250, pageInfo)
-	// 	if err != nil {
-	// 		return nil, errors.Wrap(err, "failed to fetch orders")
-	// 	}
-	// 	orders = append(orders, response.Orders...)
-	// 	if response.Pagination.NextPageInfo == "" {
-	// 		break
-	// 	}
-	// 	pageInfo = response.Pagination.NextPageInfo
-	// }
-	// logger.Info("Orders fetched", "count", len(orders))
-
-	logger.Info("All data fetched successfully",
-		"locations", len(locations),
-		"products", len(products),
-		"orders", len(orders))
-
-	// Now normalize all data for batch insertion
-	return m.normalizeShopifyData(ctx, client, integrationID, locations, products, orders)
-}
-
-// normalizeShopifyData converts raw Shopify API data into normalized database structures
-func (m *InventorySyncManager) normalizeShopifyData(ctx context.Context, client *shopifyapi.Client, integrationID id.ID[id.PlatformIntegration],
-	locations []shopifyapi.ShopifyLocation, products []shopifyapi.ShopifyProduct, orders []shopifyapi.ShopifyOrder) (*ShopifySyncData, error) {
-
-	logger.Info("Normalizing Shopify data for batch insertion")
+// normalizePage converts a page of platform records into core rows with newly generated IDs.
+// Rows that already exist keep their IDs, since the upserts match on external ID.
+func (m *InventorySyncManager) normalizePage(integrationID id.ID[id.PlatformIntegration], page fetchedPage) *SyncData {
 	now := pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}
 
-	syncData := &ShopifySyncData{}
-
-	// Track external ID to internal ID mappings for products
-	productIDMap := make(map[string]id.ID[id.Product])
+	syncData := &SyncData{InventoryLevels: page.inventoryLevels}
 
 	// 1. Normalize locations
-	for _, location := range locations {
-		var addressParts []string
-		if location.Address1 != "" {
-			addressParts = append(addressParts, location.Address1)
-		}
-		if location.Address2 != "" {
-			addressParts = append(addressParts, location.Address2)
-		}
-		if location.City != "" {
-			addressParts = append(addressParts, location.City)
-		}
-		address := strings.Join(addressParts, ", ")
-
+	for _, location := range page.locations {
 		syncData.Locations = append(syncData.Locations, core.InsertLocationsBatchParams{
 			ID:            id.NewGeneration[id.Location](),
 			IntegrationID: integrationID,
-			ExternalID:    pgtype.Text{String: strconv.FormatInt(location.ID, 10), Valid: true},
+			ExternalID:    pgtype.Text{String: location.ExternalID, Valid: true},
 			Name:          location.Name,
-			Address:       pgtype.Text{String: address, Valid: address != ""},
+			Address:       pgtype.Text{String: location.Address, Valid: location.Address != ""},
 			Country:       pgtype.Text{String: location.Country, Valid: location.Country != ""},
 			Province:      pgtype.Text{String: location.Province, Valid: location.Province != ""},
-			IsActive:      pgtype.Bool{Bool: true, Valid: true},
+			IsActive:      pgtype.Bool{Bool: location.Active, Valid: true},
 			CreatedAt:     now,
 			UpdatedAt:     now,
 		})
 	}
 
-	// 2. Normalize products
-	for _, product := range products {
-		var status core.ProductStatus
-		switch product.Status {
-		case "active":
-			status = core.ProductStatusActive
-		case "archived":
-			status = core.ProductStatusArchived
-		case "draft":
-			status = core.ProductStatusDraft
-		default:
-			status = core.ProductStatusDraft
-		}
+	// Variants can share an inventory item, each item is upserted once per page
+	seenInventoryItems := make(map[string]bool)
 
+	// 2. Normalize products
+	for _, product := range page.products {
 		productID := id.NewGeneration[id.Product]()
-		productIDMap[strconv.FormatInt(product.ID, 10)] = productID
+
+		var publishedAt pgtype.Timestamp
+		if product.PublishedAt != nil {
+			publishedAt = pgtype.Timestamp{Time: product.PublishedAt.UTC(), Valid: true}
+		}
 
 		syncData.Products = append(syncData.Products, core.InsertProductsBatchParams{
 			ID:            productID,
 			IntegrationID: integrationID,
-			ExternalID:    pgtype.Text{String: strconv.FormatInt(product.ID, 10), Valid: true},
+			ExternalID:    pgtype.Text{String: product.ExternalID, Valid: true},
 			Title:         product.Title,
 			Handle:        product.Handle,
 			ProductType:   pgtype.Text{String: product.ProductType, Valid: product.ProductType != ""},
-			Status:        status,
+			Status:        product.Status,
+			Vendor:        pgtype.Text{String: product.Vendor, Valid: product.Vendor != ""},
+			Tags:          normalizeTags(product.Tags),
+			OptionNames:   textArray(product.Options),
+			PublishedAt:   publishedAt,
 			CreatedAt:     now,
 			UpdatedAt:     now,
 		})
 
 		// 3. Normalize product variants for this product
 		for _, variant := range product.Variants {
-			var price pgtype.Numeric
-			if err := price.Scan(variant.Price); err != nil {
-				logger.Warn("Failed to parse variant price", "variant_id", variant.ID, "price", variant.Price, "error", err)
-				price = pgtype.Numeric{}
-			}
-
 			syncData.ProductVariants = append(syncData.ProductVariants, core.InsertProductVariantsBatchParams{
 				ID:              id.NewGeneration[id.ProductVariant](),
 				ProductID:       productID,
-				ExternalID:      pgtype.Text{String: strconv.FormatInt(variant.ID, 10), Valid: true},
+				IntegrationID:   integrationID,
+				ExternalID:      pgtype.Text{String: variant.ExternalID, Valid: true},
 				Sku:             pgtype.Text{String: variant.SKU, Valid: variant.SKU != ""},
-				Price:           price,
-				InventoryItemID: pgtype.Text{String: strconv.FormatInt(variant.InventoryItemID, 10), Valid: variant.InventoryItemID != 0},
+				Price:           parseAmount(variant.Price, "variant price", variant.ExternalID),
+				InventoryItemID: pgtype.Text{String: variant.InventoryItemID, Valid: variant.InventoryItemID != ""},
+				Barcode:         pgtype.Text{String: variant.Barcode, Valid: variant.Barcode != ""},
+				OptionValues:    textArray(variant.Options),
+				CompareAtPrice:  parseAmount(variant.CompareAtPrice, "variant compare at price", variant.ExternalID),
+				Weight:          parseAmount(variant.Weight, "variant weight", variant.ExternalID),
+				WeightUnit:      pgtype.Text{String: variant.WeightUnit, Valid: variant.WeightUnit != ""},
 				CreatedAt:       now,
 				UpdatedAt:       now,
 			})
-		}
-	}
-
-	// 4. Get inventory items for all variants
-	inventoryItemIDs := make(map[int64]bool)
-	var inventoryItemIDList []int64
-
-	for _, product := range products {
-		for _, variant := range product.Variants {
-			if variant.InventoryItemID != 0 && !inventoryItemIDs[variant.InventoryItemID] {
-				inventoryItemIDs[variant.InventoryItemID] = true
-				inventoryItemIDList = append(inventoryItemIDList, variant.InventoryItemID)
-			}
-		}
-	}
 
-	// Fetch inventory items in batches
-	if len(inventoryItemIDList) > 0 {
-		logger.Info("Fetching inventory items", "count", len(inventoryItemIDList))
-		batchSize := 100
-		for i := 0; i < len(inventoryItemIDList); i += batchSize {
-			end := i + batchSize
-			if end > len(inventoryItemIDList) {
-				end = len(inventoryItemIDList)
-			}
-
-			batch := inventoryItemIDList[i:end]
-			response, err := client.GetInventoryItems(ctx, batch, 100, "")
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to get inventory items batch %d-%d", i, end)
-			}
-
-			for _, item := range response.InventoryItems {
-				var cost pgtype.Numeric
-				if item.Cost != "" {
-					if err := cost.Scan(item.Cost); err != nil {
-						logger.Warn("Failed to parse inventory item cost", "item_id", item.ID, "cost", item.Cost, "error", err)
-						cost = pgtype.Numeric{}
-					}
-				}
-
-				syncData.InventoryItems = append(syncData.InventoryItems, core.InsertInventoryItemsBatchParams{
-					ID:            id.NewGeneration[id.InventoryItem](),
-					IntegrationID: integrationID,
-					ExternalID:    pgtype.Text{String: strconv.FormatInt(item.ID, 10), Valid: true},
-					Sku:           pgtype.Text{String: item.SKU, Valid: item.SKU != ""},
-					Tracked:       pgtype.Bool{Bool: item.Tracked, Valid: true},
-					Cost:          cost,
-					CreatedAt:     now,
-					UpdatedAt:     now,
-				})
+			// 4. Normalize the inventory item behind the variant
+			item := variant.InventoryItem
+			if item == nil || seenInventoryItems[item.ExternalID] {
+				continue
 			}
+			seenInventoryItems[item.ExternalID] = true
+
+			syncData.InventoryItems = append(syncData.InventoryItems, core.InsertInventoryItemsBatchParams{
+				ID:            id.NewGeneration[id.InventoryItem](),
+				IntegrationID: integrationID,
+				ExternalID:    pgtype.Text{String: item.ExternalID, Valid: true},
+				Sku:           pgtype.Text{String: item.SKU, Valid: item.SKU != ""},
+				Tracked:       pgtype.Bool{Bool: item.Tracked, Valid: true},
+				Cost:          parseAmount(item.Cost, "inventory item cost", item.ExternalID),
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			})
 		}
 	}
 
 	// 5. Normalize orders
-	for _, order := range orders {
+	for _, order := range page.orders {
 		var cancelledAt pgtype.Timestamp
 		if order.CancelledAt != nil {
 			cancelledAt = pgtype.Timestamp{Time: *order.CancelledAt, Valid: true}
 		}
 
-		var financialStatus core.FinancialStatus
-		switch order.FinancialStatus {
-		case "pending":
-			financialStatus = core.FinancialStatusPending
-		case "authorized":
-			financialStatus = core.FinancialStatusAuthorized
-		case "partially_paid":
-			financialStatus = core.FinancialStatusPartiallyPaid
-		case "paid":
-			financialStatus = core.FinancialStatusPaid
-		case "partially_refunded":
-			financialStatus = core.FinancialStatusPartiallyRefunded
-		case "refunded":
-			financialStatus = core.FinancialStatusRefunded
-		case "voided":
-			financialStatus = core.FinancialStatusVoided
-		default:
-			financialStatus = core.FinancialStatusPending
-		}
-
-		var fulfillmentStatus core.FulfillmentStatus
-		if order.FulfillmentStatus != nil {
-			switch *order.FulfillmentStatus {
-			case "fulfilled":
-				fulfillmentStatus = core.FulfillmentStatusFulfilled
-			case "partial":
-				fulfillmentStatus = core.FulfillmentStatusPartial
-			case "restocked":
-				fulfillmentStatus = core.FulfillmentStatusRestocked
-			default:
-				fulfillmentStatus = core.FulfillmentStatusNull
-			}
-		} else {
-			fulfillmentStatus = core.FulfillmentStatusNull
-		}
-
-		var totalPrice pgtype.Numeric
-		if err := totalPrice.Scan(order.TotalPrice); err != nil {
-			logger.Warn("Failed to parse order total price", "order_id", order.ID, "price", order.TotalPrice, "error", err)
-			totalPrice = pgtype.Numeric{}
-		}
-
 		syncData.Orders = append(syncData.Orders, core.InsertOrdersBatchParams{
 			ID:                id.NewGeneration[id.Order](),
 			IntegrationID:     integrationID,
-			ExternalID:        pgtype.Text{String: strconv.FormatInt(order.ID, 10), Valid: true},
+			ExternalID:        pgtype.Text{String: order.ExternalID, Valid: true},
 			CreatedAt:         pgtype.Timestamp{Time: order.CreatedAt, Valid: true},
-			FinancialStatus:   financialStatus,
-			FulfillmentStatus: fulfillmentStatus,
-			TotalPrice:        totalPrice,
+			FinancialStatus:   order.FinancialStatus,
+			FulfillmentStatus: order.FulfillmentStatus,
+			TotalPrice:        parseAmount(order.TotalPrice, "order total price", order.ExternalID),
 			CancelledAt:       cancelledAt,
+
+			PresentmentCurrency:   parseCurrency(order.PresentmentCurrency, "order presentment currency", order.ExternalID),
+			PresentmentTotalPrice: parseAmount(order.PresentmentTotalPrice, "order presentment total price", order.ExternalID),
 		})
+
+		for _, line := range order.LineItems {
+			syncData.OrderLineItems = append(syncData.OrderLineItems, SyncLineItem{OrderExternalID: order.ExternalID, OrderLineItem: line})
+		}
 	}
 
-	logger.Info("Data normalization completed",
+	logger.Debug("Page normalization completed",
 		"locations", len(syncData.Locations),
 		"products", len(syncData.Products),
 		"variants", len(syncData.ProductVariants),
 		"inventory_items", len(syncData.InventoryItems),
-		"orders", len(syncData.Orders))
+		"inventory_levels", len(syncData.InventoryLevels),
+		"orders", len(syncData.Orders),
+		"order_line_items", len(syncData.OrderLineItems))
+
+	return syncData
+}
+
+// parseAmount parses a decimal amount of a record. An empty amount is NULL, as is one that
+// cannot be parsed, which is logged rather than failing the page.
+func parseAmount(amount, what, externalID string) pgtype.Numeric {
+	var value pgtype.Numeric
+	if amount == "" {
+		return value
+	}
+	if err := value.Scan(amount); err != nil {
+		logger.Warn("Failed to parse amount", "field", what, "external_id", externalID, "value", amount, "error", err)
+		return pgtype.Numeric{}
+	}
+	return value
+}
+
+// textArray returns the values for a TEXT[] NOT NULL column, which stores no values as an empty array
+func textArray(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
 
-	return syncData, nil
+// parseCurrency normalizes a currency code for storage, logging and dropping codes that are not ISO 4217
+func parseCurrency(code, what, externalID string) pgtype.Text {
+	if code == "" {
+		return pgtype.Text{}
+	}
+	normalized, err := currency.Normalize(code)
+	if err != nil {
+		logger.Warn("Failed to parse currency", "field", what, "external_id", externalID, "value", code, "error", err)
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: normalized, Valid: true}
+}
+
+// recordCurrency saves the store currency of connectors that report it on the integration when it
+// changed. The sync goes on without it when it cannot be read.
+func (m *InventorySyncManager) recordCurrency(ctx context.Context, conn connector.PlatformConnector, integration core.PlatformIntegration) {
+	reader, ok := conn.(connector.CurrencyReader)
+	if !ok {
+		return
+	}
+
+	code, err := reader.Currency(ctx)
+	if err != nil {
+		logger.Warn("Failed to read store currency", "integration_id", integration.ID, "error", err)
+		return
+	}
+	storeCurrency := parseCurrency(code, "store currency", integration.PlatformShopID)
+	if !storeCurrency.Valid || storeCurrency == integration.Currency {
+		return
+	}
+
+	if _, err := m.database.GetCore().SetPlatformIntegrationCurrency(ctx, core.SetPlatformIntegrationCurrencyParams{
+		ID:       integration.ID,
+		Currency: storeCurrency,
+	}); err != nil {
+		logger.Warn("Failed to save store currency", "integration_id", integration.ID, "currency", storeCurrency.String, "error", err)
+		return
+	}
+	logger.Info("Recorded store currency", "integration_id", integration.ID, "currency", storeCurrency.String)
 }
 
 // batchSyncAllData performs batch insertion of all normalized data
-func (m *InventorySyncManager) batchSyncAllData(ctx context.Context, tx *db.TxDB, syncData *ShopifySyncData) error {
+func (m *InventorySyncManager) batchSyncAllData(ctx context.Context, tx *db.TxDB, integrationID id.ID[id.PlatformIntegration], syncData *SyncData) error {
 	const batchSize = 250
 
 	// 1. Batch insert locations
@@ -753,9 +889,17 @@ func (m *InventorySyncManager) batchSyncAllData(ctx context.Context, tx *db.TxDB
 	// 2. Batch insert products
 	if len(syncData.Products) > 0 {
 		logger.Info("Batch inserting products", "count", len(syncData.Products))
-		if err := m.batchInsertProducts(ctx, tx, syncData.Products, batchSize); err != nil {
+		storedIDs, err := m.batchInsertProducts(ctx, tx, syncData.Products, batchSize)
+		if err != nil {
 			return errors.Wrap(err, "failed to batch insert products")
 		}
+
+		// Products that already existed keep their IDs, new variants of them must reference those
+		for i, variant := range syncData.ProductVariants {
+			if storedID, ok := storedIDs[variant.ProductID]; ok {
+				syncData.ProductVariants[i].ProductID = storedID
+			}
+		}
 	}
 
 	// 3. Batch insert product variants
@@ -774,7 +918,15 @@ func (m *InventorySyncManager) batchSyncAllData(ctx context.Context, tx *db.TxDB
 		}
 	}
 
-	// 5. Batch insert orders
+	// 5. Upsert inventory levels, after the items and locations they reference
+	if len(syncData.InventoryLevels) > 0 {
+		logger.Info("Upserting inventory levels", "count", len(syncData.InventoryLevels))
+		if err := m.upsertInventoryLevels(ctx, tx, integrationID, syncData.InventoryLevels); err != nil {
+			return errors.Wrap(err, "failed to upsert inventory levels")
+		}
+	}
+
+	// 6. Batch insert orders
 	if len(syncData.Orders) > 0 {
 		logger.Info("Batch inserting orders", "count", len(syncData.Orders))
 		if err := m.batchInsertOrders(ctx, tx, syncData.Orders, batchSize); err != nil {
@@ -782,7 +934,15 @@ func (m *InventorySyncManager) batchSyncAllData(ctx context.Context, tx *db.TxDB
 		}
 	}
 
-	logger.Info("All batch insertions completed successfully")
+	// 7. Upsert order line items, after the orders and variants they reference
+	if len(syncData.OrderLineItems) > 0 {
+		logger.Info("Upserting order line items", "count", len(syncData.OrderLineItems))
+		if err := m.upsertOrderLineItems(ctx, tx, integrationID, syncData.OrderLineItems); err != nil {
+			return errors.Wrap(err, "failed to upsert order line items")
+		}
+	}
+
+	logger.Debug("Page batch insertions completed")
 	return nil
 }
 
@@ -818,8 +978,10 @@ func (m *InventorySyncManager) batchInsertLocations(ctx context.Context, tx *db.
 	return nil
 }
 
-// batchInsertProducts inserts products using upsert to handle conflicts
-func (m *InventorySyncManager) batchInsertProducts(ctx context.Context, tx *db.TxDB, products []core.InsertProductsBatchParams, batchSize int) error {
+// batchInsertProducts inserts products using upsert to handle conflicts. It returns the stored
+// IDs of the products that already existed, keyed by the IDs they were normalized with.
+func (m *InventorySyncManager) batchInsertProducts(ctx context.Context, tx *db.TxDB, products []core.InsertProductsBatchParams, batchSize int) (map[id.ID[id.Product]]id.ID[id.Product], error) {
+	storedIDs := make(map[id.ID[id.Product]]id.ID[id.Product])
 	for i := 0; i < len(products); i += batchSize {
 		end := i + batchSize
 		if end > len(products) {
@@ -830,7 +992,7 @@ func (m *InventorySyncManager) batchInsertProducts(ctx context.Context, tx *db.T
 		
 		// Use individual upserts instead of batch insert to handle ON CONFLICT
 		for _, product := range batch {
-			_, err := tx.GetCore().UpsertProduct(ctx, core.UpsertProductParams{
+			stored, err := tx.GetCore().UpsertProduct(ctx, core.UpsertProductParams{
 				ID:            product.ID,
 				IntegrationID: product.IntegrationID,
 				ExternalID:    product.ExternalID,
@@ -838,15 +1000,22 @@ func (m *InventorySyncManager) batchInsertProducts(ctx context.Context, tx *db.T
 				Handle:        product.Handle,
 				ProductType:   product.ProductType,
 				Status:        product.Status,
+				Vendor:        product.Vendor,
+				Tags:          product.Tags,
+				OptionNames:   product.OptionNames,
+				PublishedAt:   product.PublishedAt,
 			})
 			if err != nil {
-				return errors.Wrapf(err, "failed to upsert product %s", product.Handle)
+				return nil, errors.Wrapf(err, "failed to upsert product %s", product.Handle)
+			}
+			if stored.ID != product.ID {
+				storedIDs[product.ID] = stored.ID
 			}
 		}
 
 		logger.Info("Upserted products batch", "start", i, "end", end, "count", len(batch))
 	}
-	return nil
+	return storedIDs, nil
 }
 
 // batchInsertProductVariants inserts product variants using upsert to handle conflicts
@@ -864,10 +1033,16 @@ func (m *InventorySyncManager) batchInsertProductVariants(ctx context.Context, t
 			_, err := tx.GetCore().UpsertProductVariant(ctx, core.UpsertProductVariantParams{
 				ID:              variant.ID,
 				ProductID:       variant.ProductID,
+				IntegrationID:   variant.IntegrationID,
 				ExternalID:      variant.ExternalID,
 				Sku:             variant.Sku,
 				Price:           variant.Price,
 				InventoryItemID: variant.InventoryItemID,
+				Barcode:         variant.Barcode,
+				OptionValues:    variant.OptionValues,
+				CompareAtPrice:  variant.CompareAtPrice,
+				Weight:          variant.Weight,
+				WeightUnit:      variant.WeightUnit,
 			})
 			if err != nil {
 				return errors.Wrapf(err, "failed to upsert product variant %v", variant.ExternalID)
@@ -930,6 +1105,9 @@ func (m *InventorySyncManager) batchInsertOrders(ctx context.Context, tx *db.TxD
 				FulfillmentStatus: order.FulfillmentStatus,
 				TotalPrice:        order.TotalPrice,
 				CancelledAt:       order.CancelledAt,
+
+				PresentmentCurrency:   order.PresentmentCurrency,
+				PresentmentTotalPrice: order.PresentmentTotalPrice,
 			})
 			if err != nil {
 				return errors.Wrapf(err, "failed to upsert order %v", order.ExternalID)
@@ -940,3 +1118,142 @@ func (m *InventorySyncManager) batchInsertOrders(ctx context.Context, tx *db.TxD
 	}
 	return nil
 }
+
+// upsertInventoryLevels resolves the external IDs of each level's item and location and upserts the level.
+// Levels of items or locations that were not synced, such as untracked or deleted ones, are skipped.
+func (m *InventorySyncManager) upsertInventoryLevels(ctx context.Context, tx *db.TxDB, integrationID id.ID[id.PlatformIntegration], levels []connector.InventoryLevel) error {
+	itemIDs := make(map[string]*id.ID[id.InventoryItem])
+	locationIDs := make(map[string]*id.ID[id.Location])
+	skipped := 0
+
+	for _, level := range levels {
+		itemID, ok := itemIDs[level.InventoryItemID]
+		if !ok {
+			item, err := tx.GetCore().GetInventoryItemByExternalID(ctx, core.GetInventoryItemByExternalIDParams{
+				IntegrationID: integrationID,
+				ExternalID:    pgtype.Text{String: level.InventoryItemID, Valid: true},
+			})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return errors.Wrapf(err, "failed to get inventory item %s", level.InventoryItemID)
+			}
+			if err == nil {
+				itemID = &item.ID
+			}
+			itemIDs[level.InventoryItemID] = itemID
+		}
+
+		locationID, ok := locationIDs[level.LocationID]
+		if !ok {
+			location, err := tx.GetCore().GetLocationByExternalID(ctx, core.GetLocationByExternalIDParams{
+				IntegrationID: integrationID,
+				ExternalID:    pgtype.Text{String: level.LocationID, Valid: true},
+			})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return errors.Wrapf(err, "failed to get location %s", level.LocationID)
+			}
+			if err == nil {
+				locationID = &location.ID
+			}
+			locationIDs[level.LocationID] = locationID
+		}
+
+		if itemID == nil || locationID == nil {
+			skipped++
+			continue
+		}
+
+		_, err := tx.GetCore().UpsertInventoryLevel(ctx, core.UpsertInventoryLevelParams{
+			ID:              id.NewGeneration[id.InventoryLevel](),
+			InventoryItemID: *itemID,
+			LocationID:      *locationID,
+			Available:       pgtype.Int4{Int32: int32(level.Available), Valid: true},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to upsert inventory level of item %s at location %s", level.InventoryItemID, level.LocationID)
+		}
+	}
+
+	if skipped > 0 {
+		logger.Debug("Skipped inventory levels of unknown items or locations", "integration_id", integrationID, "count", skipped)
+	}
+	return nil
+}
+
+// orderLineVariant is a stored variant an order line item refers to, along with its inventory item
+type orderLineVariant struct {
+	variant core.ProductVariant
+	itemID  id.ID[id.InventoryItem]
+}
+
+// upsertOrderLineItems resolves the external IDs of each line item's order and variant and upserts
+// the line item. Lines are matched by their external ID within the order, so a line removed from an
+// order that is synced again is kept. Lines of variants that were not synced, such as custom or
+// deleted ones, or that have no inventory item are skipped.
+func (m *InventorySyncManager) upsertOrderLineItems(ctx context.Context, tx *db.TxDB, integrationID id.ID[id.PlatformIntegration], lines []SyncLineItem) error {
+	orderIDs := make(map[string]id.ID[id.Order])
+	variants := make(map[string]*orderLineVariant)
+	skipped := 0
+
+	for _, line := range lines {
+		orderID, ok := orderIDs[line.OrderExternalID]
+		if !ok {
+			order, err := tx.GetCore().GetOrderByExternalID(ctx, core.GetOrderByExternalIDParams{
+				IntegrationID: integrationID,
+				ExternalID:    pgtype.Text{String: line.OrderExternalID, Valid: true},
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to get order %s", line.OrderExternalID)
+			}
+			orderID = order.ID
+			orderIDs[line.OrderExternalID] = orderID
+		}
+
+		variant, ok := variants[line.VariantID]
+		if !ok && line.VariantID != "" {
+			stored, err := tx.GetCore().GetProductVariantByExternalID(ctx, core.GetProductVariantByExternalIDParams{
+				IntegrationID: integrationID,
+				ExternalID:    pgtype.Text{String: line.VariantID, Valid: true},
+			})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return errors.Wrapf(err, "failed to get product variant %s", line.VariantID)
+			}
+			if err == nil && stored.InventoryItemID.Valid {
+				item, err := tx.GetCore().GetInventoryItemByExternalID(ctx, core.GetInventoryItemByExternalIDParams{
+					IntegrationID: integrationID,
+					ExternalID:    stored.InventoryItemID,
+				})
+				if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					return errors.Wrapf(err, "failed to get inventory item of variant %s", line.VariantID)
+				}
+				if err == nil {
+					variant = &orderLineVariant{variant: stored, itemID: item.ID}
+				}
+			}
+			variants[line.VariantID] = variant
+		}
+
+		if variant == nil {
+			skipped++
+			continue
+		}
+
+		_, err := tx.GetCore().UpsertOrderLineItem(ctx, core.UpsertOrderLineItemParams{
+			ID:              id.NewGeneration[id.OrderLineItem](),
+			OrderID:         orderID,
+			ExternalID:      pgtype.Text{String: line.ExternalID, Valid: true},
+			ProductID:       variant.variant.ProductID,
+			VariantID:       variant.variant.ID,
+			InventoryItemID: variant.itemID,
+			Quantity:        int32(line.Quantity),
+			Price:           parseAmount(line.Price, "line item price", line.ExternalID),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to upsert line item %s of order %s", line.ExternalID, line.OrderExternalID)
+		}
+	}
+
+	if skipped > 0 {
+		logger.Debug("Skipped order line items of unknown variants", "integration_id", integrationID, "count", skipped)
+	}
+	return nil
+}
@@ -0,0 +1,623 @@
+package manager
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce/woocommercetest"
+	"github.com/ConradKurth/forecasting/backend/internal/crypto"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/db/dbtest"
+	"github.com/ConradKurth/forecasting/backend/internal/events"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
+	"github.com/ConradKurth/forecasting/backend/internal/worker/workertest"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	testShopDomain  = "test-shop.myshopify.com"
+	testAccessToken = "shpat_test"
+)
+
+// discardEvents drops every sync event
+type discardEvents struct{}
+
+func (discardEvents) PublishSyncEvent(context.Context, id.ID[id.PlatformIntegration], events.SyncEvent) error {
+	return nil
+}
+
+// parseFakeStoreURL accepts the URLs of fake stores, which listen on loopback over http
+func parseFakeStoreURL(ctx context.Context, storeURL string) (*url.URL, error) {
+	parsed, err := url.Parse(storeURL)
+	if err != nil || parsed.Host == "" {
+		return nil, errors.Errorf("%q is not a store url", storeURL)
+	}
+	return parsed, nil
+}
+
+// syncTest is an InventorySyncManager backed by in-memory fakes, with one user connected to one shop
+type syncTest struct {
+	ctx     context.Context
+	db      *dbtest.Memory
+	queue   *workertest.Queue
+	manager *InventorySyncManager
+	userID  id.ID[id.User]
+	shopID  id.ID[id.ShopifyStore]
+}
+
+func newSyncTest(t *testing.T) *syncTest {
+	t.Helper()
+
+	st := &syncTest{
+		ctx:    context.Background(),
+		db:     dbtest.New(),
+		queue:  workertest.NewQueue(),
+		userID: id.NewGeneration[id.User](),
+		shopID: id.NewGeneration[id.ShopifyStore](),
+	}
+	st.manager = NewInventorySyncManager(st.db, st.queue, discardEvents{})
+	st.manager.checkStoreURL = parseFakeStoreURL
+
+	if _, err := st.db.CreateUser(st.ctx, st.userID); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if _, err := st.db.CreateShopifyStore(st.ctx, shopify.CreateShopifyStoreParams{ID: st.shopID, ShopDomain: testShopDomain}); err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	if _, err := st.db.CreateShopifyUser(st.ctx, shopify.CreateShopifyUserParams{
+		ID:             id.NewGeneration[id.ShopifyUser](),
+		UserID:         st.userID,
+		ShopifyStoreID: st.shopID,
+		AccessToken:    crypto.EncryptedSecret(testAccessToken),
+	}); err != nil {
+		t.Fatalf("create shopify user: %v", err)
+	}
+
+	return st
+}
+
+// integration creates the shop's Shopify integration
+func (st *syncTest) integration(t *testing.T) id.ID[id.PlatformIntegration] {
+	t.Helper()
+
+	integration, err := st.db.CreatePlatformIntegration(st.ctx, core.CreatePlatformIntegrationParams{
+		ID:             id.NewGeneration[id.PlatformIntegration](),
+		ShopID:         st.shopID,
+		PlatformType:   core.PlatformTypeShopify,
+		PlatformShopID: testShopDomain,
+		IsActive:       pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("create integration: %v", err)
+	}
+	return integration.ID
+}
+
+// setState stores a sync state of the integration
+func (st *syncTest) setState(integrationID id.ID[id.PlatformIntegration], entity core.EntityType, status core.SyncStatus, lastSyncedAgo, heartbeatAgo time.Duration, errorMessage string) {
+	state := core.SyncState{
+		ID:            id.NewGeneration[id.SyncState](),
+		IntegrationID: integrationID,
+		EntityType:    entity,
+		SyncStatus:    status,
+		ErrorMessage:  pgtype.Text{String: errorMessage, Valid: errorMessage != ""},
+		HeartbeatAt:   pgtype.Timestamp{Time: time.Now().UTC().Add(-heartbeatAgo), Valid: true},
+	}
+	if lastSyncedAgo > 0 {
+		state.LastSyncedAt = pgtype.Timestamp{Time: time.Now().UTC().Add(-lastSyncedAgo), Valid: true}
+	}
+	st.db.SetSyncState(state)
+}
+
+func (st *syncTest) trigger(force bool) (*SyncResult, error) {
+	return st.manager.TriggerShopifySync(st.ctx, SyncRequest{
+		UserID:     st.userID,
+		ShopDomain: testShopDomain,
+		Force:      force,
+		Trigger:    SyncTriggerManual,
+	})
+}
+
+func TestTriggerShopifySyncEnqueuesSync(t *testing.T) {
+	st := newSyncTest(t)
+
+	result, err := st.trigger(false)
+	if err != nil {
+		t.Fatalf("TriggerShopifySync: %v", err)
+	}
+	if result.Status != SyncStatusInProgress {
+		t.Errorf("status = %q, want %q", result.Status, SyncStatusInProgress)
+	}
+
+	// The integration is created on the first sync
+	integrationID := id.ID[id.PlatformIntegration](result.IntegrationID)
+	if _, err := st.db.GetPlatformIntegrationByID(st.ctx, integrationID); err != nil {
+		t.Fatalf("integration %s was not created: %v", integrationID, err)
+	}
+
+	state, err := st.db.GetSyncState(st.ctx, core.GetSyncStateParams{IntegrationID: integrationID, EntityType: core.EntityTypeFullSync})
+	if err != nil {
+		t.Fatalf("GetSyncState: %v", err)
+	}
+	if state.SyncStatus != core.SyncStatusInProgress {
+		t.Errorf("sync state = %q, want in_progress", state.SyncStatus)
+	}
+
+	tasks := st.queue.InventorySyncs()
+	if len(tasks) != 1 {
+		t.Fatalf("got %d enqueued syncs, want 1", len(tasks))
+	}
+	want := workertest.InventorySyncTask{
+		IntegrationID: result.IntegrationID,
+		ShopDomain:    testShopDomain,
+		AccessToken:   testAccessToken,
+		Trigger:       string(SyncTriggerManual),
+	}
+	if tasks[0] != want {
+		t.Errorf("enqueued %+v, want %+v", tasks[0], want)
+	}
+}
+
+func TestTriggerShopifySyncReusesIntegration(t *testing.T) {
+	st := newSyncTest(t)
+	integrationID := st.integration(t)
+
+	result, err := st.trigger(false)
+	if err != nil {
+		t.Fatalf("TriggerShopifySync: %v", err)
+	}
+	if result.IntegrationID != integrationID.String() {
+		t.Errorf("integration = %s, want existing %s", result.IntegrationID, integrationID)
+	}
+}
+
+func TestTriggerShopifySyncSkips(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     core.SyncStatus
+		lastSynced time.Duration
+		force      bool
+		wantStatus SyncStatus
+		wantQueued bool
+	}{
+		{name: "sync in progress", status: core.SyncStatusInProgress, wantStatus: SyncStatusInProgress},
+		{name: "recently completed", status: core.SyncStatusCompleted, lastSynced: 5 * time.Minute, wantStatus: SyncStatusCompleted},
+		{name: "completed long ago", status: core.SyncStatusCompleted, lastSynced: 2 * time.Hour, wantStatus: SyncStatusInProgress, wantQueued: true},
+		{name: "forced after recent completion", status: core.SyncStatusCompleted, lastSynced: 5 * time.Minute, force: true, wantStatus: SyncStatusInProgress, wantQueued: true},
+		{name: "previous sync failed", status: core.SyncStatusFailed, wantStatus: SyncStatusInProgress, wantQueued: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newSyncTest(t)
+			integrationID := st.integration(t)
+			st.setState(integrationID, core.EntityTypeFullSync, tt.status, tt.lastSynced, 0, "")
+
+			result, err := st.trigger(tt.force)
+			if err != nil {
+				t.Fatalf("TriggerShopifySync: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if queued := len(st.queue.InventorySyncs()) == 1; queued != tt.wantQueued {
+				t.Errorf("queued = %v, want %v", queued, tt.wantQueued)
+			}
+		})
+	}
+}
+
+func TestTriggerShopifySyncAlreadyQueued(t *testing.T) {
+	st := newSyncTest(t)
+
+	if _, err := st.trigger(true); err != nil {
+		t.Fatalf("first TriggerShopifySync: %v", err)
+	}
+
+	// A forced sync skips the state check but the queue still rejects the duplicate
+	result, err := st.trigger(true)
+	if err != nil {
+		t.Fatalf("second TriggerShopifySync: %v", err)
+	}
+	if result.Status != SyncStatusInProgress {
+		t.Errorf("status = %q, want %q", result.Status, SyncStatusInProgress)
+	}
+	if got := len(st.queue.InventorySyncs()); got != 1 {
+		t.Errorf("got %d enqueued syncs, want 1", got)
+	}
+}
+
+func TestTriggerShopifySyncReplacesArchivedSync(t *testing.T) {
+	st := newSyncTest(t)
+
+	first, err := st.trigger(false)
+	if err != nil {
+		t.Fatalf("first TriggerShopifySync: %v", err)
+	}
+
+	// The sync failed permanently: asynq archived the task and the worker failed the sync state
+	st.queue.Archive(first.IntegrationID)
+	st.setState(id.ID[id.PlatformIntegration](first.IntegrationID), core.EntityTypeFullSync, core.SyncStatusFailed, 0, 0, "unauthorized")
+
+	if _, err := st.trigger(false); err != nil {
+		t.Fatalf("TriggerShopifySync after the archived sync: %v", err)
+	}
+	if got := len(st.queue.InventorySyncs()); got != 2 {
+		t.Errorf("got %d enqueued syncs, want the archived sync replaced by a second one", got)
+	}
+	if archived := st.queue.Archived(); len(archived) != 0 {
+		t.Errorf("archived = %v, want the archived sync replaced", archived)
+	}
+}
+
+func TestTriggerShopifySyncEnqueueFailure(t *testing.T) {
+	st := newSyncTest(t)
+	integrationID := st.integration(t)
+
+	enqueueErr := errors.New("redis unavailable")
+	st.queue.FailEnqueue(enqueueErr)
+
+	if _, err := st.trigger(false); !errors.Is(err, enqueueErr) {
+		t.Fatalf("TriggerShopifySync error = %v, want %v", err, enqueueErr)
+	}
+
+	// Nothing was queued, so the sync must not show as in progress or every later sync would be skipped
+	state, err := st.db.GetSyncState(st.ctx, core.GetSyncStateParams{IntegrationID: integrationID, EntityType: core.EntityTypeFullSync})
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("sync state = %+v, %v, want none", state, err)
+	}
+}
+
+func TestMarkSyncQueuedLeavesStateToStartedTask(t *testing.T) {
+	st := newSyncTest(t)
+	integrationID := st.integration(t)
+	st.setState(integrationID, core.EntityTypeFullSync, core.SyncStatusFailed, 0, time.Hour, "timeout")
+	before, err := st.db.GetSyncState(st.ctx, core.GetSyncStateParams{IntegrationID: integrationID, EntityType: core.EntityTypeFullSync})
+	if err != nil {
+		t.Fatalf("GetSyncState: %v", err)
+	}
+
+	// The task already ran and completed by the time the trigger marks it
+	st.setState(integrationID, core.EntityTypeFullSync, core.SyncStatusCompleted, time.Second, time.Second, "")
+	if err := st.manager.markSyncQueued(st.ctx, integrationID, before.HeartbeatAt); err != nil {
+		t.Fatalf("markSyncQueued: %v", err)
+	}
+	state, _ := st.db.GetSyncState(st.ctx, core.GetSyncStateParams{IntegrationID: integrationID, EntityType: core.EntityTypeFullSync})
+	if state.SyncStatus != core.SyncStatusCompleted {
+		t.Errorf("state after a finished task = %q, want completed", state.SyncStatus)
+	}
+
+	// The task is running and holds the sync lock
+	release, _, _ := st.db.TryLock(st.ctx, syncLockKey(integrationID))
+	if err := st.manager.markSyncQueued(st.ctx, integrationID, state.HeartbeatAt); err != nil {
+		t.Fatalf("markSyncQueued: %v", err)
+	}
+	release()
+	state, _ = st.db.GetSyncState(st.ctx, core.GetSyncStateParams{IntegrationID: integrationID, EntityType: core.EntityTypeFullSync})
+	if state.SyncStatus != core.SyncStatusCompleted {
+		t.Errorf("state while the task runs = %q, want it left to the task", state.SyncStatus)
+	}
+
+	// Once the task neither runs nor ran, the queued sync is in progress
+	if err := st.manager.markSyncQueued(st.ctx, integrationID, state.HeartbeatAt); err != nil {
+		t.Fatalf("markSyncQueued: %v", err)
+	}
+	state, _ = st.db.GetSyncState(st.ctx, core.GetSyncStateParams{IntegrationID: integrationID, EntityType: core.EntityTypeFullSync})
+	if state.SyncStatus != core.SyncStatusInProgress {
+		t.Errorf("state of a queued sync = %q, want in_progress", state.SyncStatus)
+	}
+}
+
+func TestTriggerShopifySyncRejectsUnknownShopAndUser(t *testing.T) {
+	st := newSyncTest(t)
+
+	if _, err := st.manager.TriggerShopifySync(st.ctx, SyncRequest{UserID: st.userID, ShopDomain: "other-shop.myshopify.com"}); err == nil {
+		t.Error("expected an error for an unknown shop")
+	}
+	if _, err := st.manager.TriggerShopifySync(st.ctx, SyncRequest{UserID: id.NewGeneration[id.User](), ShopDomain: testShopDomain}); err == nil {
+		t.Error("expected an error for an unknown user")
+	}
+	if got := len(st.queue.InventorySyncs()); got != 0 {
+		t.Errorf("got %d enqueued syncs, want 0", got)
+	}
+}
+
+func TestGetSyncStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		noIntegration  bool
+		states         []core.SyncState
+		wantStatus     SyncStatus
+		wantError      string
+		wantLastSynced bool
+	}{
+		{name: "no integration", noIntegration: true, wantStatus: SyncStatusNeverSynced},
+		{name: "no sync states", wantStatus: SyncStatusNeverSynced},
+		{
+			name:       "only an entity sync",
+			states:     []core.SyncState{{EntityType: core.EntityTypeLocations, SyncStatus: core.SyncStatusCompleted}},
+			wantStatus: SyncStatusPartialSyncOnly,
+		},
+		{
+			name:       "failed full sync",
+			states:     []core.SyncState{{EntityType: core.EntityTypeFullSync, SyncStatus: core.SyncStatusFailed, ErrorMessage: pgtype.Text{String: "boom", Valid: true}}},
+			wantStatus: SyncStatusFailed,
+			wantError:  "boom",
+		},
+		{
+			name: "completed full sync",
+			states: []core.SyncState{
+				{EntityType: core.EntityTypeOrders, SyncStatus: core.SyncStatusFailed},
+				{EntityType: core.EntityTypeFullSync, SyncStatus: core.SyncStatusCompleted, LastSyncedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}},
+			},
+			wantStatus:     SyncStatusCompleted,
+			wantLastSynced: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newSyncTest(t)
+
+			var integrationID id.ID[id.PlatformIntegration]
+			if !tt.noIntegration {
+				integrationID = st.integration(t)
+			}
+			for _, state := range tt.states {
+				state.ID = id.NewGeneration[id.SyncState]()
+				state.IntegrationID = integrationID
+				st.db.SetSyncState(state)
+			}
+
+			result, err := st.manager.GetSyncStatus(st.ctx, st.userID, testShopDomain, "")
+			if err != nil {
+				t.Fatalf("GetSyncStatus: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if result.IntegrationID != integrationID.String() {
+				t.Errorf("integration = %q, want %q", result.IntegrationID, integrationID)
+			}
+			if result.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", result.Error, tt.wantError)
+			}
+			if (result.LastSynced != nil) != tt.wantLastSynced {
+				t.Errorf("last synced = %v, want set: %v", result.LastSynced, tt.wantLastSynced)
+			}
+		})
+	}
+}
+
+func TestGetSyncStatusUnknownShop(t *testing.T) {
+	st := newSyncTest(t)
+
+	if _, err := st.manager.GetSyncStatus(st.ctx, st.userID, "other-shop.myshopify.com", ""); err == nil {
+		t.Error("expected an error for an unknown shop")
+	}
+}
+
+func TestShouldSkipSync(t *testing.T) {
+	tests := []struct {
+		name       string
+		noState    bool
+		status     core.SyncStatus
+		lastSynced time.Duration
+		heartbeat  time.Duration
+		wantSkip   bool
+		wantReason SyncStatus
+	}{
+		{name: "never synced", noState: true},
+		{name: "in progress", status: core.SyncStatusInProgress, heartbeat: time.Minute, wantSkip: true, wantReason: SyncStatusInProgress},
+		{name: "in progress without heartbeats", status: core.SyncStatusInProgress, heartbeat: syncStaleAfter + time.Minute},
+		{name: "completed within 30 minutes", status: core.SyncStatusCompleted, lastSynced: 29 * time.Minute, wantSkip: true, wantReason: SyncStatusCompleted},
+		{name: "completed over 30 minutes ago", status: core.SyncStatusCompleted, lastSynced: 31 * time.Minute},
+		{name: "failed", status: core.SyncStatusFailed, lastSynced: time.Minute},
+		{name: "cancelled", status: core.SyncStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newSyncTest(t)
+			integrationID := st.integration(t)
+			if !tt.noState {
+				st.setState(integrationID, core.EntityTypeFullSync, tt.status, tt.lastSynced, tt.heartbeat, "")
+			}
+
+			skip, reason, err := st.manager.shouldSkipSync(st.ctx, integrationID)
+			if err != nil {
+				t.Fatalf("shouldSkipSync: %v", err)
+			}
+			if skip != tt.wantSkip || reason != tt.wantReason {
+				t.Errorf("shouldSkipSync = (%v, %q), want (%v, %q)", skip, reason, tt.wantSkip, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSyncKeepsProductAttributes(t *testing.T) {
+	st := newSyncTest(t)
+	integrationID := st.integration(t)
+
+	publishedAt := time.Date(2025, 6, 3, 9, 0, 0, 0, time.FixedZone("EDT", -4*60*60))
+	products := []connector.Product{
+		{
+			ExternalID:  "7001",
+			Title:       "Classic Tee",
+			Handle:      "classic-tee",
+			Status:      core.ProductStatusActive,
+			Vendor:      "Fake Shop Apparel",
+			Tags:        []string{"Summer", " tees", "summer"},
+			Options:     []string{"Color", "Size"},
+			PublishedAt: &publishedAt,
+			Variants: []connector.Variant{{
+				ExternalID:     "8001",
+				SKU:            "TEE-BLK-M",
+				Price:          "24.00",
+				Barcode:        "0012345600011",
+				Options:        []string{"Black", "M"},
+				CompareAtPrice: "30.00",
+				Weight:         "0.2",
+				WeightUnit:     "kg",
+			}},
+		},
+		{
+			ExternalID: "7004",
+			Title:      "Enamel Pin",
+			Handle:     "enamel-pin",
+			Status:     core.ProductStatusDraft,
+			Variants:   []connector.Variant{{ExternalID: "8007", SKU: "PIN-LOGO", Price: "8.00"}},
+		},
+	}
+	err := st.db.WithTx(st.ctx, func(tx *db.TxDB) error {
+		return st.manager.batchSyncAllData(st.ctx, tx, integrationID, st.manager.normalizePage(integrationID, fetchedPage{products: products}))
+	})
+	if err != nil {
+		t.Fatalf("batchSyncAllData: %v", err)
+	}
+
+	tee, err := st.db.GetProductByExternalID(st.ctx, core.GetProductByExternalIDParams{IntegrationID: integrationID, ExternalID: pgtype.Text{String: "7001", Valid: true}})
+	if err != nil {
+		t.Fatalf("GetProductByExternalID: %v", err)
+	}
+	if tee.Vendor.String != "Fake Shop Apparel" || !slices.Equal(tee.Tags, []string{"summer", "tees"}) || !slices.Equal(tee.OptionNames, []string{"Color", "Size"}) {
+		t.Errorf("tee = %+v, want its vendor, normalized tags and option names", tee)
+	}
+	if !tee.PublishedAt.Valid || !tee.PublishedAt.Time.Equal(publishedAt) || tee.PublishedAt.Time.Location() != time.UTC {
+		t.Errorf("tee published at %v, want %v in UTC", tee.PublishedAt, publishedAt)
+	}
+
+	variant, err := st.db.GetProductVariantByExternalID(st.ctx, core.GetProductVariantByExternalIDParams{IntegrationID: integrationID, ExternalID: pgtype.Text{String: "8001", Valid: true}})
+	if err != nil {
+		t.Fatalf("GetProductVariantByExternalID: %v", err)
+	}
+	compareAtPrice, _ := variant.CompareAtPrice.Float64Value()
+	weight, _ := variant.Weight.Float64Value()
+	if variant.Barcode.String != "0012345600011" || !slices.Equal(variant.OptionValues, []string{"Black", "M"}) || compareAtPrice.Float64 != 30 || weight.Float64 != 0.2 || variant.WeightUnit.String != "kg" {
+		t.Errorf("variant = %+v, want its barcode, option values, compare at price and weight", variant)
+	}
+
+	// Products without tags or options store empty arrays, which the columns require
+	pin, err := st.db.GetProductByExternalID(st.ctx, core.GetProductByExternalIDParams{IntegrationID: integrationID, ExternalID: pgtype.Text{String: "7004", Valid: true}})
+	if err != nil {
+		t.Fatalf("GetProductByExternalID: %v", err)
+	}
+	if pin.Tags == nil || pin.OptionNames == nil || pin.Vendor.Valid || pin.PublishedAt.Valid {
+		t.Errorf("pin = %+v, want empty tags and option names and no vendor or published time", pin)
+	}
+
+	// Tags match whatever case they are asked for in
+	tagged, err := st.manager.GetProductsByTags(st.ctx, ProductsByTagsRequest{
+		UserID:        st.userID,
+		IntegrationID: integrationID,
+		Tags:          []string{"SUMMER "},
+	})
+	if err != nil || len(tagged) != 1 || tagged[0].ID != tee.ID.String() || !slices.Equal(tagged[0].Tags, []string{"summer", "tees"}) {
+		t.Errorf("products tagged summer = %+v %v, want the tee", tagged, err)
+	}
+	tagged, err = st.manager.GetProductsByTags(st.ctx, ProductsByTagsRequest{
+		UserID:        st.userID,
+		IntegrationID: integrationID,
+		Tags:          []string{"summer", "Mugs"},
+	})
+	if err != nil || len(tagged) != 0 {
+		t.Errorf("products tagged summer and mugs = %+v %v, want none", tagged, err)
+	}
+	if _, err := st.manager.GetProductsByTags(st.ctx, ProductsByTagsRequest{
+		UserID:        st.userID,
+		IntegrationID: integrationID,
+		Tags:          []string{" ", ""},
+	}); !errors.Is(err, ErrNoTags) {
+		t.Errorf("blank tags got %v, want ErrNoTags", err)
+	}
+}
+
+func TestSyncKeepsStoresWithTheSameExternalIDsApart(t *testing.T) {
+	st := newSyncTest(t)
+
+	// Both stores number their records from 1 and have a "default" source
+	stores := []string{"one.example.com", "two.example.com"}
+	var integrationIDs []id.ID[id.PlatformIntegration]
+	for _, store := range stores {
+		integration, err := st.db.CreatePlatformIntegration(st.ctx, core.CreatePlatformIntegrationParams{
+			ID:             id.NewGeneration[id.PlatformIntegration](),
+			ShopID:         st.shopID,
+			PlatformType:   core.PlatformTypeMagento,
+			PlatformShopID: store,
+			IsActive:       pgtype.Bool{Bool: true, Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("create integration: %v", err)
+		}
+		integrationIDs = append(integrationIDs, integration.ID)
+
+		page := fetchedPage{
+			locations: []connector.Location{{ExternalID: "default", Name: "Warehouse of " + store, Active: true}},
+			products: []connector.Product{{
+				ExternalID: "1",
+				Title:      "Tote of " + store,
+				Handle:     "tote",
+				Status:     core.ProductStatusActive,
+				Variants:   []connector.Variant{{ExternalID: "1", SKU: "TOTE-" + store}},
+			}},
+		}
+		err = st.db.WithTx(st.ctx, func(tx *db.TxDB) error {
+			return st.manager.batchSyncAllData(st.ctx, tx, integration.ID, st.manager.normalizePage(integration.ID, page))
+		})
+		if err != nil {
+			t.Fatalf("batchSyncAllData: %v", err)
+		}
+	}
+
+	for i, integrationID := range integrationIDs {
+		store := stores[i]
+		location, err := st.db.GetLocationByExternalID(st.ctx, core.GetLocationByExternalIDParams{IntegrationID: integrationID, ExternalID: pgtype.Text{String: "default", Valid: true}})
+		if err != nil || location.Name != "Warehouse of "+store {
+			t.Errorf("location of %s = %+v %v, want its own warehouse", store, location, err)
+		}
+		variant, err := st.db.GetProductVariantByExternalID(st.ctx, core.GetProductVariantByExternalIDParams{IntegrationID: integrationID, ExternalID: pgtype.Text{String: "1", Valid: true}})
+		if err != nil || variant.Sku.String != "TOTE-"+store || variant.IntegrationID != integrationID {
+			t.Errorf("variant of %s = %+v %v, want its own tote", store, variant, err)
+		}
+	}
+}
+
+func TestFullSyncIncludesRecentOrders(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	if err := st.manager.SyncInventory(st.ctx, integrationID, string(SyncTriggerManual)); err != nil {
+		t.Fatalf("SyncInventory: %v", err)
+	}
+
+	runs, err := st.manager.GetSyncRuns(st.ctx, st.userID, "", integrationID, 1)
+	if err != nil {
+		t.Fatalf("GetSyncRuns: %v", err)
+	}
+	if len(runs) != 1 || runs[0].EntityType != EntityTypeFullSync || runs[0].Stats.OrdersCount != 2 {
+		t.Fatalf("runs = %+v, want a full sync of the two orders of the last %d days", runs, orderSyncDays)
+	}
+
+	for _, order := range []struct {
+		externalID string
+		synced     bool
+	}{{"501", true}, {"502", true}, {"503", false}} {
+		_, err := st.db.GetOrderByExternalID(st.ctx, core.GetOrderByExternalIDParams{IntegrationID: integrationID, ExternalID: pgtype.Text{String: order.externalID, Valid: true}})
+		if synced := err == nil; synced != order.synced {
+			t.Errorf("order %s synced = %v (%v), want %v", order.externalID, synced, err, order.synced)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// Number of pages buffered between pipeline stages. This bounds memory use
+	// and lets the API crawl run ahead of the database only by a few pages.
+	pipelineBufferSize = 2
+
+	// Orders are fetched for this many days back
+	orderSyncDays = 30
+)
+
+// Entities synchronized by a full sync, in dependency order: levels reference both locations and inventory items,
+// order line items reference variants.
+var fullSyncEntities = []EntityType{EntityTypeLocation, EntityTypeProduct, EntityTypeInventoryLevel, EntityTypeOrder}
+
+// syncScope describes what a sync run covers and which sync_states row tracks it
+type syncScope struct {
+	stateEntity EntityType
+	entities    []EntityType
+}
+
+// entityScope returns the scope of a sync that refreshes a single entity type
+func entityScope(entity EntityType) syncScope {
+	return syncScope{stateEntity: entity, entities: []EntityType{entity}}
+}
+
+// resumable reports whether the run persists checkpoints and detects deletions. Only full syncs do:
+// checkpoints are stored per entity type, so an entity sync would otherwise overwrite a full sync's.
+func (s syncScope) resumable() bool {
+	return s.stateEntity == EntityTypeFullSync
+}
+
+// fetchedPage is a single page of platform records produced by the fetch stage.
+// nextPageInfo is the connector's cursor for the following page.
+type fetchedPage struct {
+	entity          EntityType
+	locations       []connector.Location
+	products        []connector.Product
+	inventoryLevels []connector.InventoryLevel
+	orders          []connector.Order
+	nextPageInfo    string
+}
+
+// normalizedPage is a page converted into core rows, ready to be upserted in its own transaction
+type normalizedPage struct {
+	entity       EntityType
+	data         *SyncData
+	nextPageInfo string
+}
+
+// add accumulates the row counts of a normalized page
+func (s *SyncStats) add(data *SyncData) {
+	s.LocationsCount += len(data.Locations)
+	s.ProductsCount += len(data.Products)
+	s.ProductVariantsCount += len(data.ProductVariants)
+	s.InventoryItemsCount += len(data.InventoryItems)
+	s.InventoryLevelsCount += len(data.InventoryLevels)
+	s.OrdersCount += len(data.Orders)
+}
+
+// runSyncPipeline streams the entities of the scope from the platform connector into the core tables page by page.
+//
+// The pipeline has three stages connected by bounded channels:
+//   - fetch: crawls the platform API one page at a time
+//   - normalize: converts each page of records into core insert params
+//   - upsert: writes each page in its own transaction and commits it
+//
+// Only a few pages are held in memory at once and no transaction stays open across API calls.
+// The first error cancels the remaining stages; pages committed before it are kept.
+//
+// progress holds the checkpoints of an interrupted sync. Completed entities are skipped and
+// unfinished ones resume from their saved cursor. It is updated as pages are committed, and
+// persisted alongside each page when the scope is resumable.
+func (m *InventorySyncManager) runSyncPipeline(ctx context.Context, conn connector.PlatformConnector, run *syncRun, progress map[EntityType]*entityProgress) (*SyncStats, error) {
+	g, ctx := errgroup.WithContext(ctx)
+	integrationID, scope := run.integrationID, run.scope
+
+	run.pipelineStarted = time.Now()
+	for entity, p := range progress {
+		// Only rows of counted entities go into the ETA
+		if _, counted := run.totals[entity]; counted {
+			run.resumedRows += primaryRows(entity, p.stats)
+		}
+	}
+
+	// Work out where each entity starts before the stages run, since the upsert stage owns progress
+	startPageInfo := make(map[EntityType]string)
+	var pending []EntityType
+	for _, entity := range scope.entities {
+		if p, ok := progress[entity]; ok {
+			if p.completed {
+				logger.Info("Skipping entity completed by a previous attempt", "integration_id", integrationID, "entity", entity)
+				continue
+			}
+			logger.Info("Resuming entity from checkpoint", "integration_id", integrationID, "entity", entity, "pages_processed", p.pages)
+			startPageInfo[entity] = p.pageInfo
+		}
+		pending = append(pending, entity)
+	}
+
+	pages := make(chan fetchedPage, pipelineBufferSize)
+	normalized := make(chan normalizedPage, pipelineBufferSize)
+
+	// Stage 1: fetch pages from the API
+	g.Go(func() error {
+		defer close(pages)
+		for _, entity := range pending {
+			if err := m.fetchEntityPages(ctx, conn, run, entity, startPageInfo[entity], pages); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// Stage 2: normalize pages into core rows
+	g.Go(func() error {
+		defer close(normalized)
+		for page := range pages {
+			started := time.Now()
+			data := m.normalizePage(integrationID, page)
+			run.normalize += time.Since(started)
+
+			select {
+			case normalized <- normalizedPage{entity: page.entity, data: data, nextPageInfo: page.nextPageInfo}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	// Stage 3: upsert each page and commit it together with its checkpoint
+	g.Go(func() error {
+		for page := range normalized {
+			current, ok := progress[page.entity]
+			if !ok {
+				current = &entityProgress{}
+			}
+
+			next := *current
+			next.pageInfo = page.nextPageInfo
+			next.pages++
+			next.stats.add(page.data)
+			next.completed = page.nextPageInfo == ""
+
+			if m.cancelRequested(ctx, run) {
+				return errSyncCancelled
+			}
+
+			started := time.Now()
+			err := m.database.WithTx(ctx, func(tx *db.TxDB) error {
+				if err := m.batchSyncAllData(ctx, tx, integrationID, page.data); err != nil {
+					return err
+				}
+				if !scope.resumable() {
+					return nil
+				}
+				return m.saveCheckpoint(ctx, tx, integrationID, page.entity, &next)
+			})
+			run.upsert += time.Since(started)
+			if err != nil {
+				return errors.Wrapf(err, "failed to upsert %s page", page.entity)
+			}
+			progress[page.entity] = &next
+			m.publishEvent(ctx, run, run.progressEvent(page.entity, progress))
+		}
+		return nil
+	})
+
+	err := g.Wait()
+	return totalStats(progress), err
+}
+
+// fetchEntityPages crawls the pages of one entity type from pageInfo onwards and sends them to the pipeline.
+// Only the time spent waiting on the API counts towards the run's fetch phase.
+func (m *InventorySyncManager) fetchEntityPages(ctx context.Context, conn connector.PlatformConnector, run *syncRun, entity EntityType, pageInfo string, pages chan<- fetchedPage) error {
+	logger.Info("Fetching pages from API", "entity", entity)
+
+	pageCount := 0
+	for {
+		started := time.Now()
+		page, err := m.fetchPage(ctx, conn, entity, pageInfo)
+		run.fetch += time.Since(started)
+		if err != nil {
+			return err
+		}
+		pageCount++
+
+		select {
+		case pages <- *page:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if page.nextPageInfo == "" {
+			break
+		}
+		pageInfo = page.nextPageInfo
+	}
+
+	logger.Info("Pages fetched", "entity", entity, "pages", pageCount)
+	return nil
+}
+
+// fetchPage fetches a single page of the given entity type starting at the connector cursor pageInfo
+func (m *InventorySyncManager) fetchPage(ctx context.Context, conn connector.PlatformConnector, entity EntityType, pageInfo string) (*fetchedPage, error) {
+	page := &fetchedPage{entity: entity}
+
+	switch entity {
+	case EntityTypeLocation:
+		response, err := conn.ListLocations(ctx, pageInfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch locations")
+		}
+		page.locations = response.Items
+		page.nextPageInfo = response.NextCursor
+
+	case EntityTypeProduct:
+		// Variants and their inventory items come with their products
+		response, err := conn.ListProducts(ctx, pageInfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch products")
+		}
+		page.products = response.Items
+		page.nextPageInfo = response.NextCursor
+
+	case EntityTypeInventoryLevel:
+		response, err := conn.ListInventoryLevels(ctx, pageInfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch inventory levels")
+		}
+		page.inventoryLevels = response.Items
+		page.nextPageInfo = response.NextCursor
+
+	case EntityTypeOrder:
+		response, err := conn.ListOrders(ctx, time.Now().AddDate(0, 0, -orderSyncDays), pageInfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch orders")
+		}
+		page.orders = response.Items
+		page.nextPageInfo = response.NextCursor
+
+	default:
+		return nil, errors.Errorf("unsupported sync entity %s", entity)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,52 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento"
+	"github.com/ConradKurth/forecasting/backend/internal/crypto"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/pkg/errors"
+)
+
+// ConnectMagentoRequest represents a request to connect a Magento store to an account
+type ConnectMagentoRequest struct {
+	ConnectOwner
+	StoreURL    string `json:"store_url"`
+	AccessToken string `json:"access_token"`
+}
+
+// ConnectMagento connects a Magento 2 store to an account and starts its initial sync.
+//
+// The store URL must be a public https URL. The access token is the token of an integration
+// created in the store admin, with access to the catalog, inventory sources and sales orders.
+// It is checked against each of them before it is saved, encrypted at rest.
+func (m *InventorySyncManager) ConnectMagento(ctx context.Context, req ConnectMagentoRequest) (*IntegrationResult, error) {
+	owner, err := m.getIntegrationOwner(ctx, req.ConnectOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	storeURL := magento.NormalizeStoreURL(req.StoreURL)
+	parsed, err := m.checkStoreURL(ctx, storeURL)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidStoreURL, err.Error())
+	}
+
+	client := magento.NewClient(storeURL, req.AccessToken)
+	if err := magento.New(client).Verify(ctx); err != nil {
+		return nil, verifyError(err, "magento access token")
+	}
+
+	return m.connectIntegration(ctx, owner, core.PlatformTypeMagento, parsed.Host, func(tx *db.TxDB, integrationID id.ID[id.PlatformIntegration]) error {
+		_, err := tx.GetCore().UpsertMagentoCredentials(ctx, core.UpsertMagentoCredentialsParams{
+			ID:            id.NewGeneration[id.MagentoCredential](),
+			IntegrationID: integrationID,
+			StoreUrl:      storeURL,
+			AccessToken:   crypto.EncryptedSecret(req.AccessToken),
+		})
+		return errors.Wrap(err, "failed to save magento credentials")
+	})
+}
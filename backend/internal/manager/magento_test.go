@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/magento/magentotest"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+)
+
+// newMagentoStore starts a fake Magento store
+func newMagentoStore(t *testing.T) *magentotest.Server {
+	t.Helper()
+
+	server := magentotest.NewServer(magentotest.DemoFixtures())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (st *syncTest) connectMagento(storeURL, accessToken string) (*IntegrationResult, error) {
+	return st.manager.ConnectMagento(st.ctx, ConnectMagentoRequest{
+		ConnectOwner: ConnectOwner{UserID: st.userID, ShopDomain: testShopDomain},
+		StoreURL:     storeURL,
+		AccessToken:  accessToken,
+	})
+}
+
+func TestConnectMagentoStoresToken(t *testing.T) {
+	st := newSyncTest(t)
+	store := newMagentoStore(t)
+
+	result, err := st.connectMagento(store.URL+"/", magentotest.AccessToken)
+	if err != nil {
+		t.Fatalf("ConnectMagento: %v", err)
+	}
+	parsed, _ := url.Parse(store.URL)
+	if result.PlatformShopID != parsed.Host {
+		t.Errorf("result = %+v, want the magento store host", result)
+	}
+
+	credentials, err := st.db.GetMagentoCredentialsByIntegrationID(st.ctx, id.ID[id.PlatformIntegration](result.ID))
+	if err != nil {
+		t.Fatalf("GetMagentoCredentialsByIntegrationID: %v", err)
+	}
+	if credentials.StoreUrl != store.URL || credentials.AccessToken.String() != magentotest.AccessToken {
+		t.Errorf("credentials = %s, %s, want the normalized url and the token", credentials.StoreUrl, credentials.AccessToken)
+	}
+}
@@ -0,0 +1,108 @@
+package manager
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+)
+
+// skuMatcher finds the master SKU of a variant among the master SKUs of an account. A variant
+// matches, in order of confidence:
+//
+//   - exact: a master SKU with the same SKU
+//   - barcode: a master SKU with the same barcode, compared as a GTIN so a 12 digit UPC matches
+//     the 13 digit EAN of the same item
+//   - normalized: a master SKU with the same SKU ignoring case, spaces and separators, so
+//     "TEE-S", "tee s" and "TEE_S" match
+//
+// Barcodes and normalized SKUs shared by several master SKUs are ambiguous and match none of them.
+type skuMatcher struct {
+	bySKU        map[string]core.MasterSku
+	byBarcode    map[string]core.MasterSku
+	byNormalized map[string]core.MasterSku
+
+	// Keys of byBarcode and byNormalized that more than one master SKU has
+	ambiguous map[string]bool
+}
+
+func newSKUMatcher(masters []core.MasterSku) *skuMatcher {
+	matcher := &skuMatcher{
+		bySKU:        make(map[string]core.MasterSku, len(masters)),
+		byBarcode:    make(map[string]core.MasterSku),
+		byNormalized: make(map[string]core.MasterSku, len(masters)),
+		ambiguous:    make(map[string]bool),
+	}
+	for _, master := range masters {
+		matcher.add(master)
+	}
+	return matcher
+}
+
+// add makes a master SKU a candidate of the next matches
+func (s *skuMatcher) add(master core.MasterSku) {
+	s.bySKU[master.Sku] = master
+	s.addKey(s.byBarcode, "barcode:"+normalizeBarcode(master.Barcode.String), master)
+	s.addKey(s.byNormalized, "sku:"+normalizeSKU(master.Sku), master)
+}
+
+func (s *skuMatcher) addKey(index map[string]core.MasterSku, key string, master core.MasterSku) {
+	if strings.HasSuffix(key, ":") {
+		return
+	}
+	if existing, ok := index[key]; ok && existing.ID != master.ID {
+		s.ambiguous[key] = true
+		return
+	}
+	index[key] = master
+}
+
+// match returns the master SKU of a variant and how it matched
+func (s *skuMatcher) match(sku, barcode string) (core.MasterSku, core.MasterSkuMatchType, bool) {
+	if master, ok := s.bySKU[sku]; ok && sku != "" {
+		return master, core.MasterSkuMatchTypeExact, true
+	}
+	if master, ok := s.lookup(s.byBarcode, "barcode:"+normalizeBarcode(barcode)); ok {
+		return master, core.MasterSkuMatchTypeBarcode, true
+	}
+	if master, ok := s.lookup(s.byNormalized, "sku:"+normalizeSKU(sku)); ok {
+		return master, core.MasterSkuMatchTypeNormalized, true
+	}
+	return core.MasterSku{}, "", false
+}
+
+func (s *skuMatcher) lookup(index map[string]core.MasterSku, key string) (core.MasterSku, bool) {
+	master, ok := index[key]
+	return master, ok && !s.ambiguous[key]
+}
+
+// matchStatus is the review state of a new match: exact and manual matches are certain, the
+// others are suggested until a user confirms them
+func matchStatus(matchType core.MasterSkuMatchType) core.MasterSkuLinkStatus {
+	switch matchType {
+	case core.MasterSkuMatchTypeExact, core.MasterSkuMatchTypeManual:
+		return core.MasterSkuLinkStatusConfirmed
+	default:
+		return core.MasterSkuLinkStatusSuggested
+	}
+}
+
+// normalizeSKU keeps the letters and digits of a SKU, in upper case
+func normalizeSKU(sku string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, sku)
+}
+
+// normalizeBarcode keeps the digits of a barcode without leading zeros, which is how GTINs of
+// different lengths compare. Barcodes with letters are not GTINs and are compared like SKUs.
+func normalizeBarcode(barcode string) string {
+	normalized := normalizeSKU(barcode)
+	if strings.IndexFunc(normalized, unicode.IsLetter) >= 0 {
+		return normalized
+	}
+	return strings.TrimLeft(normalized, "0")
+}
@@ -0,0 +1,463 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// Master SKUs are the physical items of a merchant account. Each integration sells an item as
+// its own product variant; linking the variants to the item's master SKU rolls the stock and
+// demand of every channel up to the item, even when the channels use different SKUs.
+
+var (
+	// ErrMasterSKUNotFound is returned when a master SKU does not exist in the account
+	ErrMasterSKUNotFound = errors.New("master SKU not found")
+
+	// ErrMasterSKUExists is returned when creating a master SKU with the SKU of another one
+	ErrMasterSKUExists = errors.New("master SKU already exists")
+
+	// ErrVariantNotFound is returned when a variant is not one of the account's integrations
+	ErrVariantNotFound = errors.New("product variant not found")
+
+	// ErrMasterSKULinkNotFound is returned when reviewing the match of a variant that has none
+	ErrMasterSKULinkNotFound = errors.New("variant has no master SKU match")
+
+	// ErrInvalidMasterSKUReview is returned for a review that neither confirms, rejects nor links a match
+	ErrInvalidMasterSKUReview = errors.New("review must confirm or reject the match, or link a master SKU")
+)
+
+// MasterSKU is the stock and recent sales of a physical item across every channel of an account
+type MasterSKU struct {
+	ID         string  `json:"id"`
+	SKU        string  `json:"sku"`
+	Title      string  `json:"title"`
+	Barcode    string  `json:"barcode,omitempty"`
+	Available  int64   `json:"available"`
+	UnitsSold  int64   `json:"units_sold"`
+	DailyUnits float64 `json:"daily_units"`
+	Revenue    float64 `json:"revenue"`
+	StockValue float64 `json:"stock_value"`
+
+	// DaysOfCover is how long the stock lasts at the current daily units, nil when nothing sold
+	DaysOfCover *float64 `json:"days_of_cover,omitempty"`
+
+	Channels []AccountSKUChannel `json:"channels"`
+}
+
+// MasterSKUsResult represents the master SKUs of an account with their rollups. Currency is the
+// reporting currency of the totals; MissingRates lists the currencies of channels left out of
+// them for lack of a rate, or "unknown" for channels whose currency is not known. Every amount,
+// revenue of past orders included, is converted at the latest rates on RatesDate.
+type MasterSKUsResult struct {
+	AccountID    string      `json:"account_id"`
+	Days         int         `json:"days"`
+	Since        time.Time   `json:"since"`
+	Currency     string      `json:"currency"`
+	RatesDate    time.Time   `json:"rates_date"`
+	MissingRates []string    `json:"missing_rates"`
+	MasterSKUs   []MasterSKU `json:"master_skus"`
+}
+
+// CreateMasterSKURequest represents a request to create a master SKU that variants can be linked to
+type CreateMasterSKURequest struct {
+	AccountRequest
+	SKU     string `json:"sku"`
+	Title   string `json:"title"`
+	Barcode string `json:"barcode,omitempty"`
+}
+
+// MatchMasterSKUsResult counts what matching did with the variants without a master SKU
+type MatchMasterSKUsResult struct {
+	AccountID string `json:"account_id"`
+
+	// MasterSKUsCreated counts the variants whose SKU matched no master SKU, and became one
+	MasterSKUsCreated int `json:"master_skus_created"`
+
+	// Confirmed and Suggested count the variants linked by an exact match, and by a match to review
+	Confirmed int `json:"confirmed"`
+	Suggested int `json:"suggested"`
+
+	// Unmatched counts the variants without a SKU that matched nothing, left to link manually
+	Unmatched int `json:"unmatched"`
+}
+
+// MasterSKULinksRequest represents a request for the matches of an account in a review state
+type MasterSKULinksRequest struct {
+	AccountRequest
+	Status core.MasterSkuLinkStatus `json:"status"`
+}
+
+// ReviewMasterSKULinkRequest represents the review of a variant's match: confirming or rejecting
+// it, or linking the variant to another master SKU
+type ReviewMasterSKULinkRequest struct {
+	AccountRequest
+	VariantID   id.ID[id.ProductVariant] `json:"variant_id"`
+	Status      core.MasterSkuLinkStatus `json:"status,omitempty"`
+	MasterSKUID id.ID[id.MasterSKU]      `json:"master_sku_id,omitempty"`
+}
+
+// MasterSKULink is the match of a variant to a master SKU
+type MasterSKULink struct {
+	VariantID      string                   `json:"variant_id"`
+	VariantSKU     string                   `json:"variant_sku,omitempty"`
+	VariantBarcode string                   `json:"variant_barcode,omitempty"`
+	ProductTitle   string                   `json:"product_title"`
+	IntegrationID  string                   `json:"integration_id"`
+	PlatformType   core.PlatformType        `json:"platform_type"`
+	MasterSKUID    string                   `json:"master_sku_id"`
+	MasterSKU      string                   `json:"master_sku"`
+	MasterTitle    string                   `json:"master_title"`
+	MatchType      core.MasterSkuMatchType  `json:"match_type"`
+	Status         core.MasterSkuLinkStatus `json:"status"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// GetMasterSKUs gets the master SKUs of the account with their stock and the sales of the last
+// days, summed across the variants confirmed as the item on every channel
+func (m *AccountManager) GetMasterSKUs(ctx context.Context, req AccountSKUsRequest) (*MasterSKUsResult, error) {
+	account, err := m.resolveAccount(ctx, req.AccountRequest)
+	if err != nil {
+		return nil, err
+	}
+	days, since := salesWindow(req.Days)
+
+	masters, err := m.database.GetCore().GetMasterSKUsByAccountID(ctx, account.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get master SKUs")
+	}
+	inventory, err := m.database.GetCore().GetMasterSKUInventory(ctx, account.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get master SKU inventory")
+	}
+	sales, err := m.database.GetCore().GetMasterSKUSales(ctx, core.GetMasterSKUSalesParams{
+		AccountID: account.ID,
+		CreatedAt: pgtype.Timestamp{Time: since, Valid: true},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get master SKU sales")
+	}
+	conversion, err := newReportingConversion(ctx, m.database.GetCore(), account)
+	if err != nil {
+		return nil, err
+	}
+
+	results := rollUpMasterSKUs(masters, inventory, sales, days, conversion)
+	return &MasterSKUsResult{
+		AccountID:    account.ID.String(),
+		Days:         days,
+		Since:        since,
+		Currency:     account.ReportingCurrency,
+		RatesDate:    conversion.asOf,
+		MissingRates: conversion.missingRates(),
+		MasterSKUs:   results,
+	}, nil
+}
+
+// rollUpMasterSKUs adds the per-integration stock and sales rows to their master SKUs,
+// converting revenue and stock value into the reporting currency
+func rollUpMasterSKUs(masters []core.MasterSku, inventory []core.GetMasterSKUInventoryRow, sales []core.GetMasterSKUSalesRow, days int, conversion *reportingConversion) []MasterSKU {
+	results := make([]MasterSKU, 0, len(masters))
+	index := make(map[id.ID[id.MasterSKU]]int, len(masters))
+	for _, master := range masters {
+		index[master.ID] = len(results)
+		results = append(results, toMasterSKU(master))
+	}
+	channel := func(masterID id.ID[id.MasterSKU], integrationID id.ID[id.PlatformIntegration], platformType core.PlatformType, integrationCurrency pgtype.Text) *AccountSKUChannel {
+		result := &results[index[masterID]]
+		for i := range result.Channels {
+			if result.Channels[i].IntegrationID == integrationID.String() {
+				return &result.Channels[i]
+			}
+		}
+		result.Channels = append(result.Channels, AccountSKUChannel{
+			IntegrationID: integrationID.String(),
+			PlatformType:  platformType,
+			Currency:      conversion.currencyOf(integrationCurrency),
+		})
+		return &result.Channels[len(result.Channels)-1]
+	}
+
+	for _, row := range inventory {
+		if _, ok := index[row.MasterSkuID]; ok {
+			c := channel(row.MasterSkuID, row.IntegrationID, row.PlatformType, row.Currency)
+			c.Available, c.StockValue = row.Available, roundAmount(row.StockValue)
+		}
+	}
+	for _, row := range sales {
+		if _, ok := index[row.MasterSkuID]; ok {
+			c := channel(row.MasterSkuID, row.IntegrationID, row.PlatformType, row.Currency)
+			c.UnitsSold, c.OrdersCount, c.Revenue = row.UnitsSold, row.OrdersCount, roundAmount(row.Revenue)
+		}
+	}
+
+	for i := range results {
+		result := &results[i]
+		for _, c := range result.Channels {
+			result.Available += c.Available
+			result.UnitsSold += c.UnitsSold
+			result.Revenue += conversion.convert(c.Revenue, c.Currency)
+			result.StockValue += conversion.convert(c.StockValue, c.Currency)
+		}
+		result.Revenue, result.StockValue = roundAmount(result.Revenue), roundAmount(result.StockValue)
+		result.DailyUnits, result.DaysOfCover = salesRate(result.Available, result.UnitsSold, days)
+	}
+	return results
+}
+
+// CreateMasterSKU creates a master SKU in the account, for variants that matched none
+func (m *AccountManager) CreateMasterSKU(ctx context.Context, req CreateMasterSKURequest) (*MasterSKU, error) {
+	account, err := m.resolveAccount(ctx, req.AccountRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = m.database.GetCore().GetMasterSKUBySKU(ctx, core.GetMasterSKUBySKUParams{AccountID: account.ID, Sku: req.SKU})
+	if err == nil {
+		return nil, ErrMasterSKUExists
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "failed to get master SKU")
+	}
+
+	master, err := m.database.GetCore().CreateMasterSKU(ctx, core.CreateMasterSKUParams{
+		ID:        id.NewGeneration[id.MasterSKU](),
+		AccountID: account.ID,
+		Sku:       req.SKU,
+		Title:     req.Title,
+		Barcode:   pgtype.Text{String: req.Barcode, Valid: req.Barcode != ""},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create master SKU")
+	}
+
+	result := toMasterSKU(master)
+	return &result, nil
+}
+
+// MatchMasterSKUs links the account's variants without a master SKU to one. Variants are
+// matched in the order their integrations were connected, so the SKUs of the first channel
+// become the master SKUs; a variant that matches no master SKU becomes one. Exact matches are
+// confirmed, barcode and normalized matches are suggested for review. Variants already linked,
+// rejected matches included, are left as they are.
+func (m *AccountManager) MatchMasterSKUs(ctx context.Context, req AccountRequest) (*MatchMasterSKUsResult, error) {
+	account, err := m.resolveAccount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MatchMasterSKUsResult{AccountID: account.ID.String()}
+	err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		masters, err := tx.GetCore().GetMasterSKUsByAccountID(ctx, account.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get master SKUs")
+		}
+		variants, err := tx.GetCore().GetUnlinkedAccountVariants(ctx, account.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get unlinked variants")
+		}
+
+		matcher := newSKUMatcher(masters)
+		for _, variant := range variants {
+			master, matchType, ok := matcher.match(variant.Sku.String, variant.Barcode.String)
+			if !ok && variant.Sku.String == "" {
+				result.Unmatched++
+				continue
+			}
+			if !ok {
+				master, err = tx.GetCore().CreateMasterSKU(ctx, core.CreateMasterSKUParams{
+					ID:        id.NewGeneration[id.MasterSKU](),
+					AccountID: account.ID,
+					Sku:       variant.Sku.String,
+					Title:     variant.Title,
+					Barcode:   variant.Barcode,
+				})
+				if err != nil {
+					return errors.Wrapf(err, "failed to create master SKU %s", variant.Sku.String)
+				}
+				matcher.add(master)
+				matchType = core.MasterSkuMatchTypeExact
+				result.MasterSKUsCreated++
+			}
+
+			status := matchStatus(matchType)
+			_, err = tx.GetCore().UpsertMasterSKULink(ctx, core.UpsertMasterSKULinkParams{
+				VariantID:   variant.ID,
+				MasterSkuID: master.ID,
+				MatchType:   matchType,
+				Status:      status,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to link variant %s", variant.ID)
+			}
+			if status == core.MasterSkuLinkStatusConfirmed {
+				result.Confirmed++
+			} else {
+				result.Suggested++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Matched master SKUs", "account_id", account.ID, "created", result.MasterSKUsCreated, "confirmed", result.Confirmed, "suggested", result.Suggested, "unmatched", result.Unmatched)
+	return result, nil
+}
+
+// GetMasterSKULinks lists the account's matches in a review state, the suggested ones by default
+func (m *AccountManager) GetMasterSKULinks(ctx context.Context, req MasterSKULinksRequest) ([]MasterSKULink, error) {
+	account, err := m.resolveAccount(ctx, req.AccountRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = core.MasterSkuLinkStatusSuggested
+	}
+	rows, err := m.database.GetCore().GetMasterSKULinks(ctx, core.GetMasterSKULinksParams{
+		AccountID: account.ID,
+		Status:    status,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get master SKU links")
+	}
+
+	links := make([]MasterSKULink, 0, len(rows))
+	for _, row := range rows {
+		links = append(links, MasterSKULink{
+			VariantID:      row.VariantID.String(),
+			VariantSKU:     row.VariantSku.String,
+			VariantBarcode: row.VariantBarcode.String,
+			ProductTitle:   row.ProductTitle,
+			IntegrationID:  row.IntegrationID.String(),
+			PlatformType:   row.PlatformType,
+			MasterSKUID:    row.MasterSkuID.String(),
+			MasterSKU:      row.MasterSku,
+			MasterTitle:    row.MasterTitle,
+			MatchType:      row.MatchType,
+			Status:         row.Status,
+			UpdatedAt:      row.UpdatedAt.Time,
+		})
+	}
+	return links, nil
+}
+
+// ReviewMasterSKULink confirms or rejects the match of a variant, or links the variant to a
+// master SKU of the user's choosing, which confirms it as a manual match. Rejected matches stay
+// rejected when matching runs again.
+func (m *AccountManager) ReviewMasterSKULink(ctx context.Context, req ReviewMasterSKULinkRequest) (*MasterSKULink, error) {
+	account, err := m.resolveAccount(ctx, req.AccountRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	manual := req.MasterSKUID != ""
+	if manual && req.Status != "" && req.Status != core.MasterSkuLinkStatusConfirmed {
+		return nil, ErrInvalidMasterSKUReview
+	}
+	if !manual && req.Status != core.MasterSkuLinkStatusConfirmed && req.Status != core.MasterSkuLinkStatusRejected {
+		return nil, ErrInvalidMasterSKUReview
+	}
+
+	variant, err := m.database.GetCore().GetAccountVariantByID(ctx, core.GetAccountVariantByIDParams{
+		AccountID: account.ID,
+		ID:        req.VariantID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get variant")
+	}
+
+	current, err := m.database.GetCore().GetMasterSKULinkByVariantID(ctx, core.GetMasterSKULinkByVariantIDParams{
+		VariantID: variant.ID,
+		AccountID: account.ID,
+	})
+	hasLink := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "failed to get master SKU link")
+	}
+
+	var link core.MasterSkuLink
+	switch {
+	case manual && (!hasLink || current.MasterSkuID != req.MasterSKUID):
+		if _, err := m.masterSKU(ctx, account.ID, req.MasterSKUID); err != nil {
+			return nil, err
+		}
+		link, err = m.database.GetCore().UpsertMasterSKULink(ctx, core.UpsertMasterSKULinkParams{
+			VariantID:   variant.ID,
+			MasterSkuID: req.MasterSKUID,
+			MatchType:   core.MasterSkuMatchTypeManual,
+			Status:      core.MasterSkuLinkStatusConfirmed,
+		})
+	case !hasLink:
+		return nil, ErrMasterSKULinkNotFound
+	default:
+		status := req.Status
+		if manual {
+			status = core.MasterSkuLinkStatusConfirmed
+		}
+		link, err = m.database.GetCore().SetMasterSKULinkStatus(ctx, core.SetMasterSKULinkStatusParams{
+			VariantID: variant.ID,
+			Status:    status,
+		})
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to review master SKU link")
+	}
+
+	master, err := m.masterSKU(ctx, account.ID, link.MasterSkuID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Reviewed master SKU link", "account_id", account.ID, "variant_id", variant.ID, "master_sku_id", master.ID, "match_type", link.MatchType, "status", link.Status)
+	return &MasterSKULink{
+		VariantID:      variant.ID.String(),
+		VariantSKU:     variant.Sku.String,
+		VariantBarcode: variant.Barcode.String,
+		ProductTitle:   variant.Title,
+		IntegrationID:  variant.IntegrationID.String(),
+		PlatformType:   variant.PlatformType,
+		MasterSKUID:    master.ID.String(),
+		MasterSKU:      master.Sku,
+		MasterTitle:    master.Title,
+		MatchType:      link.MatchType,
+		Status:         link.Status,
+		UpdatedAt:      link.UpdatedAt.Time,
+	}, nil
+}
+
+// masterSKU gets a master SKU of the account
+func (m *AccountManager) masterSKU(ctx context.Context, accountID id.ID[id.MerchantAccount], masterSKUID id.ID[id.MasterSKU]) (core.MasterSku, error) {
+	master, err := m.database.GetCore().GetMasterSKUByID(ctx, core.GetMasterSKUByIDParams{
+		ID:        masterSKUID,
+		AccountID: accountID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return core.MasterSku{}, ErrMasterSKUNotFound
+	}
+	return master, errors.Wrap(err, "failed to get master SKU")
+}
+
+// toMasterSKU converts a master SKU without stock or sales
+func toMasterSKU(master core.MasterSku) MasterSKU {
+	return MasterSKU{
+		ID:       master.ID.String(),
+		SKU:      master.Sku,
+		Title:    master.Title,
+		Barcode:  master.Barcode.String,
+		Channels: []AccountSKUChannel{},
+	}
+}
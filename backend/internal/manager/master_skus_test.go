@@ -0,0 +1,280 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/fileimport"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+func TestNormalizeSKUAndBarcode(t *testing.T) {
+	skus := map[string]string{
+		"TEE-S":    "TEES",
+		"tee s":    "TEES",
+		"Tee_s.":   "TEES",
+		"mug/01 ":  "MUG01",
+		"Café-01":  "CAFÉ01",
+		"---":      "",
+		"ab12-Xy9": "AB12XY9",
+	}
+	for sku, want := range skus {
+		if got := normalizeSKU(sku); got != want {
+			t.Errorf("normalizeSKU(%q) = %q, want %q", sku, got, want)
+		}
+	}
+
+	barcodes := map[string]string{
+		"012345678905":    "12345678905",
+		"0012345678905":   "12345678905",
+		"0 12345 67890 5": "12345678905",
+		"5012345678900":   "5012345678900",
+		"abc-0012":        "ABC0012",
+		"0000":            "",
+	}
+	for barcode, want := range barcodes {
+		if got := normalizeBarcode(barcode); got != want {
+			t.Errorf("normalizeBarcode(%q) = %q, want %q", barcode, got, want)
+		}
+	}
+}
+
+func TestSKUMatcher(t *testing.T) {
+	master := func(sku, barcode string) core.MasterSku {
+		return core.MasterSku{
+			ID:      id.NewGeneration[id.MasterSKU](),
+			Sku:     sku,
+			Barcode: pgtype.Text{String: barcode, Valid: barcode != ""},
+		}
+	}
+	tee := master("TEE-S", "")
+	mug := master("MUG-01", "012345678905")
+	mugSpaced := master("mug 01", "")
+	bag := master("BAG-01", "5012345678900")
+	tote := master("TOTE", "5012345678900")
+	matcher := newSKUMatcher([]core.MasterSku{tee, mug, mugSpaced, bag, tote})
+
+	tests := []struct {
+		sku, barcode string
+		want         core.MasterSku
+		wantType     core.MasterSkuMatchType
+		wantOK       bool
+	}{
+		{sku: "TEE-S", want: tee, wantType: core.MasterSkuMatchTypeExact, wantOK: true},
+		{sku: "tee_s", want: tee, wantType: core.MasterSkuMatchTypeNormalized, wantOK: true},
+		// The exact SKU wins over a barcode of another master SKU
+		{sku: "TEE-S", barcode: "0012345678905", want: tee, wantType: core.MasterSkuMatchTypeExact, wantOK: true},
+		// A 13 digit EAN matches the 12 digit UPC of the same item
+		{sku: "MUG-RED", barcode: "0012345678905", want: mug, wantType: core.MasterSkuMatchTypeBarcode, wantOK: true},
+		{sku: "MUG-01", want: mug, wantType: core.MasterSkuMatchTypeExact, wantOK: true},
+		// MUG-01 and "mug 01" normalize alike, so only their exact SKUs match them
+		{sku: "Mug_01"},
+		// Two master SKUs have this barcode
+		{sku: "BAG", barcode: "5012345678900"},
+		{sku: "HAT-01", barcode: "999"},
+		{},
+	}
+	for _, tt := range tests {
+		got, gotType, ok := matcher.match(tt.sku, tt.barcode)
+		if ok != tt.wantOK || got.ID != tt.want.ID || gotType != tt.wantType {
+			t.Errorf("match(%q, %q) = %s %q %v, want %s %q %v", tt.sku, tt.barcode, got.Sku, gotType, ok, tt.want.Sku, tt.wantType, tt.wantOK)
+		}
+	}
+
+	// Master SKUs created while matching are candidates of the next variants
+	hat := master("HAT-01", "")
+	matcher.add(hat)
+	if got, gotType, ok := matcher.match("hat 01", ""); !ok || got.ID != hat.ID || gotType != core.MasterSkuMatchTypeNormalized {
+		t.Errorf("match(hat 01) = %s %q %v, want HAT-01 normalized", got.Sku, gotType, ok)
+	}
+}
+
+func TestMatchMasterSKUsAcrossChannels(t *testing.T) {
+	st := newSyncTest(t)
+	accounts := NewAccountManager(st.db)
+	st.addShop(t, testOtherShopDomain)
+
+	recent := time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02")
+	st.importFiles(t, testShopDomain, [3]string{
+		"sku,title,barcode\nTEE-S,Logo Tee,\nMUG-01,Enamel Mug,\nBAG-01,Tote Bag,012345678905\n",
+		"sku,location,available\nTEE-S,Main,4\nMUG-01,Main,10\n",
+		fmt.Sprintf("order_id,date,sku,quantity,price\n1001,%s,MUG-01,1,12\n", recent),
+	})
+	st.importFiles(t, testOtherShopDomain, [3]string{
+		"sku,title,barcode\nMUG-01,Mug,\ntee s,Tee,\nTOTE,Tote,0012345678905\n",
+		"sku,location,available\nMUG-01,Till,3\ntee s,Till,5\n",
+		fmt.Sprintf("order_id,date,sku,quantity,price\nA-1,%s,MUG-01,2,12\nA-2,%s,tee s,1,19.50\n", recent, recent),
+	})
+
+	account := st.account(t, accounts, testShopDomain)
+	request := AccountRequest{UserID: st.userID, AccountID: id.ID[id.MerchantAccount](account.ID)}
+	if _, err := accounts.LinkShop(st.ctx, LinkShopRequest{AccountRequest: request, LinkShopDomain: testOtherShopDomain}); err != nil {
+		t.Fatalf("LinkShop: %v", err)
+	}
+
+	// The first shop's SKUs become the master SKUs, the other shop's variants match them
+	matched, err := accounts.MatchMasterSKUs(st.ctx, request)
+	if err != nil {
+		t.Fatalf("MatchMasterSKUs: %v", err)
+	}
+	if matched.MasterSKUsCreated != 3 || matched.Confirmed != 4 || matched.Suggested != 2 || matched.Unmatched != 0 {
+		t.Errorf("matched = %+v, want 3 created, 4 confirmed and 2 suggested", matched)
+	}
+	again, err := accounts.MatchMasterSKUs(st.ctx, request)
+	if err != nil {
+		t.Fatalf("MatchMasterSKUs again: %v", err)
+	}
+	if again.MasterSKUsCreated+again.Confirmed+again.Suggested+again.Unmatched != 0 {
+		t.Errorf("matching again = %+v, want linked variants left alone", again)
+	}
+
+	suggested, err := accounts.GetMasterSKULinks(st.ctx, MasterSKULinksRequest{AccountRequest: request})
+	if err != nil {
+		t.Fatalf("GetMasterSKULinks: %v", err)
+	}
+	links := map[string]MasterSKULink{}
+	for _, link := range suggested {
+		links[link.VariantSKU] = link
+	}
+	if len(links) != 2 {
+		t.Fatalf("suggested = %+v, want tee s and TOTE", suggested)
+	}
+	if tee := links["tee s"]; tee.MasterSKU != "TEE-S" || tee.MatchType != core.MasterSkuMatchTypeNormalized {
+		t.Errorf("tee s link = %+v, want a normalized match of TEE-S", tee)
+	}
+	if tote := links["TOTE"]; tote.MasterSKU != "BAG-01" || tote.MatchType != core.MasterSkuMatchTypeBarcode {
+		t.Errorf("TOTE link = %+v, want a barcode match of BAG-01", tote)
+	}
+
+	// Suggested matches are left out of the rollups until confirmed
+	masterSKUs := func() map[string]MasterSKU {
+		t.Helper()
+		result, err := accounts.GetMasterSKUs(st.ctx, AccountSKUsRequest{AccountRequest: request, Days: 10})
+		if err != nil {
+			t.Fatalf("GetMasterSKUs: %v", err)
+		}
+		masters := map[string]MasterSKU{}
+		for _, master := range result.MasterSKUs {
+			masters[master.SKU] = master
+		}
+		return masters
+	}
+	masters := masterSKUs()
+	if mug := masters["MUG-01"]; mug.Available != 13 || mug.UnitsSold != 3 || len(mug.Channels) != 2 {
+		t.Errorf("mug = %+v, want 13 available and 3 sold on 2 channels", mug)
+	}
+	if tee := masters["TEE-S"]; tee.Available != 4 || tee.UnitsSold != 0 || len(tee.Channels) != 1 {
+		t.Errorf("tee = %+v, want the first shop's stock only", tee)
+	}
+
+	review := func(variantID string, status core.MasterSkuLinkStatus, masterSKUID string) (*MasterSKULink, error) {
+		return accounts.ReviewMasterSKULink(st.ctx, ReviewMasterSKULinkRequest{
+			AccountRequest: request,
+			VariantID:      id.ID[id.ProductVariant](variantID),
+			Status:         status,
+			MasterSKUID:    id.ID[id.MasterSKU](masterSKUID),
+		})
+	}
+	if _, err := review(links["tee s"].VariantID, core.MasterSkuLinkStatusConfirmed, ""); err != nil {
+		t.Fatalf("confirm tee s: %v", err)
+	}
+	if tee := masterSKUs()["TEE-S"]; tee.Available != 9 || tee.UnitsSold != 1 || len(tee.Channels) != 2 {
+		t.Errorf("confirmed tee = %+v, want 9 available and 1 sold on 2 channels", tee)
+	}
+
+	// Rejected matches stay rejected when matching runs again
+	if _, err := review(links["TOTE"].VariantID, core.MasterSkuLinkStatusRejected, ""); err != nil {
+		t.Fatalf("reject TOTE: %v", err)
+	}
+	if again, err = accounts.MatchMasterSKUs(st.ctx, request); err != nil || again.Suggested != 0 {
+		t.Errorf("matching after rejecting = %+v %v, want nothing suggested", again, err)
+	}
+	rejected, err := accounts.GetMasterSKULinks(st.ctx, MasterSKULinksRequest{AccountRequest: request, Status: core.MasterSkuLinkStatusRejected})
+	if err != nil || len(rejected) != 1 || rejected[0].VariantSKU != "TOTE" {
+		t.Errorf("rejected = %+v %v, want TOTE", rejected, err)
+	}
+
+	// A rejected variant can be linked to a master SKU the user creates
+	toteBag, err := accounts.CreateMasterSKU(st.ctx, CreateMasterSKURequest{AccountRequest: request, SKU: "TOTE-BAG", Title: "Tote Bag"})
+	if err != nil {
+		t.Fatalf("CreateMasterSKU: %v", err)
+	}
+	linked, err := review(links["TOTE"].VariantID, "", toteBag.ID)
+	if err != nil {
+		t.Fatalf("link TOTE: %v", err)
+	}
+	if linked.MasterSKU != "TOTE-BAG" || linked.MatchType != core.MasterSkuMatchTypeManual || linked.Status != core.MasterSkuLinkStatusConfirmed {
+		t.Errorf("linked = %+v, want a confirmed manual match of TOTE-BAG", linked)
+	}
+	if bag := masterSKUs()["BAG-01"]; len(bag.Channels) != 1 {
+		t.Errorf("bag = %+v, want the first shop's variant only", bag)
+	}
+
+	if _, err := accounts.CreateMasterSKU(st.ctx, CreateMasterSKURequest{AccountRequest: request, SKU: "MUG-01", Title: "Mug"}); !errors.Is(err, ErrMasterSKUExists) {
+		t.Errorf("creating MUG-01 again got %v, want %v", err, ErrMasterSKUExists)
+	}
+	if _, err := review(links["TOTE"].VariantID, "", id.NewGeneration[id.MasterSKU]().String()); !errors.Is(err, ErrMasterSKUNotFound) {
+		t.Errorf("linking an unknown master SKU got %v, want %v", err, ErrMasterSKUNotFound)
+	}
+	if _, err := review(id.NewGeneration[id.ProductVariant]().String(), core.MasterSkuLinkStatusConfirmed, ""); !errors.Is(err, ErrVariantNotFound) {
+		t.Errorf("reviewing an unknown variant got %v, want %v", err, ErrVariantNotFound)
+	}
+	if _, err := review(links["TOTE"].VariantID, core.MasterSkuLinkStatusSuggested, ""); !errors.Is(err, ErrInvalidMasterSKUReview) {
+		t.Errorf("suggesting a match got %v, want %v", err, ErrInvalidMasterSKUReview)
+	}
+}
+
+func TestMasterSKULinksLeaveOutDeletedProducts(t *testing.T) {
+	st := newSyncTest(t)
+	accounts := NewAccountManager(st.db)
+	st.addShop(t, testOtherShopDomain)
+
+	st.mustImport(t, fileimport.KindProducts, "sku,title\nTEE-S,Logo Tee\n")
+	other, err := st.manager.ImportFile(st.ctx, ImportFileRequest{
+		UserID:     st.userID,
+		ShopDomain: testOtherShopDomain,
+		Kind:       fileimport.KindProducts,
+		FileName:   "products.csv",
+		Content:    strings.NewReader("sku,title\ntee s,Tee\n"),
+	})
+	if err != nil {
+		t.Fatalf("ImportFile: %v", err)
+	}
+
+	account := st.account(t, accounts, testShopDomain)
+	request := AccountRequest{UserID: st.userID, AccountID: id.ID[id.MerchantAccount](account.ID)}
+	if _, err := accounts.LinkShop(st.ctx, LinkShopRequest{AccountRequest: request, LinkShopDomain: testOtherShopDomain}); err != nil {
+		t.Fatalf("LinkShop: %v", err)
+	}
+	if _, err := accounts.MatchMasterSKUs(st.ctx, request); err != nil {
+		t.Fatalf("MatchMasterSKUs: %v", err)
+	}
+
+	suggested := func() []MasterSKULink {
+		t.Helper()
+		links, err := accounts.GetMasterSKULinks(st.ctx, MasterSKULinksRequest{AccountRequest: request})
+		if err != nil {
+			t.Fatalf("GetMasterSKULinks: %v", err)
+		}
+		return links
+	}
+	if links := suggested(); len(links) != 1 || links[0].VariantSKU != "tee s" {
+		t.Fatalf("suggested = %+v, want tee s", links)
+	}
+
+	// A product the platform deleted leaves the review queue
+	if _, err := st.db.SoftDeleteProductsNotSyncedSince(st.ctx, core.SoftDeleteProductsNotSyncedSinceParams{
+		IntegrationID: id.ID[id.PlatformIntegration](other.IntegrationID),
+		UpdatedAt:     pgtype.Timestamp{Time: time.Now().UTC().Add(time.Minute), Valid: true},
+	}); err != nil {
+		t.Fatalf("SoftDeleteProductsNotSyncedSince: %v", err)
+	}
+	if links := suggested(); len(links) != 0 {
+		t.Errorf("suggested = %+v, want the deleted product's variant left out", links)
+	}
+}
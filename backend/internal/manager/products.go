@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/pkg/errors"
+)
+
+// ErrNoTags is returned when filtering products by tags without any tag
+var ErrNoTags = errors.New("at least one tag is required")
+
+// ProductsByTagsRequest represents a request for the products of an integration carrying tags
+type ProductsByTagsRequest struct {
+	UserID        id.ID[id.User]                `json:"user_id"`
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	Tags          []string                      `json:"tags"`
+}
+
+// ProductResult represents a product of an integration
+type ProductResult struct {
+	ID          string             `json:"id"`
+	ExternalID  string             `json:"external_id"`
+	Title       string             `json:"title"`
+	Handle      string             `json:"handle"`
+	ProductType string             `json:"product_type,omitempty"`
+	Status      core.ProductStatus `json:"status"`
+	Vendor      string             `json:"vendor,omitempty"`
+	Tags        []string           `json:"tags"`
+	OptionNames []string           `json:"option_names"`
+
+	// PublishedAt is when the product was published, nil while it is unpublished
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// GetProductsByTags gets the products of an integration the user has access to that carry every
+// one of the tags, sorted by title. Tags are stored normalized, so they match whatever their case.
+func (m *InventorySyncManager) GetProductsByTags(ctx context.Context, req ProductsByTagsRequest) ([]ProductResult, error) {
+	tags := normalizeTags(req.Tags)
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+
+	integration, err := m.getUserIntegration(ctx, req.UserID, req.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := m.database.GetCore().GetProductsByTags(ctx, core.GetProductsByTagsParams{
+		IntegrationID: integration.ID,
+		Tags:          tags,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get products by tags")
+	}
+
+	results := make([]ProductResult, 0, len(products))
+	for _, product := range products {
+		results = append(results, toProductResult(product))
+	}
+	return results, nil
+}
+
+// toProductResult converts a product row
+func toProductResult(product core.Product) ProductResult {
+	result := ProductResult{
+		ID:          product.ID.String(),
+		ExternalID:  product.ExternalID.String,
+		Title:       product.Title,
+		Handle:      product.Handle,
+		ProductType: product.ProductType.String,
+		Status:      product.Status,
+		Vendor:      product.Vendor.String,
+		Tags:        product.Tags,
+		OptionNames: product.OptionNames,
+	}
+	if product.PublishedAt.Valid {
+		result.PublishedAt = &product.PublishedAt.Time
+	}
+	return result
+}
+
+// normalizeTags trims and lower-cases tags and drops empty and repeated ones, keeping their order.
+// Platforms keep the case a merchant typed, so "Summer" and "summer" are one tag once normalized.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
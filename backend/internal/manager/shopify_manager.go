@@ -7,6 +7,7 @@ import (
 	"github.com/ConradKurth/forecasting/backend/internal/crypto"
 	"github.com/ConradKurth/forecasting/backend/internal/db"
 	"github.com/ConradKurth/forecasting/backend/internal/interfaces"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
 	"github.com/ConradKurth/forecasting/backend/internal/repository/shopify"
 	"github.com/ConradKurth/forecasting/backend/internal/repository/users"
 	"github.com/ConradKurth/forecasting/backend/pkg/id"
@@ -45,6 +46,7 @@ type ShopifyIntegration struct {
 	User        *users.User           `json:"user"`
 	Store       *shopify.ShopifyStore `json:"store"`
 	ShopifyUser *shopify.ShopifyUser  `json:"shopify_user"`
+	Account     *core.MerchantAccount `json:"account,omitempty"`
 	AccessToken string                `json:"-"` // Don't serialize access token
 }
 
@@ -56,6 +58,9 @@ type ShopifyIntegration struct {
 // - If any step fails, all changes are rolled back automatically
 // - The transaction ensures that partial integrations cannot exist
 // - Example: If store creation succeeds but shopify user creation fails, the store creation will be rolled back
+//
+// A new store joins the merchant account given in params when the user is one of its users, so
+// connecting a second store from a session groups both stores; otherwise it gets an account of its own.
 func (m *ShopifyManager) CreateOrUpdateShopifyIntegration(ctx context.Context, params CreateShopifyIntegrationParams) (*ShopifyIntegration, error) {
 	var result *ShopifyIntegration
 
@@ -107,10 +112,17 @@ func (m *ShopifyManager) CreateOrUpdateShopifyIntegration(ctx context.Context, p
 			return errors.Wrap(err, "failed to create or update shopify user")
 		}
 
+		// Step 4: Add the store to a merchant account, the session's account for a new store
+		account, err := ensureShopAccount(ctx, txDB.GetCore(), store, params.UserID, params.AccountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to ensure merchant account")
+		}
+
 		result = &ShopifyIntegration{
 			User:        &user,
 			Store:       &store,
 			ShopifyUser: &shopifyUser,
+			Account:     &account,
 			AccessToken: params.AccessToken,
 		}
 
@@ -141,6 +153,10 @@ type CreateShopifyIntegrationParams struct {
 	AccessToken string         `json:"access_token"`
 	Scope       string         `json:"scope"`
 	ExpiresAt   *time.Time     `json:"expires_at,omitempty"`
+
+	// AccountID is the merchant account of the session the store was connected from, which a
+	// store without an account joins
+	AccountID id.ID[id.MerchantAccount] `json:"account_id,omitempty"`
 }
 
 // GetShopifyIntegration retrieves the complete shopify integration for a user and shop domain
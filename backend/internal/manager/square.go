@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square"
+	"github.com/ConradKurth/forecasting/backend/internal/crypto"
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/pkg/errors"
+)
+
+// ConnectSquareRequest represents a request to connect a Square seller to an account
+type ConnectSquareRequest struct {
+	ConnectOwner
+	AccessToken string `json:"access_token"`
+}
+
+// ConnectSquare connects a Square seller to an account and starts its initial sync, so that point
+// of sale orders are forecast together with the account's online sales.
+//
+// The seller is identified by the merchant the access token belongs to. The token is checked
+// against the catalog, inventory and orders before it is saved, encrypted at rest.
+func (m *InventorySyncManager) ConnectSquare(ctx context.Context, req ConnectSquareRequest) (*IntegrationResult, error) {
+	owner, err := m.getIntegrationOwner(ctx, req.ConnectOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	client := square.NewClient(req.AccessToken)
+	merchant, err := client.GetMerchant(ctx)
+	if err == nil {
+		err = square.New(client).Verify(ctx)
+	}
+	if err != nil {
+		return nil, verifyError(err, "square access token")
+	}
+
+	return m.connectIntegration(ctx, owner, core.PlatformTypeSquare, merchant.ID, func(tx *db.TxDB, integrationID id.ID[id.PlatformIntegration]) error {
+		_, err := tx.GetCore().UpsertSquareCredentials(ctx, core.UpsertSquareCredentialsParams{
+			ID:            id.NewGeneration[id.SquareCredential](),
+			IntegrationID: integrationID,
+			AccessToken:   crypto.EncryptedSecret(req.AccessToken),
+		})
+		return errors.Wrap(err, "failed to save square credentials")
+	})
+}
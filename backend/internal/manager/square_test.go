@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/connector/square/squaretest"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+)
+
+// newSquareSeller starts a fake Square API and points the Square client at it
+func newSquareSeller(t *testing.T) *squaretest.Server {
+	t.Helper()
+
+	server := squaretest.NewServer(squaretest.DemoFixtures())
+	t.Cleanup(server.Close)
+	previousBaseURL := config.Values.Square.APIBaseURL
+	config.Values.Square.APIBaseURL = server.URL
+	t.Cleanup(func() { config.Values.Square.APIBaseURL = previousBaseURL })
+	return server
+}
+
+func (st *syncTest) connectSquare(accessToken string) (*IntegrationResult, error) {
+	return st.manager.ConnectSquare(st.ctx, ConnectSquareRequest{
+		ConnectOwner: ConnectOwner{UserID: st.userID, ShopDomain: testShopDomain},
+		AccessToken:  accessToken,
+	})
+}
+
+func TestConnectSquareStoresToken(t *testing.T) {
+	st := newSyncTest(t)
+	newSquareSeller(t)
+
+	result, err := st.connectSquare(squaretest.AccessToken)
+	if err != nil {
+		t.Fatalf("ConnectSquare: %v", err)
+	}
+	if result.PlatformShopID != squaretest.MerchantID {
+		t.Errorf("result = %+v, want the square merchant", result)
+	}
+
+	credentials, err := st.db.GetSquareCredentialsByIntegrationID(st.ctx, id.ID[id.PlatformIntegration](result.ID))
+	if err != nil {
+		t.Fatalf("GetSquareCredentialsByIntegrationID: %v", err)
+	}
+	if credentials.AccessToken.String() != squaretest.AccessToken {
+		t.Errorf("stored token = %s, want the access token", credentials.AccessToken)
+	}
+}
@@ -0,0 +1,131 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/events"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// ErrNoSyncInProgress is returned when cancelling a shop that has no sync to cancel
+var ErrNoSyncInProgress = errors.New("no sync in progress")
+
+// errSyncCancelled stops the sync pipeline once the user asked to cancel the run
+var errSyncCancelled = errors.New("sync cancelled")
+
+// CancelSync stops the in-progress syncs of an integration the user has access to, or of the
+// shop's Shopify integration when integrationID is empty.
+//
+// Running syncs are flagged for cancellation and their asynq tasks are sent a cancellation signal;
+// the sync also checks the flag between pages, so it stops even if the signal is missed.
+// Sync tasks still waiting in the queue are deleted. The sync states are set to cancelled right
+// away so a new sync can be triggered without waiting for the worker to wind down.
+func (m *InventorySyncManager) CancelSync(ctx context.Context, userID id.ID[id.User], shopDomain string, integrationID id.ID[id.PlatformIntegration]) (*SyncResult, error) {
+	integration, found, err := m.getSyncIntegration(ctx, userID, shopDomain, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNoSyncInProgress
+	}
+
+	var runs []core.SyncRun
+	var states []core.SyncState
+	err = m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		runs, err = tx.GetCore().RequestSyncRunsCancellation(ctx, integration.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to request sync run cancellation")
+		}
+
+		states, err = tx.GetCore().CancelInProgressSyncStates(ctx, integration.ID)
+		return errors.Wrap(err, "failed to cancel sync states")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(runs) == 0 && len(states) == 0 {
+		return nil, ErrNoSyncInProgress
+	}
+
+	var taskIDs []string
+	for _, run := range runs {
+		if run.TaskID.Valid {
+			taskIDs = append(taskIDs, run.TaskID.String)
+		}
+	}
+
+	if err := m.queue.CancelInventorySyncs(ctx, integration.ID.String(), taskIDs); err != nil {
+		return nil, errors.Wrap(err, "failed to cancel sync tasks")
+	}
+
+	logger.Info("Sync cancelled", "user_id", userID, "integration_id", integration.ID, "runs", len(runs), "states", len(states))
+
+	return &SyncResult{
+		IntegrationID: integration.ID.String(),
+		Status:        SyncStatusCancelled,
+	}, nil
+}
+
+// cancelRequested reports whether the user asked to cancel the run. It is checked between pages
+// and when the sync fails, since a cancelled task context surfaces as an ordinary error.
+func (m *InventorySyncManager) cancelRequested(ctx context.Context, run *syncRun) bool {
+	requested, err := m.database.GetCore().IsSyncRunCancelRequested(context.WithoutCancel(ctx), run.id)
+	if err != nil {
+		logger.Warn("Failed to check sync cancellation", "integration_id", run.integrationID, "run_id", run.id, "error", err)
+		return false
+	}
+	return requested
+}
+
+// taskCancelled reports whether the asynq task running this sync was cancelled by an earlier attempt.
+// asynq retries a task whose processing was cancelled, and those retries must not sync again.
+// Sync task IDs are reused across enqueues, so only retries and only the task's latest run count.
+func (m *InventorySyncManager) taskCancelled(ctx context.Context) (bool, error) {
+	taskID, ok := asynq.GetTaskID(ctx)
+	if !ok {
+		return false, nil
+	}
+	if retried, _ := asynq.GetRetryCount(ctx); retried == 0 {
+		return false, nil
+	}
+
+	cancelled, err := m.database.GetCore().IsSyncTaskCancelled(ctx, pgtype.Text{String: taskID, Valid: true})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check sync task cancellation")
+	}
+	return cancelled, nil
+}
+
+// handleSyncCancelled records a cancelled run. Pages committed before the cancellation are kept,
+// the one in flight was rolled back with its transaction, and the checkpoints are dropped so the
+// next sync starts over instead of resuming the cancelled one.
+func (m *InventorySyncManager) handleSyncCancelled(ctx context.Context, run *syncRun) error {
+	logger.Info("Sync cancelled by user", "integration_id", run.integrationID, "scope", run.scope.stateEntity, "run_id", run.id)
+
+	ctx = context.WithoutCancel(ctx)
+	err := m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		if run.scope.resumable() {
+			if err := tx.GetCore().DeleteSyncCheckpointsByIntegrationID(ctx, run.integrationID); err != nil {
+				return errors.Wrap(err, "failed to delete sync checkpoints")
+			}
+		}
+		if err := m.updateSyncState(ctx, tx, run.integrationID, run.scope.stateEntity, SyncStatusCancelled, ""); err != nil {
+			return err
+		}
+		return m.finishSyncRun(ctx, tx, run, SyncStatusCancelled, "")
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to record cancelled sync")
+	}
+
+	m.publishEvent(ctx, run, events.SyncEvent{Type: events.SyncEventCancelled})
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// Checkpoints older than this are discarded and the sync starts over,
+// since platform cursors (such as Shopify page_info) are not meant to be held indefinitely
+const checkpointMaxAge = 24 * time.Hour
+
+// entityProgress tracks how far a sync has progressed through one entity type
+type entityProgress struct {
+	pageInfo  string
+	pages     int32
+	stats     SyncStats
+	completed bool
+}
+
+// totalStats sums the row counts of all entities
+func totalStats(progress map[EntityType]*entityProgress) *SyncStats {
+	total := &SyncStats{}
+	for _, p := range progress {
+		total.LocationsCount += p.stats.LocationsCount
+		total.ProductsCount += p.stats.ProductsCount
+		total.ProductVariantsCount += p.stats.ProductVariantsCount
+		total.InventoryItemsCount += p.stats.InventoryItemsCount
+		total.InventoryLevelsCount += p.stats.InventoryLevelsCount
+		total.OrdersCount += p.stats.OrdersCount
+	}
+	return total
+}
+
+// loadCheckpoints returns the progress persisted by a previous, unfinished sync of the integration.
+// An empty map means the sync starts from the beginning.
+func (m *InventorySyncManager) loadCheckpoints(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (map[EntityType]*entityProgress, error) {
+	checkpoints, err := m.database.GetCore().GetSyncCheckpointsByIntegrationID(ctx, integrationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sync checkpoints")
+	}
+
+	progress := make(map[EntityType]*entityProgress)
+	for _, checkpoint := range checkpoints {
+		if checkpoint.UpdatedAt.Valid && time.Since(checkpoint.UpdatedAt.Time) > checkpointMaxAge {
+			logger.Info("Discarding stale sync checkpoints", "integration_id", integrationID, "updated_at", checkpoint.UpdatedAt.Time)
+			if err := m.database.GetCore().DeleteSyncCheckpointsByIntegrationID(ctx, integrationID); err != nil {
+				return nil, errors.Wrap(err, "failed to delete stale sync checkpoints")
+			}
+			return make(map[EntityType]*entityProgress), nil
+		}
+
+		var stats SyncStats
+		if err := json.Unmarshal(checkpoint.Stats, &stats); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %s checkpoint stats", checkpoint.EntityType)
+		}
+
+		progress[FromCoreEntity(checkpoint.EntityType)] = &entityProgress{
+			pageInfo:  checkpoint.PageInfo.String,
+			pages:     checkpoint.PagesProcessed,
+			stats:     stats,
+			completed: checkpoint.Completed,
+		}
+	}
+
+	return progress, nil
+}
+
+// saveCheckpoint persists the progress of one entity. It must run in the same
+// transaction as the page it describes so the two can never disagree.
+func (m *InventorySyncManager) saveCheckpoint(ctx context.Context, tx *db.TxDB, integrationID id.ID[id.PlatformIntegration], entity EntityType, progress *entityProgress) error {
+	stats, err := json.Marshal(progress.stats)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode checkpoint stats")
+	}
+
+	_, err = tx.GetCore().UpsertSyncCheckpoint(ctx, core.UpsertSyncCheckpointParams{
+		ID:             id.NewGeneration[id.SyncCheckpoint](),
+		IntegrationID:  integrationID,
+		EntityType:     ToCoreEntity(entity),
+		PageInfo:       pgtype.Text{String: progress.pageInfo, Valid: progress.pageInfo != ""},
+		PagesProcessed: progress.pages,
+		Stats:          stats,
+		Completed:      progress.completed,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to save %s checkpoint", entity)
+	}
+
+	return nil
+}
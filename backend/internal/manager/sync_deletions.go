@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// softDeleteMissing marks rows that were not touched by the finished full sync as deleted.
+//
+// Every row seen during the sync is upserted, which bumps its updated_at, so anything older than the
+// start of the sync no longer exists on the platform. The start is taken from the earliest checkpoint:
+// it is written in the same transaction as the first page, so both share the same database timestamp
+// and resumed attempts still count from the original start. Must run before the checkpoints are deleted.
+func (m *InventorySyncManager) softDeleteMissing(ctx context.Context, tx *db.TxDB, integrationID id.ID[id.PlatformIntegration], entities []EntityType) error {
+	checkpoints, err := tx.GetCore().GetSyncCheckpointsByIntegrationID(ctx, integrationID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get sync checkpoints")
+	}
+	if len(checkpoints) == 0 || !checkpoints[0].CreatedAt.Valid {
+		logger.Warn("No sync start recorded, skipping deletion detection", "integration_id", integrationID)
+		return nil
+	}
+
+	// Checkpoints are ordered by created_at
+	syncStartedAt := checkpoints[0].CreatedAt
+
+	for _, entity := range entities {
+		switch entity {
+		case EntityTypeLocation:
+			deleted, err := tx.GetCore().SoftDeleteLocationsNotSyncedSince(ctx, core.SoftDeleteLocationsNotSyncedSinceParams{
+				IntegrationID: integrationID,
+				UpdatedAt:     syncStartedAt,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to soft delete locations")
+			}
+			logDeleted(integrationID, "locations", deleted)
+
+		case EntityTypeProduct:
+			// Variants and inventory items are synced together with their products
+			deleted, err := tx.GetCore().SoftDeleteProductsNotSyncedSince(ctx, core.SoftDeleteProductsNotSyncedSinceParams{
+				IntegrationID: integrationID,
+				UpdatedAt:     syncStartedAt,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to soft delete products")
+			}
+			logDeleted(integrationID, "products", deleted)
+
+			deleted, err = tx.GetCore().SoftDeleteProductVariantsNotSyncedSince(ctx, core.SoftDeleteProductVariantsNotSyncedSinceParams{
+				IntegrationID: integrationID,
+				UpdatedAt:     syncStartedAt,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to soft delete product variants")
+			}
+			logDeleted(integrationID, "product_variants", deleted)
+
+			deleted, err = tx.GetCore().SoftDeleteInventoryItemsNotSyncedSince(ctx, core.SoftDeleteInventoryItemsNotSyncedSinceParams{
+				IntegrationID: integrationID,
+				UpdatedAt:     syncStartedAt,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to soft delete inventory items")
+			}
+			logDeleted(integrationID, "inventory_items", deleted)
+		}
+	}
+
+	return nil
+}
+
+// logDeleted reports how many rows of a table were soft deleted
+func logDeleted(integrationID id.ID[id.PlatformIntegration], table string, count int64) {
+	if count > 0 {
+		logger.Info("Soft deleted records removed on the platform", "integration_id", integrationID, "table", table, "count", count)
+	}
+}
@@ -0,0 +1,486 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"slices"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// Number of example rows kept for each kind of change per entity
+const dryRunSampleSize = 5
+
+// Variants have no sync entity of their own, they are synced with their products
+const entityTypeProductVariant EntityType = "product_variant"
+
+// Kinds of change reported by a dry run
+const (
+	DiffChangeNew     = "new"
+	DiffChangeUpdated = "updated"
+	DiffChangeRemoved = "removed"
+)
+
+// SyncDiff summarizes what a full sync would change, per entity
+type SyncDiff struct {
+	Locations       EntityDiff `json:"locations"`
+	Products        EntityDiff `json:"products"`
+	ProductVariants EntityDiff `json:"product_variants"`
+	InventoryItems  EntityDiff `json:"inventory_items"`
+	InventoryLevels EntityDiff `json:"inventory_levels"`
+}
+
+// EntityDiff counts the changes to one entity and keeps a few examples of each
+type EntityDiff struct {
+	New       int          `json:"new"`
+	Updated   int          `json:"updated"`
+	Removed   int          `json:"removed"`
+	Unchanged int          `json:"unchanged"`
+	Samples   []DiffSample `json:"samples,omitempty"`
+}
+
+// DiffSample is an example row of a change
+type DiffSample struct {
+	Change     string   `json:"change"`
+	ExternalID string   `json:"external_id"`
+	Label      string   `json:"label"`
+	Fields     []string `json:"fields,omitempty"`
+}
+
+// record counts a change and keeps it as a sample while there is room for its kind
+func (d *EntityDiff) record(change, externalID, label string, fields []string) {
+	var count int
+	switch change {
+	case DiffChangeNew:
+		d.New++
+		count = d.New
+	case DiffChangeUpdated:
+		d.Updated++
+		count = d.Updated
+	case DiffChangeRemoved:
+		d.Removed++
+		count = d.Removed
+	}
+
+	if count <= dryRunSampleSize {
+		d.Samples = append(d.Samples, DiffSample{Change: change, ExternalID: externalID, Label: label, Fields: fields})
+	}
+}
+
+// currentRows holds the live rows of an integration by external ID, and which of them the platform still returns
+type currentRows struct {
+	locations      map[string]core.Location
+	products       map[string]core.Product
+	variants       map[string]core.ProductVariant
+	inventoryItems map[string]core.InventoryItem
+
+	// Levels are keyed by levelKey of their item's and location's external IDs
+	inventoryLevels map[string]core.GetInventoryLevelsByIntegrationIDRow
+
+	seen map[EntityType]map[string]bool
+}
+
+// markSeen records that the platform returned the row and reports whether it had already been compared
+func (c *currentRows) markSeen(entity EntityType, externalID string) bool {
+	if c.seen[entity] == nil {
+		c.seen[entity] = make(map[string]bool)
+	}
+	if c.seen[entity][externalID] {
+		return true
+	}
+	c.seen[entity][externalID] = true
+	return false
+}
+
+// levelKey identifies an inventory level by the external IDs of its item and location
+func levelKey(inventoryItemID, locationID string) string {
+	return inventoryItemID + "@" + locationID
+}
+
+// startDryRun records a dry run of the integration as a sync run and enqueues it
+func (m *InventorySyncManager) startDryRun(ctx context.Context, integration core.PlatformIntegration, trigger SyncTrigger) (*SyncResult, error) {
+	run, err := m.database.GetCore().CreateSyncRun(ctx, core.CreateSyncRunParams{
+		ID:            id.NewGeneration[id.SyncRun](),
+		IntegrationID: integration.ID,
+		EntityType:    ToCoreEntity(EntityTypeFullSync),
+		Trigger:       ToCoreTrigger(trigger),
+		SyncStatus:    core.SyncStatusInProgress,
+		DryRun:        true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create sync run")
+	}
+
+	if err := m.queue.EnqueueSyncDryRun(ctx, integration.ID, run.ID); err != nil {
+		_ = m.finishDryRun(ctx, run.ID, SyncStatusFailed, nil, "failed to enqueue dry run task")
+		return nil, errors.Wrap(err, "failed to enqueue dry run task")
+	}
+
+	return &SyncResult{
+		IntegrationID: integration.ID.String(),
+		Status:        SyncStatusDryRun,
+		RunID:         run.ID.String(),
+	}, nil
+}
+
+// RunSyncDryRun runs the dry run recorded as the given sync run and stores its diff on the run.
+// A dry run cancelled before it finished is recorded as cancelled, without a diff.
+func (m *InventorySyncManager) RunSyncDryRun(ctx context.Context, integrationID id.ID[id.PlatformIntegration], runID id.ID[id.SyncRun]) error {
+	run := &syncRun{id: runID, integrationID: integrationID}
+	if m.cancelRequested(ctx, run) {
+		return m.finishDryRun(ctx, runID, SyncStatusCancelled, nil, "")
+	}
+
+	integration, err := m.database.GetCore().GetPlatformIntegrationByID(ctx, integrationID)
+	if err != nil {
+		err = errors.Wrap(err, "failed to get integration")
+	}
+	var diff *SyncDiff
+	if err == nil {
+		diff, err = m.dryRunSync(ctx, integration)
+	}
+
+	switch {
+	case m.cancelRequested(ctx, run):
+		return m.finishDryRun(ctx, runID, SyncStatusCancelled, nil, "")
+	case err != nil:
+		if finishErr := m.finishDryRun(ctx, runID, SyncStatusFailed, nil, err.Error()); finishErr != nil {
+			return finishErr
+		}
+		return errors.Wrap(err, "failed to run sync dry run")
+	}
+	return m.finishDryRun(ctx, runID, SyncStatusCompleted, diff, "")
+}
+
+// finishDryRun records the outcome of a dry run on its run
+func (m *InventorySyncManager) finishDryRun(ctx context.Context, runID id.ID[id.SyncRun], status SyncStatus, diff *SyncDiff, errorMessage string) error {
+	var encoded []byte
+	if diff != nil {
+		var err error
+		if encoded, err = json.Marshal(diff); err != nil {
+			return errors.Wrap(err, "failed to encode sync diff")
+		}
+	}
+
+	_, err := m.database.GetCore().FinishSyncDryRun(context.WithoutCancel(ctx), core.FinishSyncDryRunParams{
+		ID:           runID,
+		SyncStatus:   ToCoreStatus(status),
+		Diff:         encoded,
+		ErrorMessage: pgtype.Text{String: errorMessage, Valid: errorMessage != ""},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to finish sync dry run")
+	}
+	return nil
+}
+
+// dryRunSync fetches and normalizes the catalog and stock a full sync would, and compares them with
+// the current rows of the integration instead of writing them
+func (m *InventorySyncManager) dryRunSync(ctx context.Context, integration core.PlatformIntegration) (*SyncDiff, error) {
+	current, err := m.loadCurrentRows(ctx, integration.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := m.connectors.Connect(ctx, integration)
+	if err != nil {
+		return nil, err
+	}
+	diff := &SyncDiff{}
+
+	for _, entity := range fullSyncEntities {
+		// The diff covers the catalog and stock, orders are not previewed
+		if entity == EntityTypeOrder {
+			continue
+		}
+
+		pageInfo := ""
+		for {
+			page, err := m.fetchPage(ctx, conn, entity, pageInfo)
+			if err != nil {
+				return nil, err
+			}
+
+			data := m.normalizePage(integration.ID, *page)
+			if err := m.diffPage(ctx, integration.ID, current, data, diff); err != nil {
+				return nil, err
+			}
+
+			if page.nextPageInfo == "" {
+				break
+			}
+			pageInfo = page.nextPageInfo
+		}
+	}
+
+	// Whatever the platform no longer returns would be soft-deleted
+	for externalID, location := range current.locations {
+		if !current.seen[EntityTypeLocation][externalID] {
+			diff.Locations.record(DiffChangeRemoved, externalID, location.Name, nil)
+		}
+	}
+	for externalID, product := range current.products {
+		if !current.seen[EntityTypeProduct][externalID] {
+			diff.Products.record(DiffChangeRemoved, externalID, product.Title, nil)
+		}
+	}
+	for externalID, variant := range current.variants {
+		if !current.seen[entityTypeProductVariant][externalID] {
+			diff.ProductVariants.record(DiffChangeRemoved, externalID, variant.Sku.String, nil)
+		}
+	}
+	for externalID, item := range current.inventoryItems {
+		if !current.seen[EntityTypeInventoryItem][externalID] {
+			diff.InventoryItems.record(DiffChangeRemoved, externalID, item.Sku.String, nil)
+		}
+	}
+
+	// A sync only upserts levels, the ones the platform no longer returns are kept and not reported
+
+	logger.Info("Sync dry run completed",
+		"integration_id", integration.ID,
+		"products_new", diff.Products.New,
+		"products_updated", diff.Products.Updated,
+		"products_removed", diff.Products.Removed)
+
+	return diff, nil
+}
+
+// diffPage compares one normalized page with the current rows
+func (m *InventorySyncManager) diffPage(ctx context.Context, integrationID id.ID[id.PlatformIntegration], current *currentRows, data *SyncData, diff *SyncDiff) error {
+	for _, location := range data.Locations {
+		externalID := location.ExternalID.String
+		if current.markSeen(EntityTypeLocation, externalID) {
+			continue
+		}
+
+		existing, ok := current.locations[externalID]
+		if !ok {
+			diff.Locations.record(DiffChangeNew, externalID, location.Name, nil)
+			continue
+		}
+		changedFields(&diff.Locations, externalID, location.Name,
+			field("name", existing.Name == location.Name),
+			field("address", existing.Address == location.Address),
+			field("country", existing.Country == location.Country),
+			field("province", existing.Province == location.Province),
+			field("is_active", existing.IsActive == location.IsActive))
+	}
+
+	for _, product := range data.Products {
+		externalID := product.ExternalID.String
+		if current.markSeen(EntityTypeProduct, externalID) {
+			continue
+		}
+
+		existing, ok := current.products[externalID]
+		if !ok {
+			diff.Products.record(DiffChangeNew, externalID, product.Title, nil)
+			continue
+		}
+		changedFields(&diff.Products, externalID, product.Title,
+			field("title", existing.Title == product.Title),
+			field("handle", existing.Handle == product.Handle),
+			field("product_type", existing.ProductType == product.ProductType),
+			field("status", existing.Status == product.Status),
+			field("vendor", existing.Vendor == product.Vendor),
+			field("tags", slices.Equal(existing.Tags, product.Tags)),
+			field("option_names", slices.Equal(existing.OptionNames, product.OptionNames)),
+			field("published_at", timestampEqual(existing.PublishedAt, product.PublishedAt)))
+	}
+
+	for _, variant := range data.ProductVariants {
+		externalID := variant.ExternalID.String
+		if current.markSeen(entityTypeProductVariant, externalID) {
+			continue
+		}
+
+		existing, ok := current.variants[externalID]
+		if !ok {
+			diff.ProductVariants.record(DiffChangeNew, externalID, variant.Sku.String, nil)
+			continue
+		}
+		changedFields(&diff.ProductVariants, externalID, variant.Sku.String,
+			field("sku", existing.Sku == variant.Sku),
+			field("price", numericEqual(existing.Price, variant.Price)),
+			field("inventory_item_id", existing.InventoryItemID == variant.InventoryItemID),
+			field("barcode", existing.Barcode == variant.Barcode),
+			field("option_values", slices.Equal(existing.OptionValues, variant.OptionValues)),
+			field("compare_at_price", numericEqual(existing.CompareAtPrice, variant.CompareAtPrice)),
+			field("weight", numericEqual(existing.Weight, variant.Weight)),
+			field("weight_unit", existing.WeightUnit == variant.WeightUnit))
+	}
+
+	for _, item := range data.InventoryItems {
+		externalID := item.ExternalID.String
+		if current.markSeen(EntityTypeInventoryItem, externalID) {
+			continue
+		}
+
+		existing, ok := current.inventoryItems[externalID]
+		if !ok {
+			diff.InventoryItems.record(DiffChangeNew, externalID, item.Sku.String, nil)
+			continue
+		}
+		changedFields(&diff.InventoryItems, externalID, item.Sku.String,
+			field("sku", existing.Sku == item.Sku),
+			field("tracked", existing.Tracked == item.Tracked),
+			field("cost", numericEqual(existing.Cost, item.Cost)))
+	}
+
+	for _, level := range data.InventoryLevels {
+		key := levelKey(level.InventoryItemID, level.LocationID)
+		if current.markSeen(EntityTypeInventoryLevel, key) {
+			continue
+		}
+
+		existing, ok := current.inventoryLevels[key]
+		if !ok {
+			diff.InventoryLevels.record(DiffChangeNew, key, current.levelLabel(level), nil)
+			continue
+		}
+		changedFields(&diff.InventoryLevels, key, current.levelLabel(level),
+			field("available", existing.Available.Valid && existing.Available.Int32 == int32(level.Available)))
+	}
+
+	return nil
+}
+
+// levelLabel names a level by its item's SKU and its location's name, as far as they are stored
+func (c *currentRows) levelLabel(level connector.InventoryLevel) string {
+	item, location := level.InventoryItemID, level.LocationID
+	if existing, ok := c.inventoryItems[item]; ok && existing.Sku.String != "" {
+		item = existing.Sku.String
+	}
+	if existing, ok := c.locations[location]; ok {
+		location = existing.Name
+	}
+	return item + " at " + location
+}
+
+// fieldCheck is the outcome of comparing one column
+type fieldCheck struct {
+	name  string
+	equal bool
+}
+
+func field(name string, equal bool) fieldCheck {
+	return fieldCheck{name: name, equal: equal}
+}
+
+// changedFields records the row as updated when any column differs, and as unchanged otherwise
+func changedFields(d *EntityDiff, externalID, label string, checks ...fieldCheck) {
+	var changed []string
+	for _, check := range checks {
+		if !check.equal {
+			changed = append(changed, check.name)
+		}
+	}
+
+	if len(changed) == 0 {
+		d.Unchanged++
+		return
+	}
+	d.record(DiffChangeUpdated, externalID, label, changed)
+}
+
+// numericEqual compares two numerics by value, so 10 and 10.00 are equal
+func numericEqual(a, b pgtype.Numeric) bool {
+	if !a.Valid || !b.Valid || a.NaN || b.NaN {
+		return a.Valid == b.Valid && a.NaN == b.NaN
+	}
+	return numericRat(a).Cmp(numericRat(b)) == 0
+}
+
+// timestampEqual compares two timestamps by the instant they hold
+func timestampEqual(a, b pgtype.Timestamp) bool {
+	return a.Valid == b.Valid && a.Time.Equal(b.Time)
+}
+
+// numericRat converts a valid numeric (Int * 10^Exp) to an exact rational
+func numericRat(n pgtype.Numeric) *big.Rat {
+	exp := int64(n.Exp)
+	if exp < 0 {
+		exp = -exp
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)
+
+	if n.Exp < 0 {
+		return new(big.Rat).SetFrac(n.Int, scale)
+	}
+	return new(big.Rat).SetInt(new(big.Int).Mul(n.Int, scale))
+}
+
+// loadCurrentRows reads the live rows of an integration, keyed by external ID. Each entity is read
+// in a single query: rows written by one batch share created_at, so offset paging would be unstable.
+func (m *InventorySyncManager) loadCurrentRows(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (*currentRows, error) {
+	current := &currentRows{
+		locations:      make(map[string]core.Location),
+		products:       make(map[string]core.Product),
+		variants:       make(map[string]core.ProductVariant),
+		inventoryItems: make(map[string]core.InventoryItem),
+		seen:           make(map[EntityType]map[string]bool),
+
+		inventoryLevels: make(map[string]core.GetInventoryLevelsByIntegrationIDRow),
+	}
+	queries := m.database.GetCore()
+
+	// Inactive locations are synced and soft deleted like active ones
+	locations, err := queries.GetAllLocationsByIntegrationID(ctx, integrationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get locations")
+	}
+	for _, row := range locations {
+		current.locations[row.ExternalID.String] = row
+	}
+
+	products, err := queries.GetProductsByIntegrationID(ctx, core.GetProductsByIntegrationIDParams{
+		IntegrationID: integrationID,
+		Limit:         math.MaxInt32,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get products")
+	}
+	for _, row := range products {
+		current.products[row.ExternalID.String] = row
+	}
+
+	variants, err := queries.GetProductVariantsByIntegrationID(ctx, core.GetProductVariantsByIntegrationIDParams{
+		IntegrationID: integrationID,
+		Limit:         math.MaxInt32,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get product variants")
+	}
+	for _, row := range variants {
+		current.variants[row.ExternalID.String] = row
+	}
+
+	inventoryItems, err := queries.GetInventoryItemsByIntegrationID(ctx, core.GetInventoryItemsByIntegrationIDParams{
+		IntegrationID: integrationID,
+		Limit:         math.MaxInt32,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get inventory items")
+	}
+	for _, row := range inventoryItems {
+		current.inventoryItems[row.ExternalID.String] = row
+	}
+
+	levels, err := queries.GetInventoryLevelsByIntegrationID(ctx, integrationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get inventory levels")
+	}
+	for _, row := range levels {
+		current.inventoryLevels[levelKey(row.InventoryItemExternalID.String, row.LocationExternalID.String)] = row
+	}
+
+	return current, nil
+}
@@ -0,0 +1,197 @@
+package manager
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector/woocommerce/woocommercetest"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDryRunDiffsInventoryLevels(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	for _, entity := range []EntityType{EntityTypeLocation, EntityTypeProduct, EntityTypeInventoryLevel} {
+		if err := st.manager.runSync(st.ctx, integrationID, entityScope(entity), SyncTriggerManual); err != nil {
+			t.Fatalf("sync %s: %v", entity, err)
+		}
+	}
+
+	// The tote sold two, and a new tracked product was added
+	store.UpdateFixtures(func(fixtures *woocommercetest.Fixtures) {
+		stock := 10
+		fixtures.Products[0].StockQuantity = &stock
+
+		mug := fixtures.Products[0]
+		mug.ID, mug.Name, mug.Slug, mug.SKU = 14, "Mug", "mug", "MUG-1"
+		fixtures.Products = append(fixtures.Products, mug)
+	})
+
+	integration, err := st.db.GetPlatformIntegrationByID(st.ctx, integrationID)
+	if err != nil {
+		t.Fatalf("GetPlatformIntegrationByID: %v", err)
+	}
+	diff, err := st.manager.dryRunSync(st.ctx, integration)
+	if err != nil {
+		t.Fatalf("dryRunSync: %v", err)
+	}
+
+	levels := diff.InventoryLevels
+	if levels.New != 1 || levels.Updated != 1 || levels.Removed != 0 || levels.Unchanged == 0 {
+		t.Fatalf("inventory levels diff = %+v, want the mug's level new and the tote's updated", levels)
+	}
+	for _, sample := range levels.Samples {
+		switch sample.Change {
+		case DiffChangeNew:
+			if !strings.HasPrefix(sample.Label, "14 at ") {
+				t.Errorf("new level = %+v, want the mug's, labelled by external ID until it is stored", sample)
+			}
+		case DiffChangeUpdated:
+			if !strings.HasPrefix(sample.Label, "TOTE-1 at ") || !slices.Equal(sample.Fields, []string{"available"}) {
+				t.Errorf("updated level = %+v, want the tote's available count", sample)
+			}
+		}
+	}
+}
+
+func TestDryRunRunsInTheBackground(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	statusBefore, err := st.manager.GetSyncStatus(st.ctx, st.userID, testShopDomain, integrationID)
+	if err != nil {
+		t.Fatalf("GetSyncStatus: %v", err)
+	}
+
+	result, err := st.manager.TriggerShopifySync(st.ctx, SyncRequest{
+		UserID:        st.userID,
+		ShopDomain:    testShopDomain,
+		Trigger:       SyncTriggerManual,
+		DryRun:        true,
+		IntegrationID: integrationID,
+	})
+	if err != nil {
+		t.Fatalf("TriggerShopifySync: %v", err)
+	}
+	if result.Status != SyncStatusDryRun || result.RunID == "" {
+		t.Fatalf("result = %+v, want a started dry run", result)
+	}
+
+	// The dry run is queued, leaving the sync state alone
+	dryRuns := st.queue.DryRuns()
+	if len(dryRuns) != 1 || dryRuns[0].IntegrationID != integrationID || dryRuns[0].RunID.String() != result.RunID {
+		t.Fatalf("enqueued dry runs = %+v, want run %s", dryRuns, result.RunID)
+	}
+	status, err := st.manager.GetSyncStatus(st.ctx, st.userID, testShopDomain, integrationID)
+	if err != nil || status.Status != statusBefore.Status {
+		t.Errorf("status = %+v %v, want it left at %s", status, err, statusBefore.Status)
+	}
+	runs, err := st.manager.GetSyncRuns(st.ctx, st.userID, testShopDomain, integrationID, 10)
+	if err != nil || len(runs) != 1 || !runs[0].DryRun || runs[0].Status != SyncStatusInProgress || runs[0].Diff != nil {
+		t.Fatalf("runs = %+v %v, want the dry run in progress", runs, err)
+	}
+
+	// The worker records the diff on the run
+	if err := st.manager.RunSyncDryRun(st.ctx, dryRuns[0].IntegrationID, dryRuns[0].RunID); err != nil {
+		t.Fatalf("RunSyncDryRun: %v", err)
+	}
+	runs, err = st.manager.GetSyncRuns(st.ctx, st.userID, testShopDomain, integrationID, 10)
+	if err != nil || len(runs) != 1 || runs[0].Status != SyncStatusCompleted || runs[0].FinishedAt == nil || runs[0].Diff == nil {
+		t.Fatalf("runs = %+v %v, want the dry run completed with its diff", runs, err)
+	}
+	if diff := runs[0].Diff; diff.Products.New != 3 || diff.InventoryLevels.New != 4 {
+		t.Errorf("diff = %+v, want every product and level of the store new", diff)
+	}
+	if products, _ := st.db.GetProductsByIntegrationID(st.ctx, core.GetProductsByIntegrationIDParams{IntegrationID: integrationID, Limit: 10}); len(products) != 0 {
+		t.Errorf("dry run wrote products %+v", products)
+	}
+}
+
+func TestCancelledDryRunRecordsNoDiff(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	if _, err := st.manager.TriggerShopifySync(st.ctx, SyncRequest{
+		UserID:        st.userID,
+		ShopDomain:    testShopDomain,
+		DryRun:        true,
+		IntegrationID: integrationID,
+	}); err != nil {
+		t.Fatalf("TriggerShopifySync: %v", err)
+	}
+
+	if _, err := st.manager.CancelSync(st.ctx, st.userID, testShopDomain, integrationID); err != nil {
+		t.Fatalf("CancelSync: %v", err)
+	}
+	dryRun := st.queue.DryRuns()[0]
+	if err := st.manager.RunSyncDryRun(st.ctx, dryRun.IntegrationID, dryRun.RunID); err != nil {
+		t.Fatalf("RunSyncDryRun: %v", err)
+	}
+
+	runs, err := st.manager.GetSyncRuns(st.ctx, st.userID, testShopDomain, integrationID, 10)
+	if err != nil || len(runs) != 1 || runs[0].Status != SyncStatusCancelled || runs[0].Diff != nil {
+		t.Errorf("runs = %+v %v, want the dry run cancelled without a diff", runs, err)
+	}
+}
+
+func TestDryRunComparesInactiveLocations(t *testing.T) {
+	st := newSyncTest(t)
+	store := newWooCommerceStore(t)
+
+	connected, err := st.connectWooCommerce(store.URL, woocommercetest.ConsumerSecret)
+	if err != nil {
+		t.Fatalf("ConnectWooCommerce: %v", err)
+	}
+	integrationID := id.ID[id.PlatformIntegration](connected.ID)
+	if err := st.manager.SyncLocations(st.ctx, integrationID, string(SyncTriggerManual)); err != nil {
+		t.Fatalf("SyncLocations: %v", err)
+	}
+
+	// A closed warehouse the store no longer lists is soft deleted by a full sync, active or not
+	_, err = st.db.UpsertLocation(st.ctx, core.UpsertLocationParams{
+		ID:            id.NewGeneration[id.Location](),
+		IntegrationID: integrationID,
+		ExternalID:    pgtype.Text{String: "closed", Valid: true},
+		Name:          "Closed warehouse",
+		IsActive:      pgtype.Bool{Bool: false, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("UpsertLocation: %v", err)
+	}
+
+	integration, err := st.db.GetPlatformIntegrationByID(st.ctx, integrationID)
+	if err != nil {
+		t.Fatalf("GetPlatformIntegrationByID: %v", err)
+	}
+	diff, err := st.manager.dryRunSync(st.ctx, integration)
+	if err != nil {
+		t.Fatalf("dryRunSync: %v", err)
+	}
+
+	locations := diff.Locations
+	if locations.Removed != 1 || locations.New != 0 || locations.Unchanged != 1 {
+		t.Fatalf("locations diff = %+v, want the closed warehouse removed", locations)
+	}
+	if sample := locations.Samples[0]; sample.ExternalID != "closed" {
+		t.Errorf("removed location = %+v, want the closed warehouse", sample)
+	}
+}
@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/internal/events"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+)
+
+// publishEvent broadcasts a sync event for the run. Failures are only logged
+// since progress reporting must never fail a sync.
+func (m *InventorySyncManager) publishEvent(ctx context.Context, run *syncRun, event events.SyncEvent) {
+	event.RunID = run.id.String()
+	event.IntegrationID = run.integrationID.String()
+	event.Timestamp = time.Now().UTC()
+
+	if err := m.events.PublishSyncEvent(context.WithoutCancel(ctx), run.integrationID, event); err != nil {
+		logger.Warn("Failed to publish sync event", "integration_id", run.integrationID, "type", event.Type, "error", err)
+	}
+}
+
+// countEntities asks the platform how many rows each entity has so progress events can carry an ETA.
+// Inventory levels cannot be counted up front, and an entity whose count fails is left out too, as
+// are all entities of connectors that cannot count. Such entities report no total, see progressEvent.
+func (m *InventorySyncManager) countEntities(ctx context.Context, conn connector.PlatformConnector, entities []EntityType) map[EntityType]int {
+	totals := make(map[EntityType]int)
+	counter, ok := conn.(connector.Counter)
+	if !ok {
+		return totals
+	}
+
+	for _, entity := range entities {
+		var count int
+		var err error
+
+		switch entity {
+		case EntityTypeLocation:
+			count, err = counter.CountLocations(ctx)
+		case EntityTypeProduct:
+			count, err = counter.CountProducts(ctx)
+		case EntityTypeOrder:
+			count, err = counter.CountOrders(ctx, time.Now().AddDate(0, 0, -orderSyncDays))
+		default:
+			continue
+		}
+		if err != nil {
+			logger.Warn("Failed to count entity rows, no ETA will be reported", "entity", entity, "error", err)
+			continue
+		}
+		totals[entity] = count
+	}
+	return totals
+}
+
+// primaryRows returns how many rows of the entity itself (not its children) are in the stats
+func primaryRows(entity EntityType, stats SyncStats) int {
+	switch entity {
+	case EntityTypeLocation:
+		return stats.LocationsCount
+	case EntityTypeProduct:
+		return stats.ProductsCount
+	case EntityTypeInventoryLevel:
+		return stats.InventoryLevelsCount
+	case EntityTypeOrder:
+		return stats.OrdersCount
+	default:
+		return 0
+	}
+}
+
+// progressEvent describes the run after a page of the entity was committed. The ETA covers the
+// entities with a known total; the others have an indeterminate total and are left out of it.
+func (r *syncRun) progressEvent(entity EntityType, progress map[EntityType]*entityProgress) events.SyncEvent {
+	event := events.SyncEvent{
+		Type:      events.SyncEventProgress,
+		Phase:     events.SyncPhaseSync,
+		Entity:    string(entity),
+		TotalRows: r.totals[entity],
+	}
+	if p, ok := progress[entity]; ok {
+		event.PagesFetched = int(p.pages)
+		event.RowsUpserted = primaryRows(entity, p.stats)
+	}
+
+	// Extrapolate the throughput of this attempt over the rows still to go
+	done, remaining := 0, 0
+	for _, e := range r.scope.entities {
+		total, ok := r.totals[e]
+		if !ok {
+			continue
+		}
+
+		rows := 0
+		if p, ok := progress[e]; ok {
+			rows = primaryRows(e, p.stats)
+		}
+		done += rows
+		remaining += max(total-rows, 0)
+	}
+
+	elapsed := time.Since(r.pipelineStarted).Seconds()
+	if throughput := float64(done-r.resumedRows) / elapsed; throughput > 0 {
+		eta := int64(float64(remaining) / throughput)
+		event.ETASeconds = &eta
+	}
+
+	return event
+}
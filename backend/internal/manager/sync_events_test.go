@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressEventEstimatesCountedEntities(t *testing.T) {
+	// Inventory levels cannot be counted, the other entities of a full sync can
+	run := &syncRun{
+		scope:           syncScope{stateEntity: EntityTypeFullSync, entities: fullSyncEntities},
+		totals:          map[EntityType]int{EntityTypeLocation: 2, EntityTypeProduct: 100, EntityTypeOrder: 50},
+		pipelineStarted: time.Now().Add(-10 * time.Second),
+	}
+	progress := map[EntityType]*entityProgress{
+		EntityTypeLocation:       {pages: 1, stats: SyncStats{LocationsCount: 2}},
+		EntityTypeProduct:        {pages: 2, stats: SyncStats{ProductsCount: 100, ProductVariantsCount: 300}},
+		EntityTypeInventoryLevel: {pages: 4, stats: SyncStats{InventoryLevelsCount: 600}},
+	}
+
+	event := run.progressEvent(EntityTypeInventoryLevel, progress)
+	if event.TotalRows != 0 || event.RowsUpserted != 600 || event.PagesFetched != 4 {
+		t.Errorf("event = %+v, want 600 rows of an indeterminate total", event)
+	}
+
+	// 102 rows in 10 seconds leave about 5 seconds for the 50 orders
+	if event.ETASeconds == nil || *event.ETASeconds < 4 || *event.ETASeconds > 5 {
+		t.Fatalf("eta = %v, want about 5 seconds", event.ETASeconds)
+	}
+
+	event = run.progressEvent(EntityTypeProduct, progress)
+	if event.TotalRows != 100 || event.ETASeconds == nil {
+		t.Errorf("event = %+v, want the product total and an ETA", event)
+	}
+
+	// Without any count there is nothing to estimate
+	run.totals = map[EntityType]int{}
+	if event := run.progressEvent(EntityTypeProduct, progress); event.ETASeconds != nil {
+		t.Errorf("eta = %d, want none", *event.ETASeconds)
+	}
+}
@@ -0,0 +1,180 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	// How often a running sync refreshes the heartbeat of its sync state
+	syncHeartbeatInterval = 30 * time.Second
+
+	// An in_progress state without a heartbeat for this long is considered stuck. It is generous
+	// so a sync still waiting in a busy queue after TriggerShopifySync is not failed before it starts.
+	syncStaleAfter = 15 * time.Minute
+)
+
+const (
+	staleSyncError = "sync stopped reporting progress, the worker may have crashed; trigger a new sync"
+	lostSyncError  = "sync task was lost before it finished; trigger a new sync"
+)
+
+// startHeartbeat refreshes the heartbeat of the run's sync state until the returned stop function is called
+func (m *InventorySyncManager) startHeartbeat(ctx context.Context, run *syncRun) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(syncHeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := m.database.GetCore().TouchSyncStateHeartbeat(ctx, core.TouchSyncStateHeartbeatParams{
+					IntegrationID: run.integrationID,
+					EntityType:    ToCoreEntity(run.scope.stateEntity),
+				})
+				if err != nil && ctx.Err() == nil {
+					logger.Warn("Failed to refresh sync heartbeat", "integration_id", run.integrationID, "scope", run.scope.stateEntity, "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// isStale reports whether an in_progress state has stopped receiving heartbeats
+func isStale(state core.SyncState) bool {
+	return state.HeartbeatAt.Valid && time.Since(state.HeartbeatAt.Time) > syncStaleAfter
+}
+
+// ReapStaleSyncs fails the in_progress sync states whose sync stopped sending heartbeats,
+// along with their unfinished runs, so new syncs are no longer blocked by them
+func (m *InventorySyncManager) ReapStaleSyncs(ctx context.Context) error {
+	errorMessage := pgtype.Text{String: staleSyncError, Valid: true}
+
+	var reaped []core.SyncState
+	err := m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		var err error
+		reaped, err = tx.GetCore().FailStaleSyncStates(ctx, core.FailStaleSyncStatesParams{
+			HeartbeatAt:  pgtype.Timestamp{Time: time.Now().UTC().Add(-syncStaleAfter), Valid: true},
+			ErrorMessage: errorMessage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to fail stale sync states")
+		}
+
+		for _, state := range reaped {
+			_, err := tx.GetCore().FailUnfinishedSyncRuns(ctx, core.FailUnfinishedSyncRunsParams{
+				IntegrationID: state.IntegrationID,
+				EntityType:    state.EntityType,
+				ErrorMessage:  errorMessage,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to fail unfinished sync runs")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, state := range reaped {
+		logger.Warn("Failed stale sync state",
+			"integration_id", state.IntegrationID,
+			"entity_type", state.EntityType,
+			"heartbeat_at", state.HeartbeatAt.Time)
+	}
+
+	return nil
+}
+
+// syncTaskKey identifies the sync state a sync task reports to
+type syncTaskKey struct {
+	integrationID id.ID[id.PlatformIntegration]
+	entity        EntityType
+}
+
+// ReconcileSyncStates fails the in_progress sync states that have no sync task running or waiting to run.
+// It runs when a worker starts, so states left behind by a crash or a lost task are recovered
+// right away instead of waiting for the reaper.
+func (m *InventorySyncManager) ReconcileSyncStates(ctx context.Context) error {
+	tasks, err := m.queue.ListInventorySyncs(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list sync tasks")
+	}
+
+	live := make(map[syncTaskKey]bool, len(tasks))
+	for _, task := range tasks {
+		live[syncTaskKey{integrationID: task.IntegrationID, entity: EntityType(task.Entity)}] = true
+	}
+
+	states, err := m.database.GetCore().GetInProgressSyncStates(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get in progress sync states")
+	}
+
+	reconciled := 0
+	for _, state := range states {
+		if live[syncTaskKey{integrationID: state.IntegrationID, entity: FromCoreEntity(state.EntityType)}] {
+			continue
+		}
+
+		if err := m.failLostSync(ctx, state); err != nil {
+			return err
+		}
+		reconciled++
+	}
+
+	logger.Info("Reconciled sync states with the task queue", "in_progress", len(states), "failed", reconciled)
+	return nil
+}
+
+// failLostSync fails an in_progress state and its unfinished runs. The state is only
+// changed if it is still in_progress, so a sync that just finished is left alone.
+func (m *InventorySyncManager) failLostSync(ctx context.Context, state core.SyncState) error {
+	errorMessage := pgtype.Text{String: lostSyncError, Valid: true}
+
+	return m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		failed, err := tx.GetCore().FailInProgressSyncState(ctx, core.FailInProgressSyncStateParams{
+			IntegrationID: state.IntegrationID,
+			EntityType:    state.EntityType,
+			ErrorMessage:  errorMessage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to fail lost sync state")
+		}
+		if failed == 0 {
+			return nil
+		}
+
+		_, err = tx.GetCore().FailUnfinishedSyncRuns(ctx, core.FailUnfinishedSyncRunsParams{
+			IntegrationID: state.IntegrationID,
+			EntityType:    state.EntityType,
+			ErrorMessage:  errorMessage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to fail unfinished sync runs")
+		}
+
+		logger.Warn("Failed sync state with no running task", "integration_id", state.IntegrationID, "entity_type", state.EntityType)
+		return nil
+	})
+}
@@ -0,0 +1,220 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/db"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// SyncTrigger identifies what started a sync run
+type SyncTrigger string
+
+const (
+	SyncTriggerOAuth    SyncTrigger = "oauth"
+	SyncTriggerManual   SyncTrigger = "manual"
+	SyncTriggerSchedule SyncTrigger = "schedule"
+	SyncTriggerWebhook  SyncTrigger = "webhook"
+)
+
+func FromCoreTrigger(coreTrigger core.SyncTrigger) SyncTrigger {
+	switch coreTrigger {
+	case core.SyncTriggerOauth:
+		return SyncTriggerOAuth
+	case core.SyncTriggerSchedule:
+		return SyncTriggerSchedule
+	case core.SyncTriggerWebhook:
+		return SyncTriggerWebhook
+	default:
+		return SyncTriggerManual
+	}
+}
+
+func ToCoreTrigger(managerTrigger SyncTrigger) core.SyncTrigger {
+	switch managerTrigger {
+	case SyncTriggerOAuth:
+		return core.SyncTriggerOauth
+	case SyncTriggerSchedule:
+		return core.SyncTriggerSchedule
+	case SyncTriggerWebhook:
+		return core.SyncTriggerWebhook
+	default:
+		return core.SyncTriggerManual
+	}
+}
+
+// SyncPhaseTimings records the milliseconds spent in each phase of a sync run.
+// The fetch, normalize and upsert stages overlap, so each is the time that stage was busy.
+type SyncPhaseTimings struct {
+	SetupMs     int64 `json:"setup_ms"`
+	FetchMs     int64 `json:"fetch_ms"`
+	NormalizeMs int64 `json:"normalize_ms"`
+	UpsertMs    int64 `json:"upsert_ms"`
+	FinalizeMs  int64 `json:"finalize_ms"`
+}
+
+// SyncRunResult represents one past or running sync attempt
+type SyncRunResult struct {
+	ID           string           `json:"id"`
+	EntityType   EntityType       `json:"entity_type"`
+	Trigger      SyncTrigger      `json:"trigger"`
+	Status       SyncStatus       `json:"status"`
+	StartedAt    time.Time        `json:"started_at"`
+	FinishedAt   *time.Time       `json:"finished_at,omitempty"`
+	PhaseTimings SyncPhaseTimings `json:"phase_timings"`
+	Stats        SyncStats        `json:"stats"`
+	Error        string           `json:"error,omitempty"`
+
+	// A dry run writes nothing, its diff is set once it completed
+	DryRun bool      `json:"dry_run,omitempty"`
+	Diff   *SyncDiff `json:"diff,omitempty"`
+}
+
+// syncRun is one sync attempt and its row in sync_runs. Each pipeline stage only
+// touches its own timing, so the stages can record them without locking.
+type syncRun struct {
+	id            id.ID[id.SyncRun]
+	integrationID id.ID[id.PlatformIntegration]
+	scope         syncScope
+
+	setup     time.Duration
+	fetch     time.Duration
+	normalize time.Duration
+	upsert    time.Duration
+	finalize  time.Duration
+
+	stats *SyncStats
+
+	// Progress reporting: Shopify's row counts and where this attempt started
+	totals          map[EntityType]int
+	pipelineStarted time.Time
+	resumedRows     int
+}
+
+// phaseTimings converts the recorded durations for storage
+func (r *syncRun) phaseTimings() SyncPhaseTimings {
+	return SyncPhaseTimings{
+		SetupMs:     r.setup.Milliseconds(),
+		FetchMs:     r.fetch.Milliseconds(),
+		NormalizeMs: r.normalize.Milliseconds(),
+		UpsertMs:    r.upsert.Milliseconds(),
+		FinalizeMs:  r.finalize.Milliseconds(),
+	}
+}
+
+// startSyncRun marks the scope's sync state as in_progress and records a new run
+func (m *InventorySyncManager) startSyncRun(ctx context.Context, integrationID id.ID[id.PlatformIntegration], scope syncScope, trigger SyncTrigger) (*syncRun, error) {
+	run := &syncRun{
+		id:            id.NewGeneration[id.SyncRun](),
+		integrationID: integrationID,
+		scope:         scope,
+		stats:         &SyncStats{},
+	}
+
+	// Recorded so the task can be cancelled while it runs
+	taskID, ok := asynq.GetTaskID(ctx)
+
+	err := m.database.WithTx(ctx, func(tx *db.TxDB) error {
+		if err := m.updateSyncState(ctx, tx, integrationID, scope.stateEntity, SyncStatusInProgress, ""); err != nil {
+			return errors.Wrap(err, "failed to set sync state to in_progress")
+		}
+
+		_, err := tx.GetCore().CreateSyncRun(ctx, core.CreateSyncRunParams{
+			ID:            run.id,
+			IntegrationID: integrationID,
+			EntityType:    ToCoreEntity(scope.stateEntity),
+			Trigger:       ToCoreTrigger(trigger),
+			SyncStatus:    core.SyncStatusInProgress,
+			TaskID:        pgtype.Text{String: taskID, Valid: ok},
+		})
+		return errors.Wrap(err, "failed to create sync run")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// finishSyncRun records the outcome of a run. It runs in the transaction that updates the sync state.
+func (m *InventorySyncManager) finishSyncRun(ctx context.Context, tx *db.TxDB, run *syncRun, status SyncStatus, errorMessage string) error {
+	timings, err := json.Marshal(run.phaseTimings())
+	if err != nil {
+		return errors.Wrap(err, "failed to encode phase timings")
+	}
+
+	stats, err := json.Marshal(run.stats)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode sync stats")
+	}
+
+	_, err = tx.GetCore().FinishSyncRun(ctx, core.FinishSyncRunParams{
+		ID:           run.id,
+		SyncStatus:   ToCoreStatus(status),
+		PhaseTimings: timings,
+		Stats:        stats,
+		ErrorMessage: pgtype.Text{String: errorMessage, Valid: errorMessage != ""},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to finish sync run")
+	}
+
+	return nil
+}
+
+// GetSyncRuns returns the most recent sync runs of an integration the user has access to, or of
+// the shop's Shopify integration when integrationID is empty, newest first
+func (m *InventorySyncManager) GetSyncRuns(ctx context.Context, userID id.ID[id.User], shopDomain string, integrationID id.ID[id.PlatformIntegration], limit int32) ([]SyncRunResult, error) {
+	integration, found, err := m.getSyncIntegration(ctx, userID, shopDomain, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		// Never synced
+		return []SyncRunResult{}, nil
+	}
+
+	runs, err := m.database.GetCore().GetSyncRunsByIntegrationID(ctx, core.GetSyncRunsByIntegrationIDParams{
+		IntegrationID: integration.ID,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sync runs")
+	}
+
+	results := make([]SyncRunResult, 0, len(runs))
+	for _, run := range runs {
+		result := SyncRunResult{
+			ID:         run.ID.String(),
+			EntityType: FromCoreEntity(run.EntityType),
+			Trigger:    FromCoreTrigger(run.Trigger),
+			Status:     FromCoreStatus(run.SyncStatus),
+			StartedAt:  run.StartedAt.Time,
+			Error:      run.ErrorMessage.String,
+			DryRun:     run.DryRun,
+		}
+		if run.FinishedAt.Valid {
+			result.FinishedAt = &run.FinishedAt.Time
+		}
+		if err := json.Unmarshal(run.PhaseTimings, &result.PhaseTimings); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode phase timings of sync run %s", run.ID)
+		}
+		if err := json.Unmarshal(run.Stats, &result.Stats); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode stats of sync run %s", run.ID)
+		}
+		if len(run.Diff) > 0 {
+			if err := json.Unmarshal(run.Diff, &result.Diff); err != nil {
+				return nil, errors.Wrapf(err, "failed to decode diff of sync run %s", run.ID)
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
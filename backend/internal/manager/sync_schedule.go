@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/interfaces"
+	"github.com/ConradKurth/forecasting/backend/internal/repository/core"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Shortest and longest interval of scheduled syncs. Integrations have none until one is set.
+	MinSyncIntervalMinutes = 60
+	MaxSyncIntervalMinutes = 7 * 24 * 60
+)
+
+// ErrInvalidSyncInterval is returned when setting a sync interval outside of the allowed range
+var ErrInvalidSyncInterval = errors.Errorf("sync interval must be 0 or between %d and %d minutes", MinSyncIntervalMinutes, MaxSyncIntervalMinutes)
+
+// SetSyncIntervalRequest represents a request to change how often an integration is synced
+type SetSyncIntervalRequest struct {
+	UserID        id.ID[id.User]                `json:"user_id"`
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+
+	// IntervalMinutes is the time between scheduled full syncs, 0 turns them off
+	IntervalMinutes int `json:"sync_interval_minutes"`
+}
+
+// SetSyncInterval sets how often the scheduler runs a full sync of an integration the user has access to.
+// The scheduler picks the change up when it next reloads the schedules.
+func (m *InventorySyncManager) SetSyncInterval(ctx context.Context, req SetSyncIntervalRequest) (*IntegrationResult, error) {
+	if req.IntervalMinutes != 0 && (req.IntervalMinutes < MinSyncIntervalMinutes || req.IntervalMinutes > MaxSyncIntervalMinutes) {
+		return nil, ErrInvalidSyncInterval
+	}
+
+	integration, err := m.getUserIntegration(ctx, req.UserID, req.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration.PlatformType == core.PlatformTypeFileImport {
+		return nil, ErrNotSyncable
+	}
+
+	integration, err = m.database.GetCore().SetPlatformIntegrationSyncInterval(ctx, core.SetPlatformIntegrationSyncIntervalParams{
+		ID:                  integration.ID,
+		SyncIntervalMinutes: int32(req.IntervalMinutes),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set sync interval")
+	}
+
+	logger.Info("Sync interval set", "user_id", req.UserID, "integration_id", integration.ID, "interval_minutes", req.IntervalMinutes)
+
+	result := toIntegrationResult(integration)
+	return &result, nil
+}
+
+// GetSyncSchedules returns the sync interval of every active integration with scheduled syncs enabled
+func (m *InventorySyncManager) GetSyncSchedules(ctx context.Context) ([]interfaces.SyncSchedule, error) {
+	integrations, err := m.database.GetCore().GetScheduledPlatformIntegrations(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get scheduled platform integrations")
+	}
+
+	schedules := make([]interfaces.SyncSchedule, 0, len(integrations))
+	for _, integration := range integrations {
+		schedules = append(schedules, interfaces.SyncSchedule{
+			IntegrationID: integration.ID,
+			Interval:      time.Duration(integration.SyncIntervalMinutes) * time.Minute,
+		})
+	}
+
+	return schedules, nil
+}
@@ -21,13 +21,14 @@ var (
 const insertLocationsBatch = `-- name: InsertLocationsBatch :batchexec
 INSERT INTO locations (id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at) 
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-ON CONFLICT (external_id)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     name = EXCLUDED.name,
     address = EXCLUDED.address,
     country = EXCLUDED.country,
     province = EXCLUDED.province,
     is_active = EXCLUDED.is_active,
+    deleted_at = NULL,
     updated_at = EXCLUDED.updated_at
 `
 
@@ -93,13 +94,19 @@ func (b *InsertLocationsBatchBatchResults) Close() error {
 }
 
 const insertProductVariantsBatch = `-- name: InsertProductVariantsBatch :batchexec
-INSERT INTO product_variants (id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at) 
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-ON CONFLICT (external_id)
+INSERT INTO product_variants (id, product_id, integration_id, external_id, sku, price, inventory_item_id, barcode, option_values, compare_at_price, weight, weight_unit, created_at, updated_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     sku = EXCLUDED.sku,
     price = EXCLUDED.price,
     inventory_item_id = EXCLUDED.inventory_item_id,
+    barcode = EXCLUDED.barcode,
+    option_values = EXCLUDED.option_values,
+    compare_at_price = EXCLUDED.compare_at_price,
+    weight = EXCLUDED.weight,
+    weight_unit = EXCLUDED.weight_unit,
+    deleted_at = NULL,
     updated_at = EXCLUDED.updated_at
 `
 
@@ -110,14 +117,20 @@ type InsertProductVariantsBatchBatchResults struct {
 }
 
 type InsertProductVariantsBatchParams struct {
-	ID              id.ID[id.ProductVariant] `json:"id"`
-	ProductID       id.ID[id.Product]        `json:"product_id"`
-	ExternalID      pgtype.Text              `json:"external_id"`
-	Sku             pgtype.Text              `json:"sku"`
-	Price           pgtype.Numeric           `json:"price"`
-	InventoryItemID pgtype.Text              `json:"inventory_item_id"`
-	CreatedAt       pgtype.Timestamp         `json:"created_at"`
-	UpdatedAt       pgtype.Timestamp         `json:"updated_at"`
+	ID              id.ID[id.ProductVariant]      `json:"id"`
+	ProductID       id.ID[id.Product]             `json:"product_id"`
+	IntegrationID   id.ID[id.PlatformIntegration] `json:"integration_id"`
+	ExternalID      pgtype.Text                   `json:"external_id"`
+	Sku             pgtype.Text                   `json:"sku"`
+	Price           pgtype.Numeric                `json:"price"`
+	InventoryItemID pgtype.Text                   `json:"inventory_item_id"`
+	Barcode         pgtype.Text                   `json:"barcode"`
+	OptionValues    []string                      `json:"option_values"`
+	CompareAtPrice  pgtype.Numeric                `json:"compare_at_price"`
+	Weight          pgtype.Numeric                `json:"weight"`
+	WeightUnit      pgtype.Text                   `json:"weight_unit"`
+	CreatedAt       pgtype.Timestamp              `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp              `json:"updated_at"`
 }
 
 func (q *Queries) InsertProductVariantsBatch(ctx context.Context, arg []InsertProductVariantsBatchParams) *InsertProductVariantsBatchBatchResults {
@@ -126,10 +139,16 @@ func (q *Queries) InsertProductVariantsBatch(ctx context.Context, arg []InsertPr
 		vals := []interface{}{
 			a.ID,
 			a.ProductID,
+			a.IntegrationID,
 			a.ExternalID,
 			a.Sku,
 			a.Price,
 			a.InventoryItemID,
+			a.Barcode,
+			a.OptionValues,
+			a.CompareAtPrice,
+			a.Weight,
+			a.WeightUnit,
 			a.CreatedAt,
 			a.UpdatedAt,
 		}
@@ -161,14 +180,19 @@ func (b *InsertProductVariantsBatchBatchResults) Close() error {
 }
 
 const insertProductsBatch = `-- name: InsertProductsBatch :batchexec
-INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at) 
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, vendor, tags, option_names, published_at, created_at, updated_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 ON CONFLICT (integration_id, handle)
 DO UPDATE SET
     external_id = EXCLUDED.external_id,
     title = EXCLUDED.title,
     product_type = EXCLUDED.product_type,
     status = EXCLUDED.status,
+    vendor = EXCLUDED.vendor,
+    tags = EXCLUDED.tags,
+    option_names = EXCLUDED.option_names,
+    published_at = EXCLUDED.published_at,
+    deleted_at = NULL,
     updated_at = EXCLUDED.updated_at
 `
 
@@ -186,6 +210,10 @@ type InsertProductsBatchParams struct {
 	Handle        string                        `json:"handle"`
 	ProductType   pgtype.Text                   `json:"product_type"`
 	Status        ProductStatus                 `json:"status"`
+	Vendor        pgtype.Text                   `json:"vendor"`
+	Tags          []string                      `json:"tags"`
+	OptionNames   []string                      `json:"option_names"`
+	PublishedAt   pgtype.Timestamp              `json:"published_at"`
 	CreatedAt     pgtype.Timestamp              `json:"created_at"`
 	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
 }
@@ -201,6 +229,10 @@ func (q *Queries) InsertProductsBatch(ctx context.Context, arg []InsertProductsB
 			a.Handle,
 			a.ProductType,
 			a.Status,
+			a.Vendor,
+			a.Tags,
+			a.OptionNames,
+			a.PublishedAt,
 			a.CreatedAt,
 			a.UpdatedAt,
 		}
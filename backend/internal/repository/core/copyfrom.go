@@ -76,6 +76,8 @@ func (r iteratorForInsertOrdersBatch) Values() ([]interface{}, error) {
 		r.rows[0].FulfillmentStatus,
 		r.rows[0].TotalPrice,
 		r.rows[0].CancelledAt,
+		r.rows[0].PresentmentCurrency,
+		r.rows[0].PresentmentTotalPrice,
 	}, nil
 }
 
@@ -84,5 +86,5 @@ func (r iteratorForInsertOrdersBatch) Err() error {
 }
 
 func (q *Queries) InsertOrdersBatch(ctx context.Context, arg []InsertOrdersBatchParams) (int64, error) {
-	return q.db.CopyFrom(ctx, []string{"orders"}, []string{"id", "integration_id", "external_id", "created_at", "financial_status", "fulfillment_status", "total_price", "cancelled_at"}, &iteratorForInsertOrdersBatch{rows: arg})
+	return q.db.CopyFrom(ctx, []string{"orders"}, []string{"id", "integration_id", "external_id", "created_at", "financial_status", "fulfillment_status", "total_price", "cancelled_at", "presentment_currency", "presentment_total_price"}, &iteratorForInsertOrdersBatch{rows: arg})
 }
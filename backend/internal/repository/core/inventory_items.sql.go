@@ -15,7 +15,7 @@ import (
 const createInventoryItem = `-- name: CreateInventoryItem :one
 INSERT INTO inventory_items (id, integration_id, external_id, sku, tracked, cost, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-RETURNING id, integration_id, external_id, sku, tracked, cost, created_at, updated_at
+RETURNING id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at
 `
 
 type CreateInventoryItemParams struct {
@@ -46,14 +46,15 @@ func (q *Queries) CreateInventoryItem(ctx context.Context, arg CreateInventoryIt
 		&i.Cost,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getInventoryItemByExternalID = `-- name: GetInventoryItemByExternalID :one
-SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at
+SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at
 FROM inventory_items
-WHERE integration_id = $1 AND external_id = $2
+WHERE integration_id = $1 AND external_id = $2 AND deleted_at IS NULL
 `
 
 type GetInventoryItemByExternalIDParams struct {
@@ -73,14 +74,15 @@ func (q *Queries) GetInventoryItemByExternalID(ctx context.Context, arg GetInven
 		&i.Cost,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getInventoryItemByID = `-- name: GetInventoryItemByID :one
-SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at
+SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at
 FROM inventory_items
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetInventoryItemByID(ctx context.Context, argID id.ID[id.InventoryItem]) (InventoryItem, error) {
@@ -95,14 +97,15 @@ func (q *Queries) GetInventoryItemByID(ctx context.Context, argID id.ID[id.Inven
 		&i.Cost,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getInventoryItemsByIntegrationID = `-- name: GetInventoryItemsByIntegrationID :many
-SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at
+SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at
 FROM inventory_items
-WHERE integration_id = $1
+WHERE integration_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -131,6 +134,7 @@ func (q *Queries) GetInventoryItemsByIntegrationID(ctx context.Context, arg GetI
 			&i.Cost,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -153,16 +157,36 @@ type InsertInventoryItemsBatchParams struct {
 	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
 }
 
+const softDeleteInventoryItemsNotSyncedSince = `-- name: SoftDeleteInventoryItemsNotSyncedSince :execrows
+UPDATE inventory_items
+SET deleted_at = NOW()
+WHERE integration_id = $1 AND updated_at < $2 AND deleted_at IS NULL
+`
+
+type SoftDeleteInventoryItemsNotSyncedSinceParams struct {
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+}
+
+func (q *Queries) SoftDeleteInventoryItemsNotSyncedSince(ctx context.Context, arg SoftDeleteInventoryItemsNotSyncedSinceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteInventoryItemsNotSyncedSince, arg.IntegrationID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const upsertInventoryItem = `-- name: UpsertInventoryItem :one
 INSERT INTO inventory_items (id, integration_id, external_id, sku, tracked, cost, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-ON CONFLICT (external_id)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     sku = EXCLUDED.sku,
     tracked = EXCLUDED.tracked,
     cost = EXCLUDED.cost,
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING id, integration_id, external_id, sku, tracked, cost, created_at, updated_at
+RETURNING id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at
 `
 
 type UpsertInventoryItemParams struct {
@@ -193,6 +217,7 @@ func (q *Queries) UpsertInventoryItem(ctx context.Context, arg UpsertInventoryIt
 		&i.Cost,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
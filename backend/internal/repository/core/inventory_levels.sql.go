@@ -62,6 +62,41 @@ func (q *Queries) GetInventoryLevelByID(ctx context.Context, argID id.ID[id.Inve
 	return i, err
 }
 
+const getInventoryLevelsByIntegrationID = `-- name: GetInventoryLevelsByIntegrationID :many
+SELECT ii.external_id AS inventory_item_external_id, l.external_id AS location_external_id, il.available
+FROM inventory_levels il
+JOIN inventory_items ii ON ii.id = il.inventory_item_id AND ii.deleted_at IS NULL
+JOIN locations l ON l.id = il.location_id AND l.deleted_at IS NULL
+WHERE ii.integration_id = $1
+`
+
+type GetInventoryLevelsByIntegrationIDRow struct {
+	InventoryItemExternalID pgtype.Text `json:"inventory_item_external_id"`
+	LocationExternalID      pgtype.Text `json:"location_external_id"`
+	Available               pgtype.Int4 `json:"available"`
+}
+
+// Levels of the integration's live items at its live locations, by the external IDs of both
+func (q *Queries) GetInventoryLevelsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]GetInventoryLevelsByIntegrationIDRow, error) {
+	rows, err := q.db.Query(ctx, getInventoryLevelsByIntegrationID, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetInventoryLevelsByIntegrationIDRow{}
+	for rows.Next() {
+		var i GetInventoryLevelsByIntegrationIDRow
+		if err := rows.Scan(&i.InventoryItemExternalID, &i.LocationExternalID, &i.Available); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getInventoryLevelsByInventoryItemID = `-- name: GetInventoryLevelsByInventoryItemID :many
 SELECT id, inventory_item_id, location_id, available, updated_at
 FROM inventory_levels
@@ -15,7 +15,7 @@ import (
 const createLocation = `-- name: CreateLocation :one
 INSERT INTO locations (id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
-RETURNING id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at
+RETURNING id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
 `
 
 type CreateLocationParams struct {
@@ -52,14 +52,55 @@ func (q *Queries) CreateLocation(ctx context.Context, arg CreateLocationParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const getAllLocationsByIntegrationID = `-- name: GetAllLocationsByIntegrationID :many
+SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
+FROM locations
+WHERE integration_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+// Live locations of the integration whether active or not, which is what a full sync keeps up to date
+func (q *Queries) GetAllLocationsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]Location, error) {
+	rows, err := q.db.Query(ctx, getAllLocationsByIntegrationID, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Location{}
+	for rows.Next() {
+		var i Location
+		if err := rows.Scan(
+			&i.ID,
+			&i.IntegrationID,
+			&i.ExternalID,
+			&i.Name,
+			&i.Address,
+			&i.Country,
+			&i.Province,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getLocationByExternalID = `-- name: GetLocationByExternalID :one
-SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at
+SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
 FROM locations
-WHERE integration_id = $1 AND external_id = $2
+WHERE integration_id = $1 AND external_id = $2 AND deleted_at IS NULL
 `
 
 type GetLocationByExternalIDParams struct {
@@ -81,14 +122,15 @@ func (q *Queries) GetLocationByExternalID(ctx context.Context, arg GetLocationBy
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getLocationByID = `-- name: GetLocationByID :one
-SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at
+SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
 FROM locations
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetLocationByID(ctx context.Context, argID id.ID[id.Location]) (Location, error) {
@@ -105,14 +147,15 @@ func (q *Queries) GetLocationByID(ctx context.Context, argID id.ID[id.Location])
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getLocationsByIntegrationID = `-- name: GetLocationsByIntegrationID :many
-SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at
+SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
 FROM locations
-WHERE integration_id = $1 AND is_active = true
+WHERE integration_id = $1 AND is_active = true AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -143,6 +186,7 @@ func (q *Queries) GetLocationsByIntegrationID(ctx context.Context, arg GetLocati
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -154,18 +198,38 @@ func (q *Queries) GetLocationsByIntegrationID(ctx context.Context, arg GetLocati
 	return items, nil
 }
 
+const softDeleteLocationsNotSyncedSince = `-- name: SoftDeleteLocationsNotSyncedSince :execrows
+UPDATE locations
+SET deleted_at = NOW()
+WHERE integration_id = $1 AND updated_at < $2 AND deleted_at IS NULL
+`
+
+type SoftDeleteLocationsNotSyncedSinceParams struct {
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+}
+
+func (q *Queries) SoftDeleteLocationsNotSyncedSince(ctx context.Context, arg SoftDeleteLocationsNotSyncedSinceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteLocationsNotSyncedSince, arg.IntegrationID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const upsertLocation = `-- name: UpsertLocation :one
 INSERT INTO locations (id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
-ON CONFLICT (external_id)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     name = EXCLUDED.name,
     address = EXCLUDED.address,
     country = EXCLUDED.country,
     province = EXCLUDED.province,
     is_active = EXCLUDED.is_active,
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at
+RETURNING id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
 `
 
 type UpsertLocationParams struct {
@@ -202,6 +266,7 @@ func (q *Queries) UpsertLocation(ctx context.Context, arg UpsertLocationParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -8,6 +8,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 
+	"github.com/ConradKurth/forecasting/backend/internal/crypto"
 	"github.com/ConradKurth/forecasting/backend/pkg/id"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -15,11 +16,12 @@ import (
 type EntityType string
 
 const (
-	EntityTypeProducts  EntityType = "products"
-	EntityTypeOrders    EntityType = "orders"
-	EntityTypeInventory EntityType = "inventory"
-	EntityTypeLocations EntityType = "locations"
-	EntityTypeFullSync  EntityType = "full_sync"
+	EntityTypeProducts        EntityType = "products"
+	EntityTypeOrders          EntityType = "orders"
+	EntityTypeInventory       EntityType = "inventory"
+	EntityTypeLocations       EntityType = "locations"
+	EntityTypeFullSync        EntityType = "full_sync"
+	EntityTypeInventoryLevels EntityType = "inventory_levels"
 )
 
 func (e *EntityType) Scan(src interface{}) error {
@@ -148,6 +150,93 @@ func (ns NullFulfillmentStatus) Value() (driver.Value, error) {
 	return string(ns.FulfillmentStatus), nil
 }
 
+type MasterSkuLinkStatus string
+
+const (
+	MasterSkuLinkStatusSuggested MasterSkuLinkStatus = "suggested"
+	MasterSkuLinkStatusConfirmed MasterSkuLinkStatus = "confirmed"
+	MasterSkuLinkStatusRejected  MasterSkuLinkStatus = "rejected"
+)
+
+func (e *MasterSkuLinkStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MasterSkuLinkStatus(s)
+	case string:
+		*e = MasterSkuLinkStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MasterSkuLinkStatus: %T", src)
+	}
+	return nil
+}
+
+type NullMasterSkuLinkStatus struct {
+	MasterSkuLinkStatus MasterSkuLinkStatus `json:"master_sku_link_status"`
+	Valid               bool                `json:"valid"` // Valid is true if MasterSkuLinkStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMasterSkuLinkStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.MasterSkuLinkStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MasterSkuLinkStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMasterSkuLinkStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MasterSkuLinkStatus), nil
+}
+
+type MasterSkuMatchType string
+
+const (
+	MasterSkuMatchTypeExact      MasterSkuMatchType = "exact"
+	MasterSkuMatchTypeNormalized MasterSkuMatchType = "normalized"
+	MasterSkuMatchTypeBarcode    MasterSkuMatchType = "barcode"
+	MasterSkuMatchTypeManual     MasterSkuMatchType = "manual"
+)
+
+func (e *MasterSkuMatchType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MasterSkuMatchType(s)
+	case string:
+		*e = MasterSkuMatchType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MasterSkuMatchType: %T", src)
+	}
+	return nil
+}
+
+type NullMasterSkuMatchType struct {
+	MasterSkuMatchType MasterSkuMatchType `json:"master_sku_match_type"`
+	Valid              bool               `json:"valid"` // Valid is true if MasterSkuMatchType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMasterSkuMatchType) Scan(value interface{}) error {
+	if value == nil {
+		ns.MasterSkuMatchType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MasterSkuMatchType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMasterSkuMatchType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MasterSkuMatchType), nil
+}
+
 type PlatformType string
 
 const (
@@ -156,6 +245,7 @@ const (
 	PlatformTypeBigcommerce PlatformType = "bigcommerce"
 	PlatformTypeSquare      PlatformType = "square"
 	PlatformTypeMagento     PlatformType = "magento"
+	PlatformTypeFileImport  PlatformType = "file_import"
 )
 
 func (e *PlatformType) Scan(src interface{}) error {
@@ -243,6 +333,7 @@ const (
 	SyncStatusInProgress SyncStatus = "in_progress"
 	SyncStatusCompleted  SyncStatus = "completed"
 	SyncStatusFailed     SyncStatus = "failed"
+	SyncStatusCancelled  SyncStatus = "cancelled"
 )
 
 func (e *SyncStatus) Scan(src interface{}) error {
@@ -280,6 +371,71 @@ func (ns NullSyncStatus) Value() (driver.Value, error) {
 	return string(ns.SyncStatus), nil
 }
 
+type SyncTrigger string
+
+const (
+	SyncTriggerOauth    SyncTrigger = "oauth"
+	SyncTriggerManual   SyncTrigger = "manual"
+	SyncTriggerSchedule SyncTrigger = "schedule"
+	SyncTriggerWebhook  SyncTrigger = "webhook"
+)
+
+func (e *SyncTrigger) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SyncTrigger(s)
+	case string:
+		*e = SyncTrigger(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SyncTrigger: %T", src)
+	}
+	return nil
+}
+
+type NullSyncTrigger struct {
+	SyncTrigger SyncTrigger `json:"sync_trigger"`
+	Valid       bool        `json:"valid"` // Valid is true if SyncTrigger is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSyncTrigger) Scan(value interface{}) error {
+	if value == nil {
+		ns.SyncTrigger, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SyncTrigger.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSyncTrigger) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SyncTrigger), nil
+}
+
+type BigcommerceInstallation struct {
+	ID             id.ID[id.BigCommerceInstallation] `json:"id"`
+	StoreHash      string                            `json:"store_hash"`
+	AccessToken    crypto.EncryptedSecret            `json:"access_token"`
+	Scope          string                            `json:"scope"`
+	ClaimTokenHash pgtype.Text                       `json:"claim_token_hash"`
+	ClaimExpiresAt pgtype.Timestamp                  `json:"claim_expires_at"`
+	CreatedAt      pgtype.Timestamp                  `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp                  `json:"updated_at"`
+}
+
+type ExchangeRate struct {
+	BaseCurrency  string           `json:"base_currency"`
+	QuoteCurrency string           `json:"quote_currency"`
+	RateDate      pgtype.Date      `json:"rate_date"`
+	Rate          pgtype.Numeric   `json:"rate"`
+	Source        string           `json:"source"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
 type InventoryItem struct {
 	ID            id.ID[id.InventoryItem]       `json:"id"`
 	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
@@ -289,6 +445,7 @@ type InventoryItem struct {
 	Cost          pgtype.Numeric                `json:"cost"`
 	CreatedAt     pgtype.Timestamp              `json:"created_at"`
 	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+	DeletedAt     pgtype.Timestamp              `json:"deleted_at"`
 }
 
 type InventoryLevel struct {
@@ -310,17 +467,68 @@ type Location struct {
 	IsActive      pgtype.Bool                   `json:"is_active"`
 	CreatedAt     pgtype.Timestamp              `json:"created_at"`
 	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+	DeletedAt     pgtype.Timestamp              `json:"deleted_at"`
+}
+
+type MagentoCredential struct {
+	ID            id.ID[id.MagentoCredential]   `json:"id"`
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	StoreUrl      string                        `json:"store_url"`
+	AccessToken   crypto.EncryptedSecret        `json:"access_token"`
+	CreatedAt     pgtype.Timestamp              `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+}
+
+type MasterSku struct {
+	ID        id.ID[id.MasterSKU]       `json:"id"`
+	AccountID id.ID[id.MerchantAccount] `json:"account_id"`
+	Sku       string                    `json:"sku"`
+	Title     string                    `json:"title"`
+	Barcode   pgtype.Text               `json:"barcode"`
+	CreatedAt pgtype.Timestamp          `json:"created_at"`
+	UpdatedAt pgtype.Timestamp          `json:"updated_at"`
+}
+
+type MasterSkuLink struct {
+	VariantID   id.ID[id.ProductVariant] `json:"variant_id"`
+	MasterSkuID id.ID[id.MasterSKU]      `json:"master_sku_id"`
+	MatchType   MasterSkuMatchType       `json:"match_type"`
+	Status      MasterSkuLinkStatus      `json:"status"`
+	CreatedAt   pgtype.Timestamp         `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp         `json:"updated_at"`
+}
+
+type MerchantAccount struct {
+	ID                id.ID[id.MerchantAccount] `json:"id"`
+	Name              string                    `json:"name"`
+	CreatedAt         pgtype.Timestamp          `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp          `json:"updated_at"`
+	ReportingCurrency string                    `json:"reporting_currency"`
+}
+
+type MerchantAccountShop struct {
+	ShopID    id.ID[id.ShopifyStore]    `json:"shop_id"`
+	AccountID id.ID[id.MerchantAccount] `json:"account_id"`
+	CreatedAt pgtype.Timestamp          `json:"created_at"`
+}
+
+type MerchantAccountUser struct {
+	AccountID id.ID[id.MerchantAccount] `json:"account_id"`
+	UserID    id.ID[id.User]            `json:"user_id"`
+	CreatedAt pgtype.Timestamp          `json:"created_at"`
 }
 
 type Order struct {
-	ID                id.ID[id.Order]               `json:"id"`
-	IntegrationID     id.ID[id.PlatformIntegration] `json:"integration_id"`
-	ExternalID        pgtype.Text                   `json:"external_id"`
-	CreatedAt         pgtype.Timestamp              `json:"created_at"`
-	FinancialStatus   FinancialStatus               `json:"financial_status"`
-	FulfillmentStatus FulfillmentStatus             `json:"fulfillment_status"`
-	TotalPrice        pgtype.Numeric                `json:"total_price"`
-	CancelledAt       pgtype.Timestamp              `json:"cancelled_at"`
+	ID                    id.ID[id.Order]               `json:"id"`
+	IntegrationID         id.ID[id.PlatformIntegration] `json:"integration_id"`
+	ExternalID            pgtype.Text                   `json:"external_id"`
+	CreatedAt             pgtype.Timestamp              `json:"created_at"`
+	FinancialStatus       FinancialStatus               `json:"financial_status"`
+	FulfillmentStatus     FulfillmentStatus             `json:"fulfillment_status"`
+	TotalPrice            pgtype.Numeric                `json:"total_price"`
+	CancelledAt           pgtype.Timestamp              `json:"cancelled_at"`
+	PresentmentCurrency   pgtype.Text                   `json:"presentment_currency"`
+	PresentmentTotalPrice pgtype.Numeric                `json:"presentment_total_price"`
 }
 
 type OrderLineItem struct {
@@ -335,13 +543,16 @@ type OrderLineItem struct {
 }
 
 type PlatformIntegration struct {
-	ID             id.ID[id.PlatformIntegration] `json:"id"`
-	ShopID         id.ID[id.ShopifyStore]        `json:"shop_id"`
-	PlatformType   PlatformType                  `json:"platform_type"`
-	PlatformShopID string                        `json:"platform_shop_id"`
-	IsActive       pgtype.Bool                   `json:"is_active"`
-	CreatedAt      pgtype.Timestamp              `json:"created_at"`
-	UpdatedAt      pgtype.Timestamp              `json:"updated_at"`
+	ID                  id.ID[id.PlatformIntegration] `json:"id"`
+	ShopID              id.ID[id.ShopifyStore]        `json:"shop_id"`
+	PlatformType        PlatformType                  `json:"platform_type"`
+	PlatformShopID      string                        `json:"platform_shop_id"`
+	IsActive            pgtype.Bool                   `json:"is_active"`
+	CreatedAt           pgtype.Timestamp              `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp              `json:"updated_at"`
+	SyncIntervalMinutes int32                         `json:"sync_interval_minutes"`
+	Currency            pgtype.Text                   `json:"currency"`
+	AccountID           id.ID[id.MerchantAccount]     `json:"account_id"`
 }
 
 type Product struct {
@@ -354,27 +565,39 @@ type Product struct {
 	Status        ProductStatus                 `json:"status"`
 	CreatedAt     pgtype.Timestamp              `json:"created_at"`
 	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+	DeletedAt     pgtype.Timestamp              `json:"deleted_at"`
+	Vendor        pgtype.Text                   `json:"vendor"`
+	Tags          []string                      `json:"tags"`
+	OptionNames   []string                      `json:"option_names"`
+	PublishedAt   pgtype.Timestamp              `json:"published_at"`
 }
 
 type ProductVariant struct {
-	ID              id.ID[id.ProductVariant] `json:"id"`
-	ProductID       id.ID[id.Product]        `json:"product_id"`
-	ExternalID      pgtype.Text              `json:"external_id"`
-	Sku             pgtype.Text              `json:"sku"`
-	Price           pgtype.Numeric           `json:"price"`
-	InventoryItemID pgtype.Text              `json:"inventory_item_id"`
-	CreatedAt       pgtype.Timestamp         `json:"created_at"`
-	UpdatedAt       pgtype.Timestamp         `json:"updated_at"`
+	ID              id.ID[id.ProductVariant]      `json:"id"`
+	ProductID       id.ID[id.Product]             `json:"product_id"`
+	ExternalID      pgtype.Text                   `json:"external_id"`
+	Sku             pgtype.Text                   `json:"sku"`
+	Price           pgtype.Numeric                `json:"price"`
+	InventoryItemID pgtype.Text                   `json:"inventory_item_id"`
+	CreatedAt       pgtype.Timestamp              `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp              `json:"updated_at"`
+	DeletedAt       pgtype.Timestamp              `json:"deleted_at"`
+	Barcode         pgtype.Text                   `json:"barcode"`
+	OptionValues    []string                      `json:"option_values"`
+	CompareAtPrice  pgtype.Numeric                `json:"compare_at_price"`
+	Weight          pgtype.Numeric                `json:"weight"`
+	WeightUnit      pgtype.Text                   `json:"weight_unit"`
+	IntegrationID   id.ID[id.PlatformIntegration] `json:"integration_id"`
 }
 
 type ShopifyStore struct {
-	ID         string           `json:"id"`
-	ShopDomain string           `json:"shop_domain"`
-	ShopName   pgtype.Text      `json:"shop_name"`
-	Timezone   pgtype.Text      `json:"timezone"`
-	Currency   pgtype.Text      `json:"currency"`
-	CreatedAt  pgtype.Timestamp `json:"created_at"`
-	UpdatedAt  pgtype.Timestamp `json:"updated_at"`
+	ID         id.ID[id.ShopifyStore] `json:"id"`
+	ShopDomain string                 `json:"shop_domain"`
+	ShopName   pgtype.Text            `json:"shop_name"`
+	Timezone   pgtype.Text            `json:"timezone"`
+	Currency   pgtype.Text            `json:"currency"`
+	CreatedAt  pgtype.Timestamp       `json:"created_at"`
+	UpdatedAt  pgtype.Timestamp       `json:"updated_at"`
 }
 
 type ShopifyUser struct {
@@ -388,6 +611,43 @@ type ShopifyUser struct {
 	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
 }
 
+type SquareCredential struct {
+	ID            id.ID[id.SquareCredential]    `json:"id"`
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	AccessToken   crypto.EncryptedSecret        `json:"access_token"`
+	CreatedAt     pgtype.Timestamp              `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+}
+
+type SyncCheckpoint struct {
+	ID             id.ID[id.SyncCheckpoint]      `json:"id"`
+	IntegrationID  id.ID[id.PlatformIntegration] `json:"integration_id"`
+	EntityType     EntityType                    `json:"entity_type"`
+	PageInfo       pgtype.Text                   `json:"page_info"`
+	PagesProcessed int32                         `json:"pages_processed"`
+	Stats          []byte                        `json:"stats"`
+	Completed      bool                          `json:"completed"`
+	CreatedAt      pgtype.Timestamp              `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp              `json:"updated_at"`
+}
+
+type SyncRun struct {
+	ID                id.ID[id.SyncRun]             `json:"id"`
+	IntegrationID     id.ID[id.PlatformIntegration] `json:"integration_id"`
+	EntityType        EntityType                    `json:"entity_type"`
+	Trigger           SyncTrigger                   `json:"trigger"`
+	SyncStatus        SyncStatus                    `json:"sync_status"`
+	StartedAt         pgtype.Timestamp              `json:"started_at"`
+	FinishedAt        pgtype.Timestamp              `json:"finished_at"`
+	PhaseTimings      []byte                        `json:"phase_timings"`
+	Stats             []byte                        `json:"stats"`
+	ErrorMessage      pgtype.Text                   `json:"error_message"`
+	TaskID            pgtype.Text                   `json:"task_id"`
+	CancelRequestedAt pgtype.Timestamp              `json:"cancel_requested_at"`
+	DryRun            bool                          `json:"dry_run"`
+	Diff              []byte                        `json:"diff"`
+}
+
 type SyncState struct {
 	ID            id.ID[id.SyncState]           `json:"id"`
 	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
@@ -395,6 +655,7 @@ type SyncState struct {
 	LastSyncedAt  pgtype.Timestamp              `json:"last_synced_at"`
 	SyncStatus    SyncStatus                    `json:"sync_status"`
 	ErrorMessage  pgtype.Text                   `json:"error_message"`
+	HeartbeatAt   pgtype.Timestamp              `json:"heartbeat_at"`
 }
 
 type User struct {
@@ -402,3 +663,13 @@ type User struct {
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 }
+
+type WoocommerceCredential struct {
+	ID             id.ID[id.WooCommerceCredential] `json:"id"`
+	IntegrationID  id.ID[id.PlatformIntegration]   `json:"integration_id"`
+	StoreUrl       string                          `json:"store_url"`
+	ConsumerKey    crypto.EncryptedSecret          `json:"consumer_key"`
+	ConsumerSecret crypto.EncryptedSecret          `json:"consumer_secret"`
+	CreatedAt      pgtype.Timestamp                `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp                `json:"updated_at"`
+}
@@ -13,20 +13,22 @@ import (
 )
 
 const createOrder = `-- name: CreateOrder :one
-INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 `
 
 type CreateOrderParams struct {
-	ID                id.ID[id.Order]               `json:"id"`
-	IntegrationID     id.ID[id.PlatformIntegration] `json:"integration_id"`
-	ExternalID        pgtype.Text                   `json:"external_id"`
-	CreatedAt         pgtype.Timestamp              `json:"created_at"`
-	FinancialStatus   FinancialStatus               `json:"financial_status"`
-	FulfillmentStatus FulfillmentStatus             `json:"fulfillment_status"`
-	TotalPrice        pgtype.Numeric                `json:"total_price"`
-	CancelledAt       pgtype.Timestamp              `json:"cancelled_at"`
+	ID                    id.ID[id.Order]               `json:"id"`
+	IntegrationID         id.ID[id.PlatformIntegration] `json:"integration_id"`
+	ExternalID            pgtype.Text                   `json:"external_id"`
+	CreatedAt             pgtype.Timestamp              `json:"created_at"`
+	FinancialStatus       FinancialStatus               `json:"financial_status"`
+	FulfillmentStatus     FulfillmentStatus             `json:"fulfillment_status"`
+	TotalPrice            pgtype.Numeric                `json:"total_price"`
+	CancelledAt           pgtype.Timestamp              `json:"cancelled_at"`
+	PresentmentCurrency   pgtype.Text                   `json:"presentment_currency"`
+	PresentmentTotalPrice pgtype.Numeric                `json:"presentment_total_price"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
@@ -39,6 +41,8 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		arg.FulfillmentStatus,
 		arg.TotalPrice,
 		arg.CancelledAt,
+		arg.PresentmentCurrency,
+		arg.PresentmentTotalPrice,
 	)
 	var i Order
 	err := row.Scan(
@@ -50,6 +54,8 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.FulfillmentStatus,
 		&i.TotalPrice,
 		&i.CancelledAt,
+		&i.PresentmentCurrency,
+		&i.PresentmentTotalPrice,
 	)
 	return i, err
 }
@@ -69,7 +75,7 @@ func (q *Queries) DeleteOrder(ctx context.Context, arg DeleteOrderParams) error
 }
 
 const getOrderByExternalID = `-- name: GetOrderByExternalID :one
-SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 FROM orders
 WHERE integration_id = $1 AND external_id = $2
 `
@@ -91,12 +97,14 @@ func (q *Queries) GetOrderByExternalID(ctx context.Context, arg GetOrderByExtern
 		&i.FulfillmentStatus,
 		&i.TotalPrice,
 		&i.CancelledAt,
+		&i.PresentmentCurrency,
+		&i.PresentmentTotalPrice,
 	)
 	return i, err
 }
 
 const getOrderByID = `-- name: GetOrderByID :one
-SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 FROM orders
 WHERE id = $1
 `
@@ -113,12 +121,14 @@ func (q *Queries) GetOrderByID(ctx context.Context, argID id.ID[id.Order]) (Orde
 		&i.FulfillmentStatus,
 		&i.TotalPrice,
 		&i.CancelledAt,
+		&i.PresentmentCurrency,
+		&i.PresentmentTotalPrice,
 	)
 	return i, err
 }
 
 const getOrdersByIntegrationID = `-- name: GetOrdersByIntegrationID :many
-SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 FROM orders
 WHERE integration_id = $1
 ORDER BY created_at DESC
@@ -149,6 +159,8 @@ func (q *Queries) GetOrdersByIntegrationID(ctx context.Context, arg GetOrdersByI
 			&i.FulfillmentStatus,
 			&i.TotalPrice,
 			&i.CancelledAt,
+			&i.PresentmentCurrency,
+			&i.PresentmentTotalPrice,
 		); err != nil {
 			return nil, err
 		}
@@ -161,7 +173,7 @@ func (q *Queries) GetOrdersByIntegrationID(ctx context.Context, arg GetOrdersByI
 }
 
 const getOrdersByIntegrationIDSince = `-- name: GetOrdersByIntegrationIDSince :many
-SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 FROM orders
 WHERE integration_id = $1 AND created_at >= $2
 ORDER BY created_at DESC
@@ -198,6 +210,8 @@ func (q *Queries) GetOrdersByIntegrationIDSince(ctx context.Context, arg GetOrde
 			&i.FulfillmentStatus,
 			&i.TotalPrice,
 			&i.CancelledAt,
+			&i.PresentmentCurrency,
+			&i.PresentmentTotalPrice,
 		); err != nil {
 			return nil, err
 		}
@@ -210,30 +224,34 @@ func (q *Queries) GetOrdersByIntegrationIDSince(ctx context.Context, arg GetOrde
 }
 
 type InsertOrdersBatchParams struct {
-	ID                id.ID[id.Order]               `json:"id"`
-	IntegrationID     id.ID[id.PlatformIntegration] `json:"integration_id"`
-	ExternalID        pgtype.Text                   `json:"external_id"`
-	CreatedAt         pgtype.Timestamp              `json:"created_at"`
-	FinancialStatus   FinancialStatus               `json:"financial_status"`
-	FulfillmentStatus FulfillmentStatus             `json:"fulfillment_status"`
-	TotalPrice        pgtype.Numeric                `json:"total_price"`
-	CancelledAt       pgtype.Timestamp              `json:"cancelled_at"`
+	ID                    id.ID[id.Order]               `json:"id"`
+	IntegrationID         id.ID[id.PlatformIntegration] `json:"integration_id"`
+	ExternalID            pgtype.Text                   `json:"external_id"`
+	CreatedAt             pgtype.Timestamp              `json:"created_at"`
+	FinancialStatus       FinancialStatus               `json:"financial_status"`
+	FulfillmentStatus     FulfillmentStatus             `json:"fulfillment_status"`
+	TotalPrice            pgtype.Numeric                `json:"total_price"`
+	CancelledAt           pgtype.Timestamp              `json:"cancelled_at"`
+	PresentmentCurrency   pgtype.Text                   `json:"presentment_currency"`
+	PresentmentTotalPrice pgtype.Numeric                `json:"presentment_total_price"`
 }
 
 const updateOrder = `-- name: UpdateOrder :one
 UPDATE orders
-SET financial_status = $3, fulfillment_status = $4, total_price = $5, cancelled_at = $6
+SET financial_status = $3, fulfillment_status = $4, total_price = $5, cancelled_at = $6, presentment_currency = $7, presentment_total_price = $8
 WHERE id = $1 AND integration_id = $2
-RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 `
 
 type UpdateOrderParams struct {
-	ID                id.ID[id.Order]               `json:"id"`
-	IntegrationID     id.ID[id.PlatformIntegration] `json:"integration_id"`
-	FinancialStatus   FinancialStatus               `json:"financial_status"`
-	FulfillmentStatus FulfillmentStatus             `json:"fulfillment_status"`
-	TotalPrice        pgtype.Numeric                `json:"total_price"`
-	CancelledAt       pgtype.Timestamp              `json:"cancelled_at"`
+	ID                    id.ID[id.Order]               `json:"id"`
+	IntegrationID         id.ID[id.PlatformIntegration] `json:"integration_id"`
+	FinancialStatus       FinancialStatus               `json:"financial_status"`
+	FulfillmentStatus     FulfillmentStatus             `json:"fulfillment_status"`
+	TotalPrice            pgtype.Numeric                `json:"total_price"`
+	CancelledAt           pgtype.Timestamp              `json:"cancelled_at"`
+	PresentmentCurrency   pgtype.Text                   `json:"presentment_currency"`
+	PresentmentTotalPrice pgtype.Numeric                `json:"presentment_total_price"`
 }
 
 func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order, error) {
@@ -244,6 +262,8 @@ func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order
 		arg.FulfillmentStatus,
 		arg.TotalPrice,
 		arg.CancelledAt,
+		arg.PresentmentCurrency,
+		arg.PresentmentTotalPrice,
 	)
 	var i Order
 	err := row.Scan(
@@ -255,31 +275,37 @@ func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order
 		&i.FulfillmentStatus,
 		&i.TotalPrice,
 		&i.CancelledAt,
+		&i.PresentmentCurrency,
+		&i.PresentmentTotalPrice,
 	)
 	return i, err
 }
 
 const upsertOrder = `-- name: UpsertOrder :one
-INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-ON CONFLICT (external_id)
+INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     financial_status = EXCLUDED.financial_status,
     fulfillment_status = EXCLUDED.fulfillment_status,
     total_price = EXCLUDED.total_price,
-    cancelled_at = EXCLUDED.cancelled_at
-RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+    cancelled_at = EXCLUDED.cancelled_at,
+    presentment_currency = EXCLUDED.presentment_currency,
+    presentment_total_price = EXCLUDED.presentment_total_price
+RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 `
 
 type UpsertOrderParams struct {
-	ID                id.ID[id.Order]               `json:"id"`
-	IntegrationID     id.ID[id.PlatformIntegration] `json:"integration_id"`
-	ExternalID        pgtype.Text                   `json:"external_id"`
-	CreatedAt         pgtype.Timestamp              `json:"created_at"`
-	FinancialStatus   FinancialStatus               `json:"financial_status"`
-	FulfillmentStatus FulfillmentStatus             `json:"fulfillment_status"`
-	TotalPrice        pgtype.Numeric                `json:"total_price"`
-	CancelledAt       pgtype.Timestamp              `json:"cancelled_at"`
+	ID                    id.ID[id.Order]               `json:"id"`
+	IntegrationID         id.ID[id.PlatformIntegration] `json:"integration_id"`
+	ExternalID            pgtype.Text                   `json:"external_id"`
+	CreatedAt             pgtype.Timestamp              `json:"created_at"`
+	FinancialStatus       FinancialStatus               `json:"financial_status"`
+	FulfillmentStatus     FulfillmentStatus             `json:"fulfillment_status"`
+	TotalPrice            pgtype.Numeric                `json:"total_price"`
+	CancelledAt           pgtype.Timestamp              `json:"cancelled_at"`
+	PresentmentCurrency   pgtype.Text                   `json:"presentment_currency"`
+	PresentmentTotalPrice pgtype.Numeric                `json:"presentment_total_price"`
 }
 
 func (q *Queries) UpsertOrder(ctx context.Context, arg UpsertOrderParams) (Order, error) {
@@ -292,6 +318,8 @@ func (q *Queries) UpsertOrder(ctx context.Context, arg UpsertOrderParams) (Order
 		arg.FulfillmentStatus,
 		arg.TotalPrice,
 		arg.CancelledAt,
+		arg.PresentmentCurrency,
+		arg.PresentmentTotalPrice,
 	)
 	var i Order
 	err := row.Scan(
@@ -303,6 +331,8 @@ func (q *Queries) UpsertOrder(ctx context.Context, arg UpsertOrderParams) (Order
 		&i.FulfillmentStatus,
 		&i.TotalPrice,
 		&i.CancelledAt,
+		&i.PresentmentCurrency,
+		&i.PresentmentTotalPrice,
 	)
 	return i, err
 }
@@ -13,14 +13,15 @@ import (
 )
 
 const createPlatformIntegration = `-- name: CreatePlatformIntegration :one
-INSERT INTO platform_integrations (id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+INSERT INTO platform_integrations (id, shop_id, account_id, platform_type, platform_shop_id, is_active, created_at, updated_at)
+VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, NOW(), NOW())
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 `
 
 type CreatePlatformIntegrationParams struct {
 	ID             id.ID[id.PlatformIntegration] `json:"id"`
 	ShopID         id.ID[id.ShopifyStore]        `json:"shop_id"`
+	AccountID      id.ID[id.MerchantAccount]     `json:"account_id"`
 	PlatformType   PlatformType                  `json:"platform_type"`
 	PlatformShopID string                        `json:"platform_shop_id"`
 	IsActive       pgtype.Bool                   `json:"is_active"`
@@ -30,6 +31,7 @@ func (q *Queries) CreatePlatformIntegration(ctx context.Context, arg CreatePlatf
 	row := q.db.QueryRow(ctx, createPlatformIntegration,
 		arg.ID,
 		arg.ShopID,
+		arg.AccountID,
 		arg.PlatformType,
 		arg.PlatformShopID,
 		arg.IsActive,
@@ -43,6 +45,9 @@ func (q *Queries) CreatePlatformIntegration(ctx context.Context, arg CreatePlatf
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncIntervalMinutes,
+		&i.Currency,
+		&i.AccountID,
 	)
 	return i, err
 }
@@ -59,7 +64,7 @@ func (q *Queries) DeactivatePlatformIntegration(ctx context.Context, argID id.ID
 }
 
 const getPlatformIntegrationByID = `-- name: GetPlatformIntegrationByID :one
-SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 FROM platform_integrations
 WHERE id = $1
 `
@@ -75,12 +80,44 @@ func (q *Queries) GetPlatformIntegrationByID(ctx context.Context, argID id.ID[id
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncIntervalMinutes,
+		&i.Currency,
+		&i.AccountID,
+	)
+	return i, err
+}
+
+const getPlatformIntegrationByPlatformShop = `-- name: GetPlatformIntegrationByPlatformShop :one
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
+FROM platform_integrations
+WHERE platform_shop_id = $1 AND platform_type = $2
+`
+
+type GetPlatformIntegrationByPlatformShopParams struct {
+	PlatformShopID string       `json:"platform_shop_id"`
+	PlatformType   PlatformType `json:"platform_type"`
+}
+
+func (q *Queries) GetPlatformIntegrationByPlatformShop(ctx context.Context, arg GetPlatformIntegrationByPlatformShopParams) (PlatformIntegration, error) {
+	row := q.db.QueryRow(ctx, getPlatformIntegrationByPlatformShop, arg.PlatformShopID, arg.PlatformType)
+	var i PlatformIntegration
+	err := row.Scan(
+		&i.ID,
+		&i.ShopID,
+		&i.PlatformType,
+		&i.PlatformShopID,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SyncIntervalMinutes,
+		&i.Currency,
+		&i.AccountID,
 	)
 	return i, err
 }
 
 const getPlatformIntegrationByShopAndType = `-- name: GetPlatformIntegrationByShopAndType :one
-SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 FROM platform_integrations
 WHERE shop_id = $1 AND platform_type = $2 AND is_active = true
 `
@@ -101,12 +138,53 @@ func (q *Queries) GetPlatformIntegrationByShopAndType(ctx context.Context, arg G
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncIntervalMinutes,
+		&i.Currency,
+		&i.AccountID,
 	)
 	return i, err
 }
 
+const getPlatformIntegrationsByAccountID = `-- name: GetPlatformIntegrationsByAccountID :many
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
+FROM platform_integrations
+WHERE account_id = $1 AND is_active = true
+ORDER BY created_at
+`
+
+func (q *Queries) GetPlatformIntegrationsByAccountID(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]PlatformIntegration, error) {
+	rows, err := q.db.Query(ctx, getPlatformIntegrationsByAccountID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PlatformIntegration{}
+	for rows.Next() {
+		var i PlatformIntegration
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShopID,
+			&i.PlatformType,
+			&i.PlatformShopID,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SyncIntervalMinutes,
+			&i.Currency,
+			&i.AccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPlatformIntegrationsByShopID = `-- name: GetPlatformIntegrationsByShopID :many
-SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 FROM platform_integrations
 WHERE shop_id = $1 AND is_active = true
 ORDER BY created_at DESC
@@ -129,6 +207,9 @@ func (q *Queries) GetPlatformIntegrationsByShopID(ctx context.Context, shopID id
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SyncIntervalMinutes,
+			&i.Currency,
+			&i.AccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -140,11 +221,126 @@ func (q *Queries) GetPlatformIntegrationsByShopID(ctx context.Context, shopID id
 	return items, nil
 }
 
+const getScheduledPlatformIntegrations = `-- name: GetScheduledPlatformIntegrations :many
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
+FROM platform_integrations
+WHERE is_active = true AND sync_interval_minutes > 0 AND platform_type <> 'file_import'
+ORDER BY created_at
+`
+
+func (q *Queries) GetScheduledPlatformIntegrations(ctx context.Context) ([]PlatformIntegration, error) {
+	rows, err := q.db.Query(ctx, getScheduledPlatformIntegrations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PlatformIntegration{}
+	for rows.Next() {
+		var i PlatformIntegration
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShopID,
+			&i.PlatformType,
+			&i.PlatformShopID,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SyncIntervalMinutes,
+			&i.Currency,
+			&i.AccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPlatformIntegrationCurrency = `-- name: SetPlatformIntegrationCurrency :one
+UPDATE platform_integrations
+SET currency = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
+`
+
+type SetPlatformIntegrationCurrencyParams struct {
+	ID       id.ID[id.PlatformIntegration] `json:"id"`
+	Currency pgtype.Text                   `json:"currency"`
+}
+
+func (q *Queries) SetPlatformIntegrationCurrency(ctx context.Context, arg SetPlatformIntegrationCurrencyParams) (PlatformIntegration, error) {
+	row := q.db.QueryRow(ctx, setPlatformIntegrationCurrency, arg.ID, arg.Currency)
+	var i PlatformIntegration
+	err := row.Scan(
+		&i.ID,
+		&i.ShopID,
+		&i.PlatformType,
+		&i.PlatformShopID,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SyncIntervalMinutes,
+		&i.Currency,
+		&i.AccountID,
+	)
+	return i, err
+}
+
+const setPlatformIntegrationSyncInterval = `-- name: SetPlatformIntegrationSyncInterval :one
+UPDATE platform_integrations
+SET sync_interval_minutes = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
+`
+
+type SetPlatformIntegrationSyncIntervalParams struct {
+	ID                  id.ID[id.PlatformIntegration] `json:"id"`
+	SyncIntervalMinutes int32                         `json:"sync_interval_minutes"`
+}
+
+func (q *Queries) SetPlatformIntegrationSyncInterval(ctx context.Context, arg SetPlatformIntegrationSyncIntervalParams) (PlatformIntegration, error) {
+	row := q.db.QueryRow(ctx, setPlatformIntegrationSyncInterval, arg.ID, arg.SyncIntervalMinutes)
+	var i PlatformIntegration
+	err := row.Scan(
+		&i.ID,
+		&i.ShopID,
+		&i.PlatformType,
+		&i.PlatformShopID,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SyncIntervalMinutes,
+		&i.Currency,
+		&i.AccountID,
+	)
+	return i, err
+}
+
+const setPlatformIntegrationsAccountByShopID = `-- name: SetPlatformIntegrationsAccountByShopID :exec
+UPDATE platform_integrations
+SET account_id = $2, updated_at = NOW()
+WHERE shop_id = $1
+`
+
+type SetPlatformIntegrationsAccountByShopIDParams struct {
+	ShopID    id.ID[id.ShopifyStore]    `json:"shop_id"`
+	AccountID id.ID[id.MerchantAccount] `json:"account_id"`
+}
+
+// Moves the integrations of a shop into the account the shop joined
+func (q *Queries) SetPlatformIntegrationsAccountByShopID(ctx context.Context, arg SetPlatformIntegrationsAccountByShopIDParams) error {
+	_, err := q.db.Exec(ctx, setPlatformIntegrationsAccountByShopID, arg.ShopID, arg.AccountID)
+	return err
+}
+
 const updatePlatformIntegration = `-- name: UpdatePlatformIntegration :one
 UPDATE platform_integrations
 SET is_active = $3, updated_at = NOW()
 WHERE id = $1 AND shop_id = $2
-RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 `
 
 type UpdatePlatformIntegrationParams struct {
@@ -164,23 +360,27 @@ func (q *Queries) UpdatePlatformIntegration(ctx context.Context, arg UpdatePlatf
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncIntervalMinutes,
+		&i.Currency,
+		&i.AccountID,
 	)
 	return i, err
 }
 
 const upsertPlatformIntegration = `-- name: UpsertPlatformIntegration :one
-INSERT INTO platform_integrations (id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+INSERT INTO platform_integrations (id, shop_id, account_id, platform_type, platform_shop_id, is_active, created_at, updated_at)
+VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, NOW(), NOW())
 ON CONFLICT (platform_shop_id, platform_type)
 DO UPDATE SET
     is_active = EXCLUDED.is_active,
     updated_at = NOW()
-RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 `
 
 type UpsertPlatformIntegrationParams struct {
 	ID             id.ID[id.PlatformIntegration] `json:"id"`
 	ShopID         id.ID[id.ShopifyStore]        `json:"shop_id"`
+	AccountID      id.ID[id.MerchantAccount]     `json:"account_id"`
 	PlatformType   PlatformType                  `json:"platform_type"`
 	PlatformShopID string                        `json:"platform_shop_id"`
 	IsActive       pgtype.Bool                   `json:"is_active"`
@@ -190,6 +390,7 @@ func (q *Queries) UpsertPlatformIntegration(ctx context.Context, arg UpsertPlatf
 	row := q.db.QueryRow(ctx, upsertPlatformIntegration,
 		arg.ID,
 		arg.ShopID,
+		arg.AccountID,
 		arg.PlatformType,
 		arg.PlatformShopID,
 		arg.IsActive,
@@ -203,6 +404,9 @@ func (q *Queries) UpsertPlatformIntegration(ctx context.Context, arg UpsertPlatf
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncIntervalMinutes,
+		&i.Currency,
+		&i.AccountID,
 	)
 	return i, err
 }
@@ -13,28 +13,40 @@ import (
 )
 
 const createProductVariant = `-- name: CreateProductVariant :one
-INSERT INTO product_variants (id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-RETURNING id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at
+INSERT INTO product_variants (id, product_id, integration_id, external_id, sku, price, inventory_item_id, barcode, option_values, compare_at_price, weight, weight_unit, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+RETURNING id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at, deleted_at, barcode, option_values, compare_at_price, weight, weight_unit, integration_id
 `
 
 type CreateProductVariantParams struct {
-	ID              id.ID[id.ProductVariant] `json:"id"`
-	ProductID       id.ID[id.Product]        `json:"product_id"`
-	ExternalID      pgtype.Text              `json:"external_id"`
-	Sku             pgtype.Text              `json:"sku"`
-	Price           pgtype.Numeric           `json:"price"`
-	InventoryItemID pgtype.Text              `json:"inventory_item_id"`
+	ID              id.ID[id.ProductVariant]      `json:"id"`
+	ProductID       id.ID[id.Product]             `json:"product_id"`
+	IntegrationID   id.ID[id.PlatformIntegration] `json:"integration_id"`
+	ExternalID      pgtype.Text                   `json:"external_id"`
+	Sku             pgtype.Text                   `json:"sku"`
+	Price           pgtype.Numeric                `json:"price"`
+	InventoryItemID pgtype.Text                   `json:"inventory_item_id"`
+	Barcode         pgtype.Text                   `json:"barcode"`
+	OptionValues    []string                      `json:"option_values"`
+	CompareAtPrice  pgtype.Numeric                `json:"compare_at_price"`
+	Weight          pgtype.Numeric                `json:"weight"`
+	WeightUnit      pgtype.Text                   `json:"weight_unit"`
 }
 
 func (q *Queries) CreateProductVariant(ctx context.Context, arg CreateProductVariantParams) (ProductVariant, error) {
 	row := q.db.QueryRow(ctx, createProductVariant,
 		arg.ID,
 		arg.ProductID,
+		arg.IntegrationID,
 		arg.ExternalID,
 		arg.Sku,
 		arg.Price,
 		arg.InventoryItemID,
+		arg.Barcode,
+		arg.OptionValues,
+		arg.CompareAtPrice,
+		arg.Weight,
+		arg.WeightUnit,
 	)
 	var i ProductVariant
 	err := row.Scan(
@@ -46,15 +58,22 @@ func (q *Queries) CreateProductVariant(ctx context.Context, arg CreateProductVar
 		&i.InventoryItemID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Barcode,
+		&i.OptionValues,
+		&i.CompareAtPrice,
+		&i.Weight,
+		&i.WeightUnit,
+		&i.IntegrationID,
 	)
 	return i, err
 }
 
 const getProductVariantByExternalID = `-- name: GetProductVariantByExternalID :one
-SELECT pv.id, pv.product_id, pv.external_id, pv.sku, pv.price, pv.inventory_item_id, pv.created_at, pv.updated_at
+SELECT pv.id, pv.product_id, pv.external_id, pv.sku, pv.price, pv.inventory_item_id, pv.created_at, pv.updated_at, pv.deleted_at, pv.barcode, pv.option_values, pv.compare_at_price, pv.weight, pv.weight_unit, pv.integration_id
 FROM product_variants pv
 JOIN products p ON pv.product_id = p.id
-WHERE p.integration_id = $1 AND pv.external_id = $2
+WHERE p.integration_id = $1 AND pv.external_id = $2 AND pv.deleted_at IS NULL
 `
 
 type GetProductVariantByExternalIDParams struct {
@@ -74,14 +93,21 @@ func (q *Queries) GetProductVariantByExternalID(ctx context.Context, arg GetProd
 		&i.InventoryItemID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Barcode,
+		&i.OptionValues,
+		&i.CompareAtPrice,
+		&i.Weight,
+		&i.WeightUnit,
+		&i.IntegrationID,
 	)
 	return i, err
 }
 
 const getProductVariantByID = `-- name: GetProductVariantByID :one
-SELECT id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at
+SELECT id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at, deleted_at, barcode, option_values, compare_at_price, weight, weight_unit, integration_id
 FROM product_variants
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetProductVariantByID(ctx context.Context, argID id.ID[id.ProductVariant]) (ProductVariant, error) {
@@ -96,15 +122,22 @@ func (q *Queries) GetProductVariantByID(ctx context.Context, argID id.ID[id.Prod
 		&i.InventoryItemID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Barcode,
+		&i.OptionValues,
+		&i.CompareAtPrice,
+		&i.Weight,
+		&i.WeightUnit,
+		&i.IntegrationID,
 	)
 	return i, err
 }
 
 const getProductVariantsByIntegrationID = `-- name: GetProductVariantsByIntegrationID :many
-SELECT pv.id, pv.product_id, pv.external_id, pv.sku, pv.price, pv.inventory_item_id, pv.created_at, pv.updated_at
+SELECT pv.id, pv.product_id, pv.external_id, pv.sku, pv.price, pv.inventory_item_id, pv.created_at, pv.updated_at, pv.deleted_at, pv.barcode, pv.option_values, pv.compare_at_price, pv.weight, pv.weight_unit, pv.integration_id
 FROM product_variants pv
 JOIN products p ON pv.product_id = p.id
-WHERE p.integration_id = $1
+WHERE p.integration_id = $1 AND pv.deleted_at IS NULL
 ORDER BY pv.created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -133,6 +166,13 @@ func (q *Queries) GetProductVariantsByIntegrationID(ctx context.Context, arg Get
 			&i.InventoryItemID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Barcode,
+			&i.OptionValues,
+			&i.CompareAtPrice,
+			&i.Weight,
+			&i.WeightUnit,
+			&i.IntegrationID,
 		); err != nil {
 			return nil, err
 		}
@@ -145,9 +185,9 @@ func (q *Queries) GetProductVariantsByIntegrationID(ctx context.Context, arg Get
 }
 
 const getProductVariantsByProductID = `-- name: GetProductVariantsByProductID :many
-SELECT id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at
+SELECT id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at, deleted_at, barcode, option_values, compare_at_price, weight, weight_unit, integration_id
 FROM product_variants
-WHERE product_id = $1
+WHERE product_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -169,6 +209,13 @@ func (q *Queries) GetProductVariantsByProductID(ctx context.Context, productID i
 			&i.InventoryItemID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Barcode,
+			&i.OptionValues,
+			&i.CompareAtPrice,
+			&i.Weight,
+			&i.WeightUnit,
+			&i.IntegrationID,
 		); err != nil {
 			return nil, err
 		}
@@ -180,35 +227,73 @@ func (q *Queries) GetProductVariantsByProductID(ctx context.Context, productID i
 	return items, nil
 }
 
+const softDeleteProductVariantsNotSyncedSince = `-- name: SoftDeleteProductVariantsNotSyncedSince :execrows
+UPDATE product_variants pv
+SET deleted_at = NOW()
+FROM products p
+WHERE pv.product_id = p.id AND p.integration_id = $1 AND pv.updated_at < $2 AND pv.deleted_at IS NULL
+`
+
+type SoftDeleteProductVariantsNotSyncedSinceParams struct {
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+}
+
+func (q *Queries) SoftDeleteProductVariantsNotSyncedSince(ctx context.Context, arg SoftDeleteProductVariantsNotSyncedSinceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteProductVariantsNotSyncedSince, arg.IntegrationID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const upsertProductVariant = `-- name: UpsertProductVariant :one
-INSERT INTO product_variants (id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-ON CONFLICT (external_id)
+INSERT INTO product_variants (id, product_id, integration_id, external_id, sku, price, inventory_item_id, barcode, option_values, compare_at_price, weight, weight_unit, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     sku = EXCLUDED.sku,
     price = EXCLUDED.price,
     inventory_item_id = EXCLUDED.inventory_item_id,
+    barcode = EXCLUDED.barcode,
+    option_values = EXCLUDED.option_values,
+    compare_at_price = EXCLUDED.compare_at_price,
+    weight = EXCLUDED.weight,
+    weight_unit = EXCLUDED.weight_unit,
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at
+RETURNING id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at, deleted_at, barcode, option_values, compare_at_price, weight, weight_unit, integration_id
 `
 
 type UpsertProductVariantParams struct {
-	ID              id.ID[id.ProductVariant] `json:"id"`
-	ProductID       id.ID[id.Product]        `json:"product_id"`
-	ExternalID      pgtype.Text              `json:"external_id"`
-	Sku             pgtype.Text              `json:"sku"`
-	Price           pgtype.Numeric           `json:"price"`
-	InventoryItemID pgtype.Text              `json:"inventory_item_id"`
+	ID              id.ID[id.ProductVariant]      `json:"id"`
+	ProductID       id.ID[id.Product]             `json:"product_id"`
+	IntegrationID   id.ID[id.PlatformIntegration] `json:"integration_id"`
+	ExternalID      pgtype.Text                   `json:"external_id"`
+	Sku             pgtype.Text                   `json:"sku"`
+	Price           pgtype.Numeric                `json:"price"`
+	InventoryItemID pgtype.Text                   `json:"inventory_item_id"`
+	Barcode         pgtype.Text                   `json:"barcode"`
+	OptionValues    []string                      `json:"option_values"`
+	CompareAtPrice  pgtype.Numeric                `json:"compare_at_price"`
+	Weight          pgtype.Numeric                `json:"weight"`
+	WeightUnit      pgtype.Text                   `json:"weight_unit"`
 }
 
 func (q *Queries) UpsertProductVariant(ctx context.Context, arg UpsertProductVariantParams) (ProductVariant, error) {
 	row := q.db.QueryRow(ctx, upsertProductVariant,
 		arg.ID,
 		arg.ProductID,
+		arg.IntegrationID,
 		arg.ExternalID,
 		arg.Sku,
 		arg.Price,
 		arg.InventoryItemID,
+		arg.Barcode,
+		arg.OptionValues,
+		arg.CompareAtPrice,
+		arg.Weight,
+		arg.WeightUnit,
 	)
 	var i ProductVariant
 	err := row.Scan(
@@ -220,6 +305,13 @@ func (q *Queries) UpsertProductVariant(ctx context.Context, arg UpsertProductVar
 		&i.InventoryItemID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Barcode,
+		&i.OptionValues,
+		&i.CompareAtPrice,
+		&i.Weight,
+		&i.WeightUnit,
+		&i.IntegrationID,
 	)
 	return i, err
 }
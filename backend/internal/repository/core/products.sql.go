@@ -13,9 +13,9 @@ import (
 )
 
 const createProduct = `-- name: CreateProduct :one
-INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
-RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, vendor, tags, option_names, published_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 `
 
 type CreateProductParams struct {
@@ -26,6 +26,10 @@ type CreateProductParams struct {
 	Handle        string                        `json:"handle"`
 	ProductType   pgtype.Text                   `json:"product_type"`
 	Status        ProductStatus                 `json:"status"`
+	Vendor        pgtype.Text                   `json:"vendor"`
+	Tags          []string                      `json:"tags"`
+	OptionNames   []string                      `json:"option_names"`
+	PublishedAt   pgtype.Timestamp              `json:"published_at"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
@@ -37,6 +41,10 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		arg.Handle,
 		arg.ProductType,
 		arg.Status,
+		arg.Vendor,
+		arg.Tags,
+		arg.OptionNames,
+		arg.PublishedAt,
 	)
 	var i Product
 	err := row.Scan(
@@ -49,6 +57,11 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Vendor,
+		&i.Tags,
+		&i.OptionNames,
+		&i.PublishedAt,
 	)
 	return i, err
 }
@@ -68,9 +81,9 @@ func (q *Queries) DeleteProduct(ctx context.Context, arg DeleteProductParams) er
 }
 
 const getProductByExternalID = `-- name: GetProductByExternalID :one
-SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 FROM products
-WHERE integration_id = $1 AND external_id = $2
+WHERE integration_id = $1 AND external_id = $2 AND deleted_at IS NULL
 `
 
 type GetProductByExternalIDParams struct {
@@ -91,14 +104,19 @@ func (q *Queries) GetProductByExternalID(ctx context.Context, arg GetProductByEx
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Vendor,
+		&i.Tags,
+		&i.OptionNames,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
 const getProductByHandle = `-- name: GetProductByHandle :one
-SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 FROM products
-WHERE integration_id = $1 AND handle = $2
+WHERE integration_id = $1 AND handle = $2 AND deleted_at IS NULL
 `
 
 type GetProductByHandleParams struct {
@@ -119,14 +137,19 @@ func (q *Queries) GetProductByHandle(ctx context.Context, arg GetProductByHandle
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Vendor,
+		&i.Tags,
+		&i.OptionNames,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
 const getProductByID = `-- name: GetProductByID :one
-SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 FROM products
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetProductByID(ctx context.Context, argID id.ID[id.Product]) (Product, error) {
@@ -142,14 +165,19 @@ func (q *Queries) GetProductByID(ctx context.Context, argID id.ID[id.Product]) (
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Vendor,
+		&i.Tags,
+		&i.OptionNames,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
 const getProductsByIntegrationID = `-- name: GetProductsByIntegrationID :many
-SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 FROM products
-WHERE integration_id = $1
+WHERE integration_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -179,6 +207,11 @@ func (q *Queries) GetProductsByIntegrationID(ctx context.Context, arg GetProduct
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Vendor,
+			&i.Tags,
+			&i.OptionNames,
+			&i.PublishedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -190,11 +223,78 @@ func (q *Queries) GetProductsByIntegrationID(ctx context.Context, arg GetProduct
 	return items, nil
 }
 
+const getProductsByTags = `-- name: GetProductsByTags :many
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
+FROM products
+WHERE integration_id = $1 AND tags @> $2 AND deleted_at IS NULL
+ORDER BY title, id
+`
+
+type GetProductsByTagsParams struct {
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	Tags          []string                      `json:"tags"`
+}
+
+// Products of an integration carrying every one of the tags
+func (q *Queries) GetProductsByTags(ctx context.Context, arg GetProductsByTagsParams) ([]Product, error) {
+	rows, err := q.db.Query(ctx, getProductsByTags, arg.IntegrationID, arg.Tags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Product{}
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.IntegrationID,
+			&i.ExternalID,
+			&i.Title,
+			&i.Handle,
+			&i.ProductType,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Vendor,
+			&i.Tags,
+			&i.OptionNames,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteProductsNotSyncedSince = `-- name: SoftDeleteProductsNotSyncedSince :execrows
+UPDATE products
+SET deleted_at = NOW()
+WHERE integration_id = $1 AND updated_at < $2 AND deleted_at IS NULL
+`
+
+type SoftDeleteProductsNotSyncedSinceParams struct {
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	UpdatedAt     pgtype.Timestamp              `json:"updated_at"`
+}
+
+func (q *Queries) SoftDeleteProductsNotSyncedSince(ctx context.Context, arg SoftDeleteProductsNotSyncedSinceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteProductsNotSyncedSince, arg.IntegrationID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateProduct = `-- name: UpdateProduct :one
 UPDATE products
 SET title = $3, handle = $4, product_type = $5, status = $6, updated_at = NOW()
 WHERE id = $1 AND integration_id = $2
-RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 `
 
 type UpdateProductParams struct {
@@ -226,21 +326,31 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (P
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Vendor,
+		&i.Tags,
+		&i.OptionNames,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
 const upsertProduct = `-- name: UpsertProduct :one
-INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, vendor, tags, option_names, published_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
 ON CONFLICT (integration_id, handle)
 DO UPDATE SET
     external_id = EXCLUDED.external_id,
     title = EXCLUDED.title,
     product_type = EXCLUDED.product_type,
     status = EXCLUDED.status,
+    vendor = EXCLUDED.vendor,
+    tags = EXCLUDED.tags,
+    option_names = EXCLUDED.option_names,
+    published_at = EXCLUDED.published_at,
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 `
 
 type UpsertProductParams struct {
@@ -251,6 +361,10 @@ type UpsertProductParams struct {
 	Handle        string                        `json:"handle"`
 	ProductType   pgtype.Text                   `json:"product_type"`
 	Status        ProductStatus                 `json:"status"`
+	Vendor        pgtype.Text                   `json:"vendor"`
+	Tags          []string                      `json:"tags"`
+	OptionNames   []string                      `json:"option_names"`
+	PublishedAt   pgtype.Timestamp              `json:"published_at"`
 }
 
 func (q *Queries) UpsertProduct(ctx context.Context, arg UpsertProductParams) (Product, error) {
@@ -262,6 +376,10 @@ func (q *Queries) UpsertProduct(ctx context.Context, arg UpsertProductParams) (P
 		arg.Handle,
 		arg.ProductType,
 		arg.Status,
+		arg.Vendor,
+		arg.Tags,
+		arg.OptionNames,
+		arg.PublishedAt,
 	)
 	var i Product
 	err := row.Scan(
@@ -274,6 +392,11 @@ func (q *Queries) UpsertProduct(ctx context.Context, arg UpsertProductParams) (P
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Vendor,
+		&i.Tags,
+		&i.OptionNames,
+		&i.PublishedAt,
 	)
 	return i, err
 }
@@ -8,31 +8,81 @@ import (
 	"context"
 
 	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddMerchantAccountUser(ctx context.Context, arg AddMerchantAccountUserParams) error
+	CancelInProgressSyncStates(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]SyncState, error)
+	ClearBigCommerceClaimToken(ctx context.Context, argID id.ID[id.BigCommerceInstallation]) error
 	CreateInventoryItem(ctx context.Context, arg CreateInventoryItemParams) (InventoryItem, error)
 	CreateInventoryLevel(ctx context.Context, arg CreateInventoryLevelParams) (InventoryLevel, error)
 	CreateLocation(ctx context.Context, arg CreateLocationParams) (Location, error)
+	CreateMasterSKU(ctx context.Context, arg CreateMasterSKUParams) (MasterSku, error)
+	CreateMerchantAccount(ctx context.Context, arg CreateMerchantAccountParams) (MerchantAccount, error)
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
 	CreateOrderLineItem(ctx context.Context, arg CreateOrderLineItemParams) (OrderLineItem, error)
 	CreatePlatformIntegration(ctx context.Context, arg CreatePlatformIntegrationParams) (PlatformIntegration, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
 	CreateProductVariant(ctx context.Context, arg CreateProductVariantParams) (ProductVariant, error)
+	CreateSyncRun(ctx context.Context, arg CreateSyncRunParams) (SyncRun, error)
 	CreateSyncState(ctx context.Context, arg CreateSyncStateParams) (SyncState, error)
 	DeactivatePlatformIntegration(ctx context.Context, argID id.ID[id.PlatformIntegration]) error
+	DeleteBigCommerceInstallation(ctx context.Context, storeHash string) error
 	DeleteOrder(ctx context.Context, arg DeleteOrderParams) error
 	DeleteProduct(ctx context.Context, arg DeleteProductParams) error
+	DeleteSyncCheckpointsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) error
 	DeleteSyncState(ctx context.Context, arg DeleteSyncStateParams) error
+	FailInProgressSyncState(ctx context.Context, arg FailInProgressSyncStateParams) (int64, error)
+	FailStaleSyncStates(ctx context.Context, arg FailStaleSyncStatesParams) ([]SyncState, error)
+	FailUnfinishedSyncRuns(ctx context.Context, arg FailUnfinishedSyncRunsParams) (int64, error)
+	FinishSyncDryRun(ctx context.Context, arg FinishSyncDryRunParams) (SyncRun, error)
+	FinishSyncRun(ctx context.Context, arg FinishSyncRunParams) (SyncRun, error)
+	GetAccountVariantByID(ctx context.Context, arg GetAccountVariantByIDParams) (GetAccountVariantByIDRow, error)
+	// Live locations of the integration whether active or not, which is what a full sync keeps up to date
+	GetAllLocationsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]Location, error)
+	GetBigCommerceInstallationByClaimToken(ctx context.Context, claimTokenHash pgtype.Text) (BigcommerceInstallation, error)
+	GetBigCommerceInstallationByStoreHash(ctx context.Context, storeHash string) (BigcommerceInstallation, error)
+	GetInProgressSyncStates(ctx context.Context) ([]SyncState, error)
 	GetInventoryItemByExternalID(ctx context.Context, arg GetInventoryItemByExternalIDParams) (InventoryItem, error)
 	GetInventoryItemByID(ctx context.Context, argID id.ID[id.InventoryItem]) (InventoryItem, error)
 	GetInventoryItemsByIntegrationID(ctx context.Context, arg GetInventoryItemsByIntegrationIDParams) ([]InventoryItem, error)
 	GetInventoryLevelByID(ctx context.Context, argID id.ID[id.InventoryLevel]) (InventoryLevel, error)
+	// Levels of the integration's live items at its live locations, by the external IDs of both
+	GetInventoryLevelsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]GetInventoryLevelsByIntegrationIDRow, error)
 	GetInventoryLevelsByInventoryItemID(ctx context.Context, inventoryItemID id.ID[id.InventoryItem]) ([]InventoryLevel, error)
 	GetInventoryLevelsByLocationID(ctx context.Context, locationID id.ID[id.Location]) ([]InventoryLevel, error)
+	// The latest rate of every currency pair on or before a day
+	GetLatestExchangeRates(ctx context.Context, rateDate pgtype.Date) ([]GetLatestExchangeRatesRow, error)
 	GetLocationByExternalID(ctx context.Context, arg GetLocationByExternalIDParams) (Location, error)
 	GetLocationByID(ctx context.Context, argID id.ID[id.Location]) (Location, error)
 	GetLocationsByIntegrationID(ctx context.Context, arg GetLocationsByIntegrationIDParams) ([]Location, error)
+	GetMagentoCredentialsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (MagentoCredential, error)
+	GetMasterSKUByID(ctx context.Context, arg GetMasterSKUByIDParams) (MasterSku, error)
+	GetMasterSKUBySKU(ctx context.Context, arg GetMasterSKUBySKUParams) (MasterSku, error)
+	// Stock of every master SKU of the account and its value at cost in the integration's
+	// currency, per integration, through confirmed links of variants of the account's active
+	// integrations
+	GetMasterSKUInventory(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]GetMasterSKUInventoryRow, error)
+	GetMasterSKULinkByVariantID(ctx context.Context, arg GetMasterSKULinkByVariantIDParams) (MasterSkuLink, error)
+	// Links of the account's variants in a review state, with the master SKU and variant they join
+	GetMasterSKULinks(ctx context.Context, arg GetMasterSKULinksParams) ([]GetMasterSKULinksRow, error)
+	// Units sold of every master SKU of the account since a time and their revenue in the
+	// integration's currency, per integration, through confirmed links. Cancelled and voided
+	// orders are left out.
+	GetMasterSKUSales(ctx context.Context, arg GetMasterSKUSalesParams) ([]GetMasterSKUSalesRow, error)
+	GetMasterSKUsByAccountID(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]MasterSku, error)
+	GetMerchantAccountByID(ctx context.Context, argID id.ID[id.MerchantAccount]) (MerchantAccount, error)
+	GetMerchantAccountByShopID(ctx context.Context, shopID id.ID[id.ShopifyStore]) (MerchantAccount, error)
+	// Stock of every SKU of the account's active integrations and its value at cost in the
+	// integration's currency, per integration. Variants and inventory items are joined on the
+	// item's external ID within the integration.
+	GetMerchantAccountInventoryBySKU(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]GetMerchantAccountInventoryBySKURow, error)
+	// Units sold of every SKU of the account's integrations since a time and their revenue in the
+	// integration's currency, per integration. Cancelled and voided orders are left out.
+	GetMerchantAccountSalesBySKU(ctx context.Context, arg GetMerchantAccountSalesBySKUParams) ([]GetMerchantAccountSalesBySKURow, error)
+	GetMerchantAccountShops(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]GetMerchantAccountShopsRow, error)
+	GetMerchantAccountsByUserID(ctx context.Context, userID id.ID[id.User]) ([]MerchantAccount, error)
 	GetOrderByExternalID(ctx context.Context, arg GetOrderByExternalIDParams) (Order, error)
 	GetOrderByID(ctx context.Context, argID id.ID[id.Order]) (Order, error)
 	GetOrderLineItemByID(ctx context.Context, argID id.ID[id.OrderLineItem]) (OrderLineItem, error)
@@ -40,7 +90,9 @@ type Querier interface {
 	GetOrdersByIntegrationID(ctx context.Context, arg GetOrdersByIntegrationIDParams) ([]Order, error)
 	GetOrdersByIntegrationIDSince(ctx context.Context, arg GetOrdersByIntegrationIDSinceParams) ([]Order, error)
 	GetPlatformIntegrationByID(ctx context.Context, argID id.ID[id.PlatformIntegration]) (PlatformIntegration, error)
+	GetPlatformIntegrationByPlatformShop(ctx context.Context, arg GetPlatformIntegrationByPlatformShopParams) (PlatformIntegration, error)
 	GetPlatformIntegrationByShopAndType(ctx context.Context, arg GetPlatformIntegrationByShopAndTypeParams) (PlatformIntegration, error)
+	GetPlatformIntegrationsByAccountID(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]PlatformIntegration, error)
 	GetPlatformIntegrationsByShopID(ctx context.Context, shopID id.ID[id.ShopifyStore]) ([]PlatformIntegration, error)
 	GetProductByExternalID(ctx context.Context, arg GetProductByExternalIDParams) (Product, error)
 	GetProductByHandle(ctx context.Context, arg GetProductByHandleParams) (Product, error)
@@ -50,26 +102,59 @@ type Querier interface {
 	GetProductVariantsByIntegrationID(ctx context.Context, arg GetProductVariantsByIntegrationIDParams) ([]ProductVariant, error)
 	GetProductVariantsByProductID(ctx context.Context, productID id.ID[id.Product]) ([]ProductVariant, error)
 	GetProductsByIntegrationID(ctx context.Context, arg GetProductsByIntegrationIDParams) ([]Product, error)
+	// Products of an integration carrying every one of the tags
+	GetProductsByTags(ctx context.Context, arg GetProductsByTagsParams) ([]Product, error)
+	GetScheduledPlatformIntegrations(ctx context.Context) ([]PlatformIntegration, error)
+	GetSquareCredentialsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (SquareCredential, error)
+	GetSyncCheckpointsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]SyncCheckpoint, error)
+	GetSyncRunsByIntegrationID(ctx context.Context, arg GetSyncRunsByIntegrationIDParams) ([]SyncRun, error)
 	GetSyncState(ctx context.Context, arg GetSyncStateParams) (SyncState, error)
 	GetSyncStatesByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]SyncState, error)
+	// Variants of the account's active integrations without a master SKU link, in the order
+	// their integrations were connected
+	GetUnlinkedAccountVariants(ctx context.Context, accountID id.ID[id.MerchantAccount]) ([]GetUnlinkedAccountVariantsRow, error)
+	GetWooCommerceCredentialsByIntegrationID(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) (WoocommerceCredential, error)
 	InsertInventoryItemsBatch(ctx context.Context, arg []InsertInventoryItemsBatchParams) (int64, error)
 	InsertLocationsBatch(ctx context.Context, arg []InsertLocationsBatchParams) *InsertLocationsBatchBatchResults
 	InsertOrdersBatch(ctx context.Context, arg []InsertOrdersBatchParams) (int64, error)
 	InsertProductVariantsBatch(ctx context.Context, arg []InsertProductVariantsBatchParams) *InsertProductVariantsBatchBatchResults
 	InsertProductsBatch(ctx context.Context, arg []InsertProductsBatchParams) *InsertProductsBatchBatchResults
+	IsMerchantAccountUser(ctx context.Context, arg IsMerchantAccountUserParams) (bool, error)
+	IsSyncRunCancelRequested(ctx context.Context, id id.ID[id.SyncRun]) (bool, error)
+	IsSyncTaskCancelled(ctx context.Context, taskID pgtype.Text) (bool, error)
+	RequestSyncRunsCancellation(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]SyncRun, error)
+	SetMasterSKULinkStatus(ctx context.Context, arg SetMasterSKULinkStatusParams) (MasterSkuLink, error)
+	SetMerchantAccountReportingCurrency(ctx context.Context, arg SetMerchantAccountReportingCurrencyParams) (MerchantAccount, error)
+	SetMerchantAccountShop(ctx context.Context, arg SetMerchantAccountShopParams) (MerchantAccountShop, error)
+	SetPlatformIntegrationCurrency(ctx context.Context, arg SetPlatformIntegrationCurrencyParams) (PlatformIntegration, error)
+	SetPlatformIntegrationSyncInterval(ctx context.Context, arg SetPlatformIntegrationSyncIntervalParams) (PlatformIntegration, error)
+	// Moves the integrations of a shop into the account the shop joined
+	SetPlatformIntegrationsAccountByShopID(ctx context.Context, arg SetPlatformIntegrationsAccountByShopIDParams) error
+	SoftDeleteInventoryItemsNotSyncedSince(ctx context.Context, arg SoftDeleteInventoryItemsNotSyncedSinceParams) (int64, error)
+	SoftDeleteLocationsNotSyncedSince(ctx context.Context, arg SoftDeleteLocationsNotSyncedSinceParams) (int64, error)
+	SoftDeleteProductVariantsNotSyncedSince(ctx context.Context, arg SoftDeleteProductVariantsNotSyncedSinceParams) (int64, error)
+	SoftDeleteProductsNotSyncedSince(ctx context.Context, arg SoftDeleteProductsNotSyncedSinceParams) (int64, error)
+	TouchSyncStateHeartbeat(ctx context.Context, arg TouchSyncStateHeartbeatParams) error
 	UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order, error)
 	UpdatePlatformIntegration(ctx context.Context, arg UpdatePlatformIntegrationParams) (PlatformIntegration, error)
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
 	UpdateSyncState(ctx context.Context, arg UpdateSyncStateParams) (SyncState, error)
+	UpsertBigCommerceInstallation(ctx context.Context, arg UpsertBigCommerceInstallationParams) (BigcommerceInstallation, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) error
 	UpsertInventoryItem(ctx context.Context, arg UpsertInventoryItemParams) (InventoryItem, error)
 	UpsertInventoryLevel(ctx context.Context, arg UpsertInventoryLevelParams) (InventoryLevel, error)
 	UpsertLocation(ctx context.Context, arg UpsertLocationParams) (Location, error)
+	UpsertMagentoCredentials(ctx context.Context, arg UpsertMagentoCredentialsParams) (MagentoCredential, error)
+	UpsertMasterSKULink(ctx context.Context, arg UpsertMasterSKULinkParams) (MasterSkuLink, error)
 	UpsertOrder(ctx context.Context, arg UpsertOrderParams) (Order, error)
 	UpsertOrderLineItem(ctx context.Context, arg UpsertOrderLineItemParams) (OrderLineItem, error)
 	UpsertPlatformIntegration(ctx context.Context, arg UpsertPlatformIntegrationParams) (PlatformIntegration, error)
 	UpsertProduct(ctx context.Context, arg UpsertProductParams) (Product, error)
 	UpsertProductVariant(ctx context.Context, arg UpsertProductVariantParams) (ProductVariant, error)
+	UpsertSquareCredentials(ctx context.Context, arg UpsertSquareCredentialsParams) (SquareCredential, error)
+	UpsertSyncCheckpoint(ctx context.Context, arg UpsertSyncCheckpointParams) (SyncCheckpoint, error)
 	UpsertSyncState(ctx context.Context, arg UpsertSyncStateParams) (SyncState, error)
+	UpsertWooCommerceCredentials(ctx context.Context, arg UpsertWooCommerceCredentialsParams) (WoocommerceCredential, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,10 +12,45 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelInProgressSyncStates = `-- name: CancelInProgressSyncStates :many
+UPDATE sync_states
+SET sync_status = 'cancelled', error_message = NULL
+WHERE integration_id = $1 AND sync_status = 'in_progress'
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
+`
+
+func (q *Queries) CancelInProgressSyncStates(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) ([]SyncState, error) {
+	rows, err := q.db.Query(ctx, cancelInProgressSyncStates, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SyncState{}
+	for rows.Next() {
+		var i SyncState
+		if err := rows.Scan(
+			&i.ID,
+			&i.IntegrationID,
+			&i.EntityType,
+			&i.LastSyncedAt,
+			&i.SyncStatus,
+			&i.ErrorMessage,
+			&i.HeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createSyncState = `-- name: CreateSyncState :one
-INSERT INTO sync_states (id, integration_id, entity_type, last_synced_at, sync_status, error_message)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message
+INSERT INTO sync_states (id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
 `
 
 type CreateSyncStateParams struct {
@@ -44,6 +79,7 @@ func (q *Queries) CreateSyncState(ctx context.Context, arg CreateSyncStateParams
 		&i.LastSyncedAt,
 		&i.SyncStatus,
 		&i.ErrorMessage,
+		&i.HeartbeatAt,
 	)
 	return i, err
 }
@@ -62,8 +98,102 @@ func (q *Queries) DeleteSyncState(ctx context.Context, arg DeleteSyncStateParams
 	return err
 }
 
+const failInProgressSyncState = `-- name: FailInProgressSyncState :execrows
+UPDATE sync_states
+SET sync_status = 'failed', error_message = $3
+WHERE integration_id = $1 AND entity_type = $2 AND sync_status = 'in_progress'
+`
+
+type FailInProgressSyncStateParams struct {
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	EntityType    EntityType                    `json:"entity_type"`
+	ErrorMessage  pgtype.Text                   `json:"error_message"`
+}
+
+func (q *Queries) FailInProgressSyncState(ctx context.Context, arg FailInProgressSyncStateParams) (int64, error) {
+	result, err := q.db.Exec(ctx, failInProgressSyncState, arg.IntegrationID, arg.EntityType, arg.ErrorMessage)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const failStaleSyncStates = `-- name: FailStaleSyncStates :many
+UPDATE sync_states
+SET sync_status = 'failed', error_message = $2
+WHERE sync_status = 'in_progress' AND heartbeat_at < $1
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
+`
+
+type FailStaleSyncStatesParams struct {
+	HeartbeatAt  pgtype.Timestamp `json:"heartbeat_at"`
+	ErrorMessage pgtype.Text      `json:"error_message"`
+}
+
+func (q *Queries) FailStaleSyncStates(ctx context.Context, arg FailStaleSyncStatesParams) ([]SyncState, error) {
+	rows, err := q.db.Query(ctx, failStaleSyncStates, arg.HeartbeatAt, arg.ErrorMessage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SyncState{}
+	for rows.Next() {
+		var i SyncState
+		if err := rows.Scan(
+			&i.ID,
+			&i.IntegrationID,
+			&i.EntityType,
+			&i.LastSyncedAt,
+			&i.SyncStatus,
+			&i.ErrorMessage,
+			&i.HeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInProgressSyncStates = `-- name: GetInProgressSyncStates :many
+SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
+FROM sync_states
+WHERE sync_status = 'in_progress'
+`
+
+func (q *Queries) GetInProgressSyncStates(ctx context.Context) ([]SyncState, error) {
+	rows, err := q.db.Query(ctx, getInProgressSyncStates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SyncState{}
+	for rows.Next() {
+		var i SyncState
+		if err := rows.Scan(
+			&i.ID,
+			&i.IntegrationID,
+			&i.EntityType,
+			&i.LastSyncedAt,
+			&i.SyncStatus,
+			&i.ErrorMessage,
+			&i.HeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSyncState = `-- name: GetSyncState :one
-SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message
+SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
 FROM sync_states
 WHERE integration_id = $1 AND entity_type = $2
 `
@@ -83,12 +213,13 @@ func (q *Queries) GetSyncState(ctx context.Context, arg GetSyncStateParams) (Syn
 		&i.LastSyncedAt,
 		&i.SyncStatus,
 		&i.ErrorMessage,
+		&i.HeartbeatAt,
 	)
 	return i, err
 }
 
 const getSyncStatesByIntegrationID = `-- name: GetSyncStatesByIntegrationID :many
-SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message
+SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
 FROM sync_states
 WHERE integration_id = $1
 ORDER BY last_synced_at DESC
@@ -110,6 +241,7 @@ func (q *Queries) GetSyncStatesByIntegrationID(ctx context.Context, integrationI
 			&i.LastSyncedAt,
 			&i.SyncStatus,
 			&i.ErrorMessage,
+			&i.HeartbeatAt,
 		); err != nil {
 			return nil, err
 		}
@@ -121,11 +253,27 @@ func (q *Queries) GetSyncStatesByIntegrationID(ctx context.Context, integrationI
 	return items, nil
 }
 
+const touchSyncStateHeartbeat = `-- name: TouchSyncStateHeartbeat :exec
+UPDATE sync_states
+SET heartbeat_at = NOW()
+WHERE integration_id = $1 AND entity_type = $2 AND sync_status = 'in_progress'
+`
+
+type TouchSyncStateHeartbeatParams struct {
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	EntityType    EntityType                    `json:"entity_type"`
+}
+
+func (q *Queries) TouchSyncStateHeartbeat(ctx context.Context, arg TouchSyncStateHeartbeatParams) error {
+	_, err := q.db.Exec(ctx, touchSyncStateHeartbeat, arg.IntegrationID, arg.EntityType)
+	return err
+}
+
 const updateSyncState = `-- name: UpdateSyncState :one
 UPDATE sync_states
-SET last_synced_at = $3, sync_status = $4, error_message = $5
+SET last_synced_at = $3, sync_status = $4, error_message = $5, heartbeat_at = NOW()
 WHERE integration_id = $1 AND entity_type = $2
-RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
 `
 
 type UpdateSyncStateParams struct {
@@ -152,19 +300,21 @@ func (q *Queries) UpdateSyncState(ctx context.Context, arg UpdateSyncStateParams
 		&i.LastSyncedAt,
 		&i.SyncStatus,
 		&i.ErrorMessage,
+		&i.HeartbeatAt,
 	)
 	return i, err
 }
 
 const upsertSyncState = `-- name: UpsertSyncState :one
-INSERT INTO sync_states (id, integration_id, entity_type, last_synced_at, sync_status, error_message)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO sync_states (id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
 ON CONFLICT (integration_id, entity_type)
 DO UPDATE SET
     last_synced_at = EXCLUDED.last_synced_at,
     sync_status = EXCLUDED.sync_status,
-    error_message = EXCLUDED.error_message
-RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message
+    error_message = EXCLUDED.error_message,
+    heartbeat_at = EXCLUDED.heartbeat_at
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
 `
 
 type UpsertSyncStateParams struct {
@@ -193,6 +343,7 @@ func (q *Queries) UpsertSyncState(ctx context.Context, arg UpsertSyncStateParams
 		&i.LastSyncedAt,
 		&i.SyncStatus,
 		&i.ErrorMessage,
+		&i.HeartbeatAt,
 	)
 	return i, err
 }
@@ -0,0 +1,32 @@
+package shopify
+
+import (
+	"testing"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+)
+
+func TestConfiguredBaseURLOnlyAppliesInDevelopment(t *testing.T) {
+	previous := *config.Values
+	t.Cleanup(func() { *config.Values = previous })
+	config.Values.Shopify.APIBaseURL = "http://127.0.0.1:8090"
+
+	envs := map[string]string{
+		"":            "http://127.0.0.1:8090",
+		"development": "http://127.0.0.1:8090",
+		"staging":     "https://fake-shop.myshopify.com",
+		"production":  "https://fake-shop.myshopify.com",
+	}
+	for env, want := range envs {
+		config.Values.Service.Env = env
+		if got := NewClient("fake-shop.myshopify.com", "shpat_test").baseURL; got != want {
+			t.Errorf("base URL with SERVICE_ENV=%q = %s, want %s", env, got, want)
+		}
+	}
+
+	// An explicit WithBaseURL still applies, tests rely on it
+	config.Values.Service.Env = "production"
+	if got := NewClient("fake-shop.myshopify.com", "shpat_test", WithBaseURL("http://127.0.0.1:9000")).baseURL; got != "http://127.0.0.1:9000" {
+		t.Errorf("base URL with WithBaseURL = %s, want http://127.0.0.1:9000", got)
+	}
+}
@@ -10,8 +10,12 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
 	goshopify "github.com/bold-commerce/go-shopify/v4"
 	"github.com/pkg/errors"
 	"golang.org/x/time/rate"
@@ -21,29 +25,109 @@ import (
 type Client struct {
 	shopDomain  string
 	accessToken string
+	apiVersion  string
+	baseURL     string
 	httpClient  *http.Client
 	rateLimiter *rate.Limiter
 	goShopify   *goshopify.Client
+
+	// Version/deprecation warnings already logged by this client
+	reportedHeaders sync.Map
 }
 
-// NewClient creates a new Shopify API client
-func NewClient(shopDomain, accessToken string) *Client {
-	// Create go-shopify client for known methods
-	goShopifyClient, err := goshopify.NewClient(goshopify.App{}, shopDomain, accessToken)
-	if err != nil {
-		goShopifyClient = nil // Fallback to custom implementation
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithBaseURL sends requests to baseURL ("http://127.0.0.1:8090") instead of https://<shop domain>,
+// so a fake Shopify server can stand in for a real shop
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// baseURLNotice logs once per process that SHOPIFY_API_BASE_URL is set
+var baseURLNotice sync.Once
+
+// configuredBaseURL returns the SHOPIFY_API_BASE_URL override. It sends the access token of every
+// shop to one host, so it only applies in development and tests and is ignored elsewhere.
+func configuredBaseURL() string {
+	baseURL := config.Values.Shopify.APIBaseURL
+	if baseURL == "" {
+		return ""
+	}
+	if !config.IsTestOrDevelopment() {
+		baseURLNotice.Do(func() {
+			logger.Error("Ignoring SHOPIFY_API_BASE_URL, it is only honoured in development", "service_env", config.Values.Service.Env, "base_url", baseURL)
+		})
+		return ""
 	}
+	baseURLNotice.Do(func() {
+		logger.Warn("SHOPIFY_API_BASE_URL is set, every shop's Shopify API requests and access token go to it", "base_url", baseURL)
+	})
+	return baseURL
+}
+
+// NewClient creates a new Shopify API client
+func NewClient(shopDomain, accessToken string, opts ...ClientOption) *Client {
+	apiVersion := config.Values.Shopify.APIVersion
 
-	return &Client{
+	c := &Client{
 		shopDomain:  shopDomain,
 		accessToken: accessToken,
+		apiVersion:  apiVersion,
+		baseURL:     "https://" + shopDomain,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		// Shopify rate limit: 4 requests per second
+		// Start at 4 requests per second; adjusted from X-Shopify-Shop-Api-Call-Limit once responses arrive
 		rateLimiter: rate.NewLimiter(4, 1),
-		goShopify:   goShopifyClient,
 	}
+
+	if baseURL := configuredBaseURL(); baseURL != "" {
+		WithBaseURL(baseURL)(c)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	goShopifyOpts := []goshopify.Option{goshopify.WithVersion(apiVersion)}
+	if c.baseURL != "https://"+shopDomain {
+		// go-shopify always builds https://<shop>.myshopify.com URLs, so point its transport at the base URL instead
+		goShopifyOpts = append(goShopifyOpts, goshopify.WithHTTPClient(&http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &baseURLTransport{baseURL: c.baseURL},
+		}))
+	}
+
+	// Create go-shopify client for known methods
+	goShopifyClient, err := goshopify.NewClient(goshopify.App{}, shopDomain, accessToken, goShopifyOpts...)
+	if err != nil {
+		goShopifyClient = nil // Fallback to custom implementation
+	}
+	c.goShopify = goShopifyClient
+
+	return c
+}
+
+// baseURLTransport rewrites the scheme and host of every request to those of baseURL
+type baseURLTransport struct {
+	baseURL string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Shopify base URL %q", t.baseURL)
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	req.Host = target.Host
+
+	return http.DefaultTransport.RoundTrip(req)
 }
 
 // ResponseWithPagination wraps response data with pagination info
@@ -61,18 +145,33 @@ func (c *Client) makeRequest(ctx context.Context, method, path string, params ur
 	return resp.Data, nil
 }
 
-// makeRequestWithPagination makes a request to the Shopify API with rate limiting and returns pagination info
+// makeRequestWithPagination makes a request to the Shopify API with rate limiting and returns pagination info.
+// Rate limited (429) and server (5xx) responses are retried with jittered exponential backoff.
 func (c *Client) makeRequestWithPagination(ctx context.Context, method, path string, params url.Values) (*ResponseWithPagination, error) {
+	requestURL := fmt.Sprintf("%s/admin/api/%s%s", c.baseURL, c.apiVersion, path)
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	var resp *ResponseWithPagination
+	err := connector.Retry(ctx, "Shopify", path, func() error {
+		var err error
+		resp, err = c.doRequest(ctx, method, path, requestURL)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// doRequest performs a single rate limited request and converts error statuses into *APIError
+func (c *Client) doRequest(ctx context.Context, method, path, requestURL string) (*ResponseWithPagination, error) {
 	// Wait for rate limiter
 	if err := c.rateLimiter.Wait(ctx); err != nil {
 		return nil, errors.Wrap(err, "rate limiter error")
 	}
 
-	requestURL := fmt.Sprintf("https://%s/admin/api/2023-10%s", c.shopDomain, path)
-	if len(params) > 0 {
-		requestURL += "?" + params.Encode()
-	}
-
 	req, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create request")
@@ -87,13 +186,21 @@ func (c *Client) makeRequestWithPagination(ctx context.Context, method, path str
 	}
 	defer resp.Body.Close()
 
+	// Keep the limiter in step with Shopify's leaky bucket
+	c.adjustRateLimit(resp.Header.Get("X-Shopify-Shop-Api-Call-Limit"))
+	c.logVersionHeaders(path, resp.Header)
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read response body")
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("shopify API error: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
 	}
 
 	// Extract pagination info from Link header
@@ -220,7 +327,31 @@ func (c *Client) GetInventoryLevels(ctx context.Context, inventoryItemIDs []int6
 	}
 
 	params := url.Values{}
-	params.Set("inventory_item_ids", convertIDsToString(inventoryItemIDs))
+	// Shopify rejects filters on follow-up pages, the cursor already encodes them
+	if pageInfo == "" {
+		params.Set("inventory_item_ids", convertIDsToString(inventoryItemIDs))
+	}
+	addPaginationParams(params, limit, pageInfo)
+
+	var response InventoryLevelsResponse
+	if err := c.makePaginatedRequest(ctx, "/inventory_levels.json", params, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to get inventory levels")
+	}
+
+	return &response, nil
+}
+
+// GetInventoryLevelsByLocations retrieves the inventory levels of every item stocked at the given locations
+func (c *Client) GetInventoryLevelsByLocations(ctx context.Context, locationIDs []int64, limit int, pageInfo string) (*InventoryLevelsResponse, error) {
+	if len(locationIDs) == 0 {
+		return &InventoryLevelsResponse{}, nil
+	}
+
+	params := url.Values{}
+	// Shopify rejects filters on follow-up pages, the cursor already encodes them
+	if pageInfo == "" {
+		params.Set("location_ids", convertIDsToString(locationIDs))
+	}
 	addPaginationParams(params, limit, pageInfo)
 
 	var response InventoryLevelsResponse
@@ -269,9 +400,12 @@ func (c *Client) GetInventoryItems(ctx context.Context, inventoryItemIDs []int64
 // GetOrders retrieves orders from Shopify with date filtering
 func (c *Client) GetOrders(ctx context.Context, createdAtMin time.Time, limit int, pageInfo string) (*OrdersResponse, error) {
 	params := url.Values{}
-	params.Set("status", "any")
-	params.Set("created_at_min", createdAtMin.Format(time.RFC3339))
-	params.Set("fields", "id,name,created_at,updated_at,financial_status,fulfillment_status,total_price,currency,line_items")
+	// Shopify rejects filters on follow-up pages, the cursor already encodes them
+	if pageInfo == "" {
+		params.Set("status", "any")
+		params.Set("created_at_min", createdAtMin.Format(time.RFC3339))
+	}
+	params.Set("fields", "id,name,created_at,updated_at,financial_status,fulfillment_status,total_price,currency,presentment_currency,total_price_set,cancelled_at,line_items")
 	addPaginationParams(params, limit, pageInfo)
 
 	var response OrdersResponse
@@ -282,6 +416,43 @@ func (c *Client) GetOrders(ctx context.Context, createdAtMin time.Time, limit in
 	return &response, nil
 }
 
+// GetProductsCount retrieves the number of products in the store
+func (c *Client) GetProductsCount(ctx context.Context) (int, error) {
+	count, err := c.getCount(ctx, "/products/count.json", url.Values{})
+	return count, errors.Wrap(err, "failed to get products count")
+}
+
+// GetLocationsCount retrieves the number of locations in the store
+func (c *Client) GetLocationsCount(ctx context.Context) (int, error) {
+	count, err := c.getCount(ctx, "/locations/count.json", url.Values{})
+	return count, errors.Wrap(err, "failed to get locations count")
+}
+
+// GetOrdersCount retrieves the number of orders created since createdAtMin, using the same filters as GetOrders
+func (c *Client) GetOrdersCount(ctx context.Context, createdAtMin time.Time) (int, error) {
+	params := url.Values{}
+	params.Set("status", "any")
+	params.Set("created_at_min", createdAtMin.Format(time.RFC3339))
+
+	count, err := c.getCount(ctx, "/orders/count.json", params)
+	return count, errors.Wrap(err, "failed to get orders count")
+}
+
+// getCount calls a Shopify count endpoint
+func (c *Client) getCount(ctx context.Context, endpoint string, params url.Values) (int, error) {
+	data, err := c.makeRequest(ctx, "GET", endpoint, params)
+	if err != nil {
+		return 0, err
+	}
+
+	var response CountResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, errors.Wrap(err, "failed to unmarshal count response")
+	}
+
+	return response.Count, nil
+}
+
 // GetShop retrieves shop information from Shopify
 func (c *Client) GetShop(ctx context.Context) (*goshopify.Shop, error) {
 
@@ -0,0 +1,119 @@
+package shopify_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/shopify"
+	"github.com/ConradKurth/forecasting/backend/internal/shopify/shopifytest"
+	"github.com/pkg/errors"
+)
+
+const testToken = "shpat_test"
+
+func newFakeShop(t *testing.T) *shopifytest.Server {
+	t.Helper()
+
+	server := shopifytest.NewServer(shopifytest.DemoFixtures(), shopifytest.WithAccessToken(testToken))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetProductsFollowsLinkPagination(t *testing.T) {
+	server := newFakeShop(t)
+	client := server.ShopifyClient(testToken)
+	ctx := context.Background()
+
+	var products []shopify.ShopifyProduct
+	pages := 0
+	for pageInfo := ""; ; {
+		resp, err := client.GetProducts(ctx, 3, pageInfo)
+		if err != nil {
+			t.Fatalf("GetProducts: %v", err)
+		}
+		products = append(products, resp.Products...)
+		pages++
+		if pageInfo = resp.Pagination.NextPageInfo; pageInfo == "" {
+			break
+		}
+	}
+
+	count, err := client.GetProductsCount(ctx)
+	if err != nil {
+		t.Fatalf("GetProductsCount: %v", err)
+	}
+	if len(products) != count {
+		t.Errorf("paged through %d products, count endpoint reports %d", len(products), count)
+	}
+	if wantPages := (count + 2) / 3; pages != wantPages {
+		t.Errorf("got %d pages, want %d", pages, wantPages)
+	}
+}
+
+func TestGetOrdersKeepsFiltersAcrossPages(t *testing.T) {
+	server := newFakeShop(t)
+	client := server.ShopifyClient(testToken)
+	ctx := context.Background()
+
+	since := time.Now().AddDate(0, 0, -20)
+
+	total := 0
+	for pageInfo := ""; ; {
+		resp, err := client.GetOrders(ctx, since, 2, pageInfo)
+		if err != nil {
+			t.Fatalf("GetOrders: %v", err)
+		}
+		for _, order := range resp.Orders {
+			if order.CreatedAt.Before(since) {
+				t.Errorf("order %d created %s, before created_at_min %s", order.ID, order.CreatedAt, since)
+			}
+		}
+		total += len(resp.Orders)
+		if pageInfo = resp.Pagination.NextPageInfo; pageInfo == "" {
+			break
+		}
+	}
+
+	count, err := client.GetOrdersCount(ctx, since)
+	if err != nil {
+		t.Fatalf("GetOrdersCount: %v", err)
+	}
+	if total != count || total == 0 {
+		t.Errorf("paged through %d orders, count endpoint reports %d", total, count)
+	}
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	server := newFakeShop(t)
+	client := server.ShopifyClient(testToken)
+
+	server.FailNext("/locations.json", http.StatusServiceUnavailable, 2)
+
+	resp, err := client.GetLocations(context.Background(), 50, "")
+	if err != nil {
+		t.Fatalf("GetLocations: %v", err)
+	}
+	if len(resp.Locations) == 0 {
+		t.Error("expected locations after retrying")
+	}
+	if got := server.Requests("/locations.json"); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestErrorClasses(t *testing.T) {
+	server := newFakeShop(t)
+	ctx := context.Background()
+
+	_, err := server.ShopifyClient("wrong-token").GetProducts(ctx, 50, "")
+	if !errors.Is(err, shopify.ErrUnauthorized) {
+		t.Errorf("wrong token: got %v, want ErrUnauthorized", err)
+	}
+
+	_, err = server.ShopifyClient(testToken).GetInventoryItem(ctx, 1)
+	if !errors.Is(err, shopify.ErrNotFound) {
+		t.Errorf("unknown inventory item: got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,56 @@
+package shopify
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors for the classes of Shopify API failures callers can branch on.
+// Use errors.Is against these; the concrete value is always an *APIError.
+var (
+	ErrRateLimited  = errors.New("shopify: rate limited")
+	ErrUnauthorized = errors.New("shopify: unauthorized")
+	ErrNotFound     = errors.New("shopify: not found")
+	ErrServer       = errors.New("shopify: server error")
+)
+
+// APIError represents a non-successful response from the Shopify API
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("shopify API error: status %d, body: %s", e.StatusCode, e.Body)
+}
+
+// Is reports whether the API error belongs to the given error class
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// HTTPStatus implements connector.APIError
+func (e *APIError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// RetryDelay implements connector.APIError
+func (e *APIError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
@@ -0,0 +1,82 @@
+package shopify
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// Shopify REST buckets leak at 1/20th of their size per second
+	// (40 requests leak at 2/s on standard plans, 400 at 20/s on Plus)
+	bucketLeakDivisor = 20
+
+	// Number of requests we always keep free in the bucket
+	bucketSafetyMargin = 5
+)
+
+// parseCallLimit parses the X-Shopify-Shop-Api-Call-Limit header ("32/40")
+func parseCallLimit(header string) (used, size int, ok bool) {
+	usedPart, sizePart, found := strings.Cut(header, "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	used, err := strconv.Atoi(strings.TrimSpace(usedPart))
+	if err != nil {
+		return 0, 0, false
+	}
+	size, err = strconv.Atoi(strings.TrimSpace(sizePart))
+	if err != nil || size <= 0 {
+		return 0, 0, false
+	}
+
+	return used, size, true
+}
+
+// adjustRateLimit tunes the limiter to the leaky bucket state reported by Shopify.
+// With plenty of room we spend it at twice the leak rate, near the top we drop to the
+// leak rate, and inside the safety margin we slow down so the bucket can drain.
+func (c *Client) adjustRateLimit(header string) {
+	used, size, ok := parseCallLimit(header)
+	if !ok {
+		return
+	}
+
+	leakRate := float64(size) / bucketLeakDivisor
+	remaining := size - used
+
+	switch {
+	case remaining > size/2:
+		c.rateLimiter.SetLimit(rate.Limit(leakRate * 2))
+		c.rateLimiter.SetBurst(max(remaining/2, 1))
+	case remaining > bucketSafetyMargin:
+		c.rateLimiter.SetLimit(rate.Limit(leakRate))
+		c.rateLimiter.SetBurst(1)
+	default:
+		c.rateLimiter.SetLimit(rate.Limit(leakRate / 2))
+		c.rateLimiter.SetBurst(1)
+	}
+}
+
+// parseRetryAfter parses the Retry-After header, which Shopify sends as seconds (possibly fractional)
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(header, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
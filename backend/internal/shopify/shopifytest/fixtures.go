@@ -0,0 +1,167 @@
+package shopifytest
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/shopify"
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/pkg/errors"
+)
+
+// Page sizes used while recording, the maximums Shopify allows
+const (
+	recordPageSize = 250
+	recordIDBatch  = 50
+)
+
+//go:embed fixtures/demo_shop.json
+var demoShop []byte
+
+// Fixtures is the data a fake shop serves, in the shape of the Shopify Admin REST API
+type Fixtures struct {
+	Shop            *goshopify.Shop                 `json:"shop,omitempty"`
+	Locations       []shopify.ShopifyLocation       `json:"locations"`
+	Products        []shopify.ShopifyProduct        `json:"products"`
+	InventoryItems  []shopify.ShopifyInventoryItem  `json:"inventory_items"`
+	InventoryLevels []shopify.ShopifyInventoryLevel `json:"inventory_levels"`
+	Orders          []shopify.ShopifyOrder          `json:"orders"`
+}
+
+// DemoFixtures returns a small shop with two locations, a handful of products and recent orders.
+// Order dates are shifted so the newest order was placed an hour ago and all fall inside the sync window.
+func DemoFixtures() *Fixtures {
+	var fixtures Fixtures
+	if err := json.Unmarshal(demoShop, &fixtures); err != nil {
+		panic(errors.Wrap(err, "invalid demo shop fixtures"))
+	}
+
+	var newest time.Time
+	for _, order := range fixtures.Orders {
+		if order.CreatedAt.After(newest) {
+			newest = order.CreatedAt
+		}
+	}
+
+	shift := time.Since(newest) - time.Hour
+	for i := range fixtures.Orders {
+		order := &fixtures.Orders[i]
+		order.CreatedAt = order.CreatedAt.Add(shift)
+		if order.CancelledAt != nil {
+			cancelledAt := order.CancelledAt.Add(shift)
+			order.CancelledAt = &cancelledAt
+		}
+	}
+
+	return &fixtures
+}
+
+// LoadFixtures reads fixtures from a JSON file, such as one written by Record
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read fixtures %s", path)
+	}
+
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode fixtures %s", path)
+	}
+
+	return &fixtures, nil
+}
+
+// Save writes the fixtures to a JSON file
+func (f *Fixtures) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode fixtures")
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write fixtures %s", path)
+	}
+
+	return nil
+}
+
+// Record downloads everything the sync reads from a real shop, with orders created since ordersSince
+func Record(ctx context.Context, client *shopify.Client, ordersSince time.Time) (*Fixtures, error) {
+	fixtures := &Fixtures{}
+
+	shop, err := client.GetShop(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to record shop")
+	}
+	fixtures.Shop = shop
+
+	for pageInfo := ""; ; {
+		resp, err := client.GetLocations(ctx, recordPageSize, pageInfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to record locations")
+		}
+		fixtures.Locations = append(fixtures.Locations, resp.Locations...)
+		if pageInfo = resp.Pagination.NextPageInfo; pageInfo == "" {
+			break
+		}
+	}
+
+	var inventoryItemIDs []int64
+	for pageInfo := ""; ; {
+		resp, err := client.GetProducts(ctx, recordPageSize, pageInfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to record products")
+		}
+		fixtures.Products = append(fixtures.Products, resp.Products...)
+		for _, product := range resp.Products {
+			for _, variant := range product.Variants {
+				inventoryItemIDs = append(inventoryItemIDs, variant.InventoryItemID)
+			}
+		}
+		if pageInfo = resp.Pagination.NextPageInfo; pageInfo == "" {
+			break
+		}
+	}
+
+	for start := 0; start < len(inventoryItemIDs); start += recordIDBatch {
+		batch := inventoryItemIDs[start:min(start+recordIDBatch, len(inventoryItemIDs))]
+
+		for pageInfo := ""; ; {
+			resp, err := client.GetInventoryItems(ctx, batch, recordPageSize, pageInfo)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to record inventory items")
+			}
+			fixtures.InventoryItems = append(fixtures.InventoryItems, resp.InventoryItems...)
+			if pageInfo = resp.Pagination.NextPageInfo; pageInfo == "" {
+				break
+			}
+		}
+
+		for pageInfo := ""; ; {
+			resp, err := client.GetInventoryLevels(ctx, batch, recordPageSize, pageInfo)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to record inventory levels")
+			}
+			fixtures.InventoryLevels = append(fixtures.InventoryLevels, resp.InventoryLevels...)
+			if pageInfo = resp.Pagination.NextPageInfo; pageInfo == "" {
+				break
+			}
+		}
+	}
+
+	for pageInfo := ""; ; {
+		resp, err := client.GetOrders(ctx, ordersSince, recordPageSize, pageInfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to record orders")
+		}
+		fixtures.Orders = append(fixtures.Orders, resp.Orders...)
+		if pageInfo = resp.Pagination.NextPageInfo; pageInfo == "" {
+			break
+		}
+	}
+
+	return fixtures, nil
+}
@@ -0,0 +1,469 @@
+// Package shopifytest provides a fake Shopify Admin REST API for tests and local development.
+// It serves the endpoints the sync reads from recorded fixtures, with cursor pagination through
+// Link headers, the leaky bucket rate limit headers and injectable errors.
+package shopifytest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/shopify"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Standard plan bucket: 40 requests, leaking 2 per second
+	defaultBucketSize = 40
+	bucketLeakDivisor = 20
+
+	defaultPageSize = 50
+	maxPageSize     = 250
+
+	// Retry-After sent with rate limited responses, in seconds
+	retryAfterSeconds = "1.0"
+)
+
+// ShopDomain is the shop domain used by Server.ShopifyClient
+const ShopDomain = "fake-shop.myshopify.com"
+
+// Option configures a Handler
+type Option func(*Handler)
+
+// WithAccessToken rejects requests that do not send this access token.
+// Without it any non-empty token is accepted.
+func WithAccessToken(token string) Option {
+	return func(h *Handler) {
+		h.accessToken = token
+	}
+}
+
+// WithBucketSize sets the size of the rate limit bucket, 400 mimics a Shopify Plus shop
+func WithBucketSize(size int) Option {
+	return func(h *Handler) {
+		h.bucketSize = size
+	}
+}
+
+// injectedError is a canned error response returned by the next requests to an endpoint
+type injectedError struct {
+	status    int
+	remaining int
+}
+
+// Handler is an http.Handler serving the fake Shopify Admin API
+type Handler struct {
+	accessToken string
+	bucketSize  int
+
+	fixturesMu sync.RWMutex
+	fixtures   *Fixtures
+
+	mu            sync.Mutex
+	bucketLevel   float64
+	bucketUpdated time.Time
+	failures      map[string][]*injectedError
+	requests      map[string]int
+}
+
+// NewHandler creates a handler serving the given fixtures
+func NewHandler(fixtures *Fixtures, opts ...Option) *Handler {
+	h := &Handler{
+		bucketSize: defaultBucketSize,
+		fixtures:   fixtures,
+		failures:   map[string][]*injectedError{},
+		requests:   map[string]int{},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Server is a running fake Shopify server, closed with Close
+type Server struct {
+	*httptest.Server
+	*Handler
+}
+
+// NewServer starts a fake Shopify server serving the given fixtures
+func NewServer(fixtures *Fixtures, opts ...Option) *Server {
+	handler := NewHandler(fixtures, opts...)
+	return &Server{
+		Server:  httptest.NewServer(handler),
+		Handler: handler,
+	}
+}
+
+// ShopifyClient returns a client that talks to this server
+func (s *Server) ShopifyClient(accessToken string) *shopify.Client {
+	return shopify.NewClient(ShopDomain, accessToken, shopify.WithBaseURL(s.URL))
+}
+
+// FailNext makes the next times requests to endpoint ("/products.json") fail with status
+func (h *Handler) FailNext(endpoint string, status, times int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.failures[endpoint] = append(h.failures[endpoint], &injectedError{status: status, remaining: times})
+}
+
+// Requests returns how many requests endpoint ("/products.json") has received, including failed ones
+func (h *Handler) Requests(endpoint string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.requests[endpoint]
+}
+
+// UpdateFixtures changes the served data, e.g. to simulate edits between two syncs
+func (h *Handler) UpdateFixtures(update func(*Fixtures)) {
+	h.fixturesMu.Lock()
+	defer h.fixturesMu.Unlock()
+
+	update(h.fixtures)
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	version, endpoint, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/admin/api/"), "/")
+	if !ok || !strings.HasPrefix(r.URL.Path, "/admin/api/") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	endpoint = "/" + endpoint
+	w.Header().Set("X-Shopify-API-Version", version)
+
+	h.mu.Lock()
+	h.requests[endpoint]++
+	h.mu.Unlock()
+
+	token := r.Header.Get("X-Shopify-Access-Token")
+	if token == "" || (h.accessToken != "" && token != h.accessToken) {
+		writeError(w, http.StatusUnauthorized, "[API] Invalid API key or access token (unrecognized login or wrong password)")
+		return
+	}
+
+	if !h.takeFromBucket(w) {
+		w.Header().Set("Retry-After", retryAfterSeconds)
+		writeError(w, http.StatusTooManyRequests, "Exceeded 2 calls per second for api client. Reduce request rates to resume uninterrupted service.")
+		return
+	}
+
+	if status, failed := h.injectedFailure(endpoint); failed {
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", retryAfterSeconds)
+		}
+		writeError(w, status, http.StatusText(status))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	h.fixturesMu.RLock()
+	defer h.fixturesMu.RUnlock()
+	h.route(w, r, endpoint)
+}
+
+// route serves an authenticated, rate limited request from the fixtures
+func (h *Handler) route(w http.ResponseWriter, r *http.Request, endpoint string) {
+	switch endpoint {
+	case "/shop.json":
+		if h.fixtures.Shop == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"shop": h.fixtures.Shop})
+	case "/locations.json":
+		servePage(w, r, "locations", func(url.Values) ([]shopify.ShopifyLocation, error) {
+			return h.fixtures.Locations, nil
+		})
+	case "/locations/count.json":
+		writeJSON(w, http.StatusOK, shopify.CountResponse{Count: len(h.fixtures.Locations)})
+	case "/products.json":
+		servePage(w, r, "products", func(url.Values) ([]shopify.ShopifyProduct, error) {
+			return h.fixtures.Products, nil
+		})
+	case "/products/count.json":
+		writeJSON(w, http.StatusOK, shopify.CountResponse{Count: len(h.fixtures.Products)})
+	case "/inventory_items.json":
+		servePage(w, r, "inventory_items", h.inventoryItems)
+	case "/inventory_levels.json":
+		servePage(w, r, "inventory_levels", h.inventoryLevels)
+	case "/orders.json":
+		servePage(w, r, "orders", h.orders)
+	case "/orders/count.json":
+		orders, err := h.orders(r.URL.Query())
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, shopify.CountResponse{Count: len(orders)})
+	default:
+		if strings.HasPrefix(endpoint, "/inventory_items/") {
+			h.serveInventoryItem(w, endpoint)
+			return
+		}
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// serveInventoryItem serves /inventory_items/<id>.json
+func (h *Handler) serveInventoryItem(w http.ResponseWriter, endpoint string) {
+	itemID, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(endpoint, "/inventory_items/"), ".json"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	for _, item := range h.fixtures.InventoryItems {
+		if item.ID == itemID {
+			writeJSON(w, http.StatusOK, shopify.InventoryItemResponse{InventoryItem: item})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+// inventoryItems filters inventory items by the required ids parameter
+func (h *Handler) inventoryItems(filters url.Values) ([]shopify.ShopifyInventoryItem, error) {
+	ids, err := parseIDs(filters.Get("ids"))
+	if err != nil || len(ids) == 0 {
+		return nil, errors.New("ids is required")
+	}
+
+	items := []shopify.ShopifyInventoryItem{}
+	for _, item := range h.fixtures.InventoryItems {
+		if slices.Contains(ids, item.ID) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// inventoryLevels filters inventory levels by inventory_item_ids and location_ids, one of which is required
+func (h *Handler) inventoryLevels(filters url.Values) ([]shopify.ShopifyInventoryLevel, error) {
+	itemIDs, err := parseIDs(filters.Get("inventory_item_ids"))
+	if err != nil {
+		return nil, errors.New("inventory_item_ids is invalid")
+	}
+	locationIDs, err := parseIDs(filters.Get("location_ids"))
+	if err != nil {
+		return nil, errors.New("location_ids is invalid")
+	}
+	if len(itemIDs) == 0 && len(locationIDs) == 0 {
+		return nil, errors.New("inventory_item_ids or location_ids is required")
+	}
+
+	levels := []shopify.ShopifyInventoryLevel{}
+	for _, level := range h.fixtures.InventoryLevels {
+		if len(itemIDs) > 0 && !slices.Contains(itemIDs, level.InventoryItemID) {
+			continue
+		}
+		if len(locationIDs) > 0 && !slices.Contains(locationIDs, level.LocationID) {
+			continue
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// orders filters orders by status (open by default) and created_at_min
+func (h *Handler) orders(filters url.Values) ([]shopify.ShopifyOrder, error) {
+	var createdAtMin time.Time
+	if value := filters.Get("created_at_min"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.New("created_at_min is invalid")
+		}
+		createdAtMin = parsed
+	}
+
+	status := filters.Get("status")
+	if status == "" {
+		status = "open"
+	}
+
+	orders := []shopify.ShopifyOrder{}
+	for _, order := range h.fixtures.Orders {
+		if order.CreatedAt.Before(createdAtMin) {
+			continue
+		}
+		switch status {
+		case "any":
+		case "cancelled":
+			if order.CancelledAt == nil {
+				continue
+			}
+		default:
+			if order.CancelledAt != nil {
+				continue
+			}
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// pageCursor is the state encoded in page_info. Like Shopify's, it carries the
+// original filters, which may not be repeated on follow-up pages.
+type pageCursor struct {
+	Offset  int        `json:"offset"`
+	Filters url.Values `json:"filters"`
+}
+
+// servePage writes one page of the filtered items under key, with a Link header to the neighbouring pages
+func servePage[T any](w http.ResponseWriter, r *http.Request, key string, filter func(url.Values) ([]T, error)) {
+	query := r.URL.Query()
+
+	limit := defaultPageSize
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			writeError(w, http.StatusBadRequest, "limit is invalid")
+			return
+		}
+		limit = parsed
+	}
+
+	cursor := pageCursor{Filters: url.Values{}}
+	if pageInfo := query.Get("page_info"); pageInfo != "" {
+		for param := range query {
+			if param != "page_info" && param != "limit" && param != "fields" {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("%s cannot be passed when page_info is present", param))
+				return
+			}
+		}
+
+		decoded, err := base64.RawURLEncoding.DecodeString(pageInfo)
+		if err != nil || json.Unmarshal(decoded, &cursor) != nil {
+			writeError(w, http.StatusBadRequest, "page_info is invalid")
+			return
+		}
+	} else {
+		for param, values := range query {
+			if param != "limit" && param != "fields" {
+				cursor.Filters[param] = values
+			}
+		}
+	}
+
+	items, err := filter(cursor.Filters)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	start := min(cursor.Offset, len(items))
+	end := min(start+limit, len(items))
+
+	var links []string
+	if start > 0 {
+		links = append(links, pageLink(r, limit, pageCursor{Offset: max(start-limit, 0), Filters: cursor.Filters}, "previous"))
+	}
+	if end < len(items) {
+		links = append(links, pageLink(r, limit, pageCursor{Offset: end, Filters: cursor.Filters}, "next"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{key: items[start:end]})
+}
+
+// pageLink formats one Link header entry pointing at the page described by cursor
+func pageLink(r *http.Request, limit int, cursor pageCursor, rel string) string {
+	encoded, _ := json.Marshal(cursor)
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("page_info", base64.RawURLEncoding.EncodeToString(encoded))
+
+	return fmt.Sprintf(`<%s://%s%s?%s>; rel="%s"`, scheme, r.Host, r.URL.Path, params.Encode(), rel)
+}
+
+// takeFromBucket adds the request to the leaky bucket and reports the bucket state.
+// It returns false when the bucket is full and the request must be rejected.
+func (h *Handler) takeFromBucket(w http.ResponseWriter) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if !h.bucketUpdated.IsZero() {
+		leaked := now.Sub(h.bucketUpdated).Seconds() * float64(h.bucketSize) / bucketLeakDivisor
+		h.bucketLevel = math.Max(h.bucketLevel-leaked, 0)
+	}
+	h.bucketUpdated = now
+
+	allowed := h.bucketLevel+1 <= float64(h.bucketSize)
+	if allowed {
+		h.bucketLevel++
+	}
+
+	w.Header().Set("X-Shopify-Shop-Api-Call-Limit", fmt.Sprintf("%d/%d", int(math.Ceil(h.bucketLevel)), h.bucketSize))
+	return allowed
+}
+
+// injectedFailure consumes the next injected error of an endpoint, if any
+func (h *Handler) injectedFailure(endpoint string) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pending := h.failures[endpoint]
+	if len(pending) == 0 {
+		return 0, false
+	}
+
+	failure := pending[0]
+	failure.remaining--
+	if failure.remaining <= 0 {
+		h.failures[endpoint] = pending[1:]
+	}
+	return failure.status, true
+}
+
+// parseIDs parses a comma separated list of IDs
+func parseIDs(value string) ([]int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		parsed, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, parsed)
+	}
+	return ids, nil
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in Shopify's {"errors": ...} format
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"errors": message})
+}
@@ -14,6 +14,22 @@ type ShopifyProduct struct {
 	Variants    []ShopifyProductVariant `json:"variants"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
+
+	// Vendor, the comma-separated tags, the options variants choose values of and when the
+	// product was published to the online store, nil while unpublished
+	Vendor      string                 `json:"vendor"`
+	Tags        string                 `json:"tags"`
+	Options     []ShopifyProductOption `json:"options"`
+	PublishedAt *time.Time             `json:"published_at"`
+}
+
+// ShopifyProductOption represents a product option such as Size or Color from Shopify API
+type ShopifyProductOption struct {
+	ID        int64    `json:"id"`
+	ProductID int64    `json:"product_id"`
+	Name      string   `json:"name"`
+	Position  int      `json:"position"`
+	Values    []string `json:"values"`
 }
 
 // ShopifyProductVariant represents a product variant from Shopify API
@@ -26,6 +42,16 @@ type ShopifyProductVariant struct {
 	InventoryQuantity int     `json:"inventory_quantity"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
+
+	// Values of the product's options in position order, nil past the product's last option
+	Option1 *string `json:"option1"`
+	Option2 *string `json:"option2"`
+	Option3 *string `json:"option3"`
+
+	Barcode        *string `json:"barcode"`
+	CompareAtPrice *string `json:"compare_at_price"`
+	Weight         float64 `json:"weight"`
+	WeightUnit     string  `json:"weight_unit"`
 }
 
 // ShopifyInventoryItem represents an inventory item from Shopify API
@@ -68,6 +94,22 @@ type ShopifyOrder struct {
 	TotalPrice        string                    `json:"total_price"`
 	CancelledAt       *time.Time                `json:"cancelled_at"`
 	LineItems         []ShopifyOrderLineItem    `json:"line_items"`
+
+	// Currency the customer paid in, and the total in the shop and that currency
+	PresentmentCurrency string           `json:"presentment_currency,omitempty"`
+	TotalPriceSet       *ShopifyPriceSet `json:"total_price_set,omitempty"`
+}
+
+// ShopifyPriceSet represents an amount in the shop currency and in the presentment currency
+type ShopifyPriceSet struct {
+	ShopMoney        ShopifyMoney `json:"shop_money"`
+	PresentmentMoney ShopifyMoney `json:"presentment_money"`
+}
+
+// ShopifyMoney represents an amount in a currency
+type ShopifyMoney struct {
+	Amount       string `json:"amount"`
+	CurrencyCode string `json:"currency_code"`
 }
 
 // ShopifyOrderLineItem represents an order line item from Shopify API
@@ -114,6 +156,10 @@ type LocationsResponse struct {
 	Pagination PaginationInfo    `json:"-"`
 }
 
+type CountResponse struct {
+	Count int `json:"count"`
+}
+
 type OrdersResponse struct {
 	Orders     []ShopifyOrder `json:"orders"`
 	Pagination PaginationInfo `json:"-"`
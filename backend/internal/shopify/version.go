@@ -0,0 +1,74 @@
+package shopify
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// Shopify releases a stable API version every quarter and supports each one
+// for a minimum of twelve months after its release
+const apiVersionSupportMonths = 12
+
+// APIVersionSupportEnds returns the date the given stable API version ("2023-10") stops being supported
+func APIVersionSupportEnds(version string) (time.Time, error) {
+	released, err := time.Parse("2006-01", version)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid Shopify API version %q", version)
+	}
+
+	switch released.Month() {
+	case time.January, time.April, time.July, time.October:
+	default:
+		return time.Time{}, errors.Errorf("invalid Shopify API version %q: stable versions are released in January, April, July and October", version)
+	}
+
+	return released.AddDate(0, apiVersionSupportMonths, 0), nil
+}
+
+// CheckAPIVersion warns at startup when the configured API version is past its support window,
+// in which case Shopify silently serves the oldest supported version instead
+func CheckAPIVersion(version string, now time.Time) {
+	supportEnds, err := APIVersionSupportEnds(version)
+	if err != nil {
+		logger.Warn("Could not check Shopify API version support window", "api_version", version, "error", err)
+		return
+	}
+
+	if now.After(supportEnds) {
+		logger.Warn("Configured Shopify API version is past its support window, update SHOPIFY_API_VERSION",
+			"api_version", version,
+			"support_ended", supportEnds.Format("2006-01-02"))
+		return
+	}
+
+	logger.Info("Using Shopify API version", "api_version", version, "supported_until", supportEnds.Format("2006-01-02"))
+}
+
+// logVersionHeaders reports when Shopify served a different API version than requested or
+// flagged the endpoint as deprecated. Each path is only reported once per client.
+func (c *Client) logVersionHeaders(path string, header http.Header) {
+	servedVersion := header.Get("X-Shopify-API-Version")
+	deprecatedReason := header.Get("X-Shopify-API-Deprecated-Reason")
+
+	if servedVersion != "" && servedVersion != c.apiVersion {
+		if _, seen := c.reportedHeaders.LoadOrStore("version:"+servedVersion, true); !seen {
+			logger.Warn("Shopify served a different API version than requested",
+				"shop_domain", c.shopDomain,
+				"requested_version", c.apiVersion,
+				"served_version", servedVersion)
+		}
+	}
+
+	if deprecatedReason != "" {
+		if _, seen := c.reportedHeaders.LoadOrStore("deprecated:"+path, true); !seen {
+			logger.Warn("Shopify API endpoint is deprecated",
+				"shop_domain", c.shopDomain,
+				"api_version", servedVersion,
+				"path", path,
+				"reason", deprecatedReason)
+		}
+	}
+}
@@ -2,15 +2,28 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 
 	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/interfaces"
 	"github.com/ConradKurth/forecasting/backend/pkg/id"
 	"github.com/hibiken/asynq"
 )
 
+const (
+	// Number of tasks fetched per page when searching the queues
+	inspectorPageSize = 100
+
+	// Queue the sync tasks are enqueued to
+	syncQueue = "default"
+)
+
 // Client wraps asynq.Client for enqueueing tasks
 type Client struct {
-	client *asynq.Client
+	client    *asynq.Client
+	inspector *asynq.Inspector
 }
 
 // NewClient creates a new worker client for enqueueing tasks
@@ -20,7 +33,8 @@ func NewClient() *Client {
 	}
 
 	return &Client{
-		client: asynq.NewClient(redisOpt),
+		client:    asynq.NewClient(redisOpt),
+		inspector: asynq.NewInspector(redisOpt),
 	}
 }
 
@@ -48,24 +62,54 @@ func (c *Client) EnqueueShopifyStoreSync(ctx context.Context, userID, shopID, to
 }
 
 // EnqueueShopifyInventorySync enqueues a Shopify inventory sync task
-func (c *Client) EnqueueShopifyInventorySync(ctx context.Context, integrationID, shopDomain, accessToken string) error {
+func (c *Client) EnqueueShopifyInventorySync(ctx context.Context, integrationID, shopDomain, accessToken, trigger string) error {
 	// Parse integrationID back to the proper type
 	integrationIDParsed, err := id.ParseTyped[id.PlatformIntegration](integrationID)
 	if err != nil {
 		return err
 	}
 	
-	task, err := NewShopifyInventorySyncTask(integrationIDParsed)
+	task, err := NewShopifyInventorySyncTask(integrationIDParsed, trigger)
 	if err != nil {
 		return err
 	}
 
-	_, err = c.client.EnqueueContext(ctx, task)
-	return err
+	return c.enqueueSync(ctx, task, integrationIDParsed)
+}
+
+// EnqueueShopifyLocationsSync enqueues a sync of only the integration's locations
+func (c *Client) EnqueueShopifyLocationsSync(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error {
+	task, err := NewShopifyLocationsSyncTask(integrationID, trigger)
+	if err != nil {
+		return err
+	}
+
+	return c.enqueueSync(ctx, task, integrationID)
+}
+
+// EnqueueShopifyProductsSync enqueues a sync of only the integration's products
+func (c *Client) EnqueueShopifyProductsSync(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error {
+	task, err := NewShopifyProductsSyncTask(integrationID, trigger)
+	if err != nil {
+		return err
+	}
+
+	return c.enqueueSync(ctx, task, integrationID)
 }
 
-func (c *Client) EnqueueShopifyLocationsSync(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) error {
-	task, err := NewShopifyLocationsSyncTask(integrationID)
+// EnqueueShopifyOrdersSync enqueues a sync of only the integration's recent orders
+func (c *Client) EnqueueShopifyOrdersSync(ctx context.Context, integrationID id.ID[id.PlatformIntegration], trigger string) error {
+	task, err := NewShopifyOrdersSyncTask(integrationID, trigger)
+	if err != nil {
+		return err
+	}
+
+	return c.enqueueSync(ctx, task, integrationID)
+}
+
+// EnqueueSyncDryRun enqueues the dry run recorded as the given sync run
+func (c *Client) EnqueueSyncDryRun(ctx context.Context, integrationID id.ID[id.PlatformIntegration], runID id.ID[id.SyncRun]) error {
+	task, err := NewSyncDryRunTask(integrationID, runID)
 	if err != nil {
 		return err
 	}
@@ -74,17 +118,174 @@ func (c *Client) EnqueueShopifyLocationsSync(ctx context.Context, integrationID
 	return err
 }
 
-func (c *Client) EnqueueShopifyProductsSync(ctx context.Context, integrationID id.ID[id.PlatformIntegration]) error {
-	task, err := NewShopifyProductsSyncTask(integrationID)
-	if err != nil {
+// enqueueSync enqueues a sync task under its fixed ID to run now. If the same sync is already
+// pending or running, ErrTaskAlreadyQueued is returned. One that is scheduled for later, waiting
+// for a retry, archived or completed is replaced instead: asynq keeps those under the ID too, so
+// they would otherwise delay or block every later sync of the integration.
+func (c *Client) enqueueSync(ctx context.Context, task *asynq.Task, integrationID id.ID[id.PlatformIntegration]) error {
+	_, err := c.client.EnqueueContext(ctx, task)
+	if !errors.Is(err, asynq.ErrTaskIDConflict) {
 		return err
 	}
 
+	taskID := syncTaskID(task.Type(), integrationID)
+	existing, err := c.inspector.GetTaskInfo(syncQueue, taskID)
+	switch {
+	case errors.Is(err, asynq.ErrTaskNotFound):
+		// Finished in the meantime
+	case err != nil:
+		return fmt.Errorf("failed to get sync task %s: %w", taskID, err)
+	case !replaceableSyncState(existing.State):
+		return interfaces.ErrTaskAlreadyQueued
+	default:
+		if err := c.inspector.DeleteTask(syncQueue, taskID); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+			return fmt.Errorf("failed to replace sync task %s: %w", taskID, err)
+		}
+	}
+
 	_, err = c.client.EnqueueContext(ctx, task)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return interfaces.ErrTaskAlreadyQueued
+	}
 	return err
 }
 
+// replaceableSyncState reports whether a sync task in the state no longer holds its integration's
+// place in the queue. A scheduled sync only starts at its slot in the sync interval and a retry
+// picks up from the same checkpoints, so the new run should not wait for either; archived and
+// completed tasks are only kept for inspection.
+func replaceableSyncState(state asynq.TaskState) bool {
+	switch state {
+	case asynq.TaskStateScheduled, asynq.TaskStateRetry, asynq.TaskStateArchived, asynq.TaskStateCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// CancelInventorySyncs stops the sync tasks of an integration. Running tasks are sent asynq's
+// cancellation signal, which cancels their context, and tasks still waiting to run are deleted.
+func (c *Client) CancelInventorySyncs(ctx context.Context, integrationID string, activeTaskIDs []string) error {
+	for _, taskID := range activeTaskIDs {
+		if err := c.inspector.CancelProcessing(taskID); err != nil {
+			return fmt.Errorf("failed to cancel sync task %s: %w", taskID, err)
+		}
+	}
+
+	queues, err := c.inspector.Queues()
+	if err != nil {
+		return fmt.Errorf("failed to list queues: %w", err)
+	}
+
+	for _, queue := range queues {
+		tasks, err := c.waitingSyncTasks(queue, integrationID)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			// The task may have started or finished since it was listed
+			if err := c.inspector.DeleteTask(queue, task.ID); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+				return fmt.Errorf("failed to delete sync task %s: %w", task.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// taskLister lists one state of tasks in a queue, such as Inspector.ListPendingTasks
+type taskLister func(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+
+// waitingSyncTasks lists the integration's sync tasks in the queue that are pending, scheduled or awaiting a retry
+func (c *Client) waitingSyncTasks(queue, integrationID string) ([]*asynq.TaskInfo, error) {
+	tasks, err := c.syncTasks(queue, c.inspector.ListPendingTasks, c.inspector.ListScheduledTasks, c.inspector.ListRetryTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*asynq.TaskInfo
+	for _, task := range tasks {
+		if syncTaskIntegrationID(task.Payload) == integrationID {
+			matches = append(matches, task)
+		}
+	}
+	return matches, nil
+}
+
+// syncTasks lists the inventory sync tasks of the queue in the states covered by the listers
+func (c *Client) syncTasks(queue string, listers ...taskLister) ([]*asynq.TaskInfo, error) {
+	var matches []*asynq.TaskInfo
+	for _, list := range listers {
+		for page := 1; ; page++ {
+			tasks, err := list(queue, asynq.Page(page), asynq.PageSize(inspectorPageSize))
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tasks in queue %s: %w", queue, err)
+			}
+
+			for _, task := range tasks {
+				if isInventorySyncTask(task.Type) {
+					matches = append(matches, task)
+				}
+			}
+
+			if len(tasks) < inspectorPageSize {
+				break
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// ListInventorySyncs lists the sync tasks of all queues that are running or waiting to run.
+// Waiting tasks are listed first, so a task that starts while listing is still found among the active ones.
+func (c *Client) ListInventorySyncs(ctx context.Context) ([]interfaces.SyncTask, error) {
+	queues, err := c.inspector.Queues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queues: %w", err)
+	}
+
+	var syncTasks []interfaces.SyncTask
+	for _, queue := range queues {
+		tasks, err := c.syncTasks(queue,
+			c.inspector.ListPendingTasks,
+			c.inspector.ListScheduledTasks,
+			c.inspector.ListRetryTasks,
+			c.inspector.ListActiveTasks)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, task := range tasks {
+			var payload ShopifyInventorySyncPayload
+			if err := json.Unmarshal(task.Payload, &payload); err != nil {
+				continue
+			}
+
+			syncTasks = append(syncTasks, interfaces.SyncTask{
+				IntegrationID: payload.IntegrationID,
+				Entity:        syncTaskEntities[task.Type],
+			})
+		}
+	}
+
+	return syncTasks, nil
+}
+
+// syncTaskIntegrationID returns the integration a sync task payload belongs to, or "" if it cannot be decoded
+func syncTaskIntegrationID(data []byte) string {
+	var payload ShopifyInventorySyncPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return ""
+	}
+	return payload.IntegrationID.String()
+}
+
 // Close closes the worker client connection
 func (c *Client) Close() error {
+	if err := c.inspector.Close(); err != nil {
+		return err
+	}
 	return c.client.Close()
 }
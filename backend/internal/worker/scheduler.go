@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/ConradKurth/forecasting/backend/internal/config"
+	"github.com/ConradKurth/forecasting/backend/internal/interfaces"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+	"github.com/ConradKurth/forecasting/backend/pkg/logger"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// How often the schedules are reloaded from the database
+	scheduleSyncInterval = time.Minute
+
+	// Upper bound for loading the schedules
+	scheduleLoadTimeout = 30 * time.Second
+
+	// How often sync states stuck in_progress are looked for
+	reapStaleSyncsInterval = 5 * time.Minute
+)
+
+// Scheduler enqueues periodic inventory syncs for every integration with scheduled syncs enabled,
+// and the periodic cleanup of sync states stuck in_progress
+type Scheduler struct {
+	manager *asynq.PeriodicTaskManager
+}
+
+// NewScheduler creates a new scheduler that reads the per-integration intervals from the given provider
+func NewScheduler(schedules interfaces.SyncScheduleProvider) (*Scheduler, error) {
+	redisOpt := asynq.RedisClientOpt{
+		Addr: config.Values.Redis.URL,
+	}
+
+	manager, err := asynq.NewPeriodicTaskManager(asynq.PeriodicTaskManagerOpts{
+		RedisConnOpt:               redisOpt,
+		PeriodicTaskConfigProvider: &syncConfigProvider{schedules: schedules},
+		SyncInterval:               scheduleSyncInterval,
+		SchedulerOpts: &asynq.SchedulerOpts{
+			PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+				switch {
+				case err == nil:
+				case errors.Is(err, asynq.ErrTaskIDConflict), errors.Is(err, asynq.ErrDuplicateTask):
+					// The integration's sync is already queued or running
+					logger.Debug("Skipped scheduled sync", "reason", err)
+				default:
+					logger.Error("Failed to enqueue scheduled sync", "error", err)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create periodic task manager: %w", err)
+	}
+
+	return &Scheduler{manager: manager}, nil
+}
+
+// Start starts the scheduler in the background
+func (s *Scheduler) Start() error {
+	return s.manager.Start()
+}
+
+// Shutdown stops the scheduler
+func (s *Scheduler) Shutdown() {
+	s.manager.Shutdown()
+}
+
+// syncConfigProvider turns the integration schedules into asynq periodic task configs
+type syncConfigProvider struct {
+	schedules interfaces.SyncScheduleProvider
+}
+
+// GetConfigs implements asynq.PeriodicTaskConfigProvider
+func (p *syncConfigProvider) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), scheduleLoadTimeout)
+	defer cancel()
+
+	schedules, err := p.schedules.GetSyncSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync schedules: %w", err)
+	}
+
+	configs := make([]*asynq.PeriodicTaskConfig, 0, len(schedules)+1)
+
+	// Recover sync states left in_progress by crashed workers
+	configs = append(configs, &asynq.PeriodicTaskConfig{
+		Cronspec: fmt.Sprintf("@every %s", reapStaleSyncsInterval),
+		Task:     NewReapStaleSyncsTask(),
+		Opts:     []asynq.Option{asynq.Unique(reapStaleSyncsInterval)},
+	})
+
+	for _, schedule := range schedules {
+		task, err := NewShopifyInventorySyncTask(schedule.IntegrationID, SyncTriggerSchedule)
+		if err != nil {
+			return nil, err
+		}
+
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: fmt.Sprintf("@every %s", schedule.Interval),
+			Task:     task,
+			Opts: []asynq.Option{
+				// Spread integrations across the interval so they don't all hit Shopify at once
+				asynq.ProcessIn(staggerOffset(schedule.IntegrationID, schedule.Interval)),
+				// Don't pile up syncs when one takes longer than the interval
+				asynq.Unique(schedule.Interval),
+			},
+		})
+	}
+
+	logger.Debug("Loaded sync schedules", "count", len(schedules))
+	return configs, nil
+}
+
+// staggerOffset returns a stable delay within the interval for the integration, so each
+// integration keeps the same slot across scheduler restarts
+func staggerOffset(integrationID id.ID[id.PlatformIntegration], interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(integrationID.String()))
+	return time.Duration(h.Sum64() % uint64(interval)).Truncate(time.Second)
+}
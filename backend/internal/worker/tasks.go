@@ -14,17 +14,49 @@ const (
 	TypeShopifyLocationsSync = "shopify:locations_sync"
 	TypeShopifyProductsSync  = "shopify:products_sync"
 	TypeShopifyOrdersSync    = "shopify:orders_sync"
+	TypeReapStaleSyncs       = "sync:reap_stale"
+	TypeSyncDryRun           = "sync:dry_run"
 )
 
+// syncTaskEntities maps the inventory sync task types to the sync state each one reports to
+var syncTaskEntities = map[string]string{
+	TypeShopifyInventorySync: "full_sync",
+	TypeShopifyLocationsSync: "location",
+	TypeShopifyProductsSync:  "product",
+	TypeShopifyOrdersSync:    "order",
+}
+
+// syncTaskID returns the fixed ID of an integration's sync task of the given type. asynq rejects a
+// task whose ID is already waiting or running, so each sync is queued at most once.
+func syncTaskID(taskType string, integrationID id.ID[id.PlatformIntegration]) string {
+	return taskType + ":" + integrationID.String()
+}
+
+// isInventorySyncTask reports whether the task type syncs an integration's inventory data
+func isInventorySyncTask(taskType string) bool {
+	_, ok := syncTaskEntities[taskType]
+	return ok
+}
+
 // ShopifyStoreSyncPayload contains data needed for Shopify store sync
 type ShopifyStoreSyncPayload struct {
 	UserID id.ID[id.User]         `json:"user_id"`
 	ShopID id.ID[id.ShopifyStore] `json:"shop_id"`
 }
 
+// Trigger recorded for syncs enqueued by the scheduler
+const SyncTriggerSchedule = "schedule"
+
 // ShopifyInventorySyncPayload contains data needed for Shopify inventory sync
 type ShopifyInventorySyncPayload struct {
 	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	Trigger       string                        `json:"trigger,omitempty"`
+}
+
+// SyncDryRunPayload contains data needed for a sync dry run
+type SyncDryRunPayload struct {
+	IntegrationID id.ID[id.PlatformIntegration] `json:"integration_id"`
+	RunID         id.ID[id.SyncRun]             `json:"run_id"`
 }
 
 // NewShopifyStoreSyncTask creates a new task for syncing Shopify store data
@@ -43,9 +75,10 @@ func NewShopifyStoreSyncTask(userID id.ID[id.User], shopID id.ID[id.ShopifyStore
 }
 
 // NewShopifyInventorySyncTask creates a new task for syncing Shopify inventory data
-func NewShopifyInventorySyncTask(integrationID id.ID[id.PlatformIntegration]) (*asynq.Task, error) {
+func NewShopifyInventorySyncTask(integrationID id.ID[id.PlatformIntegration], trigger string) (*asynq.Task, error) {
 	payload := ShopifyInventorySyncPayload{
 		IntegrationID: integrationID,
+		Trigger:       trigger,
 	}
 
 	data, err := json.Marshal(payload)
@@ -53,13 +86,14 @@ func NewShopifyInventorySyncTask(integrationID id.ID[id.PlatformIntegration]) (*
 		return nil, err
 	}
 
-	return asynq.NewTask(TypeShopifyInventorySync, data), nil
+	return asynq.NewTask(TypeShopifyInventorySync, data, asynq.TaskID(syncTaskID(TypeShopifyInventorySync, integrationID))), nil
 }
 
 // NewShopifyLocationsSyncTask creates a new task for syncing Shopify locations
-func NewShopifyLocationsSyncTask(integrationID id.ID[id.PlatformIntegration]) (*asynq.Task, error) {
+func NewShopifyLocationsSyncTask(integrationID id.ID[id.PlatformIntegration], trigger string) (*asynq.Task, error) {
 	payload := ShopifyInventorySyncPayload{
 		IntegrationID: integrationID,
+		Trigger:       trigger,
 	}
 
 	data, err := json.Marshal(payload)
@@ -67,13 +101,14 @@ func NewShopifyLocationsSyncTask(integrationID id.ID[id.PlatformIntegration]) (*
 		return nil, err
 	}
 
-	return asynq.NewTask(TypeShopifyLocationsSync, data), nil
+	return asynq.NewTask(TypeShopifyLocationsSync, data, asynq.TaskID(syncTaskID(TypeShopifyLocationsSync, integrationID))), nil
 }
 
 // NewShopifyProductsSyncTask creates a new task for syncing Shopify products
-func NewShopifyProductsSyncTask(integrationID id.ID[id.PlatformIntegration]) (*asynq.Task, error) {
+func NewShopifyProductsSyncTask(integrationID id.ID[id.PlatformIntegration], trigger string) (*asynq.Task, error) {
 	payload := ShopifyInventorySyncPayload{
 		IntegrationID: integrationID,
+		Trigger:       trigger,
 	}
 
 	data, err := json.Marshal(payload)
@@ -81,13 +116,30 @@ func NewShopifyProductsSyncTask(integrationID id.ID[id.PlatformIntegration]) (*a
 		return nil, err
 	}
 
-	return asynq.NewTask(TypeShopifyProductsSync, data), nil
+	return asynq.NewTask(TypeShopifyProductsSync, data, asynq.TaskID(syncTaskID(TypeShopifyProductsSync, integrationID))), nil
 }
 
 // NewShopifyOrdersSyncTask creates a new task for syncing Shopify orders
-func NewShopifyOrdersSyncTask(integrationID id.ID[id.PlatformIntegration]) (*asynq.Task, error) {
+func NewShopifyOrdersSyncTask(integrationID id.ID[id.PlatformIntegration], trigger string) (*asynq.Task, error) {
 	payload := ShopifyInventorySyncPayload{
 		IntegrationID: integrationID,
+		Trigger:       trigger,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeShopifyOrdersSync, data, asynq.TaskID(syncTaskID(TypeShopifyOrdersSync, integrationID))), nil
+}
+
+// NewSyncDryRunTask creates a new task for the dry run recorded as the given sync run. The run
+// records a failed dry run, so the task is not retried: it can be triggered again instead.
+func NewSyncDryRunTask(integrationID id.ID[id.PlatformIntegration], runID id.ID[id.SyncRun]) (*asynq.Task, error) {
+	payload := SyncDryRunPayload{
+		IntegrationID: integrationID,
+		RunID:         runID,
 	}
 
 	data, err := json.Marshal(payload)
@@ -95,5 +147,10 @@ func NewShopifyOrdersSyncTask(integrationID id.ID[id.PlatformIntegration]) (*asy
 		return nil, err
 	}
 
-	return asynq.NewTask(TypeShopifyOrdersSync, data), nil
+	return asynq.NewTask(TypeSyncDryRun, data, asynq.MaxRetry(0)), nil
+}
+
+// NewReapStaleSyncsTask creates a new task for failing sync states stuck in_progress
+func NewReapStaleSyncsTask() *asynq.Task {
+	return asynq.NewTask(TypeReapStaleSyncs, nil)
 }
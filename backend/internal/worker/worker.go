@@ -3,8 +3,10 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
 	"github.com/ConradKurth/forecasting/backend/internal/interfaces"
 	"github.com/ConradKurth/forecasting/backend/pkg/logger"
 	"github.com/hibiken/asynq"
@@ -31,6 +33,8 @@ func (w *Worker) RegisterHandlers(mux *asynq.ServeMux) {
 	mux.HandleFunc(TypeShopifyLocationsSync, w.HandleShopifyLocationsSync)
 	mux.HandleFunc(TypeShopifyProductsSync, w.HandleShopifyProductsSync)
 	mux.HandleFunc(TypeShopifyOrdersSync, w.HandleShopifyOrdersSync)
+	mux.HandleFunc(TypeReapStaleSyncs, w.HandleReapStaleSyncs)
+	mux.HandleFunc(TypeSyncDryRun, w.HandleSyncDryRun)
 }
 
 // HandleShopifyStoreSync processes Shopify store synchronization tasks
@@ -57,10 +61,10 @@ func (w *Worker) HandleShopifyInventorySync(ctx context.Context, t *asynq.Task)
 		return fmt.Errorf("failed to unmarshal shopify inventory sync payload: %w", err)
 	}
 
-	logger.Info("Inventory sync requested", "integration_id", payload.IntegrationID)
+	logger.Info("Inventory sync requested", "integration_id", payload.IntegrationID, "trigger", payload.Trigger)
 
 	// Use the injected sync manager to perform the inventory sync
-	return w.syncManager.SyncInventory(ctx, payload.IntegrationID)
+	return skipRetryIfPermanent(w.syncManager.SyncInventory(ctx, payload.IntegrationID, payload.Trigger))
 }
 
 // HandleShopifyLocationsSync processes Shopify locations synchronization tasks
@@ -71,7 +75,7 @@ func (w *Worker) HandleShopifyLocationsSync(ctx context.Context, t *asynq.Task)
 	}
 
 	logger.Info("Locations sync requested", "integration_id", payload.IntegrationID)
-	return nil
+	return skipRetryIfPermanent(w.syncManager.SyncLocations(ctx, payload.IntegrationID, payload.Trigger))
 }
 
 // HandleShopifyProductsSync processes Shopify products synchronization tasks
@@ -82,7 +86,7 @@ func (w *Worker) HandleShopifyProductsSync(ctx context.Context, t *asynq.Task) e
 	}
 
 	logger.Info("Products sync requested", "integration_id", payload.IntegrationID)
-	return nil
+	return skipRetryIfPermanent(w.syncManager.SyncProducts(ctx, payload.IntegrationID, payload.Trigger))
 }
 
 // HandleShopifyOrdersSync processes Shopify orders synchronization tasks
@@ -93,5 +97,36 @@ func (w *Worker) HandleShopifyOrdersSync(ctx context.Context, t *asynq.Task) err
 	}
 
 	logger.Info("Orders sync requested", "integration_id", payload.IntegrationID)
+	return skipRetryIfPermanent(w.syncManager.SyncOrders(ctx, payload.IntegrationID, payload.Trigger))
+}
+
+// HandleSyncDryRun processes sync dry run tasks
+func (w *Worker) HandleSyncDryRun(ctx context.Context, t *asynq.Task) error {
+	var payload SyncDryRunPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal sync dry run payload: %w", err)
+	}
+
+	logger.Info("Sync dry run requested", "integration_id", payload.IntegrationID, "run_id", payload.RunID)
+	return w.syncManager.RunSyncDryRun(ctx, payload.IntegrationID, payload.RunID)
+}
+
+// HandleReapStaleSyncs fails sync states left in_progress by syncs that stopped sending heartbeats
+func (w *Worker) HandleReapStaleSyncs(ctx context.Context, t *asynq.Task) error {
+	if err := w.syncManager.ReapStaleSyncs(ctx); err != nil {
+		return fmt.Errorf("failed to reap stale syncs: %w", err)
+	}
 	return nil
 }
+
+// skipRetryIfPermanent stops asynq from retrying sync failures that retrying cannot fix,
+// such as a revoked token or a deleted store, whatever the platform
+func skipRetryIfPermanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, connector.ErrUnauthorized) || errors.Is(err, connector.ErrNotFound) {
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ConradKurth/forecasting/backend/internal/connector"
+	shopifyapi "github.com/ConradKurth/forecasting/backend/internal/shopify"
+	"github.com/hibiken/asynq"
+)
+
+func TestSkipRetryIfPermanentKeepsTheErrorChain(t *testing.T) {
+	for _, cause := range []error{
+		connector.WithStatus(&shopifyapi.APIError{StatusCode: http.StatusUnauthorized}, http.StatusUnauthorized),
+		connector.WithStatus(errors.New("woocommerce API error: status 404"), http.StatusNotFound),
+		connector.WithStatus(errors.New("square API error: status 403"), http.StatusForbidden),
+	} {
+		err := skipRetryIfPermanent(fmt.Errorf("failed to sync: %w", cause))
+		if !errors.Is(err, asynq.SkipRetry) || !errors.Is(err, cause) {
+			t.Errorf("skipRetryIfPermanent(%v) = %v, want both SkipRetry and the cause matched", cause, err)
+		}
+	}
+
+	rateLimited := connector.WithStatus(errors.New("bigcommerce API error: status 429"), http.StatusTooManyRequests)
+	if err := skipRetryIfPermanent(rateLimited); errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("skipRetryIfPermanent(rate limited) = %v, want it retried", err)
+	}
+
+	transient := errors.New("connection reset")
+	if err := skipRetryIfPermanent(transient); errors.Is(err, asynq.SkipRetry) || err != transient {
+		t.Errorf("skipRetryIfPermanent(transient) = %v, want it returned as is", err)
+	}
+}
+
+func TestReplaceableSyncState(t *testing.T) {
+	states := map[asynq.TaskState]bool{
+		asynq.TaskStatePending:   false,
+		asynq.TaskStateActive:    false,
+		asynq.TaskStateScheduled: true,
+		asynq.TaskStateRetry:     true,
+		asynq.TaskStateArchived:  true,
+		asynq.TaskStateCompleted: true,
+	}
+	for state, want := range states {
+		if got := replaceableSyncState(state); got != want {
+			t.Errorf("replaceableSyncState(%s) = %v, want %v", state, got, want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package workertest provides an in-memory interfaces.Queue for unit tests that should not need Redis.
+package workertest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/ConradKurth/forecasting/backend/internal/interfaces"
+	"github.com/ConradKurth/forecasting/backend/pkg/id"
+)
+
+// StoreSyncTask is an enqueued Shopify store sync
+type StoreSyncTask struct {
+	UserID string
+	ShopID string
+	Token  string
+}
+
+// InventorySyncTask is an enqueued Shopify inventory sync
+type InventorySyncTask struct {
+	IntegrationID string
+	ShopDomain    string
+	AccessToken   string
+	Trigger       string
+}
+
+// DryRunTask is an enqueued sync dry run
+type DryRunTask struct {
+	IntegrationID id.ID[id.PlatformIntegration]
+	RunID         id.ID[id.SyncRun]
+}
+
+// Queue is an in-memory interfaces.Queue that records the tasks enqueued on it. Nothing is run:
+// an inventory sync stays queued until Finish or Archive is called, and like the real queue a
+// second sync of the same integration is rejected with interfaces.ErrTaskAlreadyQueued while one
+// is queued. Archived syncs are replaced by the next sync of their integration.
+type Queue struct {
+	mu             sync.Mutex
+	storeSyncs     []StoreSyncTask
+	inventorySyncs []InventorySyncTask
+	dryRuns        []DryRunTask
+	queued         []string
+	archived       []string
+	cancelled      []string
+	enqueueErr     error
+}
+
+var _ interfaces.Queue = (*Queue)(nil)
+
+// NewQueue creates an empty queue
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+// FailEnqueue makes every following enqueue return err, nil restores normal behaviour
+func (q *Queue) FailEnqueue(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.enqueueErr = err
+}
+
+// StoreSyncs returns the store syncs enqueued so far
+func (q *Queue) StoreSyncs() []StoreSyncTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return slices.Clone(q.storeSyncs)
+}
+
+// InventorySyncs returns the inventory syncs enqueued so far, including finished and cancelled ones
+func (q *Queue) InventorySyncs() []InventorySyncTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return slices.Clone(q.inventorySyncs)
+}
+
+// DryRuns returns the sync dry runs enqueued so far
+func (q *Queue) DryRuns() []DryRunTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return slices.Clone(q.dryRuns)
+}
+
+// Cancelled returns the integrations whose syncs were cancelled, in order
+func (q *Queue) Cancelled() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return slices.Clone(q.cancelled)
+}
+
+// Finish takes the integration's sync off the queue, as if the worker had run it
+func (q *Queue) Finish(integrationID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queued = slices.DeleteFunc(q.queued, func(queued string) bool { return queued == integrationID })
+}
+
+// Archive takes the integration's sync off the queue into the archive, as asynq does with a task
+// that failed without retrying or ran out of retries
+func (q *Queue) Archive(integrationID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queued = slices.DeleteFunc(q.queued, func(queued string) bool { return queued == integrationID })
+	q.archived = append(q.archived, integrationID)
+}
+
+// Archived returns the integrations whose archived syncs were not replaced yet
+func (q *Queue) Archived() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return slices.Clone(q.archived)
+}
+
+// EnqueueShopifyStoreSync records a store sync
+func (q *Queue) EnqueueShopifyStoreSync(ctx context.Context, userID, shopID, token string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.enqueueErr != nil {
+		return q.enqueueErr
+	}
+
+	q.storeSyncs = append(q.storeSyncs, StoreSyncTask{UserID: userID, ShopID: shopID, Token: token})
+	return nil
+}
+
+// EnqueueShopifyInventorySync records an inventory sync and keeps it queued until Finish
+func (q *Queue) EnqueueShopifyInventorySync(ctx context.Context, integrationID, shopDomain, accessToken, trigger string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.enqueueErr != nil {
+		return q.enqueueErr
+	}
+	if slices.Contains(q.queued, integrationID) {
+		return interfaces.ErrTaskAlreadyQueued
+	}
+	q.archived = slices.DeleteFunc(q.archived, func(archived string) bool { return archived == integrationID })
+
+	q.inventorySyncs = append(q.inventorySyncs, InventorySyncTask{
+		IntegrationID: integrationID,
+		ShopDomain:    shopDomain,
+		AccessToken:   accessToken,
+		Trigger:       trigger,
+	})
+	q.queued = append(q.queued, integrationID)
+	return nil
+}
+
+// EnqueueSyncDryRun records a sync dry run
+func (q *Queue) EnqueueSyncDryRun(ctx context.Context, integrationID id.ID[id.PlatformIntegration], runID id.ID[id.SyncRun]) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.enqueueErr != nil {
+		return q.enqueueErr
+	}
+
+	q.dryRuns = append(q.dryRuns, DryRunTask{IntegrationID: integrationID, RunID: runID})
+	return nil
+}
+
+// CancelInventorySyncs takes the integration's sync off the queue and records the cancellation
+func (q *Queue) CancelInventorySyncs(ctx context.Context, integrationID string, activeTaskIDs []string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queued = slices.DeleteFunc(q.queued, func(queued string) bool { return queued == integrationID })
+	q.cancelled = append(q.cancelled, integrationID)
+	return nil
+}
+
+// ListInventorySyncs lists the queued inventory syncs
+func (q *Queue) ListInventorySyncs(ctx context.Context) ([]interfaces.SyncTask, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	tasks := make([]interfaces.SyncTask, 0, len(q.queued))
+	for _, integrationID := range q.queued {
+		tasks = append(tasks, interfaces.SyncTask{
+			IntegrationID: id.ID[id.PlatformIntegration](integrationID),
+			Entity:        "full_sync",
+		})
+	}
+	return tasks, nil
+}
+
+// Close implements interfaces.Queue, there is nothing to close
+func (q *Queue) Close() error {
+	return nil
+}
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Sync checkpoints - the resume position of each entity within an unfinished sync.
+-- A row is written in the same transaction as every committed page, so a retried
+-- sync can continue from the last committed page instead of starting over.
+CREATE TABLE sync_checkpoints (
+    id TEXT PRIMARY KEY,
+    integration_id TEXT NOT NULL REFERENCES platform_integrations(id),
+    entity_type entity_type NOT NULL,
+    page_info TEXT, -- Cursor of the next page to fetch, NULL when the entity is complete
+    pages_processed INTEGER NOT NULL DEFAULT 0,
+    stats JSONB NOT NULL DEFAULT '{}', -- Row counts committed so far
+    completed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(integration_id, entity_type)
+);
+
+CREATE INDEX idx_sync_checkpoints_integration_id ON sync_checkpoints(integration_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS sync_checkpoints;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Soft deletes - rows that disappear from the platform are marked with deleted_at
+-- at the end of a full sync instead of lingering as live data
+ALTER TABLE locations ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE product_variants ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE inventory_items ADD COLUMN deleted_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE inventory_items DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE locations DROP COLUMN IF EXISTS deleted_at;
+
+-- +goose StatementEnd
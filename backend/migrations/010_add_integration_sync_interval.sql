@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- How often the scheduler runs a full sync for the integration, 0 disables scheduled syncs
+ALTER TABLE platform_integrations ADD COLUMN sync_interval_minutes INTEGER NOT NULL DEFAULT 360
+    CHECK (sync_interval_minutes >= 0);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE platform_integrations DROP COLUMN IF EXISTS sync_interval_minutes;
+
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- What started a sync run
+CREATE TYPE sync_trigger AS ENUM (
+    'oauth',
+    'manual',
+    'schedule',
+    'webhook'
+);
+
+-- Sync runs - one row per sync attempt, kept as history next to the latest state in sync_states
+CREATE TABLE sync_runs (
+    id TEXT PRIMARY KEY,
+    integration_id TEXT NOT NULL REFERENCES platform_integrations(id),
+    entity_type entity_type NOT NULL,
+    trigger sync_trigger NOT NULL,
+    sync_status sync_status NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP,
+    phase_timings JSONB NOT NULL DEFAULT '{}', -- Milliseconds spent in each phase
+    stats JSONB NOT NULL DEFAULT '{}', -- Row counts committed by the run
+    error_message TEXT
+);
+
+CREATE INDEX idx_sync_runs_integration_id_started_at ON sync_runs(integration_id, started_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS sync_runs;
+DROP TYPE IF EXISTS sync_trigger;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+-- Syncs stopped by the user. Enum values cannot be added inside a transaction block.
+ALTER TYPE sync_status ADD VALUE IF NOT EXISTS 'cancelled';
+
+-- The asynq task running each sync, so it can be cancelled and its retries skipped
+ALTER TABLE sync_runs ADD COLUMN task_id TEXT;
+
+-- Set when a user asks to stop the run; the sync checks it between pages
+ALTER TABLE sync_runs ADD COLUMN cancel_requested_at TIMESTAMP;
+
+CREATE INDEX idx_sync_runs_task_id ON sync_runs(task_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_sync_runs_task_id;
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS cancel_requested_at;
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS task_id;
+
+-- Postgres cannot drop an enum value, so move cancelled rows to failed and leave the value in place
+UPDATE sync_states SET sync_status = 'failed' WHERE sync_status = 'cancelled';
+UPDATE sync_runs SET sync_status = 'failed' WHERE sync_status = 'cancelled';
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Last sign of life of the sync owning the state. Running syncs refresh it periodically, so an
+-- in_progress state with an old heartbeat belongs to a worker that crashed or a task that was lost.
+ALTER TABLE sync_states ADD COLUMN heartbeat_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_sync_states_in_progress_heartbeat ON sync_states(heartbeat_at) WHERE sync_status = 'in_progress';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_sync_states_in_progress_heartbeat;
+ALTER TABLE sync_states DROP COLUMN IF EXISTS heartbeat_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+-- Inventory levels are synced as an entity of their own after locations and products,
+-- so they get their own sync checkpoint. Enum values cannot be added inside a transaction block.
+ALTER TYPE entity_type ADD VALUE IF NOT EXISTS 'inventory_levels';
+
+-- +goose Down
+
+-- Postgres cannot drop an enum value, so remove the rows using it and leave the value in place
+DELETE FROM sync_checkpoints WHERE entity_type = 'inventory_levels';
+DELETE FROM sync_states WHERE entity_type = 'inventory_levels';
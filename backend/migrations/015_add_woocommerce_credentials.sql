@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- WooCommerce REST API credentials of an integration. The consumer key and secret are
+-- encrypted by the application (crypto.EncryptedSecret) before they are stored.
+CREATE TABLE woocommerce_credentials (
+    id TEXT PRIMARY KEY,
+    integration_id TEXT NOT NULL UNIQUE REFERENCES platform_integrations(id),
+    store_url TEXT NOT NULL, -- Base URL of the WordPress site, e.g. https://shop.example.com
+    consumer_key TEXT NOT NULL,
+    consumer_secret TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_woocommerce_credentials_updated_at
+    BEFORE UPDATE ON woocommerce_credentials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_woocommerce_credentials_updated_at ON woocommerce_credentials;
+DROP TABLE IF EXISTS woocommerce_credentials;
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- BigCommerce app installations, one per store. The install callback saves the access token
+-- before the store is linked to a shop; the merchant then claims the installation from the app
+-- with the one-time claim token, which creates the store's platform integration.
+-- The access token is encrypted by the application (crypto.EncryptedSecret) before it is stored.
+CREATE TABLE bigcommerce_installations (
+    id TEXT PRIMARY KEY,
+    store_hash TEXT NOT NULL UNIQUE, -- Matches platform_integrations.platform_shop_id
+    access_token TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    claim_token_hash TEXT UNIQUE, -- SHA-256 of the claim token, NULL once claimed
+    claim_expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_bigcommerce_installations_updated_at
+    BEFORE UPDATE ON bigcommerce_installations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_bigcommerce_installations_updated_at ON bigcommerce_installations;
+DROP TABLE IF EXISTS bigcommerce_installations;
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Square access token of an integration, a personal access token of the seller's account. It is
+-- encrypted by the application (crypto.EncryptedSecret) before it is stored.
+CREATE TABLE square_credentials (
+    id TEXT PRIMARY KEY,
+    integration_id TEXT NOT NULL UNIQUE REFERENCES platform_integrations(id),
+    access_token TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_square_credentials_updated_at
+    BEFORE UPDATE ON square_credentials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_square_credentials_updated_at ON square_credentials;
+DROP TABLE IF EXISTS square_credentials;
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Magento 2 REST API credentials of an integration, the access token of an integration created in
+-- the Magento admin. It is encrypted by the application (crypto.EncryptedSecret) before it is stored.
+CREATE TABLE magento_credentials (
+    id TEXT PRIMARY KEY,
+    integration_id TEXT NOT NULL UNIQUE REFERENCES platform_integrations(id),
+    store_url TEXT NOT NULL, -- Base URL of the Magento store, e.g. https://shop.example.com
+    access_token TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_magento_credentials_updated_at
+    BEFORE UPDATE ON magento_credentials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_magento_credentials_updated_at ON magento_credentials;
+DROP TABLE IF EXISTS magento_credentials;
+
+-- +goose StatementEnd
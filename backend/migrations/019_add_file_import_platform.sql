@@ -0,0 +1,11 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+-- Shops without an API integration, whose catalog, stock and sales are imported from CSV or
+-- Excel files. Enum values cannot be added inside a transaction block.
+ALTER TYPE platform_type ADD VALUE IF NOT EXISTS 'file_import';
+
+-- +goose Down
+
+-- Postgres cannot drop an enum value, so deactivate file import integrations and leave the value in place
+UPDATE platform_integrations SET is_active = false WHERE platform_type = 'file_import';
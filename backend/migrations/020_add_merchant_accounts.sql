@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Merchant accounts group the shops of one merchant, such as two Shopify stores, so the
+-- integrations of all of them (a Square point of sale included) can be viewed together.
+CREATE TABLE merchant_accounts (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Users with access to an account
+CREATE TABLE merchant_account_users (
+    account_id TEXT NOT NULL REFERENCES merchant_accounts(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (account_id, user_id)
+);
+
+-- Shops of an account. A shop belongs to one account, and its integrations with it.
+CREATE TABLE merchant_account_shops (
+    shop_id TEXT PRIMARY KEY REFERENCES shopify_store(id) ON DELETE CASCADE,
+    account_id TEXT NOT NULL REFERENCES merchant_accounts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_merchant_account_users_user_id ON merchant_account_users(user_id);
+CREATE INDEX idx_merchant_account_shops_account_id ON merchant_account_shops(account_id);
+
+-- Variants are matched across the channels of an account by SKU
+CREATE INDEX idx_product_variants_sku ON product_variants(sku);
+
+CREATE TRIGGER update_merchant_accounts_updated_at
+    BEFORE UPDATE ON merchant_accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_merchant_accounts_updated_at ON merchant_accounts;
+DROP INDEX IF EXISTS idx_product_variants_sku;
+DROP TABLE IF EXISTS merchant_account_shops;
+DROP TABLE IF EXISTS merchant_account_users;
+DROP TABLE IF EXISTS merchant_accounts;
+
+-- +goose StatementEnd
@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- How a variant was matched to a master SKU
+CREATE TYPE master_sku_match_type AS ENUM (
+    'exact',      -- Same SKU
+    'normalized', -- Same SKU ignoring case, spaces and separators
+    'barcode',    -- Same barcode (GTIN, UPC or EAN)
+    'manual'      -- Linked by a user
+);
+
+-- Review state of a match. Exact and manual matches are confirmed, the others are suggested
+-- until a user confirms or rejects them.
+CREATE TYPE master_sku_link_status AS ENUM (
+    'suggested',
+    'confirmed',
+    'rejected'
+);
+
+-- Barcodes of variants, matched across platforms that use different SKUs
+ALTER TABLE product_variants ADD COLUMN barcode TEXT;
+CREATE INDEX idx_product_variants_barcode ON product_variants(barcode);
+
+-- Master SKUs are the physical items of a merchant account, which each integration sells as
+-- its own product variant
+CREATE TABLE master_skus (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL REFERENCES merchant_accounts(id) ON DELETE CASCADE,
+    sku TEXT NOT NULL,
+    title TEXT NOT NULL,
+    barcode TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (account_id, sku)
+);
+
+-- Links of product variants to their master SKU. A variant has at most one link, a rejected
+-- link is kept so matching does not suggest it again.
+CREATE TABLE master_sku_links (
+    variant_id TEXT PRIMARY KEY REFERENCES product_variants(id) ON DELETE CASCADE,
+    master_sku_id TEXT NOT NULL REFERENCES master_skus(id) ON DELETE CASCADE,
+    match_type master_sku_match_type NOT NULL,
+    status master_sku_link_status NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_master_sku_links_master_sku_id ON master_sku_links(master_sku_id);
+
+CREATE TRIGGER update_master_skus_updated_at
+    BEFORE UPDATE ON master_skus
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_master_sku_links_updated_at
+    BEFORE UPDATE ON master_sku_links
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_master_sku_links_updated_at ON master_sku_links;
+DROP TRIGGER IF EXISTS update_master_skus_updated_at ON master_skus;
+DROP TABLE IF EXISTS master_sku_links;
+DROP TABLE IF EXISTS master_skus;
+DROP INDEX IF EXISTS idx_product_variants_barcode;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS barcode;
+DROP TYPE IF EXISTS master_sku_link_status;
+DROP TYPE IF EXISTS master_sku_match_type;
+
+-- +goose StatementEnd
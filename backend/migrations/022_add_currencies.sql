@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Currency of the integration's store as an ISO 4217 code. Variant prices, inventory item
+-- costs and order totals of the integration are all in it; NULL until the platform reported it.
+ALTER TABLE platform_integrations ADD COLUMN currency TEXT
+    CHECK (currency ~ '^[A-Z]{3}$');
+
+-- Currency the customer paid in and the order total in it, when they differ from the store
+-- currency of total_price
+ALTER TABLE orders ADD COLUMN presentment_currency TEXT
+    CHECK (presentment_currency ~ '^[A-Z]{3}$');
+ALTER TABLE orders ADD COLUMN presentment_total_price DECIMAL(10,2);
+
+-- Currency the revenue and stock value of an account's views are converted into
+ALTER TABLE merchant_accounts ADD COLUMN reporting_currency TEXT NOT NULL DEFAULT 'USD'
+    CHECK (reporting_currency ~ '^[A-Z]{3}$');
+
+-- Exchange rates by day: one unit of the base currency is worth rate units of the quote
+-- currency. Rates are loaded from files or a rates provider; source records which.
+CREATE TABLE exchange_rates (
+    base_currency TEXT NOT NULL CHECK (base_currency ~ '^[A-Z]{3}$'),
+    quote_currency TEXT NOT NULL CHECK (quote_currency ~ '^[A-Z]{3}$'),
+    rate_date DATE NOT NULL,
+    rate NUMERIC(20,10) NOT NULL CHECK (rate > 0),
+    source TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency, rate_date)
+);
+
+CREATE INDEX idx_exchange_rates_rate_date ON exchange_rates(rate_date);
+
+CREATE TRIGGER update_exchange_rates_updated_at
+    BEFORE UPDATE ON exchange_rates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_exchange_rates_updated_at ON exchange_rates;
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE merchant_accounts DROP COLUMN IF EXISTS reporting_currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS presentment_total_price;
+ALTER TABLE orders DROP COLUMN IF EXISTS presentment_currency;
+ALTER TABLE platform_integrations DROP COLUMN IF EXISTS currency;
+
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Attributes products are grouped and forecast by. Tags are free-form labels of the platform;
+-- option_names are the names of the product's options (Size, Color) in position order.
+ALTER TABLE products ADD COLUMN vendor TEXT;
+ALTER TABLE products ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE products ADD COLUMN option_names TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE products ADD COLUMN published_at TIMESTAMP; -- NULL while the product is unpublished
+
+-- Products are looked up by the tags they carry
+CREATE INDEX idx_products_tags ON products USING GIN (tags);
+CREATE INDEX idx_products_vendor ON products(vendor);
+
+-- option_values are the variant's value of each of its product's options, in the same order.
+-- Weight is in weight_unit (g, kg, lb or oz).
+ALTER TABLE product_variants ADD COLUMN option_values TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE product_variants ADD COLUMN compare_at_price DECIMAL(10,2);
+ALTER TABLE product_variants ADD COLUMN weight DECIMAL(10,3);
+ALTER TABLE product_variants ADD COLUMN weight_unit TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE product_variants DROP COLUMN IF EXISTS weight_unit;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS weight;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS compare_at_price;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS option_values;
+DROP INDEX IF EXISTS idx_products_vendor;
+DROP INDEX IF EXISTS idx_products_tags;
+ALTER TABLE products DROP COLUMN IF EXISTS published_at;
+ALTER TABLE products DROP COLUMN IF EXISTS option_names;
+ALTER TABLE products DROP COLUMN IF EXISTS tags;
+ALTER TABLE products DROP COLUMN IF EXISTS vendor;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Scheduled syncs are opt-in: integrations start without one until an interval is set for them.
+-- Integrations still at the former default of 360 minutes never chose it, so theirs is turned off.
+ALTER TABLE platform_integrations ALTER COLUMN sync_interval_minutes SET DEFAULT 0;
+UPDATE platform_integrations SET sync_interval_minutes = 0 WHERE sync_interval_minutes = 360;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE platform_integrations ALTER COLUMN sync_interval_minutes SET DEFAULT 360;
+
+-- +goose StatementEnd
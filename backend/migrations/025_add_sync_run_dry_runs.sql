@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Dry runs are recorded as sync runs too, and their result is read back from the run.
+-- They leave the sync states alone, so only the run tracks them.
+ALTER TABLE sync_runs ADD COLUMN dry_run BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE sync_runs ADD COLUMN diff JSONB; -- What a finished dry run found a sync would change
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM sync_runs WHERE dry_run;
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS diff;
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS dry_run;
+
+-- +goose StatementEnd
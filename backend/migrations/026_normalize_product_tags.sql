@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Tags are stored trimmed and lower-cased without repeats, so that filtering by a tag matches it
+-- whatever case the merchant typed it in. Syncs write them normalized; this rewrites the stored ones.
+UPDATE products SET tags = ARRAY(
+    SELECT lower(btrim(t.tag))
+    FROM unnest(tags) WITH ORDINALITY AS t(tag, position)
+    WHERE btrim(t.tag) <> ''
+    GROUP BY lower(btrim(t.tag))
+    ORDER BY min(t.position)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+
+-- The case tags were written in is not kept, normalized tags stay as they are
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- External IDs are only unique within one platform store. WooCommerce, BigCommerce and Magento number
+-- their records from 1, and every Magento store has a "default" source, so a global key on external_id
+-- made two stores overwrite each other's rows. Rows are keyed on (integration_id, external_id) instead.
+ALTER TABLE locations DROP CONSTRAINT IF EXISTS locations_external_id_key;
+ALTER TABLE locations ADD CONSTRAINT locations_integration_id_external_id_key UNIQUE (integration_id, external_id);
+
+ALTER TABLE inventory_items DROP CONSTRAINT IF EXISTS inventory_items_external_id_key;
+ALTER TABLE inventory_items ADD CONSTRAINT inventory_items_integration_id_external_id_key UNIQUE (integration_id, external_id);
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_external_id_key;
+ALTER TABLE orders ADD CONSTRAINT orders_integration_id_external_id_key UNIQUE (integration_id, external_id);
+
+-- Variants carry the integration of their product so that they can be keyed the same way
+ALTER TABLE product_variants ADD COLUMN integration_id TEXT REFERENCES platform_integrations(id);
+UPDATE product_variants pv SET integration_id = p.integration_id FROM products p WHERE pv.product_id = p.id;
+ALTER TABLE product_variants ALTER COLUMN integration_id SET NOT NULL;
+
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS product_variants_external_id_key;
+ALTER TABLE product_variants ADD CONSTRAINT product_variants_integration_id_external_id_key UNIQUE (integration_id, external_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS product_variants_integration_id_external_id_key;
+ALTER TABLE product_variants ADD CONSTRAINT product_variants_external_id_key UNIQUE (external_id);
+ALTER TABLE product_variants DROP COLUMN IF EXISTS integration_id;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_integration_id_external_id_key;
+ALTER TABLE orders ADD CONSTRAINT orders_external_id_key UNIQUE (external_id);
+
+ALTER TABLE inventory_items DROP CONSTRAINT IF EXISTS inventory_items_integration_id_external_id_key;
+ALTER TABLE inventory_items ADD CONSTRAINT inventory_items_external_id_key UNIQUE (external_id);
+
+ALTER TABLE locations DROP CONSTRAINT IF EXISTS locations_integration_id_external_id_key;
+ALTER TABLE locations ADD CONSTRAINT locations_external_id_key UNIQUE (external_id);
+
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Integrations belong to a merchant account. The Shopify shop they were connected from is kept when
+-- there is one, but a merchant selling only on Square or WooCommerce has no shop at all.
+ALTER TABLE platform_integrations ADD COLUMN account_id TEXT REFERENCES merchant_accounts(id) ON DELETE CASCADE;
+UPDATE platform_integrations pi SET account_id = mas.account_id FROM merchant_account_shops mas WHERE pi.shop_id = mas.shop_id;
+
+ALTER TABLE platform_integrations ALTER COLUMN shop_id DROP NOT NULL;
+ALTER TABLE platform_integrations ADD CONSTRAINT platform_integrations_owner_check CHECK (shop_id IS NOT NULL OR account_id IS NOT NULL);
+
+CREATE INDEX idx_platform_integrations_account_id ON platform_integrations(account_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_platform_integrations_account_id;
+ALTER TABLE platform_integrations DROP CONSTRAINT IF EXISTS platform_integrations_owner_check;
+-- Fails while there are integrations without a shop, which have to be removed first
+ALTER TABLE platform_integrations ALTER COLUMN shop_id SET NOT NULL;
+ALTER TABLE platform_integrations DROP COLUMN IF EXISTS account_id;
+
+-- +goose StatementEnd
@@ -107,3 +107,67 @@ type SyncState struct {
 func (s SyncState) Prefix() string {
 	return "syc_"
 }
+
+type SyncCheckpoint struct {
+	ID string
+}
+
+func (s SyncCheckpoint) Prefix() string {
+	return "sck_"
+}
+
+type SyncRun struct {
+	ID string
+}
+
+func (s SyncRun) Prefix() string {
+	return "srn_"
+}
+
+type WooCommerceCredential struct {
+	ID string
+}
+
+func (w WooCommerceCredential) Prefix() string {
+	return "wcc_"
+}
+
+type BigCommerceInstallation struct {
+	ID string
+}
+
+func (b BigCommerceInstallation) Prefix() string {
+	return "bci_"
+}
+
+type SquareCredential struct {
+	ID string
+}
+
+func (s SquareCredential) Prefix() string {
+	return "sqc_"
+}
+
+type MagentoCredential struct {
+	ID string
+}
+
+func (m MagentoCredential) Prefix() string {
+	return "mgc_"
+}
+
+type MerchantAccount struct {
+	ID string
+}
+
+func (m MerchantAccount) Prefix() string {
+	return "mac_"
+}
+
+type MasterSKU struct {
+	ID string
+}
+
+func (m MasterSKU) Prefix() string {
+	return "msk_"
+}
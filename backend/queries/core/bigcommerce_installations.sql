@@ -0,0 +1,32 @@
+-- name: ClearBigCommerceClaimToken :exec
+UPDATE bigcommerce_installations
+SET claim_token_hash = NULL,
+    claim_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteBigCommerceInstallation :exec
+DELETE FROM bigcommerce_installations
+WHERE store_hash = $1;
+
+-- name: GetBigCommerceInstallationByClaimToken :one
+SELECT id, store_hash, access_token, scope, claim_token_hash, claim_expires_at, created_at, updated_at
+FROM bigcommerce_installations
+WHERE claim_token_hash = $1 AND claim_expires_at > NOW();
+
+-- name: GetBigCommerceInstallationByStoreHash :one
+SELECT id, store_hash, access_token, scope, claim_token_hash, claim_expires_at, created_at, updated_at
+FROM bigcommerce_installations
+WHERE store_hash = $1;
+
+-- name: UpsertBigCommerceInstallation :one
+INSERT INTO bigcommerce_installations (id, store_hash, access_token, scope, claim_token_hash, claim_expires_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+ON CONFLICT (store_hash)
+DO UPDATE SET
+    access_token = EXCLUDED.access_token,
+    scope = EXCLUDED.scope,
+    claim_token_hash = EXCLUDED.claim_token_hash,
+    claim_expires_at = EXCLUDED.claim_expires_at,
+    updated_at = NOW()
+RETURNING id, store_hash, access_token, scope, claim_token_hash, claim_expires_at, created_at, updated_at;
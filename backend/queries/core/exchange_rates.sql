@@ -0,0 +1,15 @@
+-- name: UpsertExchangeRate :exec
+INSERT INTO exchange_rates (base_currency, quote_currency, rate_date, rate, source, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+ON CONFLICT (base_currency, quote_currency, rate_date)
+DO UPDATE SET
+    rate = EXCLUDED.rate,
+    source = EXCLUDED.source,
+    updated_at = NOW();
+
+-- name: GetLatestExchangeRates :many
+-- The latest rate of every currency pair on or before a day
+SELECT DISTINCT ON (base_currency, quote_currency) base_currency, quote_currency, rate_date, rate, source
+FROM exchange_rates
+WHERE rate_date <= $1
+ORDER BY base_currency, quote_currency, rate_date DESC;
@@ -1,35 +1,41 @@
 -- name: GetInventoryItemByID :one
-SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at
+SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at
 FROM inventory_items
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetInventoryItemsByIntegrationID :many
-SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at
+SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at
 FROM inventory_items
-WHERE integration_id = $1
+WHERE integration_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: GetInventoryItemByExternalID :one
-SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at
+SELECT id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at
 FROM inventory_items
-WHERE integration_id = $1 AND external_id = $2;
+WHERE integration_id = $1 AND external_id = $2 AND deleted_at IS NULL;
 
 -- name: CreateInventoryItem :one
 INSERT INTO inventory_items (id, integration_id, external_id, sku, tracked, cost, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-RETURNING id, integration_id, external_id, sku, tracked, cost, created_at, updated_at;
+RETURNING id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at;
 
 -- name: UpsertInventoryItem :one
 INSERT INTO inventory_items (id, integration_id, external_id, sku, tracked, cost, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-ON CONFLICT (external_id)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     sku = EXCLUDED.sku,
     tracked = EXCLUDED.tracked,
     cost = EXCLUDED.cost,
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING id, integration_id, external_id, sku, tracked, cost, created_at, updated_at;
+RETURNING id, integration_id, external_id, sku, tracked, cost, created_at, updated_at, deleted_at;
 
 -- name: InsertInventoryItemsBatch :copyfrom
 INSERT INTO inventory_items (id, integration_id, external_id, sku, tracked, cost, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: SoftDeleteInventoryItemsNotSyncedSince :execrows
+UPDATE inventory_items
+SET deleted_at = NOW()
+WHERE integration_id = $1 AND updated_at < $2 AND deleted_at IS NULL;
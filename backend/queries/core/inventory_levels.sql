@@ -3,6 +3,14 @@ SELECT id, inventory_item_id, location_id, available, updated_at
 FROM inventory_levels
 WHERE id = $1;
 
+-- name: GetInventoryLevelsByIntegrationID :many
+-- Levels of the integration's live items at its live locations, by the external IDs of both
+SELECT ii.external_id AS inventory_item_external_id, l.external_id AS location_external_id, il.available
+FROM inventory_levels il
+JOIN inventory_items ii ON ii.id = il.inventory_item_id AND ii.deleted_at IS NULL
+JOIN locations l ON l.id = il.location_id AND l.deleted_at IS NULL
+WHERE ii.integration_id = $1;
+
 -- name: GetInventoryLevelsByInventoryItemID :many
 SELECT id, inventory_item_id, location_id, available, updated_at
 FROM inventory_levels
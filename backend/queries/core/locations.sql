@@ -1,46 +1,60 @@
 -- name: GetLocationByID :one
-SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at
+SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
 FROM locations
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetLocationsByIntegrationID :many
-SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at
+SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
 FROM locations
-WHERE integration_id = $1 AND is_active = true
+WHERE integration_id = $1 AND is_active = true AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
+-- name: GetAllLocationsByIntegrationID :many
+-- Live locations of the integration whether active or not, which is what a full sync keeps up to date
+SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
+FROM locations
+WHERE integration_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
 -- name: GetLocationByExternalID :one
-SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at
+SELECT id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at
 FROM locations
-WHERE integration_id = $1 AND external_id = $2;
+WHERE integration_id = $1 AND external_id = $2 AND deleted_at IS NULL;
 
 -- name: CreateLocation :one
 INSERT INTO locations (id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
-RETURNING id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at;
+RETURNING id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at;
 
 -- name: UpsertLocation :one
 INSERT INTO locations (id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
-ON CONFLICT (external_id)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     name = EXCLUDED.name,
     address = EXCLUDED.address,
     country = EXCLUDED.country,
     province = EXCLUDED.province,
     is_active = EXCLUDED.is_active,
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at;
+RETURNING id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at, deleted_at;
 
 -- name: InsertLocationsBatch :batchexec
 INSERT INTO locations (id, integration_id, external_id, name, address, country, province, is_active, created_at, updated_at) 
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-ON CONFLICT (external_id)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     name = EXCLUDED.name,
     address = EXCLUDED.address,
     country = EXCLUDED.country,
     province = EXCLUDED.province,
     is_active = EXCLUDED.is_active,
+    deleted_at = NULL,
     updated_at = EXCLUDED.updated_at;
+
+-- name: SoftDeleteLocationsNotSyncedSince :execrows
+UPDATE locations
+SET deleted_at = NOW()
+WHERE integration_id = $1 AND updated_at < $2 AND deleted_at IS NULL;
@@ -0,0 +1,14 @@
+-- name: GetMagentoCredentialsByIntegrationID :one
+SELECT id, integration_id, store_url, access_token, created_at, updated_at
+FROM magento_credentials
+WHERE integration_id = $1;
+
+-- name: UpsertMagentoCredentials :one
+INSERT INTO magento_credentials (id, integration_id, store_url, access_token, created_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW(), NOW())
+ON CONFLICT (integration_id)
+DO UPDATE SET
+    store_url = EXCLUDED.store_url,
+    access_token = EXCLUDED.access_token,
+    updated_at = NOW()
+RETURNING id, integration_id, store_url, access_token, created_at, updated_at;
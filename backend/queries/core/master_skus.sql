@@ -0,0 +1,115 @@
+-- name: CreateMasterSKU :one
+INSERT INTO master_skus (id, account_id, sku, title, barcode, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+RETURNING id, account_id, sku, title, barcode, created_at, updated_at;
+
+-- name: GetMasterSKUByID :one
+SELECT id, account_id, sku, title, barcode, created_at, updated_at
+FROM master_skus
+WHERE id = $1 AND account_id = $2;
+
+-- name: GetMasterSKUBySKU :one
+SELECT id, account_id, sku, title, barcode, created_at, updated_at
+FROM master_skus
+WHERE account_id = $1 AND sku = $2;
+
+-- name: GetMasterSKUsByAccountID :many
+SELECT id, account_id, sku, title, barcode, created_at, updated_at
+FROM master_skus
+WHERE account_id = $1
+ORDER BY sku;
+
+-- name: GetUnlinkedAccountVariants :many
+-- Variants of the account's active integrations without a master SKU link, in the order
+-- their integrations were connected
+SELECT pv.id, pv.sku, pv.barcode, p.title, pi.id AS integration_id, pi.platform_type
+FROM platform_integrations pi
+JOIN products p ON p.integration_id = pi.id AND p.deleted_at IS NULL
+JOIN product_variants pv ON pv.product_id = p.id AND pv.deleted_at IS NULL
+LEFT JOIN master_sku_links msl ON msl.variant_id = pv.id
+WHERE pi.account_id = $1 AND pi.is_active = true AND msl.variant_id IS NULL
+ORDER BY pi.created_at, pv.sku, pv.id;
+
+-- name: GetAccountVariantByID :one
+SELECT pv.id, pv.sku, pv.barcode, p.title, pi.id AS integration_id, pi.platform_type
+FROM product_variants pv
+JOIN products p ON p.id = pv.product_id AND p.deleted_at IS NULL
+JOIN platform_integrations pi ON pi.id = p.integration_id
+WHERE pi.account_id = $1 AND pv.id = $2 AND pv.deleted_at IS NULL;
+
+-- name: UpsertMasterSKULink :one
+INSERT INTO master_sku_links (variant_id, master_sku_id, match_type, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW(), NOW())
+ON CONFLICT (variant_id)
+DO UPDATE SET
+    master_sku_id = EXCLUDED.master_sku_id,
+    match_type = EXCLUDED.match_type,
+    status = EXCLUDED.status,
+    updated_at = NOW()
+RETURNING variant_id, master_sku_id, match_type, status, created_at, updated_at;
+
+-- name: GetMasterSKULinkByVariantID :one
+SELECT msl.variant_id, msl.master_sku_id, msl.match_type, msl.status, msl.created_at, msl.updated_at
+FROM master_sku_links msl
+JOIN master_skus ms ON ms.id = msl.master_sku_id
+WHERE msl.variant_id = $1 AND ms.account_id = $2;
+
+-- name: SetMasterSKULinkStatus :one
+UPDATE master_sku_links
+SET status = $2, updated_at = NOW()
+WHERE variant_id = $1
+RETURNING variant_id, master_sku_id, match_type, status, created_at, updated_at;
+
+-- name: GetMasterSKULinks :many
+-- Links of the account's variants in a review state, with the master SKU and variant they join
+SELECT msl.variant_id, msl.master_sku_id, msl.match_type, msl.status, msl.updated_at,
+       ms.sku AS master_sku, ms.title AS master_title,
+       pv.sku AS variant_sku, pv.barcode AS variant_barcode, p.title AS product_title,
+       pi.id AS integration_id, pi.platform_type
+FROM master_sku_links msl
+JOIN master_skus ms ON ms.id = msl.master_sku_id
+JOIN product_variants pv ON pv.id = msl.variant_id AND pv.deleted_at IS NULL
+JOIN products p ON p.id = pv.product_id AND p.deleted_at IS NULL
+JOIN platform_integrations pi ON pi.id = p.integration_id
+WHERE ms.account_id = $1 AND msl.status = $2
+ORDER BY ms.sku, pi.created_at, pv.id;
+
+-- name: GetMasterSKUInventory :many
+-- Stock of every master SKU of the account and its value at cost in the integration's
+-- currency, per integration, through confirmed links of variants of the account's active
+-- integrations
+SELECT ms.id AS master_sku_id, pi.id AS integration_id, pi.platform_type, pi.currency,
+       COUNT(DISTINCT pv.id) AS variants_count,
+       COALESCE(SUM(il.available) FILTER (WHERE l.deleted_at IS NULL), 0)::bigint AS available,
+       COALESCE(SUM(il.available * ii.cost) FILTER (WHERE l.deleted_at IS NULL AND il.available > 0), 0)::float8 AS stock_value
+FROM master_skus ms
+JOIN master_sku_links msl ON msl.master_sku_id = ms.id AND msl.status = 'confirmed'
+JOIN product_variants pv ON pv.id = msl.variant_id AND pv.deleted_at IS NULL
+JOIN products p ON p.id = pv.product_id AND p.deleted_at IS NULL
+JOIN platform_integrations pi ON pi.id = p.integration_id AND pi.account_id = ms.account_id AND pi.is_active = true
+LEFT JOIN inventory_items ii ON ii.integration_id = pi.id AND ii.external_id = pv.inventory_item_id AND ii.deleted_at IS NULL
+LEFT JOIN inventory_levels il ON il.inventory_item_id = ii.id
+LEFT JOIN locations l ON l.id = il.location_id
+WHERE ms.account_id = $1
+GROUP BY ms.id, pi.id, pi.platform_type, pi.currency
+ORDER BY ms.id, pi.id;
+
+-- name: GetMasterSKUSales :many
+-- Units sold of every master SKU of the account since a time and their revenue in the
+-- integration's currency, per integration, through confirmed links. Cancelled and voided
+-- orders are left out.
+SELECT ms.id AS master_sku_id, pi.id AS integration_id, pi.platform_type, pi.currency,
+       COUNT(DISTINCT o.id) AS orders_count,
+       SUM(oli.quantity)::bigint AS units_sold,
+       COALESCE(SUM(oli.quantity * oli.price), 0)::float8 AS revenue
+FROM master_skus ms
+JOIN master_sku_links msl ON msl.master_sku_id = ms.id AND msl.status = 'confirmed'
+JOIN order_line_items oli ON oli.variant_id = msl.variant_id
+JOIN orders o ON o.id = oli.order_id
+JOIN platform_integrations pi ON pi.id = o.integration_id AND pi.account_id = ms.account_id
+WHERE ms.account_id = $1
+  AND o.created_at >= $2
+  AND o.cancelled_at IS NULL
+  AND o.financial_status <> 'voided'
+GROUP BY ms.id, pi.id, pi.platform_type, pi.currency
+ORDER BY ms.id, pi.id;
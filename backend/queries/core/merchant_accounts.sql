@@ -0,0 +1,91 @@
+-- name: CreateMerchantAccount :one
+INSERT INTO merchant_accounts (id, name, reporting_currency, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+RETURNING id, name, created_at, updated_at, reporting_currency;
+
+-- name: GetMerchantAccountByID :one
+SELECT id, name, created_at, updated_at, reporting_currency
+FROM merchant_accounts
+WHERE id = $1;
+
+-- name: GetMerchantAccountByShopID :one
+SELECT ma.id, ma.name, ma.created_at, ma.updated_at, ma.reporting_currency
+FROM merchant_accounts ma
+JOIN merchant_account_shops mas ON mas.account_id = ma.id
+WHERE mas.shop_id = $1;
+
+-- name: GetMerchantAccountsByUserID :many
+SELECT ma.id, ma.name, ma.created_at, ma.updated_at, ma.reporting_currency
+FROM merchant_accounts ma
+JOIN merchant_account_users mau ON mau.account_id = ma.id
+WHERE mau.user_id = $1
+ORDER BY ma.created_at;
+
+-- name: SetMerchantAccountReportingCurrency :one
+UPDATE merchant_accounts
+SET reporting_currency = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, created_at, updated_at, reporting_currency;
+
+-- name: AddMerchantAccountUser :exec
+INSERT INTO merchant_account_users (account_id, user_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (account_id, user_id) DO NOTHING;
+
+-- name: IsMerchantAccountUser :one
+SELECT EXISTS (
+    SELECT 1 FROM merchant_account_users WHERE account_id = $1 AND user_id = $2
+) AS is_member;
+
+-- name: SetMerchantAccountShop :one
+INSERT INTO merchant_account_shops (shop_id, account_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (shop_id)
+DO UPDATE SET
+    account_id = EXCLUDED.account_id
+RETURNING shop_id, account_id, created_at;
+
+-- name: GetMerchantAccountShops :many
+SELECT ss.id, ss.shop_domain, ss.shop_name, mas.created_at AS linked_at
+FROM merchant_account_shops mas
+JOIN shopify_store ss ON ss.id = mas.shop_id
+WHERE mas.account_id = $1
+ORDER BY mas.created_at, ss.shop_domain;
+
+-- name: GetMerchantAccountInventoryBySKU :many
+-- Stock of every SKU of the account's active integrations and its value at cost in the
+-- integration's currency, per integration. Variants and inventory items are joined on the
+-- item's external ID within the integration.
+SELECT pv.sku::text AS sku, pi.id AS integration_id, pi.platform_type, pi.currency,
+       MIN(p.title)::text AS title,
+       COUNT(DISTINCT pv.id) AS variants_count,
+       COALESCE(SUM(il.available) FILTER (WHERE l.deleted_at IS NULL), 0)::bigint AS available,
+       COALESCE(SUM(il.available * ii.cost) FILTER (WHERE l.deleted_at IS NULL AND il.available > 0), 0)::float8 AS stock_value
+FROM platform_integrations pi
+JOIN products p ON p.integration_id = pi.id AND p.deleted_at IS NULL
+JOIN product_variants pv ON pv.product_id = p.id AND pv.deleted_at IS NULL
+LEFT JOIN inventory_items ii ON ii.integration_id = pi.id AND ii.external_id = pv.inventory_item_id AND ii.deleted_at IS NULL
+LEFT JOIN inventory_levels il ON il.inventory_item_id = ii.id
+LEFT JOIN locations l ON l.id = il.location_id
+WHERE pi.account_id = $1 AND pi.is_active = true AND pv.sku IS NOT NULL AND pv.sku <> ''
+GROUP BY pv.sku, pi.id, pi.platform_type, pi.currency
+ORDER BY pv.sku, pi.id;
+
+-- name: GetMerchantAccountSalesBySKU :many
+-- Units sold of every SKU of the account's integrations since a time and their revenue in the
+-- integration's currency, per integration. Cancelled and voided orders are left out.
+SELECT pv.sku::text AS sku, pi.id AS integration_id, pi.platform_type, pi.currency,
+       COUNT(DISTINCT o.id) AS orders_count,
+       SUM(oli.quantity)::bigint AS units_sold,
+       COALESCE(SUM(oli.quantity * oli.price), 0)::float8 AS revenue
+FROM platform_integrations pi
+JOIN orders o ON o.integration_id = pi.id
+JOIN order_line_items oli ON oli.order_id = o.id
+JOIN product_variants pv ON pv.id = oli.variant_id
+WHERE pi.account_id = $1
+  AND o.created_at >= $2
+  AND o.cancelled_at IS NULL
+  AND o.financial_status <> 'voided'
+  AND pv.sku IS NOT NULL AND pv.sku <> ''
+GROUP BY pv.sku, pi.id, pi.platform_type, pi.currency
+ORDER BY pv.sku, pi.id;
@@ -1,51 +1,53 @@
 -- name: GetOrderByID :one
-SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 FROM orders
 WHERE id = $1;
 
 -- name: GetOrdersByIntegrationID :many
-SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 FROM orders
 WHERE integration_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: GetOrdersByIntegrationIDSince :many
-SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 FROM orders
 WHERE integration_id = $1 AND created_at >= $2
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4;
 
 -- name: GetOrderByExternalID :one
-SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at
+SELECT id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price
 FROM orders
 WHERE integration_id = $1 AND external_id = $2;
 
 -- name: CreateOrder :one
-INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at;
+INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price;
 
 -- name: UpdateOrder :one
 UPDATE orders
-SET financial_status = $3, fulfillment_status = $4, total_price = $5, cancelled_at = $6
+SET financial_status = $3, fulfillment_status = $4, total_price = $5, cancelled_at = $6, presentment_currency = $7, presentment_total_price = $8
 WHERE id = $1 AND integration_id = $2
-RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at;
+RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price;
 
 -- name: UpsertOrder :one
-INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-ON CONFLICT (external_id)
+INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     financial_status = EXCLUDED.financial_status,
     fulfillment_status = EXCLUDED.fulfillment_status,
     total_price = EXCLUDED.total_price,
-    cancelled_at = EXCLUDED.cancelled_at
-RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at;
+    cancelled_at = EXCLUDED.cancelled_at,
+    presentment_currency = EXCLUDED.presentment_currency,
+    presentment_total_price = EXCLUDED.presentment_total_price
+RETURNING id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price;
 
 -- name: InsertOrdersBatch :copyfrom
-INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+INSERT INTO orders (id, integration_id, external_id, created_at, financial_status, fulfillment_status, total_price, cancelled_at, presentment_currency, presentment_total_price) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
 
 -- name: DeleteOrder :exec
 DELETE FROM orders WHERE id = $1 AND integration_id = $2;
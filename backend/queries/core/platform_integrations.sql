@@ -1,40 +1,75 @@
 -- name: GetPlatformIntegrationByID :one
-SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 FROM platform_integrations
 WHERE id = $1;
 
+-- name: GetPlatformIntegrationByPlatformShop :one
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
+FROM platform_integrations
+WHERE platform_shop_id = $1 AND platform_type = $2;
+
 -- name: GetPlatformIntegrationsByShopID :many
-SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 FROM platform_integrations
 WHERE shop_id = $1 AND is_active = true
 ORDER BY created_at DESC;
 
 -- name: GetPlatformIntegrationByShopAndType :one
-SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
 FROM platform_integrations
 WHERE shop_id = $1 AND platform_type = $2 AND is_active = true;
 
+-- name: GetScheduledPlatformIntegrations :many
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
+FROM platform_integrations
+WHERE is_active = true AND sync_interval_minutes > 0 AND platform_type <> 'file_import'
+ORDER BY created_at;
+
 -- name: CreatePlatformIntegration :one
-INSERT INTO platform_integrations (id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at;
+INSERT INTO platform_integrations (id, shop_id, account_id, platform_type, platform_shop_id, is_active, created_at, updated_at)
+VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, NOW(), NOW())
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id;
 
 -- name: UpdatePlatformIntegration :one
 UPDATE platform_integrations
 SET is_active = $3, updated_at = NOW()
 WHERE id = $1 AND shop_id = $2
-RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at;
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id;
 
 -- name: UpsertPlatformIntegration :one
-INSERT INTO platform_integrations (id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+INSERT INTO platform_integrations (id, shop_id, account_id, platform_type, platform_shop_id, is_active, created_at, updated_at)
+VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, NOW(), NOW())
 ON CONFLICT (platform_shop_id, platform_type)
 DO UPDATE SET
     is_active = EXCLUDED.is_active,
     updated_at = NOW()
-RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at;
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id;
+
+-- name: SetPlatformIntegrationCurrency :one
+UPDATE platform_integrations
+SET currency = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id;
+
+-- name: SetPlatformIntegrationSyncInterval :one
+UPDATE platform_integrations
+SET sync_interval_minutes = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id;
 
 -- name: DeactivatePlatformIntegration :exec
 UPDATE platform_integrations
 SET is_active = false, updated_at = NOW()
 WHERE id = $1;
+
+-- name: GetPlatformIntegrationsByAccountID :many
+SELECT id, shop_id, platform_type, platform_shop_id, is_active, created_at, updated_at, sync_interval_minutes, currency, account_id
+FROM platform_integrations
+WHERE account_id = $1 AND is_active = true
+ORDER BY created_at;
+
+-- name: SetPlatformIntegrationsAccountByShopID :exec
+-- Moves the integrations of a shop into the account the shop joined
+UPDATE platform_integrations
+SET account_id = $2, updated_at = NOW()
+WHERE shop_id = $1;
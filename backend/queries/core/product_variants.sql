@@ -1,50 +1,68 @@
 -- name: GetProductVariantByID :one
-SELECT id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at
+SELECT id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at, deleted_at, barcode, option_values, compare_at_price, weight, weight_unit, integration_id
 FROM product_variants
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetProductVariantsByProductID :many
-SELECT id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at
+SELECT id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at, deleted_at, barcode, option_values, compare_at_price, weight, weight_unit, integration_id
 FROM product_variants
-WHERE product_id = $1
+WHERE product_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: GetProductVariantsByIntegrationID :many
-SELECT pv.id, pv.product_id, pv.external_id, pv.sku, pv.price, pv.inventory_item_id, pv.created_at, pv.updated_at
+SELECT pv.id, pv.product_id, pv.external_id, pv.sku, pv.price, pv.inventory_item_id, pv.created_at, pv.updated_at, pv.deleted_at, pv.barcode, pv.option_values, pv.compare_at_price, pv.weight, pv.weight_unit, pv.integration_id
 FROM product_variants pv
 JOIN products p ON pv.product_id = p.id
-WHERE p.integration_id = $1
+WHERE p.integration_id = $1 AND pv.deleted_at IS NULL
 ORDER BY pv.created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: GetProductVariantByExternalID :one
-SELECT pv.id, pv.product_id, pv.external_id, pv.sku, pv.price, pv.inventory_item_id, pv.created_at, pv.updated_at
+SELECT pv.id, pv.product_id, pv.external_id, pv.sku, pv.price, pv.inventory_item_id, pv.created_at, pv.updated_at, pv.deleted_at, pv.barcode, pv.option_values, pv.compare_at_price, pv.weight, pv.weight_unit, pv.integration_id
 FROM product_variants pv
 JOIN products p ON pv.product_id = p.id
-WHERE p.integration_id = $1 AND pv.external_id = $2;
+WHERE p.integration_id = $1 AND pv.external_id = $2 AND pv.deleted_at IS NULL;
 
 -- name: CreateProductVariant :one
-INSERT INTO product_variants (id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-RETURNING id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at;
+INSERT INTO product_variants (id, product_id, integration_id, external_id, sku, price, inventory_item_id, barcode, option_values, compare_at_price, weight, weight_unit, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+RETURNING id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at, deleted_at, barcode, option_values, compare_at_price, weight, weight_unit, integration_id;
 
 -- name: UpsertProductVariant :one
-INSERT INTO product_variants (id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-ON CONFLICT (external_id)
+INSERT INTO product_variants (id, product_id, integration_id, external_id, sku, price, inventory_item_id, barcode, option_values, compare_at_price, weight, weight_unit, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     sku = EXCLUDED.sku,
     price = EXCLUDED.price,
     inventory_item_id = EXCLUDED.inventory_item_id,
+    barcode = EXCLUDED.barcode,
+    option_values = EXCLUDED.option_values,
+    compare_at_price = EXCLUDED.compare_at_price,
+    weight = EXCLUDED.weight,
+    weight_unit = EXCLUDED.weight_unit,
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at;
+RETURNING id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at, deleted_at, barcode, option_values, compare_at_price, weight, weight_unit, integration_id;
 
 -- name: InsertProductVariantsBatch :batchexec
-INSERT INTO product_variants (id, product_id, external_id, sku, price, inventory_item_id, created_at, updated_at) 
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-ON CONFLICT (external_id)
+INSERT INTO product_variants (id, product_id, integration_id, external_id, sku, price, inventory_item_id, barcode, option_values, compare_at_price, weight, weight_unit, created_at, updated_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (integration_id, external_id)
 DO UPDATE SET
     sku = EXCLUDED.sku,
     price = EXCLUDED.price,
     inventory_item_id = EXCLUDED.inventory_item_id,
+    barcode = EXCLUDED.barcode,
+    option_values = EXCLUDED.option_values,
+    compare_at_price = EXCLUDED.compare_at_price,
+    weight = EXCLUDED.weight,
+    weight_unit = EXCLUDED.weight_unit,
+    deleted_at = NULL,
     updated_at = EXCLUDED.updated_at;
+
+-- name: SoftDeleteProductVariantsNotSyncedSince :execrows
+UPDATE product_variants pv
+SET deleted_at = NOW()
+FROM products p
+WHERE pv.product_id = p.id AND p.integration_id = $1 AND pv.updated_at < $2 AND pv.deleted_at IS NULL;
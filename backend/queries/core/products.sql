@@ -1,58 +1,80 @@
 -- name: GetProductByID :one
-SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 FROM products
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetProductsByIntegrationID :many
-SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 FROM products
-WHERE integration_id = $1
+WHERE integration_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: GetProductByHandle :one
-SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 FROM products
-WHERE integration_id = $1 AND handle = $2;
+WHERE integration_id = $1 AND handle = $2 AND deleted_at IS NULL;
+
+-- name: GetProductsByTags :many
+-- Products of an integration carrying every one of the tags
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
+FROM products
+WHERE integration_id = $1 AND tags @> $2 AND deleted_at IS NULL
+ORDER BY title, id;
 
 -- name: GetProductByExternalID :one
-SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at
+SELECT id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at
 FROM products
-WHERE integration_id = $1 AND external_id = $2;
+WHERE integration_id = $1 AND external_id = $2 AND deleted_at IS NULL;
 
 -- name: CreateProduct :one
-INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
-RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at;
+INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, vendor, tags, option_names, published_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at;
 
 -- name: UpdateProduct :one
 UPDATE products
 SET title = $3, handle = $4, product_type = $5, status = $6, updated_at = NOW()
 WHERE id = $1 AND integration_id = $2
-RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at;
+RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at;
 
 -- name: UpsertProduct :one
-INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, vendor, tags, option_names, published_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
 ON CONFLICT (integration_id, handle)
 DO UPDATE SET
     external_id = EXCLUDED.external_id,
     title = EXCLUDED.title,
     product_type = EXCLUDED.product_type,
     status = EXCLUDED.status,
+    vendor = EXCLUDED.vendor,
+    tags = EXCLUDED.tags,
+    option_names = EXCLUDED.option_names,
+    published_at = EXCLUDED.published_at,
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at;
+RETURNING id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at, deleted_at, vendor, tags, option_names, published_at;
 
 -- name: InsertProductsBatch :batchexec
-INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, created_at, updated_at) 
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+INSERT INTO products (id, integration_id, external_id, title, handle, product_type, status, vendor, tags, option_names, published_at, created_at, updated_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 ON CONFLICT (integration_id, handle)
 DO UPDATE SET
     external_id = EXCLUDED.external_id,
     title = EXCLUDED.title,
     product_type = EXCLUDED.product_type,
     status = EXCLUDED.status,
+    vendor = EXCLUDED.vendor,
+    tags = EXCLUDED.tags,
+    option_names = EXCLUDED.option_names,
+    published_at = EXCLUDED.published_at,
+    deleted_at = NULL,
     updated_at = EXCLUDED.updated_at;
 
 -- name: DeleteProduct :exec
 DELETE FROM products WHERE id = $1 AND integration_id = $2;
+
+-- name: SoftDeleteProductsNotSyncedSince :execrows
+UPDATE products
+SET deleted_at = NOW()
+WHERE integration_id = $1 AND updated_at < $2 AND deleted_at IS NULL;
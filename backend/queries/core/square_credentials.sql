@@ -0,0 +1,13 @@
+-- name: GetSquareCredentialsByIntegrationID :one
+SELECT id, integration_id, access_token, created_at, updated_at
+FROM square_credentials
+WHERE integration_id = $1;
+
+-- name: UpsertSquareCredentials :one
+INSERT INTO square_credentials (id, integration_id, access_token, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+ON CONFLICT (integration_id)
+DO UPDATE SET
+    access_token = EXCLUDED.access_token,
+    updated_at = NOW()
+RETURNING id, integration_id, access_token, created_at, updated_at;
@@ -0,0 +1,20 @@
+-- name: GetSyncCheckpointsByIntegrationID :many
+SELECT id, integration_id, entity_type, page_info, pages_processed, stats, completed, created_at, updated_at
+FROM sync_checkpoints
+WHERE integration_id = $1
+ORDER BY created_at;
+
+-- name: UpsertSyncCheckpoint :one
+INSERT INTO sync_checkpoints (id, integration_id, entity_type, page_info, pages_processed, stats, completed, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+ON CONFLICT (integration_id, entity_type)
+DO UPDATE SET
+    page_info = EXCLUDED.page_info,
+    pages_processed = EXCLUDED.pages_processed,
+    stats = EXCLUDED.stats,
+    completed = EXCLUDED.completed,
+    updated_at = NOW()
+RETURNING id, integration_id, entity_type, page_info, pages_processed, stats, completed, created_at, updated_at;
+
+-- name: DeleteSyncCheckpointsByIntegrationID :exec
+DELETE FROM sync_checkpoints WHERE integration_id = $1;
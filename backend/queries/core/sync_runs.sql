@@ -0,0 +1,47 @@
+-- name: CreateSyncRun :one
+INSERT INTO sync_runs (id, integration_id, entity_type, trigger, sync_status, task_id, dry_run, started_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+RETURNING id, integration_id, entity_type, trigger, sync_status, started_at, finished_at, phase_timings, stats, error_message, task_id, cancel_requested_at, dry_run, diff;
+
+-- name: FinishSyncRun :one
+UPDATE sync_runs
+SET sync_status = $2, finished_at = NOW(), phase_timings = $3, stats = $4, error_message = $5
+WHERE id = $1
+RETURNING id, integration_id, entity_type, trigger, sync_status, started_at, finished_at, phase_timings, stats, error_message, task_id, cancel_requested_at, dry_run, diff;
+
+-- name: FinishSyncDryRun :one
+UPDATE sync_runs
+SET sync_status = $2, finished_at = NOW(), diff = $3, error_message = $4
+WHERE id = $1 AND dry_run
+RETURNING id, integration_id, entity_type, trigger, sync_status, started_at, finished_at, phase_timings, stats, error_message, task_id, cancel_requested_at, dry_run, diff;
+
+-- name: GetSyncRunsByIntegrationID :many
+SELECT id, integration_id, entity_type, trigger, sync_status, started_at, finished_at, phase_timings, stats, error_message, task_id, cancel_requested_at, dry_run, diff
+FROM sync_runs
+WHERE integration_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
+
+-- name: IsSyncRunCancelRequested :one
+SELECT cancel_requested_at IS NOT NULL AS cancel_requested
+FROM sync_runs
+WHERE id = $1;
+
+-- name: IsSyncTaskCancelled :one
+SELECT EXISTS (
+    SELECT 1 FROM (
+        SELECT sync_status FROM sync_runs WHERE task_id = $1 ORDER BY started_at DESC LIMIT 1
+    ) latest
+    WHERE latest.sync_status = 'cancelled'
+) AS cancelled;
+
+-- name: RequestSyncRunsCancellation :many
+UPDATE sync_runs
+SET cancel_requested_at = NOW()
+WHERE integration_id = $1 AND finished_at IS NULL AND cancel_requested_at IS NULL
+RETURNING id, integration_id, entity_type, trigger, sync_status, started_at, finished_at, phase_timings, stats, error_message, task_id, cancel_requested_at, dry_run, diff;
+
+-- name: FailUnfinishedSyncRuns :execrows
+UPDATE sync_runs
+SET sync_status = 'failed', finished_at = NOW(), error_message = $3
+WHERE integration_id = $1 AND entity_type = $2 AND finished_at IS NULL AND NOT dry_run;
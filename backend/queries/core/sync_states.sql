@@ -1,34 +1,62 @@
 -- name: GetSyncState :one
-SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message
+SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
 FROM sync_states
 WHERE integration_id = $1 AND entity_type = $2;
 
 -- name: GetSyncStatesByIntegrationID :many
-SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message
+SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
 FROM sync_states
 WHERE integration_id = $1
 ORDER BY last_synced_at DESC;
 
+-- name: GetInProgressSyncStates :many
+SELECT id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at
+FROM sync_states
+WHERE sync_status = 'in_progress';
+
 -- name: CreateSyncState :one
-INSERT INTO sync_states (id, integration_id, entity_type, last_synced_at, sync_status, error_message)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message;
+INSERT INTO sync_states (id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at;
 
 -- name: UpdateSyncState :one
 UPDATE sync_states
-SET last_synced_at = $3, sync_status = $4, error_message = $5
+SET last_synced_at = $3, sync_status = $4, error_message = $5, heartbeat_at = NOW()
 WHERE integration_id = $1 AND entity_type = $2
-RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message;
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at;
 
 -- name: UpsertSyncState :one
-INSERT INTO sync_states (id, integration_id, entity_type, last_synced_at, sync_status, error_message)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO sync_states (id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
 ON CONFLICT (integration_id, entity_type)
 DO UPDATE SET
     last_synced_at = EXCLUDED.last_synced_at,
     sync_status = EXCLUDED.sync_status,
-    error_message = EXCLUDED.error_message
-RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message;
+    error_message = EXCLUDED.error_message,
+    heartbeat_at = EXCLUDED.heartbeat_at
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at;
 
 -- name: DeleteSyncState :exec
 DELETE FROM sync_states WHERE integration_id = $1 AND entity_type = $2;
+
+-- name: CancelInProgressSyncStates :many
+UPDATE sync_states
+SET sync_status = 'cancelled', error_message = NULL
+WHERE integration_id = $1 AND sync_status = 'in_progress'
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at;
+
+-- name: TouchSyncStateHeartbeat :exec
+UPDATE sync_states
+SET heartbeat_at = NOW()
+WHERE integration_id = $1 AND entity_type = $2 AND sync_status = 'in_progress';
+
+-- name: FailInProgressSyncState :execrows
+UPDATE sync_states
+SET sync_status = 'failed', error_message = $3
+WHERE integration_id = $1 AND entity_type = $2 AND sync_status = 'in_progress';
+
+-- name: FailStaleSyncStates :many
+UPDATE sync_states
+SET sync_status = 'failed', error_message = $2
+WHERE sync_status = 'in_progress' AND heartbeat_at < $1
+RETURNING id, integration_id, entity_type, last_synced_at, sync_status, error_message, heartbeat_at;
@@ -0,0 +1,15 @@
+-- name: GetWooCommerceCredentialsByIntegrationID :one
+SELECT id, integration_id, store_url, consumer_key, consumer_secret, created_at, updated_at
+FROM woocommerce_credentials
+WHERE integration_id = $1;
+
+-- name: UpsertWooCommerceCredentials :one
+INSERT INTO woocommerce_credentials (id, integration_id, store_url, consumer_key, consumer_secret, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+ON CONFLICT (integration_id)
+DO UPDATE SET
+    store_url = EXCLUDED.store_url,
+    consumer_key = EXCLUDED.consumer_key,
+    consumer_secret = EXCLUDED.consumer_secret,
+    updated_at = NOW()
+RETURNING id, integration_id, store_url, consumer_key, consumer_secret, created_at, updated_at;
@@ -0,0 +1,201 @@
+import { apiClient } from './client';
+import type { Integration } from './integrations';
+
+export interface AccountShop {
+  id: string;
+  shop_domain: string;
+  shop_name?: string;
+  linked_at: string;
+}
+
+export interface Account {
+  id: string;
+  name: string;
+  // Currency the revenue and stock value of the account views are converted into (ISO 4217)
+  reporting_currency: string;
+  shops: AccountShop[];
+  integrations: Integration[];
+}
+
+export interface AccountSKUChannel {
+  integration_id: string;
+  platform_type: Integration['platform_type'];
+  title?: string;
+  available: number;
+  units_sold: number;
+  orders_count: number;
+  // Revenue and stock value are in the channel's currency, empty while it is not known
+  currency: string;
+  revenue: number;
+  stock_value: number;
+}
+
+export interface AccountSKU {
+  sku: string;
+  title: string;
+  available: number;
+  units_sold: number;
+  daily_units: number;
+  revenue: number;
+  stock_value: number;
+  days_of_cover?: number;
+  channels: AccountSKUChannel[];
+}
+
+export interface AccountSKUs {
+  account_id: string;
+  days: number;
+  since: string;
+  // Reporting currency of the totals, and the channel currencies left out of them for lack of a
+  // rate ('unknown' for channels whose currency is not known yet)
+  currency: string;
+  // Day of the rates every amount is converted at, revenue of past orders included
+  rates_date: string;
+  missing_rates: string[];
+  skus: AccountSKU[];
+}
+
+export interface MasterSKU {
+  id: string;
+  sku: string;
+  title: string;
+  barcode?: string;
+  available: number;
+  units_sold: number;
+  daily_units: number;
+  revenue: number;
+  stock_value: number;
+  days_of_cover?: number;
+  channels: AccountSKUChannel[];
+}
+
+export interface MasterSKUs {
+  account_id: string;
+  days: number;
+  since: string;
+  currency: string;
+  rates_date: string;
+  missing_rates: string[];
+  master_skus: MasterSKU[];
+}
+
+export type MasterSKUMatchType = 'exact' | 'normalized' | 'barcode' | 'manual';
+
+export type MasterSKULinkStatus = 'suggested' | 'confirmed' | 'rejected';
+
+export interface MasterSKULink {
+  variant_id: string;
+  variant_sku?: string;
+  variant_barcode?: string;
+  product_title: string;
+  integration_id: string;
+  platform_type: Integration['platform_type'];
+  master_sku_id: string;
+  master_sku: string;
+  master_title: string;
+  match_type: MasterSKUMatchType;
+  status: MasterSKULinkStatus;
+  updated_at: string;
+}
+
+export interface MatchMasterSKUsResult {
+  account_id: string;
+  master_skus_created: number;
+  confirmed: number;
+  suggested: number;
+  unmatched: number;
+}
+
+export interface CreateMasterSKURequest {
+  sku: string;
+  title: string;
+  barcode?: string;
+}
+
+export interface ReviewMasterSKULinkRequest {
+  status?: MasterSKULinkStatus;
+  master_sku_id?: string;
+}
+
+export class AccountsApiService {
+  /**
+   * List the merchant accounts of the signed in user
+   */
+  async listAccounts(): Promise<Account[]> {
+    const response = await apiClient.get<{ accounts: Account[] }>('/v1/accounts', true);
+    return response.accounts;
+  }
+
+  /**
+   * Get the account of the session with its shops and integrations
+   */
+  async getCurrentAccount(): Promise<Account> {
+    return apiClient.get<Account>('/v1/accounts/current', true);
+  }
+
+  /**
+   * Change the currency the account views convert revenue and stock value into
+   */
+  async setReportingCurrency(reportingCurrency: string): Promise<Account> {
+    return apiClient.put<Account>('/v1/accounts/current', { reporting_currency: reportingCurrency }, true);
+  }
+
+  /**
+   * Move another shop the user has connected into the account of the session
+   */
+  async linkShop(shopDomain: string): Promise<Account> {
+    return apiClient.post<Account>('/v1/accounts/current/shops', { shop_domain: shopDomain }, true);
+  }
+
+  /**
+   * Get the stock and sales of every SKU across all the channels of the account
+   */
+  async getAccountSKUs(days?: number): Promise<AccountSKUs> {
+    const query = days ? `?days=${days}` : '';
+    return apiClient.get<AccountSKUs>(`/v1/accounts/current/skus${query}`, true);
+  }
+
+  /**
+   * Get the master SKUs of the account with the stock and sales of their confirmed variants
+   */
+  async getMasterSKUs(days?: number): Promise<MasterSKUs> {
+    const query = days ? `?days=${days}` : '';
+    return apiClient.get<MasterSKUs>(`/v1/accounts/current/master-skus${query}`, true);
+  }
+
+  /**
+   * Create a master SKU that variants can be linked to
+   */
+  async createMasterSKU(request: CreateMasterSKURequest): Promise<MasterSKU> {
+    return apiClient.post<MasterSKU>('/v1/accounts/current/master-skus', request, true);
+  }
+
+  /**
+   * Link the variants without a master SKU to one, suggesting the uncertain matches
+   */
+  async matchMasterSKUs(): Promise<MatchMasterSKUsResult> {
+    return apiClient.post<MatchMasterSKUsResult>('/v1/accounts/current/master-skus/match', {}, true);
+  }
+
+  /**
+   * List the matches of the account in a review state, the suggested ones by default
+   */
+  async getMasterSKULinks(status?: MasterSKULinkStatus): Promise<MasterSKULink[]> {
+    const query = status ? `?status=${status}` : '';
+    const response = await apiClient.get<{ links: MasterSKULink[] }>(
+      `/v1/accounts/current/master-skus/links${query}`,
+      true
+    );
+    return response.links;
+  }
+
+  /**
+   * Confirm or reject the match of a variant, or link it to another master SKU
+   */
+  async reviewMasterSKULink(variantId: string, review: ReviewMasterSKULinkRequest): Promise<MasterSKULink> {
+    return apiClient.put<MasterSKULink>(`/v1/accounts/current/master-skus/links/${variantId}`, review, true);
+  }
+}
+
+// Export a singleton instance
+export const accountsApi = new AccountsApiService();
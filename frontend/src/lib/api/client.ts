@@ -20,8 +20,9 @@ export class ApiClient {
   ): Promise<T> {
     const url = `${this.baseUrl}${endpoint}`;
     
+    // Multipart bodies get their Content-Type, boundary included, from fetch
     const headers: Record<string, string> = {
-      'Content-Type': 'application/json',
+      ...(options.body instanceof FormData ? {} : { 'Content-Type': 'application/json' }),
       ...(options.headers as Record<string, string>),
     };
 
@@ -78,6 +79,13 @@ export class ApiClient {
     }, requiresAuth);
   }
 
+  async upload<T>(endpoint: string, data: FormData, requiresAuth: boolean = false): Promise<T> {
+    return this.request<T>(endpoint, {
+      method: 'POST',
+      body: data,
+    }, requiresAuth);
+  }
+
   async put<T>(endpoint: string, data?: any, requiresAuth: boolean = false): Promise<T> {
     return this.request<T>(endpoint, {
       method: 'PUT',
@@ -0,0 +1,154 @@
+import { apiClient } from './client';
+import type { SyncStatus } from './sync';
+
+export interface Integration {
+  id: string;
+  platform_type: 'shopify' | 'woocommerce' | 'bigcommerce' | 'square' | 'magento' | 'file_import' | string;
+  platform_shop_id: string;
+  is_active: boolean;
+  created_at: string;
+  // Store currency of the integration's amounts (ISO 4217), absent until known
+  currency?: string;
+  // Minutes between scheduled full syncs, 0 when they are off
+  sync_interval_minutes: number;
+  sync?: SyncStatus;
+}
+
+export interface ConnectWooCommerceRequest {
+  // Shop the store is connected from, the session's account is used when absent
+  shop_domain?: string;
+  store_url: string;
+  consumer_key: string;
+  consumer_secret: string;
+}
+
+export interface ClaimBigCommerceRequest {
+  shop_domain?: string;
+  claim_token: string;
+}
+
+export interface ConnectSquareRequest {
+  shop_domain?: string;
+  access_token: string;
+}
+
+export interface ConnectMagentoRequest {
+  shop_domain?: string;
+  store_url: string;
+  access_token: string;
+}
+
+export interface Product {
+  id: string;
+  external_id: string;
+  title: string;
+  handle: string;
+  product_type?: string;
+  status: 'active' | 'archived' | 'draft';
+  vendor?: string;
+  // Lower-cased, as tags are stored normalized
+  tags: string[];
+  option_names: string[];
+  // Absent while the product is unpublished
+  published_at?: string;
+}
+
+export type ImportType = 'products' | 'inventory' | 'sales';
+
+export interface ImportFileResult {
+  integration_id: string;
+  kind: ImportType;
+  rows: number;
+  stats: {
+    locations_count: number;
+    products_count: number;
+    product_variants_count: number;
+    inventory_items_count: number;
+    inventory_levels_count: number;
+    orders_count: number;
+  };
+  order_line_items_count: number;
+}
+
+export class IntegrationsApiService {
+  /**
+   * List the platform integrations of a shop
+   */
+  async listIntegrations(shopDomain: string): Promise<Integration[]> {
+    const response = await apiClient.get<{ integrations: Integration[] }>(
+      `/v1/integrations?shop_domain=${encodeURIComponent(shopDomain)}`,
+      true
+    );
+    return response.integrations;
+  }
+
+  /**
+   * Connect a WooCommerce store with a REST API consumer key and secret
+   */
+  async connectWooCommerce(request: ConnectWooCommerceRequest): Promise<Integration> {
+    return apiClient.post<Integration>('/v1/integrations/woocommerce', request, true);
+  }
+
+  /**
+   * Link a BigCommerce store that installed the app, using the claim token from the install redirect
+   */
+  async claimBigCommerce(request: ClaimBigCommerceRequest): Promise<Integration> {
+    return apiClient.post<Integration>('/v1/integrations/bigcommerce/claim', request, true);
+  }
+
+  /**
+   * Connect a Square seller with an access token
+   */
+  async connectSquare(request: ConnectSquareRequest): Promise<Integration> {
+    return apiClient.post<Integration>('/v1/integrations/square', request, true);
+  }
+
+  /**
+   * Connect a Magento store with the access token of a store integration
+   */
+  async connectMagento(request: ConnectMagentoRequest): Promise<Integration> {
+    return apiClient.post<Integration>('/v1/integrations/magento', request, true);
+  }
+
+  /**
+   * Set the minutes between scheduled full syncs of an integration, 0 to turn them off
+   */
+  async setSyncInterval(integrationId: string, minutes: number): Promise<Integration> {
+    return apiClient.put<Integration>(
+      `/v1/integrations/${integrationId}/sync-interval`,
+      { sync_interval_minutes: minutes },
+      true
+    );
+  }
+
+  /**
+   * List the products of an integration carrying every one of the tags, whatever their case
+   */
+  async getProductsByTags(integrationId: string, tags: string[]): Promise<Product[]> {
+    const params = new URLSearchParams({ tags: tags.join(',') });
+    const response = await apiClient.get<{ products: Product[] }>(
+      `/v1/integrations/${integrationId}/products?${params}`,
+      true
+    );
+    return response.products;
+  }
+
+  /**
+   * Import the products, inventory or sales of a shop from a CSV or XLSX file.
+   * Products must be imported before the inventory and sales that refer to them.
+   * Amounts are in the shop's currency unless another currency is given.
+   */
+  async importFile(shopDomain: string, type: ImportType, file: File, currency?: string): Promise<ImportFileResult> {
+    const form = new FormData();
+    form.append('shop_domain', shopDomain);
+    form.append('type', type);
+    form.append('file', file);
+    if (currency) {
+      form.append('currency', currency);
+    }
+    return apiClient.upload<ImportFileResult>('/v1/integrations/imports', form, true);
+  }
+}
+
+// Export a singleton instance
+export const integrationsApi = new IntegrationsApiService();
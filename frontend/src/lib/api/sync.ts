@@ -1,23 +1,63 @@
 import { apiClient } from './client';
 
+export interface DiffSample {
+  change: 'new' | 'updated' | 'removed';
+  external_id: string;
+  label: string;
+  fields?: string[];
+}
+
+export interface EntityDiff {
+  new: number;
+  updated: number;
+  removed: number;
+  unchanged: number;
+  samples?: DiffSample[];
+}
+
+export interface SyncDiff {
+  locations: EntityDiff;
+  products: EntityDiff;
+  product_variants: EntityDiff;
+  inventory_items: EntityDiff;
+  inventory_levels: EntityDiff;
+}
+
 export interface SyncStatus {
   integration_id: string;
   status: string;
   last_synced?: string | null;
   error?: string;
+  // Run of a started dry run, whose diff is listed by getSyncRuns once it completed
+  run_id?: string;
+}
+
+export interface SyncRun {
+  id: string;
+  entity_type: string;
+  trigger: string;
+  status: string;
+  started_at: string;
+  finished_at?: string;
+  error?: string;
+  dry_run?: boolean;
+  diff?: SyncDiff;
 }
 
 export interface TriggerSyncRequest {
   shop_domain: string;
   force?: boolean;
+  dry_run?: boolean;
+  integration_id?: string;
 }
 
 export class SyncApiService {
   /**
-   * Get the sync status for a shop
+   * Get the sync status for a shop, or for one of its connected stores
    */
-  async getSyncStatus(shopDomain: string): Promise<SyncStatus> {
-    return apiClient.get<SyncStatus>(`/v1/sync/status/${shopDomain}`, true);
+  async getSyncStatus(shopDomain: string, integrationId?: string): Promise<SyncStatus> {
+    const query = integrationId ? `?integration_id=${encodeURIComponent(integrationId)}` : '';
+    return apiClient.get<SyncStatus>(`/v1/sync/status/${shopDomain}${query}`, true);
   }
 
   /**
@@ -26,6 +66,29 @@ export class SyncApiService {
   async triggerSync(request: TriggerSyncRequest): Promise<SyncStatus> {
     return apiClient.post<SyncStatus>('/v1/sync/trigger', request, true);
   }
+
+  /**
+   * List the most recent sync runs of a shop, or of one of its connected stores, newest first
+   */
+  async getSyncRuns(shopDomain: string, integrationId?: string): Promise<SyncRun[]> {
+    const integration = integrationId ? `&integration_id=${encodeURIComponent(integrationId)}` : '';
+    const response = await apiClient.get<{ runs: SyncRun[] }>(
+      `/v1/sync/runs?shop_domain=${encodeURIComponent(shopDomain)}${integration}`,
+      true
+    );
+    return response.runs;
+  }
+
+  /**
+   * Cancel the in-progress sync of a shop, or of one of its connected stores
+   */
+  async cancelSync(shopDomain: string, integrationId?: string): Promise<SyncStatus> {
+    return apiClient.post<SyncStatus>(
+      '/v1/sync/cancel',
+      { shop_domain: shopDomain, integration_id: integrationId },
+      true
+    );
+  }
 }
 
 // Export a singleton instance